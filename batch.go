@@ -0,0 +1,298 @@
+/*
+  batch.go
+  Description: Concurrent generation of many sheets from master files
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 05:41:27 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/clinaresl/mathprob/fstools"
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// By default, as many sheets are generated concurrently as CPUs are available
+var DEFAULTJOBS int = runtime.NumCPU()
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// Placeholders of the output files and directories of records are given
+// between braces, e.g., {name}
+var placeholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// Columns of the rows of CSV rosters when no header is given. Only the first
+// two are mandatory
+var rosterColumns = []string{"name", "class", "seed", "level"}
+
+// types
+// ----------------------------------------------------------------------------
+
+// A sheet consists of a master file and the name of the TeX file to generate
+// from it
+type sheet struct {
+	masterFile mathtools.MasterFile
+	dst        string
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given template of the output file or directory of the given
+// record, which is the n-th one starting from one, with all its placeholders
+// substituted. Values are stripped of path separators so that they can not
+// escape from the directory where they are given. If an unknown placeholder is
+// found, an error is returned
+func expandRecordTemplate(template string, record mathtools.MasterFile, n int) (string, error) {
+
+	infile := filepath.Base(record.GetInfile())
+	values := map[string]string{
+		"name":   record.GetName(),
+		"class":  record.GetClass(),
+		"infile": strings.TrimSuffix(infile, filepath.Ext(infile)),
+		"n":      strconv.Itoa(n),
+	}
+	sanitize := strings.NewReplacer("/", "-", "\\", "-")
+
+	var err error
+	result := placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		value, ok := values[match[1:len(match)-1]]
+		if !ok {
+			err = fmt.Errorf("unknown placeholder '%v' in '%v'", match, template)
+			return match
+		}
+		return sanitize.Replace(strings.TrimSpace(value))
+	})
+	return result, err
+}
+
+// return the records of all students given in the CSV roster stored in the
+// given file, all of them generated from the given master file. Every row
+// provides the student's name and class and, optionally, the seed used for
+// generating the problems and the student's difficulty level. If the first row
+// is a header naming the columns, they can be given in any order and any other
+// column is given as a parameter of the student (see MasterFile.GetParam)
+// unless it is empty. Columns can be separated either by commas or semicolons.
+// Records get the output file "{name}.tex" unless -outfile-template was given
+func readRoster(filename, infile string) ([]mathtools.MasterFile, error) {
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("It was not possible to read the CSV file '%v'", filename)
+	}
+	contents = bytes.TrimPrefix(contents, []byte("\xef\xbb\xbf"))
+
+	// spreadsheets exported with some locales separate columns by semicolons
+	reader := csv.NewReader(bytes.NewReader(contents))
+	firstLine := string(bytes.SplitN(contents, []byte("\n"), 2)[0])
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Error while reading the CSV file '%v': %v", filename, err)
+	}
+
+	// locate the index of every column, either from the header or by position.
+	// Rows are numbered as in the spreadsheet
+	first := 1
+	index := make(map[string]int)
+	for idx, column := range rosterColumns {
+		index[column] = idx
+	}
+	if len(rows) > 0 && helpers.Find(strings.ToLower(strings.TrimSpace(rows[0][0])), rosterColumns) {
+		index = make(map[string]int)
+		for idx, column := range rows[0] {
+			index[strings.ToLower(strings.TrimSpace(column))] = idx
+		}
+		if _, ok := index["name"]; !ok {
+			return nil, fmt.Errorf("the header of the CSV file '%v' does not name the column 'name'", filename)
+		}
+		rows, first = rows[1:], 2
+	}
+
+	// return the value of the given column of a row, which is empty if it
+	// was not given
+	value := func(row []string, column string) string {
+		if idx, ok := index[column]; ok && idx < len(row) {
+			return strings.TrimSpace(row[idx])
+		}
+		return ""
+	}
+
+	var records []mathtools.MasterFile
+	for idx, row := range rows {
+
+		// empty rows are skipped
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		record := mathtools.NewMasterFile(infile, value(row, "name"), value(row, "class"))
+		if record.Name == "" {
+			return nil, fmt.Errorf("no student's name was given in row #%v of the CSV file '%v'", idx+first, filename)
+		}
+		if seed := value(row, "seed"); seed != "" {
+			if record.Seed, err = strconv.ParseInt(seed, 10, 64); err != nil {
+				return nil, fmt.Errorf("the seed '%v' given in row #%v of the CSV file '%v' is not an integer", seed, idx+first, filename)
+			}
+		}
+		if level := value(row, "level"); level != "" {
+			if record.Level, err = strconv.Atoi(level); err != nil {
+				return nil, fmt.Errorf("the level '%v' given in row #%v of the CSV file '%v' is not an integer", level, idx+first, filename)
+			}
+			if _, ok := mathtools.GetLevel(record.Level); !ok {
+				return nil, fmt.Errorf("the level '%v' given in row #%v of the CSV file '%v' is not defined", level, idx+first, filename)
+			}
+		}
+		for column := range index {
+			if param := value(row, column); param != "" && !helpers.Find(column, rosterColumns) {
+				if record.Params == nil {
+					record.Params = make(map[string]interface{})
+				}
+				record.Params[column] = param
+			}
+		}
+		if outfileTemplate == "" {
+			record.Outfile = "{name}.tex"
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// return the path of the TeX file generated from the given record, which is
+// the n-th one starting from one. It is given by its output file or, if none
+// was given, by the template given with -outfile-template, within its output
+// directory (if any). Both can contain placeholders (see
+// expandRecordTemplate). The directory of the TeX file is created if it does
+// not exist unless a dry run was requested
+func recordPath(record mathtools.MasterFile, n int) (string, error) {
+
+	outfile := record.GetOutfile()
+	if outfile == "" {
+		outfile = outfileTemplate
+	}
+	if outfile == "" {
+		return "", fmt.Errorf("no output file was given for record #%v, use either 'outfile' or -outfile-template", n)
+	}
+	filename, err := expandRecordTemplate(filepath.Join(record.GetOutdir(), outfile), record, n)
+	if err != nil {
+		return "", err
+	}
+	dst, err := outPath(fstools.AddSuffix(filename, ".tex"))
+	if err != nil {
+		return "", err
+	}
+	if dryRun {
+		return dst, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", fmt.Errorf("It was not possible to create the output directory '%v'", filepath.Dir(dst))
+	}
+	return dst, nil
+}
+
+// generate all the given sheets with a pool of the given number of workers.
+// Sheets are independent of each other, so that errors in one sheet do not
+// prevent the others from being generated. The error of every sheet (nil if it
+// was successfully generated) is returned in the same order. Once the given
+// context is done, the remaining sheets are not generated
+func generateSheets(ctx context.Context, sheets []sheet, jobs int) []error {
+
+	errs := make([]error, len(sheets))
+
+	// the indexes of all sheets are sent to the workers through a channel
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				errs[idx] = sheets[idx].masterFile.MasterToFileFromTemplate(ctx, sheets[idx].dst)
+			}
+		}()
+	}
+	for idx := range sheets {
+		indexes <- idx
+	}
+	close(indexes)
+
+	// and wait for all workers to finish
+	wg.Wait()
+	return errs
+}
+
+// report the errors found when generating the given sheets, one per sheet that
+// could not be generated, and exit with failure if any was found
+func reportSheets(sheets []sheet, errs []error) {
+
+	failed := 0
+	for idx, err := range errs {
+		if err != nil {
+			fmt.Printf(" Error in sheet #%v (%v): %v\n", idx+1, sheets[idx].dst, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n %v out of %v sheets could not be generated\n", failed, len(sheets))
+		os.Exit(1)
+	}
+}
+
+// show a summary of every one of the given sheets without writing anything
+// (see MasterFile.Summarize), along with the total number of problems and
+// pages, and exit. If any sheet is not correct, all errors found are reported
+// and it exits with failure
+func summarizeSheets(ctx context.Context, sheets []sheet) {
+
+	status := EXIT_SUCCESS
+	problems, pages := 0, 0
+	for idx, sheet := range sheets {
+		summary, err := sheet.masterFile.Summarize(ctx, sheet.dst)
+		if err != nil {
+			fmt.Printf(" Error in sheet #%v (%v):\n%v\n\n", idx+1, sheet.dst, err)
+			status = 1
+			continue
+		}
+		fmt.Printf(" Sheet #%v (%v)\n", idx+1, sheet.masterFile.GetInfile())
+		fmt.Printf("\t Problems : %v\n", summary.Problems)
+		fmt.Printf("\t Pages    : %v\n", summary.Pages)
+		fmt.Printf("\t Files    : %v\n\n", strings.Join(summary.Files, ", "))
+		problems += summary.Problems
+		pages += summary.Pages
+	}
+	fmt.Printf(" %v sheets, %v problems and %v pages would be generated\n", len(sheets), problems, pages)
+	os.Exit(status)
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
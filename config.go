@@ -0,0 +1,206 @@
+/*
+  config.go
+  Description: Configuration files with default values of command-line flags
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 05:14:09 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Configuration file read by default from the current directory, if it exists
+const DEFAULTCONFIGFILE string = "mathprob.yaml"
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given scalar of a configuration file without surrounding quotes
+func configScalar(value string) string {
+
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 &&
+		((value[0] == '"' && value[len(value)-1] == '"') ||
+			(value[0] == '\'' && value[len(value)-1] == '\'')) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// return the given line of a configuration file without its comment, if any.
+// Comments start with '#' either at the beginning of the line or after a blank
+// space, and they are not recognized within quotes
+func configUncomment(line string) string {
+
+	var quote rune
+	for idx, char := range line {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '#' && (idx == 0 || line[idx-1] == ' ' || line[idx-1] == '\t'):
+			return line[:idx]
+		}
+	}
+	return line
+}
+
+// parse the contents of a configuration file and return a dictionary with the
+// value of every key. Configuration files are written in a subset of YAML
+// where every line either defines a key with a scalar value ("key: value") or
+// a list of scalars, given either inline ("key: [a, b]") or with one item per
+// line ("- item") indented below the key, which has no value. Comments start
+// with '#'. If the contents do not follow this format, an error is returned
+// with the line where it was found
+func parseConfig(contents []byte) (map[string]interface{}, error) {
+
+	config := make(map[string]interface{})
+	var list string
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for nbline := 1; scanner.Scan(); nbline++ {
+
+		// skip blank lines and comments
+		line := strings.TrimRight(configUncomment(scanner.Text()), " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// items of lists are indented below their key
+		if item := strings.TrimSpace(line); strings.HasPrefix(item, "- ") || item == "-" {
+			if list == "" || line[0] != ' ' && line[0] != '\t' {
+				return nil, fmt.Errorf("line %v: items of lists should be indented below a key with no value", nbline)
+			}
+			config[list] = append(config[list].([]string), configScalar(strings.TrimPrefix(item, "-")))
+			continue
+		}
+
+		// otherwise, this line should define a new key at the top level
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("line %v: nested mappings are not supported", nbline)
+		}
+		separator := strings.Index(line, ":")
+		if separator <= 0 {
+			return nil, fmt.Errorf("line %v: keys should be given as 'key: value'", nbline)
+		}
+		key, value := strings.TrimSpace(line[:separator]), strings.TrimSpace(line[separator+1:])
+		if _, ok := config[key]; ok {
+			return nil, fmt.Errorf("line %v: the key '%v' is defined more than once", nbline, key)
+		}
+
+		// keys with no value are the heading of a list of items
+		list = ""
+		switch {
+		case value == "":
+			list = key
+			config[key] = []string{}
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			items := []string{}
+			if inner := strings.TrimSpace(value[1 : len(value)-1]); inner != "" {
+				for _, item := range strings.Split(inner, ",") {
+					items = append(items, configScalar(item))
+				}
+			}
+			config[key] = items
+		default:
+			config[key] = configScalar(value)
+		}
+	}
+	return config, scanner.Err()
+}
+
+// read the given configuration file and use its values as the defaults of the
+// command-line flags with the same name, unless they were explicitly given in
+// the command line. In addition, the key "students" provides a list of
+// students' names. If no file is given, then DEFAULTCONFIGFILE is read from
+// the current directory, if it exists
+func loadConfig(filename string) error {
+
+	if filename == "" {
+		if _, err := os.Stat(DEFAULTCONFIGFILE); err != nil {
+			return nil
+		}
+		filename = DEFAULTCONFIGFILE
+	}
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("It was not possible to read the configuration file '%v'", filename)
+	}
+	config, err := parseConfig(contents)
+	if err != nil {
+		return fmt.Errorf("Error in the configuration file '%v': %v", filename, err)
+	}
+
+	// flags given in the command line override the values of the
+	// configuration file
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	for key, value := range config {
+		switch value := value.(type) {
+		case []string:
+			if key != "students" {
+				return fmt.Errorf("Error in the configuration file '%v': the key '%v' can not be given a list", filename, key)
+			}
+			students = value
+		case string:
+			if key == "students" {
+				return fmt.Errorf("Error in the configuration file '%v': the students should be given as a list", filename)
+			}
+			if flag.Lookup(key) == nil || key == "config" {
+				return fmt.Errorf("Error in the configuration file '%v': unknown key '%v'", filename, key)
+			}
+			if given[key] {
+				continue
+			}
+			if err := flag.Set(key, value); err != nil {
+				return fmt.Errorf("Error in the configuration file '%v': invalid value '%v' for '%v'", filename, value, key)
+			}
+		}
+	}
+	return nil
+}
+
+// return the given output filename located in the output directory given with
+// -outdir, if any, which is created if it does not exist unless a dry run was
+// requested. Absolute paths are returned as they are
+func outPath(filename string) (string, error) {
+
+	if outDir == "" || filepath.IsAbs(filename) {
+		return filename, nil
+	}
+	if dryRun {
+		return filepath.Join(outDir, filename), nil
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", fmt.Errorf("It was not possible to create the output directory '%v'", outDir)
+	}
+	return filepath.Join(outDir, filename), nil
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
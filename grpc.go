@@ -0,0 +1,257 @@
+/*
+  grpc.go
+  Description: gRPC server exposing the service defined in proto/mathprob.proto
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 06:21:47 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Status codes of gRPC used by the server
+const (
+	GRPCOK                int = 0
+	GRPCCANCELLED         int = 1
+	GRPCINVALIDARGUMENT   int = 3
+	GRPCRESOURCEEXHAUSTED int = 8
+	GRPCUNIMPLEMENTED     int = 12
+	GRPCINTERNAL          int = 13
+)
+
+// Every method of the gRPC service is served at a path given by the name of
+// the service and the method
+const GRPCSERVICE string = "/mathprob.MathProb/"
+
+// types
+// ----------------------------------------------------------------------------
+
+// A gRPC error consists of a status code and a message, which are sent to the
+// client in the trailers of the response
+type grpcError struct {
+	code    int
+	message string
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given message of a gRPC status percent-encoded as required in
+// the trailers of responses
+func grpcMessage(message string) string {
+
+	var output strings.Builder
+	for _, char := range []byte(message) {
+		if char < 0x20 || char > 0x7e || char == '%' {
+			fmt.Fprintf(&output, "%%%02X", char)
+		} else {
+			output.WriteByte(char)
+		}
+	}
+	return output.String()
+}
+
+// read the message sent in the body of a gRPC request. Messages are preceded
+// by a flag which tells whether they are compressed and their length. Since no
+// compression is supported, compressed messages are rejected
+func grpcRead(r *http.Request) ([]byte, *grpcError) {
+
+	var prefix [5]byte
+	if _, err := io.ReadFull(r.Body, prefix[:]); err != nil {
+		return nil, &grpcError{GRPCINVALIDARGUMENT, "malformed request"}
+	}
+	if prefix[0] != 0 {
+		return nil, &grpcError{GRPCUNIMPLEMENTED, "compressed messages are not supported"}
+	}
+	length := binary.BigEndian.Uint32(prefix[1:])
+	if int64(length) > MAXREQUESTSIZE {
+		return nil, &grpcError{GRPCRESOURCEEXHAUSTED, fmt.Sprintf("requests can not be larger than %v bytes", MAXREQUESTSIZE)}
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(r.Body, message); err != nil {
+		return nil, &grpcError{GRPCINVALIDARGUMENT, "malformed request"}
+	}
+	return message, nil
+}
+
+// handles all requests to the gRPC service. Responses are written with the
+// message returned by the method requested, if any, and the status of the
+// call in the trailers
+func handleGRPC(w http.ResponseWriter, r *http.Request) {
+
+	// gRPC requests are always sent with POST over HTTP/2
+	if r.Method != http.MethodPost || r.ProtoMajor != 2 ||
+		!strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "only gRPC requests are served", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+	// read the request and execute the method requested
+	response, status := func() ([]byte, *grpcError) {
+		request, status := grpcRead(r)
+		if status != nil {
+			return nil, status
+		}
+		switch strings.TrimPrefix(r.URL.Path, GRPCSERVICE) {
+		case "GenerateProblems":
+			return grpcGenerateProblems(r, request)
+		case "ListProblemTypes":
+			return grpcListProblemTypes()
+		}
+		return nil, &grpcError{GRPCUNIMPLEMENTED, fmt.Sprintf("unknown method '%v'", r.URL.Path)}
+	}()
+
+	// and write the response, if any, followed by the status
+	if status == nil {
+		var prefix [5]byte
+		binary.BigEndian.PutUint32(prefix[1:], uint32(len(response)))
+		w.Write(prefix[:])
+		w.Write(response)
+		status = &grpcError{code: GRPCOK}
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(status.code))
+	w.Header().Set("Grpc-Message", grpcMessage(status.message))
+}
+
+// generates the problems requested in the given message of type
+// GenerateProblemsRequest and returns them in a message of type
+// GenerateProblemsResponse
+func grpcGenerateProblems(r *http.Request, request []byte) ([]byte, *grpcError) {
+
+	// every master problem is translated into the same JSON used in JSON
+	// problem files so that it is verified in the very same way
+	var entries []map[string]interface{}
+	err := protoDecode(request, func(number, wiretype int, value uint64, bytes []byte) error {
+		if number != 1 || wiretype != PBBYTES {
+			return nil
+		}
+		entry := map[string]interface{}{"nbprobs": 0, "args": json.RawMessage("{}")}
+		err := protoDecode(bytes, func(number, wiretype int, value uint64, bytes []byte) error {
+			switch number {
+			case 1:
+				entry["type"] = string(bytes)
+			case 2:
+				entry["nbprobs"] = int32(value)
+			case 3:
+				entry["args"] = json.RawMessage(bytes)
+			case 4:
+				entry["seed"] = int64(value)
+			case 5:
+				entry["avoidrepeat"] = value != 0
+			}
+			return nil
+		})
+		entries = append(entries, entry)
+		return err
+	})
+	if err != nil {
+		return nil, &grpcError{GRPCINVALIDARGUMENT, err.Error()}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, &grpcError{GRPCINVALIDARGUMENT, fmt.Sprintf("the arguments of problems should be given as JSON objects: %v", err)}
+	}
+
+	// make sure that not too many problems are requested at once
+	masterProblem, err := mathtools.Unmarshall(data)
+	if err != nil {
+		return nil, &grpcError{GRPCINVALIDARGUMENT, err.Error()}
+	}
+	nbprobs := 0
+	for _, problem := range masterProblem {
+		nbprobs += problem.GetNbProbs()
+	}
+	if nbprobs > MAXSERVEDPROBLEMS {
+		return nil, &grpcError{GRPCRESOURCEEXHAUSTED, fmt.Sprintf("it is not allowed to request more than %v problems at once", MAXSERVEDPROBLEMS)}
+	}
+	deriveSeeds(masterProblem)
+
+	// generate all problems and encode them
+	problems, err := mathtools.GenerateProblems(r.Context(), masterProblem)
+	if err != nil {
+		if errors.Is(err, r.Context().Err()) {
+			return nil, &grpcError{GRPCCANCELLED, err.Error()}
+		}
+		return nil, &grpcError{GRPCINVALIDARGUMENT, err.Error()}
+	}
+	var response protoEncoder
+	for _, problem := range problems {
+		var message protoEncoder
+		message.String(1, problem.Probtype)
+		message.Int(2, int64(problem.Id))
+		message.Strings(3, problem.Args)
+		message.Strings(4, problem.Solution)
+		message.Strings(5, problem.Steps)
+		message.Int(6, int64(problem.Difficulty))
+		message.String(7, problem.Skill)
+		message.Strings(8, problem.Tags)
+		response.Message(1, message)
+	}
+	return response.data, nil
+}
+
+// returns a message of type ListProblemTypesResponse with all the problem
+// types supported
+func grpcListProblemTypes() ([]byte, *grpcError) {
+
+	var response protoEncoder
+	for _, problemType := range mathtools.SupportedTypes() {
+		example, err := json.Marshal(problemType.Example)
+		if err != nil {
+			return nil, &grpcError{GRPCINTERNAL, err.Error()}
+		}
+		var message protoEncoder
+		message.String(1, problemType.Name)
+		message.Strings(2, problemType.Mandatory)
+		message.Strings(3, problemType.Optional)
+		message.Bytes(4, example)
+		message.Bool(5, problemType.Master)
+		response.Message(1, message)
+	}
+	return response.data, nil
+}
+
+// starts a gRPC server listening at the given address which exposes the
+// service defined in proto/mathprob.proto. Requests are served over HTTP/2
+// without TLS. It only returns if the server can not be started or it stops
+func serveGRPC(addr string) error {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(GRPCSERVICE, handleGRPC)
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	server := &http.Server{Addr: addr, Handler: mux, Protocols: &protocols}
+
+	log.Printf("Serving the gRPC problem API at %v\n", addr)
+	return server.ListenAndServe()
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
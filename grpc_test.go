@@ -0,0 +1,229 @@
+/*
+  grpc_test.go
+  Description: Tests of the gRPC server exposing the service defined in
+  proto/mathprob.proto
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 10:06:35 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// return the given message framed as in gRPC requests and responses, i.e.,
+// preceded by the given compression flag and its length
+func grpcFrame(flag byte, message []byte) []byte {
+
+	prefix := [5]byte{flag}
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(message)))
+	return append(prefix[:], message...)
+}
+
+// return the response of the gRPC server to a call to the given method with
+// the given body sent over HTTP/2
+func callGRPC(t *testing.T, method string, body []byte) *http.Response {
+
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, GRPCSERVICE+method, bytes.NewReader(body))
+	req.ProtoMajor, req.ProtoMinor = 2, 0
+	req.Header.Set("Content-Type", "application/grpc")
+	rec := httptest.NewRecorder()
+	handleGRPC(rec, req)
+	return rec.Result()
+}
+
+// return the message of the given gRPC response after verifying that it
+// succeeded and that the message is correctly framed
+func grpcResponse(t *testing.T, res *http.Response) []byte {
+
+	t.Helper()
+	var body bytes.Buffer
+	body.ReadFrom(res.Body)
+	if status := res.Trailer.Get("Grpc-Status"); status != strconv.Itoa(GRPCOK) {
+		t.Fatalf("expected the status %v but got %q with message %q", GRPCOK, status, res.Trailer.Get("Grpc-Message"))
+	}
+	data := body.Bytes()
+	if len(data) < 5 || data[0] != 0 || int(binary.BigEndian.Uint32(data[1:5])) != len(data)-5 {
+		t.Fatalf("the response % x is not framed correctly", data)
+	}
+	return data[5:]
+}
+
+// the problems requested with GenerateProblems are returned in the fields
+// defined in proto/mathprob.proto, and they are the same generated from the
+// same request in JSON format
+func TestGRPCGenerateProblems(t *testing.T) {
+
+	// request two clocks and three divisions, all with the same seed
+	var request protoEncoder
+	for _, entry := range []struct {
+		probtype string
+		nbprobs  int64
+		args     string
+	}{
+		{"Clock", 2, `{"type": 0, "granularity": "quarter"}`},
+		{"Division", 3, `{"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2}`},
+	} {
+		var message protoEncoder
+		message.String(1, entry.probtype)
+		message.Int(2, entry.nbprobs)
+		message.String(3, entry.args)
+		message.Int(4, 17)
+		message.Bool(5, true)
+		request.Message(1, message)
+	}
+	res := callGRPC(t, "GenerateProblems", grpcFrame(0, request.data))
+	if contentType := res.Header.Get("Content-Type"); contentType != "application/grpc" {
+		t.Errorf("expected the content type 'application/grpc' but got %q", contentType)
+	}
+
+	// decode all problems returned
+	var problems []mathtools.ProblemJSON
+	for _, field := range protoFields(t, grpcResponse(t, res)) {
+		if field.number != 1 || field.wiretype != PBBYTES {
+			t.Fatalf("unexpected field %v in the response", field)
+		}
+		var problem mathtools.ProblemJSON
+		for _, item := range protoFields(t, []byte(field.bytes)) {
+			switch item.number {
+			case 1:
+				problem.Probtype = item.bytes
+			case 2:
+				problem.Id = int(item.value)
+			case 3:
+				problem.Args = append(problem.Args, item.bytes)
+			case 4:
+				problem.Solution = append(problem.Solution, item.bytes)
+			case 5:
+				problem.Steps = append(problem.Steps, item.bytes)
+			case 6:
+				problem.Difficulty = int(item.value)
+			case 7:
+				problem.Skill = item.bytes
+			case 8:
+				problem.Tags = append(problem.Tags, item.bytes)
+			}
+		}
+		problems = append(problems, problem)
+	}
+
+	// and compare them with those generated from the same request in JSON
+	masterProblem, err := mathtools.Unmarshall([]byte(`[
+		{"type": "Clock", "nbprobs": 2, "args": {"type": 0, "granularity": "quarter"}, "seed": 17, "avoidrepeat": true},
+		{"type": "Division", "nbprobs": 3, "args": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2}, "seed": 17, "avoidrepeat": true}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := mathtools.GenerateProblems(context.Background(), masterProblem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %v problems but got %v", len(expected), len(problems))
+	}
+	for idx := range expected {
+		if !reflect.DeepEqual(problems[idx], expected[idx]) {
+			t.Errorf("expected the problem #%v to be %+v but got %+v", idx, expected[idx], problems[idx])
+		}
+	}
+}
+
+// all problem types are listed with ListProblemTypes, along with their keys
+// and an example given as a JSON object
+func TestGRPCListProblemTypes(t *testing.T) {
+
+	res := callGRPC(t, "ListProblemTypes", grpcFrame(0, nil))
+	var names []string
+	for _, field := range protoFields(t, grpcResponse(t, res)) {
+		var name, example string
+		for _, item := range protoFields(t, []byte(field.bytes)) {
+			switch item.number {
+			case 1:
+				name = item.bytes
+			case 4:
+				example = item.bytes
+			}
+		}
+		if !json.Valid([]byte(example)) {
+			t.Errorf("the example of '%v' is not a JSON object: %q", name, example)
+		}
+		names = append(names, name)
+	}
+	var expected []string
+	for _, problemType := range mathtools.SupportedTypes() {
+		expected = append(expected, problemType.Name)
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the problem types %v but got %v", expected, names)
+	}
+}
+
+// errors are reported in the trailers with their status code and a
+// percent-encoded message, and no message is written
+func TestGRPCErrors(t *testing.T) {
+
+	var invalid, message protoEncoder
+	message.String(1, "Nope")
+	message.Int(2, 1)
+	invalid.Message(1, message)
+
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		code   int
+	}{
+		{"compressed message", "ListProblemTypes", grpcFrame(1, nil), GRPCUNIMPLEMENTED},
+		{"truncated frame", "ListProblemTypes", []byte{0, 0, 0}, GRPCINVALIDARGUMENT},
+		{"truncated message", "ListProblemTypes", grpcFrame(0, []byte{1, 2, 3})[:6], GRPCINVALIDARGUMENT},
+		{"unknown method", "Nope", grpcFrame(0, nil), GRPCUNIMPLEMENTED},
+		{"unknown problem type", "GenerateProblems", grpcFrame(0, invalid.data), GRPCINVALIDARGUMENT},
+		{"malformed protocol buffer", "GenerateProblems", grpcFrame(0, []byte{0x0a, 0x05}), GRPCINVALIDARGUMENT},
+	}
+	for _, test := range tests {
+		res := callGRPC(t, test.method, test.body)
+		var body bytes.Buffer
+		body.ReadFrom(res.Body)
+		if status := res.Trailer.Get("Grpc-Status"); status != strconv.Itoa(test.code) {
+			t.Errorf("%v: expected the status %v but got %q", test.name, test.code, status)
+		}
+		if message := res.Trailer.Get("Grpc-Message"); message == "" || message != grpcMessage(message) {
+			t.Errorf("%v: expected a percent-encoded message but got %q", test.name, message)
+		}
+		if body.Len() != 0 {
+			t.Errorf("%v: expected no message but got % x", test.name, body.Bytes())
+		}
+	}
+
+	// requests which are not sent with gRPC over HTTP/2 are rejected
+	req := httptest.NewRequest(http.MethodPost, GRPCSERVICE+"ListProblemTypes", bytes.NewReader(grpcFrame(0, nil)))
+	req.Header.Set("Content-Type", "application/grpc")
+	rec := httptest.NewRecorder()
+	handleGRPC(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected the status %v for HTTP/1.1 requests but got %v", http.StatusUnsupportedMediaType, rec.Code)
+	}
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
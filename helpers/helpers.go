@@ -11,6 +11,7 @@
 package helpers
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
@@ -18,27 +19,46 @@ import (
 	"strings"
 )
 
-// transform the input into a bool by making sure the input is either an int or
-// a string. In case an integer is given, 0 is false and any other value is 1;
-// if a string is given, "" and "false" (with any mixture of upper/lower case
-// letter) is false and any other string is true. In case it is not possible,
-// the value returned is undefined and an error is signaled
+// global variables
+// ----------------------------------------------------------------------------
+
+// number of significant digits used when formatting floating-point numbers,
+// e.g., in the formulas used for computing the location of TikZ coordinates
+var precision int = 4
+
+// functions
+// ----------------------------------------------------------------------------
+
+// transform the input into a bool by making sure the input is either a bool,
+// an int (also a number decoded from JSON) or a string. In case an integer is
+// given, 0 is false and any other value is 1; if a string is given, "" and
+// "false" (with any mixture of upper/lower case letter) is false and any other
+// string is true. In case it is not possible, the value returned is undefined
+// and an error is signaled
 func Atob(n interface{}) (bool, error) {
 
 	switch value := n.(type) {
+	case bool:
+		return value, nil
 	case int:
 		return value != 0, nil
+	case json.Number:
+		number, err := value.Float64()
+		if err != nil {
+			return false, err
+		}
+		return number != 0, nil
 	case string:
 		return value != "" && strings.ToLower(value) != "false", nil
 	}
 
 	// if the type has not been recognized, then return an error
-	return false, fmt.Errorf("It was not possible to cast '%v' into a bool")
+	return false, fmt.Errorf("It was not possible to cast '%v' into a bool", n)
 }
 
 // transform the input into an integer by making sure that the input is either
-// an int, a float or a string. In case it is not possible, the value returned
-// is undefined and an error is signaled
+// an int, a float, a number decoded from JSON or a string. In case it is not
+// possible, the value returned is undefined and an error is signaled
 func Atoi(n interface{}) (int, error) {
 
 	switch value := n.(type) {
@@ -54,6 +74,14 @@ func Atoi(n interface{}) (int, error) {
 		return int(value), nil
 	case float64:
 		return int(value), nil
+	case json.Number:
+
+		// numbers with a fractional part are truncated as floats are
+		if result, err := value.Int64(); err == nil {
+			return int(result), nil
+		}
+		result, err := value.Float64()
+		return int(result), err
 	case string:
 		if result, err := strconv.Atoi(value); err != nil {
 			return 0, err
@@ -63,7 +91,69 @@ func Atoi(n interface{}) (int, error) {
 	}
 
 	// if the type was not recognized, then return an error
-	return 0, fmt.Errorf("It was not possible to cast '%v' into an integer")
+	return 0, fmt.Errorf("It was not possible to cast '%v' into an integer", n)
+}
+
+// transform the input into a floating-point number by making sure that the
+// input is either an int, a float, a number decoded from JSON or a string. In
+// case it is not possible, the value returned is undefined and an error is
+// signaled
+func Atof(n interface{}) (float64, error) {
+
+	switch value := n.(type) {
+	case int:
+		return float64(value), nil
+	case float32:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case json.Number:
+		return value.Float64()
+	case string:
+		if result, err := strconv.ParseFloat(value, 64); err != nil {
+			return 0, err
+		} else {
+			return result, nil
+		}
+	}
+
+	// if the type was not recognized, then return an error
+	return 0, fmt.Errorf("It was not possible to cast '%v' into a floating-point number", n)
+}
+
+// transform the input into a slice of integers by making sure that the input is
+// either a slice of ints, a slice of values that can be individually cast into
+// integers (as those read from JSON files) or a string with comma-separated
+// integers. In case it is not possible, the value returned is undefined and an
+// error is signaled
+func AtoiSlice(n interface{}) ([]int, error) {
+
+	switch value := n.(type) {
+	case []int:
+		return value, nil
+	case []interface{}:
+		result := make([]int, len(value))
+		for idx, item := range value {
+			var err error
+			if result[idx], err = Atoi(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case string:
+		var result []int
+		for _, item := range strings.Split(value, ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, number)
+		}
+		return result, nil
+	}
+
+	// if the type was not recognized, then return an error
+	return nil, fmt.Errorf("It was not possible to cast '%v' into a slice of integers", n)
 }
 
 // return true if and only if the given value has been found in the
@@ -102,6 +192,39 @@ func FindInt(item int, container []int) bool {
 	return false
 }
 
+// Set the number of significant digits used when formatting floating-point
+// numbers with Ftoa. Non-positive values are ignored
+func SetPrecision(p int) {
+	if p > 0 {
+		precision = p
+	}
+}
+
+// return a string with the given floating-point number rounded to the current
+// number of significant digits (see SetPrecision). The result is never shown
+// in scientific notation so that it can be safely inserted in TikZ formulas,
+// and trailing zeros are removed
+func Ftoa(f float64) string {
+
+	// zero (and also non-finite numbers) can not be rounded using the
+	// logarithm of its magnitude
+	if f == 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	// compute the power of ten required to preserve only the requested number
+	// of significant digits and round the number accordingly. Note that
+	// negative exponents are handled separately to avoid dividing by inexact
+	// fractions such as 0.1
+	exp := float64(precision-1) - math.Floor(math.Log10(math.Abs(f)))
+	if exp < 0 {
+		scale := math.Pow(10, -exp)
+		return strconv.FormatFloat(math.Round(f/scale)*scale, 'f', -1, 64)
+	}
+	scale := math.Pow(10, exp)
+	return strconv.FormatFloat(math.Round(f*scale)/scale, 'f', -1, 64)
+}
+
 // compute the maximum of two floats
 func Max(a, b float64) float64 {
 	if a < b {
@@ -118,6 +241,52 @@ func Min(a, b int) int {
 	return b
 }
 
+// return the quotient of a and b rounded towards plus infinity. b has to be
+// strictly positive
+func CeilDiv(a, b int) int {
+	if a > 0 {
+		return 1 + (a-1)/b
+	}
+	return a / b
+}
+
+// return the quotient of a and b rounded towards minus infinity. b has to be
+// strictly positive
+func FloorDiv(a, b int) int {
+	if a < 0 {
+		return -CeilDiv(-a, b)
+	}
+	return a / b
+}
+
+// return n rounded to the nearest multiple of scale, which has to be strictly
+// positive. Numbers halfway between two multiples are rounded according to the
+// given tiebreak: "even" rounds them to the even multiple, "away" rounds them
+// away from zero and "up" (or any other value) rounds them towards plus
+// infinity
+func Round(n, scale int, tiebreak string) int {
+
+	lower := FloorDiv(n, scale) * scale
+	if rest := n - lower; 2*rest < scale {
+		return lower
+	} else if 2*rest > scale {
+		return lower + scale
+	}
+
+	// at this point, n is halfway between lower and the next multiple
+	switch tiebreak {
+	case "even":
+		if FloorDiv(lower, scale)%2 == 0 {
+			return lower
+		}
+	case "away":
+		if n < 0 {
+			return lower
+		}
+	}
+	return lower + scale
+}
+
 // return the number of digits of number n. In case the number is negative, then
 // 1 is added to display the unary -
 func NbDigits(n int) int {
@@ -137,11 +306,12 @@ func NbDigits(n int) int {
 	return 1 + int(math.Log10(float64(n)))
 }
 
-// return a random number with exactly n digits
-func RandN(n int) int {
+// return a random number with exactly n digits using the given source of
+// random numbers
+func RandN(rnd *rand.Rand, n int) int {
 	lower := int(math.Pow(float64(10), float64(n)-1))
 	upper := int(math.Pow(float64(10), float64(n)))
-	return lower + rand.Int()%(upper-lower)
+	return lower + rnd.Int()%(upper-lower)
 }
 
 // In case any of the arguments given in args does not appear in the specified
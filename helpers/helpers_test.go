@@ -0,0 +1,114 @@
+// -*- coding: utf-8 -*-
+// helpers_test.go
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 17:20:13.448021765 (1792171213)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// Tests of the general purpose functions used in the implementation of the
+// math tools
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+// return the number of significant digits of the given number formatted in
+// decimal notation
+func significantDigits(number string) int {
+
+	digits := strings.TrimLeft(number, "-")
+	digits = strings.Replace(digits, ".", "", 1)
+	return len(strings.Trim(digits, "0"))
+}
+
+// floating-point numbers are formatted with at most the current number of
+// significant digits, rounding them and never in scientific notation
+func TestFtoa(t *testing.T) {
+
+	defer SetPrecision(precision)
+	tests := []struct {
+		precision int
+		value     float64
+		expected  string
+	}{
+		{4, 3.14159, "3.142"},
+		{4, -2.71828, "-2.718"},
+		{4, 0.000123456, "0.0001235"},
+		{4, -0.000000001234567, "-0.000000001235"},
+		{4, 1234567, "1235000"},
+		{4, -42, "-42"},
+		{4, 0, "0"},
+		{4, 0.5, "0.5"},
+		{2, 3.14159, "3.1"},
+		{2, -0.0004567, "-0.00046"},
+		{2, 987, "990"},
+		{2, 100, "100"},
+		{6, 1.506, "1.506"},
+		{6, 2.0 / 3.0, "0.666667"},
+		{6, -123456789, "-123457000"},
+		{1, 0.0999, "0.1"},
+	}
+	for _, test := range tests {
+		SetPrecision(test.precision)
+		result := Ftoa(test.value)
+		if result != test.expected {
+			t.Errorf("Ftoa(%v) with precision %v: expected '%v' but got '%v'", test.value, test.precision, test.expected, result)
+		}
+		if strings.ContainsAny(result, "eE") {
+			t.Errorf("Ftoa(%v) with precision %v is given in scientific notation: '%v'", test.value, test.precision, result)
+		}
+		if digits := significantDigits(result); digits > test.precision {
+			t.Errorf("Ftoa(%v) with precision %v has %v significant digits: '%v'", test.value, test.precision, digits, result)
+		}
+	}
+}
+
+// non-positive precisions are ignored
+func TestSetPrecision(t *testing.T) {
+
+	defer SetPrecision(precision)
+	SetPrecision(3)
+	SetPrecision(0)
+	SetPrecision(-2)
+	if precision != 3 {
+		t.Errorf("expected a precision of 3 but got %v", precision)
+	}
+}
+
+// numbers are rounded to the nearest multiple, and ties are broken according
+// to the given rule
+func TestRound(t *testing.T) {
+
+	tests := []struct {
+		n, scale int
+		up       int
+		even     int
+		away     int
+	}{
+		{25, 10, 30, 20, 30},
+		{35, 10, 40, 40, 40},
+		{24, 10, 20, 20, 20},
+		{26, 10, 30, 30, 30},
+		{250, 100, 300, 200, 300},
+		{1500, 1000, 2000, 2000, 2000},
+		{-25, 10, -20, -20, -30},
+		{-35, 10, -30, -40, -40},
+		{-26, 10, -30, -30, -30},
+		{7, 1, 7, 7, 7},
+	}
+	for _, test := range tests {
+		for tiebreak, expected := range map[string]int{"up": test.up, "even": test.even, "away": test.away} {
+			if result := Round(test.n, test.scale, tiebreak); result != expected {
+				t.Errorf("expected Round(%v, %v, %v) to be %v but got %v", test.n, test.scale, tiebreak, expected, result)
+			}
+		}
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -19,14 +19,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/clinaresl/mathprob/fstools"
+	"github.com/clinaresl/mathprob/helpers"
 	"github.com/clinaresl/mathprob/mathtools"
 )
 
@@ -39,15 +45,70 @@ var EXIT_SUCCESS int = 0     // exit with success
 var masterFilename string      // master file
 var texFilename string         // output tex filename
 var jsonFilename string        // JSON filename with info of all records to process
+var csvFilename string         // CSV roster with the students to process
 var jsonProblemFilename string // JSON input filename requesting problems to generate
 var studentName string         // student's name
 var className string           // student's class name
+var coordPrecision int         // number of significant digits in coordinates
+var levelsFilename string      // JSON file with user-defined difficulty levels
+var lang string                // locale used for drawing problems
+var solutions bool             // should solutions be written in JSON format?
+var answers bool               // should answer keys be generated?
+var seed int64                 // seed used for generating random problems
+var unique bool                // should problems be unique within a sheet?
+var uniqueAttempts int         // maximum number of attempts to avoid repetitions
+var difficulty string          // band of difficulties of problems in sheets
+var numbered bool              // should problems be numbered?
+var serveAddr string           // address where the JSON problem API is served
+var pdf bool                   // should TeX files be compiled into PDF?
+var svg bool                   // should JSON problems be rendered in SVG?
+var export string              // format used for exporting JSON problems
+var latexEngine string         // LaTeX engine used for compiling TeX files
+var latexPasses int            // number of passes of the LaTeX engine
+var check bool                 // should master files be only validated?
+var dryRun bool                // should sheets be only summarized?
+var watchMode bool             // should the master file be watched?
+var configFilename string      // configuration file with default values
+var outDir string              // directory where output files are written
+var outfileTemplate string     // template of the TeX files of JSON records
+var students []string          // students' names given in the configuration file
+var jobs int                   // number of sheets generated concurrently
+var grpc bool                  // should the gRPC problem API be served?
+var legacyJSON bool            // should JSON problems be written without envelope?
 var helpMaster bool            // is help on master files requested?
 var helpJSON bool              // is help about JSON files requested?
 var helpJSONProblem bool       // is help about JSON problem files requested?
+var jsonSchema string          // name of the JSON Schema to show
 var verbose bool               // has verbose output been requested?
 var version bool               // has version info been requested?
 
+// Reusable TikZ components that can be used in master files, along with their
+// mandatory and optional keys and an example
+var masterComponents = []struct {
+	name                string
+	mandatory, optional []string
+	example             string
+}{
+	{"Coordinate", []string{"label"}, []string{"x", "y", "formula"},
+		`{{.Coordinate (dict "label" "center" "x" 2 "y" 1.5)}}`},
+	{"Text", nil, []string{"label", "text", "options"},
+		`{{.Text (dict "label" "title" "text" "Solve it!")}}`},
+	{"Circle", []string{"reference", "radius"}, []string{"options"},
+		`{{.Circle (dict "reference" "center" "radius" 1.5 "options" "fill=LightBlue")}}`},
+	{"Ellipse", []string{"reference", "xradius", "yradius"}, []string{"options"},
+		`{{.Ellipse (dict "reference" "center" "xradius" 2 "yradius" 1)}}`},
+	{"Arrow", []string{"from", "to"}, []string{"double", "label", "options"},
+		`{{.Arrow (dict "from" "a" "to" "b" "double" true "label" "$d$")}}`},
+	{"MeasuredSegment", []string{"from", "to", "length"}, []string{"brace", "options"},
+		`{{.MeasuredSegment (dict "from" "a" "to" "b" "length" "5 cm" "brace" true)}}`},
+	{"CellGrid", []string{"origin"}, []string{"cells", "rows", "cols", "step", "width", "height", "options"},
+		`{{.CellGrid (dict "origin" "center" "rows" 10 "cols" 10 "step" 0.5)}}`},
+	{"Polygon", []string{"ref0", "ref1", "ref2"}, []string{"ref3, ...", "options"},
+		`{{.Polygon (dict "ref0" "a" "ref1" "b" "ref2" "c" "options" "fill=LightYellow")}}`},
+	{"RegularPolygon", []string{"reference", "sides", "radius"}, []string{"rotation", "options"},
+		`{{.RegularPolygon (dict "reference" "center" "sides" 6 "radius" 1)}}`},
+}
+
 // functions
 // ----------------------------------------------------------------------------
 
@@ -58,13 +119,40 @@ func init() {
 	flag.StringVar(&masterFilename, "infile", "", "master file to use for generating the sheets of exercises. If a JSON file is given also, this parameter is automatically discarded. Use '-help-master' to obtain additional information")
 	flag.StringVar(&texFilename, "outfile", "", "output filename with the TeX code of the exercises generated from the template file. If not given, then the student's name provided with -student-name is used instead. If none is provided, then 'main.tex' is used by default. In case the resulting TeX file already exists, then it is re-numbered to avoid overwritting existing contents")
 	flag.StringVar(&jsonFilename, "json-file", "", "file with information of all records to process in JSON format. If a JSON file is given, the input file given with -infile is automatically discarded. It is not allowed to provide more than 1024 records in the JSON file. Use 'help-json' to obtain additional information")
+	flag.StringVar(&csvFilename, "csv-file", "", "CSV roster with one student per row given with their name, class and, optionally, the seed used for generating their problems and their difficulty level, which can be used in the master file with {{.GetLevel}}. Columns are given in this order unless the first row names them, and they can be separated by commas or semicolons. A sheet is generated for every student from the master file given with -infile, named after the student unless -outfile-template is given. Use 'help-json' to obtain additional information")
 	flag.StringVar(&jsonProblemFilename, "json-problems-file", "", "JSON file requesting the generation of a number of problems which are return as another JSON file")
 	flag.StringVar(&studentName, "name", "", "Student's name")
 	flag.StringVar(&className, "class", "", "Student's class")
+	flag.BoolVar(&solutions, "solutions", false, "if given, the solutions of all problems generated from master files are written in JSON format to a sibling file with the suffix '.solutions.json'")
+	flag.BoolVar(&answers, "answers", false, "if given, an answer key with the same problems generated from master files and their solutions is written to a sibling TeX file with the suffix '.answers.tex'")
+	flag.Int64Var(&seed, "seed", 0, "seed used for generating problems so that the same sheets, answer keys and JSON problems are generated every time. If zero, it is taken from the current time")
+	flag.BoolVar(&unique, "unique", false, "if given, problems are not repeated within the same sheet generated from a master file. Repeated problems are regenerated up to the number of attempts given with -unique-attempts")
+	flag.IntVar(&uniqueAttempts, "unique-attempts", mathtools.MAXREPEATATTEMPTS, "maximum number of attempts for regenerating a repeated problem when -unique is given. If it is exhausted, the repeated problem is accepted and a warning is issued")
+	flag.StringVar(&difficulty, "difficulty", "", "difficulty of the problems generated from master files, either a single difficulty from 1 to 5 (e.g., '4') or an interval (e.g., '3-5'), where any bound can be omitted (e.g., '4-'). Problems with a different difficulty are generated again, so that hard sheets are consistently hard. By default, problems of any difficulty are accepted")
+	flag.BoolVar(&numbered, "numbered", false, "if given, all problems generated from master files are numbered consecutively starting from one. Use {{.AnswerSection}} in a master file to list the answers of all problems by their number")
+	flag.StringVar(&serveAddr, "serve", "", "if given, an HTTP server is started at the given address (e.g., ':8080') exposing the JSON problem API: 'POST /problems' receives the same JSON used with -json-problems-file and returns the problems generated, 'GET /problems/types' lists the problem types supported, and 'GET /schemas/request.json' and 'GET /schemas/problem.json' return the JSON Schemas of requests and problems")
+	flag.BoolVar(&grpc, "grpc", false, "if given, the gRPC service defined in 'proto/mathprob.proto' is served at the address given with -serve instead of the JSON problem API. Requests are served over HTTP/2 without TLS")
+	flag.BoolVar(&legacyJSON, "legacy-json", false, "if given, the problems generated in JSON format with -json-problems-file or -serve are written as a bare list, instead of within an envelope with the version of the format, the version of this program, the seed and the time when they were generated")
+	flag.BoolVar(&svg, "svg", false, "if given, the problems requested with -json-problems-file are rendered in SVG format and returned in a JSON list with the keys 'type', 'id' and 'svg'")
+	flag.StringVar(&export, "export", "json", "format of the problems requested with -json-problems-file: 'json' returns them in JSON format, 'gift' and 'moodle' export them as GIFT and Moodle XML question banks respectively, which can be directly imported into Moodle, and 'anki' exports them as Anki flashcards in a tab-separated text file")
+	flag.BoolVar(&pdf, "pdf", false, "if given, the TeX files generated from master files (and their answer keys, if any) are compiled into PDF files in the same directory with the LaTeX engine given with -latex-engine")
+	flag.StringVar(&latexEngine, "latex-engine", mathtools.DEFAULTLATEXENGINE, "LaTeX engine (e.g., 'pdflatex' or 'lualatex') used for compiling TeX files when -pdf is given")
+	flag.IntVar(&latexPasses, "latex-passes", mathtools.DEFAULTLATEXPASSES, "number of times the LaTeX engine is executed over every TeX file when -pdf is given")
+	flag.StringVar(&lang, "lang", "", "locale used for drawing problems, either 'en' or 'es'. It sets the decimal separator, the layout of divisions ('es' encloses the divisor in a box whereas 'en' uses the long division bracket), the language of the default stories of word problems and the words of their operators, and the language for writing numbers in words. Master files can query it with {{.Locale}}. By default, decimal points and boxed divisions are used, stories are told in Spanish and numbers are written in words in English")
+	flag.StringVar(&levelsFilename, "levels", "", "JSON file with a dictionary of difficulty levels of basic operations indexed by their number. Every level is given with the keys 'grade', 'operator', 'nboperands', 'nbdigitsop', 'nbdigitsrslt' and 'carry', and it either adds a new level or overrides a predefined one. Use '-help-master' to see the predefined levels")
+	flag.BoolVar(&check, "check", false, "if given, the master files given with -infile or -json-file are only validated without generating any problem. All errors found are reported along with their locations")
+	flag.BoolVar(&watchMode, "watch", false, "if given, the master file given with -infile is monitored and the TeX file (and its PDF, if -pdf is given) is generated again every time it is saved. The TeX file is overwritten instead of being re-numbered")
+	flag.StringVar(&configFilename, "config", "", "configuration file with the default values of any other flag, e.g., 'latex-engine: lualatex', one per line. Flags given in the command line override them. A list of students' names can be given with the key 'students', and one sheet is generated for every student from the master file given with -infile. If not given, '"+DEFAULTCONFIGFILE+"' is used if it exists in the current directory")
+	flag.BoolVar(&dryRun, "dry-run", false, "if given, the master files given with -infile or -json-file are validated and executed, and a summary of every sheet is shown with the number of problems and pages and the files that would be written, but nothing is written. It exits with failure if any error is found")
+	flag.StringVar(&outDir, "outdir", "", "directory where the TeX files generated from master files are written. It is created if it does not exist")
+	flag.StringVar(&outfileTemplate, "outfile-template", "", "template of the names of the TeX files generated from the records given with -json-file which do not provide their own 'outfile', e.g., '{class}/{name}-week{n}.tex'. Use 'help-json' to obtain additional information")
+	flag.IntVar(&jobs, "jobs", DEFAULTJOBS, "number of sheets generated concurrently from the records given with -json-file or the students given in the configuration file. By default, as many as CPUs are available")
+	flag.IntVar(&coordPrecision, "coord-precision", 4, "number of significant digits used for writing floating-point numbers in the coordinates of TikZ pictures")
 
 	flag.BoolVar(&helpMaster, "help-master", false, "provides information about the format and usage of master files")
 	flag.BoolVar(&helpJSON, "help-json", false, "provides information about the JSON format used to specify multiple records")
 	flag.BoolVar(&helpJSONProblem, "help-json-problem", false, "provides information about the JSON format used to request various problems as a JSON file")
+	flag.StringVar(&jsonSchema, "json-schema", "", "shows the JSON Schema of either the JSON problem files given with -json-problems-file ('request') or the problems generated from them ('problem') and exits")
 
 	// other optional parameters are verbose and version
 	flag.BoolVar(&verbose, "verbose", false, "provides verbose output")
@@ -79,16 +167,166 @@ func showVersion(signal int) {
 	os.Exit(signal)
 }
 
-// shows informmation on master files
+// return the value of the given argument as it should be written in the
+// dictionary of a master file. Strings are quoted whereas any other value is
+// written as is
+func masterValue(value interface{}) string {
+
+	if svalue, ok := value.(string); ok {
+		return fmt.Sprintf("%q", svalue)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// shows informmation on master files (see writeHelpMaster) and exits with the
+// given signal
 func showHelpMaster(signal int) {
+	writeHelpMaster(os.Stdout)
+	os.Exit(signal)
+}
 
-	fmt.Println(`
+// writes the information on master files to the given writer. The problem
+// types that can be used in master files are listed from the problem types
+// supported by mathtools, and the reusable components from masterComponents
+func writeHelpMaster(w io.Writer) {
+
+	fmt.Fprintln(w, `
  MASTER FILES
  ============
- 
- TBW
-`)
-	os.Exit(signal)
+
+ Master files are Go text templates which are instantiated to generate TeX
+ files. Besides the fields {{.GetName}} and {{.GetClass}}, with the student's
+ name and class, {{.GetLevel}}, with the student's difficulty level, {{.GetParam
+ "key" default}}, with the student's own parameters (see -help-json), and
+ {{.GetInfile}}, {{.GetOutfile}} and {{.GetOutdir}}, with the master file, the
+ TeX file and its directory, problems are generated with methods that receive
+ a dictionary of arguments created with 'dict'. To repeat a problem a number
+ of times use {{range .Slice n}} ... {{end}}. The following problem types are
+ available:`)
+
+	for _, problemType := range mathtools.SupportedTypes() {
+
+		// skip those problem types that can not be used in master files
+		if !problemType.Master {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n * %v\n", problemType.Name)
+		fmt.Fprintf(w, "\t Mandatory keys: %v\n", strings.Join(problemType.Mandatory, ", "))
+		if len(problemType.Optional) > 0 {
+			fmt.Fprintf(w, "\t Optional keys : %v\n", strings.Join(problemType.Optional, ", "))
+		}
+
+		// show an example writing first the mandatory keys and then the
+		// optional ones
+		var args []string
+		for _, key := range append(append([]string{}, problemType.Mandatory...), problemType.Optional...) {
+			if value, ok := problemType.Example[key]; ok {
+				args = append(args, fmt.Sprintf("%q %v", key, masterValue(value)))
+			}
+		}
+		fmt.Fprintf(w, "\t Example       : {{.%v (dict %v)}}\n", problemType.Name, strings.Join(args, " "))
+	}
+
+	// show how to use problem types registered by other packages
+	fmt.Fprintln(w, `
+ Problem types registered by other packages are generated with {{.Problem
+ "Name" (dict ...)}}, where "Name" is the name of the problem type.`)
+
+	// show how to lay out many problems in a grid
+	fmt.Fprintln(w, `
+ Problems of the same type can be laid out in a grid of rows and columns with
+ {{.Grid (dict "rows" 5 "cols" 4 "problem" "BasicOperation" "args" (dict ...))}},
+ where "args" is the dictionary of every problem. Optionally, the vertical
+ space between rows can be given as a LaTeX length with "vspace", and a page
+ break can be forced after every number of rows given with "pagerows".`)
+
+	// how to draw blank grid paper
+	fmt.Fprintln(w, `
+ Blank grid paper is drawn with {{.GridPaper (dict "step" 0.5 "cols" 30 "rows"
+ 40 "style" "square")}}, where "step" is the side of every cell in centimeters
+ and "style" is either "square" or "lined". Optionally, a margin in centimeters
+ can be left around the grid with "margin".`)
+
+	// and the reusable components for creating TikZ drawings
+	fmt.Fprintln(w, `
+ Drawings can be created within a tikzpicture environment with the following
+ components, where coordinates are referred to by their labels or formulas:`)
+	for _, component := range masterComponents {
+		fmt.Fprintf(w, "\n * %v\n", component.name)
+		if len(component.mandatory) > 0 {
+			fmt.Fprintf(w, "\t Mandatory keys: %v\n", strings.Join(component.mandatory, ", "))
+		}
+		fmt.Fprintf(w, "\t Optional keys : %v\n", strings.Join(component.optional, ", "))
+		fmt.Fprintf(w, "\t Example       : %v\n", component.example)
+	}
+
+	// how to draw random values in master files
+	fmt.Fprintln(w, `
+ Ad-hoc problems and varied headings can be written with random values drawn
+ with {{rand 10 99}}, which returns an integer in the given interval, {{choice
+ "red" "blue" "green"}}, which returns one of the given items, and {{range
+ shuffle 1 2 3}} ... {{end}}, which returns the given items in random order.
+ They are drawn from the seed given with -seed, and the same values are shown
+ in the answer key.`)
+
+	// how to parameterize master files in one place
+	fmt.Fprintln(w, `
+ The number of exercises, digits and so on can be parameterized in one place
+ at the top of a master file either with template variables, e.g., {{$n := 6}},
+ or with variables of the master file which are also visible in its partials,
+ e.g., {{.Set "digits" 3}} and {{.Get "digits"}}. Both can be used in
+ dictionaries along with the functions add, sub and mul, e.g., (add $n 1), seq,
+ which returns the numbers from one to the given one or in the given interval,
+ e.g., {{range seq 2 9}}, and repeat, which repeats a text a number of times,
+ e.g., {{repeat "\\quad" 3}}.`)
+
+	// how to share sections among many master files
+	fmt.Fprintln(w, `
+ Reusable sections stored in other files (partials) can be included either
+ with {{template "warmup.tex" .}} or with {{.Include "warmup.tex"}}. Partials
+ can use the same fields and methods of master files, and their paths are
+ resolved relative to the directory of the master file.`)
+
+	// how to add headers and footers to every page
+	fmt.Fprintln(w, `
+ Every page can show a header with the student's name and class, the title of
+ the sheet, the date and a logo, and a footer with a score box and the page
+ number with {{.PageStyle (dict "title" "..." "logo" "logo.png" "score" 10)}}.
+ All keys are optional, and "student", "date" and "pagenumbers" can be set to
+ false to hide them. Logos require the package graphicx.`)
+
+	// and how to list the answers of all problems
+	fmt.Fprintln(w, `
+ The answers of all problems drawn so far can be listed by their number with
+ {{.AnswerSection}}, usually at the end of the master file. Optionally, a
+ dictionary can be given with the title of the section ("title") and whether it
+ has to start in a new page ("newpage"). Use -numbered to number all problems.`)
+
+	// how to adapt master files to the locale
+	fmt.Fprintln(w, `
+ The locale selected with -lang can be queried with {{.Locale}}, which provides
+ its ISO 639-1 code (Code), decimal separator (DecimalSeparator), layout of
+ divisions (DivisionStyle) and the words of every operator (Operators), e.g.,
+ {{if eq .Locale.Code "es"}}Nombre{{else}}Name{{end}}.`)
+
+	// show also the difficulty levels of basic operations
+	fmt.Fprintln(w, `
+ Basic operations can be also defined with a difficulty level given with the
+ key "level", e.g., {{.BasicOperation (dict "level" 3)}}. Any other key given
+ overrides the value of the level. The following levels are predefined, and new
+ ones can be given with -levels:`)
+	fmt.Fprintln(w)
+	for _, number := range mathtools.Levels() {
+		level, _ := mathtools.GetLevel(number)
+		carry := "with carries"
+		if !level.Carry {
+			carry = "without carries"
+		}
+		fmt.Fprintf(w, "\t %v: %v operands of %v digits with operator '%v' and results of %v digits %v (%v)\n",
+			number, level.NbOperands, level.NbDigitsOp, level.Operator, level.NbDigitsRslt, carry, level.Grade)
+	}
+	fmt.Fprintln(w)
 }
 
 // shows informmation about input JSON files for generating many tex files in bach mode
@@ -98,22 +336,130 @@ func showHelpJSON(signal int) {
  JSON FILES
  ==========
 
- TBW
-`)
+ JSON files are used to generate many TeX files at once. They consist of a
+ list of records, each one with the following keys:
+
+	 infile : master file used for generating the TeX file
+	 name   : student's name
+	 class  : student's class
+	 outfile: name of the TeX file to generate
+
+ Optionally, the following keys can be given as well:
+
+	 outdir   : directory where the TeX file is written
+	 solutions: whether to write the solutions in JSON format
+	 answers  : whether to write an answer key
+	 seed     : seed used for generating the problems
+	 level    : student's difficulty level, available as {{.GetLevel}}
+	 params   : dictionary with the student's own parameters
+	 unique   : whether to avoid repeated problems in the same sheet
+	 numbered : whether to number all problems
+	 pdf      : whether to compile the TeX file into PDF
+
+ Example:
+
+ [
+	 {
+		 "infile": "templates/sequence.master",
+		 "name": "Adriana",
+		 "class": "1º A",
+		 "outfile": "adriana.tex"
+	 }
+ ]
+
+ Both the output file and directory can contain the placeholders {name},
+ {class}, {infile} (the name of the master file without its extension) and
+ {n} (the number of the record starting from one), so that many TeX files can
+ be organized automatically, e.g., "outfile": "{class}/{name}-week{n}.tex".
+ Records without an output file take it from -outfile-template. Directories
+ are relative to the one given with -outdir, if any, and they are created if
+ they do not exist.
+
+ The parameters of every student are available in the master file, so that
+ the same master file generates differentiated sheets. They can be accessed as
+ fields, e.g., {{.Params.nbdigitsop}}, or with a default value for students
+ not giving them, e.g., {{.BasicOperation (dict ... "nbdigitsop" (.GetParam
+ "nbdigitsop" 2))}} with records such as:
+
+	 {
+		 "infile": "templates/basic_operation.master",
+		 "name": "Juan",
+		 "params": {"nbdigitsop": 3}
+	 }
+
+ Alternatively, the students can be given in a CSV roster with -csv-file, as
+ exported from spreadsheets. Every row provides the student's name and class
+ and, optionally, the seed and level, in this order unless the first row is a
+ header naming the columns. Any other column named in the header is given as a
+ parameter of the student, e.g.:
+
+	 name;class;level;nbdigitsop
+	 Adriana;1º A;2;2
+	 Juan;1º A;3;3
+
+ All sheets are generated from the master file given with -infile, and they
+ are named after the students unless -outfile-template is given.`)
+	fmt.Println()
 	os.Exit(signal)
 }
 
 // shows informmation about input JSON files for generating problems of any kind
-// as an output JSON file
+// as an output JSON file (see writeHelpJSONProblem) and exits with the given
+// signal
 func showHelpJSONProblem(signal int) {
+	writeHelpJSONProblem(os.Stdout)
+	os.Exit(signal)
+}
 
-	fmt.Println(`
+// writes the information on JSON problem files to the given writer. The
+// problem types are listed from those supported by mathtools
+func writeHelpJSONProblem(w io.Writer) {
+
+	fmt.Fprintln(w, `
  JSON PROBLEM FILES
  ==================
 
- TBW
-`)
-	os.Exit(signal)
+ JSON problem files request the generation of problems which are returned in
+ JSON format. They consist of a list of entries, each one with the type of
+ problem ("type"), its arguments ("args"), the number of problems to generate
+ ("nbprobs") and, optionally, whether consecutive problems with the same
+ arguments and solution should be avoided ("avoidrepeat"), a seed for generating the same
+ problems every time ("seed") and the difficulty of the problems to generate
+ ("difficulty"), either a single one from 1 to 5 or an interval such as "3-5".
+ Requests are validated against the JSON Schema shown with -json-schema before
+ generating any problem.
+
+ Problems are returned within an envelope with the version of the format
+ ("format"), the version of this program ("generator"), the seed from which
+ the seeds of all problems requested without one are derived ("seed", given as
+ a string so that it is not rounded) and the time when they were generated
+ ("timestamp"). Giving the same seed with -seed generates the same problems
+ again. If -legacy-json is given, problems are returned as a bare list
+ instead. Every problem generated is given with its arguments ("args") and
+ solution ("solution"), and divisions, linear equations and basic operations
+ with more than two operands also enumerate the intermediate steps of their
+ solution ("steps"). Problems are also given with their difficulty from 1 to 5
+ ("difficulty"), the skill they exercise ("skill") and tags that describe them
+ further ("tags"), so that they can be filtered. The following problem types
+ are available:`)
+
+	for _, problemType := range mathtools.SupportedTypes() {
+
+		fmt.Fprintf(w, "\n * %v\n", problemType.Name)
+		fmt.Fprintf(w, "\t Mandatory keys: %v\n", strings.Join(problemType.Mandatory, ", "))
+		if len(problemType.Optional) > 0 {
+			fmt.Fprintf(w, "\t Optional keys : %v\n", strings.Join(problemType.Optional, ", "))
+		}
+
+		// show an example of a valid entry
+		example, _ := json.MarshalIndent(map[string]interface{}{
+			"type":    problemType.Name,
+			"args":    problemType.Example,
+			"nbprobs": 1,
+		}, "\t ", "    ")
+		fmt.Fprintf(w, "\t Example:\n\t %v\n", string(example))
+	}
+	fmt.Fprintln(w)
 }
 
 // parse the flags and verifies that proper values were given. If not, a fatal
@@ -137,18 +483,80 @@ func verify() {
 		showHelpJSONProblem(EXIT_SUCCESS)
 	}
 
+	// likewise, if a JSON Schema is requested show it and exit
+	switch jsonSchema {
+	case "":
+	case "request":
+		fmt.Print(string(mathtools.RequestSchema()))
+		os.Exit(EXIT_SUCCESS)
+	case "problem":
+		fmt.Print(string(mathtools.ProblemSchema()))
+		os.Exit(EXIT_SUCCESS)
+	default:
+		log.Fatalf("Unknown JSON Schema '%v' given with -json-schema. Use either 'request' or 'problem'", jsonSchema)
+	}
+
+	// verify that the precision of coordinates is strictly positive
+	if coordPrecision <= 0 {
+		log.Fatalf("The precision of coordinates given with -coord-precision should be strictly positive")
+	}
+
+	// verify that problems are exported in a known format, and that they are
+	// not rendered in SVG at the same time
+	if export != "json" && export != "gift" && export != "moodle" && export != "anki" {
+		log.Fatalf("Unknown format '%v' given with -export. Use either 'json', 'gift', 'moodle' or 'anki'", export)
+	}
+	if export != "json" && svg {
+		log.Fatalf("Problems can not be rendered in SVG with -svg when they are exported with -export")
+	}
+
+	// verify that the gRPC problem API is served at some address
+	if grpc && serveAddr == "" {
+		log.Fatalf("The address where the gRPC problem API is served should be given with -serve")
+	}
+
+	// verify that the LaTeX engine is executed at least once
+	if latexPasses <= 0 {
+		log.Fatalf("The number of passes given with -latex-passes should be strictly positive")
+	}
+
+	// verify that sheets are generated by one worker at least
+	if jobs <= 0 {
+		log.Fatalf("The number of jobs given with -jobs should be strictly positive")
+	}
+
+	// verify that repeated problems are regenerated at least once
+	if uniqueAttempts <= 0 {
+		log.Fatalf("The number of attempts given with -unique-attempts should be strictly positive")
+	}
+
+	// verify that dry runs are only requested for master files
+	if dryRun && (jsonProblemFilename != "" || serveAddr != "" || watchMode || check) {
+		log.Fatalf("-dry-run can only be used with the master files given with -infile or -json-file")
+	}
+
+	// verify that CSV rosters are used with a master file given with -infile
+	if csvFilename != "" && (masterFilename == "" || jsonFilename != "") {
+		log.Fatalf("The students given with -csv-file are processed with the master file given with -infile and they can not be used along with -json-file")
+	}
+
+	// verify that only master files given with -infile can be watched
+	if watchMode && (masterFilename == "" || jsonFilename != "" || csvFilename != "" || jsonProblemFilename != "" || serveAddr != "" || len(students) > 0) {
+		log.Fatalf("Only the master file given with -infile can be watched with -watch")
+	}
+
 	// verify that a master file has been given
-	if masterFilename == "" && jsonFilename == "" && jsonProblemFilename == "" {
+	if masterFilename == "" && jsonFilename == "" && jsonProblemFilename == "" && serveAddr == "" {
 		log.Fatalf("Use either -master-file or -json-file to provide a master file. See -help for more details")
 	}
 
 	// if optional parameters have not been provided, issue a
 	// warning as it might be used in the master file
-	if studentName == "" && jsonFilename == "" && jsonProblemFilename == "" {
+	if studentName == "" && len(students) == 0 && jsonFilename == "" && csvFilename == "" && jsonProblemFilename == "" && serveAddr == "" && !check {
 		log.Println("No student's name has been provided!")
 	}
 
-	if className == "" && jsonFilename == "" && jsonProblemFilename == "" {
+	if className == "" && jsonFilename == "" && csvFilename == "" && jsonProblemFilename == "" && serveAddr == "" && !check {
 		log.Println("No student's class has been provided!")
 	}
 }
@@ -177,17 +585,128 @@ func getTexName() string {
 
 }
 
+// if no seed was given for a master problem, then derive it from the one given
+// in the command line or, if none was given, from the current time. The seed
+// used is returned, so that the same problems can be generated again by
+// giving it with -seed
+func deriveSeeds(masterProblem []mathtools.MasterProblem) int64 {
+
+	base := seed
+	if base == 0 {
+		base = time.Now().UTC().UnixNano()
+	}
+	for idx := range masterProblem {
+		if masterProblem[idx].Seed == 0 {
+			masterProblem[idx].Seed = base + int64(idx)
+		}
+	}
+	return base
+}
+
+// write the problems requested in the given master problems to the given
+// writer in JSON format as soon as they are generated, and within an envelope
+// unless -legacy-json was given
+func writeJSONProblems(ctx context.Context, w io.Writer, masterProblem []mathtools.MasterProblem, seed int64) error {
+
+	if legacyJSON {
+		return mathtools.GenerateJSONStream(ctx, w, masterProblem)
+	}
+	envelope := mathtools.NewJSONEnvelope(fmt.Sprintf("mathprob %v", VERSION), seed)
+	return envelope.Stream(ctx, w, masterProblem)
+}
+
+// validate the master file given with -infile or all those given in the records
+// of the file given with -json-file, and exit with failure if any error is
+// found
+func checkMasterFiles() {
+
+	// get all master files to validate
+	infiles := []string{masterFilename}
+	if jsonFilename != "" {
+		jsonData, err := ioutil.ReadFile(jsonFilename)
+		if err != nil {
+			log.Fatalf(" Fatal Error: %v", err)
+		}
+		var records []mathtools.MasterFile
+		if err := json.Unmarshal(jsonData, &records); err != nil {
+			log.Fatalf(" Fatal Error: %v", err)
+		}
+		infiles = nil
+		for _, record := range records {
+			if !helpers.Find(record.GetInfile(), infiles) {
+				infiles = append(infiles, record.GetInfile())
+			}
+		}
+	}
+
+	// and validate them all, reporting all errors found
+	status := EXIT_SUCCESS
+	for _, infile := range infiles {
+		if err := mathtools.NewMasterFile(infile, "", "").Validate(); err != nil {
+			fmt.Printf(" %v:\n%v\n", infile, err)
+			status = 1
+		} else {
+			fmt.Printf(" %v: ok\n", infile)
+		}
+	}
+	os.Exit(status)
+}
+
 // Main body
 func main() {
 
-	// first, parse the flags
+	// first, parse the flags and take the defaults of those not given from
+	// the configuration file, if any
 	flag.Parse()
+	if err := loadConfig(configFilename); err != nil {
+		log.Fatalf(" Fatal Error: %v", err)
+	}
 
 	// verify the values parsed
 	verify()
 
-	// in case a JSON file was requested with different problems
-	if jsonProblemFilename != "" {
+	// set the precision used for writing coordinates
+	helpers.SetPrecision(coordPrecision)
+
+	// generation stops as soon as the program is interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// and read the difficulty levels defined by the user, if any
+	if levelsFilename != "" {
+		if err := mathtools.LoadLevels(levelsFilename); err != nil {
+			log.Fatalf(" Fatal Error: %v", err)
+		}
+	}
+
+	// and select the locale used for drawing problems, if any
+	if err := mathtools.SetLocale(lang); err != nil {
+		log.Fatalf(" Fatal Error: %v", err)
+	}
+
+	// and also the band of difficulties of the problems generated in sheets
+	band, err := mathtools.ParseDifficultyBand(difficulty)
+	if err != nil {
+		log.Fatalf(" Fatal Error: %v", err)
+	}
+
+	// in case master files have to be only validated, do it and exit
+	if check {
+		checkMasterFiles()
+	}
+
+	// in case the JSON problem API has to be served, start the server
+	if serveAddr != "" {
+		serveAPI := serve
+		if grpc {
+			serveAPI = serveGRPC
+		}
+		if err := serveAPI(serveAddr); err != nil {
+			log.Fatalf(" Fatal Error: %v", err)
+		}
+	} else if jsonProblemFilename != "" {
+
+		// in case a JSON file was requested with different problems
 
 		// Unmarshall the data from the input JSON file
 		jsonInput, _ := ioutil.ReadFile(jsonProblemFilename)
@@ -195,54 +714,178 @@ func main() {
 			log.Fatalf(" Fatal Error: %v", err)
 		} else {
 
-			// get the contents of problems in JSON format
-			if jsonOutput, err := mathtools.GenerateJSON(masterProblem); err != nil {
+			// if no seed was given for a problem, then derive it from the one
+			// given in the command line (if any)
+			base := deriveSeeds(masterProblem)
+
+			// problems in JSON format are written as soon as they are
+			// generated
+			if !svg && export == "json" {
+				if err := writeJSONProblems(ctx, os.Stdout, masterProblem, base); err != nil {
+					log.Fatalf(" Fatal Error: %v", err)
+				}
+				fmt.Println()
+				return
+			}
+
+			// otherwise, get the contents of problems rendered in SVG or
+			// exported to Moodle or Anki
+			var generate func(context.Context, []mathtools.MasterProblem) ([]byte, error)
+			switch {
+			case svg:
+				generate = mathtools.GenerateSVG
+			case export == "gift":
+				generate = func(ctx context.Context, problems []mathtools.MasterProblem) ([]byte, error) {
+					return mathtools.GenerateMoodle(ctx, problems, mathtools.MOODLEGIFT)
+				}
+			case export == "moodle":
+				generate = func(ctx context.Context, problems []mathtools.MasterProblem) ([]byte, error) {
+					return mathtools.GenerateMoodle(ctx, problems, mathtools.MOODLEXML)
+				}
+			case export == "anki":
+				generate = mathtools.GenerateAnki
+			}
+			if jsonOutput, err := generate(ctx, masterProblem); err != nil {
 				log.Fatalf(" Fatal Error: %v", err)
 			} else {
 				fmt.Println(string(jsonOutput))
 			}
 		}
 
-	} else if jsonFilename != "" {
+	} else if jsonFilename != "" || csvFilename != "" {
 
-		// in case a JSON file was provided
+		// in case a JSON file or a CSV roster was provided
 
-		// Unmarshal the JSON file to get all records to process
-		jsonData, _ := ioutil.ReadFile(jsonFilename)
+		// Unmarshal the JSON file to get all records to process or, if none
+		// was given, read them from the CSV roster
 		var records []mathtools.MasterFile
-		records = make([]mathtools.MasterFile, 5)
-		_ = json.Unmarshal([]byte(jsonData), &records)
+		if jsonFilename != "" {
+			jsonData, _ := ioutil.ReadFile(jsonFilename)
+			records = make([]mathtools.MasterFile, 5)
+			_ = json.Unmarshal([]byte(jsonData), &records)
+		} else if records, err = readRoster(csvFilename, masterFilename); err != nil {
+			log.Fatalf(" Fatal Error: %v", err)
+		}
 
 		fmt.Println()
-		for _, field := range records {
+		var sheets []sheet
+		for idx, field := range records {
 
 			// show info
 			fmt.Println(" * Processing ...")
 			fmt.Printf("\t Master file    : %s\n", field.GetInfile())
 			fmt.Printf("\t Student's name : %v\n", field.GetName())
-			fmt.Printf("\t TeX file       : %v\n\n", field.GetOutfile())
 
 			// process this specific record
 			masterFile := mathtools.NewMasterFile(field.GetInfile(),
 				field.GetName(),
 				field.GetClass())
-			masterFile.MasterToFileFromTemplate(fstools.AddSuffix(field.GetOutfile(),
-				".tex"))
+			masterFile.Level = field.Level
+			masterFile.Params = field.Params
+			masterFile.Solutions = solutions || field.Solutions
+			masterFile.Answers = answers || field.Answers
+			masterFile.Unique = unique || field.Unique
+			masterFile.UniqueAttempts = uniqueAttempts
+			masterFile.Difficulty = band
+			masterFile.Numbered = numbered || field.Numbered
+			masterFile.PDF = pdf || field.PDF
+			masterFile.LatexEngine = latexEngine
+			masterFile.LatexPasses = latexPasses
+			// if no seed was given for this record, then derive it from the
+			// one given in the command line (if any) so that different
+			// records generate different sheets
+			masterFile.Seed = field.Seed
+			if masterFile.Seed == 0 && seed != 0 {
+				masterFile.Seed = seed + int64(idx)
+			}
+			dst, err := recordPath(field, idx+1)
+			if err != nil {
+				log.Fatalf(" Fatal Error: %v", err)
+			}
+			fmt.Printf("\t TeX file       : %v\n\n", dst)
+			sheets = append(sheets, sheet{masterFile: masterFile, dst: dst})
+		}
+
+		// errors in one record are reported but they do not prevent the
+		// others from being processed
+		if dryRun {
+			summarizeSheets(ctx, sheets)
 		}
+		reportSheets(sheets, generateSheets(ctx, sheets, jobs))
+	} else if len(students) > 0 {
+
+		// in case a list of students was given in the configuration file,
+		// generate a sheet for every student from the same master file
+		fmt.Println()
+		var sheets []sheet
+		for idx, student := range students {
+
+			// show info
+			fmt.Println(" * Processing ...")
+			fmt.Printf("\t Master file    : %s\n", masterFilename)
+			fmt.Printf("\t Student's name : %v\n", student)
+
+			// process this student. Different students get different sheets
+			// unless no seed was given
+			masterFile := mathtools.NewMasterFile(masterFilename,
+				student,
+				className)
+			masterFile.Solutions = solutions
+			masterFile.Answers = answers
+			masterFile.Unique = unique
+			masterFile.UniqueAttempts = uniqueAttempts
+			masterFile.Difficulty = band
+			masterFile.Numbered = numbered
+			masterFile.PDF = pdf
+			masterFile.LatexEngine = latexEngine
+			masterFile.LatexPasses = latexPasses
+			if seed != 0 {
+				masterFile.Seed = seed + int64(idx)
+			}
+			dst, err := outPath(fstools.AddSuffix(student, ".tex"))
+			if err != nil {
+				log.Fatalf(" Fatal Error: %v", err)
+			}
+			fmt.Printf("\t TeX file       : %v\n\n", dst)
+			sheets = append(sheets, sheet{masterFile: masterFile, dst: dst})
+		}
+		if dryRun {
+			summarizeSheets(ctx, sheets)
+		}
+		reportSheets(sheets, generateSheets(ctx, sheets, jobs))
 	} else {
 
 		// Otherwise, use the parameters given by the user to
 		// generate a unique TeX file
 
 		// get the tex filename and show it on the standard output
-		texFilename = getTexName()
+		var err error
+		if texFilename, err = outPath(getTexName()); err != nil {
+			log.Fatalf(" Fatal Error: %v", err)
+		}
 		log.Printf("TeX filename: %s\n", texFilename)
 
 		// now, instantiate the master file with the data generated
 		masterFile := mathtools.NewMasterFile(masterFilename,
 			studentName,
 			className)
-		masterFile.MasterToFileFromTemplate(texFilename)
+		masterFile.Solutions = solutions
+		masterFile.Answers = answers
+		masterFile.Seed = seed
+		masterFile.Unique = unique
+		masterFile.UniqueAttempts = uniqueAttempts
+		masterFile.Difficulty = band
+		masterFile.Numbered = numbered
+		masterFile.PDF = pdf
+		masterFile.LatexEngine = latexEngine
+		masterFile.LatexPasses = latexPasses
+		if dryRun {
+			summarizeSheets(ctx, []sheet{{masterFile: masterFile, dst: texFilename}})
+		} else if watchMode {
+			watch(ctx, masterFile, texFilename)
+		} else if err := masterFile.MasterToFileFromTemplate(ctx, texFilename); err != nil {
+			log.Fatalf(" Fatal Error: %v", err)
+		}
 	}
 }
 
@@ -0,0 +1,73 @@
+/*
+  mathprob_test.go
+  Description: Tests of the command-line interface of mathprob
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 17:41:08 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// Methods of master files which are services of this package rather than
+// methods intended to be used within master files
+var masterServices = []string{"CompilePDF", "MasterToFileFromTemplate", "Summarize", "Validate"}
+
+// every method of master files intended to be used in their templates is
+// documented in the help on master files
+func TestHelpMasterMethods(t *testing.T) {
+
+	var help bytes.Buffer
+	writeHelpMaster(&help)
+
+	masterType := reflect.TypeOf(mathtools.MasterFile{})
+	for idx := 0; idx < masterType.NumMethod(); idx++ {
+		name := masterType.Method(idx).Name
+		if helpers.Find(name, masterServices) {
+			continue
+		}
+		if !regexp.MustCompile(`\{\{[^}]*\.` + name + `\b`).Match(help.Bytes()) {
+			t.Errorf("the method '%v' of master files is not documented in -help-master", name)
+		}
+	}
+}
+
+// every problem type is documented in the help on master files, if it can be
+// used in them, and in the help on JSON problem files, along with its
+// mandatory keys
+func TestHelpProblemTypes(t *testing.T) {
+
+	var helpMaster, helpJSONProblem bytes.Buffer
+	writeHelpMaster(&helpMaster)
+	writeHelpJSONProblem(&helpJSONProblem)
+	for _, problemType := range mathtools.SupportedTypes() {
+		entry := regexp.MustCompile(`\* ` + problemType.Name + `\n\t Mandatory keys: ` +
+			regexp.QuoteMeta(strings.Join(problemType.Mandatory, ", ")) + `\n`)
+		if problemType.Master && !entry.Match(helpMaster.Bytes()) {
+			t.Errorf("the problem type '%v' and its mandatory keys are not documented in -help-master", problemType.Name)
+		}
+		if !entry.Match(helpJSONProblem.Bytes()) {
+			t.Errorf("the problem type '%v' and its mandatory keys are not documented in -help-json-problem", problemType.Name)
+		}
+	}
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
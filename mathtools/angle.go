@@ -0,0 +1,390 @@
+// -*- coding: utf-8 -*-
+// angle.go
+//
+// Description: Provides services for automatically creating problems where
+// angles have to be either classified or measured
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:45:44.976636409 (1792115144)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of angle problems: "classify" or "measure". In
+// the first case, the student has to tell whether the angle is acute, right or
+// obtuse; in the latter, the student has to measure it in degrees
+const (
+	ANCLASSIFY int = iota
+	ANMEASURE
+)
+
+// Angles are classified as follows
+const (
+	ANACUTE  string = "acute"
+	ANRIGHT  string = "right"
+	ANOBTUSE string = "obtuse"
+)
+
+// By default, the measure of angles is a multiple of the following number of
+// degrees
+const DEFAULTANGLESTEP int = 5
+
+// The rays of angles, the arc marking them and the protractor have the
+// following lengths in centimeters
+const (
+	ANRAYLENGTH        float64 = 3.0
+	ANMARKRADIUS       float64 = 0.6
+	ANPROTRACTORRADIUS float64 = 2.5
+)
+
+// the TikZ code for generating angles is shown next. Note that it makes use of
+// LaTeX/TikZ components
+const latexAngleCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the angle
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZAngleCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box and vertex of the angle
+      {{.Bottom}}
+      {{.Vertex}}
+
+      % --- Protractor ------------------------------------------------------
+
+      % the protractor, if requested, is drawn below the angle with ticks
+      % every ten degrees
+{{.GetProtractor}}
+      % --- Angle -----------------------------------------------------------
+
+      % the first ray of the angle is horizontal, and the angle is marked with
+      % an arc
+      {{.Rays}}
+      {{.Mark}}
+
+      % --- Question --------------------------------------------------------
+
+      % the answer is shown below the angle within an empty box
+{{.GetQuestion}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// An angle problem consists of an angle whose measure is a multiple of step
+// degrees strictly between 0 and 180, and it is drawn with a protractor below
+// if requested. There are two types of angle problems:
+//
+//    0: the angle has to be classified as acute, right or obtuse
+//    1: the angle has to be measured in degrees
+type angle struct {
+	antype     int
+	step       int
+	protractor bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw angles
+type angleTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0),
+	// and the vertex of the angle is located above it
+	Bottom, Vertex components.Coordinate
+
+	// the protractor consists of an arc, its baseline, ticks and labels
+	protractor []fmt.Stringer
+
+	// the rays of the angle are drawn as a line going through its vertex, and
+	// the angle is marked with an arc
+	Rays components.Line
+	Mark components.Arc
+
+	// the question consists of the box to fill in and, when measuring, the
+	// symbol of degrees
+	question []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the classification of an angle with the given measure in degrees
+func classifyAngle(measure int) string {
+
+	switch {
+	case measure < 90:
+		return ANACUTE
+	case measure == 90:
+		return ANRIGHT
+	}
+	return ANOBTUSE
+}
+
+// return the position at the given distance in centimeters from the vertex of
+// an angle in the direction of the given angle in degrees. Offsets are rounded
+// to one micron to avoid showing tiny numbers such as 1e-16
+func fromVertex(angle, distance float64) string {
+	return fmt.Sprintf(`$(vertex) + (%vcm, %vcm)$`,
+		helpers.Ftoa(math.Round(1e4*distance*math.Cos(angle*math.Pi/180.0))/1e4),
+		helpers.Ftoa(math.Round(1e4*distance*math.Sin(angle*math.Pi/180.0))/1e4))
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- angleTikZ
+
+// Generates the TikZ code necessary for drawing the protractor
+func (tikz angleTikZ) GetProtractor() string {
+
+	// Use a btyes buffer to append the strings of each element
+	var output bytes.Buffer
+
+	for _, item := range tikz.protractor {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// protractor
+	return output.String()
+}
+
+// Generates the TikZ code necessary for drawing the question
+func (tikz angleTikZ) GetQuestion() string {
+
+	// Use a btyes buffer to append the strings of each text
+	var output bytes.Buffer
+
+	for _, item := range tikz.question {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// question
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz angleTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("angleTikZ").Parse(tikZAngleCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- angle
+
+// return the instance of a specific angle problem that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with the measure of the angle in degrees followed, when
+// the angle has to be classified, by its classification: "acute", "right" or
+// "obtuse". Either the classification or the measure are shown as "?" in the
+// arguments as they have to be guessed by the student
+func (an angle) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if an.step < 1 || an.step >= 180 {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate angles which are multiples of %v degrees", an.step)
+	}
+
+	// randomly choose the measure of the angle among all the multiples of the
+	// step strictly between 0 and 180 degrees
+	measure := an.step * (1 + rnd.Intn((179)/an.step))
+
+	// and return the problem along with its solution
+	if an.antype == ANCLASSIFY {
+		return ProblemJSON{
+			Probtype: "Angle",
+			Args:     []string{strconv.Itoa(measure), "?"},
+			Solution: []string{strconv.Itoa(measure), classifyAngle(measure)}}, nil
+	}
+	return ProblemJSON{
+		Probtype: "Angle",
+		Args:     []string{"?"},
+		Solution: []string{strconv.Itoa(measure)}}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this angle using TikZ components
+func (an angle) GetTikZPicture() (string, error) {
+
+	// -- angle: randomly determine the measure of the angle. For this, the
+	//           service that generates problems is the one that can marshal
+	//           them into JSON format. A question mark is either the
+	//           classification or the measure that has to be guessed by the
+	//           student
+	instance, err := an.next(annotated(an))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid angle: %v", err)
+	}
+	measure, _ := strconv.Atoi(instance.Solution[0])
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box, and the vertex is
+	// located far enough to its right to draw obtuse angles
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	vertex := components.NewCoordinate(components.Point{
+		X: 0.5 + ANRAYLENGTH,
+		Y: 1.6,
+	}, "vertex")
+
+	// -- protractor: it consists of a semicircle with a baseline, ticks every
+	//                ten degrees which are longer every thirty degrees, and
+	//                labels every thirty degrees
+	var protractor []fmt.Stringer
+	if an.protractor {
+		semicircle := components.NewArc("vertex", ANPROTRACTORRADIUS, 0, 180)
+		semicircle.SetOptions("gray")
+		baseline := components.NewLine(fromVertex(180, ANPROTRACTORRADIUS), fromVertex(0, ANPROTRACTORRADIUS))
+		baseline.SetOptions("gray")
+		protractor = append(protractor, semicircle, baseline)
+		for degrees := 10; degrees < 180; degrees += 10 {
+			length := 0.2
+			if degrees%30 == 0 {
+				length = 0.35
+			}
+			tick := components.NewLine(
+				fromVertex(float64(degrees), ANPROTRACTORRADIUS-length),
+				fromVertex(float64(degrees), ANPROTRACTORRADIUS))
+			tick.SetOptions("gray")
+			protractor = append(protractor, tick)
+			if degrees%30 == 0 {
+				protractor = append(protractor, components.NewCoordinatedText(
+					components.NewCoordinate(
+						components.Formula(fromVertex(float64(degrees), ANPROTRACTORRADIUS-0.65)),
+						fmt.Sprintf("degrees%v", degrees)),
+					"gray", fmt.Sprintf(`\tiny %v`, degrees)))
+			}
+		}
+	}
+
+	// -- angle: the first ray is horizontal and the second one has the given
+	//           measure
+	rays := components.NewLine(fromVertex(0, ANRAYLENGTH), "vertex", fromVertex(float64(measure), ANRAYLENGTH))
+	rays.SetOptions("thick")
+	mark := components.NewArc("vertex", ANMARKRADIUS, 0, float64(measure))
+
+	// -- question: the box is wide enough to write the longest classification
+	//              or any measure, and measures are followed by the symbol of
+	//              degrees
+	idx := len(instance.Args) - 1
+	width := 2.0 + float64(len(ANOBTUSE))
+	if an.antype == ANMEASURE {
+		width = 2.0 + 3.0
+	}
+	question := []components.CoordinatedText{
+		components.NewCoordinatedText(
+			components.NewCoordinate(components.Point{
+				X: 0.5 + ANRAYLENGTH,
+				Y: 0.6,
+			}, "answer"),
+			fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width)),
+			an.answer(instance.Solution[idx])),
+	}
+	if an.antype == ANMEASURE {
+		question = append(question, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(answer) + (%v\zerowidth, 0.0)$`, helpers.Ftoa(1.0+width/2.0))),
+				"degrees"),
+			"", `\huge $^\circ$`))
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(components.Point{
+		X: 1.0 + 2.0*ANRAYLENGTH,
+		Y: 2.0 + ANRAYLENGTH,
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the angle
+	anPicture := angleTikZ{
+		Bottom:     bottom,
+		Vertex:     vertex,
+		protractor: protractor,
+		Rays:       rays,
+		Mark:       mark,
+		question:   question,
+		BBox:       bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return anPicture.execute()
+}
+
+// Return TikZ code that represents an angle
+func (an angle) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this angle
+	tpl, err := template.New("angle").Parse(latexAngleCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, an); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
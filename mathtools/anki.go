@@ -0,0 +1,74 @@
+// -*- coding: utf-8 -*-
+// anki.go
+//
+// Description: Provides services for exporting generated problems as Anki
+// flashcards
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:54:37.266423225 (1792112077)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Header of the text files imported by Anki. It declares the separator of the
+// fields, that they are plain text and that the last column contains tags
+const ankiHeader = `#separator:tab
+#html:false
+#tags column:3
+`
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given items as a single field of an Anki text file. Tabs and new
+// lines are substituted by blank spaces so that they do not break the format
+func ankiField(items []string) string {
+
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(strings.Join(items, " "))
+}
+
+// Return the given problems as Anki flashcards in a tab-separated text file
+// which can be directly imported into Anki. The front of every card shows the
+// problem with its masked arguments as "?", and the back shows its solution.
+// Every card is tagged with the type of its problem
+func ExportAnki(problems []ProblemJSON) ([]byte, error) {
+
+	output := bytes.NewBufferString(ankiHeader)
+	for _, problem := range problems {
+		fmt.Fprintf(output, "%v\t%v\t%v\n",
+			ankiField(problem.Args), ankiField(problem.Solution), problem.Probtype)
+	}
+	return output.Bytes(), nil
+}
+
+// given an array of master problems (of any type) return a slice of bytes with
+// the requested problems exported as Anki flashcards. If a problem could not be
+// generated, the contents of the returned data are undefined and an error is
+// raised. The generation stops as soon as the given context is done
+func GenerateAnki(ctx context.Context, problems []MasterProblem) (data []byte, err error) {
+
+	// generate all the problems requested
+	jsonprobs, err := generateJSONProblems(ctx, problems)
+	if err != nil {
+		return data, err
+	}
+
+	// and export them as flashcards
+	return ExportAnki(jsonprobs)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,425 @@
+// -*- coding: utf-8 -*-
+// barchart.go
+//
+// Description: Provides services for automatically creating problems where
+// bar charts have to be either read or drawn
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:58:13.417209846 (1792119493)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Bar charts are either drawn so that students answer questions about them
+// ("read"), or data is given in a table and students have to draw the bars
+// ("draw")
+const (
+	BCREAD string = "read"
+	BCDRAW string = "draw"
+)
+
+// The questions that can be asked about bar charts are the value of a
+// category ("value"), which category has the largest value ("most"), which one
+// has the smallest value ("least") and how much larger one category is than
+// another ("difference")
+const (
+	BCVALUE      string = "value"
+	BCMOST       string = "most"
+	BCLEAST      string = "least"
+	BCDIFFERENCE string = "difference"
+)
+
+// Maximum number of categories and maximum value of every category, so that
+// bar charts fit in the width of a page and the value of every bar can be read
+const (
+	MAXBARCHARTCATEGORIES int = 8
+	MAXBARCHARTVALUE      int = 20
+)
+
+// Questions are shown in rows of the following height, and the data table has
+// cells of the following width and height, all given in centimeters
+const (
+	BCROWHEIGHT  float64 = 1.0
+	BCCELLWIDTH  float64 = 2.0
+	BCCELLHEIGHT float64 = 0.8
+)
+
+// the TikZ code for generating bar charts is shown next. Note that it makes use
+// of LaTeX/TikZ components
+const latexBarChartCode = `\begin{minipage}{\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the bar chart
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZBarChartCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box, of the chart and upper-left
+      % corner of the data table
+      {{.Bottom}}
+      {{.Origin}}
+      {{.Table}}
+
+      % --- Data ------------------------------------------------------------
+
+      % data is given either in a table or with a bar chart
+{{.GetData}}
+      % --- Questions -------------------------------------------------------
+
+      % every question is shown with a box where the answer has to be written
+{{.GetQuestions}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A bar chart problem consists of a number of categories, each one with a
+// random value in the range [geq, leq]. In "read" mode the bar chart is drawn
+// and the student has to answer the given questions, each one among "value",
+// "most", "least" and "difference". In "draw" mode, data is given in a table
+// and the student has to draw the bars over empty axes
+type barChart struct {
+	categories []string
+	geq, leq   int
+	mode       string
+	questions  []string
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw bar
+// charts
+type barChartTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0),
+	// the origin is the lower-left corner of the chart, and the table is the
+	// upper-left corner of the data table
+	Bottom, Origin, Table components.Coordinate
+
+	// data is shown with a bar chart and, in "draw" mode, also with a table
+	data []fmt.Stringer
+
+	// the questions are shown with their boxes
+	questions []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- barChartTikZ
+
+// Generates the TikZ code necessary for drawing all data
+func (tikz barChartTikZ) GetData() string {
+
+	// Use a btyes buffer to append the strings of each element
+	var output bytes.Buffer
+
+	for _, item := range tikz.data {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// data
+	return output.String()
+}
+
+// Generates the TikZ code necessary for drawing all questions
+func (tikz barChartTikZ) GetQuestions() string {
+
+	// Use a btyes buffer to append the strings of each question
+	var output bytes.Buffer
+
+	for _, item := range tikz.questions {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// questions
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz barChartTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("barChartTikZ").Parse(tikZBarChartCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- barChart
+
+// return the text shown for the given question, which is given as in the
+// arguments of the JSON problem, i.e., with the names of the categories it
+// refers to separated by colons
+func (bc barChart) text(question string) string {
+
+	fields := strings.Split(question, ":")
+	switch fields[0] {
+	case BCVALUE:
+		return fields[1]
+	case BCDIFFERENCE:
+		return fmt.Sprintf("%v $-$ %v", fields[1], fields[2])
+	}
+	return fields[0]
+}
+
+// return the instance of a specific bar chart problem that can be marshalled
+// in JSON format. The receiver is assumed to have been fully verified so that
+// it should be consistent.
+//
+// The result is given with the mode followed by the name of every category and
+// its value. In "read" mode, every question is followed by its answer, where
+// questions about the value of a category are given as "value:category", and
+// questions about the difference between two categories are given as
+// "difference:category1:category2", the first one being the largest. Answers
+// are shown as "?" in the arguments as they have to be guessed by the student.
+// In "draw" mode there are no questions, as the bars have to be drawn
+func (bc barChart) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if len(bc.categories) < 2 || len(bc.categories) > MAXBARCHARTCATEGORIES {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate bar charts with %v categories", len(bc.categories))
+	}
+	if bc.geq < 0 || bc.geq > bc.leq || bc.leq < 1 || bc.leq > MAXBARCHARTVALUE {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate bar charts with values in the range [%v, %v]", bc.geq, bc.leq)
+	}
+
+	// randomly determine the values of all categories. If the categories with
+	// the largest or smallest value have to be guessed, they have to be unique
+	unique := bc.mode == BCREAD && (helpers.Find(BCMOST, bc.questions) || helpers.Find(BCLEAST, bc.questions))
+	values, most, least, err := categoryValues(len(bc.categories), bc.geq, bc.leq, unique, rnd)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+
+	// create two slices: one for storing the instance of this problem where
+	// the answers that should be given by the student are marked with
+	// question marks "?"; and another one with the full solution
+	args := []string{bc.mode}
+	for idx, category := range bc.categories {
+		args = append(args, category, strconv.Itoa(values[idx]))
+	}
+	solution := append([]string{}, args...)
+	if bc.mode == BCREAD {
+		for _, question := range bc.questions {
+			var answer string
+			switch question {
+			case BCVALUE:
+				idx := rnd.Intn(len(bc.categories))
+				question, answer = BCVALUE+":"+bc.categories[idx], strconv.Itoa(values[idx])
+			case BCMOST:
+				answer = bc.categories[most]
+			case BCLEAST:
+				answer = bc.categories[least]
+			case BCDIFFERENCE:
+				perm := rnd.Perm(len(bc.categories))
+				first, second := perm[0], perm[1]
+				if values[first] < values[second] {
+					first, second = second, first
+				}
+				question = BCDIFFERENCE + ":" + bc.categories[first] + ":" + bc.categories[second]
+				answer = strconv.Itoa(values[first] - values[second])
+			}
+			args = append(args, question, "?")
+			solution = append(solution, question, answer)
+		}
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "BarChart",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this bar chart problem using
+// TikZ components
+func (bc barChart) GetTikZPicture() (string, error) {
+
+	// -- data: randomly determine the values of all categories. For this, the
+	//          service that generates problems is the one that can marshal
+	//          them into JSON format. A question mark is an answer that has to
+	//          be guessed by the student
+	instance, err := bc.next(annotated(bc))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid bar chart: %v", err)
+	}
+	var values []int
+	for idx := range bc.categories {
+		value, _ := strconv.Atoi(instance.Args[2+2*idx])
+		values = append(values, value)
+	}
+
+	// the vertical axis is labeled at every unit unless values are large
+	step := 1
+	if bc.leq > 10 {
+		step = 2
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box. The chart is shown
+	// above the questions, if any, and to the right of the data table, if
+	// any. The table is as high as the chart
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	nbquestions := (len(instance.Args) - 1 - 2*len(bc.categories)) / 2
+	x, y := 1.0, BCROWHEIGHT*float64(nbquestions)+1.0
+	if bc.mode == BCDRAW {
+		x += 2.0*BCCELLWIDTH + 0.5
+	}
+	origin := components.NewCoordinate(components.Point{
+		X: x,
+		Y: y,
+	}, "origin")
+	table := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: y + components.DEFAULTBARCHARTHEIGHT,
+	}, "table")
+
+	// -- data: in "draw" mode, data is given in a table and bars are drawn
+	//          only when answers are shown
+	var data []fmt.Stringer
+	if bc.mode == BCDRAW {
+		texts, framed := [][]string{}, [][]bool{}
+		for idx, category := range bc.categories {
+			texts = append(texts, []string{`\large ` + category, `\large ` + strconv.Itoa(values[idx])})
+			framed = append(framed, []bool{true, true})
+		}
+		data = append(data, components.NewGrid("table", BCCELLWIDTH, BCCELLHEIGHT, texts, framed))
+		if !bc.showAnswers() {
+			values = nil
+		}
+	}
+	chart := components.NewBarChart("origin", bc.categories, values, bc.leq, step, components.DEFAULTBARCHARTHEIGHT)
+	chart.SetOptions("fill=lightgray")
+	data = append(data, chart)
+
+	// -- questions: they are shown from top to bottom below the chart, each
+	//              one with a box wide enough for its answer
+	var questions []components.CoordinatedText
+	namewidth, xmax := 0.0, x+chart.GetWidth()
+	for _, category := range bc.categories {
+		namewidth = helpers.Max(namewidth, float64(len([]rune(category))))
+	}
+	xanswer := 0.6 + 0.25*(2.0*namewidth+3.0)
+	for idx := 0; idx < nbquestions; idx++ {
+		offset := 1 + 2*(len(bc.categories)+idx)
+		y := BCROWHEIGHT * (0.5 + float64(nbquestions-1-idx))
+		width, answer := 2.0+namewidth, instance.Solution[1+offset]
+		if question := strings.Split(instance.Args[offset], ":")[0]; question == BCVALUE || question == BCDIFFERENCE {
+			width = 2.0 + float64(helpers.NbDigits(bc.leq))
+		}
+		xmax = helpers.Max(xmax, xanswer+0.5*width)
+		if bc.showAnswers() {
+			answer = `\Large ` + answer
+		} else {
+			answer = ""
+		}
+		questions = append(questions,
+			components.NewCoordinatedText(
+				components.NewCoordinate(components.Formula(fromBottom(0.2, y)), fmt.Sprintf("question%v", idx)),
+				"anchor=west", `\large `+bc.text(instance.Args[offset])+":"),
+			components.NewCoordinatedText(
+				components.NewCoordinate(components.Formula(fromBottom(xanswer, y)), fmt.Sprintf("answer%v", idx)),
+				fmt.Sprintf(`anchor=west, rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+					helpers.Ftoa(width)),
+				answer))
+	}
+
+	// -- bounding box: it has to contain the boxes of the questions as well,
+	//                 whose width is estimated assuming that every digit is
+	//                 half a centimeter wide
+	right := components.NewCoordinate(components.Point{
+		X: xmax + 0.5,
+		Y: y + components.DEFAULTBARCHARTHEIGHT + 0.5,
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the bar
+	// chart
+	bcPicture := barChartTikZ{
+		Bottom:    bottom,
+		Origin:    origin,
+		Table:     table,
+		data:      data,
+		questions: questions,
+		BBox:      bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return bcPicture.execute()
+}
+
+// Return TikZ code that represents a bar chart problem
+func (bc barChart) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this bar chart
+	tpl, err := template.New("barChart").Parse(latexBarChartCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, bc); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -14,11 +14,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
+	"strings"
 	"text/template"
-	"time"
 
 	"github.com/clinaresl/mathprob/helpers"
 	"github.com/clinaresl/mathprob/mathtools/components"
@@ -36,6 +35,23 @@ const (
 	BOOPERAND
 )
 
+// Basic operations can be shown either in columns, with the operands one above
+// the other ("vertical"), or in one single line, e.g., "47 + 38 = ?"
+// ("horizontal")
+const (
+	BOVERTICAL   string = "vertical"
+	BOHORIZONTAL string = "horizontal"
+)
+
+// Carries (in additions) and borrows (in subtractions) can be allowed in any
+// column ("any"), requested in at least one column ("required") or forbidden
+// in all of them ("forbidden")
+const (
+	BOCARRYANY       string = "any"
+	BOCARRYREQUIRED  string = "required"
+	BOCARRYFORBIDDEN string = "forbidden"
+)
+
 // the TikZ code for generating arbitrary basic operations is shown next. Note that it
 // makes use of LaTeX/TikZ components
 const latexBasicOperationCode = `\begin{minipage}{0.25\linewidth}
@@ -83,6 +99,12 @@ const tikZBasicOperationCode = `% --- Coordinates ------------------------------
 
       % ---------------------------------------------------------------------
 
+      % --- Scaffold --------------------------------------------------------
+
+      % in subtractions, small boxes can be shown above every column of the
+      % first operand for recording borrows and, in additions, above every
+      % column that might receive a carry
+{{.GetScaffold}}
       % --- Bounding Box ----------------------------------------------------
 
       % the distance between the answer box and the end of the bounding box is
@@ -100,6 +122,37 @@ const tikZBasicOperationCode = `% --- Coordinates ------------------------------
       % ---------------------------------------------------------------------
 `
 
+// basic operations shown in one single line take more room than those shown
+// in columns
+const latexHorizontalBasicOperationCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the basic operation
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZHorizontalBasicOperationCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Operation -------------------------------------------------------
+
+      % all operands and operators are shown from left to right followed by
+      % the equal sign and the result. Unknown numbers are shown within a box
+{{.GetItems}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
 // types
 // ----------------------------------------------------------------------------
 
@@ -111,12 +164,56 @@ const tikZBasicOperationCode = `% --- Coordinates ------------------------------
 //    0: all operands are given and the student has to guess the result
 //    1: all operands but one are shown but the result can be seen. The student
 //    has to provide the value of the missing operand
+//
+// Subtractions can show a scaffold with small boxes above every column of the
+// first operand where students record borrows. Likewise, additions can show
+// small boxes above every column where students write carries. Carries (in
+// additions) and borrows (in subtractions) can be also required in at least
+// one column or forbidden altogether. In both cases, operands are built column
+// by column unless they are bounded or their digits are constrained.
+//
+// Operands can be given with a number of decimal digits. In this case, the
+// number of digits of the operands and the result refer to all their digits,
+// i.e., those of both the integer and the decimal part. Results of additions
+// and subtractions have as many decimal digits as their operands, whereas those
+// of multiplications have the sum of the decimal digits of all operands.
+//
+// By default, operands and results are positive. If negative numbers are
+// allowed, operands are randomly negated and results can be negative as well.
+// The unary minus is not counted in the number of digits.
+//
+// Operands can be bounded in the range [geq, leq]: either one bound is given
+// for all operands or one for every operand, and a null upper bound means that
+// no bound is given at all. Bounds are only acknowledged in operations with
+// neither decimal nor negative numbers. Additionally, the digits of all
+// operands can be constrained (see digitConstraint).
+//
+// All operands have the same number of digits unless a different number is
+// given for every operand. In this case, the number of digits of the basic
+// operation is the one of its widest operand, and operands are right aligned
+// when shown in columns.
+//
+// Basic operations are shown either in columns ("vertical") or in one single
+// line ("horizontal")
 type basicOperation struct {
 	botype       int
 	operator     string
 	nboperands   int
 	nbdigitsop   int
+	nbdigitsops  []int
 	nbdigitsrslt int
+	scaffold     bool
+	carrybox     bool
+	carry        string
+	nbdecimals   int
+	negative     bool
+	layout       string
+	geq          []int
+	leq          []int
+	constraint   digitConstraint
+
+	// generated problems are recorded when solutions are requested
+	recorder
 }
 
 // The following struct stores all the information necessary to draw basic
@@ -143,6 +240,10 @@ type basicOperationTikZ struct {
 	OperatorCoord components.Coordinate
 	Operator      components.LabeledText
 
+	// The boxes for recording borrows or writing carries, if any, are located
+	// above the columns of the first operand
+	scaffold []components.CoordinatedText
+
 	// the bounding box surrounding all the necessary area for solving the
 	// exercise is defined next and it is computed with two formulas that
 	// specify the lower left and upper right corners
@@ -152,6 +253,62 @@ type basicOperationTikZ struct {
 	Result components.LabeledText
 }
 
+// The following struct stores all the information necessary to draw basic
+// operations in one single line
+type horizontalBasicOperationTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// all operands, operators, the equal sign and the result are shown from
+	// left to right
+	items []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given number divided by ten to the given number of decimals, with
+// exactly that number of decimal digits
+func formatDecimal(number, decimals int) string {
+
+	if number < 0 {
+		return "-" + formatDecimal(-number, decimals)
+	}
+	if decimals == 0 {
+		return fmt.Sprintf("%v", number)
+	}
+	digits := fmt.Sprintf("%0*d", decimals+1, number)
+	return digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+}
+
+// return the range of numbers with the given number of digits which are also
+// in the range [geq, leq]. If leq is null, no upper bound is considered. If no
+// number satisfies both constraints, the lower bound returned is strictly
+// larger than the upper bound
+func operandRange(nbdigits, geq, leq int) (lower, upper int) {
+
+	lower = int(math.Pow10(nbdigits - 1))
+	upper = int(math.Pow10(nbdigits)) - 1
+	if geq > lower {
+		lower = geq
+	}
+	if leq > 0 && leq < upper {
+		upper = leq
+	}
+	return
+}
+
+// return a random number in the range [lower, upper] using the given source of
+// random numbers
+func randRange(rnd *rand.Rand, lower, upper int) int {
+	return lower + rnd.Int()%(upper-lower+1)
+}
+
 // methods
 // ----------------------------------------------------------------------------
 
@@ -179,25 +336,80 @@ func (tikz basicOperationTikZ) GetOperands() string {
 	return output.String()
 }
 
+// Generates the TikZ code necessary for drawing the boxes used for recording
+// borrows or writing carries, if any
+func (tikz basicOperationTikZ) GetScaffold() string {
+
+	// Use a btyes buffer to append the strings of each box
+	var output bytes.Buffer
+
+	for _, box := range tikz.scaffold {
+		fmt.Fprintf(&output, "      %v\n", box)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// boxes
+	return output.String()
+}
+
 // Return the LaTeX/TikZ commands that show up the picture stored in the
 // receiver
-func (tikz basicOperationTikZ) execute() string {
+func (tikz basicOperationTikZ) execute() (string, error) {
 
 	// create a template with the TikZ code for showing this picture
 	tpl, err := template.New("basicOperationTikZ").Parse(tikZBasicOperationCode)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- horizontalBasicOperationTikZ
+
+// Generates the TikZ code necessary for drawing all items of the basic
+// operation
+func (tikz horizontalBasicOperationTikZ) GetItems() string {
+
+	// Use a btyes buffer to append the strings of each item
+	var output bytes.Buffer
+
+	for _, item := range tikz.items {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// items
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz horizontalBasicOperationTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("horizontalBasicOperationTikZ").Parse(tikZHorizontalBasicOperationCode)
+	if err != nil {
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the execution of
 	// the template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, tikz); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 // -- basicOperation
@@ -210,21 +422,31 @@ func (tikz basicOperationTikZ) execute() string {
 //    1. The first string is the operation to perform: "+", "-", "*" or "/"
 //    2. First, all operands are given
 //    3. The last string is the result
-func (bo basicOperation) generateJSONProblem() (problemJSON, error) {
+func (bo basicOperation) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
 
-	rand.Seed(time.Now().UTC().UnixNano())
 
 	// first, ensure that the number of digits both for the operands and the
-	// result are compatible
+	// result are compatible. For this, the largest and smallest values of all
+	// operands, and the sum of their number of digits are computed
+	var largest, smallest, others, nbdigits int
+	for i := 0; i < bo.nboperands; i++ {
+		largest += int(math.Pow(10, float64(bo.width(i)))) - 1
+		smallest += int(math.Pow(10, float64(bo.width(i)-1)))
+		if i > 0 {
+			others += int(math.Pow(10, float64(1+bo.width(i))))
+		}
+		nbdigits += bo.width(i)
+	}
 	switch bo.operator {
 	case "+":
 
 		// no math expression! I just compute the upper and lower bound on the
-		// number of digits in the result and compare it to the value given
-		if helpers.NbDigits(bo.nboperands*int(math.Pow(10, float64(bo.nbdigitsop))-1)) < bo.nbdigitsrslt ||
-			helpers.NbDigits(bo.nboperands*int(math.Pow(10, float64(bo.nbdigitsop-1)))) > bo.nbdigitsrslt {
-			return problemJSON{}, fmt.Errorf("It is not possible to generate summations with %v digits using %v operands with %v digits each",
-				bo.nbdigitsrslt, bo.nboperands, bo.nbdigitsop)
+		// number of digits in the result and compare it to the value given.
+		// If operands can be negative, the result can have one single digit
+		if helpers.NbDigits(largest) < bo.nbdigitsrslt ||
+			(!bo.negative && helpers.NbDigits(smallest) > bo.nbdigitsrslt) {
+			return ProblemJSON{}, fmt.Errorf("It is not possible to generate summations with %v digits using %v operands with %v digits",
+				bo.nbdigitsrslt, bo.nboperands, bo.describeWidths())
 		}
 
 	case "-":
@@ -237,38 +459,38 @@ func (bo basicOperation) generateJSONProblem() (problemJSON, error) {
 		// number of digits in the result is the same as if we are summing up
 		// all operands but the first one. As for the lower bound in the number
 		// of digits it is clearly one
-		if helpers.NbDigits((bo.nboperands-1)*int(math.Pow(10, float64(1+bo.nbdigitsop)))) < bo.nbdigitsrslt ||
+		if helpers.NbDigits(others) < bo.nbdigitsrslt ||
 			1 > bo.nbdigitsrslt {
-			return problemJSON{}, fmt.Errorf("It is not possible to generate subtractions with %v digits using %v operands with %v digits each",
-				bo.nbdigitsrslt, bo.nboperands, bo.nbdigitsop)
+			return ProblemJSON{}, fmt.Errorf("It is not possible to generate subtractions with %v digits using %v operands with %v digits",
+				bo.nbdigitsrslt, bo.nboperands, bo.describeWidths())
 		}
 
 	case "*":
 
 		// this is easy ...
-		if bo.nboperands*bo.nbdigitsop < bo.nbdigitsrslt ||
-			1+bo.nboperands*(bo.nbdigitsop-1) > bo.nbdigitsrslt {
-			return problemJSON{}, fmt.Errorf("It is not possible to generate multiplications with %v digits using %v operands with %v digits each",
-				bo.nbdigitsrslt, bo.nboperands, bo.nbdigitsop)
+		if nbdigits < bo.nbdigitsrslt ||
+			1+nbdigits-bo.nboperands > bo.nbdigitsrslt {
+			return ProblemJSON{}, fmt.Errorf("It is not possible to generate multiplications with %v digits using %v operands with %v digits",
+				bo.nbdigitsrslt, bo.nboperands, bo.describeWidths())
 		}
 
 	case "/":
 
 		// Divisions can consist only of two arguments
 		if bo.nboperands > 2 {
-			return problemJSON{}, errors.New("Divisions can consist only of two items!")
+			return ProblemJSON{}, errors.New("Divisions can consist only of two items!")
 		}
 
 		// and considering that both operands have the same number of digits,
 		// the result necessarily consists of one single digit
 		if bo.nbdigitsrslt != 1 {
-			return problemJSON{}, errors.New("Divisions can only generate results with 1 digit")
+			return ProblemJSON{}, errors.New("Divisions can only generate results with 1 digit")
 		}
 	}
 
 	// in case type 1 was selected, randomly choose any location among all
 	// operands
-	pos := 1 + rand.Int()%bo.nboperands
+	pos := 1 + rnd.Int()%bo.nboperands
 
 	// next, create the instance.
 	var result int
@@ -285,20 +507,50 @@ func (bo basicOperation) generateJSONProblem() (problemJSON, error) {
 	// and now randomly generate operands of the given width until a result of
 	// the desired width is generated. Also, basic operations are intended for
 	// very beginners and thus, negative values are intentionally removed
-	for helpers.NbDigits(result) != bo.nbdigitsrslt ||
-		result <= 0 {
+	// unless they are explicitly allowed. If no result is found after a
+	// maximum number of attempts, the parameters are deemed to be
+	// incompatible
+	for attempt := 0; bo.nbdigits(result) != bo.nbdigitsrslt ||
+		result == 0 || (result < 0 && !bo.negative) ||
+		!bo.regroups(solution[1:1+bo.nboperands]) ||
+		!bo.admits(solution[1:1+bo.nboperands]); attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate a basic operation with operator '%v', %v operands with %v digits and a positive result with %v digits (carries: %v) after %v attempts",
+				bo.operator, bo.nboperands, bo.describeWidths(), bo.nbdigitsrslt, bo.carry, MAXGENERATIONATTEMPTS)
+		}
 
-		// generate all operands first and write them tentatively in the
-		// solution slice
+		// generate all operands first within their range and write them
+		// tentatively in the solution slice. If carries are either required
+		// or forbidden, operands are built column by column unless they are
+		// bounded or constrained, as otherwise they could fall out of range
+		var columns []int
+		if bo.carry != BOCARRYANY && len(bo.geq) == 0 && len(bo.leq) == 0 &&
+			len(bo.constraint.excluded) == 0 && len(bo.constraint.patterns) == 0 {
+			columns = bo.columnOperands(rnd)
+		}
 		for i := 0; i < bo.nboperands; i++ {
-			solution[1+i] = fmt.Sprintf("%v", helpers.RandN(bo.nbdigitsop))
+			var value int
+			if columns != nil {
+				value = columns[i]
+			} else if bo.constraint.constrains(bo.width(i)) {
+				value = bo.constraint.random(rnd, bo.width(i))
+			} else {
+				lower, upper := bo.operandRange(i)
+				value = randRange(rnd, lower, upper)
+			}
+			if bo.negative && rnd.Intn(2) == 0 {
+				value = -value
+			}
+			solution[1+i] = formatDecimal(value, bo.nbdecimals)
 		}
 
 		// compute the specified operation over these items. First initialize
-		// the result to the value of the first operand
-		result, _ = helpers.Atoi(solution[1])
+		// the result to the value of the first operand. Decimal numbers are
+		// operated as integers by ignoring their decimal point
+		result, _ = helpers.Atoi(strings.Replace(solution[1], ".", "", 1))
 		for i := 1; i < bo.nboperands; i++ {
-			value, _ := helpers.Atoi(solution[1+i])
+			value, _ := helpers.Atoi(strings.Replace(solution[1+i], ".", "", 1))
 			switch bo.operator {
 			case "+":
 				result += value
@@ -311,7 +563,7 @@ func (bo basicOperation) generateJSONProblem() (problemJSON, error) {
 			}
 		}
 	}
-	solution[1+bo.nboperands] = fmt.Sprintf("%v", result)
+	solution[1+bo.nboperands] = formatDecimal(result, bo.decimals())
 
 	// now, copy the solution to the args but ...
 	args := make([]string, 2+bo.nboperands)
@@ -328,29 +580,487 @@ func (bo basicOperation) generateJSONProblem() (problemJSON, error) {
 		args[1+bo.nboperands] = "?"
 	}
 
-	return problemJSON{
+	return ProblemJSON{
 		Probtype: "BasicOperation",
 		Args:     args,
 		Solution: solution,
+		Steps:    bo.steps(solution[1 : 1+bo.nboperands]),
 	}, nil
 }
 
+// return the intermediate steps of this basic operation over the given
+// operands, i.e., the partial result obtained after operating every operand
+// with the previous ones. Operations with only two operands have no
+// intermediate steps
+func (bo basicOperation) steps(operands []string) (steps []string) {
+
+	if len(operands) <= 2 {
+		return
+	}
+
+	// decimal numbers are operated as integers by ignoring their decimal
+	// point, though partial products have more decimal digits than the
+	// operands
+	result, _ := helpers.Atoi(strings.Replace(operands[0], ".", "", 1))
+	decimals := bo.nbdecimals
+	for _, operand := range operands[1:] {
+		value, _ := helpers.Atoi(strings.Replace(operand, ".", "", 1))
+		previous := formatDecimal(result, decimals)
+		switch bo.operator {
+		case "+":
+			result += value
+		case "-":
+			result -= value
+		case "*":
+			result *= value
+			decimals += bo.nbdecimals
+		}
+		steps = append(steps, solutionStep(previous, bo.operator, operand, formatDecimal(result, decimals)))
+	}
+	return
+}
+
+// set the difficulty, skill and tags of the given problem generated by this
+// basic operation. Problems are harder with more operands, more digits,
+// carries or borrows (and even more so if they happen in most columns), zeros in
+// the operands of subtractions and multiplications, decimal or negative
+// numbers, and also when an operand has to be found instead of the result
+func (bo basicOperation) annotate(problem *ProblemJSON) {
+
+	operands := problem.Solution[1 : 1+bo.nboperands]
+	negative := false
+	for _, number := range problem.Solution[1:] {
+		negative = negative || strings.HasPrefix(number, "-")
+	}
+
+	score := bo.nbdigitsop
+	name := map[string]string{"+": "addition", "-": "subtraction", "*": "multiplication", "/": "division"}[bo.operator]
+	problem.Skill = "arithmetic." + name
+	problem.Tags = []string{name, fmt.Sprintf("%v-digits", bo.nbdigitsop)}
+	if bo.operator == "*" || bo.operator == "/" {
+		score++
+	}
+	if !negative && (bo.operator == "+" || bo.operator == "-") && bo.carries(operands) {
+		score++
+		problem.Tags = append(problem.Tags, map[string]string{"+": "carrying", "-": "borrowing"}[bo.operator])
+
+		// regrouping in most columns is harder than in just one
+		if regroupings := bo.regroupings(operands); regroupings > 1 && 2*regroupings >= bo.nbdigitsop {
+			score++
+		}
+	}
+	if (bo.operator == "-" || bo.operator == "*") && nbZeros(operands) > 0 {
+		score++
+		problem.Tags = append(problem.Tags, "zeros")
+	}
+	if bo.nboperands > 2 {
+		score++
+		problem.Tags = append(problem.Tags, "multiple-operands")
+	}
+	if bo.nbdecimals > 0 {
+		score++
+		problem.Tags = append(problem.Tags, "decimals")
+	}
+	if negative {
+		score++
+		problem.Tags = append(problem.Tags, "negative")
+	}
+	if bo.botype == BOOPERAND {
+		score++
+		problem.Tags = append(problem.Tags, "missing-operand")
+	}
+	problem.Difficulty = difficulty(score)
+}
+
+// return the bounds given to the i-th operand of this basic operation, starting
+// from 0. Null bounds are returned if none were given
+func (bo basicOperation) bounds(i int) (geq, leq int) {
+
+	if len(bo.geq) == 1 {
+		geq = bo.geq[0]
+	} else if i < len(bo.geq) {
+		geq = bo.geq[i]
+	}
+	if len(bo.leq) == 1 {
+		leq = bo.leq[0]
+	} else if i < len(bo.leq) {
+		leq = bo.leq[i]
+	}
+	return
+}
+
+// return the range of values of the i-th operand of this basic operation,
+// starting from 0
+func (bo basicOperation) operandRange(i int) (lower, upper int) {
+
+	geq, leq := bo.bounds(i)
+	return operandRange(bo.width(i), geq, leq)
+}
+
+// return the number of digits of the i-th operand of this basic operation,
+// starting from 0
+func (bo basicOperation) width(i int) int {
+
+	if i < len(bo.nbdigitsops) {
+		return bo.nbdigitsops[i]
+	}
+	return bo.nbdigitsop
+}
+
+// return a description of the number of digits of the operands of this basic
+// operation to be used in error messages
+func (bo basicOperation) describeWidths() string {
+
+	if bo.nbdigitsops == nil {
+		return fmt.Sprintf("%v digits each", bo.nbdigitsop)
+	}
+	return fmt.Sprintf("%v", bo.nbdigitsops)
+}
+
+// return true if all the given operands are in their range and their digits
+// satisfy the constraint of this basic operation, and false otherwise
+func (bo basicOperation) admits(operands []string) bool {
+
+	for i, operand := range operands {
+		value, _ := helpers.Atoi(strings.Replace(strings.TrimPrefix(operand, "-"), ".", "", 1))
+		if lower, upper := bo.operandRange(i); value < lower || value > upper ||
+			!bo.constraint.admits(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// return the number of decimal digits of the result of this basic operation
+func (bo basicOperation) decimals() int {
+
+	if bo.operator == "*" {
+		return bo.nboperands * bo.nbdecimals
+	}
+	return bo.nbdecimals
+}
+
+// return the number of digits used for writing the given result, including the
+// leading zero of decimal numbers less than one but not the unary minus
+func (bo basicOperation) nbdigits(result int) int {
+	if result < 0 {
+		result = -result
+	}
+	return int(helpers.Max(float64(helpers.NbDigits(result)), float64(1+bo.decimals())))
+}
+
+// return the LaTeX code for writing the given number right aligned in a column
+// with the given number of digits. Decimal numbers are written with the
+// decimal separator of the current locale as wide as a digit and, likewise,
+// the unary minus of negative numbers takes the width of a digit. As numbers
+// might have a different number of digits, they are padded to the left with
+// invisible digits so that their columns are aligned. If neither decimal nor
+// negative numbers are allowed and all operands have the same number of
+// digits, numbers are returned verbatim
+func (bo basicOperation) digits(number string, width int) string {
+
+	if bo.nbdecimals == 0 && !bo.negative && bo.nbdigitsops == nil {
+		return number
+	}
+	padding := ""
+	if width > len(number) {
+		padding = `\phantom{` + strings.Repeat("0", width-len(number)) + "}"
+	}
+	number = strings.Replace(number, ".", `\makebox[\zerowidth]{`+currentLocale().DecimalSeparator+`}`, 1)
+	return padding + strings.Replace(number, "-", `\makebox[\zerowidth]{$-$}`, 1)
+}
+
+// return true if computing this basic operation over the given operands
+// requires a carry (in additions) or a borrow (in subtractions) in any column
+// and false otherwise
+func (bo basicOperation) carries(operands []string) bool {
+	return bo.regroupings(operands) > 0
+}
+
+// return the number of columns where computing this basic operation over the
+// given operands requires a carry (in additions) or a borrow (in subtractions)
+func (bo basicOperation) regroupings(operands []string) (count int) {
+
+	// decimal points are ignored as all operands have the same number of
+	// decimal digits
+	digits := make([]string, len(operands))
+	for idx, operand := range operands {
+		digits[idx] = strings.Replace(operand, ".", "", 1)
+	}
+	operands = digits
+
+	// return the digit of the given operand in a column, which is null if
+	// the operand has not so many digits
+	digit := func(operand string, column int) int {
+		if column >= len(operand) {
+			return 0
+		}
+		return int(operand[len(operand)-1-column] - '0')
+	}
+
+	// process all columns from the rightmost one
+	for column := 0; column < bo.nbdigitsop; column++ {
+
+		// compute the sum of the digits of all operands but the first one in
+		// this column
+		first := digit(operands[0], column)
+		others := 0
+		for _, operand := range operands[1:] {
+			others += digit(operand, column)
+		}
+
+		// additions carry when the sum of all digits exceeds nine, whereas
+		// subtractions borrow when the digits of the other operands exceed
+		// the digit of the first one
+		if (bo.operator == "+" && first+others > 9) ||
+			(bo.operator == "-" && first < others) {
+			count++
+		}
+	}
+	return
+}
+
+// return true if the given operands satisfy the carries (in additions) or
+// borrows (in subtractions) requested in this basic operation, and false
+// otherwise
+func (bo basicOperation) regroups(operands []string) bool {
+
+	switch bo.carry {
+	case BOCARRYREQUIRED:
+		return bo.carries(operands)
+	case BOCARRYFORBIDDEN:
+		return !bo.carries(operands)
+	}
+	return true
+}
+
+// return the digits of the given column of all operands of this basic
+// operation, drawn at random until they satisfy the given condition. Leading
+// digits are never null, and the digits of operands with less digits than the
+// column are null. If no digits satisfy the condition after a maximum number of
+// attempts, the last ones drawn are returned anyway
+func (bo basicOperation) columnDigits(rnd *rand.Rand, column int, condition func(first, others int) bool) []int {
+
+	digits := make([]int, bo.nboperands)
+	for attempt := 0; attempt < MAXGENERATIONATTEMPTS; attempt++ {
+		others := 0
+		for i := range digits {
+			switch {
+			case column >= bo.width(i):
+				digits[i] = 0
+			case column == bo.width(i)-1:
+				digits[i] = randRange(rnd, 1, 9)
+			default:
+				digits[i] = randRange(rnd, 0, 9)
+			}
+			if i > 0 {
+				others += digits[i]
+			}
+		}
+		if condition(digits[0], others) {
+			break
+		}
+	}
+	return digits
+}
+
+// return random operands of this basic operation built column by column so
+// that they satisfy the carries (in additions) or borrows (in subtractions)
+// requested. If they are required, one column where at least two operands
+// have digits is randomly chosen to carry (or borrow) whereas the digits of the
+// others are drawn at random. Borrows are never forced in the leading column of
+// the first operand, as the result would be negative, and neither are carries
+// in the leading column unless the result has more digits than the operands
+func (bo basicOperation) columnOperands(rnd *rand.Rand) []int {
+
+	// additions carry when the sum of all digits exceeds nine, whereas
+	// subtractions borrow when the digits of the other operands exceed the
+	// digit of the first one
+	carry := func(first, others int) bool {
+		if bo.operator == "+" {
+			return first+others > 9
+		}
+		return first < others
+	}
+	noCarry := func(first, others int) bool { return !carry(first, others) }
+	free := func(first, others int) bool { return true }
+
+	// compute the number of columns where carries (or borrows) can be forced
+	// starting from the units. Additions need two digits at least, whereas
+	// subtractions need a digit of the first operand and another one of the
+	// other operands
+	forced := -1
+	if bo.carry == BOCARRYREQUIRED {
+		var widest, second int
+		for i := 0; i < bo.nboperands; i++ {
+			if bo.width(i) > widest {
+				widest, second = bo.width(i), widest
+			} else if bo.width(i) > second {
+				second = bo.width(i)
+			}
+		}
+		nbcols := second
+		if nbcols == bo.nbdigitsop && bo.nbdigitsrslt <= bo.nbdigitsop {
+			nbcols--
+		}
+		if bo.operator == "-" {
+			nbcols = 0
+			for i := 1; i < bo.nboperands; i++ {
+				nbcols = int(helpers.Max(float64(nbcols), float64(bo.width(i))))
+			}
+			nbcols = helpers.Min(nbcols, bo.width(0)-1)
+		}
+		if nbcols > 0 {
+			forced = rnd.Intn(nbcols)
+		}
+	}
+
+	operands := make([]int, bo.nboperands)
+	for column, power := 0, 1; column < bo.nbdigitsop; column, power = column+1, power*10 {
+		condition := free
+		if bo.carry == BOCARRYFORBIDDEN {
+			condition = noCarry
+		} else if column == forced {
+			condition = carry
+		}
+		for i, digit := range bo.columnDigits(rnd, column, condition) {
+			operands[i] += digit * power
+		}
+	}
+	return operands
+}
+
+// return the LaTeX code of the given operator
+func operatorLaTeX(operator string) string {
+
+	switch operator {
+	case "*":
+		return `$\times$`
+	case "/":
+		return `$\div$`
+	}
+	return operator
+}
+
+// return a valid LaTeX/TikZ representation of this basic operation shown in
+// one single line using TikZ components
+func (bo basicOperation) horizontalTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the values of the operands. For this, the
+	//              service that generates problems is the one that can marshal
+	//              them into JSON format
+	instance, err := bo.next(annotated(bo))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid basic operation: %v", err)
+	}
+
+	// compute the number of digits required to write any operand or the
+	// result, which is the width of the boxes of unknown numbers. Decimal
+	// points and the unary minus are as wide as a digit
+	nbdigits := helpers.Max(float64(bo.nbdigitsop), float64(bo.nbdigitsrslt))
+	if bo.nbdecimals > 0 {
+		nbdigits += 1
+	}
+	if bo.negative {
+		nbdigits += 1
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- items: all of them are written from left to right. Every item is
+	//           located at the given horizontal distance (in digits) from the
+	//           bottom coordinate and it takes the given width, also in digits.
+	//           Negative operands but the first one are written within
+	//           parentheses
+	var items []components.CoordinatedText
+	x := 0.5
+	add := func(text, options string, width float64) {
+		items = append(items, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight + 0.5\baselineskip)$`,
+					helpers.Ftoa(x))),
+				fmt.Sprintf("item%v", len(items))),
+			options, text))
+		x += width + 0.5
+	}
+	box := fmt.Sprintf(`anchor=west, rounded corners, rectangle, minimum width=%v*\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+		helpers.Ftoa(2.0+nbdigits))
+	for idx, item := range instance.Args[1:] {
+
+		// operands are preceded by the operator, and the result by the equal
+		// sign
+		if idx == len(instance.Args)-2 {
+			add(`\huge $=$`, "anchor=west", 1.0)
+		} else if idx > 0 {
+			add(`\huge `+operatorLaTeX(instance.Args[0]), "anchor=west", 1.0)
+		}
+
+		// unknown numbers are shown within a box, whereas the others are
+		// written verbatim
+		if item == "?" {
+			add(bo.answer(strings.Replace(localizeDecimal(instance.Solution[1+idx]), "-", `$-$`, 1)), box, 2.0+nbdigits)
+		} else {
+			if strings.HasPrefix(item, "-") && idx > 0 && idx < len(instance.Args)-2 {
+				item = "(" + item + ")"
+			}
+			add(`\huge `+strings.Replace(localizeDecimal(item), "-", `$-$`, 1), "anchor=west", float64(len(item)))
+		}
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight + \baselineskip)$`,
+			helpers.Ftoa(x))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of a basic
+	// operation
+	boPicture := horizontalBasicOperationTikZ{
+		Bottom: bottom,
+		items:  items,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return boPicture.execute()
+}
+
 // return a valid LaTeX/TikZ representation of this basic operation using TikZ
 // components
-func (bo basicOperation) GetTikZPicture() string {
+func (bo basicOperation) GetTikZPicture() (string, error) {
+
+	// basic operations shown in one single line are drawn separately
+	if bo.layout == BOHORIZONTAL {
+		return bo.horizontalTikZPicture()
+	}
 
 	// -- operands: randomly determine the values of the operands. For this, the
 	//              service that generates problems is the one that can marshal
 	//              them into JSON format. The operands and the result are given
 	//              in Args, where a question mark is a number that has to be
 	//              guessed by the student
-	instance, err := bo.generateJSONProblem()
+	instance, err := bo.next(annotated(bo))
 	if err != nil {
-		log.Fatalf(" Fatal error while generating a valid basic operation: %v", err)
+		return "", fmt.Errorf("error while generating a valid basic operation: %v", err)
 	}
 
-	// compute the number of digits required to draw all operands and the result
+	// compute the number of digits required to draw all operands and the result.
+	// Decimal points and the unary minus are as wide as a digit
 	nbdigits := helpers.Max(float64(bo.nbdigitsop), float64(bo.nbdigitsrslt))
+	if bo.nbdecimals > 0 {
+		nbdigits += 1
+	}
+	if bo.negative {
+		nbdigits += 1
+	}
 
 	// -- Coordinates
 
@@ -363,7 +1073,7 @@ func (bo basicOperation) GetTikZPicture() string {
 	// The answer box is located in the last row of the figure
 	answer := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight+1.0\baselineskip)$`,
-			1.5+(2.0+nbdigits)/2.0)),
+			helpers.Ftoa(1.5+(2.0+nbdigits)/2.0))),
 		"answer",
 	)
 
@@ -371,12 +1081,12 @@ func (bo basicOperation) GetTikZPicture() string {
 	// computed separately
 	split1 := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(answer) + (%v\zerowidth, 1.5\baselineskip)$`,
-			-(0.75+(2.0+nbdigits)/2.0))),
+			helpers.Ftoa(-(0.75+(2.0+nbdigits)/2.0)))),
 		"split1",
 	)
 	split2 := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(answer) + (%v\zerowidth, 1.5\baselineskip)$`,
-			(2.0+nbdigits)/2.0)),
+			helpers.Ftoa((2.0+nbdigits)/2.0))),
 		"split2",
 	)
 	splitLine := components.NewLine("split1", "split2")
@@ -404,8 +1114,8 @@ func (bo basicOperation) GetTikZPicture() string {
 		ith := float64(len(instance.Args)-idx) - 2.0
 		coord := components.NewCoordinate(
 			components.Formula(fmt.Sprintf(`$(answer) + (0, %v\zeroheight + %v\baselineskip)$`,
-				ith-1.0,
-				2.0+ith)),
+				helpers.Ftoa(ith-1.0),
+				helpers.Ftoa(2.0+ith))),
 			fmt.Sprintf("op%v", ith),
 		)
 
@@ -415,10 +1125,10 @@ func (bo basicOperation) GetTikZPicture() string {
 			// then add an empty text box
 			box = components.NewLabeledText(
 				fmt.Sprintf(`rounded corners, rectangle, minimum width=%v*\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
-					2.0+nbdigits,
+					helpers.Ftoa(2.0+nbdigits),
 				),
 				fmt.Sprintf("op%v", ith),
-				"",
+				bo.answer(bo.digits(instance.Solution[1+idx], int(nbdigits))),
 			)
 		} else {
 
@@ -426,7 +1136,7 @@ func (bo basicOperation) GetTikZPicture() string {
 			box = components.NewLabeledText(
 				"",
 				fmt.Sprintf("op%v", ith),
-				`\huge `+item)
+				`\huge `+bo.digits(item, int(nbdigits)))
 		}
 
 		// and add the new box and its coordinates
@@ -437,26 +1147,51 @@ func (bo basicOperation) GetTikZPicture() string {
 	// -- operator
 	operatorCoord := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(op1) + (%v\zerowidth, 0.0)$`,
-			-0.75-(2+nbdigits)/2.0)),
+			helpers.Ftoa(-0.75-(2+nbdigits)/2.0))),
 		"operator",
 	)
 
 	// the text to show for the operator depends upon the operator requested
-	var opLaTeX string
-	switch instance.Args[0] {
-	case "+", "-":
-		opLaTeX = instance.Args[0]
-	case "*":
-		opLaTeX = `$\times$`
-	case "/":
-		opLaTeX = `$\div$`
+	operator := components.NewLabeledText("", "operator", `\huge `+operatorLaTeX(instance.Args[0]))
+
+	// -- scaffold: the first operand is centered in its row, so that its
+	//              columns are located from its number of digits. Note that it
+	//              might be hidden, so that they are taken from the solution.
+	//              Borrows can happen in any column of the first operand,
+	//              whereas carries can go to any column of the result but the
+	//              units
+	var scaffold []components.CoordinatedText
+	nbrows := len(instance.Args) - 2
+	first := instance.Solution[1]
+	width := len(first)
+	if bo.nbdecimals > 0 || bo.nbdigitsops != nil {
+
+		// decimal numbers and operands with a different number of digits are
+		// padded to the width of the widest number
+		width = int(nbdigits)
+	}
+	if bo.scaffold {
+		scaffold = bo.columnBoxes(len(instance.Args)-2, width, 0, len(first), "borrow")
+	}
+	if bo.carrybox {
+
+		// carries can go to any column of the widest operand or the result
+		nbcols := 0.0
+		for _, number := range instance.Solution[1:] {
+			nbcols = helpers.Max(nbcols, float64(len(number)))
+		}
+		scaffold = bo.columnBoxes(len(instance.Args)-2, width, 1, int(nbcols), "carry")
+	}
+
+	// leave room for the row of the scaffold in the bounding box
+	if scaffold != nil {
+		nbrows += 1
 	}
-	operator := components.NewLabeledText("", "operator", `\huge `+opLaTeX)
 
 	// -- bounding box
 	right := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(split2) + (0.75\zerowidth, %v\baselineskip)$`,
-			1+2.0*(len(instance.Args)-2))),
+			1+2.0*nbrows)),
 		"right")
 	bBox := components.NewCoordinatedRectangle(bottom, right)
 	bBox.SetOptions("white")
@@ -471,10 +1206,10 @@ func (bo basicOperation) GetTikZPicture() string {
 		// in case it is unknown, draw an empty box
 		result = components.NewLabeledText(
 			fmt.Sprintf(`rounded corners, rectangle, minimum width=%v*\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
-				2.0+nbdigits,
+				helpers.Ftoa(2.0+nbdigits),
 			),
 			fmt.Sprintf("answer"),
-			"",
+			bo.answer(bo.digits(instance.Solution[len(instance.Solution)-1], int(nbdigits))),
 		)
 	} else {
 
@@ -482,7 +1217,7 @@ func (bo basicOperation) GetTikZPicture() string {
 		result = components.NewLabeledText(
 			"",
 			fmt.Sprintf("answer"),
-			`\huge `+instance.Args[len(instance.Args)-1])
+			`\huge `+bo.digits(instance.Args[len(instance.Args)-1], int(nbdigits)))
 	}
 
 	// And put all these elements together to show up the picture of a basic
@@ -497,6 +1232,7 @@ func (bo basicOperation) GetTikZPicture() string {
 		ops:           ops,
 		OperatorCoord: operatorCoord,
 		Operator:      operator,
+		scaffold:      scaffold,
 		BBox:          bBox,
 		Result:        result,
 	}
@@ -505,24 +1241,58 @@ func (bo basicOperation) GetTikZPicture() string {
 	return boPicture.execute()
 }
 
+// return small boxes located right above the columns [from, to) of the
+// operand in the given row, which has the given number of digits. Columns are
+// numbered from the right, starting with the units at 0, and they can go
+// beyond the digits of the operand. The column of the decimal point, if any, is
+// skipped. The labels of the boxes are given with the specified prefix followed
+// by their column
+func (bo basicOperation) columnBoxes(row, nbdigits, from, to int, prefix string) []components.CoordinatedText {
+
+	var boxes []components.CoordinatedText
+	for col := from; col < to; col++ {
+
+		if bo.nbdecimals > 0 && col == bo.nbdecimals {
+			continue
+		}
+
+		// as operands are centered, the column of the units is located at
+		// half the width of the operand minus half a digit to the right of
+		// its center
+		boxes = append(boxes, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(op%v) + (%v\zerowidth, 0.5\zeroheight + 0.5\baselineskip)$`,
+					row, helpers.Ftoa(float64(nbdigits)/2.0-float64(col)-0.5))),
+				fmt.Sprintf("%v%v", prefix, col)),
+			`rounded corners, rectangle, dashed, minimum width=0.8\zerowidth, minimum height=0.6\zeroheight, draw`,
+			""))
+	}
+	return boxes
+}
+
 // Return TikZ code that represents a basic operation
-func (bo basicOperation) execute() string {
+func (bo basicOperation) execute() (string, error) {
 
-	// create a template with the TikZ code for showing this basic operation
-	tpl, err := template.New("basicOperation").Parse(latexBasicOperationCode)
+	// create a template with the TikZ code for showing this basic operation,
+	// which depends on its layout
+	code := latexBasicOperationCode
+	if bo.layout == BOHORIZONTAL {
+		code = latexHorizontalBasicOperationCode
+	}
+	tpl, err := template.New("basicOperation").Parse(code)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the execution of the
 	// template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, bo); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 // Local Variables:
@@ -0,0 +1,398 @@
+// -*- coding: utf-8 -*-
+// clock.go
+//
+// Description: Provides services for automatically creating problems where
+// the time has to be read from an analog clock, or drawn on it
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:38:59.893169164 (1792111139)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of clock problems: "read" or "draw". In the
+// first case, the hands of the clock are drawn and the student has to write
+// down the time; in the latter, the time is given and the student has to draw
+// the hands on an empty clock face
+const (
+	CLOCKREAD int = iota
+	CLOCKDRAW
+)
+
+// the radius of the clock face, in centimeters
+const clockRadius = 1.75
+
+// the TikZ code for generating clocks is shown next. Note that it makes use of
+// LaTeX/TikZ components
+const latexClockCode = `\begin{minipage}{0.25\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the clock
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZClockCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Clock face ------------------------------------------------------
+
+      % the face is a circle with ticks for every minute, which are thicker for
+      % every hour, and the numbers of the hours
+      {{.Face}}
+{{.GetDial}}
+      % --- Hands -----------------------------------------------------------
+
+      % the hands are drawn only if the time has to be read, or when showing
+      % the answers
+{{.GetHands}}
+      % --- Time ------------------------------------------------------------
+
+      % the time is shown below the clock face either explicitly, or with empty
+      % boxes for the hours and the minutes
+{{.GetTime}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A clock problem consists of a type (either CLOCKREAD or CLOCKDRAW) and the
+// granularity of the minutes shown in the clock, either "hour" (o'clock),
+// "half", "quarter", "five" (5-minute intervals) or "minute"
+type clock struct {
+	clocktype   int
+	granularity string
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw clocks
+type clockTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the clock face is drawn as a circle around its center, and it contains
+	// ticks and the numbers of the hours
+	Face    components.CoordinatedText
+	ticks   []components.Line
+	numbers []components.CoordinatedText
+
+	// the hands of the clock
+	hands []components.Line
+
+	// the time is shown with a number of text boxes
+	time []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the number of minutes between consecutive times that can be generated
+// with the given granularity. If the granularity is not known, 0 is returned
+func clockStep(granularity string) int {
+
+	switch granularity {
+	case "hour":
+		return 60
+	case "half":
+		return 30
+	case "quarter":
+		return 15
+	case "five":
+		return 5
+	case "minute":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- clockTikZ
+
+// Generates the TikZ code necessary for drawing the ticks and numbers of the
+// clock face
+func (tikz clockTikZ) GetDial() string {
+
+	// Use a btyes buffer to append the strings of each component
+	var output bytes.Buffer
+
+	for _, tick := range tikz.ticks {
+		fmt.Fprintf(&output, "      %v\n", tick)
+	}
+	for _, number := range tikz.numbers {
+		fmt.Fprintf(&output, "      %v\n", number)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// dial
+	return output.String()
+}
+
+// Generates the TikZ code necessary for drawing the hands of the clock, if any
+func (tikz clockTikZ) GetHands() string {
+
+	// Use a btyes buffer to append the strings of each component
+	var output bytes.Buffer
+
+	for _, hand := range tikz.hands {
+		fmt.Fprintf(&output, "      %v\n", hand)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// hands
+	return output.String()
+}
+
+// Generates the TikZ code necessary for showing the time below the clock face
+func (tikz clockTikZ) GetTime() string {
+
+	// Use a btyes buffer to append the strings of each component
+	var output bytes.Buffer
+
+	for _, text := range tikz.time {
+		fmt.Fprintf(&output, "      %v\n", text)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// time
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz clockTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("clockTikZ").Parse(tikZClockCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- clock
+
+// return the instance of a specific clock problem that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The time is given as "h:mm" with the hours between 1 and 12. If the time has
+// to be read from the clock, the argument is shown as "?" as it has to be
+// guessed by the student; otherwise, it is the time to draw
+func (clk clock) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	step := clockStep(clk.granularity)
+	if step == 0 {
+		return ProblemJSON{}, fmt.Errorf("Unknown granularity '%v'", clk.granularity)
+	}
+
+	// randomly determine the hour and minutes with the given granularity
+	hours := 1 + rnd.Intn(12)
+	minutes := step * rnd.Intn(60/step)
+	time := fmt.Sprintf("%v:%02d", hours, minutes)
+
+	// the argument depends on the type of clock problem
+	arg := time
+	if clk.clocktype == CLOCKREAD {
+		arg = "?"
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "Clock",
+		Args:     []string{arg},
+		Solution: []string{time}}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this clock using TikZ components
+func (clk clock) GetTikZPicture() (string, error) {
+
+	// -- time: randomly determine the time to show. For this, the service that
+	//          generates problems is the one that can marshal them into JSON
+	//          format
+	instance, err := clk.next(annotated(clk))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid clock: %v", err)
+	}
+	var hours, minutes int
+	if _, err := fmt.Sscanf(instance.Solution[0], "%d:%d", &hours, &minutes); err != nil {
+		return "", fmt.Errorf("error while reading the time '%v': %v", instance.Solution[0], err)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// the center of the clock leaves room below it for showing the time
+	center := components.NewCoordinate(components.Point{
+		X: clockRadius + 0.25,
+		Y: clockRadius + 1.5,
+	}, "center")
+
+	// points of the clock face are given in polar coordinates with respect to
+	// its center, with the angle in degrees and the distance in centimeters
+	polar := func(angle, distance float64) string {
+		return fmt.Sprintf("$(center) + (%v:%vcm)$", helpers.Ftoa(angle), helpers.Ftoa(distance))
+	}
+
+	// -- face
+	face := components.NewCoordinatedText(center,
+		fmt.Sprintf("circle, draw, thick, minimum size=%vcm", helpers.Ftoa(2*clockRadius)), "")
+
+	// -- ticks: every minute is marked with a thin tick, and every hour with a
+	//           thicker and longer one
+	var ticks []components.Line
+	for minute := 0; minute < 60; minute++ {
+		angle := 90.0 - 6.0*float64(minute)
+		tick := components.NewLine(polar(angle, 0.92*clockRadius), polar(angle, clockRadius))
+		if minute%5 == 0 {
+			tick = components.NewLine(polar(angle, 0.85*clockRadius), polar(angle, clockRadius))
+			tick.SetOptions("thick")
+		}
+		ticks = append(ticks, tick)
+	}
+
+	// -- numbers
+	var numbers []components.CoordinatedText
+	for hour := 1; hour <= 12; hour++ {
+		numbers = append(numbers,
+			components.NewCoordinatedText(
+				components.NewCoordinate(components.Formula(polar(90.0-30.0*float64(hour), 0.7*clockRadius)),
+					fmt.Sprintf("hour%v", hour)),
+				"", fmt.Sprintf(`\large %v`, hour)))
+	}
+
+	// -- hands: they are drawn only if the time has to be read, or when
+	//           replaying problems to show the answers
+	var hands []components.Line
+	if clk.clocktype == CLOCKREAD || clk.showAnswers() {
+		hour := components.NewLine("center",
+			polar(90.0-30.0*float64(hours%12)-0.5*float64(minutes), 0.5*clockRadius))
+		hour.SetOptions("line width=2pt, line cap=round")
+		minute := components.NewLine("center",
+			polar(90.0-6.0*float64(minutes), 0.8*clockRadius))
+		minute.SetOptions("line width=1pt, line cap=round")
+		hands = append(hands, hour, minute)
+	}
+
+	// -- time: all items are placed right below the clock face
+	at := func(label string, offset string) components.Coordinate {
+		return components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%vcm%v, 0.75cm)$`,
+				helpers.Ftoa(clockRadius+0.25), offset)),
+			label)
+	}
+	var time []components.CoordinatedText
+	if clk.clocktype == CLOCKREAD {
+
+		// the hours and minutes are shown within empty boxes separated by a
+		// colon
+		box := func(label string, offset, solution string) components.CoordinatedText {
+			return components.NewCoordinatedText(at(label, offset),
+				`rounded corners, rectangle, minimum width=3\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				clk.answer(solution))
+		}
+		time = append(time,
+			box("hours", `-2.5\zerowidth`, fmt.Sprintf("%v", hours)),
+			components.NewCoordinatedText(at("colon", ""), "", `\huge :`),
+			box("minutes", `+2.5\zerowidth`, fmt.Sprintf("%02d", minutes)))
+	} else {
+		time = append(time,
+			components.NewCoordinatedText(at("time", ""), "", `\huge `+instance.Solution[0]))
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(components.Point{
+		X: 2*clockRadius + 0.5,
+		Y: 2*clockRadius + 1.75,
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the clock
+	clockPicture := clockTikZ{
+		Bottom:  bottom,
+		Face:    face,
+		ticks:   ticks,
+		numbers: numbers,
+		hands:   hands,
+		time:    time,
+		BBox:    bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return clockPicture.execute()
+}
+
+// Return TikZ code that represents a clock problem
+func (clk clock) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this clock
+	tpl, err := template.New("clock").Parse(latexClockCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, clk); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
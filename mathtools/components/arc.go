@@ -0,0 +1,195 @@
+// -*- coding: utf-8 -*-
+// arc.go
+//
+// Description: Definition of arcs of circles as reusable components to be used
+//              in TikZ drawings
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:45:44.976636409 (1792115144)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// TikZ code to generate arcs: the arc starts at the point of the circle with
+// the initial angle and goes counterclockwise until the final angle
+const tikzArc = `\draw [{{.GetOptions}}] ($({{.GetReference}}) + ({{.GetX0}}cm, {{.GetY0}}cm)$) arc ({{.GetFrom}}:{{.GetTo}}:{{.GetRadius}}cm);`
+
+// types
+// ----------------------------------------------------------------------------
+
+// An arc is a part of the circle centered at the given reference (either the
+// name of a label or a formula) with the given radius in centimeters, which
+// goes from one angle to another, both given in degrees. Additionally, an
+// arbitrary number of options can be given as a comma-separated string, e.g.,
+// the line width
+type Arc struct {
+	reference string
+	radius    float64
+	from, to  float64
+	BaseLine
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new arc of the circle centered at the given reference with the given
+// radius, from one angle to another given in degrees. Note that the options are
+// specified through a dedicated service
+func NewArc(reference string, radius, from, to float64) Arc {
+	return Arc{
+		reference: reference,
+		radius:    radius,
+		from:      from,
+		to:        to,
+	}
+}
+
+// return a valid specification of an arc with no error if all the keys given
+// in dict are correct for defining it. Otherwise, return an error. If an error
+// is returned, the contents of the arc are undefined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// its center as a string with the keyword "reference", its radius with
+// "radius", and the initial and final angles in degrees with "from" and "to".
+// These are the only mandatory arguments. In addition, it is also possible to
+// specify arbitrary options as a string
+func VerifyArcDict(dict map[string]interface{}) (Arc, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"reference", "radius", "from", "to", "options"}
+	mandatory := []string{"reference", "radius", "from", "to"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return Arc{}, fmt.Errorf("Mandatory key '%v' for defining an arc not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var err error
+	var reference string
+	var radius, from, to float64
+	if reference, ok = dict["reference"].(string); !ok {
+		return Arc{}, errors.New("The reference of an arc should be given as a string")
+	}
+	if radius, err = helpers.Atof(dict["radius"]); err != nil || radius <= 0 {
+		return Arc{}, errors.New("The radius of an arc should be given as a positive number")
+	}
+	if from, err = helpers.Atof(dict["from"]); err != nil {
+		return Arc{}, errors.New("The initial angle of an arc should be given as a number")
+	}
+	if to, err = helpers.Atof(dict["to"]); err != nil {
+		return Arc{}, errors.New("The final angle of an arc should be given as a number")
+	}
+
+	// now, perform the same operation with the optional parameters
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return Arc{}, errors.New("The options of an arc should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating an arc and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid arc
+	return Arc{
+		reference: reference,
+		radius:    radius,
+		from:      from,
+		to:        to,
+		BaseLine:  BaseLine{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// Return the reference of the center of this arc
+func (a Arc) GetReference() string {
+	return a.reference
+}
+
+// Return the radius of this arc
+func (a Arc) GetRadius() string {
+	return helpers.Ftoa(a.radius)
+}
+
+// Return the initial angle of this arc
+func (a Arc) GetFrom() string {
+	return helpers.Ftoa(a.from)
+}
+
+// Return the final angle of this arc
+func (a Arc) GetTo() string {
+	return helpers.Ftoa(a.to)
+}
+
+// Return the horizontal offset of the initial point of this arc with respect
+// to its center
+func (a Arc) GetX0() string {
+	return helpers.Ftoa(a.radius * math.Cos(a.from*math.Pi/180.0))
+}
+
+// Return the vertical offset of the initial point of this arc with respect to
+// its center
+func (a Arc) GetY0() string {
+	return helpers.Ftoa(a.radius * math.Sin(a.from*math.Pi/180.0))
+}
+
+// Finally, arcs are stringers and these are the means provided for
+// automatically reusing this component
+func (a Arc) String() string {
+
+	// create a template with the TikZ code for showing an arc
+	tpl, err := template.New("arc").Parse(tikzArc)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, a); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
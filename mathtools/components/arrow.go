@@ -0,0 +1,197 @@
+// -*- coding: utf-8 -*-
+// arrow.go
+//
+// Description: Definition of arrows between two references with an optional
+// label shown at their midpoint
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 15:02:37.604118295 (1792162957)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// TikZ code to generate an arrow: it draws a straight line from one reference
+// to another with an arrow tip at the end (or at both ends) and, if a label is
+// given, it is shown above the midpoint of the arrow
+const tikzArrow = `\draw [{{.GetTips}}{{if .GetOptions}}, {{.GetOptions}}{{end}}] ({{.GetFrom}}) -- ({{.GetTo}}){{if .GetLabel}} node [midway, sloped, above] { {{- .GetLabel -}} }{{end}};`
+
+// types
+// ----------------------------------------------------------------------------
+
+// An arrow goes from one reference to another (either the names of labels or
+// formulas). It can be double-headed, in which case it has arrow tips at both
+// ends, and it can show a label at its midpoint. Additionally, an arbitrary
+// number of options can be given as a comma-separated string, e.g., its color
+type Arrow struct {
+	from, to string
+	double   bool
+	label    string
+	BaseLine
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new single-headed arrow from one reference to another with no
+// label. Note that the label, the options and whether it is double-headed are
+// specified through dedicated services
+func NewArrow(from, to string) Arrow {
+	return Arrow{
+		from: from,
+		to:   to,
+	}
+}
+
+// return a valid specification of an arrow with no error if all the keys given
+// in dict are correct for defining it. Otherwise, return an error. If an error
+// is returned, the contents of the arrow are undefined
+//
+// A dictionary is correct if and only if it correctly defines the references
+// of its initial and final points as strings with the keywords "from" and
+// "to". These are the only mandatory arguments. In addition, it is also
+// possible to specify whether it is double-headed with "double", the label
+// shown at its midpoint with "label" and arbitrary options as a string
+func VerifyArrowDict(dict map[string]interface{}) (Arrow, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"from", "to", "double", "label", "options"}
+	mandatory := []string{"from", "to"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return Arrow{}, fmt.Errorf("Mandatory key '%v' for defining an arrow not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var from, to string
+	if from, ok = dict["from"].(string); !ok {
+		return Arrow{}, errors.New("The initial point of an arrow should be given as a string")
+	}
+	if to, ok = dict["to"].(string); !ok {
+		return Arrow{}, errors.New("The final point of an arrow should be given as a string")
+	}
+
+	// now, perform the same operation with the optional parameters
+	var err error
+	var double bool
+	if _, ok := dict["double"]; ok {
+		if double, err = helpers.Atob(dict["double"]); err != nil {
+			return Arrow{}, errors.New("Whether an arrow is double-headed should be given as a boolean")
+		}
+	}
+	var label string
+	if _, ok := dict["label"]; ok {
+		if label, ok = dict["label"].(string); !ok {
+			return Arrow{}, errors.New("The label of an arrow should be given as a string")
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if options, ok = dict["options"].(string); !ok {
+			return Arrow{}, errors.New("The options of an arrow should be given as a string")
+		}
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating an arrow and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid arrow
+	return Arrow{
+		from:     from,
+		to:       to,
+		double:   double,
+		label:    label,
+		BaseLine: BaseLine{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// Set whether this arrow has arrow tips at both ends
+func (a *Arrow) SetDouble(double bool) {
+	a.double = double
+}
+
+// Set the label shown at the midpoint of this arrow
+func (a *Arrow) SetLabel(label string) {
+	a.label = label
+}
+
+// Return the reference of the initial point of this arrow
+func (a Arrow) GetFrom() string {
+	return a.from
+}
+
+// Return the reference of the final point of this arrow
+func (a Arrow) GetTo() string {
+	return a.to
+}
+
+// Return the arrow tips of this arrow in TikZ format
+func (a Arrow) GetTips() string {
+	if a.double {
+		return "<->"
+	}
+	return "->"
+}
+
+// Return the label shown at the midpoint of this arrow, if any
+func (a Arrow) GetLabel() string {
+	return a.label
+}
+
+// Finally, arrows are stringers and these are the means provided for
+// automatically reusing this component
+func (a Arrow) String() string {
+
+	// create a template with the TikZ code for showing an arrow
+	tpl, err := template.New("arrow").Parse(tikzArrow)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, a); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,264 @@
+// -*- coding: utf-8 -*-
+// barchart.go
+//
+// Description: Definition of bar charts, i.e., vertical bars drawn over a grid
+//              with labeled axes, as reusable components to be used in TikZ
+//              drawings
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:58:13.417209846 (1792119493)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Every category takes a slot of the following width along the horizontal
+// axis, and its bar is as wide as the given value, all in centimeters
+const (
+	BARCHARTSLOTWIDTH float64 = 1.4
+	BARCHARTBARWIDTH  float64 = 0.8
+)
+
+// By default, the vertical axis of bar charts is as high as the following
+// value in centimeters
+const DEFAULTBARCHARTHEIGHT float64 = 6.0
+
+// types
+// ----------------------------------------------------------------------------
+
+// A bar chart consists of a number of categories, each one with a label shown
+// below the horizontal axis and a value shown as a vertical bar. The vertical
+// axis goes from zero to the given maximum, with a horizontal line for every
+// unit, and it is labeled every step units. The lower-left corner of the chart
+// is located at its origin, which is given as a reference (either the name of
+// a label or a formula). If no values are given, only the axes are drawn, so
+// that bars can be drawn by students. Additionally, an arbitrary number of
+// options can be given as a comma-separated string for drawing the bars, e.g.,
+// their fill color
+type BarChart struct {
+	origin        string
+	labels        []string
+	values        []int
+	maximum, step int
+	height        float64
+	BaseRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new instance of a bar chart given the reference of its origin, the
+// labels and values of all categories, the maximum value of the vertical axis,
+// the step between its labels and its height in centimeters. Note that the
+// options are specified through a dedicated service
+func NewBarChart(origin string, labels []string, values []int, maximum, step int, height float64) BarChart {
+	return BarChart{
+		origin:  origin,
+		labels:  labels,
+		values:  values,
+		maximum: maximum,
+		step:    step,
+		height:  height,
+	}
+}
+
+// return a valid specification of a bar chart with no error if all the keys
+// given in dict are correct for defining it. Otherwise, return an error. If an
+// error is returned, the contents of the bar chart are undefined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// the origin as a string with the keyword "origin", the labels of all
+// categories as a comma-separated string with "labels", and the maximum value
+// of the vertical axis with "maximum". These are the only mandatory arguments.
+// In addition, it is also possible to specify the values of all categories as
+// a comma-separated string with "values" (none by default), the step between
+// the labels of the vertical axis with "step" (1 by default), its height in
+// centimeters with "height" (DEFAULTBARCHARTHEIGHT by default), and arbitrary
+// options as a string
+func VerifyBarChartDict(dict map[string]interface{}) (BarChart, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"origin", "labels", "maximum", "values", "step", "height", "options"}
+	mandatory := []string{"origin", "labels", "maximum"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return BarChart{}, fmt.Errorf("Mandatory key '%v' for defining a bar chart not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var err error
+	var origin, labels string
+	var maximum int
+	if origin, ok = dict["origin"].(string); !ok {
+		return BarChart{}, errors.New("The origin of a bar chart should be given as a string")
+	}
+	if labels, ok = dict["labels"].(string); !ok {
+		return BarChart{}, errors.New("The labels of a bar chart should be given as a comma-separated string")
+	}
+	if maximum, err = helpers.Atoi(dict["maximum"]); err != nil || maximum <= 0 {
+		return BarChart{}, errors.New("The maximum value of a bar chart should be given as a positive integer")
+	}
+
+	// now, perform the same operation with the optional parameters
+	var values []int
+	if _, ok := dict["values"]; ok {
+		if _, ok := dict["values"].(string); !ok {
+			return BarChart{}, errors.New("The values of a bar chart should be given as a comma-separated string")
+		}
+		for _, value := range strings.Split(dict["values"].(string), ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || number < 0 || number > maximum {
+				return BarChart{}, fmt.Errorf("The values of a bar chart should be integers in the range [0, %v]", maximum)
+			}
+			values = append(values, number)
+		}
+	}
+	step := 1
+	if _, ok := dict["step"]; ok {
+		if step, err = helpers.Atoi(dict["step"]); err != nil || step <= 0 {
+			return BarChart{}, errors.New("The step between labels of a bar chart should be given as a positive integer")
+		}
+	}
+	height := DEFAULTBARCHARTHEIGHT
+	if _, ok := dict["height"]; ok {
+		if height, err = helpers.Atof(dict["height"]); err != nil || height <= 0 {
+			return BarChart{}, errors.New("The height of a bar chart should be given as a positive number")
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return BarChart{}, errors.New("The options of a bar chart should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a bar chart and it will be ignored", key)
+		}
+	}
+
+	// make sure there is a value for every category, if any is given
+	categories := strings.Split(labels, ",")
+	if values != nil && len(values) != len(categories) {
+		return BarChart{}, fmt.Errorf("The bar chart has %v categories but %v values were given", len(categories), len(values))
+	}
+
+	// At this point, the dictionary is correct, return a valid bar chart
+	return BarChart{
+		origin:        origin,
+		labels:        categories,
+		values:        values,
+		maximum:       maximum,
+		step:          step,
+		height:        height,
+		BaseRectangle: BaseRectangle{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// return the reference of the point located at the given distance in
+// centimeters to the right of the origin and the given value above it.
+// Formulas can not be nested in TikZ, so that if the origin is a formula the
+// offset is added to it
+func (bc BarChart) at(x float64, value float64) string {
+
+	position := fmt.Sprintf("(%v)", bc.origin)
+	if strings.HasPrefix(bc.origin, "$") && strings.HasSuffix(bc.origin, "$") {
+		position = strings.Trim(bc.origin, "$")
+	}
+	return fmt.Sprintf("$%v + (%vcm, %vcm)$", position,
+		helpers.Ftoa(x), helpers.Ftoa(bc.height*value/float64(bc.maximum)))
+}
+
+// Return the width of this bar chart in centimeters
+func (bc BarChart) GetWidth() float64 {
+	return BARCHARTSLOTWIDTH * float64(len(bc.labels))
+}
+
+// Return the height of this bar chart in centimeters
+func (bc BarChart) GetHeight() float64 {
+	return bc.height
+}
+
+// Return the reference of the point right below the i-th category, where its
+// label is shown, so that other components can be placed with respect to it
+func (bc BarChart) GetCategory(i int) string {
+	return bc.at(BARCHARTSLOTWIDTH*(0.5+float64(i)), 0.0)
+}
+
+// Return the corners of the bar of the i-th category with the given value
+func (bc BarChart) GetBar(i, value int) (corner0, corner1 string) {
+
+	x := BARCHARTSLOTWIDTH*(0.5+float64(i)) - BARCHARTBARWIDTH/2.0
+	return bc.at(x, 0.0), bc.at(x+BARCHARTBARWIDTH, float64(value))
+}
+
+// Finally, bar charts are stringers and these are the means provided for
+// automatically reusing this component
+func (bc BarChart) String() string {
+
+	// Use a btyes buffer to append the TikZ code of every part of the chart
+	var output bytes.Buffer
+
+	// first, a horizontal line is drawn for every unit, and those which are
+	// multiple of the step are labeled
+	for value := 0; value <= bc.maximum; value++ {
+		fmt.Fprintf(&output, `\draw [gray] (%v) -- (%v);`+"\n", bc.at(0.0, float64(value)), bc.at(bc.GetWidth(), float64(value)))
+		if value%bc.step == 0 {
+			fmt.Fprintf(&output, `\draw (%v) node [left] { %v };`+"\n", bc.at(0.0, float64(value)), value)
+		}
+	}
+
+	// next, the bars of all categories, if any are given, along with their
+	// labels
+	for i, label := range bc.labels {
+		if i < len(bc.values) {
+			corner0, corner1 := bc.GetBar(i, bc.values[i])
+			fmt.Fprintf(&output, `\draw [%v] (%v) rectangle (%v);`+"\n", bc.options, corner0, corner1)
+		}
+		fmt.Fprintf(&output, `\draw (%v) node [below] { %v };`+"\n", bc.GetCategory(i), label)
+	}
+
+	// and finally both axes
+	fmt.Fprintf(&output, `\draw [thick, ->] (%v) -- (%v);`+"\n", bc.at(0.0, 0.0), bc.at(0.0, float64(bc.maximum)+0.5))
+	fmt.Fprintf(&output, `\draw [thick, ->] (%v) -- (%v);`, bc.at(0.0, 0.0), bc.at(bc.GetWidth()+0.3, 0.0))
+
+	// and return the TikZ code used for drawing this bar chart
+	return output.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
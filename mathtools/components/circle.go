@@ -0,0 +1,291 @@
+// -*- coding: utf-8 -*-
+// circle.go
+//
+// Description: Definition of circles and ellipses centered at a reference with
+// additional options
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 14:31:52.118094372 (1792161112)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// TikZ code to generate a circle: it just simply draws a circle with the
+// specified options centered at the given reference, either the name of a
+// coordinate (i.e., its label) or a formula explicitly given
+const tikzCircle = `\draw [{{.GetOptions}}] ({{.GetReference}}) circle ({{.GetRadius}}cm);`
+
+// TikZ code to generate an ellipse: the same as circles but with two different
+// radii along the x and y axes
+const tikzEllipse = `\draw [{{.GetOptions}}] ({{.GetReference}}) ellipse ({{.GetXRadius}}cm and {{.GetYRadius}}cm);`
+
+// types
+// ----------------------------------------------------------------------------
+
+// Any circle or ellipse has options which consist of a comma-separated list of
+// options in a string, e.g., the color used for filling it
+type BaseCircle struct {
+	options string
+}
+
+// A circle requires the reference of its center (either the name of a label or
+// a formula explicitly given) and its radius in centimeters
+type Circle struct {
+	reference string
+	radius    float64
+	BaseCircle
+}
+
+// An ellipse requires the reference of its center (either the name of a label
+// or a formula explicitly given) and its radii along the x and y axes in
+// centimeters
+type Ellipse struct {
+	reference        string
+	xradius, yradius float64
+	BaseCircle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new instance of a circle centered at the given reference with the
+// given radius. Note that the options are specified through a dedicated service
+func NewCircle(reference string, radius float64) Circle {
+	return Circle{
+		reference: reference,
+		radius:    radius,
+	}
+}
+
+// Create a new instance of an ellipse centered at the given reference with the
+// given radii along the x and y axes. Note that the options are specified
+// through a dedicated service
+func NewEllipse(reference string, xradius, yradius float64) Ellipse {
+	return Ellipse{
+		reference: reference,
+		xradius:   xradius,
+		yradius:   yradius,
+	}
+}
+
+// verify that all the given mandatory keys are given in the dictionary of the
+// given shape, and return the options given in it, if any. In case any other
+// key not given in all is found, a warning is issued
+func verifyCircleKeys(dict map[string]interface{}, mandatory, all []string, shape string) (string, error) {
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return "", fmt.Errorf("Mandatory key '%v' for defining %v not found", key, shape)
+		}
+	}
+
+	// now, perform the same operation with the optional parameters
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return "", fmt.Errorf("The options of %v should be given as a string", shape)
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating %v and it will be ignored", key, shape)
+		}
+	}
+	return options, nil
+}
+
+// return a valid specification of a circle with no error if all the keys given
+// in dict are correct for defining a circle. Otherwise, return an error. If an
+// error is returned, the contents of the circle are undetermined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// its center as a string with the keyword "reference" and its radius with
+// "radius". These are the only mandatory arguments. In addition, it is also
+// possible to specify arbitrary options as a string
+func VerifyCircleDict(dict map[string]interface{}) (Circle, error) {
+
+	// first of all, ensure that all mandatory parameters are given along with
+	// the options, if any
+	options, err := verifyCircleKeys(dict,
+		[]string{"reference", "radius"},
+		[]string{"reference", "radius", "options"}, "a circle")
+	if err != nil {
+		return Circle{}, err
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var reference string
+	var radius float64
+	if reference, ok = dict["reference"].(string); !ok {
+		return Circle{}, errors.New("The reference of the center of a circle should be given as a string")
+	}
+	if radius, err = helpers.Atof(dict["radius"]); err != nil || radius <= 0 {
+		return Circle{}, errors.New("The radius of a circle should be given as a positive number")
+	}
+
+	// At this point, the dictionary is correct, return a valid circle
+	return Circle{
+		reference:  reference,
+		radius:     radius,
+		BaseCircle: BaseCircle{options: options},
+	}, nil
+}
+
+// return a valid specification of an ellipse with no error if all the keys
+// given in dict are correct for defining an ellipse. Otherwise, return an
+// error. If an error is returned, the contents of the ellipse are undetermined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// its center as a string with the keyword "reference" and its radii along the x
+// and y axes with "xradius" and "yradius". These are the only mandatory
+// arguments. In addition, it is also possible to specify arbitrary options as a
+// string
+func VerifyEllipseDict(dict map[string]interface{}) (Ellipse, error) {
+
+	// first of all, ensure that all mandatory parameters are given along with
+	// the options, if any
+	options, err := verifyCircleKeys(dict,
+		[]string{"reference", "xradius", "yradius"},
+		[]string{"reference", "xradius", "yradius", "options"}, "an ellipse")
+	if err != nil {
+		return Ellipse{}, err
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var reference string
+	var xradius, yradius float64
+	if reference, ok = dict["reference"].(string); !ok {
+		return Ellipse{}, errors.New("The reference of the center of an ellipse should be given as a string")
+	}
+	if xradius, err = helpers.Atof(dict["xradius"]); err != nil || xradius <= 0 {
+		return Ellipse{}, errors.New("The radius of an ellipse along the x axis should be given as a positive number")
+	}
+	if yradius, err = helpers.Atof(dict["yradius"]); err != nil || yradius <= 0 {
+		return Ellipse{}, errors.New("The radius of an ellipse along the y axis should be given as a positive number")
+	}
+
+	// At this point, the dictionary is correct, return a valid ellipse
+	return Ellipse{
+		reference:  reference,
+		xradius:    xradius,
+		yradius:    yradius,
+		BaseCircle: BaseCircle{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// --BaseCircle
+
+// Set the options of a circle or ellipse
+func (circle *BaseCircle) SetOptions(options string) {
+	circle.options = options
+}
+
+// Get the options used
+func (circle BaseCircle) GetOptions() string {
+	return circle.options
+}
+
+// --Circle
+
+// Return the reference of the center of the circle
+func (circle Circle) GetReference() string {
+	return circle.reference
+}
+
+// Return the radius of the circle
+func (circle Circle) GetRadius() string {
+	return helpers.Ftoa(circle.radius)
+}
+
+// Finally, circles are stringers and these are the means provided for
+// automatically reusing this component
+func (circle Circle) String() string {
+
+	// create a template with the TikZ code for showing a circle
+	tpl, err := template.New("circle").Parse(tikzCircle)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, circle); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// --Ellipse
+
+// Return the reference of the center of the ellipse
+func (ellipse Ellipse) GetReference() string {
+	return ellipse.reference
+}
+
+// Return the radius of the ellipse along the x axis
+func (ellipse Ellipse) GetXRadius() string {
+	return helpers.Ftoa(ellipse.xradius)
+}
+
+// Return the radius of the ellipse along the y axis
+func (ellipse Ellipse) GetYRadius() string {
+	return helpers.Ftoa(ellipse.yradius)
+}
+
+// Finally, ellipses are stringers and these are the means provided for
+// automatically reusing this component
+func (ellipse Ellipse) String() string {
+
+	// create a template with the TikZ code for showing an ellipse
+	tpl, err := template.New("ellipse").Parse(tikzEllipse)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, ellipse); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
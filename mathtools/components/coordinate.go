@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"log"
 	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
 )
 
 // constants
@@ -199,9 +201,10 @@ func VerifyCoordinateDict(dict map[string]interface{}) (Coordinate, error) {
 // -- Point
 
 // Points are of course positionable and, as such, they return a string that
-// represents their location as a valid TikZ representation
+// represents their location as a valid TikZ representation. Both coordinates
+// are shown with the precision given in helpers.SetPrecision
 func (p Point) Position() string {
-	return fmt.Sprintf("(%v, %v)", p.X, p.Y)
+	return fmt.Sprintf("(%v, %v)", helpers.Ftoa(p.X), helpers.Ftoa(p.Y))
 }
 
 // -- Formula
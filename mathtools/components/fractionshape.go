@@ -0,0 +1,288 @@
+// -*- coding: utf-8 -*-
+// fractionshape.go
+//
+// Description: Definition of circles and bars divided into equal parts, some of
+//              which are shaded, as reusable components to be used in TikZ
+//              drawings
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:40:40.662951707 (1792114840)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Fraction shapes are drawn either as circles ("pie") with the following
+// radius or as bars ("bar") with the following width and height, all given in
+// centimeters
+const (
+	FRACTIONPIE       string  = "pie"
+	FRACTIONBAR       string  = "bar"
+	FRACTIONPIERADIUS float64 = 1.2
+	FRACTIONBARWIDTH  float64 = 4.8
+	FRACTIONBARHEIGHT float64 = 1.0
+)
+
+// Shaded parts are filled with the following color
+const FRACTIONSHADE string = "lightgray"
+
+// TikZ code to generate fraction shapes: every part is drawn separately either
+// as a slice of a circle or as a rectangle around the reference, and it is
+// filled if it is shaded
+const tikzFractionSlice = `\draw [{{.Options}}] ({{.Reference}}) -- ($({{.Reference}}) + ({{.Angle0}}:{{.Radius}}cm)$) arc ({{.Angle0}}:{{.Angle1}}:{{.Radius}}cm) -- cycle;`
+const tikzFractionBar = `\draw [{{.Options}}] ($({{.Reference}}) + ({{.X0}}cm, {{.Y0}}cm)$) rectangle ($({{.Reference}}) + ({{.X1}}cm, {{.Y1}}cm)$);`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A fraction shape is either a circle or a bar centered at the given reference
+// (either the name of a label or a formula) which is divided into as many equal
+// parts as its denominator. Parts are numbered clockwise from the top of
+// circles and from left to right in bars, and those which are shaded are
+// filled. Additionally, an arbitrary number of options can be given as a
+// comma-separated string for drawing all parts, e.g., the line width
+type FractionShape struct {
+	reference string
+	shape     string
+	shaded    []bool
+	BaseRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new fraction shape (either FRACTIONPIE or FRACTIONBAR) centered at
+// the given reference, with as many parts as the length of shaded, which tells
+// whether every part is shaded or not. Note that the options are specified
+// through a dedicated service
+func NewFractionShape(reference, shape string, shaded []bool) FractionShape {
+	return FractionShape{
+		reference: reference,
+		shape:     shape,
+		shaded:    shaded,
+	}
+}
+
+// return a valid specification of a fraction shape with no error if all the
+// keys given in dict are correct for defining it. Otherwise, return an error.
+// If an error is returned, the contents of the fraction shape are undefined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// its center as a string with the keyword "reference", and the number of parts
+// as an integer with "denominator". These are the only mandatory arguments. In
+// addition, it is also possible to specify the shape, either "pie" (by
+// default) or "bar", with "shape", the parts to shade as a string of
+// comma-separated indices starting at 0 with "shaded", and arbitrary options as
+// a string
+func VerifyFractionShapeDict(dict map[string]interface{}) (FractionShape, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"reference", "denominator", "shape", "shaded", "options"}
+	mandatory := []string{"reference", "denominator"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return FractionShape{}, fmt.Errorf("Mandatory key '%v' for defining a fraction shape not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var err error
+	var reference string
+	var denominator int
+	if reference, ok = dict["reference"].(string); !ok {
+		return FractionShape{}, errors.New("The reference of a fraction shape should be given as a string")
+	}
+	if denominator, err = helpers.Atoi(dict["denominator"]); err != nil || denominator < 1 {
+		return FractionShape{}, errors.New("The denominator of a fraction shape should be given as a positive integer")
+	}
+
+	// now, perform the same operation with the optional parameters
+	shape := FRACTIONPIE
+	if _, ok := dict["shape"]; ok {
+		if shape, ok = dict["shape"].(string); !ok || (shape != FRACTIONPIE && shape != FRACTIONBAR) {
+			return FractionShape{}, fmt.Errorf("The shape of a fraction shape should be either '%v' or '%v'", FRACTIONPIE, FRACTIONBAR)
+		}
+	}
+	shaded := make([]bool, denominator)
+	if _, ok := dict["shaded"]; ok {
+		var parts string
+		if parts, ok = dict["shaded"].(string); !ok {
+			return FractionShape{}, errors.New("The shaded parts of a fraction shape should be given as a string")
+		}
+		for _, part := range strings.Split(parts, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= denominator {
+				return FractionShape{}, fmt.Errorf("The shaded part '%v' of a fraction shape should be an integer in the range [0, %v]", part, denominator-1)
+			}
+			shaded[idx] = true
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return FractionShape{}, errors.New("The options of a fraction shape should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a fraction shape and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid fraction shape
+	return FractionShape{
+		reference:     reference,
+		shape:         shape,
+		shaded:        shaded,
+		BaseRectangle: BaseRectangle{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// return the options used for drawing the given part, which is filled if it is
+// shaded
+func (f FractionShape) partOptions(part int) string {
+
+	var options []string
+	if f.options != "" {
+		options = append(options, f.options)
+	}
+	if f.shaded[part] {
+		options = append(options, "fill="+FRACTIONSHADE)
+	}
+	return strings.Join(options, ", ")
+}
+
+// return the angles in degrees where the given part of a circle starts and
+// ends. Parts are located clockwise starting from the top
+func (f FractionShape) angles(part int) (float64, float64) {
+
+	step := 360.0 / float64(len(f.shaded))
+	return 90.0 - step*float64(part), 90.0 - step*float64(part+1)
+}
+
+// return the horizontal offsets with respect to the center where the given part
+// of a bar starts and ends
+func (f FractionShape) offsets(part int) (float64, float64) {
+
+	step := FRACTIONBARWIDTH / float64(len(f.shaded))
+	return -FRACTIONBARWIDTH/2.0 + step*float64(part), -FRACTIONBARWIDTH/2.0 + step*float64(part+1)
+}
+
+// Return the reference of the center of this fraction shape
+func (f FractionShape) GetReference() string {
+	return f.reference
+}
+
+// Return the number of parts of this fraction shape
+func (f FractionShape) GetDenominator() int {
+	return len(f.shaded)
+}
+
+// Return the number of parts which are shaded
+func (f FractionShape) GetNumerator() int {
+
+	result := 0
+	for _, shaded := range f.shaded {
+		if shaded {
+			result++
+		}
+	}
+	return result
+}
+
+// Return the width in centimeters taken by this fraction shape
+func (f FractionShape) GetWidth() float64 {
+
+	if f.shape == FRACTIONBAR {
+		return FRACTIONBARWIDTH
+	}
+	return 2.0 * FRACTIONPIERADIUS
+}
+
+// Return the height in centimeters taken by this fraction shape
+func (f FractionShape) GetHeight() float64 {
+
+	if f.shape == FRACTIONBAR {
+		return FRACTIONBARHEIGHT
+	}
+	return 2.0 * FRACTIONPIERADIUS
+}
+
+// Return the TikZ code for drawing all parts of this fraction shape
+func (f FractionShape) GetParts() string {
+
+	code := tikzFractionSlice
+	if f.shape == FRACTIONBAR {
+		code = tikzFractionBar
+	}
+	tpl, err := template.New("part").Parse(code)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Use a btyes buffer to append the strings of each part
+	var output bytes.Buffer
+	for part := range f.shaded {
+		angle0, angle1 := f.angles(part)
+		x0, x1 := f.offsets(part)
+		data := struct{ Options, Reference, Radius, Angle0, Angle1, X0, Y0, X1, Y1 string }{
+			f.partOptions(part), f.reference,
+			helpers.Ftoa(FRACTIONPIERADIUS), helpers.Ftoa(angle0), helpers.Ftoa(angle1),
+			helpers.Ftoa(x0), helpers.Ftoa(-FRACTIONBARHEIGHT / 2.0),
+			helpers.Ftoa(x1), helpers.Ftoa(FRACTIONBARHEIGHT / 2.0)}
+		if err := tpl.Execute(&output, data); err != nil {
+			log.Fatal(err)
+		}
+		output.WriteString("\n")
+	}
+
+	// and return the concatenation of the TikZ code used for drawing all
+	// parts
+	return output.String()
+}
+
+// Finally, fraction shapes are stringers and these are the means provided for
+// automatically reusing this component
+func (f FractionShape) String() string {
+	return f.GetParts()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
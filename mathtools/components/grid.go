@@ -0,0 +1,347 @@
+// -*- coding: utf-8 -*-
+// grid.go
+//
+// Description: Definition of grids of equally sized cells, each one with a
+//              text which might be framed, as reusable components to be used
+//              in TikZ drawings
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:31:46.703076124 (1792114306)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// TikZ code to generate grids: framed cells are drawn first with the given
+// options, and then the text of every cell is written at its center
+const tikzGrid = `{{.GetFrames}}{{.GetTexts}}`
+const tikzGridFrame = `\draw [{{.Options}}] ({{.Corner0}}) rectangle ({{.Corner1}});`
+const tikzGridText = `\draw ({{.Center}}) node { {{.Text}} };`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A grid consists of a number of rows of cells, all with the same width and
+// height given in centimeters. The upper-left corner of the grid is located at
+// its origin, which is given as the name of a label, and rows go downwards.
+// Every cell has a text, which is not shown if it is empty, and it can be
+// framed. Additionally, an arbitrary number of options can be given as a
+// comma-separated string for drawing the frames, e.g., the line width
+type Grid struct {
+	origin        string
+	width, height float64
+	texts         [][]string
+	framed        [][]bool
+	BaseRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new instance of a grid given the label of its origin, the width and
+// height of every cell in centimeters, the text of every cell and whether they
+// are framed or not. Note that the options are specified through a dedicated
+// service
+func NewGrid(origin string, width, height float64, texts [][]string, framed [][]bool) Grid {
+	return Grid{
+		origin: origin,
+		width:  width,
+		height: height,
+		texts:  texts,
+		framed: framed,
+	}
+}
+
+// Create a new instance of a grid with the given number of rows and columns of
+// square cells with the given side in centimeters whose upper-left corner is
+// located at the given origin. All cells are empty and framed, so that it can
+// be used for drawing coordinate planes, hundred charts or area models. Note
+// that the options are specified through a dedicated service
+func NewBlankGrid(origin string, rows, cols int, step float64) Grid {
+
+	texts := make([][]string, rows)
+	framed := make([][]bool, rows)
+	for row := 0; row < rows; row++ {
+		texts[row] = make([]string, cols)
+		framed[row] = make([]bool, cols)
+		for col := range framed[row] {
+			framed[row][col] = true
+		}
+	}
+	return NewGrid(origin, step, step, texts, framed)
+}
+
+// return a valid specification of a grid with no error if all the keys given
+// in dict are correct for defining a grid. Otherwise, return an error. If an
+// error is returned, the contents of the grid are undefined
+//
+// A dictionary is correct if and only if it correctly defines the label of the
+// origin as a string with the keyword "origin", and either the text of all
+// cells as a string with "cells", where rows are separated by semicolons and
+// cells by commas, or the number of rows and columns of a grid of empty cells
+// with "rows" and "cols". Cells whose text is enclosed in square brackets,
+// e.g., "[5]" or "[]", are framed, whereas all cells of a grid given with
+// "rows" and "cols" are framed. The width and height of every cell are given
+// with "width" and "height" or both at once with "step", and cells are one
+// centimeter wide and high by default. In addition, it is also possible to
+// specify arbitrary options as a string
+func VerifyGridDict(dict map[string]interface{}) (Grid, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"origin", "width", "height", "step", "cells", "rows", "cols", "options"}
+	mandatory := []string{"origin"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return Grid{}, fmt.Errorf("Mandatory key '%v' for defining a grid not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var err error
+	var origin string
+	if origin, ok = dict["origin"].(string); !ok {
+		return Grid{}, errors.New("The origin of a grid should be given as a string")
+	}
+
+	// the size of the cells is given either with the step or with their width
+	// and height, the latter taking precedence
+	width, height := 1.0, 1.0
+	if _, ok := dict["step"]; ok {
+		if width, err = helpers.Atof(dict["step"]); err != nil || width <= 0 {
+			return Grid{}, errors.New("The step of a grid should be given as a positive number")
+		}
+		height = width
+	}
+	if _, ok := dict["width"]; ok {
+		if width, err = helpers.Atof(dict["width"]); err != nil || width <= 0 {
+			return Grid{}, errors.New("The width of the cells of a grid should be given as a positive number")
+		}
+	}
+	if _, ok := dict["height"]; ok {
+		if height, err = helpers.Atof(dict["height"]); err != nil || height <= 0 {
+			return Grid{}, errors.New("The height of the cells of a grid should be given as a positive number")
+		}
+	}
+
+	// the cells are given either explicitly or with the number of rows and
+	// columns of a grid of empty framed cells
+	var cells string
+	var rows, cols int
+	if _, ok := dict["cells"]; ok {
+		if cells, ok = dict["cells"].(string); !ok {
+			return Grid{}, errors.New("The cells of a grid should be given as a string")
+		}
+	} else {
+		_, okRows := dict["rows"]
+		_, okCols := dict["cols"]
+		if !okRows || !okCols {
+			return Grid{}, errors.New("Either the key 'cells' or both keys 'rows' and 'cols' should be given for defining a grid")
+		}
+		if rows, err = helpers.Atoi(dict["rows"]); err != nil || rows <= 0 {
+			return Grid{}, errors.New("The number of rows of a grid should be given as a positive integer")
+		}
+		if cols, err = helpers.Atoi(dict["cols"]); err != nil || cols <= 0 {
+			return Grid{}, errors.New("The number of columns of a grid should be given as a positive integer")
+		}
+	}
+
+	// now, perform the same operation with the optional parameters
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return Grid{}, errors.New("The options of a grid should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a grid and it will be ignored", key)
+		}
+	}
+
+	// if no cells were given, then return a grid of empty framed cells
+	if cells == "" && rows > 0 {
+		grid := NewBlankGrid(origin, rows, cols, width)
+		grid.height = height
+		grid.SetOptions(options)
+		return grid, nil
+	}
+
+	// parse the text of all cells, and whether they are framed or not
+	var texts [][]string
+	var framed [][]bool
+	for _, row := range strings.Split(cells, ";") {
+		var rowTexts []string
+		var rowFramed []bool
+		for _, cell := range strings.Split(row, ",") {
+			cell = strings.TrimSpace(cell)
+			frame := strings.HasPrefix(cell, "[") && strings.HasSuffix(cell, "]")
+			if frame {
+				cell = cell[1 : len(cell)-1]
+			}
+			rowTexts = append(rowTexts, cell)
+			rowFramed = append(rowFramed, frame)
+		}
+		texts = append(texts, rowTexts)
+		framed = append(framed, rowFramed)
+	}
+
+	// At this point, the dictionary is correct, return a valid grid
+	return Grid{
+		origin:        origin,
+		width:         width,
+		height:        height,
+		texts:         texts,
+		framed:        framed,
+		BaseRectangle: BaseRectangle{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// return the reference of the point located at the given number of cells to
+// the right of and below the origin. Note that it can be fractional
+func (g Grid) at(col, row float64) string {
+	return fmt.Sprintf("$(%v) + (%vcm, %vcm)$", g.origin,
+		helpers.Ftoa(col*g.width), helpers.Ftoa(0.0-row*g.height))
+}
+
+// Return the number of rows of this grid
+func (g Grid) GetNbRows() int {
+	return len(g.texts)
+}
+
+// Return the width of this grid in centimeters, which is given by its longest
+// row
+func (g Grid) GetWidth() float64 {
+
+	nbcols := 0
+	for _, row := range g.texts {
+		nbcols = int(helpers.Max(float64(nbcols), float64(len(row))))
+	}
+	return float64(nbcols) * g.width
+}
+
+// Return the height of this grid in centimeters
+func (g Grid) GetHeight() float64 {
+	return float64(len(g.texts)) * g.height
+}
+
+// Return the reference of the center of the cell in the given row and column,
+// so that other components can be placed with respect to it
+func (g Grid) GetCell(row, col int) string {
+	return g.at(float64(col)+0.5, float64(row)+0.5)
+}
+
+// Return the TikZ code for drawing the frames of all framed cells
+func (g Grid) GetFrames() string {
+
+	tpl, err := template.New("frame").Parse(tikzGridFrame)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Use a btyes buffer to append the strings of each frame
+	var output bytes.Buffer
+	for row := range g.texts {
+		for col := range g.texts[row] {
+			if !g.framed[row][col] {
+				continue
+			}
+			data := struct{ Options, Corner0, Corner1 string }{
+				g.options,
+				g.at(float64(col), float64(row+1)),
+				g.at(float64(col+1), float64(row))}
+			if err := tpl.Execute(&output, data); err != nil {
+				log.Fatal(err)
+			}
+			output.WriteString("\n")
+		}
+	}
+
+	// and return the concatenation of the TikZ code used for drawing all
+	// frames
+	return output.String()
+}
+
+// Return the TikZ code for writing the text of all cells which is not empty
+func (g Grid) GetTexts() string {
+
+	tpl, err := template.New("text").Parse(tikzGridText)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Use a btyes buffer to append the strings of each text
+	var output bytes.Buffer
+	for row := range g.texts {
+		for col, text := range g.texts[row] {
+			if text == "" {
+				continue
+			}
+			data := struct{ Center, Text string }{g.GetCell(row, col), text}
+			if err := tpl.Execute(&output, data); err != nil {
+				log.Fatal(err)
+			}
+			output.WriteString("\n")
+		}
+	}
+
+	// and return the concatenation of the TikZ code used for writing all
+	// texts
+	return output.String()
+}
+
+// Finally, grids are stringers and these are the means provided for
+// automatically reusing this component
+func (g Grid) String() string {
+
+	// create a template with the TikZ code for showing a grid
+	tpl, err := template.New("grid").Parse(tikzGrid)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, g); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -178,7 +178,7 @@ func VerifyLineDict(dict map[string]interface{}) (Line, error) {
 
 				// if this was not a reference to an end-point, then it is
 				// clearly an unnecessary argument
-				log.Printf("The parameter '%v' is not acknowledged for creating a line and it will be ignored")
+				log.Printf("The parameter '%v' is not acknowledged for creating a line and it will be ignored", key)
 			}
 		}
 	}
@@ -265,10 +265,12 @@ func (line Line) GetSegments() string {
 			log.Fatal(err)
 		}
 
-		// and now make the appropriate substitution. Note that the execution of the
-		// template is written to a string
-		if err := tpl.Execute(&tplOutput, line); err != nil {
-			log.Fatal(err)
+		// and now make the appropriate substitution for every end-point. Note
+		// that the execution of the template is written to a string
+		for _, ref := range line.refs[2:] {
+			if err := tpl.Execute(&tplOutput, struct{ GetNextReference string }{ref}); err != nil {
+				log.Fatal(err)
+			}
 		}
 	}
 
@@ -0,0 +1,210 @@
+// -*- coding: utf-8 -*-
+// money.go
+//
+// Description: Definition of coins and bills as reusable components to be used
+//              in TikZ drawings
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:14:32.036197900 (1792113272)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Coins are drawn as circles with the following radius and bills as rectangles
+// with the following width and height, all given in centimeters
+const MONEYCOINRADIUS float64 = 0.45
+const MONEYBILLWIDTH float64 = 1.8
+const MONEYBILLHEIGHT float64 = 0.9
+
+// TikZ code to generate coins and bills: the shape is drawn around the
+// reference with the given options, and the value is written inside it
+const tikzCoin = `\draw [{{.GetOptions}}] ({{.GetReference}}) circle ({{.GetRadius}}cm);
+\draw ({{.GetReference}}) node { \scriptsize {{.GetValue}} };`
+const tikzBill = `\draw [{{.GetOptions}}] ($({{.GetReference}}) - ({{.GetHalfWidth}}cm, {{.GetHalfHeight}}cm)$) rectangle ($({{.GetReference}}) + ({{.GetHalfWidth}}cm, {{.GetHalfHeight}}cm)$);
+\draw ({{.GetReference}}) node { \small {{.GetValue}} };`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A money component is either a coin or a bill centered at the given reference
+// (either the name of a label or a formula) with its value written inside.
+// Additionally, an arbitrary number of options can be given as a
+// comma-separated string for drawing its shape, e.g., the fill color
+type Money struct {
+	reference string
+	value     string
+	bill      bool
+	BaseRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new coin (if bill is false) or bill (otherwise) centered at the
+// given reference with the given value. Note that the options are specified
+// through a dedicated service
+func NewMoney(reference, value string, bill bool) Money {
+	return Money{
+		reference: reference,
+		value:     value,
+		bill:      bill,
+	}
+}
+
+// return a valid specification of a coin or bill with no error if all the keys
+// given in dict are correct for defining it. Otherwise, return an error. If an
+// error is returned, the contents of the money component are undefined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// its center as a string with the keyword "reference", and its value as a
+// string with "value". These are the only mandatory arguments. In addition, it
+// is also possible to specify whether it is a bill with "bill" and arbitrary
+// options as a string
+func VerifyMoneyDict(dict map[string]interface{}) (Money, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"reference", "value", "bill", "options"}
+	mandatory := []string{"reference", "value"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return Money{}, fmt.Errorf("Mandatory key '%v' for defining a coin or bill not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var err error
+	var reference, value string
+	if reference, ok = dict["reference"].(string); !ok {
+		return Money{}, errors.New("The reference of a coin or bill should be given as a string")
+	}
+	if value, ok = dict["value"].(string); !ok {
+		return Money{}, errors.New("The value of a coin or bill should be given as a string")
+	}
+
+	// now, perform the same operation with the optional parameters
+	var bill bool
+	if _, ok := dict["bill"]; ok {
+		if bill, err = helpers.Atob(dict["bill"]); err != nil {
+			return Money{}, errors.New("Whether money is a bill or not should be given as a boolean")
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return Money{}, errors.New("The options of a coin or bill should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a coin or bill and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid money component
+	return Money{
+		reference:     reference,
+		value:         value,
+		bill:          bill,
+		BaseRectangle: BaseRectangle{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// Return the reference of the center of this coin or bill
+func (m Money) GetReference() string {
+	return m.reference
+}
+
+// Return the value written inside this coin or bill
+func (m Money) GetValue() string {
+	return m.value
+}
+
+// Return whether this is a bill or not
+func (m Money) IsBill() bool {
+	return m.bill
+}
+
+// Return the width in centimeters taken by this coin or bill
+func (m Money) GetWidth() float64 {
+
+	if m.bill {
+		return MONEYBILLWIDTH
+	}
+	return 2.0 * MONEYCOINRADIUS
+}
+
+// Return the radius of coins
+func (m Money) GetRadius() string {
+	return helpers.Ftoa(MONEYCOINRADIUS)
+}
+
+// Return half the width of bills
+func (m Money) GetHalfWidth() string {
+	return helpers.Ftoa(MONEYBILLWIDTH / 2.0)
+}
+
+// Return half the height of bills
+func (m Money) GetHalfHeight() string {
+	return helpers.Ftoa(MONEYBILLHEIGHT / 2.0)
+}
+
+// Finally, coins and bills are stringers and these are the means provided for
+// automatically reusing this component
+func (m Money) String() string {
+
+	// create a template with the TikZ code for showing either a coin or a bill
+	code := tikzCoin
+	if m.bill {
+		code = tikzBill
+	}
+	tpl, err := template.New("money").Parse(code)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, m); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
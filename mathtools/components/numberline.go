@@ -0,0 +1,247 @@
+// -*- coding: utf-8 -*-
+// numberline.go
+//
+// Description: Definition of number lines, i.e., lines with equally spaced
+//              ticks which can be labeled, as reusable components to be used
+//              in TikZ drawings
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:40:35.400473833 (1792111235)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// TikZ code to generate number lines: the axis is drawn first with the given
+// options, and then every tick is drawn along with its label, if any
+const tikzNumberLine = `\draw [{{.GetOptions}}] {{.GetAxis}};
+{{.GetTicks}}`
+
+// Ticks are drawn as short segments crossing the axis at their reference. The
+// label is shown below the tick in horizontal number lines and to its left in
+// vertical number lines
+const tikzHorizontalTick = `\draw ({{.Reference}}) ++(0, -4pt) -- ++(0, 8pt);`
+const tikzVerticalTick = `\draw ({{.Reference}}) ++(-4pt, 0) -- ++(8pt, 0);`
+const tikzHorizontalLabel = `\draw ({{.Reference}}) node [below=6pt] { {{.Label}} };`
+const tikzVerticalLabel = `\draw ({{.Reference}}) node [left=6pt] { {{.Label}} };`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A number line consists of a number of equally spaced ticks, each one with a
+// label which is not shown if it is empty. The first tick is located at the
+// origin, which is given as a reference (either the name of a label or a
+// formula), and the following ones are separated by the given distance in
+// centimeters either to the right (horizontal number lines) or upwards
+// (vertical number lines). The axis extends half the separation beyond the
+// first and last ticks. Additionally, an arbitrary number of options can be
+// given as a comma-separated string for drawing the axis, e.g., arrows
+type NumberLine struct {
+	origin     string
+	separation float64
+	labels     []string
+	vertical   bool
+	BaseLine
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new instance of a number line given the reference of its origin,
+// the separation between consecutive ticks in centimeters, the labels of every
+// tick and whether it is vertical or not. Note that the options are specified
+// through a dedicated service
+func NewNumberLine(origin string, separation float64, labels []string, vertical bool) NumberLine {
+	return NumberLine{
+		origin:     origin,
+		separation: separation,
+		labels:     labels,
+		vertical:   vertical,
+	}
+}
+
+// return a valid specification of a number line with no error if all the keys
+// given in dict are correct for defining a number line. Otherwise, return an
+// error. If an error is returned, the contents of the number line are undefined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// the origin as a string with the keyword "origin", the separation between
+// consecutive ticks with "separation", and the labels of all ticks as a
+// comma-separated string with "labels". These are the only mandatory
+// arguments. In addition, it is also possible to specify whether the number
+// line is vertical with "vertical" and arbitrary options as a string
+func VerifyNumberLineDict(dict map[string]interface{}) (NumberLine, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"origin", "separation", "labels", "vertical", "options"}
+	mandatory := []string{"origin", "separation", "labels"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return NumberLine{}, fmt.Errorf("Mandatory key '%v' for defining a number line not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var err error
+	var origin, labels string
+	var separation float64
+	if origin, ok = dict["origin"].(string); !ok {
+		return NumberLine{}, errors.New("The origin of a number line should be given as a string")
+	}
+	if separation, err = helpers.Atof(dict["separation"]); err != nil || separation <= 0 {
+		return NumberLine{}, errors.New("The separation between ticks of a number line should be given as a positive number")
+	}
+	if labels, ok = dict["labels"].(string); !ok {
+		return NumberLine{}, errors.New("The labels of a number line should be given as a comma-separated string")
+	}
+
+	// now, perform the same operation with the optional parameters
+	var vertical bool
+	if _, ok := dict["vertical"]; ok {
+		if vertical, err = helpers.Atob(dict["vertical"]); err != nil {
+			return NumberLine{}, errors.New("Whether a number line is vertical or not should be given as a boolean")
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return NumberLine{}, errors.New("The options of a number line should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a number line and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid number line
+	return NumberLine{
+		origin:     origin,
+		separation: separation,
+		labels:     strings.Split(labels, ","),
+		vertical:   vertical,
+		BaseLine:   BaseLine{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// return the reference of the point of the axis located at the given number
+// of separations from the origin. Note that it can be fractional
+func (nl NumberLine) at(distance float64) string {
+
+	if nl.vertical {
+		return fmt.Sprintf("$(%v) + (0, %vcm)$", nl.origin, helpers.Ftoa(distance*nl.separation))
+	}
+	return fmt.Sprintf("$(%v) + (%vcm, 0)$", nl.origin, helpers.Ftoa(distance*nl.separation))
+}
+
+// Return the number of ticks of this number line
+func (nl NumberLine) GetNbTicks() int {
+	return len(nl.labels)
+}
+
+// Return the reference of the i-th tick of this number line, so that other
+// components can be placed with respect to it
+func (nl NumberLine) GetTick(i int) string {
+	return nl.at(float64(i))
+}
+
+// Return the segment used for drawing the axis of the number line
+func (nl NumberLine) GetAxis() string {
+	return fmt.Sprintf("(%v) -- (%v)", nl.at(-0.5), nl.at(float64(len(nl.labels))-0.5))
+}
+
+// Return the TikZ code for drawing all ticks along with their labels
+func (nl NumberLine) GetTicks() string {
+
+	// select the templates to use depending on the orientation
+	tick, label := tikzHorizontalTick, tikzHorizontalLabel
+	if nl.vertical {
+		tick, label = tikzVerticalTick, tikzVerticalLabel
+	}
+	tplTick, err := template.New("tick").Parse(tick)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tplLabel, err := template.New("label").Parse(label)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Use a btyes buffer to append the strings of each tick
+	var output bytes.Buffer
+	for i, text := range nl.labels {
+
+		// every tick is drawn, but only non-empty labels are shown
+		data := struct{ Reference, Label string }{nl.GetTick(i), text}
+		if err := tplTick.Execute(&output, data); err != nil {
+			log.Fatal(err)
+		}
+		output.WriteString("\n")
+		if text != "" {
+			if err := tplLabel.Execute(&output, data); err != nil {
+				log.Fatal(err)
+			}
+			output.WriteString("\n")
+		}
+	}
+
+	// and return the concatenation of the TikZ code used for drawing all ticks
+	return output.String()
+}
+
+// Finally, number lines are stringers and these are the means provided for
+// automatically reusing this component
+func (nl NumberLine) String() string {
+
+	// create a template with the TikZ code for showing a number line
+	tpl, err := template.New("numberline").Parse(tikzNumberLine)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, nl); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,339 @@
+// -*- coding: utf-8 -*-
+// polygon.go
+//
+// Description: Definition of arbitrary and regular polygons as closed paths of
+// references with additional options
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 15:47:25.091734562 (1792165645)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// TikZ code to generate polygons: the segments between consecutive vertices are
+// drawn as in lines, and the path is closed so that the polygon can be filled
+const tikzPolygon = `\draw [{{.GetOptions}}] {{.GetSegments}} -- cycle;`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A polygon is a closed line, i.e., a sequence of vertices each one identified
+// with a string which might represent a coordinate explicitly given, or a
+// formula, or the name of a label. A polygon to be correct should contain at
+// least three vertices. The options of its line are used both for drawing and
+// filling it, e.g., "fill=LightBlue"
+type Polygon struct {
+	Line
+}
+
+// A regular polygon is given by the reference of its center (either the name
+// of a label or a formula), its number of sides and its radius, i.e., the
+// distance from its center to every vertex in centimeters. By default, its
+// first vertex is right above its center, but it can be rotated
+// counterclockwise any number of degrees
+type RegularPolygon struct {
+	reference string
+	sides     int
+	radius    float64
+	rotation  float64
+	BaseLine
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new instance of a polygon given an arbitrary number of vertices.
+// Note that the options are specified through a dedicated service.
+//
+// A polygon to be correct should consist of at least three vertices but it is
+// possible to provide an arbitrary number of them
+func NewPolygon(ref0, ref1, ref2 string, refs ...string) Polygon {
+	return Polygon{
+		Line: NewLine(ref0, ref1, append([]string{ref2}, refs...)...),
+	}
+}
+
+// Create a new instance of a regular polygon centered at the given reference
+// with the given number of sides and radius. Note that the rotation and the
+// options are specified through dedicated services
+func NewRegularPolygon(reference string, sides int, radius float64) RegularPolygon {
+	return RegularPolygon{
+		reference: reference,
+		sides:     sides,
+		radius:    radius,
+	}
+}
+
+// return a valid specification of a polygon with no error if all the keys
+// given in dict are correct for defining a polygon. Otherwise, return an error.
+// If an error is returned, the contents of the polygon are undefined.
+//
+// A dictionary is correct if and only if it correctly defines a sequence of
+// vertices, each one identified with the next number after the keyword "ref",
+// i.e., "ref0", "ref1", "ref2", etc. These are the only mandatory arguments and
+// there can be an arbitrary number of them, though at least three should be
+// given. In addition, it is also possible to specify arbitrary options as a
+// string
+func VerifyPolygonDict(dict map[string]interface{}) (Polygon, error) {
+
+	// First of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments. Note that, still, there can be more vertices: "ref3", "ref4",
+	// etc.
+	all := []string{"ref0", "ref1", "ref2", "options"}
+	mandatory := []string{"ref0", "ref1", "ref2"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return Polygon{}, fmt.Errorf("Mandatory key '%v' for defining a polygon not found", key)
+		}
+	}
+
+	// now ensure that all vertices are of the right type. Note that after this
+	// process, nextidx gets the integer value of the last vertex plus one
+	var refs []string
+	nextidx := 0
+	for {
+
+		// the next vertex to look for is of the form "refi" where i is the
+		// next integer index
+		nextref := fmt.Sprintf("ref%v", nextidx)
+
+		// in case the next vertex has been found, process it
+		if _, ok := dict[nextref]; ok {
+			if _, ok := dict[nextref].(string); !ok {
+				return Polygon{}, errors.New("Every vertex of a polygon should be given as a string")
+			}
+			refs = append(refs, dict[nextref].(string))
+		} else {
+
+			// if no more vertices have been found, exit of the loop
+			break
+		}
+
+		// and increment the index
+		nextidx++
+	}
+
+	// now, perform the same operation with the optional parameters
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return Polygon{}, errors.New("The options of a polygon should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknowledged,
+	// issue a warning
+	for key, _ := range dict {
+
+		// if a key is not found in the list of all arguments then it might be
+		// an unnecessary argument unless it is one of the vertices
+		if !helpers.Find(key, all) {
+			r, _ := regexp.Compile(`ref(\d+)$`)
+			if r.MatchString(key) {
+
+				// if this index went beyond the last index processed then it is
+				// unnecessary, most likely because an intermediate vertex has
+				// not been specified
+				idx, _ := helpers.Atoi(r.FindStringSubmatch(key)[1])
+				if idx > nextidx {
+					log.Printf("A reference to a vertex '%v' has been found, but at least the previous one was not given. The last processed vertex has index %v", key, nextidx-1)
+				}
+			} else {
+				log.Printf("The parameter '%v' is not acknowledged for creating a polygon and it will be ignored", key)
+			}
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid polygon
+	return Polygon{
+		Line: Line{
+			refs:     refs,
+			BaseLine: BaseLine{options: options},
+		},
+	}, nil
+}
+
+// return a valid specification of a regular polygon with no error if all the
+// keys given in dict are correct for defining it. Otherwise, return an error.
+// If an error is returned, the contents of the regular polygon are undefined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// its center as a string with the keyword "reference", its number of sides
+// with "sides" and its radius with "radius". These are the only mandatory
+// arguments. In addition, it is also possible to specify its rotation in
+// degrees with "rotation" and arbitrary options as a string
+func VerifyRegularPolygonDict(dict map[string]interface{}) (RegularPolygon, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"reference", "sides", "radius", "rotation", "options"}
+	mandatory := []string{"reference", "sides", "radius"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return RegularPolygon{}, fmt.Errorf("Mandatory key '%v' for defining a regular polygon not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var err error
+	var reference string
+	var sides int
+	var radius float64
+	if reference, ok = dict["reference"].(string); !ok {
+		return RegularPolygon{}, errors.New("The reference of the center of a regular polygon should be given as a string")
+	}
+	if sides, err = helpers.Atoi(dict["sides"]); err != nil || sides < 3 {
+		return RegularPolygon{}, errors.New("The number of sides of a regular polygon should be given as an integer greater or equal than 3")
+	}
+	if radius, err = helpers.Atof(dict["radius"]); err != nil || radius <= 0 {
+		return RegularPolygon{}, errors.New("The radius of a regular polygon should be given as a positive number")
+	}
+
+	// now, perform the same operation with the optional parameters
+	var rotation float64
+	if _, ok := dict["rotation"]; ok {
+		if rotation, err = helpers.Atof(dict["rotation"]); err != nil {
+			return RegularPolygon{}, errors.New("The rotation of a regular polygon should be given as a number")
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return RegularPolygon{}, errors.New("The options of a regular polygon should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a regular polygon and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid regular polygon
+	return RegularPolygon{
+		reference: reference,
+		sides:     sides,
+		radius:    radius,
+		rotation:  rotation,
+		BaseLine:  BaseLine{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// --Polygon
+
+// Return the number of vertices of this polygon
+func (p Polygon) GetNbVertices() int {
+	return len(p.refs)
+}
+
+// Return the reference of the i-th vertex of this polygon starting from zero
+func (p Polygon) GetVertex(i int) string {
+	return p.refs[i]
+}
+
+// Finally, polygons are stringers and these are the means provided for
+// automatically reusing this component
+func (p Polygon) String() string {
+
+	// create a template with the TikZ code for showing a polygon
+	tpl, err := template.New("polygon").Parse(tikzPolygon)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, p); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// --RegularPolygon
+
+// Set the rotation of this regular polygon in degrees counterclockwise
+func (p *RegularPolygon) SetRotation(rotation float64) {
+	p.rotation = rotation
+}
+
+// Return the reference of the i-th vertex of this regular polygon starting
+// from zero, counterclockwise from the one right above its center (unless it
+// is rotated)
+func (p RegularPolygon) GetVertex(i int) string {
+
+	// offsets which are zero up to rounding errors are shown as zero
+	angle := (90.0 + p.rotation + 360.0*float64(i)/float64(p.sides)) * math.Pi / 180.0
+	x, y := p.radius*math.Cos(angle), p.radius*math.Sin(angle)
+	if math.Abs(x) < 1e-9 {
+		x = 0
+	}
+	if math.Abs(y) < 1e-9 {
+		y = 0
+	}
+	return fmt.Sprintf("$(%v) + (%vcm, %vcm)$", p.reference, helpers.Ftoa(x), helpers.Ftoa(y))
+}
+
+// Return the polygon with the vertices of this regular polygon and the same
+// options
+func (p RegularPolygon) GetPolygon() Polygon {
+
+	var refs []string
+	for i := 0; i < p.sides; i++ {
+		refs = append(refs, p.GetVertex(i))
+	}
+	polygon := NewPolygon(refs[0], refs[1], refs[2], refs[3:]...)
+	polygon.SetOptions(p.options)
+	return polygon
+}
+
+// Finally, regular polygons are stringers and these are the means provided for
+// automatically reusing this component
+func (p RegularPolygon) String() string {
+	return p.GetPolygon().String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
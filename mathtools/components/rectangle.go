@@ -138,7 +138,7 @@ func VerifyRectangleDict(dict map[string]interface{}) (Rectangle, error) {
 	// issue a warning
 	for key, _ := range dict {
 		if !helpers.Find(key, all) {
-			log.Printf("The parameter '%v' is not acknowledged for creating a rectangle and it will be ignored")
+			log.Printf("The parameter '%v' is not acknowledged for creating a rectangle and it will be ignored", key)
 		}
 	}
 
@@ -0,0 +1,200 @@
+// -*- coding: utf-8 -*-
+// segment.go
+//
+// Description: Definition of segments between two references labeled with
+// their length
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 15:24:11.350926481 (1792164251)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// TikZ code to generate a measured segment: it draws a straight line from one
+// reference to another and shows its length above its midpoint. If a brace is
+// requested, the length is shown above a brace drawn along the segment, which
+// requires the TikZ library decorations.pathreplacing
+const tikzMeasuredSegment = `\draw [{{.GetOptions}}] ({{.GetFrom}}) -- ({{.GetTo}}){{if not .GetBrace}} node [midway, sloped, above] { {{- .GetLength -}} }{{end}};
+{{- if .GetBrace}}
+\draw [decorate, decoration={brace, amplitude=5pt, raise=2pt}] ({{.GetFrom}}) -- ({{.GetTo}}) node [midway, sloped, above=7pt] { {{- .GetLength -}} };
+{{- end}}`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A measured segment goes from one reference to another (either the names of
+// labels or formulas) and it is labeled with its length, which is given as
+// text so that it can be either a number, an unknown or any LaTeX
+// expression. The length is shown either next to the segment or over a brace
+// drawn along it. Additionally, an arbitrary number of options can be given as
+// a comma-separated string, e.g., the line width
+type MeasuredSegment struct {
+	from, to string
+	length   string
+	brace    bool
+	BaseLine
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new segment from one reference to another labeled with the given
+// length. Note that the options and whether a brace is drawn are specified
+// through dedicated services
+func NewMeasuredSegment(from, to, length string) MeasuredSegment {
+	return MeasuredSegment{
+		from:   from,
+		to:     to,
+		length: length,
+	}
+}
+
+// return a valid specification of a measured segment with no error if all the
+// keys given in dict are correct for defining it. Otherwise, return an error.
+// If an error is returned, the contents of the segment are undefined
+//
+// A dictionary is correct if and only if it correctly defines the references
+// of its end-points as strings with the keywords "from" and "to", and its
+// length with "length", either as a string or a number. These are the only
+// mandatory arguments. In addition, it is also possible to specify whether the
+// length is shown over a brace with "brace" and arbitrary options as a string
+func VerifyMeasuredSegmentDict(dict map[string]interface{}) (MeasuredSegment, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"from", "to", "length", "brace", "options"}
+	mandatory := []string{"from", "to", "length"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return MeasuredSegment{}, fmt.Errorf("Mandatory key '%v' for defining a measured segment not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var from, to, length string
+	if from, ok = dict["from"].(string); !ok {
+		return MeasuredSegment{}, errors.New("The first end-point of a measured segment should be given as a string")
+	}
+	if to, ok = dict["to"].(string); !ok {
+		return MeasuredSegment{}, errors.New("The second end-point of a measured segment should be given as a string")
+	}
+	switch value := dict["length"].(type) {
+	case string:
+		length = value
+	case int:
+		length = fmt.Sprintf("%v", value)
+	case float64:
+		length = helpers.Ftoa(value)
+	default:
+		return MeasuredSegment{}, errors.New("The length of a measured segment should be given either as a string or a number")
+	}
+
+	// now, perform the same operation with the optional parameters
+	var err error
+	var brace bool
+	if _, ok := dict["brace"]; ok {
+		if brace, err = helpers.Atob(dict["brace"]); err != nil {
+			return MeasuredSegment{}, errors.New("Whether the length of a measured segment is shown over a brace should be given as a boolean")
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if options, ok = dict["options"].(string); !ok {
+			return MeasuredSegment{}, errors.New("The options of a measured segment should be given as a string")
+		}
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a measured segment and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid segment
+	return MeasuredSegment{
+		from:     from,
+		to:       to,
+		length:   length,
+		brace:    brace,
+		BaseLine: BaseLine{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// Set whether the length of this segment is shown over a brace
+func (s *MeasuredSegment) SetBrace(brace bool) {
+	s.brace = brace
+}
+
+// Return the reference of the first end-point of this segment
+func (s MeasuredSegment) GetFrom() string {
+	return s.from
+}
+
+// Return the reference of the second end-point of this segment
+func (s MeasuredSegment) GetTo() string {
+	return s.to
+}
+
+// Return the length shown next to this segment
+func (s MeasuredSegment) GetLength() string {
+	return s.length
+}
+
+// Return whether the length of this segment is shown over a brace
+func (s MeasuredSegment) GetBrace() bool {
+	return s.brace
+}
+
+// Finally, measured segments are stringers and these are the means provided
+// for automatically reusing this component
+func (s MeasuredSegment) String() string {
+
+	// create a template with the TikZ code for showing a measured segment
+	tpl, err := template.New("measuredSegment").Parse(tikzMeasuredSegment)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// and now make the appropriate substitution. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, s); err != nil {
+		log.Fatal(err)
+	}
+
+	// and return the resulting string
+	return tplOutput.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,619 @@
+// -*- coding: utf-8 -*-
+// svg.go
+//
+// Description: Rendering of reusable components in SVG format, so that they
+//              can be shown without a LaTeX toolchain
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:52:41.358251810 (1792111961)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package components
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// SVG drawings use centimeters as user units. The following are the
+// approximate width and height of characters used for sizing text boxes, and
+// the size of the font
+const SVGCHARWIDTH float64 = 0.25
+const SVGCHARHEIGHT float64 = 0.6
+const SVGFONTSIZE float64 = 0.45
+
+// Margin in centimeters added around all the components drawn in a canvas
+const SVGMARGIN float64 = 0.1
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// Only a small subset of TikZ positions can be rendered in SVG: explicit points
+// "(x, y)", the names of labels, "(label)", and labels shifted by a constant
+// amount, "$(label) + (x, y)$", where all quantities are given in centimeters
+var svgPointRE = regexp.MustCompile(`^\(\s*([-+]?[0-9.]+)(?:cm)?\s*,\s*([-+]?[0-9.]+)(?:cm)?\s*\)$`)
+var svgLabelRE = regexp.MustCompile(`^\$?\s*\(\s*([A-Za-z][A-Za-z0-9_]*)\s*\)\s*(?:\+\s*\(\s*([-+]?[0-9.]+)(?:cm)?\s*,\s*([-+]?[0-9.]+)(?:cm)?\s*\))?\s*\$?$`)
+
+// LaTeX commands in texts, e.g., "\huge", are removed when rendering them in
+// SVG
+var svgCommandRE = regexp.MustCompile(`\\[A-Za-z]+\s*`)
+
+// The minimum width of text boxes is given in TikZ as a multiple of the width
+// of a zero
+var svgMinimumWidthRE = regexp.MustCompile(`minimum width\s*=\s*([0-9.]+)\\zerowidth`)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A canvas consists of the SVG elements drawn so far along with the location of
+// all the labels defined, so that components can refer to them. It also keeps
+// the bounding box of all the elements drawn
+type SVGCanvas struct {
+	labels   map[string]Point
+	elements bytes.Buffer
+	min, max Point
+	empty    bool
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new empty canvas
+func NewSVGCanvas() *SVGCanvas {
+	return &SVGCanvas{labels: make(map[string]Point), empty: true}
+}
+
+// return the SVG attributes used for drawing with the given TikZ options. Only
+// a few options are acknowledged: colors, dashed, thick, fill and fill=color
+func svgStyle(options string, fill bool) string {
+
+	stroke, fillColor, width, dash := "black", "none", 0.02, ""
+	for _, option := range strings.Split(options, ",") {
+		switch option = strings.TrimSpace(option); option {
+		case "white", "black", "gray", "red", "blue", "green":
+			stroke = option
+		case "dashed":
+			dash = ` stroke-dasharray="0.1 0.1"`
+		case "thick":
+			width = 0.04
+		case "fill":
+			fillColor = stroke
+		default:
+			if strings.HasPrefix(option, "fill=") {
+				fillColor = strings.TrimPrefix(option, "fill=")
+			}
+		}
+	}
+	if fill && fillColor == "none" && stroke == "white" {
+		fillColor = "white"
+	}
+	return fmt.Sprintf(`stroke="%v" stroke-width="%v" fill="%v"%v`, stroke, helpers.Ftoa(width), fillColor, dash)
+}
+
+// return the given text without LaTeX commands and escaped so that it can be
+// written in SVG
+func svgText(text string) string {
+
+	var output bytes.Buffer
+	xml.EscapeText(&output, []byte(strings.TrimSpace(svgCommandRE.ReplaceAllString(text, ""))))
+	return output.String()
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- SVGCanvas
+
+// return the location of the given reference, which is either the name of a
+// label defined in this canvas or a position given in TikZ format. If the
+// reference can not be resolved, an error is returned
+func (canvas *SVGCanvas) Resolve(ref string) (Point, error) {
+
+	ref = strings.TrimSpace(ref)
+	if point, ok := canvas.labels[ref]; ok {
+		return point, nil
+	}
+
+	// explicit points
+	if match := svgPointRE.FindStringSubmatch(ref); match != nil {
+		x, _ := strconv.ParseFloat(match[1], 64)
+		y, _ := strconv.ParseFloat(match[2], 64)
+		return Point{X: x, Y: y}, nil
+	}
+
+	// labels, optionally shifted
+	if match := svgLabelRE.FindStringSubmatch(ref); match != nil {
+		point, ok := canvas.labels[match[1]]
+		if !ok {
+			return Point{}, fmt.Errorf("the label '%v' is not defined", match[1])
+		}
+		if match[2] != "" {
+			dx, _ := strconv.ParseFloat(match[2], 64)
+			dy, _ := strconv.ParseFloat(match[3], 64)
+			point.X, point.Y = point.X+dx, point.Y+dy
+		}
+		return point, nil
+	}
+
+	// any other position can not be resolved
+	return Point{}, fmt.Errorf("the position '%v' can not be rendered in SVG", ref)
+}
+
+// define the given label at the given point
+func (canvas *SVGCanvas) define(label string, point Point) {
+	canvas.labels[label] = point
+}
+
+// extend the bounding box of this canvas to contain the given point
+func (canvas *SVGCanvas) extend(point Point) {
+
+	if canvas.empty {
+		canvas.min, canvas.max, canvas.empty = point, point, false
+		return
+	}
+	canvas.min = Point{X: math.Min(canvas.min.X, point.X), Y: math.Min(canvas.min.Y, point.Y)}
+	canvas.max = Point{X: math.Max(canvas.max.X, point.X), Y: math.Max(canvas.max.Y, point.Y)}
+}
+
+// add the given SVG element to the canvas. Its bounding box is given with the
+// points that it has to contain
+func (canvas *SVGCanvas) add(element string, points ...Point) {
+
+	canvas.elements.WriteString("  " + element + "\n")
+	for _, point := range points {
+		canvas.extend(point)
+	}
+}
+
+// draw a text box with the given options centered at the given point. The box
+// is drawn only if requested in the options with "draw", and it is rounded if
+// requested with "rounded corners". Circles are drawn with "circle"
+func (canvas *SVGCanvas) drawText(center Point, options, text string) {
+
+	// compute the size of the box from the text and the minimum width, if any
+	content := svgText(text)
+	width := SVGCHARWIDTH * float64(len([]rune(content)))
+	if match := svgMinimumWidthRE.FindStringSubmatch(options); match != nil {
+		if minimum, err := strconv.ParseFloat(match[1], 64); err == nil {
+			width = math.Max(width, SVGCHARWIDTH*minimum)
+		}
+	}
+	width, height := width+0.2, SVGCHARHEIGHT
+	corner0 := Point{X: center.X - width/2.0, Y: center.Y - height/2.0}
+	corner1 := Point{X: center.X + width/2.0, Y: center.Y + height/2.0}
+
+	// draw the box if requested
+	if strings.Contains(options, "circle") {
+		radius := 0.1
+		if content != "" {
+			radius = width / 2.0
+		}
+		canvas.add(fmt.Sprintf(`<circle cx="%v" cy="%v" r="%v" %v/>`,
+			helpers.Ftoa(center.X), helpers.Ftoa(-center.Y), helpers.Ftoa(radius), svgStyle(options, false)),
+			Point{X: center.X - radius, Y: center.Y - radius}, Point{X: center.X + radius, Y: center.Y + radius})
+	} else if strings.Contains(options, "draw") {
+		radius := 0.0
+		if strings.Contains(options, "rounded corners") {
+			radius = 0.1
+		}
+		canvas.add(fmt.Sprintf(`<rect x="%v" y="%v" width="%v" height="%v" rx="%v" %v/>`,
+			helpers.Ftoa(corner0.X), helpers.Ftoa(-corner1.Y), helpers.Ftoa(width), helpers.Ftoa(height),
+			helpers.Ftoa(radius), svgStyle(options, false)),
+			corner0, corner1)
+	}
+
+	// and write the text, if any
+	if content != "" {
+		canvas.add(fmt.Sprintf(`<text x="%v" y="%v" font-size="%v" text-anchor="middle" dominant-baseline="central">%v</text>`,
+			helpers.Ftoa(center.X), helpers.Ftoa(-center.Y), helpers.Ftoa(SVGFONTSIZE), content),
+			corner0, corner1)
+	}
+}
+
+// Return the SVG document with all the elements drawn in this canvas. Note
+// that the vertical axis is reversed in SVG
+func (canvas *SVGCanvas) String() string {
+
+	min := Point{X: canvas.min.X - SVGMARGIN, Y: canvas.min.Y - SVGMARGIN}
+	max := Point{X: canvas.max.X + SVGMARGIN, Y: canvas.max.Y + SVGMARGIN}
+	width, height := max.X-min.X, max.Y-min.Y
+	return fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%vcm\" height=\"%vcm\" viewBox=\"%v %v %v %v\">\n%v</svg>\n",
+		helpers.Ftoa(width), helpers.Ftoa(height),
+		helpers.Ftoa(min.X), helpers.Ftoa(-max.Y), helpers.Ftoa(width), helpers.Ftoa(height),
+		canvas.elements.String())
+}
+
+// -- Coordinate
+
+// Draw this coordinate in the given canvas, i.e., define its label at its
+// position. Coordinates are not visible
+func (c Coordinate) SVG(canvas *SVGCanvas) error {
+
+	var point Point
+	var err error
+	if p, ok := c.Positioner.(Point); ok {
+		point = p
+	} else if point, err = canvas.Resolve(c.Positioner.Position()); err != nil {
+		return err
+	}
+	canvas.define(c.label, point)
+	canvas.extend(point)
+	return nil
+}
+
+// -- Line
+
+// Draw this line in the given canvas. All its references have to be resolved
+func (line Line) SVG(canvas *SVGCanvas) error {
+
+	var points []string
+	var refs []Point
+	for _, ref := range line.refs {
+		point, err := canvas.Resolve(ref)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, point)
+		points = append(points, fmt.Sprintf("%v,%v", helpers.Ftoa(point.X), helpers.Ftoa(-point.Y)))
+	}
+	canvas.add(fmt.Sprintf(`<polyline points="%v" %v/>`, strings.Join(points, " "), svgStyle(line.options, false)), refs...)
+	return nil
+}
+
+// -- Rectangle
+
+// draw a rectangle with the given options between both corners
+func svgRectangle(canvas *SVGCanvas, corner0, corner1 Point, options string) {
+
+	min := Point{X: math.Min(corner0.X, corner1.X), Y: math.Min(corner0.Y, corner1.Y)}
+	max := Point{X: math.Max(corner0.X, corner1.X), Y: math.Max(corner0.Y, corner1.Y)}
+	canvas.add(fmt.Sprintf(`<rect x="%v" y="%v" width="%v" height="%v" %v/>`,
+		helpers.Ftoa(min.X), helpers.Ftoa(-max.Y), helpers.Ftoa(max.X-min.X), helpers.Ftoa(max.Y-min.Y),
+		svgStyle(options, true)),
+		min, max)
+}
+
+// Draw this rectangle in the given canvas. Both references have to be resolved
+func (rect Rectangle) SVG(canvas *SVGCanvas) error {
+
+	corner0, err := canvas.Resolve(rect.ref0)
+	if err != nil {
+		return err
+	}
+	corner1, err := canvas.Resolve(rect.ref1)
+	if err != nil {
+		return err
+	}
+	svgRectangle(canvas, corner0, corner1, rect.options)
+	return nil
+}
+
+// Draw this rectangle in the given canvas along with its coordinates
+func (rect CoordinatedRectangle) SVG(canvas *SVGCanvas) error {
+
+	if err := rect.coord0.SVG(canvas); err != nil {
+		return err
+	}
+	if err := rect.coord1.SVG(canvas); err != nil {
+		return err
+	}
+	svgRectangle(canvas, canvas.labels[rect.coord0.label], canvas.labels[rect.coord1.label], rect.options)
+	return nil
+}
+
+// -- Text
+
+// Draw this text box in the given canvas. As it is not located anywhere, it is
+// drawn at the origin and its label is defined there
+func (t Text) SVG(canvas *SVGCanvas) error {
+
+	canvas.define(t.label, Point{})
+	canvas.drawText(Point{}, t.options, t.text)
+	return nil
+}
+
+// Draw this text box in the given canvas at the location of its label
+func (t LabeledText) SVG(canvas *SVGCanvas) error {
+
+	center, err := canvas.Resolve(t.label)
+	if err != nil {
+		return err
+	}
+	canvas.drawText(center, t.options, t.text)
+	return nil
+}
+
+// Draw this text box in the given canvas at its coordinate
+func (t CoordinatedText) SVG(canvas *SVGCanvas) error {
+
+	if err := t.Coordinate.SVG(canvas); err != nil {
+		return err
+	}
+	canvas.drawText(canvas.labels[t.Coordinate.label], t.options, t.text)
+	return nil
+}
+
+// -- Arc
+
+// Draw this arc in the given canvas. Its reference has to be resolved
+func (a Arc) SVG(canvas *SVGCanvas) error {
+
+	center, err := canvas.Resolve(a.reference)
+	if err != nil {
+		return err
+	}
+
+	// arcs go counterclockwise, which is the negative direction in SVG as
+	// vertical coordinates are flipped
+	point := func(angle float64) Point {
+		return Point{
+			X: center.X + a.radius*math.Cos(angle*math.Pi/180.0),
+			Y: center.Y + a.radius*math.Sin(angle*math.Pi/180.0),
+		}
+	}
+	p0, p1 := point(a.from), point(a.to)
+	large, sweep := 0, 0
+	if math.Abs(a.to-a.from) > 180.0 {
+		large = 1
+	}
+	if a.to < a.from {
+		sweep = 1
+	}
+	canvas.add(fmt.Sprintf(`<path d="M %v %v A %v %v 0 %v %v %v %v" %v/>`,
+		helpers.Ftoa(p0.X), helpers.Ftoa(-p0.Y),
+		helpers.Ftoa(a.radius), helpers.Ftoa(a.radius), large, sweep,
+		helpers.Ftoa(p1.X), helpers.Ftoa(-p1.Y),
+		svgStyle(a.options, false)),
+		Point{X: center.X - a.radius, Y: center.Y - a.radius},
+		Point{X: center.X + a.radius, Y: center.Y + a.radius})
+	return nil
+}
+
+// -- FractionShape
+
+// Draw this fraction shape in the given canvas. Its reference has to be
+// resolved
+func (f FractionShape) SVG(canvas *SVGCanvas) error {
+
+	center, err := canvas.Resolve(f.reference)
+	if err != nil {
+		return err
+	}
+	for part := range f.shaded {
+		if f.shape == FRACTIONBAR {
+			x0, x1 := f.offsets(part)
+			svgRectangle(canvas,
+				Point{X: center.X + x0, Y: center.Y - FRACTIONBARHEIGHT/2.0},
+				Point{X: center.X + x1, Y: center.Y + FRACTIONBARHEIGHT/2.0},
+				f.partOptions(part))
+			continue
+		}
+
+		// circles with one single part can not be drawn with arcs
+		corner0 := Point{X: center.X - FRACTIONPIERADIUS, Y: center.Y - FRACTIONPIERADIUS}
+		corner1 := Point{X: center.X + FRACTIONPIERADIUS, Y: center.Y + FRACTIONPIERADIUS}
+		if len(f.shaded) == 1 {
+			canvas.add(fmt.Sprintf(`<circle cx="%v" cy="%v" r="%v" %v/>`,
+				helpers.Ftoa(center.X), helpers.Ftoa(-center.Y), helpers.Ftoa(FRACTIONPIERADIUS),
+				svgStyle(f.partOptions(part), false)),
+				corner0, corner1)
+			continue
+		}
+
+		// otherwise, every slice goes clockwise from its first angle to the
+		// second one
+		angle0, angle1 := f.angles(part)
+		large := 0
+		if angle0-angle1 > 180.0 {
+			large = 1
+		}
+		canvas.add(fmt.Sprintf(`<path d="M %v %v L %v %v A %v %v 0 %v 1 %v %v Z" %v/>`,
+			helpers.Ftoa(center.X), helpers.Ftoa(-center.Y),
+			helpers.Ftoa(center.X+FRACTIONPIERADIUS*math.Cos(angle0*math.Pi/180.0)),
+			helpers.Ftoa(-center.Y-FRACTIONPIERADIUS*math.Sin(angle0*math.Pi/180.0)),
+			helpers.Ftoa(FRACTIONPIERADIUS), helpers.Ftoa(FRACTIONPIERADIUS), large,
+			helpers.Ftoa(center.X+FRACTIONPIERADIUS*math.Cos(angle1*math.Pi/180.0)),
+			helpers.Ftoa(-center.Y-FRACTIONPIERADIUS*math.Sin(angle1*math.Pi/180.0)),
+			svgStyle(f.partOptions(part), false)),
+			corner0, corner1)
+	}
+	return nil
+}
+
+// -- Grid
+
+// Draw this grid in the given canvas. Its origin has to be resolved
+func (g Grid) SVG(canvas *SVGCanvas) error {
+
+	for row := range g.texts {
+		for col, text := range g.texts[row] {
+			center, err := canvas.Resolve(g.GetCell(row, col))
+			if err != nil {
+				return err
+			}
+			if g.framed[row][col] {
+				svgRectangle(canvas,
+					Point{X: center.X - g.width/2.0, Y: center.Y - g.height/2.0},
+					Point{X: center.X + g.width/2.0, Y: center.Y + g.height/2.0},
+					g.options)
+			}
+			if text != "" {
+				canvas.drawText(center, "", text)
+			}
+		}
+	}
+	return nil
+}
+
+// -- Money
+
+// Draw this coin or bill in the given canvas. Its reference has to be resolved
+func (m Money) SVG(canvas *SVGCanvas) error {
+
+	center, err := canvas.Resolve(m.reference)
+	if err != nil {
+		return err
+	}
+	if m.bill {
+		svgRectangle(canvas,
+			Point{X: center.X - MONEYBILLWIDTH/2.0, Y: center.Y - MONEYBILLHEIGHT/2.0},
+			Point{X: center.X + MONEYBILLWIDTH/2.0, Y: center.Y + MONEYBILLHEIGHT/2.0},
+			m.options)
+	} else {
+		canvas.add(fmt.Sprintf(`<circle cx="%v" cy="%v" r="%v" %v/>`,
+			helpers.Ftoa(center.X), helpers.Ftoa(-center.Y), helpers.Ftoa(MONEYCOINRADIUS), svgStyle(m.options, false)),
+			Point{X: center.X - MONEYCOINRADIUS, Y: center.Y - MONEYCOINRADIUS},
+			Point{X: center.X + MONEYCOINRADIUS, Y: center.Y + MONEYCOINRADIUS})
+	}
+	canvas.drawText(center, "", m.value)
+	return nil
+}
+
+// -- NumberLine
+
+// Draw this number line in the given canvas. Its origin has to be resolved
+func (nl NumberLine) SVG(canvas *SVGCanvas) error {
+
+	// draw the axis
+	axis := NewLine(nl.at(-0.5), nl.at(float64(len(nl.labels))-0.5))
+	axis.SetOptions(nl.options)
+	if err := axis.SVG(canvas); err != nil {
+		return err
+	}
+
+	// and every tick along with its label, if any
+	for i, text := range nl.labels {
+		center, err := canvas.Resolve(nl.GetTick(i))
+		if err != nil {
+			return err
+		}
+		shift, label := Point{X: 0, Y: 0.15}, Point{X: center.X, Y: center.Y - 0.5}
+		if nl.vertical {
+			shift, label = Point{X: 0.15, Y: 0}, Point{X: center.X - 0.6, Y: center.Y}
+		}
+		tick := NewLine(
+			Point{X: center.X - shift.X, Y: center.Y - shift.Y}.Position(),
+			Point{X: center.X + shift.X, Y: center.Y + shift.Y}.Position())
+		if err := tick.SVG(canvas); err != nil {
+			return err
+		}
+		if text != "" {
+			canvas.drawText(label, "", text)
+		}
+	}
+	return nil
+}
+
+// -- Symbol
+
+// Draw this symbol in the given canvas. Its reference has to be resolved
+func (s Symbol) SVG(canvas *SVGCanvas) error {
+
+	center, err := canvas.Resolve(s.reference)
+	if err != nil {
+		return err
+	}
+	corner0 := Point{X: center.X - s.size/2.0, Y: center.Y - s.size/2.0}
+	corner1 := Point{X: center.X + s.size/2.0, Y: center.Y + s.size/2.0}
+	switch s.kind {
+	case SYMBOLSTAR:
+		var points []string
+		for _, vertex := range s.starVertices() {
+			points = append(points, fmt.Sprintf("%v,%v",
+				helpers.Ftoa(center.X+vertex.X), helpers.Ftoa(-center.Y-vertex.Y)))
+		}
+		canvas.add(fmt.Sprintf(`<polygon points="%v" %v/>`,
+			strings.Join(points, " "), svgStyle(s.options, false)),
+			corner0, corner1)
+	case SYMBOLCIRCLE:
+		canvas.add(fmt.Sprintf(`<circle cx="%v" cy="%v" r="%v" %v/>`,
+			helpers.Ftoa(center.X), helpers.Ftoa(-center.Y), helpers.Ftoa(s.size/2.0),
+			svgStyle(s.options, false)),
+			corner0, corner1)
+	case SYMBOLAPPLE:
+		body, radius, stem, leaf, rx, ry := s.appleParts()
+		canvas.add(fmt.Sprintf(`<circle cx="%v" cy="%v" r="%v" %v/>`,
+			helpers.Ftoa(center.X+body.X), helpers.Ftoa(-center.Y-body.Y), helpers.Ftoa(radius),
+			svgStyle(s.options, false)),
+			corner0, corner1)
+		canvas.add(fmt.Sprintf(`<line x1="%v" y1="%v" x2="%v" y2="%v" %v/>`,
+			helpers.Ftoa(center.X+stem[0].X), helpers.Ftoa(-center.Y-stem[0].Y),
+			helpers.Ftoa(center.X+stem[1].X), helpers.Ftoa(-center.Y-stem[1].Y),
+			svgStyle(s.options, false)))
+		canvas.add(fmt.Sprintf(`<ellipse cx="%v" cy="%v" rx="%v" ry="%v" %v/>`,
+			helpers.Ftoa(center.X+leaf.X), helpers.Ftoa(-center.Y-leaf.Y), helpers.Ftoa(rx), helpers.Ftoa(ry),
+			svgStyle(s.options, false)))
+	}
+	return nil
+}
+
+// -- BarChart
+
+// Draw this bar chart in the given canvas. Its origin has to be resolved
+func (bc BarChart) SVG(canvas *SVGCanvas) error {
+
+	// first, the horizontal lines of all units along with their labels
+	for value := 0; value <= bc.maximum; value++ {
+		line := NewLine(bc.at(0.0, float64(value)), bc.at(bc.GetWidth(), float64(value)))
+		line.SetOptions("gray")
+		if err := line.SVG(canvas); err != nil {
+			return err
+		}
+		if value%bc.step == 0 {
+			point, err := canvas.Resolve(bc.at(0.0, float64(value)))
+			if err != nil {
+				return err
+			}
+			canvas.drawText(Point{X: point.X - 0.4, Y: point.Y}, "", strconv.Itoa(value))
+		}
+	}
+
+	// next, the bars of all categories, if any are given, along with their
+	// labels
+	for i, label := range bc.labels {
+		if i < len(bc.values) {
+			ref0, ref1 := bc.GetBar(i, bc.values[i])
+			corner0, err := canvas.Resolve(ref0)
+			if err != nil {
+				return err
+			}
+			corner1, err := canvas.Resolve(ref1)
+			if err != nil {
+				return err
+			}
+			svgRectangle(canvas, corner0, corner1, bc.options)
+		}
+		point, err := canvas.Resolve(bc.GetCategory(i))
+		if err != nil {
+			return err
+		}
+		canvas.drawText(Point{X: point.X, Y: point.Y - 0.4}, "", label)
+	}
+
+	// and finally both axes
+	for _, axis := range []Line{
+		NewLine(bc.at(0.0, 0.0), bc.at(0.0, float64(bc.maximum)+0.5)),
+		NewLine(bc.at(0.0, 0.0), bc.at(bc.GetWidth()+0.3, 0.0))} {
+		axis.SetOptions("thick")
+		if err := axis.SVG(canvas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,239 @@
+// -*- coding: utf-8 -*-
+// symbol.go
+//
+// Description: Definition of small symbols such as stars, circles or apples as
+//              reusable components to be used in TikZ drawings
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:27:09.183526604 (1792117629)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// This package provides a number of reusable components that can be used for
+// creating TikZ drawings
+package components
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Symbols are either stars, circles or apples. They are all drawn with their
+// outline only, so that they can be colored by students
+const (
+	SYMBOLSTAR   string = "star"
+	SYMBOLCIRCLE string = "circle"
+	SYMBOLAPPLE  string = "apple"
+)
+
+// By default, symbols are as wide as the following size given in centimeters
+const DEFAULTSYMBOLSIZE float64 = 0.8
+
+// Stars have five points, and the ratio between the inner and the outer radius
+// is given next
+const (
+	SYMBOLSTARPOINTS int     = 5
+	SYMBOLSTARRATIO  float64 = 0.4
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A symbol is a small drawing (either a star, a circle or an apple) centered at
+// the given reference (either the name of a label or a formula) whose width is
+// given in centimeters. Additionally, an arbitrary number of options can be
+// given as a comma-separated string, e.g., the line width
+type Symbol struct {
+	reference string
+	kind      string
+	size      float64
+	BaseRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Create a new symbol (either SYMBOLSTAR, SYMBOLCIRCLE or SYMBOLAPPLE) centered
+// at the given reference with the given width in centimeters. Note that the
+// options are specified through a dedicated service
+func NewSymbol(reference, kind string, size float64) Symbol {
+	return Symbol{
+		reference: reference,
+		kind:      kind,
+		size:      size,
+	}
+}
+
+// return a valid specification of a symbol with no error if all the keys given
+// in dict are correct for defining it. Otherwise, return an error. If an error
+// is returned, the contents of the symbol are undefined
+//
+// A dictionary is correct if and only if it correctly defines the reference of
+// its center as a string with the keyword "reference", and its kind, either
+// "star", "circle" or "apple", with "kind". These are the only mandatory
+// arguments. In addition, it is also possible to specify its width in
+// centimeters with "size" (DEFAULTSYMBOLSIZE by default), and arbitrary options
+// as a string
+func VerifySymbolDict(dict map[string]interface{}) (Symbol, error) {
+
+	// first of all, ensure that all mandatory parameters are given and that
+	// they are of the correct type. Create slices for both mandatory and all
+	// arguments
+	all := []string{"reference", "kind", "size", "options"}
+	mandatory := []string{"reference", "kind"}
+
+	// verify that all mandatory arguments are given in the dictionary
+	for _, key := range mandatory {
+
+		// if a mandatory parameter has not been given, then immediately raise
+		// an error
+		if _, ok := dict[key]; !ok {
+			return Symbol{}, fmt.Errorf("Mandatory key '%v' for defining a symbol not found", key)
+		}
+	}
+
+	// now ensure that the mandatory parameters are of the right type
+	var ok bool
+	var reference, kind string
+	if reference, ok = dict["reference"].(string); !ok {
+		return Symbol{}, errors.New("The reference of a symbol should be given as a string")
+	}
+	if kind, ok = dict["kind"].(string); !ok || !helpers.Find(kind, []string{SYMBOLSTAR, SYMBOLCIRCLE, SYMBOLAPPLE}) {
+		return Symbol{}, fmt.Errorf("The kind of a symbol should be either '%v', '%v' or '%v'", SYMBOLSTAR, SYMBOLCIRCLE, SYMBOLAPPLE)
+	}
+
+	// now, perform the same operation with the optional parameters
+	var err error
+	size := DEFAULTSYMBOLSIZE
+	if _, ok := dict["size"]; ok {
+		if size, err = helpers.Atof(dict["size"]); err != nil || size <= 0 {
+			return Symbol{}, errors.New("The size of a symbol should be given as a positive number")
+		}
+	}
+	var options string
+	if _, ok := dict["options"]; ok {
+		if _, ok := dict["options"].(string); !ok {
+			return Symbol{}, errors.New("The options of a symbol should be given as a string")
+		}
+		options = dict["options"].(string)
+	}
+
+	// in case any other arguments were given, but they are not acknoweldged,
+	// issue a warning
+	for key, _ := range dict {
+		if !helpers.Find(key, all) {
+			log.Printf("The parameter '%v' is not acknowledged for creating a symbol and it will be ignored", key)
+		}
+	}
+
+	// At this point, the dictionary is correct, return a valid symbol
+	return Symbol{
+		reference:     reference,
+		kind:          kind,
+		size:          size,
+		BaseRectangle: BaseRectangle{options: options},
+	}, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// return the vertices of the outline of this star as offsets with respect to
+// its center, starting from the top point and going counterclockwise
+func (s Symbol) starVertices() []Point {
+
+	var vertices []Point
+	for idx := 0; idx < 2*SYMBOLSTARPOINTS; idx++ {
+		radius := s.size / 2.0
+		if idx%2 == 1 {
+			radius *= SYMBOLSTARRATIO
+		}
+		angle := 90.0 + 180.0*float64(idx)/float64(SYMBOLSTARPOINTS)
+		vertices = append(vertices, Point{
+			X: radius * math.Cos(angle*math.Pi/180.0),
+			Y: radius * math.Sin(angle*math.Pi/180.0),
+		})
+	}
+	return vertices
+}
+
+// return the body of this apple as a circle given with its offset with respect
+// to the center and its radius, along with the stem, given as a segment, and
+// the leaf, given as an ellipse with its offset and both radii
+func (s Symbol) appleParts() (body Point, radius float64, stem [2]Point, leaf Point, rx, ry float64) {
+
+	r := s.size / 2.0
+	body, radius = Point{X: 0.0, Y: -0.1 * r}, 0.85*r
+	stem = [2]Point{{X: 0.0, Y: 0.7 * r}, {X: 0.15 * r, Y: r}}
+	leaf, rx, ry = Point{X: 0.4 * r, Y: 0.9 * r}, 0.25*r, 0.12*r
+	return
+}
+
+// return the given offset with respect to the reference of this symbol as a
+// TikZ position. Formulas can not be nested in TikZ, so that if the reference
+// is a formula the offset is added to it
+func (s Symbol) at(offset Point) string {
+
+	position := fmt.Sprintf("(%v)", s.reference)
+	if strings.HasPrefix(s.reference, "$") && strings.HasSuffix(s.reference, "$") {
+		position = strings.Trim(s.reference, "$")
+	}
+	return fmt.Sprintf(`$%v + (%vcm, %vcm)$`, position,
+		helpers.Ftoa(math.Round(1e4*offset.X)/1e4), helpers.Ftoa(math.Round(1e4*offset.Y)/1e4))
+}
+
+// Return the reference of the center of this symbol
+func (s Symbol) GetReference() string {
+	return s.reference
+}
+
+// Return the kind of this symbol
+func (s Symbol) GetKind() string {
+	return s.kind
+}
+
+// Return the width in centimeters taken by this symbol
+func (s Symbol) GetSize() float64 {
+	return s.size
+}
+
+// Finally, symbols are stringers and these are the means provided for
+// automatically reusing this component
+func (s Symbol) String() string {
+
+	// Use a btyes buffer to append the TikZ code of every part of the symbol
+	var output bytes.Buffer
+
+	switch s.kind {
+	case SYMBOLSTAR:
+		var vertices []string
+		for _, vertex := range s.starVertices() {
+			vertices = append(vertices, fmt.Sprintf("(%v)", s.at(vertex)))
+		}
+		fmt.Fprintf(&output, `\draw [%v] %v -- cycle;`, s.options, strings.Join(vertices, " -- "))
+	case SYMBOLCIRCLE:
+		fmt.Fprintf(&output, `\draw [%v] (%v) circle (%vcm);`, s.options, s.reference, helpers.Ftoa(s.size/2.0))
+	case SYMBOLAPPLE:
+		body, radius, stem, leaf, rx, ry := s.appleParts()
+		fmt.Fprintf(&output, `\draw [%v] (%v) circle (%vcm);`, s.options, s.at(body), helpers.Ftoa(radius))
+		fmt.Fprintf(&output, "\n"+`\draw [%v] (%v) -- (%v);`, s.options, s.at(stem[0]), s.at(stem[1]))
+		fmt.Fprintf(&output, "\n"+`\draw [%v] (%v) ellipse (%vcm and %vcm);`, s.options, s.at(leaf), helpers.Ftoa(rx), helpers.Ftoa(ry))
+	}
+
+	// and return the TikZ code used for drawing this symbol
+	return output.String()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -131,7 +131,7 @@ func VerifyTextDict(dict map[string]interface{}) (Text, error) {
 				return Text{}, errors.New("The text of a text box should be given as a string")
 			}
 		default:
-			log.Printf("The parameter '%v' is not acknowledged for creating a text box and it will be ignored")
+			log.Printf("The parameter '%v' is not acknowledged for creating a text box and it will be ignored", key)
 		}
 	}
 
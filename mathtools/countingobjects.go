@@ -0,0 +1,317 @@
+// -*- coding: utf-8 -*-
+// countingobjects.go
+//
+// Description: Provides services for automatically creating problems where
+// the objects shown in a box have to be counted
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:27:09.183526604 (1792117629)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Maximum number of objects that can be counted in the same box
+const MAXCOUNTINGOBJECTS int = 30
+
+// Objects are shown within a box with the following width and height, and when
+// they are scattered, they are located in cells of the following size with a
+// random shift not larger than the given jitter, all given in centimeters
+const (
+	COUNTINGBOXWIDTH  float64 = 12.0
+	COUNTINGBOXHEIGHT float64 = 6.0
+	COUNTINGCELLSIZE  float64 = 1.2
+	COUNTINGJITTER    float64 = 0.15
+)
+
+// When grouped, objects are shown in rows of groups of the following number
+// of objects
+const COUNTINGGROUPSIZE int = 5
+
+// the TikZ code for generating counting problems is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexCountingObjectsCode = `\begin{minipage}{\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the objects to count
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZCountingObjectsCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box and of the box with the objects
+      {{.Bottom}}
+      {{.Origin}}
+
+      % --- Objects ---------------------------------------------------------
+
+      % all objects are drawn within a box
+      {{.Box}}
+{{.GetObjects}}
+      % --- Answer ----------------------------------------------------------
+
+      % the number of objects is written below the box
+      {{.Answer}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A counting problem consists of a number of objects in the range [geq, leq],
+// all of them drawn with the same symbol: "star", "circle" or "apple". Objects
+// are either randomly scattered within a box or, if grouped is true, shown in
+// groups of COUNTINGGROUPSIZE objects
+type countingObjects struct {
+	geq, leq int
+	symbol   string
+	grouped  bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw counting
+// problems
+type countingObjectsTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0),
+	// and the box with the objects is located above it
+	Bottom, Origin components.Coordinate
+
+	// the box and all the objects within it
+	Box     components.Rectangle
+	objects []components.Symbol
+
+	// the answer is written within an empty box
+	Answer components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- countingObjectsTikZ
+
+// Generates the TikZ code necessary for drawing all the objects
+func (tikz countingObjectsTikZ) GetObjects() string {
+
+	// Use a btyes buffer to append the strings of each object
+	var output bytes.Buffer
+
+	for _, object := range tikz.objects {
+		fmt.Fprintf(&output, "      %v\n", object)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// objects
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz countingObjectsTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("countingObjectsTikZ").Parse(tikZCountingObjectsCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- countingObjects
+
+// return the positions of the given number of objects within the box, given
+// in centimeters with respect to its lower-left corner. Grouped objects are
+// shown in rows of two groups each, whereas scattered objects are located in
+// randomly chosen cells with a random shift
+func (co countingObjects) positions(nbobjects int, rnd *rand.Rand) []components.Point {
+
+	var result []components.Point
+	if co.grouped {
+		gap := COUNTINGBOXWIDTH - 2.0*float64(COUNTINGGROUPSIZE)
+		for idx := 0; idx < nbobjects; idx++ {
+			group, item := idx/COUNTINGGROUPSIZE, idx%COUNTINGGROUPSIZE
+			result = append(result, components.Point{
+				X: gap/3.0 + 0.5 + float64(item) + float64(group%2)*(float64(COUNTINGGROUPSIZE)+gap/3.0),
+				Y: COUNTINGBOXHEIGHT - 0.9 - 1.5*float64(group/2),
+			})
+		}
+		return result
+	}
+
+	nbcols := int(COUNTINGBOXWIDTH / COUNTINGCELLSIZE)
+	nbrows := int(COUNTINGBOXHEIGHT / COUNTINGCELLSIZE)
+	for _, cell := range rnd.Perm(nbcols * nbrows)[:nbobjects] {
+		result = append(result, components.Point{
+			X: COUNTINGCELLSIZE*(0.5+float64(cell%nbcols)) + COUNTINGJITTER*(2.0*rnd.Float64()-1.0),
+			Y: COUNTINGCELLSIZE*(0.5+float64(cell/nbcols)) + COUNTINGJITTER*(2.0*rnd.Float64()-1.0),
+		})
+	}
+	return result
+}
+
+// return the instance of a specific counting problem that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with the symbol used followed by the positions of all
+// objects, each one given as "x,y" in centimeters with respect to the
+// lower-left corner of the box, and the number of objects, which is shown as
+// "?" in the arguments as it has to be guessed by the student
+func (co countingObjects) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if co.geq < 1 || co.geq > co.leq || co.leq > MAXCOUNTINGOBJECTS {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to count a number of objects in the range [%v, %v]", co.geq, co.leq)
+	}
+
+	// randomly determine the number of objects and their positions
+	nbobjects := co.geq + rnd.Intn(1+co.leq-co.geq)
+	args := []string{co.symbol}
+	for _, position := range co.positions(nbobjects, rnd) {
+		args = append(args, fmt.Sprintf("%v,%v",
+			strconv.FormatFloat(position.X, 'f', 2, 64), strconv.FormatFloat(position.Y, 'f', 2, 64)))
+	}
+	solution := append(append([]string{}, args...), strconv.Itoa(nbobjects))
+	args = append(args, "?")
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "CountingObjects",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this counting problem using TikZ
+// components
+func (co countingObjects) GetTikZPicture() (string, error) {
+
+	// -- objects: randomly determine the objects to count. For this, the
+	//             service that generates problems is the one that can marshal
+	//             them into JSON format. A question mark is the number of
+	//             objects that has to be guessed by the student
+	instance, err := co.next(annotated(co))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid counting problem: %v", err)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box, and the origin is
+	// the lower-left corner of the box with the objects
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	origin := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 1.5,
+	}, "origin")
+
+	// -- objects: all of them are drawn with respect to the origin of the box
+	box := components.NewRectangle("origin",
+		fmt.Sprintf(`$(origin) + (%vcm, %vcm)$`, helpers.Ftoa(COUNTINGBOXWIDTH), helpers.Ftoa(COUNTINGBOXHEIGHT)))
+	box.SetOptions("rounded corners, thick")
+	var objects []components.Symbol
+	for _, position := range instance.Args[1 : len(instance.Args)-1] {
+		coords := strings.Split(position, ",")
+		objects = append(objects, components.NewSymbol(
+			fmt.Sprintf(`$(origin) + (%vcm, %vcm)$`, coords[0], coords[1]),
+			instance.Args[0], components.DEFAULTSYMBOLSIZE))
+	}
+
+	// -- answer: the box is wide enough to write any number of objects
+	answer := components.NewCoordinatedText(
+		components.NewCoordinate(components.Point{
+			X: COUNTINGBOXWIDTH / 2.0,
+			Y: 0.6,
+		}, "answer"),
+		`rounded corners, rectangle, minimum width=4\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+		co.answer(instance.Solution[len(instance.Solution)-1]))
+
+	// -- bounding box
+	right := components.NewCoordinate(components.Point{
+		X: COUNTINGBOXWIDTH,
+		Y: 1.5 + COUNTINGBOXHEIGHT,
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// counting problem
+	coPicture := countingObjectsTikZ{
+		Bottom:  bottom,
+		Origin:  origin,
+		Box:     box,
+		objects: objects,
+		Answer:  answer,
+		BBox:    bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return coPicture.execute()
+}
+
+// Return TikZ code that represents a counting problem
+func (co countingObjects) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this counting problem
+	tpl, err := template.New("countingObjects").Parse(latexCountingObjectsCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, co); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,143 @@
+// -*- coding: utf-8 -*-
+// difficulty.go
+//
+// Description: Scores the features of every instance generated (e.g., the
+// number of carries, digits or zeros) and rejects those whose difficulty falls
+// outside a requested band
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 13:02:47.510393224 (1792155767)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A difficulty band restricts the difficulty of the problems generated to the
+// interval [Min, Max], both within [MINDIFFICULTY, MAXDIFFICULTY]. Problems
+// generated outside the band are rejected and generated again. A null bound
+// stands for no bound, so that the null band accepts all problems
+type DifficultyBand struct {
+	Min int
+	Max int
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return a difficulty band from its textual representation, either a single
+// difficulty (e.g., "4") or an interval of difficulties separated by a dash
+// (e.g., "3-5") where any bound can be omitted (e.g., "3-"). The empty string
+// stands for the null band. In case the string is not correct, an error is
+// returned
+func ParseDifficultyBand(value string) (DifficultyBand, error) {
+
+	// split the string in both bounds, which are the same if only one is given
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) == 1 {
+		bounds = append(bounds, bounds[0])
+	}
+
+	// and parse both, taking omitted bounds as null
+	var limits [2]int
+	for idx, bound := range bounds {
+		if bound = strings.TrimSpace(bound); bound == "" {
+			continue
+		}
+		var err error
+		if limits[idx], err = strconv.Atoi(bound); err != nil {
+			return DifficultyBand{}, fmt.Errorf("the bounds of the difficulty band '%v' should be given as integers", value)
+		}
+	}
+
+	band := DifficultyBand{Min: limits[0], Max: limits[1]}
+	return band, band.Validate()
+}
+
+// return the number of zeros in all the given numbers, ignoring their decimal
+// points and signs. Numbers which are null altogether are not considered
+func nbZeros(numbers []string) (count int) {
+
+	for _, number := range numbers {
+		digits := strings.TrimLeft(strings.Replace(number, ".", "", 1), "-")
+		if strings.Trim(digits, "0") != "" {
+			count += strings.Count(digits, "0")
+		}
+	}
+	return
+}
+
+// return the number of significant digits of the given number, ignoring its
+// decimal point and sign, so that the remainders of divisions with decimals
+// can be compared with their divisors
+func nbSignificantDigits(number string) int {
+	return len(strings.TrimLeft(strings.Replace(number, ".", "", 1), "-0"))
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- DifficultyBand
+
+// return an error if the bounds of this difficulty band are not correct
+func (band DifficultyBand) Validate() error {
+
+	if band.Min < 0 || (band.Min > 0 && band.Min < MINDIFFICULTY) || band.Max < 0 || band.Max > MAXDIFFICULTY {
+		return fmt.Errorf("the bounds of the difficulty band %v should be within [%v, %v]", band, MINDIFFICULTY, MAXDIFFICULTY)
+	}
+	if band.Max > 0 && band.Min > band.Max {
+		return fmt.Errorf("the lower bound of the difficulty band %v can not be larger than its upper bound", band)
+	}
+	return nil
+}
+
+// return true if this band accepts all problems
+func (band DifficultyBand) isNull() bool {
+	return band.Min == 0 && band.Max == 0
+}
+
+// return true if the given difficulty falls within this band
+func (band DifficultyBand) contains(difficulty int) bool {
+	return (band.Min == 0 || band.Min <= difficulty) && (band.Max == 0 || difficulty <= band.Max)
+}
+
+// return a string representation of this band as an interval
+func (band DifficultyBand) String() string {
+	return fmt.Sprintf("[%v, %v]", band.Min, band.Max)
+}
+
+// generate a new problem with the given function and the given source of
+// random numbers whose difficulty falls within this band. If it is not
+// possible after the maximum number of attempts, then an error is returned as
+// the parameters of the problem are deemed to be incompatible with the band
+func (band DifficultyBand) generate(generate func(rnd *rand.Rand) (ProblemJSON, error), rnd *rand.Rand) (ProblemJSON, error) {
+
+	for attempt := 0; attempt < MAXGENERATIONATTEMPTS; attempt++ {
+		problem, err := generate(rnd)
+		if err != nil || band.contains(problem.Difficulty) {
+			return problem, err
+		}
+
+		// problems which are not rated (e.g., those of problem types
+		// registered by third parties) never fall within the band
+		if problem.Difficulty == 0 {
+			return ProblemJSON{}, fmt.Errorf("the difficulty of problems of type '%v' can not be estimated", problem.Probtype)
+		}
+	}
+	return ProblemJSON{}, fmt.Errorf("it was not possible to generate a problem with a difficulty in %v after %v attempts",
+		band, MAXGENERATIONATTEMPTS)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
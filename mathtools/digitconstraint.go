@@ -0,0 +1,199 @@
+// -*- coding: utf-8 -*-
+// digitconstraint.go
+//
+// Description: Constrains the digits of the operands of problems
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 10:27:14.583016227 (1792146434)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Patterns of operands consist of digits and the following wildcard, which
+// stands for any digit
+const DIGITWILDCARD byte = 'x'
+
+// types
+// ----------------------------------------------------------------------------
+
+// Operands can be constrained so that none of their digits is among the
+// excluded ones and also so that they match any of the given patterns with
+// their number of digits. Patterns consist of digits, which have to appear in
+// the same position, and the wildcard DIGITWILDCARD, which stands for any digit
+// not excluded, e.g., "x0" are the multiples of ten with two digits, and "x00",
+// "x25", "x50" and "x75" are the multiples of 25 with three digits. Operands
+// with a number of digits different than those of all patterns are only
+// constrained by the excluded digits
+type digitConstraint struct {
+	excluded []int
+	patterns []string
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- digitConstraint
+
+// return the patterns of this constraint with the given number of digits. If
+// there are none, a pattern with only wildcards is returned
+func (c digitConstraint) patternsOf(nbdigits int) []string {
+
+	var patterns []string
+	for _, pattern := range c.patterns {
+		if len(pattern) == nbdigits {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		wildcards := make([]byte, nbdigits)
+		for idx := range wildcards {
+			wildcards[idx] = DIGITWILDCARD
+		}
+		patterns = append(patterns, string(wildcards))
+	}
+	return patterns
+}
+
+// return true if this constraint restricts numbers with the given number of
+// digits in any way and false otherwise
+func (c digitConstraint) constrains(nbdigits int) bool {
+
+	if len(c.excluded) > 0 {
+		return true
+	}
+	for _, pattern := range c.patterns {
+		if len(pattern) == nbdigits {
+			return true
+		}
+	}
+	return false
+}
+
+// return the digits that can be written in a position with the given symbol of
+// a pattern. Numbers never start with zero
+func (c digitConstraint) allowed(symbol byte, leading bool) (digits []int) {
+
+	for digit := 0; digit <= 9; digit++ {
+		if (leading && digit == 0) || c.isExcluded(digit) ||
+			(symbol != DIGITWILDCARD && int(symbol-'0') != digit) {
+			continue
+		}
+		digits = append(digits, digit)
+	}
+	return
+}
+
+// return true if the given digit is excluded and false otherwise
+func (c digitConstraint) isExcluded(digit int) bool {
+
+	for _, excluded := range c.excluded {
+		if excluded == digit {
+			return true
+		}
+	}
+	return false
+}
+
+// return true if the magnitude of the given number satisfies this constraint
+// and false otherwise
+func (c digitConstraint) admits(number int) bool {
+
+	if number < 0 {
+		number = -number
+	}
+	digits := strconv.Itoa(number)
+	for _, pattern := range c.patternsOf(len(digits)) {
+		matches := true
+		for idx := range digits {
+			if !c.matches(digits[idx], pattern[idx]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// return true if the given digit (as a character) can be written in a position
+// with the given symbol of a pattern
+func (c digitConstraint) matches(digit, symbol byte) bool {
+
+	if c.isExcluded(int(digit - '0')) {
+		return false
+	}
+	return symbol == DIGITWILDCARD || symbol == digit
+}
+
+// return a random number with the given number of digits which satisfies this
+// constraint using the given source of random numbers. First, a pattern is
+// randomly chosen and then every digit is randomly picked among those allowed.
+// The constraint is assumed to have been verified for this number of digits
+func (c digitConstraint) random(rnd *rand.Rand, nbdigits int) int {
+
+	patterns := c.patternsOf(nbdigits)
+	pattern := patterns[rnd.Intn(len(patterns))]
+	number := 0
+	for idx := range pattern {
+		digits := c.allowed(pattern[idx], idx == 0)
+		number = 10*number + digits[rnd.Intn(len(digits))]
+	}
+	return number
+}
+
+// return an error if this constraint can not be satisfied by the given
+// operands, each one with the corresponding number of digits given in
+// nbdigits. Excluded digits should be in the range [0, 9], and every pattern
+// should have the number of digits of some operand and it should be possible
+// to write at least one digit in every position
+func (c digitConstraint) verify(operands []string, nbdigits []int) error {
+
+	for _, digit := range c.excluded {
+		if digit < 0 || digit > 9 {
+			return fmt.Errorf("the excluded digit '%v' should be in the range [0, 9]", digit)
+		}
+	}
+	for _, pattern := range c.patterns {
+		found := false
+		for _, n := range nbdigits {
+			found = found || len(pattern) == n
+		}
+		if !found {
+			return fmt.Errorf("the pattern '%v' has %v digits, but no operand has that number of digits", pattern, len(pattern))
+		}
+		for idx := range pattern {
+			if pattern[idx] != DIGITWILDCARD && (pattern[idx] < '0' || pattern[idx] > '9') {
+				return fmt.Errorf("the pattern '%v' should consist only of digits and the wildcard '%c'", pattern, DIGITWILDCARD)
+			}
+		}
+	}
+
+	// finally, make sure that operands can be generated
+	for idx, n := range nbdigits {
+		for _, pattern := range c.patternsOf(n) {
+			for jdx := range pattern {
+				if len(c.allowed(pattern[jdx], jdx == 0)) == 0 {
+					return fmt.Errorf("it is not possible to generate the %v with the pattern '%v' and the excluded digits %v", operands[idx], pattern, c.excluded)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -23,10 +23,11 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
 	"strconv"
+	"strings"
 	"text/template"
-	"time"
 
 	"github.com/clinaresl/mathprob/helpers"
 	"github.com/clinaresl/mathprob/mathtools/components"
@@ -35,6 +36,14 @@ import (
 // constants
 // ----------------------------------------------------------------------------
 
+// The remainder of divisions can be left random, or it can be forced to be
+// zero (exact divisions) or strictly positive
+const (
+	DIVRANDOM  string = "random"
+	DIVEXACT   string = "exact"
+	DIVNONZERO string = "nonzero"
+)
+
 // the TikZ code for generating divisions with any parameters is shown
 // below
 const latexDivisionCode = `\begin{minipage}{0.25\linewidth}
@@ -49,6 +58,20 @@ const latexDivisionCode = `\begin{minipage}{0.25\linewidth}
 \end{minipage}
 `
 
+// Divisions can be drawn with any of the following layouts: the divisor
+// enclosed in a box to the right of the dividend with the quotient below it
+// (Spain), the long division bracket between the divisor to the left and the
+// dividend, with the quotient above it (US and UK), or the potence, i.e., a
+// long vertical line separating the dividend from the divisor, which is
+// underlined and has the quotient below it (France)
+const (
+	DIVBOX     string = "box"
+	DIVBRACKET string = "bracket"
+	DIVPOTENCE string = "potence"
+)
+
+// the TikZ code of every layout of divisions is shown below. They all draw the
+// dividend and the scaffold, if any, in the same place
 const tikZDivisionCode = `% --- Coordinates -------------------------------------------------------
 {{.Label1}}
 {{.Label2}}
@@ -75,24 +98,151 @@ const tikZDivisionCode = `% --- Coordinates ------------------------------------
         % Divisor
 {{.Divisor}}
         % -----------------------------------------------------------------------
-`
+{{if .GetTable}}
+        % --- Table of multiples ------------------------------------------------
+
+        % the products of the divisor by every digit help finding the digits of
+        % the quotient
+{{.GetTable}}        % -----------------------------------------------------------------------
+{{end}}{{if .GetScaffold}}
+        % --- Scaffold ----------------------------------------------------------
+
+        % every step consists of the product of the divisor by the next digit of
+        % the quotient, which is subtracted, and the remainder with the next
+        % digit of the dividend brought down
+{{.GetScaffold}}        % -----------------------------------------------------------------------
+{{end}}`
+
+const tikZBracketDivisionCode = `% --- Coordinates -------------------------------------------------------
+{{.Label1}}
+        % -----------------------------------------------------------------------
+
+        % --- Bounding Box ------------------------------------------------------
+{{.BBox}}
+        % -----------------------------------------------------------------------
+        % draw the long division bracket over the dividend
+{{.SBox}}
+        % show the box for writing the quotient above the dividend
+{{.Answer}}
+        % -----------------------------------------------------------------------
+
+        % --- Text ------------------------------------------------------------
+
+        % Dividend
+{{.Dividend}}
+        % Divisor
+{{.Divisor}}
+        % -----------------------------------------------------------------------
+{{if .GetTable}}
+        % --- Table of multiples ------------------------------------------------
+
+        % the products of the divisor by every digit help finding the digits of
+        % the quotient
+{{.GetTable}}        % -----------------------------------------------------------------------
+{{end}}{{if .GetScaffold}}
+        % --- Scaffold ----------------------------------------------------------
+
+        % every step consists of the product of the divisor by the next digit of
+        % the quotient, which is subtracted, and the remainder with the next
+        % digit of the dividend brought down
+{{.GetScaffold}}        % -----------------------------------------------------------------------
+{{end}}`
+
+const tikZPotenceDivisionCode = `% --- Coordinates -------------------------------------------------------
+{{.Label1}}
+{{.Label2}}
+{{.Label3}}
+        % -----------------------------------------------------------------------
+
+        % --- Ancilliary reference points
+{{.Line1}}
+        % -----------------------------------------------------------------------
+
+        % --- Bounding Box ------------------------------------------------------
+{{.BBox}}
+        % -----------------------------------------------------------------------
+        % draw the potence between the dividend and the divisor
+{{.SBox}}
+        % show the box for writing the quotient below the divisor
+{{.Answer}}
+        % -----------------------------------------------------------------------
+
+        % --- Text ------------------------------------------------------------
+
+        % Dividend
+{{.Dividend}}
+        % Divisor
+{{.Divisor}}
+        % -----------------------------------------------------------------------
+{{if .GetTable}}
+        % --- Table of multiples ------------------------------------------------
+
+        % the products of the divisor by every digit help finding the digits of
+        % the quotient
+{{.GetTable}}        % -----------------------------------------------------------------------
+{{end}}{{if .GetScaffold}}
+        % --- Scaffold ----------------------------------------------------------
+
+        % every step consists of the product of the divisor by the next digit of
+        % the quotient, which is subtracted, and the remainder with the next
+        % digit of the dividend brought down
+{{.GetScaffold}}        % -----------------------------------------------------------------------
+{{end}}`
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The TikZ code of every layout of divisions is indexed by its name
+var tikZDivisionLayouts = map[string]string{
+	DIVBOX:     tikZDivisionCode,
+	DIVBRACKET: tikZBracketDivisionCode,
+	DIVPOTENCE: tikZPotenceDivisionCode,
+}
 
 // types
 // ----------------------------------------------------------------------------
 
 // The formal definition of a division problem is given below. It is defined
-// with the number of digits of the dividend, divisor and quotient
+// with the number of digits of the dividend, divisor and quotient. If extended
+// is true, then the step-by-step scaffold of the long division is shown as well.
+// Divisions are drawn with the given style, or the one of the current locale
+// if none is given. Their remainder is either random, zero or strictly
+// positive, and if remainderbox is true a box is shown for writing it.
+//
+// If nbdecimals is strictly positive, the quotient is computed with that
+// number of decimals and thus, the dividend is extended with as many zeros,
+// which are brought down one after the other in the scaffold. If table is true,
+// the products of the divisor by every digit are shown to the right of the
+// division, each with a box for writing it. Finally, the dividend and the
+// divisor can be bounded in the ranges [dvgeq, dvleq] and [drgeq, drleq]
+// respectively, where null upper bounds stand for no bound, and their digits
+// can be constrained as well (see digitConstraint)
 type division struct {
-	nbdvdigits int
-	nbdrdigits int
-	nbqdigits  int
+	nbdvdigits   int
+	nbdrdigits   int
+	nbqdigits    int
+	nbdecimals   int
+	extended     bool
+	style        string
+	remainder    string
+	remainderbox bool
+	table        bool
+	dvgeq, dvleq int
+	drgeq, drleq int
+	constraint   digitConstraint
+
+	// generated problems are recorded when solutions are requested
+	recorder
 }
 
-// A division is characterized by its coordinates, a bounding box surrounding
-// all the available area for solving the exercise, the box enclosing the
-// divisor, and also the operands
+// A division is characterized by its layout, its coordinates, a bounding box
+// surrounding all the available area for solving the exercise, the lines
+// separating the divisor from the dividend, and also the operands
 type divisionTikZ struct {
 
+	// the name of the layout used for drawing the division
+	style string
+
 	// the first label is computed explicitly whereas the next two labels are
 	// computed with respect to the previous ones using formulas. All of them
 	// are implemented using the reusable components of TikZ coordinates
@@ -110,8 +260,9 @@ type divisionTikZ struct {
 	// specify the lower left and upper right corners
 	BBox components.CoordinatedRectangle
 
-	// the box surrounding the dividend consists of a path drawn between three
-	// coordinates whose location is determined using formulas
+	// the box surrounding the divisor (or the bracket or potence, depending on
+	// the layout) consists of a path drawn between coordinates whose location
+	// is determined using formulas
 	SBox components.Line
 
 	// the answer should be written within a box explicitly shown
@@ -119,6 +270,102 @@ type divisionTikZ struct {
 
 	// finally, both operands, are created next and implemented as Texts
 	Dividend, Divisor components.Text
+
+	// in the extended layout, the boxes of every step are shown along with the
+	// minus signs and the lines of every subtraction
+	scaffold []components.CoordinatedText
+	lines    []components.Line
+
+	// the table of multiples of the divisor is located at its origin, and it
+	// consists of the text of every product followed by its box
+	tableOrigin components.Coordinate
+	table       []components.Text
+}
+
+// Every step of a long division is characterized by the position of the last
+// digit of the dividend used in it, the product of the divisor by the
+// corresponding digit of the quotient, and the remainder followed by the next
+// digit of the dividend brought down, if any
+type longDivisionStep struct {
+	last      int
+	product   string
+	remainder string
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return all steps of the long division of the given dividend by the given
+// divisor. The dividend is given as a string so that each digit can be
+// accessed. The divisor is assumed to be strictly positive
+func longDivisionSteps(dividend string, divisor int) (steps []longDivisionStep) {
+
+	// the first partial dividend consists of the fewest leading digits of the
+	// dividend which are greater or equal than the divisor. If there are none,
+	// all digits are used
+	last, partial := 0, int(dividend[0]-'0')
+	for partial < divisor && last < len(dividend)-1 {
+		last += 1
+		partial = 10*partial + int(dividend[last]-'0')
+	}
+
+	// now, process every digit of the quotient
+	for {
+
+		// compute the product of the divisor by the next digit of the
+		// quotient, and the remainder
+		product := (partial / divisor) * divisor
+		remainder := partial - product
+
+		// if this is the last digit of the dividend, then this is the last
+		// step
+		if last == len(dividend)-1 {
+			return append(steps, longDivisionStep{
+				last:      last,
+				product:   strconv.Itoa(product),
+				remainder: strconv.Itoa(remainder),
+			})
+		}
+
+		// otherwise, bring down the next digit
+		steps = append(steps, longDivisionStep{
+			last:      last,
+			product:   strconv.Itoa(product),
+			remainder: strconv.Itoa(remainder) + string(dividend[last+1]),
+		})
+		last += 1
+		partial = 10*remainder + int(dividend[last]-'0')
+	}
+}
+
+// return the intermediate steps of the long division of the given dividend by
+// the given divisor as text. Every step divides the partial dividend by the
+// divisor, multiplies the divisor by the digit of the quotient obtained and
+// subtracts the product from the partial dividend. If any digit of the dividend
+// remains, it is brought down next to the remainder
+func longDivisionStepsText(dividend string, divisor int) (steps []string) {
+
+	// the first partial dividend consists of all leading digits of the
+	// dividend used in the first step
+	ldsteps := longDivisionSteps(dividend, divisor)
+	partial, _ := strconv.Atoi(dividend[:ldsteps[0].last+1])
+	for idx, ldstep := range ldsteps {
+
+		product, _ := strconv.Atoi(ldstep.product)
+		digit := product / divisor
+		steps = append(steps,
+			solutionStep(partial, "/", divisor, digit),
+			solutionStep(digit, "*", divisor, product),
+			solutionStep(partial, "-", product, partial-product))
+
+		// the next partial dividend is the remainder followed by the next
+		// digit of the dividend
+		if idx < len(ldsteps)-1 {
+			partial, _ = strconv.Atoi(ldstep.remainder)
+			steps = append(steps, fmt.Sprintf("bring down %c: %v", dividend[ldstep.last+1], partial))
+		}
+	}
+	return
 }
 
 // methods
@@ -126,25 +373,64 @@ type divisionTikZ struct {
 
 // -- divisionTikZ
 
+// Generates the TikZ code necessary for drawing the scaffold of a long
+// division. If the division is not shown in the extended layout, then an empty
+// string is returned
+func (tikz divisionTikZ) GetScaffold() string {
+
+	// Use a btyes buffer to append the strings of each component
+	var output bytes.Buffer
+
+	// Draw first all text boxes and then the lines of every subtraction
+	for _, text := range tikz.scaffold {
+		fmt.Fprintf(&output, "%v\n", text)
+	}
+	for _, line := range tikz.lines {
+		fmt.Fprintf(&output, "%v\n", line)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// scaffold
+	return output.String()
+}
+
+// Generates the TikZ code necessary for drawing the table of multiples of the
+// divisor. If it was not requested, then an empty string is returned
+func (tikz divisionTikZ) GetTable() string {
+
+	if len(tikz.table) == 0 {
+		return ""
+	}
+
+	// first, locate the origin of the table and then draw every row
+	var output bytes.Buffer
+	fmt.Fprintf(&output, "%v\n", tikz.tableOrigin)
+	for _, text := range tikz.table {
+		fmt.Fprintf(&output, "%v\n", text)
+	}
+	return output.String()
+}
+
 // Return the LaTeX/TikZ commands that show up the picture stored in the
 // receiver
-func (tikz divisionTikZ) execute() string {
+func (tikz divisionTikZ) execute() (string, error) {
 
-	// create a template with the TikZ code for showing this picture
-	tpl, err := template.New("divisionTikZ").Parse(tikZDivisionCode)
+	// create a template with the TikZ code for showing this picture in its
+	// layout
+	tpl, err := template.New("divisionTikZ").Parse(tikZDivisionLayouts[tikz.style])
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the execution of
 	// the template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, tikz); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 // -- division
@@ -155,21 +441,23 @@ func (tikz divisionTikZ) execute() string {
 //
 // The result is given with four items: dividend, divisor, quotient and
 // remainer. The remainder and the quotient are shown as "?" in the arguments as
-// they have to be guessed by the student
-func (div division) generateJSONProblem() (problemJSON, error) {
-
-	rand.Seed(time.Now().UTC().UnixNano())
+// they have to be guessed by the student. If the quotient is computed with
+// decimals, both the quotient and the remainder are given as decimal numbers
+// with that number of decimals. If the table of multiples of the divisor is
+// requested, the products of the divisor by every digit from 1 to 9 are given
+// next, also shown as "?" in the arguments
+func (div division) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
 
 	// First, verify that parameters are correct. If they are not, take the best
-	// action
-	if div.nbqdigits < div.nbdvdigits-div.nbdrdigits {
-		log.Printf(" It is not possible to generate quotients with %v digits if the dividend has %v digits and the divisor has %v digits. Thus, %v digits in the quotient are generated instead", div.nbqdigits, div.nbdvdigits, div.nbdrdigits, div.nbdvdigits-div.nbdrdigits)
-		div.nbqdigits = div.nbdvdigits - div.nbdrdigits
+	// action. Note that the quotient would be always zero if the dividend had
+	// less digits than the divisor
+	if div.nbdvdigits < div.nbdrdigits {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate divisions with %v digits in the dividend and %v digits in the divisor",
+			div.nbdvdigits, div.nbdrdigits)
 	}
-
-	if div.nbqdigits > div.nbdvdigits-div.nbdrdigits+1 {
-		log.Printf(" It is not possible to generate quotients with %v digits if the dividend has %v digits and the divisor has %v digits. Thus, %v digits in the quotient are generated instead", div.nbqdigits, div.nbdvdigits, div.nbdrdigits, div.nbdvdigits-div.nbdrdigits+1)
-		div.nbqdigits = div.nbdvdigits - div.nbdrdigits + 1
+	if nbqdigits := div.quotientDigits(); nbqdigits != div.nbqdigits {
+		log.Printf(" It is not possible to generate quotients with %v digits if the dividend has %v digits and the divisor has %v digits. Thus, %v digits in the quotient are generated instead", div.nbqdigits, div.nbdvdigits, div.nbdrdigits, nbqdigits)
+		div.nbqdigits = nbqdigits
 	}
 
 	// create two slices: one for storing the instance of this problem in the
@@ -179,67 +467,164 @@ func (div division) generateJSONProblem() (problemJSON, error) {
 	args := make([]string, 4)
 	solution := make([]string, 4)
 
-	// now, generate numbers in their corresponding range
+	// now, generate numbers in their corresponding range. If no quotient is
+	// found after a maximum number of attempts, the parameters are deemed to
+	// be incompatible. Note the quotient is computed over the dividend
+	// extended with as many zeros as decimals and thus, the number of digits
+	// requested refers to its integer part
 	var dividend, divisor, quotient int
-	for helpers.NbDigits(quotient) != div.nbqdigits || quotient == 0 {
-		dividend = helpers.RandN(div.nbdvdigits)
-		divisor = helpers.RandN(div.nbdrdigits)
-		quotient = dividend / divisor
+	dvlower, dvupper := operandRange(div.nbdvdigits, div.dvgeq, div.dvleq)
+	drlower, drupper := operandRange(div.nbdrdigits, div.drgeq, div.drleq)
+	scale := int(math.Pow10(div.nbdecimals))
+	for attempt := 0; helpers.NbDigits(quotient/scale) != div.nbqdigits || quotient < scale || !div.isValidRemainder(scale*dividend-divisor*quotient) ||
+		dividend < dvlower || dividend > dvupper || divisor < drlower || divisor > drupper ||
+		!div.constraint.admits(dividend) || !div.constraint.admits(divisor); attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate a division with %v digits in the dividend, %v digits in the divisor and %v digits in the quotient after %v attempts",
+				div.nbdvdigits, div.nbdrdigits, div.nbqdigits, MAXGENERATIONATTEMPTS)
+		}
+		dividend = div.operand(rnd, div.nbdvdigits, dvlower, dvupper)
+		divisor = div.operand(rnd, div.nbdrdigits, drlower, drupper)
+		quotient = scale * dividend / divisor
 	}
 
 	// now, copy the arguments and the full solution
 	solution[0] = strconv.FormatInt(int64(dividend), 10)
 	solution[1] = strconv.FormatInt(int64(divisor), 10)
-	solution[2] = strconv.FormatInt(int64(quotient), 10)
-	solution[3] = strconv.FormatInt(int64(dividend-divisor*quotient), 10)
+	solution[2] = formatDecimal(quotient, div.nbdecimals)
+	solution[3] = formatDecimal(scale*dividend-divisor*quotient, div.nbdecimals)
 	args[0] = solution[0]
 	args[1] = solution[1]
 	args[2] = "?"
 	args[3] = "?"
+	if div.table {
+		for digit := 1; digit <= 9; digit++ {
+			solution = append(solution, strconv.Itoa(digit*divisor))
+			args = append(args, "?")
+		}
+	}
 
-	// and return the problem along with its solution
-	return problemJSON{
+	// and return the problem along with its solution and the steps of the long
+	// division
+	return ProblemJSON{
 		Probtype: "Division",
 		Args:     args,
-		Solution: solution}, nil
+		Solution: solution,
+		Steps:    longDivisionStepsText(solution[0]+strings.Repeat("0", div.nbdecimals), divisor)}, nil
+}
+
+// return a random operand of this division with the given number of digits in
+// the range [lower, upper] using the given source of random numbers. If its
+// digits are constrained, it is generated digit by digit and thus, it might
+// fall out of the given range
+func (div division) operand(rnd *rand.Rand, nbdigits, lower, upper int) int {
+
+	if div.constraint.constrains(nbdigits) {
+		return div.constraint.random(rnd, nbdigits)
+	}
+	return randRange(rnd, lower, upper)
+}
+
+// return the number of digits of the quotient of this division. If it is not
+// consistent with the number of digits of the dividend and the divisor, the
+// closest feasible number of digits is returned instead
+func (div division) quotientDigits() int {
+
+	if div.nbqdigits < div.nbdvdigits-div.nbdrdigits {
+		return div.nbdvdigits - div.nbdrdigits
+	}
+	if div.nbqdigits > div.nbdvdigits-div.nbdrdigits+1 {
+		return div.nbdvdigits - div.nbdrdigits + 1
+	}
+	return div.nbqdigits
+}
+
+// return true if the given remainder is acknowledged by this division and false
+// otherwise
+func (div division) isValidRemainder(remainder int) bool {
+
+	switch div.remainder {
+	case DIVEXACT:
+		return remainder == 0
+	case DIVNONZERO:
+		return remainder != 0
+	}
+	return true
+}
+
+// set the difficulty and tags of the given problem generated by this division.
+// Divisions are harder with more digits in the divisor and the quotient
+// (including its decimals), when the quotient contains zeros, and also when the
+// remainder is as long as a divisor with several digits
+func (div division) annotate(problem *ProblemJSON) {
+
+	problem.Tags = []string{"division", fmt.Sprintf("%v-digit-divisor", div.nbdrdigits)}
+	if strings.Trim(problem.Solution[3], "0.") == "" {
+		problem.Tags = append(problem.Tags, "exact")
+	} else {
+		problem.Tags = append(problem.Tags, "remainder")
+	}
+	if div.nbdecimals > 0 {
+		problem.Tags = append(problem.Tags, "decimal-quotient")
+	}
+	score := div.nbdrdigits + div.quotientDigits() + div.nbdecimals - 1
+	if strings.Contains(problem.Solution[2], "0") {
+		score++
+		problem.Tags = append(problem.Tags, "zero-in-quotient")
+	}
+	if div.nbdrdigits > 1 && nbSignificantDigits(problem.Solution[3]) == div.nbdrdigits {
+		score++
+		problem.Tags = append(problem.Tags, "large-remainder")
+	}
+	problem.Difficulty = difficulty(score)
 }
 
 // return a valid LaTeX/TikZ representation of this sequence using TikZ
 // components
-func (div division) GetTikZPicture() string {
+func (div division) GetTikZPicture() (string, error) {
+
+	// the picture is drawn with the number of digits of the quotient actually
+	// generated
+	nbqdigits := div.quotientDigits()
+
+	// the dividend extended with zeros takes as many columns as its digits and
+	// decimals, and so does the quotient
+	nbdvcolumns := float64(div.nbdvdigits + div.nbdecimals)
+	nbqcolumns := float64(nbqdigits + div.nbdecimals)
 
 	// --coordinates
 	label1 := components.NewCoordinate(components.Point{
 		X: 0.0,
-		Y: 1 + 2.0*float64(div.nbqdigits) + 0.5,
+		Y: 1 + 2.0*float64(nbqdigits) + 0.5,
 	}, "label1")
 
 	label2 := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(label1) + %v*(\zerowidth, 0.0)$`,
-			2.0+float64(div.nbdvdigits))),
+			helpers.Ftoa(2.0+nbdvcolumns))),
 		"label2")
 
 	label3 := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(label2) + (%v*\zerowidth, -\zeroheight)$`,
-			0.5*(2+helpers.Max(float64(div.nbdrdigits), float64(div.nbqdigits))))),
+			helpers.Ftoa(0.5*(2+helpers.Max(float64(div.nbdrdigits), nbqcolumns))))),
 		"label3")
 
 	// --lines
 	line1 := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(label2) + (-%v\zerowidth, -2*\zeroheight-0.15 cm)$`,
-			2.0+float64(div.nbdvdigits))),
+			helpers.Ftoa(2.0+nbdvcolumns))),
 		"line1")
 
 	// --bounding box
 	bottom := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(line1) + %v*(0.0, -\zeroheight-\baselineskip-0.5/%v*\zeroheight)$`,
-			2.0*float64(div.nbqdigits)-1.0,
-			2.0*float64(div.nbqdigits)-1.0)),
+			helpers.Ftoa(2.0*float64(nbqdigits)-1.0),
+			helpers.Ftoa(2.0*float64(nbqdigits)-1.0))),
 		"bottom")
 	right := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(line1) + %v*(0.0, -\zeroheight-\baselineskip-0.5/%v*\zeroheight)$`,
-			2.0*float64(div.nbqdigits)-1.0,
-			2.0*float64(div.nbqdigits)-1.0)),
+			helpers.Ftoa(2.0*float64(nbqdigits)-1.0),
+			helpers.Ftoa(2.0*float64(nbqdigits)-1.0))),
 		"right")
 	bBox := components.NewCoordinatedRectangle(bottom, right)
 
@@ -247,31 +632,31 @@ func (div division) GetTikZPicture() string {
 	sBox := components.NewLine(`$(label2) + (0.0, \zeroheight)$`,
 		`$(label2) + (0.0, -\zeroheight)$`,
 		fmt.Sprintf(`$(label2) + %v*(\zerowidth, -\zeroheight/%v)$`,
-			2.0+helpers.Max(float64(div.nbdrdigits), float64(div.nbqdigits)),
-			2.0+helpers.Max(float64(div.nbdrdigits), float64(div.nbqdigits))))
+			helpers.Ftoa(2.0+helpers.Max(float64(div.nbdrdigits), nbqcolumns)),
+			helpers.Ftoa(2.0+helpers.Max(float64(div.nbdrdigits), nbqcolumns))))
 	sBox.SetOptions("thick, rounded corners")
 
-	// --answer
-
-	// note the answer is written withing a text box which necessarily contains
-	// nothing. No label is assigned to it as well as no computations are
-	// performed from its location
-	answer := components.NewText(
-		fmt.Sprintf(`rounded corners, rectangle, minimum width=%v*\zerowidth, minimum height = \zeroheight+\baselineskip, draw, below=0.15 cm of label3`,
-			2.0+helpers.Max(float64(div.nbdrdigits), float64(div.nbqdigits))),
-		"", "",
-	)
-
 	// -- operands
 
 	// randomly determine the values of the operands. For this, the service that
 	// generates problems is the one that can marshal them into JSON format. The
 	// dividend is returned in the first position and the divisor in the second
-	instance, err := div.generateJSONProblem()
+	instance, err := div.next(annotated(div))
 	if err != nil {
-		log.Fatalf(" Fatal error while generating a valid division: %v", err)
+		return "", fmt.Errorf("error while generating a valid division: %v", err)
 	}
 
+	// --answer
+
+	// note the answer is written withing a text box which contains nothing,
+	// unless the quotient is shown in an answer key. No label is assigned to it
+	// as well as no computations are performed from its location
+	answer := components.NewText(
+		fmt.Sprintf(`rounded corners, rectangle, minimum width=%v*\zerowidth, minimum height = \zeroheight+\baselineskip, draw, below=0.15 cm of label3`,
+			helpers.Ftoa(2.0+helpers.Max(float64(div.nbdrdigits), nbqcolumns))),
+		"", div.answer(localizeDecimal(instance.Solution[2])),
+	)
+
 	dividend := components.NewText(
 		`right=0.0 cm of label1`,
 		"dividend",
@@ -283,17 +668,219 @@ func (div division) GetTikZPicture() string {
 		`\huge `+instance.Solution[1],
 	)
 
+	// -- scaffold
+
+	// in the extended layout, every step of the long division is shown in two
+	// rows below the dividend: the first one with the product to subtract and
+	// the second one with the remainder. Numbers are right-aligned with the
+	// last digit of the dividend used in each step, and all boxes are as wide
+	// as the numbers to write in them. The zeros of the dividend extended with
+	// decimals are located right after its last digit. Otherwise, only the
+	// box of the remainder is shown right below the dividend, if requested
+	var scaffold []components.CoordinatedText
+	var lines []components.Line
+	var nbsteps int
+
+	// the following function returns the location of the given column
+	// (starting from 0 with the first digit of the dividend) in the given row
+	// (starting from 1 right below the dividend). Note that the dividend is
+	// shown to the right of label1 and thus, the default inner separation of
+	// nodes has to be considered
+	at := func(column float64, row int) string {
+		return fmt.Sprintf(`$(label1) + (%v\zerowidth + 0.3333em, -%v\zeroheight-%v\baselineskip)$`,
+			helpers.Ftoa(column), row, row)
+	}
+
+	// the following function returns a box with the given width (in digits)
+	// for writing the given number which ends in the given column
+	box := func(label string, number string, width, last, row int) components.CoordinatedText {
+		return components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(at(float64(last)+1.0-float64(width)/2.0, row)),
+				label),
+			fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				width),
+			div.answer(number))
+	}
+
+	if div.extended {
+
+		divisor, _ := helpers.Atoi(instance.Solution[1])
+		steps := longDivisionSteps(instance.Solution[0]+strings.Repeat("0", div.nbdecimals), divisor)
+		nbsteps = len(steps)
+		for idx, step := range steps {
+
+			// -- product: it is preceded by a minus sign and followed by the
+			//             line of the subtraction
+			row := 1 + 2*idx
+			first := step.last + 1 - len(step.product)
+			scaffold = append(scaffold,
+				box(fmt.Sprintf("product%v", idx), step.product, len(step.product), step.last, row),
+				components.NewCoordinatedText(
+					components.NewCoordinate(
+						components.Formula(at(float64(first)-0.75, row)),
+						fmt.Sprintf("minus%v", idx)),
+					"", `\huge $-$`))
+			line := components.NewLine(
+				fmt.Sprintf(`$(label1) + (%v\zerowidth + 0.3333em, -%v\zeroheight-%v\baselineskip)$`,
+					helpers.Ftoa(float64(first)-1.0), helpers.Ftoa(float64(row)+0.5), helpers.Ftoa(float64(row)+0.5)),
+				fmt.Sprintf(`$(label1) + (%v\zerowidth + 0.3333em, -%v\zeroheight-%v\baselineskip)$`,
+					helpers.Ftoa(float64(step.last)+1.0), helpers.Ftoa(float64(row)+0.5), helpers.Ftoa(float64(row)+0.5)))
+			lines = append(lines, line)
+
+			// -- remainder: except in the last step, a digit of the dividend
+			//               is brought down and thus the remainder ends in the
+			//               next column
+			last := step.last
+			if idx < len(steps)-1 {
+				last += 1
+			}
+			scaffold = append(scaffold,
+				box(fmt.Sprintf("remainder%v", idx), step.remainder, len(step.remainder), last, row+1))
+		}
+
+		// the bounding box has to be enlarged to host all rows
+		bottom = components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(label1) + (0.0, -%v\zeroheight-%v\baselineskip)$`,
+				helpers.Ftoa(2.0*float64(len(steps))+0.5), helpers.Ftoa(2.0*float64(len(steps))+0.5))),
+			"bottom")
+		right = components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(label2) + (%v\zerowidth, 0.0)$`,
+				helpers.Ftoa(2.0+helpers.Max(float64(div.nbdrdigits), nbqcolumns)))),
+			"right")
+		bBox = components.NewCoordinatedRectangle(bottom, right)
+		bBox.SetOptions("white")
+	} else if div.remainderbox {
+
+		// the remainder is less than the divisor and thus, its box is as wide
+		// as the divisor unless it has decimals, which have to be written
+		// after the decimal separator
+		width := div.nbdrdigits
+		if div.nbdecimals > 0 {
+			width = 1 + int(helpers.Max(float64(div.nbdrdigits), float64(div.nbdecimals+1)))
+		}
+		scaffold = append(scaffold,
+			box("remainder", localizeDecimal(instance.Solution[3]), width, div.nbdvdigits+div.nbdecimals-1, 1))
+	}
+
+	// -- layout: by default, the divisor is enclosed in a box. Otherwise, the
+	//            dividend and the scaffold are located exactly in the same
+	//            place but the divisor, the quotient and the lines separating
+	//            them are drawn differently
+	style := div.style
+	if style == "" {
+		style = currentLocale().DivisionStyle
+	}
+	switch style {
+	case DIVBRACKET:
+
+		// the divisor is written to the left of the dividend and the quotient
+		// above it
+		divisor = components.NewText(
+			`left=0.1 cm of label1`,
+			"divisor",
+			`\huge `+instance.Solution[1],
+		)
+		sBox = components.NewLine(`$(label1) + (0.0, -\zeroheight)$`,
+			`$(label1) + (0.15 cm, 0.0)$`,
+			`$(label1) + (0.0, \zeroheight)$`,
+			fmt.Sprintf(`$(label1) + (%v\zerowidth + 0.6666em, \zeroheight)$`,
+				helpers.Ftoa(nbdvcolumns)))
+		sBox.SetOptions("thick, rounded corners")
+		answer = components.NewText(
+			fmt.Sprintf(`rounded corners, rectangle, minimum width=%v*\zerowidth, minimum height = \zeroheight+\baselineskip, draw, above=\zeroheight+0.15 cm of label1, xshift=%v\zerowidth + 0.3333em`,
+				helpers.Ftoa(2.0+nbqcolumns), helpers.Ftoa(0.5*nbdvcolumns)),
+			"", div.answer(localizeDecimal(instance.Solution[2])),
+		)
+
+		// the bounding box hosts the divisor to the left, the quotient above
+		// and all rows of the scaffold, if any, below
+		rows := 1.0
+		if div.extended {
+			rows = 2.0*float64(nbsteps) + 0.5
+		} else if div.remainderbox {
+			rows = 1.5
+		}
+		bottom = components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(label1) + (-%v\zerowidth - 0.5 cm, -%v\zeroheight-%v\baselineskip)$`,
+				helpers.Ftoa(1.0+float64(div.nbdrdigits)), helpers.Ftoa(rows), helpers.Ftoa(rows))),
+			"bottom")
+		right = components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(label1) + (%v\zerowidth + 0.6666em, 2\zeroheight+\baselineskip+0.3 cm)$`,
+				helpers.Ftoa(1.0+nbdvcolumns))),
+			"right")
+		bBox = components.NewCoordinatedRectangle(bottom, right)
+		bBox.SetOptions("white")
+
+	case DIVPOTENCE:
+
+		// the divisor and the quotient are located as in the box layout, but
+		// the vertical line goes down along all rows of the scaffold, if any,
+		// and the horizontal line is drawn only below the divisor
+		rows := 2.0
+		if div.extended {
+			rows = 2.0*float64(nbsteps) + 0.5
+		}
+		width := helpers.Ftoa(2.0 + helpers.Max(float64(div.nbdrdigits), nbqcolumns))
+		sBox = components.NewLine(fmt.Sprintf(`$(label2) + (%v\zerowidth, -\zeroheight)$`, width),
+			`$(label2) + (0.0, -\zeroheight)$`,
+			`$(label2) + (0.0, \zeroheight)$`,
+			`$(label2) + (0.0, -\zeroheight)$`,
+			fmt.Sprintf(`$(label2) + (0.0, -%v\zeroheight-%v\baselineskip)$`,
+				helpers.Ftoa(rows), helpers.Ftoa(rows)))
+		sBox.SetOptions("thick")
+	}
+
+	// -- table: the products of the divisor by every digit are shown to the
+	//           right of the division, one per row, each followed by a box for
+	//           writing it
+	var tableOrigin components.Coordinate
+	var table []components.Text
+	if div.table {
+		origin := fmt.Sprintf(`$(label2) + (%v\zerowidth + 0.5 cm, \zeroheight)$`,
+			helpers.Ftoa(2.0+helpers.Max(float64(div.nbdrdigits), nbqcolumns)))
+		if style == DIVBRACKET {
+			origin = fmt.Sprintf(`$(label1) + (%v\zerowidth + 0.6666em + 0.5 cm, 2\zeroheight+\baselineskip)$`,
+				helpers.Ftoa(helpers.Max(nbdvcolumns, 0.5*(2.0+nbdvcolumns+nbqcolumns))))
+		}
+		tableOrigin = components.NewCoordinate(components.Formula(origin), "table")
+		for digit := 1; digit <= 9; digit++ {
+			position := "at=(table)"
+			if digit > 1 {
+				position = fmt.Sprintf("below=0.1 cm of multiple%v.south west", digit-1)
+			}
+			product := ""
+			if div.showAnswers() {
+				product = instance.Solution[3+digit]
+			}
+			table = append(table,
+				components.NewText(
+					fmt.Sprintf(`%v, anchor=north west, minimum height=1.5em, font=\large`, position),
+					fmt.Sprintf("multiple%v", digit),
+					fmt.Sprintf(`$%v \times %v =$`, instance.Solution[1], digit)),
+				components.NewText(
+					fmt.Sprintf(`right=0.1 cm of multiple%v, rounded corners, rectangle, minimum width=%vem, minimum height=1.5em, draw, font=\large`,
+						digit, helpers.Ftoa(0.5*float64(div.nbdrdigits+2))),
+					"", product))
+		}
+	}
+
 	// And put all this elements together to show up the picture of a division
 	divPicture := divisionTikZ{
-		Label1:   label1,
-		Label2:   label2,
-		Label3:   label3,
-		Line1:    line1,
-		BBox:     bBox,
-		SBox:     sBox,
-		Answer:   answer,
-		Dividend: dividend,
-		Divisor:  divisor,
+		style:       style,
+		Label1:      label1,
+		Label2:      label2,
+		Label3:      label3,
+		Line1:       line1,
+		BBox:        bBox,
+		SBox:        sBox,
+		Answer:      answer,
+		Dividend:    dividend,
+		Divisor:     divisor,
+		scaffold:    scaffold,
+		lines:       lines,
+		tableOrigin: tableOrigin,
+		table:       table,
 	}
 
 	// and return the TikZ code necessary for drawing the problem
@@ -302,24 +889,24 @@ func (div division) GetTikZPicture() string {
 
 // Execute the given division instance and returns legal TikZ code to represent
 // it
-func (div division) execute() string {
+func (div division) execute() (string, error) {
 
 	// create a template with the TikZ code for showing this
 	// division problem
 	tpl, err := template.New("division").Parse(latexDivisionCode)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the
 	// execution of the template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, div); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 /* Local Variables: */
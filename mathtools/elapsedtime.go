@@ -0,0 +1,397 @@
+// -*- coding: utf-8 -*-
+// elapsedtime.go
+//
+// Description: Provides services for automatically creating problems of
+// elapsed time, where either the start, the duration or the end of an event
+// has to be computed
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:13:01.182278633 (1792113181)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are three different types of elapsed time problems depending on the
+// unknown: the end of the event, its duration or its start
+const (
+	ETEND int = iota
+	ETDURATION
+	ETSTART
+)
+
+// By default, events can take place at any time of the day and last at most
+// three hours
+const (
+	DEFAULTELAPSEDFROM        string = "00:00"
+	DEFAULTELAPSEDTO          string = "23:59"
+	DEFAULTELAPSEDMAXDURATION int    = 180
+)
+
+// the TikZ code for generating elapsed time problems is shown next. Note that
+// it makes use of LaTeX/TikZ components
+const latexElapsedTimeCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the elapsed time problem
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZElapsedTimeCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Story -----------------------------------------------------------
+
+      % the story is written above the answer boxes
+      {{.Story}}
+
+      % --- Answer ----------------------------------------------------------
+
+      % the answer is written in two boxes in the lower-right corner: either
+      % the hours and minutes of a time, or the hours and minutes of a duration
+{{.GetAnswer}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The stories of elapsed time problems are text templates where {{.Name}} is
+// substituted by the name of a character, {{.Start}} and {{.End}} by the times
+// the event starts and ends, and {{.Duration}} by its duration. They are
+// indexed by the type of problem
+var elapsedTimeStories = map[int][]string{
+	ETEND: {
+		"La película empieza a las {{.Start}} y dura {{.Duration}}. ¿A qué hora termina?",
+		"{{.Name}} sale de casa a las {{.Start}} y tarda {{.Duration}} en llegar a casa de su abuela. ¿A qué hora llega?",
+	},
+	ETDURATION: {
+		"El partido empieza a las {{.Start}} y termina a las {{.End}}. ¿Cuánto dura?",
+		"{{.Name}} entra en la piscina a las {{.Start}} y sale a las {{.End}}. ¿Cuánto tiempo ha estado nadando?",
+	},
+	ETSTART: {
+		"La película termina a las {{.End}} y ha durado {{.Duration}}. ¿A qué hora empezó?",
+		"{{.Name}} llega al colegio a las {{.End}} después de caminar {{.Duration}}. ¿A qué hora salió de casa?",
+	},
+}
+
+// types
+// ----------------------------------------------------------------------------
+
+// An elapsed time problem consists of a type (ETEND, ETDURATION or ETSTART),
+// the granularity of the minutes of all times and durations, either "hour",
+// "half", "quarter", "five" or "minute", the earliest start and the latest end
+// of the event given in minutes since midnight, and its maximum duration in
+// minutes
+type elapsedTime struct {
+	ettype      int
+	granularity string
+	from, to    int
+	maxduration int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw elapsed
+// time problems
+type elapsedTimeTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the story is written above the answer
+	Story components.CoordinatedText
+
+	// the answer consists of two boxes and the symbols shown next to them
+	answer []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the number of minutes since midnight of the given time in the format
+// "hh:mm". If the time is not correct, an error is returned
+func parseTime(time string) (int, error) {
+
+	var hours, minutes int
+	if n, err := fmt.Sscanf(time, "%d:%d", &hours, &minutes); err != nil || n != 2 {
+		return 0, fmt.Errorf("the time '%v' should be given as 'hh:mm'", time)
+	}
+	if hours < 0 || hours > 23 || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("the time '%v' does not exist", time)
+	}
+	return 60*hours + minutes, nil
+}
+
+// return the given number of minutes as hours and minutes in the format
+// "h:mm", which is used both for times and durations
+func formatTime(minutes int) string {
+	return fmt.Sprintf("%v:%02d", minutes/60, minutes%60)
+}
+
+// return the given duration in minutes as text, e.g., "1 h 35 min"
+func formatDuration(minutes int) string {
+
+	if minutes < 60 {
+		return fmt.Sprintf("%v min", minutes)
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%v h", minutes/60)
+	}
+	return fmt.Sprintf("%v h %v min", minutes/60, minutes%60)
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- elapsedTimeTikZ
+
+// Generates the TikZ code necessary for drawing the answer
+func (tikz elapsedTimeTikZ) GetAnswer() string {
+
+	// Use a btyes buffer to append the strings of each component
+	var output bytes.Buffer
+
+	for _, item := range tikz.answer {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// answer
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz elapsedTimeTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("elapsedTimeTikZ").Parse(tikZElapsedTimeCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- elapsedTime
+
+// return the instance of a specific elapsed time problem that can be
+// marshalled in JSON format. The receiver is assumed to have been fully
+// verified so that it should be consistent.
+//
+// The result is given with four items: the story, the start, the duration and
+// the end of the event, all given as "h:mm". The unknown is shown as "?" in the
+// arguments as it has to be guessed by the student
+func (et elapsedTime) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	step := clockStep(et.granularity)
+	if step == 0 {
+		return ProblemJSON{}, fmt.Errorf("Unknown granularity '%v'", et.granularity)
+	}
+	stories, ok := elapsedTimeStories[et.ettype]
+	if !ok {
+		return ProblemJSON{}, fmt.Errorf("Unknown type of elapsed time problem '%v'", et.ettype)
+	}
+
+	// all times are multiples of the granularity, so that the interval of
+	// times is shrunk accordingly
+	from, to := step*helpers.CeilDiv(et.from, step), step*helpers.FloorDiv(et.to, step)
+	longest := helpers.Min(et.maxduration, to-from) / step
+	if longest < 1 {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to fit events lasting at least %v minutes between %v and %v",
+			step, formatTime(et.from), formatTime(et.to))
+	}
+
+	// randomly determine the duration and next the start of the event
+	duration := step * (1 + rnd.Intn(longest))
+	start := from + step*rnd.Intn(1+(to-duration-from)/step)
+	end := start + duration
+
+	// and now tell the story with a random character
+	tpl, err := template.New("story").Parse(stories[rnd.Intn(len(stories))])
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	var text bytes.Buffer
+	if err := tpl.Execute(&text, struct {
+		Name, Start, Duration, End string
+	}{wordProblemNames[rnd.Intn(len(wordProblemNames))],
+		fmt.Sprintf("%02d:%02d", start/60, start%60), formatDuration(duration),
+		fmt.Sprintf("%02d:%02d", end/60, end%60)}); err != nil {
+		return ProblemJSON{}, err
+	}
+
+	// create two slices: one for storing the instance of this problem where
+	// the unknown is marked with a question mark "?"; and another one with the
+	// full solution
+	solution := []string{text.String(), formatTime(start), formatTime(duration), formatTime(end)}
+	args := make([]string, len(solution))
+	copy(args, solution)
+	switch et.ettype {
+	case ETEND:
+		args[3] = "?"
+	case ETDURATION:
+		args[2] = "?"
+	case ETSTART:
+		args[1] = "?"
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "ElapsedTime",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this elapsed time problem using
+// TikZ components
+func (et elapsedTime) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the story to tell. For this, the service
+	//              that generates problems is the one that can marshal them
+	//              into JSON format
+	instance, err := et.next(annotated(et))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid elapsed time problem: %v", err)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- story: it is written within a paragraph which is anchored at its
+	//           lower-left corner right above the answer boxes. The node is
+	//           named so that the bounding box can be computed from it
+	story := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(`$(bottom) + (0.25cm, 0.35cm + \zeroheight + \baselineskip)$`),
+			"story"),
+		"name=storytext, anchor=south west, text width=6.5cm, align=justify",
+		`\large `+instance.Args[0])
+
+	// -- answer: the unknown is split into its hours and minutes, each one
+	//            written in a box. Times are shown as hh:mm whereas durations
+	//            are shown with their units
+	var value, separator, units string
+	for idx, arg := range instance.Args[1:] {
+		if arg == "?" {
+			value = instance.Solution[1+idx]
+			if idx == 1 {
+				separator, units = `\large h`, `\large min`
+			} else {
+				separator = `\huge :`
+			}
+		}
+	}
+	parts := strings.Split(value, ":")
+
+	// boxes and symbols are drawn from right to left, all of them anchored at
+	// their lower-right corner
+	var answer []components.CoordinatedText
+	x := 0.0
+	item := func(label string, width float64, options, text string) {
+		answer = append(answer, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (6.75cm, 0.25cm) - (%v\zerowidth, 0)$`,
+					helpers.Ftoa(x))),
+				label),
+			strings.TrimSuffix("anchor=south east, "+options, ", "), text))
+		x += width
+	}
+	box := `rounded corners, rectangle, minimum width=3\zerowidth, minimum height = \zeroheight + \baselineskip, draw`
+	if units != "" {
+		item("units", 3.5, "", units)
+	}
+	item("minutes", 3.0, box, et.answer(parts[1]))
+	item("separator", 1.5, "", separator)
+	item("hours", 3.0, box, et.answer(parts[0]))
+
+	// -- bounding box: its upper-right corner is located right above the story
+	right := components.NewCoordinate(
+		components.Formula(`$(storytext.north) + (3.5cm, 0.25cm)$`),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// elapsed time problem
+	etPicture := elapsedTimeTikZ{
+		Bottom: bottom,
+		Story:  story,
+		answer: answer,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return etPicture.execute()
+}
+
+// Return TikZ code that represents an elapsed time problem
+func (et elapsedTime) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this elapsed time
+	// problem
+	tpl, err := template.New("elapsedTime").Parse(latexElapsedTimeCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, et); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,94 @@
+// -*- coding: utf-8 -*-
+// envelope.go
+//
+// Description: Wraps the problems generated in JSON format with information
+// about their generation
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 08:24:09.803264115 (1792139049)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Version of the format of the problems generated in JSON format. It changes
+// whenever the format changes in a way that is not backwards compatible
+const JSONFORMATVERSION string = "2.0"
+
+// types
+// ----------------------------------------------------------------------------
+
+// Problems generated in JSON format are wrapped in an envelope which stores the
+// version of the format, the program (and its version) that generated them,
+// the seed used and the time when they were generated, so that downstream
+// systems can detect incompatibilities and reproduce them. The seed is written
+// as a string, since it usually exceeds the integers that can be represented
+// exactly by JSON parsers that use floating-point numbers, e.g., JavaScript
+type JSONEnvelope struct {
+	Format    string        `json:"format"`
+	Generator string        `json:"generator"`
+	Seed      int64         `json:"seed,string"`
+	Timestamp string        `json:"timestamp"`
+	Problems  []ProblemJSON `json:"problems"`
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return a new envelope of problems generated now by the given generator (e.g.,
+// "mathprob 0.1.0") with the given seed
+func NewJSONEnvelope(generator string, seed int64) JSONEnvelope {
+
+	return JSONEnvelope{
+		Format:    JSONFORMATVERSION,
+		Generator: generator,
+		Seed:      seed,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- JSONEnvelope
+
+// write this envelope to the given writer with all the problems requested in
+// the given array of master problems, which are written as soon as they are
+// generated. The output is exactly the same than json.MarshalIndent with a tab
+// as indentation. Nothing is written until the first problem has been
+// generated so that, if it could not be generated, the writer is left
+// untouched. If any other problem could not be generated or written, an error
+// is returned and the contents written so far are not a valid JSON document.
+// The generation stops as soon as the given context is done
+func (envelope JSONEnvelope) Stream(ctx context.Context, w io.Writer, problems []MasterProblem) error {
+
+	// all fields of the envelope but the problems, which are the last field,
+	// are written along with the first problem
+	envelope.Problems = nil
+	data, err := json.MarshalIndent(envelope, "", "\t")
+	if err != nil {
+		return err
+	}
+	header := strings.TrimSuffix(string(data), "null\n}")
+	if err := streamJSONProblems(ctx, w, problems, header, "\t"); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n}")
+	return err
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,49 @@
+// -*- coding: utf-8 -*-
+// envelope_test.go
+//
+// Description: Tests of the envelope of problems generated in JSON format
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 09:48:26.301745118 (1792180106)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// seeds are written as strings so that they are read exactly, also by JSON
+// parsers that represent all numbers as floating-point numbers
+func TestEnvelopeSeed(t *testing.T) {
+
+	problems, err := Unmarshall([]byte(`[{"type": "Division", "args": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2}, "nbprobs": 1, "seed": 1}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	seed := int64(1)<<62 + 1
+	if err := NewJSONEnvelope("test", seed).Stream(context.Background(), &output, problems); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `"seed": "4611686018427387905"`) {
+		t.Errorf("expected the seed %v as a string but got %v", seed, output.String())
+	}
+	var envelope JSONEnvelope
+	if err := json.Unmarshal(output.Bytes(), &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Seed != seed {
+		t.Errorf("expected the seed %v but got %v", seed, envelope.Seed)
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,346 @@
+// -*- coding: utf-8 -*-
+// equivalentfraction.go
+//
+// Description: Provides services for automatically creating problems with
+// equivalent fractions
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 09:12:40.518262113 (1792141960)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of equivalent fractions: "numerator" or
+// "denominator". In the first case, the numerator of the scaled fraction is
+// masked and the student has to guess it; in the latter, the denominator of the
+// scaled fraction is the one to guess
+const (
+	EFNUMERATOR int = iota
+	EFDENOMINATOR
+)
+
+// the TikZ code for generating equivalent fractions is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexEquivalentFractionCode = `\begin{minipage}{0.25\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the equivalent fractions
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZEquivalentFractionCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Base fraction ---------------------------------------------------
+
+      % the denominator is located right above the bottom of the bounding box
+      % and the numerator is shown above it, leaving room for the fraction bar
+      {{.Denominator1}}
+      {{.Numerator1}}
+      {{.Bar1}}
+
+      % --- Equal -----------------------------------------------------------
+
+      {{.Equal}}
+
+      % --- Scaled fraction -------------------------------------------------
+
+      % the scaled fraction is drawn to the right of the equal sign. Either its
+      % numerator or denominator are shown within an empty box
+      {{.Denominator2}}
+      {{.Numerator2}}
+      {{.Bar2}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// An equivalent fraction consists of a base fraction whose denominator is
+// randomly chosen in the interval [dengeq, denleq] and a scaled fraction which
+// results from multiplying both the numerator and denominator of the base
+// fraction by a factor randomly chosen in the interval [scalegeq, scaleleq].
+// There are two types of equivalent fractions:
+//
+//    0: the numerator of the scaled fraction has to be guessed by the student
+//    1: the denominator of the scaled fraction has to be guessed instead
+type equivalentFraction struct {
+	eftype             int
+	dengeq, denleq     int
+	scalegeq, scaleleq int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw
+// equivalent fractions
+type equivalentFractionTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the base fraction consists of a numerator and denominator separated by a
+	// fraction bar
+	Numerator1, Denominator1 components.CoordinatedText
+	Bar1                     components.Line
+
+	// the equal symbol is located between both fractions
+	Equal components.CoordinatedText
+
+	// the scaled fraction is drawn much in the same way than the base fraction
+	Numerator2, Denominator2 components.CoordinatedText
+	Bar2                     components.Line
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- equivalentFractionTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz equivalentFractionTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("equivalentFractionTikZ").Parse(tikZEquivalentFractionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- equivalentFraction
+
+// return the instance of a specific equivalent fraction problem that can be
+// marshalled in JSON format. The receiver is assumed to have been fully
+// verified so that it should be consistent.
+//
+// The result is given with four items: the numerator and denominator of the
+// base fraction, and the numerator and denominator of the scaled fraction.
+// Either the numerator or the denominator of the scaled fraction is shown as
+// "?" in the arguments as it has to be guessed by the student
+func (ef equivalentFraction) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+
+	// First, verify that parameters are correct. Note that the base fraction
+	// is a proper fraction and thus its denominator should be at least 2
+	if ef.dengeq < 2 || ef.dengeq > ef.denleq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate denominators in the range [%v, %v]",
+			ef.dengeq, ef.denleq)
+	}
+	if ef.scalegeq < 2 || ef.scalegeq > ef.scaleleq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate scale factors in the range [%v, %v]",
+			ef.scalegeq, ef.scaleleq)
+	}
+
+	// randomly determine the base fraction, which is always a proper fraction,
+	// and also the scale. Because the scaled fraction is computed by
+	// multiplying both the numerator and denominator by the same integer, the
+	// answer is necessarily a whole number
+	denominator := ef.dengeq + rnd.Intn(1+ef.denleq-ef.dengeq)
+	numerator := 1 + rnd.Intn(denominator-1)
+	scale := ef.scalegeq + rnd.Intn(1+ef.scaleleq-ef.scalegeq)
+
+	// create two slices: one for storing the instance of this problem in the
+	// order: numerator and denominator of the base fraction, and numerator and
+	// denominator of the scaled fraction, where the part that should be filled
+	// in by the student is marked with a question mark "?"; and another one
+	// with the full solution
+	solution := []string{
+		strconv.FormatInt(int64(numerator), 10),
+		strconv.FormatInt(int64(denominator), 10),
+		strconv.FormatInt(int64(scale*numerator), 10),
+		strconv.FormatInt(int64(scale*denominator), 10),
+	}
+	args := make([]string, 4)
+	copy(args, solution)
+
+	// and now mask the requested component of the scaled fraction
+	if ef.eftype == EFNUMERATOR {
+		args[2] = "?"
+	} else {
+		args[3] = "?"
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "EquivalentFraction",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this equivalent fraction using
+// TikZ components
+func (ef equivalentFraction) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the values of both fractions. For this,
+	//              the service that generates problems is the one that can
+	//              marshal them into JSON format. A question mark is a number
+	//              that has to be guessed by the student
+	instance, err := ef.next(annotated(ef))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid equivalent fraction: %v", err)
+	}
+
+	// compute the number of digits required to draw all numbers so that all
+	// of them are equally wide. The widest number is the scaled denominator
+	nbdigits := 0.0
+	for _, item := range instance.Solution {
+		value, _ := helpers.Atoi(item)
+		nbdigits = helpers.Max(nbdigits, float64(helpers.NbDigits(value)))
+	}
+
+	// all numbers are shown within the same width which consists of the number
+	// of digits plus one additional digit to each side
+	width := 2.0 + nbdigits
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// the following function returns the text to show in the cell of the i-th
+	// argument: either an empty box, if the number has to be guessed, or the
+	// number itself
+	cell := func(label, formula string, i int) components.CoordinatedText {
+		options, text := "", ""
+		if instance.Args[i] == "?" {
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width))
+			text = ef.answer(instance.Solution[i])
+		} else {
+			text = `\huge ` + instance.Args[i]
+		}
+		return components.NewCoordinatedText(
+			components.NewCoordinate(components.Formula(formula), label),
+			options, text)
+	}
+
+	// -- base fraction
+	den1 := cell("den1",
+		fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip)$`,
+			helpers.Ftoa(width/2.0)),
+		1)
+	num1 := cell("num1",
+		`$(den1) + (0.0, \zeroheight+\baselineskip+0.2cm)$`,
+		0)
+	bar1 := components.NewLine(
+		fmt.Sprintf(`$(den1) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.1cm)$`, helpers.Ftoa(-width/2.0)),
+		fmt.Sprintf(`$(den1) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.1cm)$`, helpers.Ftoa(width/2.0)))
+	bar1.SetOptions("thick")
+
+	// -- equal
+	equal := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(den1) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.1cm)$`,
+				helpers.Ftoa(1.0+width/2.0))),
+			"equal"),
+		"",
+		`\huge $=$`)
+
+	// -- scaled fraction
+	den2 := cell("den2",
+		fmt.Sprintf(`$(den1) + (%v\zerowidth, 0.0)$`, helpers.Ftoa(2.0+width)),
+		3)
+	num2 := cell("num2",
+		`$(den2) + (0.0, \zeroheight+\baselineskip+0.2cm)$`,
+		2)
+	bar2 := components.NewLine(
+		fmt.Sprintf(`$(den2) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.1cm)$`, helpers.Ftoa(-width/2.0)),
+		fmt.Sprintf(`$(den2) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.1cm)$`, helpers.Ftoa(width/2.0)))
+	bar2.SetOptions("thick")
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(num2) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip)$`,
+			helpers.Ftoa(0.5+width/2.0))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// equivalent fractions
+	efPicture := equivalentFractionTikZ{
+		Bottom:       bottom,
+		Numerator1:   num1,
+		Denominator1: den1,
+		Bar1:         bar1,
+		Equal:        equal,
+		Numerator2:   num2,
+		Denominator2: den2,
+		Bar2:         bar2,
+		BBox:         bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return efPicture.execute()
+}
+
+// Return TikZ code that represents an equivalent fraction
+func (ef equivalentFraction) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this equivalent fraction
+	tpl, err := template.New("equivalentFraction").Parse(latexEquivalentFractionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, ef); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
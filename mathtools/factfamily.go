@@ -0,0 +1,388 @@
+// -*- coding: utf-8 -*-
+// factfamily.go
+//
+// Description: Provides services for automatically creating fact family
+// triangles, i.e., the related additions and subtractions (or multiplications
+// and divisions) of three numbers
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 12:05:31.204817733 (1792152331)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Every fact of the family is written in one line as high as the following
+// value given in centimeters
+const FFFACTHEIGHT float64 = 0.9
+
+// the TikZ code for generating fact families is shown next. Note that it makes
+// use of LaTeX/TikZ components
+const latexFactFamilyCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the fact family
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZFactFamilyCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box and vertices of the triangle
+      {{.Bottom}}
+{{.GetVertices}}
+      % --- Triangle --------------------------------------------------------
+
+      % the triangle is drawn first so that the numbers are written on top
+      % of its vertices
+      {{.Triangle}}
+
+      % --- Numbers ---------------------------------------------------------
+
+      % the result is written in the upper vertex and the other two numbers
+      % in the lower ones. The hidden number is shown within an empty box
+{{.GetNumbers}}
+      % --- Facts -----------------------------------------------------------
+
+      % the facts of the family are written below the triangle, one per line,
+      % with empty boxes for writing their numbers
+{{.GetFacts}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A fact family consists of two numbers randomly chosen in the interval [geq,
+// leq] and the result of either adding ("+") or multiplying ("*") them. They are
+// shown in the vertices of a triangle with the result on top, and one of them
+// is hidden so that it has to be guessed by the student. Unless they are
+// hidden, the four related facts, e.g., 3 x 4 = 12, 4 x 3 = 12, 12 : 3 = 4 and
+// 12 : 4 = 3, have to be written below the triangle
+type factFamily struct {
+	operator  string
+	geq, leq  int
+	hideFacts bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw fact
+// families
+type factFamilyTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the vertices of the triangle and the closed line joining them
+	vertices []components.Coordinate
+	Triangle components.Line
+
+	// the numbers written in the vertices and the facts of the family, if any
+	numbers []components.CoordinatedText
+	facts   []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- factFamilyTikZ
+
+// Generates the TikZ code necessary for defining all vertices of the triangle
+func (tikz factFamilyTikZ) GetVertices() string {
+
+	// Use a btyes buffer to append the strings of each vertex
+	var output bytes.Buffer
+
+	for _, vertex := range tikz.vertices {
+		fmt.Fprintf(&output, "      %v\n", vertex)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for defining
+	// all vertices
+	return output.String()
+}
+
+// Generates the TikZ code necessary for writing the numbers in the vertices
+func (tikz factFamilyTikZ) GetNumbers() string {
+
+	// Use a btyes buffer to append the strings of each number
+	var output bytes.Buffer
+
+	for _, number := range tikz.numbers {
+		fmt.Fprintf(&output, "      %v\n", number)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for writing
+	// all numbers
+	return output.String()
+}
+
+// Generates the TikZ code necessary for writing all facts of the family
+func (tikz factFamilyTikZ) GetFacts() string {
+
+	// Use a btyes buffer to append the strings of each fact
+	var output bytes.Buffer
+
+	for _, fact := range tikz.facts {
+		fmt.Fprintf(&output, "      %v\n", fact)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for writing
+	// all facts
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz factFamilyTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("factFamilyTikZ").Parse(tikZFactFamilyCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- factFamily
+
+// return the instance of a specific fact family that can be marshalled in JSON
+// format. The receiver is assumed to have been fully verified so that it should
+// be consistent.
+//
+// The result is given as an array of strings:
+//  1. The first string is the operator, either "+" or "*"
+//  2. The next two strings are the numbers operated
+//  3. The last string is the result
+//
+// One of the numbers is shown as "?" in the arguments as it has to be guessed
+// by the student. The four facts of the family are given as intermediate steps
+func (ff factFamily) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if ff.geq > ff.leq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate numbers in the range [%v, %v]",
+			ff.geq, ff.leq)
+	}
+
+	// randomly determine both numbers and compute their result
+	number1 := randRange(rnd, ff.geq, ff.leq)
+	number2 := randRange(rnd, ff.geq, ff.leq)
+	result := number1 + number2
+	inverse := "-"
+	if ff.operator == "*" {
+		result, inverse = number1*number2, "/"
+	}
+
+	solution := []string{
+		ff.operator,
+		strconv.Itoa(number1),
+		strconv.Itoa(number2),
+		strconv.Itoa(result),
+	}
+	args := make([]string, len(solution))
+	copy(args, solution)
+
+	// and now mask one of the numbers at random
+	args[1+rnd.Intn(3)] = "?"
+
+	return ProblemJSON{
+		Probtype: "FactFamily",
+		Args:     args,
+		Solution: solution,
+		Steps: []string{
+			solutionStep(number1, ff.operator, number2, result),
+			solutionStep(number2, ff.operator, number1, result),
+			solutionStep(result, inverse, number1, number2),
+			solutionStep(result, inverse, number2, number1),
+		},
+	}, nil
+}
+
+// set the difficulty and tags of the given problem generated by this fact
+// family. Multiplications and divisions are harder than additions and
+// subtractions, and also guessing the result is easier than any other number
+func (ff factFamily) annotate(problem *ProblemJSON) {
+
+	score := 1 + len(problem.Solution[3])/2
+	problem.Tags = []string{"addition", "subtraction"}
+	if ff.operator == "*" {
+		score++
+		problem.Tags = []string{"multiplication", "division"}
+	}
+	if problem.Args[3] != "?" {
+		score++
+		problem.Tags = append(problem.Tags, "missing-operand")
+	}
+	problem.Difficulty = difficulty(score)
+}
+
+// return a valid LaTeX/TikZ representation of this fact family using TikZ
+// components
+func (ff factFamily) GetTikZPicture() (string, error) {
+
+	// -- numbers: randomly determine the numbers of the family. For this, the
+	//             service that generates problems is the one that can marshal
+	//             them into JSON format. A question mark is a number that has
+	//             to be guessed by the student
+	instance, err := ff.next(annotated(ff))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid fact family: %v", err)
+	}
+
+	// all numbers are shown within the same width which consists of the number
+	// of digits of the largest number plus one additional digit to each side
+	nbdigits := 0.0
+	for _, item := range instance.Solution[1:] {
+		nbdigits = helpers.Max(nbdigits, float64(len(item)))
+	}
+	width := 2.0 + nbdigits
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box. The triangle is
+	// drawn above the facts, if any
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	base := 0.6
+	if !ff.hideFacts {
+		base += 4 * FFFACTHEIGHT
+	}
+
+	// the result is written in the upper vertex, and the numbers operated in
+	// the lower-left and lower-right vertices respectively
+	vertices := []components.Coordinate{
+		components.NewCoordinate(components.Point{X: 1.0, Y: base}, "vertex1"),
+		components.NewCoordinate(components.Point{X: 5.0, Y: base}, "vertex2"),
+		components.NewCoordinate(components.Point{X: 3.0, Y: base + 3.0}, "vertex3"),
+	}
+	triangle := components.NewLine("vertex1", "vertex2", "vertex3", "vertex1")
+	triangle.SetOptions("thick")
+
+	// -- numbers: they are written on a white background so that the sides
+	//             of the triangle are not drawn over them
+	var numbers []components.CoordinatedText
+	for i := 1; i <= 3; i++ {
+		position := components.Formula(fmt.Sprintf("vertex%v", i))
+		options := fmt.Sprintf(`circle, fill=white, draw, minimum size=%v\zerowidth`, helpers.Ftoa(width))
+		text := `\huge ` + instance.Args[i]
+		if instance.Args[i] == "?" {
+			options = fmt.Sprintf(`rounded corners, rectangle, fill=white, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width))
+			text = ff.answer(instance.Solution[i])
+		}
+		numbers = append(numbers, components.NewCoordinatedText(
+			components.NewCoordinate(position, fmt.Sprintf("number%v", i)), options, text))
+	}
+
+	// -- facts: every fact consists of three empty boxes separated by the
+	//           operator and the equal sign. The numbers are only shown in
+	//           the boxes when solutions are requested
+	var facts []components.CoordinatedText
+	if !ff.hideFacts {
+		for idx, step := range instance.Steps {
+			var number1, operator, number2, result string
+			fmt.Sscanf(step, "%s %s %s = %s", &number1, &operator, &number2, &result)
+			box := func(number string) string {
+				if !ff.showAnswers() {
+					number = ""
+				}
+				return fmt.Sprintf(`\fbox{\makebox[%vem]{\strut %v}}`, helpers.Ftoa(0.6*(1.0+nbdigits)), number)
+			}
+			facts = append(facts, components.NewCoordinatedText(
+				components.NewCoordinate(components.Point{
+					X: 0.5,
+					Y: 0.4 + FFFACTHEIGHT*float64(3-idx),
+				}, fmt.Sprintf("fact%v", idx)),
+				"anchor=west",
+				fmt.Sprintf(`\Large %v %v %v $=$ %v`,
+					box(number1), operatorLaTeX(operator), box(number2), box(result))))
+		}
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(components.Point{
+		X: 6.0,
+		Y: base + 3.8,
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the fact
+	// family
+	ffPicture := factFamilyTikZ{
+		Bottom:   bottom,
+		vertices: vertices,
+		Triangle: triangle,
+		numbers:  numbers,
+		facts:    facts,
+		BBox:     bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return ffPicture.execute()
+}
+
+// Return TikZ code that represents a fact family
+func (ff factFamily) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this fact family
+	tpl, err := template.New("factFamily").Parse(latexFactFamilyCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, ff); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,400 @@
+// -*- coding: utf-8 -*-
+// fdp.go
+//
+// Description: Provides services for automatically creating problems where
+// fractions, decimals and percentages have to be converted into each other
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:29:33.537708044 (1792110573)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// the TikZ code for generating conversions between fractions, decimals and
+// percentages is shown next. Note that it makes use of LaTeX/TikZ components
+const latexFDPConversionCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the conversion
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZFDPConversionCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Source ----------------------------------------------------------
+
+      % the value to convert is shown to the left of the equal sign
+      {{.Source}}
+      {{.Equal}}
+
+      % --- Target ----------------------------------------------------------
+
+      % the converted value is shown within empty boxes. Fractions are shown
+      % with two boxes separated by a fraction bar, and percentages are
+      % followed by the percent sign
+{{.GetTarget}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A conversion between fractions, decimals and percentages consists of the form
+// the value is given in ("from") and the form it has to be converted to ("to"),
+// each one among "fraction", "decimal" and "percent". Values are generated
+// from proper fractions whose denominator is not larger than denleq and which
+// can be written as terminating decimals
+type fdpConversion struct {
+	from, to string
+	denleq   int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw
+// conversions between fractions, decimals and percentages
+type fdpConversionTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the value to convert is followed by an equal sign
+	Source, Equal components.CoordinatedText
+
+	// the converted value consists of a number of text boxes and, in case it
+	// is a fraction, a fraction bar
+	target []components.CoordinatedText
+	bar    []components.Line
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the decimal representation of the given rational number with as many
+// decimal digits as necessary. If the rational number can not be written as a
+// terminating decimal, an error is returned
+func terminatingDecimal(r *big.Rat) (string, error) {
+
+	// a fraction in lowest terms is a terminating decimal if and only if the
+	// prime factors of its denominator are 2 and 5 only. The number of decimal
+	// digits is then the largest exponent of both factors
+	denom := new(big.Int).Set(r.Denom())
+	two, five, zero := big.NewInt(2), big.NewInt(5), big.NewInt(0)
+	mod := new(big.Int)
+	nbtwos, nbfives := 0, 0
+	for mod.Mod(denom, two).Cmp(zero) == 0 {
+		denom.Div(denom, two)
+		nbtwos += 1
+	}
+	for mod.Mod(denom, five).Cmp(zero) == 0 {
+		denom.Div(denom, five)
+		nbfives += 1
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("the fraction %v can not be written as a terminating decimal", r.RatString())
+	}
+
+	// and now write the rational number with precisely the number of decimal
+	// digits required, which is exact
+	nbdecimals := nbtwos
+	if nbfives > nbtwos {
+		nbdecimals = nbfives
+	}
+	return r.FloatString(nbdecimals), nil
+}
+
+// return the representation of the given rational number in the specified
+// form, either "fraction", "decimal" or "percent"
+func fdpString(r *big.Rat, form string) (string, error) {
+
+	switch form {
+	case "fraction":
+		return r.Num().String() + "/" + r.Denom().String(), nil
+	case "decimal":
+		return terminatingDecimal(r)
+	case "percent":
+		percent, err := terminatingDecimal(new(big.Rat).Mul(r, big.NewRat(100, 1)))
+		if err != nil {
+			return "", err
+		}
+		return percent + "%", nil
+	default:
+		return "", fmt.Errorf("unknown form '%v'", form)
+	}
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- fdpConversionTikZ
+
+// Generates the TikZ code necessary for drawing the converted value
+func (tikz fdpConversionTikZ) GetTarget() string {
+
+	// Use a btyes buffer to append the strings of each component
+	var output bytes.Buffer
+
+	// Draw first all text boxes and then the fraction bar, if any
+	for _, text := range tikz.target {
+		fmt.Fprintf(&output, "      %v\n", text)
+	}
+	for _, line := range tikz.bar {
+		fmt.Fprintf(&output, "      %v\n", line)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// converted value
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz fdpConversionTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("fdpConversionTikZ").Parse(tikZFDPConversionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- fdpConversion
+
+// return the instance of a specific conversion problem that can be marshalled
+// in JSON format. The receiver is assumed to have been fully verified so that
+// it should be consistent.
+//
+// The result is given with two items: the value to convert in the source form
+// and the converted value in the target form, which is shown as "?" in the
+// arguments as it has to be guessed by the student. Fractions are written as
+// "n/d" and percentages are followed by the percent sign
+func (fdp fdpConversion) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+
+	// First, verify that parameters are correct
+	if fdp.from == fdp.to {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to convert a %v into a %v", fdp.from, fdp.to)
+	}
+
+	// compute all denominators which can be used for generating terminating
+	// decimals, i.e., those whose only prime factors are 2 and 5
+	var denominators []int
+	for den := 2; den <= fdp.denleq; den++ {
+		value := den
+		for value%2 == 0 {
+			value /= 2
+		}
+		for value%5 == 0 {
+			value /= 5
+		}
+		if value == 1 {
+			denominators = append(denominators, den)
+		}
+	}
+	if len(denominators) == 0 {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate terminating decimals with denominators less or equal than %v",
+			fdp.denleq)
+	}
+
+	// randomly determine a proper fraction. Rational numbers are automatically
+	// reduced to lowest terms
+	den := denominators[rnd.Intn(len(denominators))]
+	num := 1 + rnd.Intn(den-1)
+	value := big.NewRat(int64(num), int64(den))
+
+	// and write it both in the source and target form
+	source, err := fdpString(value, fdp.from)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	target, err := fdpString(value, fdp.to)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "FDPConversion",
+		Args:     []string{source, "?"},
+		Solution: []string{source, target}}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this conversion using TikZ
+// components
+func (fdp fdpConversion) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the value to convert. For this, the
+	//              service that generates problems is the one that can marshal
+	//              them into JSON format
+	instance, err := fdp.next(annotated(fdp))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid conversion: %v", err)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// all items are placed with respect to the bottom coordinate, at the given
+	// horizontal distance (in digits) and with a vertical offset given with
+	// respect to the middle line of the picture, where fraction bars are drawn
+	at := func(label string, x float64, offset string) components.Coordinate {
+		return components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight+\baselineskip+0.1cm%v)$`,
+				helpers.Ftoa(x), offset)),
+			label)
+	}
+
+	// -- source: fractions are shown with \frac, and the width of the source
+	//            is computed as the number of characters to show
+	source := instance.Args[0]
+	text, width := `\huge `+strings.Replace(localizeDecimal(source), "%", `\%`, 1), float64(len(source))
+	if fdp.from == "fraction" {
+		terms := strings.Split(source, "/")
+		text = fmt.Sprintf(`\huge $\frac{%v}{%v}$`, terms[0], terms[1])
+		width = 1.0 + helpers.Max(float64(len(terms[0])), float64(len(terms[1])))
+	}
+	x := 0.5 + width/2.0
+	sourceText := components.NewCoordinatedText(at("source", x, ""), "", text)
+
+	// -- equal
+	x += 1.0 + width/2.0
+	equal := components.NewCoordinatedText(at("equal", x, ""), "", `\huge $=$`)
+
+	// -- target: the width of the boxes is computed from the solution so that
+	//            it fits comfortably
+	box := func(label string, x, width float64, offset, solution string) components.CoordinatedText {
+		return components.NewCoordinatedText(at(label, x, offset),
+			fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width)),
+			fdp.answer(localizeDecimal(solution)))
+	}
+	solution := strings.TrimSuffix(instance.Solution[1], "%")
+	var target []components.CoordinatedText
+	var bar []components.Line
+	switch fdp.to {
+	case "fraction":
+
+		// fractions are drawn with two boxes separated by a fraction bar
+		terms := strings.Split(solution, "/")
+		width = 2.0 + helpers.Max(float64(len(terms[0])), float64(len(terms[1])))
+		x += 1.0 + width/2.0
+		target = append(target,
+			box("numerator", x, width, "+0.5\\zeroheight+0.5\\baselineskip+0.1cm", terms[0]),
+			box("denominator", x, width, "-0.5\\zeroheight-0.5\\baselineskip-0.1cm", terms[1]))
+		line := components.NewLine(
+			fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight+\baselineskip+0.1cm)$`, helpers.Ftoa(x-width/2.0)),
+			fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight+\baselineskip+0.1cm)$`, helpers.Ftoa(x+width/2.0)))
+		line.SetOptions("thick")
+		bar = append(bar, line)
+	case "decimal":
+		width = 2.0 + float64(len(solution))
+		x += 1.0 + width/2.0
+		target = append(target, box("target", x, width, "", solution))
+	case "percent":
+
+		// percentages are followed by the percent sign
+		width = 2.0 + float64(len(solution))
+		x += 1.0 + width/2.0
+		target = append(target, box("target", x, width, "", solution))
+		x += 1.0 + width/2.0
+		target = append(target,
+			components.NewCoordinatedText(at("percent", x, ""), "", `\huge \%`))
+		width = 1.0
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 2\zeroheight+2\baselineskip+0.2cm)$`,
+			helpers.Ftoa(x+0.5+width/2.0))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// conversion
+	fdpPicture := fdpConversionTikZ{
+		Bottom: bottom,
+		Source: sourceText,
+		Equal:  equal,
+		target: target,
+		bar:    bar,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return fdpPicture.execute()
+}
+
+// Return TikZ code that represents a conversion between fractions, decimals
+// and percentages
+func (fdp fdpConversion) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this conversion
+	tpl, err := template.New("fdpConversion").Parse(latexFDPConversionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, fdp); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
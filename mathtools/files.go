@@ -0,0 +1,62 @@
+// -*- coding: utf-8 -*-
+// files.go
+//
+// Description: Provides access to the files read while generating problems
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 06:48:12.337019542 (1792132092)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// Files read while generating problems, e.g., banks of stories, are accessed
+// through the following file system. If none is given, they are read from the
+// operating system
+var fileSystem fs.FS
+var fileSystemMutex sync.RWMutex
+
+// functions
+// ----------------------------------------------------------------------------
+
+// Set the file system used for reading the files needed while generating
+// problems. This allows generating problems where there is no operating system
+// (e.g., in browsers) by providing all files in memory. If nil is given, files
+// are read again from the operating system. Banks of stories read from the
+// previous file system are forgotten
+func SetFileSystem(fsys fs.FS) {
+
+	fileSystemMutex.Lock()
+	fileSystem = fsys
+	fileSystemMutex.Unlock()
+
+	storyBankCacheMutex.Lock()
+	storyBankCache = make(map[string][]story)
+	storyBankCacheMutex.Unlock()
+}
+
+// return the contents of the given file read from the current file system
+func readFile(name string) ([]byte, error) {
+
+	fileSystemMutex.RLock()
+	defer fileSystemMutex.RUnlock()
+
+	if fileSystem == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fileSystem, name)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,208 @@
+// -*- coding: utf-8 -*-
+// gridpaper.go
+//
+// Description: Provides services for automatically creating blank sheets of
+// squared or lined paper
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:27:02.381529664 (1792110422)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// the TikZ code for generating grid paper is shown next. Note that it makes use
+// of LaTeX/TikZ components
+const latexGridPaperCode = `\begin{minipage}{\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the grid paper
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZGridPaperCode = `% --- Bounding Box ----------------------------------------------------
+
+      % the bounding box surrounds the grid leaving the requested margin to
+      % each side
+      {{.BBox}}
+
+      % --- Grid ------------------------------------------------------------
+
+      % horizontal lines are always drawn, whereas vertical lines are drawn
+      % only for squared paper
+{{.GetLines}}
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// Grid paper consists of a number of rows and columns of cells whose side
+// (given in centimeters) is equal to step. Grid paper can be either "square",
+// so that both horizontal and vertical lines are drawn, or "lined" so that only
+// the horizontal lines are shown. Optionally, a margin (also in centimeters)
+// can be left around the grid
+type gridPaper struct {
+	step       float64
+	cols, rows int
+	style      string
+	margin     float64
+}
+
+// The following struct stores all the information necessary to draw grid paper
+type gridPaperTikZ struct {
+
+	// the bounding box surrounds the grid, including the margins
+	BBox components.CoordinatedRectangle
+
+	// all lines of the grid are stored in a slice
+	lines []components.Line
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- gridPaperTikZ
+
+// Generates the TikZ code necessary for drawing all lines of the grid
+func (tikz gridPaperTikZ) GetLines() string {
+
+	// Use a btyes buffer to append the strings of each line
+	var output bytes.Buffer
+
+	// Draw all lines stored in this picture
+	for _, line := range tikz.lines {
+		fmt.Fprintf(&output, "%v\n", line)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// lines
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz gridPaperTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("gridPaperTikZ").Parse(tikZGridPaperCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- gridPaper
+
+// grid paper has no problems to solve and thus no instances can be generated in
+// JSON format
+func (gp gridPaper) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+	return ProblemJSON{}, errors.New("Unsupported generation of JSON problems for problem type 'GridPaper'")
+}
+
+// return a valid LaTeX/TikZ representation of this grid paper using TikZ
+// components. The lower-left corner of the grid is located at (0, 0)
+func (gp gridPaper) GetTikZPicture() (string, error) {
+
+	// compute the width and height of the grid
+	width := gp.step * float64(gp.cols)
+	height := gp.step * float64(gp.rows)
+
+	// -- bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: -gp.margin,
+		Y: -gp.margin,
+	}, "bottom")
+	right := components.NewCoordinate(components.Point{
+		X: width + gp.margin,
+		Y: height + gp.margin,
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// -- lines
+
+	// first, draw all horizontal lines, one per each row plus the last one
+	var lines []components.Line
+	for i := 0; i <= gp.rows; i++ {
+		line := components.NewLine(
+			fmt.Sprintf("0, %v", helpers.Ftoa(float64(i)*gp.step)),
+			fmt.Sprintf("%v, %v", helpers.Ftoa(width), helpers.Ftoa(float64(i)*gp.step)))
+		line.SetOptions("thin, gray")
+		lines = append(lines, line)
+	}
+
+	// next, in case this is squared paper, draw also the vertical lines
+	if gp.style == "square" {
+		for j := 0; j <= gp.cols; j++ {
+			line := components.NewLine(
+				fmt.Sprintf("%v, 0", helpers.Ftoa(float64(j)*gp.step)),
+				fmt.Sprintf("%v, %v", helpers.Ftoa(float64(j)*gp.step), helpers.Ftoa(height)))
+			line.SetOptions("thin, gray")
+			lines = append(lines, line)
+		}
+	}
+
+	// And put all these elements together to show up the picture of the grid
+	// paper
+	gpPicture := gridPaperTikZ{
+		BBox:  bBox,
+		lines: lines,
+	}
+
+	// and return the TikZ code necessary for drawing the grid paper
+	return gpPicture.execute()
+}
+
+// Return TikZ code that represents grid paper
+func (gp gridPaper) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this grid paper
+	tpl, err := template.New("gridPaper").Parse(latexGridPaperCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, gp); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
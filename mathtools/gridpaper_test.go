@@ -0,0 +1,143 @@
+// -*- coding: utf-8 -*-
+// gridpaper_test.go
+//
+// Description: Tests of the generation of blank sheets of squared or lined
+// paper
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 18:02:37.116408223 (1792173757)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// Lines of the grid and coordinates of the bounding box drawn in grid paper
+var (
+	gridPaperLine   = regexp.MustCompile(`\\draw \[thin, gray\] \((\S+), (\S+)\) -- \((\S+), (\S+)\);`)
+	gridPaperCorner = regexp.MustCompile(`\\coordinate \((bottom|right)\) at \((\S+), (\S+)\);`)
+)
+
+// return the floating-point numbers given in the specified strings
+func parseFloats(tb testing.TB, values ...string) []float64 {
+
+	tb.Helper()
+	result := make([]float64, len(values))
+	for idx, value := range values {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			tb.Fatalf("the coordinate '%v' is not a number: %v", value, err)
+		}
+		result[idx] = number
+	}
+	return result
+}
+
+// return true if both numbers are the same up to the precision used to format
+// them
+func sameFloat(x, y float64) bool {
+	return math.Abs(x-y) < 1e-6
+}
+
+// grid paper consists of rows+1 horizontal lines and, if it is squared, cols+1
+// vertical lines, all separated by step and spanning the whole width and
+// height of the grid, surrounded by the margin
+func TestGridPaperDimensions(t *testing.T) {
+
+	tests := []struct {
+		step       float64
+		cols, rows int
+		style      string
+		margin     float64
+	}{
+		{0.5, 4, 3, "square", 0},
+		{0.5, 4, 3, "lined", 0},
+		{1, 1, 1, "square", 0.25},
+		{0.25, 10, 6, "square", 1},
+		{0.8, 5, 12, "lined", 0.5},
+	}
+	for _, test := range tests {
+		width, height := test.step*float64(test.cols), test.step*float64(test.rows)
+		output, err := MasterFile{}.GridPaper(map[string]interface{}{
+			"step":   test.step,
+			"cols":   test.cols,
+			"rows":   test.rows,
+			"style":  test.style,
+			"margin": test.margin,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// split the lines into horizontal and vertical ones, checking their
+		// extents and remembering their position
+		var ys, xs []float64
+		for _, match := range gridPaperLine.FindAllStringSubmatch(output, -1) {
+			coords := parseFloats(t, match[1:]...)
+			x0, y0, x1, y1 := coords[0], coords[1], coords[2], coords[3]
+			switch {
+			case sameFloat(y0, y1):
+				if !sameFloat(x0, 0) || !sameFloat(x1, width) {
+					t.Errorf("%v: expected a horizontal line from 0 to %v but got one from %v to %v", test, width, x0, x1)
+				}
+				ys = append(ys, y0)
+			case sameFloat(x0, x1):
+				if !sameFloat(y0, 0) || !sameFloat(y1, height) {
+					t.Errorf("%v: expected a vertical line from 0 to %v but got one from %v to %v", test, height, y0, y1)
+				}
+				xs = append(xs, x0)
+			default:
+				t.Errorf("%v: the line %v is neither horizontal nor vertical", test, match[0])
+			}
+		}
+
+		// verify the number of lines and their spacing
+		nbvertical := test.cols + 1
+		if test.style == "lined" {
+			nbvertical = 0
+		}
+		if len(ys) != test.rows+1 || len(xs) != nbvertical {
+			t.Errorf("%v: expected %v horizontal and %v vertical lines but got %v and %v",
+				test, test.rows+1, nbvertical, len(ys), len(xs))
+			continue
+		}
+		for idx, y := range ys {
+			if !sameFloat(y, float64(idx)*test.step) {
+				t.Errorf("%v: expected the horizontal line #%v at %v but got it at %v", test, idx, float64(idx)*test.step, y)
+			}
+		}
+		for idx, x := range xs {
+			if !sameFloat(x, float64(idx)*test.step) {
+				t.Errorf("%v: expected the vertical line #%v at %v but got it at %v", test, idx, float64(idx)*test.step, x)
+			}
+		}
+
+		// and finally the bounding box, which includes the margin
+		expected := map[string][]float64{
+			"bottom": {-test.margin, -test.margin},
+			"right":  {width + test.margin, height + test.margin},
+		}
+		corners := gridPaperCorner.FindAllStringSubmatch(output, -1)
+		if len(corners) != 2 {
+			t.Errorf("%v: expected the two corners of the bounding box but got %v", test, corners)
+			continue
+		}
+		for _, corner := range corners {
+			coords := parseFloats(t, corner[2:]...)
+			if !sameFloat(coords[0], expected[corner[1]][0]) || !sameFloat(coords[1], expected[corner[1]][1]) {
+				t.Errorf("%v: expected the corner '%v' at %v but got it at %v", test, corner[1], expected[corner[1]], coords)
+			}
+		}
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
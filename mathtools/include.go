@@ -0,0 +1,85 @@
+// -*- coding: utf-8 -*-
+// include.go
+//
+// Description: Provides services for sharing template fragments (partials)
+// among many master files
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 04:12:40.118230561 (1792123960)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"text/template"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Maximum number of nested inclusions of partials with Include. It prevents
+// partials that include themselves from running forever
+const MAXINCLUDEDEPTH int = 16
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// Partials are referred to in master files with the action {{template "name"
+// ...}}, where the name is the path of the file with their contents
+var partialRegexp = regexp.MustCompile(`{{-?\s*template\s+"([^"]+)"`)
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given path of a partial resolved with respect to the given
+// directory, unless it is absolute
+func resolvePartial(path, dir string) string {
+
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// parse all partials referred to in the given contents with the action
+// {{template "name" ...}} that are not yet defined in the given template, and
+// associate them to it with the same name. Partials are read from the file
+// given in their name, resolved relative to the given directory, and they can
+// refer to other partials in turn. If any partial can not be read or parsed,
+// an error is returned
+func parsePartials(t *template.Template, contents, dir string) error {
+
+	for _, match := range partialRegexp.FindAllStringSubmatch(contents, -1) {
+
+		// skip those templates already defined, either in the master file
+		// with {{define}} or because they were already parsed
+		name := match[1]
+		if t.Lookup(name) != nil {
+			continue
+		}
+
+		partial, err := ioutil.ReadFile(resolvePartial(name, dir))
+		if err != nil {
+			return fmt.Errorf("It was not possible to read the partial '%v'", name)
+		}
+		if _, err := t.New(name).Parse(string(partial)); err != nil {
+			return err
+		}
+
+		// and parse also the partials this one refers to
+		if err := parsePartials(t, string(partial), dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,153 @@
+// -*- coding: utf-8 -*-
+// latex.go
+//
+// Description: Provides services for compiling the TeX files generated from
+// master files into PDF files
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:58:41.203318422 (1792112321)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/clinaresl/mathprob/fstools"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// LaTeX engine used by default for compiling TeX files into PDF
+const DEFAULTLATEXENGINE string = "pdflatex"
+
+// Number of times TeX files are compiled by default. TikZ pictures are drawn
+// correctly with only one pass
+const DEFAULTLATEXPASSES int = 1
+
+// Maximum number of errors reported after a failed compilation
+const MAXLATEXERRORS int = 5
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given text with all LaTeX special characters escaped so that it
+// is typeset verbatim
+func latexEscape(text string) string {
+
+	return strings.NewReplacer(
+		`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`,
+		`%`, `\%`, `$`, `\$`, `&`, `\&`, `#`, `\#`, `_`, `\_`,
+		`~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`).Replace(text)
+}
+
+// return a summary of the errors reported by LaTeX in the given log. Every
+// error starts with a line beginning with "!" and it is followed by the line
+// where it happened, which starts with "l." Only the first MAXLATEXERRORS
+// errors are reported. If no error is found, an empty string is returned
+func latexErrors(output []byte) string {
+
+	var messages []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() && len(messages) < MAXLATEXERRORS {
+
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		// look for the line where this error happened, which is shown shortly
+		// after the error message
+		for scanner.Scan() {
+			if next := scanner.Text(); strings.HasPrefix(next, "l.") {
+				line = fmt.Sprintf("%v (%v)", line, strings.TrimSpace(next))
+				break
+			}
+		}
+		messages = append(messages, line)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// compile the given TeX file with the given LaTeX engine as many times as
+// requested. The PDF file is written in the same directory of the TeX file. If
+// the compilation fails, an error is returned with the errors reported by
+// LaTeX, if any. The compilation is aborted if the given context is done
+// before it finishes
+func compileTeX(ctx context.Context, texfile, engine string, passes int) error {
+
+	// make sure the LaTeX engine is available
+	if _, err := exec.LookPath(engine); err != nil {
+		return fmt.Errorf("the LaTeX engine '%v' could not be found: %v", engine, err)
+	}
+	if passes < 1 {
+		return fmt.Errorf("the number of passes of the LaTeX engine should be strictly positive")
+	}
+
+	// LaTeX is executed in the directory of the TeX file so that all the
+	// auxiliary files are written there as well
+	for pass := 0; pass < passes; pass++ {
+
+		cmd := exec.CommandContext(ctx, engine,
+			"-interaction=nonstopmode", "-halt-on-error", filepath.Base(texfile))
+		cmd.Dir = filepath.Dir(texfile)
+		if output, err := cmd.CombinedOutput(); err != nil {
+
+			// in case the context was done, report it instead
+			if ctx.Err() != nil {
+				return fmt.Errorf("the compilation of '%v' was aborted: %v", texfile, ctx.Err())
+			}
+			if summary := latexErrors(output); summary != "" {
+				return fmt.Errorf("the compilation of '%v' with %v failed: %v", texfile, engine, summary)
+			}
+			return fmt.Errorf("the compilation of '%v' with %v failed: %v", texfile, engine, err)
+		}
+	}
+
+	// at this point, the PDF file was successfully generated
+	return nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- MasterFile
+
+// Compile the TeX file written with this master file (see GetOutfile) into a
+// PDF file in the same directory. If an answer key was requested, then it is
+// compiled as well. The LaTeX engine and the number of passes are taken from
+// the master file; if none are given, pdflatex is executed only once. If the
+// compilation fails, an error is returned with the errors reported by LaTeX
+func (masterFile MasterFile) CompilePDF(ctx context.Context) error {
+
+	engine := masterFile.LatexEngine
+	if engine == "" {
+		engine = DEFAULTLATEXENGINE
+	}
+	passes := masterFile.LatexPasses
+	if passes == 0 {
+		passes = DEFAULTLATEXPASSES
+	}
+
+	texfile := fstools.AddSuffix(masterFile.GetOutfile(), ".tex")
+	if err := compileTeX(ctx, texfile, engine, passes); err != nil {
+		return err
+	}
+	if masterFile.Answers {
+		return compileTeX(ctx, strings.TrimSuffix(texfile, ".tex")+".answers.tex", engine, passes)
+	}
+	return nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
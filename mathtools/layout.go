@@ -0,0 +1,87 @@
+// -*- coding: utf-8 -*-
+// layout.go
+//
+// Description: Provides services for laying out many problems of the same type
+// in a grid of rows and columns
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:02:02.042751550 (1792112522)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Vertical space left by default between consecutive rows of a grid
+const DEFAULTGRIDVSPACE string = `\baselineskip`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A grid lays out rows x cols problems of the same type defined with the same
+// arguments. All columns take the same fraction of the line width and rows are
+// separated by a vertical space given as a LaTeX length. Optionally, a page
+// break can be forced after every pagerows rows. If pagerows is zero, then
+// pages are broken by LaTeX between any pair of rows
+type gridLayout struct {
+	rows, cols int
+	problem    string
+	args       map[string]interface{}
+	vspace     string
+	pagerows   int
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- gridLayout
+
+// return the LaTeX code of this grid where every cell is filled in with the
+// code returned by the given function. Every problem is centered in a box as
+// wide as its column, so that the width of its own minipage is preserved
+func (grid gridLayout) execute(cell func() (string, error)) (string, error) {
+
+	// compute the width of every column as a fraction of the line width
+	width := helpers.Ftoa(1.0 / float64(grid.cols))
+
+	var output bytes.Buffer
+	for row := 0; row < grid.rows; row++ {
+
+		// rows start at the left margin and cells are written one after the
+		// other without any space between them
+		output.WriteString("\\noindent%\n")
+		for col := 0; col < grid.cols; col++ {
+			code, err := cell()
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&output, "\\makebox[%v\\linewidth]{%%\n%v}%%\n", width, strings.TrimSpace(code))
+		}
+
+		// after every row, either leave the vertical space between rows or
+		// force a page break
+		if row < grid.rows-1 {
+			if grid.pagerows > 0 && (row+1)%grid.pagerows == 0 {
+				output.WriteString("\n\\newpage\n\n")
+			} else {
+				fmt.Fprintf(&output, "\n\\vspace{%v}\n\n", grid.vspace)
+			}
+		}
+	}
+	return output.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,173 @@
+// -*- coding: utf-8 -*-
+// levels.go
+//
+// Description: Provides difficulty levels of basic operations so that they can
+// be defined with a single number instead of all their digit counts
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:00:15.863644838 (1792112415)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A level sets the operator, the number of operands, the number of digits of
+// the operands and the result of basic operations, and whether carries (in
+// additions) or borrows (in subtractions) are allowed or not. Grade is a short
+// description of the school year the level is intended for
+type Level struct {
+	Grade        string `json:"grade"`
+	Operator     string `json:"operator"`
+	NbOperands   int    `json:"nboperands"`
+	NbDigitsOp   int    `json:"nbdigitsop"`
+	NbDigitsRslt int    `json:"nbdigitsrslt"`
+	Carry        bool   `json:"carry"`
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The following levels are provided by default. They are aligned to the grades
+// of primary school, so that every grade adds a new difficulty to the previous
+// one
+var levels = map[int]Level{
+	1: {Grade: "1st grade", Operator: "+", NbOperands: 2, NbDigitsOp: 1, NbDigitsRslt: 1, Carry: false},
+	2: {Grade: "1st grade", Operator: "-", NbOperands: 2, NbDigitsOp: 1, NbDigitsRslt: 1, Carry: false},
+	3: {Grade: "2nd grade", Operator: "+", NbOperands: 2, NbDigitsOp: 2, NbDigitsRslt: 2, Carry: true},
+	4: {Grade: "3rd grade", Operator: "-", NbOperands: 2, NbDigitsOp: 3, NbDigitsRslt: 3, Carry: true},
+	5: {Grade: "4th grade", Operator: "*", NbOperands: 2, NbDigitsOp: 2, NbDigitsRslt: 4, Carry: true},
+	6: {Grade: "6th grade", Operator: "+", NbOperands: 3, NbDigitsOp: 4, NbDigitsRslt: 5, Carry: true},
+}
+var levelsMutex sync.RWMutex
+
+// functions
+// ----------------------------------------------------------------------------
+
+// set the given level, either adding a new one or overriding an existing
+// one. An error is returned if the level does not define a correct basic
+// operation
+func SetLevel(number int, level Level) error {
+
+	if err := level.options(BORESULT).Validate(); err != nil {
+		return fmt.Errorf("the level %v is incorrect: %v", number, err)
+	}
+
+	levelsMutex.Lock()
+	defer levelsMutex.Unlock()
+	levels[number] = level
+	return nil
+}
+
+// read the levels defined in the given JSON file, which consists of a
+// dictionary indexed by the number of every level, and set all of them. If an
+// error is found, then none of the levels in the file is set
+func LoadLevels(filename string) error {
+
+	data, err := readFile(filename)
+	if err != nil {
+		return err
+	}
+	var table map[string]Level
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("it was not possible to read the levels in '%v': %v", filename, err)
+	}
+
+	// verify first all levels before setting any
+	numbers := make(map[int]Level)
+	for key, level := range table {
+		number, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("the level '%v' in '%v' should be given as an integer", key, filename)
+		}
+		if err := level.options(BORESULT).Validate(); err != nil {
+			return fmt.Errorf("the level %v in '%v' is incorrect: %v", number, filename, err)
+		}
+		numbers[number] = level
+	}
+	for number, level := range numbers {
+		if err := SetLevel(number, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// return the level with the given number and whether it exists or not
+func GetLevel(number int) (Level, bool) {
+
+	levelsMutex.RLock()
+	defer levelsMutex.RUnlock()
+
+	level, ok := levels[number]
+	return level, ok
+}
+
+// return the numbers of all levels currently defined in increasing order
+func Levels() []int {
+
+	levelsMutex.RLock()
+	defer levelsMutex.RUnlock()
+
+	var numbers []int
+	for number := range levels {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- Level
+
+// return the options of a basic operation of the given type defined with this
+// level
+func (level Level) options(botype int) BasicOperationOptions {
+	return BasicOperationOptions{
+		Type:         botype,
+		Operator:     level.Operator,
+		NbOperands:   level.NbOperands,
+		NbDigitsOp:   level.NbDigitsOp,
+		NbDigitsRslt: level.NbDigitsRslt,
+		Carry:        level.carry(),
+	}
+}
+
+// return the carries allowed in the basic operations defined with this level:
+// either in any column or in none at all
+func (level Level) carry() string {
+	if level.Carry {
+		return BOCARRYANY
+	}
+	return BOCARRYFORBIDDEN
+}
+
+// return the keys of the dictionary of a basic operation defined with this
+// level
+func (level Level) dict() map[string]interface{} {
+	return map[string]interface{}{
+		"type":         BORESULT,
+		"operator":     level.Operator,
+		"nboperands":   level.NbOperands,
+		"nbdigitsop":   level.NbDigitsOp,
+		"nbdigitsrslt": level.NbDigitsRslt,
+		"carry":        level.carry(),
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,397 @@
+// -*- coding: utf-8 -*-
+// linearequation.go
+//
+// Description: Provides services for automatically creating simple linear
+// equations with one unknown
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:27:43.298844814 (1792114063)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of linear equations: those solved in one step
+// (x + a = b, x - a = b, a * x = b or x / a = b) and those solved in two steps
+// (a * x + b = c or a * x - b = c)
+const (
+	LEONESTEP int = iota
+	LETWOSTEP
+)
+
+// By default, coefficients are not larger than the following value
+const DEFAULTLECOEFLEQ int = 10
+
+// the TikZ code for generating linear equations is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexLinearEquationCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the linear equation
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZLinearEquationCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Equation --------------------------------------------------------
+
+      % all terms of the equation are written from left to right, and the
+      % unknown is shown as a box
+{{.GetTerms}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A linear equation consists of a type (either LEONESTEP or LETWOSTEP), the
+// range [geq, leq] of its solution and the largest value of its coefficients.
+// By default, all numbers shown are non-negative, though negative numbers can
+// be also allowed, both in the coefficients and the solution
+type linearEquation struct {
+	letype   int
+	geq, leq int
+	coefleq  int
+	negative bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw linear
+// equations
+type linearEquationTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// all terms of the equation, including the box of the unknown
+	terms []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- linearEquationTikZ
+
+// Generates the TikZ code necessary for drawing all the terms of the equation
+func (tikz linearEquationTikZ) GetTerms() string {
+
+	// Use a btyes buffer to append the strings of each term
+	var output bytes.Buffer
+
+	for _, term := range tikz.terms {
+		fmt.Fprintf(&output, "      %v\n", term)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// terms
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz linearEquationTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("linearEquationTikZ").Parse(tikZLinearEquationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- linearEquation
+
+// return a random coefficient of this linear equation whose magnitude is in the
+// range [from, coefleq]. It can be negative only if negative numbers are
+// allowed
+func (le linearEquation) coefficient(rnd *rand.Rand, from int) int {
+
+	value := from + rnd.Intn(1+le.coefleq-from)
+	if le.negative && rnd.Intn(2) == 0 {
+		value = -value
+	}
+	return value
+}
+
+// return the instance of a specific linear equation that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given as the sequence of terms of the equation from left to
+// right: numbers, the operators "+", "-", "*" and "/", and the equal sign "=".
+// The unknown is shown as "?" in the arguments, whereas its value is given in
+// the solution
+func (le linearEquation) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if le.geq > le.leq {
+		return ProblemJSON{}, fmt.Errorf("The range [%v, %v] of the solution of a linear equation is empty", le.geq, le.leq)
+	}
+
+	// randomly generate equations until one where all numbers shown are
+	// non-negative (unless negative numbers are allowed) is found. If none is
+	// found after a maximum number of attempts, the parameters are deemed to
+	// be incompatible
+	var terms, steps []string
+	var x int
+	for attempt := 0; ; attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate a linear equation with a solution in the range [%v, %v] and coefficients up to %v (negative numbers allowed: %v) after %v attempts",
+				le.geq, le.leq, le.coefleq, le.negative, MAXGENERATIONATTEMPTS)
+		}
+
+		// randomly choose the solution and the operator of the equation
+		x = le.geq + rnd.Intn(1+le.leq-le.geq)
+		var result int
+		if le.letype == LEONESTEP {
+
+			// equations with one step apply one operator to the unknown
+			operator := []string{"+", "-", "*", "/"}[rnd.Intn(4)]
+			switch operator {
+			case "+":
+				a := le.coefficient(rnd, 1)
+				result = x + a
+				terms = []string{"x", "+", fmt.Sprintf("%v", a)}
+				steps = []string{"x = " + solutionStep(result, "-", a, x)}
+			case "-":
+				a := le.coefficient(rnd, 1)
+				result = x - a
+				terms = []string{"x", "-", fmt.Sprintf("%v", a)}
+				steps = []string{"x = " + solutionStep(result, "+", a, x)}
+			case "*":
+				a := le.coefficient(rnd, 2)
+				result = a * x
+				terms = []string{fmt.Sprintf("%v", a), "*", "x"}
+				steps = []string{"x = " + solutionStep(result, "/", a, x)}
+			case "/":
+
+				// divisions have to be exact
+				a := le.coefficient(rnd, 2)
+				if x%a != 0 {
+					continue
+				}
+				result = x / a
+				terms = []string{"x", "/", fmt.Sprintf("%v", a)}
+				steps = []string{"x = " + solutionStep(result, "*", a, x)}
+			}
+		} else {
+
+			// equations with two steps multiply the unknown by a coefficient
+			// and then add or subtract another one
+			a, b := le.coefficient(rnd, 2), le.coefficient(rnd, 1)
+			operator := []string{"+", "-"}[rnd.Intn(2)]
+			if operator == "+" {
+				result = a*x + b
+				steps = []string{fmt.Sprintf("%v * x = ", a) + solutionStep(result, "-", b, a*x)}
+			} else {
+				result = a*x - b
+				steps = []string{fmt.Sprintf("%v * x = ", a) + solutionStep(result, "+", b, a*x)}
+			}
+			terms = []string{fmt.Sprintf("%v", a), "*", "x", operator, fmt.Sprintf("%v", b)}
+			steps = append(steps, "x = "+solutionStep(a*x, "/", a, x))
+		}
+
+		// unless negative numbers are allowed, make sure that the result is
+		// non-negative. Note that all coefficients and the solution already
+		// are
+		if !le.negative && result < 0 {
+			continue
+		}
+		terms = append(terms, "=", fmt.Sprintf("%v", result))
+		break
+	}
+
+	// the arguments mask the unknown whose value is given in the solution
+	args := make([]string, len(terms))
+	solution := make([]string, len(terms))
+	for idx, term := range terms {
+		args[idx], solution[idx] = term, term
+		if term == "x" {
+			args[idx], solution[idx] = "?", fmt.Sprintf("%v", x)
+		}
+	}
+
+	return ProblemJSON{
+		Probtype: "LinearEquation",
+		Args:     args,
+		Solution: solution,
+		Steps:    steps,
+	}, nil
+}
+
+// set the difficulty and tags of the given problem generated by this linear
+// equation. Equations solved in two steps are harder, and also those with
+// negative numbers or large coefficients
+func (le linearEquation) annotate(problem *ProblemJSON) {
+
+	score := 2
+	problem.Tags = []string{"one-step"}
+	if le.letype == LETWOSTEP {
+		score++
+		problem.Tags = []string{"two-step"}
+	}
+	for _, term := range problem.Solution {
+		if strings.HasPrefix(term, "-") && term != "-" {
+			score++
+			problem.Tags = append(problem.Tags, "negative")
+			break
+		}
+	}
+	if le.coefleq > DEFAULTLECOEFLEQ {
+		score++
+	}
+	problem.Difficulty = difficulty(score)
+}
+
+// return a valid LaTeX/TikZ representation of this linear equation using TikZ
+// components. All terms are written in one row from left to right
+func (le linearEquation) GetTikZPicture() (string, error) {
+
+	// -- terms: randomly determine the equation. For this, the service that
+	//           generates problems is the one that can marshal them into JSON
+	//           format. A question mark is the unknown that has to be guessed
+	//           by the student
+	instance, err := le.next(annotated(le))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid linear equation: %v", err)
+	}
+
+	// the box of the unknown is wide enough to write any solution in the
+	// given range, including its sign
+	nbdigits := int(helpers.Max(float64(len(fmt.Sprintf("%v", le.geq))), float64(len(fmt.Sprintf("%v", le.leq)))))
+	boxwidth := 2.0 + float64(nbdigits)
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- terms: every term is centered in its own width, and they are
+	//           separated by half the width of a digit
+	var terms []components.CoordinatedText
+	x := 0.5
+	for idx, term := range instance.Args {
+
+		var width float64
+		var options, text string
+		switch term {
+		case "?":
+			width = boxwidth
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(boxwidth))
+			text = le.answer(strings.Replace(instance.Solution[idx], "-", `$-$`, 1))
+		case "+", "-", "=":
+			width, text = 1.0, fmt.Sprintf(`\huge $%v$`, term)
+		case "*":
+			width, text = 1.0, `\huge $\cdot$`
+		case "/":
+			width, text = 1.0, `\huge $\div$`
+		default:
+
+			// negative numbers are written with a proper minus sign, and
+			// they are wrapped in parentheses unless they start either side
+			// of the equation
+			width, text = float64(len(term)), `\huge `+strings.Replace(term, "-", `$-$`, 1)
+			if strings.HasPrefix(term, "-") && idx > 0 && instance.Args[idx-1] != "=" {
+				width, text = width+1.0, `\huge (`+strings.Replace(term, "-", `$-$`, 1)+`)`
+			}
+		}
+
+		terms = append(terms, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight + 0.5\baselineskip)$`,
+					helpers.Ftoa(x+width/2.0))),
+				fmt.Sprintf("term%v", idx)),
+			options, text))
+		x += width + 0.5
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight + \baselineskip)$`,
+			helpers.Ftoa(x))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// linear equation
+	lePicture := linearEquationTikZ{
+		Bottom: bottom,
+		terms:  terms,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return lePicture.execute()
+}
+
+// Return TikZ code that represents a linear equation
+func (le linearEquation) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this linear equation
+	tpl, err := template.New("linearEquation").Parse(latexLinearEquationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, le); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
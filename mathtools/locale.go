@@ -0,0 +1,143 @@
+// -*- coding: utf-8 -*-
+// locale.go
+//
+// Description: Provides the conventions of different languages used for
+// drawing problems
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 09:02:36.471520846 (1792141356)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A locale gathers the conventions of a language for drawing problems: the
+// decimal separator, the layout of divisions (DIVBOX, DIVBRACKET or
+// DIVPOTENCE), the words used for every operator ("+", "-", "*" and "/") and
+// the bank of stories of word problems. Numbers are written in words in the
+// language of the locale, which is given with its ISO 639-1 code. Master files
+// can query the active locale with {{.Locale}}
+type Locale struct {
+	Code             string
+	DecimalSeparator string
+	DivisionStyle    string
+	Operators        map[string]string
+
+	// default bank of stories of word problems
+	stories []story
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The locales acknowledged are indexed by their ISO 639-1 code
+var locales = map[string]Locale{
+	"en": {
+		Code:             "en",
+		DecimalSeparator: ".",
+		DivisionStyle:    DIVBRACKET,
+		Operators:        map[string]string{"+": "plus", "-": "minus", "*": "times", "/": "divided by"},
+		stories:          englishStoryBank,
+	},
+	"es": {
+		Code:             "es",
+		DecimalSeparator: ",",
+		DivisionStyle:    DIVBOX,
+		Operators:        map[string]string{"+": "más", "-": "menos", "*": "por", "/": "entre"},
+		stories:          spanishStoryBank,
+	},
+}
+
+// Unless a locale is selected, problems are drawn with the following
+// conventions, and numbers are written in words in the language DEFAULTLOCALE
+var defaultLocale = Locale{
+	Code:             DEFAULTLOCALE,
+	DecimalSeparator: ".",
+	DivisionStyle:    DIVBOX,
+	Operators:        locales[DEFAULTLOCALE].Operators,
+	stories:          spanishStoryBank,
+}
+
+// The locale currently selected, if any
+var activeLocale *Locale
+var activeLocaleMutex sync.RWMutex
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the ISO 639-1 codes of all the locales acknowledged sorted in
+// alphabetical order
+func Locales() []string {
+
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Select the locale with the given ISO 639-1 code for drawing all problems
+// generated from now on. If an empty code is given, the default conventions
+// are restored. If the locale is not acknowledged, an error is returned
+func SetLocale(code string) error {
+
+	activeLocaleMutex.Lock()
+	defer activeLocaleMutex.Unlock()
+
+	if code == "" {
+		activeLocale = nil
+		return nil
+	}
+	locale, ok := locales[code]
+	if !ok {
+		return fmt.Errorf("Unknown locale '%v'. It should be one among the following: %v", code, strings.Join(Locales(), ", "))
+	}
+	activeLocale = &locale
+	return nil
+}
+
+// return the locale currently selected or the default conventions if none was
+// selected
+func currentLocale() Locale {
+
+	activeLocaleMutex.RLock()
+	defer activeLocaleMutex.RUnlock()
+
+	if activeLocale == nil {
+		return defaultLocale
+	}
+	return *activeLocale
+}
+
+// return the given number with its decimal point, if any, written with the
+// decimal separator of the current locale
+func localizeDecimal(number string) string {
+	return strings.Replace(number, ".", currentLocale().DecimalSeparator, 1)
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- MasterFile
+
+// Return the locale currently selected so that master files can adapt their
+// contents to it, e.g., {{if eq .Locale.Code "es"}}
+func (masterFile MasterFile) Locale() Locale {
+	return currentLocale()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,416 @@
+// -*- coding: utf-8 -*-
+// magicsquare.go
+//
+// Description: Provides services for automatically creating magic square
+// puzzles
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:28:55.848486185 (1792114135)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// By default, the magic constant of squares is in the following range
+const (
+	DEFAULTMAGICGEQ int = 15
+	DEFAULTMAGICLEQ int = 60
+)
+
+// the TikZ code for generating magic squares is shown next. Note that it makes
+// use of LaTeX/TikZ components
+const latexMagicSquareCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the magic square
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZMagicSquareCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Magic constant --------------------------------------------------
+
+      % the sum of every row, column and diagonal is shown above the square
+      {{.Constant}}
+
+      % --- Cells -----------------------------------------------------------
+
+      % every cell either shows its number or it is empty
+{{.GetCells}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A magic square consists of a square grid with size rows and columns (either 3
+// or 4), whose rows, columns and diagonals sum up to the same magic constant,
+// which is taken in the range [geq, leq]. Only nbrevealed cells are shown and
+// the student has to fill in the rest
+type magicSquare struct {
+	size       int
+	geq, leq   int
+	nbrevealed int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw magic
+// squares
+type magicSquareTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the magic constant is shown above the square
+	Constant components.CoordinatedText
+
+	// all cells of the square
+	cells []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// 4x4 magic squares are generated from the square of Dürer with the numbers 1
+// to 16, whose magic constant is 34
+var durerSquare = [][]int{
+	{16, 3, 2, 13},
+	{5, 10, 11, 8},
+	{9, 6, 7, 12},
+	{4, 15, 14, 1},
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the result of randomly rotating and reflecting the given square. Note
+// that these operations preserve magic squares
+func randomSymmetry(rnd *rand.Rand, square [][]int) [][]int {
+
+	size := len(square)
+	result := make([][]int, size)
+	for i := range result {
+		result[i] = make([]int, size)
+	}
+
+	rotations, transpose := rnd.Intn(4), rnd.Intn(2) == 1
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+
+			// compute the location of this cell after the rotations and then
+			// reflect it along the main diagonal if requested
+			r, c := i, j
+			for k := 0; k < rotations; k++ {
+				r, c = c, size-1-r
+			}
+			if transpose {
+				r, c = c, r
+			}
+			result[r][c] = square[i][j]
+		}
+	}
+	return result
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- magicSquareTikZ
+
+// Generates the TikZ code necessary for drawing all the cells of the magic
+// square
+func (tikz magicSquareTikZ) GetCells() string {
+
+	// Use a btyes buffer to append the strings of each cell
+	var output bytes.Buffer
+
+	for _, cell := range tikz.cells {
+		fmt.Fprintf(&output, "      %v\n", cell)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// cells
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz magicSquareTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("magicSquareTikZ").Parse(tikZMagicSquareCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- magicSquare
+
+// return a random magic square of size 3 whose magic constant is in the range
+// [geq, leq] with distinct positive numbers, and its magic constant
+func (ms magicSquare) square3(rnd *rand.Rand) ([][]int, int, error) {
+
+	// the magic constant of 3x3 squares is three times the number in their
+	// center
+	low, high := (ms.geq+2)/3, ms.leq/3
+	if low > high {
+		return nil, 0, fmt.Errorf("There are no 3x3 magic squares with a magic constant in the range [%v, %v]", ms.geq, ms.leq)
+	}
+
+	// every 3x3 magic square is fully determined by its center and two
+	// offsets. Randomly choose them until all numbers are distinct and
+	// positive
+	for attempt := 0; attempt < MAXGENERATIONATTEMPTS; attempt++ {
+
+		center := low + rnd.Intn(1+high-low)
+		if center < 2 {
+			continue
+		}
+		a, b := 1+rnd.Intn(center-1), 1+rnd.Intn(center-1)
+		square := [][]int{
+			{center - b, center + a + b, center - a},
+			{center - a + b, center, center + a - b},
+			{center + a, center - a - b, center + b},
+		}
+
+		// verify that all numbers are distinct and positive
+		seen := make(map[int]bool)
+		for _, row := range square {
+			for _, value := range row {
+				if value > 0 {
+					seen[value] = true
+				}
+			}
+		}
+		if len(seen) == 9 {
+			return square, 3 * center, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("It was not possible to generate a 3x3 magic square with a magic constant in the range [%v, %v] after %v attempts",
+		ms.geq, ms.leq, MAXGENERATIONATTEMPTS)
+}
+
+// return a random magic square of size 4 whose magic constant is in the range
+// [geq, leq] with distinct positive numbers, and its magic constant
+func (ms magicSquare) square4(rnd *rand.Rand) ([][]int, int, error) {
+
+	// 4x4 squares are generated by scaling and shifting all numbers of the
+	// square of Dürer, so that the magic constant is 34 times the scale plus
+	// 4 times the shift
+	for attempt := 0; attempt < MAXGENERATIONATTEMPTS && 34 <= ms.leq; attempt++ {
+
+		scale := 1 + rnd.Intn(ms.leq/34)
+		shift := rnd.Intn(1 + (ms.leq-34*scale)/4)
+		constant := 34*scale + 4*shift
+		if constant < ms.geq {
+			continue
+		}
+
+		square := make([][]int, 4)
+		for i, row := range durerSquare {
+			for _, value := range row {
+				square[i] = append(square[i], scale*value+shift)
+			}
+		}
+		return square, constant, nil
+	}
+	return nil, 0, fmt.Errorf("It was not possible to generate a 4x4 magic square with a magic constant in the range [%v, %v] after %v attempts",
+		ms.geq, ms.leq, MAXGENERATIONATTEMPTS)
+}
+
+// return the instance of a specific magic square that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with the size of the square, its magic constant and the
+// numbers of all its cells from top to bottom and from left to right. Cells
+// which are not revealed are shown as "?" in the arguments as they have to be
+// guessed by the student
+func (ms magicSquare) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if ms.nbrevealed < 0 || ms.nbrevealed > ms.size*ms.size {
+		return ProblemJSON{}, fmt.Errorf("The number of revealed cells of a magic square with size %v should be in the range [0, %v]", ms.size, ms.size*ms.size)
+	}
+
+	// generate a magic square of the given size and randomly rotate or
+	// reflect it
+	var square [][]int
+	var constant int
+	var err error
+	switch ms.size {
+	case 3:
+		square, constant, err = ms.square3(rnd)
+	case 4:
+		square, constant, err = ms.square4(rnd)
+	default:
+		err = fmt.Errorf("Magic squares can only have size 3 or 4 but %v was given", ms.size)
+	}
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	square = randomSymmetry(rnd, square)
+
+	// write the solution and then randomly choose the cells to reveal
+	args := []string{fmt.Sprintf("%v", ms.size), fmt.Sprintf("%v", constant)}
+	solution := []string{fmt.Sprintf("%v", ms.size), fmt.Sprintf("%v", constant)}
+	for _, row := range square {
+		for _, value := range row {
+			args = append(args, "?")
+			solution = append(solution, fmt.Sprintf("%v", value))
+		}
+	}
+	for _, idx := range rnd.Perm(ms.size * ms.size)[:ms.nbrevealed] {
+		args[2+idx] = solution[2+idx]
+	}
+
+	return ProblemJSON{
+		Probtype: "MagicSquare",
+		Args:     args,
+		Solution: solution,
+	}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this magic square using TikZ
+// components
+func (ms magicSquare) GetTikZPicture() (string, error) {
+
+	// -- cells: randomly determine the magic square. For this, the service
+	//           that generates problems is the one that can marshal them into
+	//           JSON format. A question mark is a number that has to be
+	//           guessed by the student
+	instance, err := ms.next(annotated(ms))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid magic square: %v", err)
+	}
+
+	// all cells are squares wide enough to write the largest number in them
+	nbdigits := 0
+	for _, value := range instance.Solution[2:] {
+		nbdigits = int(helpers.Max(float64(nbdigits), float64(len(value))))
+	}
+	side := 2.0 + float64(nbdigits)
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- cells: both dimensions are given in digit widths, and the first row
+	//           is the one on top
+	var cells []components.CoordinatedText
+	for idx, item := range instance.Args[2:] {
+		row, col := idx/ms.size, idx%ms.size
+		coord := components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zerowidth)$`,
+				helpers.Ftoa(0.5+side*(0.5+float64(col))),
+				helpers.Ftoa(0.5+side*(0.5+float64(ms.size-1-row))))),
+			fmt.Sprintf("cell%v%v", row, col))
+		options := fmt.Sprintf(`rectangle, minimum width=%v\zerowidth, minimum height=%v\zerowidth, draw`,
+			helpers.Ftoa(side), helpers.Ftoa(side))
+		text := `\huge ` + item
+		if item == "?" {
+			text = ms.answer(instance.Solution[2+idx])
+		}
+		cells = append(cells, components.NewCoordinatedText(coord, options, text))
+	}
+
+	// -- magic constant
+	constant := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zerowidth + \baselineskip)$`,
+				helpers.Ftoa(0.5+side*float64(ms.size)/2.0),
+				helpers.Ftoa(0.5+side*float64(ms.size)))),
+			"constant"),
+		"", fmt.Sprintf(`\Large $\Sigma = %v$`, instance.Args[1]))
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zerowidth + 2\baselineskip)$`,
+			helpers.Ftoa(1.0+side*float64(ms.size)),
+			helpers.Ftoa(0.5+side*float64(ms.size)))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the magic
+	// square
+	msPicture := magicSquareTikZ{
+		Bottom:   bottom,
+		Constant: constant,
+		cells:    cells,
+		BBox:     bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return msPicture.execute()
+}
+
+// Return TikZ code that represents a magic square
+func (ms magicSquare) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this magic square
+	tpl, err := template.New("magicSquare").Parse(latexMagicSquareCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, ms); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,498 @@
+// -*- coding: utf-8 -*-
+// mathcrossword.go
+//
+// Description: Provides services for automatically creating crossed
+// operations, i.e., grids where horizontal and vertical equations share their
+// numbers
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:31:46.703076124 (1792114306)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// By default, crossed operations use additions and subtractions
+const DEFAULTCROSSWORDOPERATORS string = "+-"
+
+// Cells of crossed operations are as high as the following value, and as wide
+// as the following margin plus the width of every digit, all given in
+// centimeters
+const (
+	CROSSWORDCELLHEIGHT float64 = 1.2
+	CROSSWORDCELLMARGIN float64 = 0.5
+	CROSSWORDDIGITWIDTH float64 = 0.45
+)
+
+// the TikZ code for generating crossed operations is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexMathCrosswordCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the crossed operations
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZMathCrosswordCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left and upper-left corners of the bounding box
+      {{.Bottom}}
+      {{.Origin}}
+
+      % --- Grid ------------------------------------------------------------
+
+      % numbers are shown in framed cells, which are empty if they have to
+      % be guessed, and operators in between
+{{.Grid}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// Crossed operations consist of a square block of operands with size rows and
+// columns. Every row and column of operands is an equation whose result is
+// written at its end, and the results of all rows and columns are also related
+// with equations, so that the number in the bottom-right corner is the result
+// of both. All operators are randomly chosen among the given ones, either
+// additions and subtractions, or multiplications. Operands are taken in the
+// range [geq, leq] and nbmasked numbers are hidden so that all of them can be
+// derived solving one equation at a time
+type mathCrossword struct {
+	size      int
+	geq, leq  int
+	operators string
+	nbmasked  int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw crossed
+// operations
+type mathCrosswordTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0),
+	// and the grid is drawn from its upper-left corner
+	Bottom, Origin components.Coordinate
+
+	// the grid with all numbers and operators
+	Grid components.Grid
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- mathCrosswordTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz mathCrosswordTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("mathCrosswordTikZ").Parse(tikZMathCrosswordCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- mathCrossword
+
+// return the equations of these crossed operations. Numbers are located in a
+// square matrix with size+1 rows and columns, where the last row and column
+// contain the results. Every equation is given as the locations of its
+// operands followed by the location of its result
+func (mc mathCrossword) equations() [][][2]int {
+
+	var result [][][2]int
+	for i := 0; i <= mc.size; i++ {
+		var row, col [][2]int
+		for j := 0; j <= mc.size; j++ {
+			row = append(row, [2]int{i, j})
+			col = append(col, [2]int{j, i})
+		}
+		result = append(result, row, col)
+	}
+	return result
+}
+
+// return true if all numbers of these crossed operations can be derived from
+// the revealed ones, solving one equation with one single unknown at a time
+func (mc mathCrossword) solvable(revealed [][]bool) bool {
+
+	known := make([][]bool, len(revealed))
+	for i := range revealed {
+		known[i] = append([]bool{}, revealed[i]...)
+	}
+
+	// repeatedly look for equations with one single unknown until no more
+	// numbers can be derived
+	for progress := true; progress; {
+		progress = false
+		for _, equation := range mc.equations() {
+			var unknowns [][2]int
+			for _, loc := range equation {
+				if !known[loc[0]][loc[1]] {
+					unknowns = append(unknowns, loc)
+				}
+			}
+			if len(unknowns) == 1 {
+				known[unknowns[0][0]][unknowns[0][1]] = true
+				progress = true
+			}
+		}
+	}
+
+	for _, row := range known {
+		for _, value := range row {
+			if !value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// return the result of applying the given operators from left to right to the
+// given operands, and whether all partial results are non-negative or not
+func evaluate(operands []int, operators []string) (int, bool) {
+
+	result, ok := operands[0], operands[0] >= 0
+	for idx, operator := range operators {
+		switch operator {
+		case "+":
+			result += operands[1+idx]
+		case "-":
+			result -= operands[1+idx]
+		case "*":
+			result *= operands[1+idx]
+		}
+		ok = ok && result >= 0
+	}
+	return result, ok
+}
+
+// return the instance of a specific set of crossed operations that can be
+// marshalled in JSON format. The receiver is assumed to have been fully
+// verified so that it should be consistent.
+//
+// The result is given with the size followed by all cells of the grid from top
+// to bottom and left to right: numbers, operators ("+", "-", "*" or "=") and
+// empty strings for the cells between operators. Numbers which are hidden are
+// shown as "?" in the arguments as they have to be guessed by the student
+func (mc mathCrossword) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if mc.geq > mc.leq {
+		return ProblemJSON{}, fmt.Errorf("The range [%v, %v] of the operands of crossed operations is empty", mc.geq, mc.leq)
+	}
+
+	// multiplications can not be mixed with additions and subtractions, as
+	// otherwise the results of the rows and columns would not be consistent.
+	// Thus, randomly choose the kind of operations first
+	multiplicative := strings.Contains(mc.operators, "*") &&
+		(!strings.ContainsAny(mc.operators, "+-") || rnd.Intn(2) == 0)
+
+	// the numbers are stored in a square matrix whose last row and column
+	// are the results, and the operators of every row and column are stored
+	// separately
+	n := mc.size + 1
+	numbers := make([][]int, n)
+	rowops, colops := make([][]string, n), make([][]string, n)
+	for attempt := 0; ; attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate crossed operations with size %v, operators '%v' and operands in the range [%v, %v] after %v attempts",
+				mc.size, mc.operators, mc.geq, mc.leq, MAXGENERATIONATTEMPTS)
+		}
+
+		// randomly choose the sign of every operand in the bottom-right
+		// corner, which is the sum of all operands. The operators of every
+		// row and column are derived from them so that both the results of
+		// rows and columns add up to the same number
+		sign := make([][]int, mc.size)
+		for i := range sign {
+			sign[i] = make([]int, mc.size)
+			for j := range sign[i] {
+				sign[i][j] = 1
+				if !multiplicative && (i > 0 || j > 0) && rnd.Intn(2) == 0 {
+					sign[i][j] = -1
+				}
+			}
+		}
+		symbol := func(value int) string {
+			if multiplicative {
+				return "*"
+			}
+			if value > 0 {
+				return "+"
+			}
+			return "-"
+		}
+		for i := 0; i < n; i++ {
+			rowops[i], colops[i] = make([]string, mc.size-1), make([]string, mc.size-1)
+			for j := 1; j < mc.size; j++ {
+				if i < mc.size {
+					rowops[i][j-1] = symbol(sign[i][j] * sign[i][0])
+					colops[i][j-1] = symbol(sign[j][i] * sign[0][i])
+				} else {
+					rowops[i][j-1] = symbol(sign[0][j])
+					colops[i][j-1] = symbol(sign[j][0])
+				}
+			}
+		}
+
+		// verify that all operators are allowed
+		allowed := true
+		for i := 0; i < n; i++ {
+			for j := 0; j < mc.size-1; j++ {
+				allowed = allowed && strings.Contains(mc.operators, rowops[i][j]) &&
+					strings.Contains(mc.operators, colops[i][j])
+			}
+		}
+		if !allowed {
+			continue
+		}
+
+		// randomly choose all operands and compute the results of all rows
+		// and columns, ensuring that no partial result is negative
+		valid := true
+		for i := 0; i < mc.size; i++ {
+			numbers[i] = make([]int, n)
+			for j := 0; j < mc.size; j++ {
+				numbers[i][j] = mc.geq + rnd.Intn(1+mc.leq-mc.geq)
+			}
+		}
+		numbers[mc.size] = make([]int, n)
+		for i := 0; i < mc.size; i++ {
+			var ok bool
+			numbers[i][mc.size], ok = evaluate(numbers[i][:mc.size], rowops[i])
+			valid = valid && ok
+
+			column := make([]int, mc.size)
+			for j := 0; j < mc.size; j++ {
+				column[j] = numbers[j][i]
+			}
+			numbers[mc.size][i], ok = evaluate(column, colops[i])
+			valid = valid && ok
+		}
+		var ok bool
+		numbers[mc.size][mc.size], ok = evaluate(numbers[mc.size][:mc.size], rowops[mc.size])
+		valid = valid && ok
+
+		// the bottom-right corner is necessarily the result of the last
+		// column as well, but its partial results have to be verified
+		column := make([]int, mc.size)
+		for j := 0; j < mc.size; j++ {
+			column[j] = numbers[j][mc.size]
+		}
+		_, ok = evaluate(column, colops[mc.size])
+		if valid && ok {
+			break
+		}
+	}
+
+	// next, randomly hide numbers until all of them can be derived from the
+	// revealed ones
+	revealed := make([][]bool, n)
+	for attempt := 0; ; attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to hide %v numbers of crossed operations with size %v so that they can be derived after %v attempts",
+				mc.nbmasked, mc.size, MAXGENERATIONATTEMPTS)
+		}
+		for i := range revealed {
+			revealed[i] = []bool{}
+			for j := 0; j < n; j++ {
+				revealed[i] = append(revealed[i], true)
+			}
+		}
+		for _, idx := range rnd.Perm(n * n)[:mc.nbmasked] {
+			revealed[idx/n][idx%n] = false
+		}
+		if mc.solvable(revealed) {
+			break
+		}
+	}
+
+	// finally, write down all cells of the grid. Numbers are located in even
+	// rows and columns, operators between them, and empty cells elsewhere
+	args := []string{fmt.Sprintf("%v", mc.size)}
+	solution := []string{fmt.Sprintf("%v", mc.size)}
+	for r := 0; r < 2*n-1; r++ {
+		for c := 0; c < 2*n-1; c++ {
+
+			var cell string
+			switch {
+			case r%2 == 0 && c%2 == 0:
+				value := fmt.Sprintf("%v", numbers[r/2][c/2])
+				solution = append(solution, value)
+				if !revealed[r/2][c/2] {
+					value = "?"
+				}
+				args = append(args, value)
+				continue
+			case r%2 == 0 && c == 2*n-3:
+				cell = "="
+			case r%2 == 0:
+				cell = rowops[r/2][(c-1)/2]
+			case c%2 == 0 && r == 2*n-3:
+				cell = "="
+			case c%2 == 0:
+				cell = colops[c/2][(r-1)/2]
+			}
+			args = append(args, cell)
+			solution = append(solution, cell)
+		}
+	}
+
+	return ProblemJSON{
+		Probtype: "MathCrossword",
+		Args:     args,
+		Solution: solution,
+	}, nil
+}
+
+// return a valid LaTeX/TikZ representation of these crossed operations using
+// TikZ components
+func (mc mathCrossword) GetTikZPicture() (string, error) {
+
+	// -- grid: randomly determine the crossed operations. For this, the
+	//          service that generates problems is the one that can marshal
+	//          them into JSON format. A question mark is a number that has to
+	//          be guessed by the student
+	instance, err := mc.next(annotated(mc))
+	if err != nil {
+		return "", fmt.Errorf("error while generating valid crossed operations: %v", err)
+	}
+
+	// all cells are wide enough to write the largest number
+	nbdigits := 0
+	for _, cell := range instance.Solution[1:] {
+		nbdigits = int(helpers.Max(float64(nbdigits), float64(len(cell))))
+	}
+	width := CROSSWORDCELLMARGIN + CROSSWORDDIGITWIDTH*float64(nbdigits)
+
+	// compute the text of every cell and whether it is framed or not
+	nbcols := 2*mc.size + 1
+	var texts [][]string
+	var framed [][]bool
+	for idx, cell := range instance.Args[1:] {
+		if idx%nbcols == 0 {
+			texts = append(texts, []string{})
+			framed = append(framed, []bool{})
+		}
+
+		var text string
+		frame := false
+		switch cell {
+		case "":
+		case "+", "-", "=":
+			text = fmt.Sprintf(`\huge $%v$`, cell)
+		case "*":
+			text = `\huge $\times$`
+		case "?":
+			text, frame = mc.answer(instance.Solution[1+idx]), true
+		default:
+			text, frame = `\huge `+cell, true
+		}
+		texts[len(texts)-1] = append(texts[len(texts)-1], text)
+		framed[len(framed)-1] = append(framed[len(framed)-1], frame)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box and the origin of
+	// the grid is its upper-left corner
+	grid := components.NewGrid("origin", width, CROSSWORDCELLHEIGHT, texts, framed)
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	origin := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: grid.GetHeight(),
+	}, "origin")
+
+	// -- bounding box
+	right := components.NewCoordinate(components.Point{
+		X: grid.GetWidth(),
+		Y: grid.GetHeight(),
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// crossed operations
+	mcPicture := mathCrosswordTikZ{
+		Bottom: bottom,
+		Origin: origin,
+		Grid:   grid,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return mcPicture.execute()
+}
+
+// Return TikZ code that represents crossed operations
+func (mc mathCrossword) execute() (string, error) {
+
+	// create a template with the TikZ code for showing these crossed operations
+	tpl, err := template.New("mathCrossword").Parse(latexMathCrosswordCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, mc); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
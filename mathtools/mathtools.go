@@ -21,12 +21,18 @@ package mathtools
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log" // logging services
 	"os"  // access to file mgmt functions
+	"path/filepath"
+	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	// go facility for processing templates
 	"github.com/clinaresl/mathprob/fstools"
@@ -34,18 +40,216 @@ import (
 	"github.com/clinaresl/mathprob/mathtools/components"
 )
 
+// global variables
+// ----------------------------------------------------------------------------
+
+// The keys acknowledged in the dictionaries used for defining every type of
+// problem are given next. These are used both for verifying the dictionaries
+// and for describing the supported problem types (see SupportedTypes)
+var angleMandatory = []string{"type"}
+var angleOptional = []string{"step", "protractor"}
+var barChartMandatory = []string{"categories", "geq", "leq"}
+var barChartOptional = []string{"mode", "questions"}
+var basicOperationMandatory = []string{"type", "operator", "nboperands", "nbdigitsop", "nbdigitsrslt"}
+var basicOperationOptional = []string{"scaffold", "carrybox", "carry", "level", "nbdecimals", "allownegative", "layout", "geq", "leq", "excludedigits", "patterns"}
+var clockMandatory = []string{"type", "granularity"}
+var countingObjectsMandatory = []string{"geq", "leq"}
+var countingObjectsOptional = []string{"symbol", "grouped"}
+var divisionMandatory = []string{"nbdvdigits", "nbdrdigits", "nbqdigits"}
+var divisionOptional = []string{"extended", "style", "remainder", "remainderbox", "nbdecimals", "table", "dvgeq", "dvleq", "drgeq", "drleq", "excludedigits", "patterns"}
+var elapsedTimeMandatory = []string{"type", "granularity"}
+var elapsedTimeOptional = []string{"from", "to", "maxduration"}
+var equivalentFractionMandatory = []string{"type", "dengeq", "denleq", "scalegeq", "scaleleq"}
+var factFamilyMandatory = []string{"operator", "geq", "leq"}
+var factFamilyOptional = []string{"facts"}
+var fdpConversionMandatory = []string{"from", "to"}
+var fdpConversionOptional = []string{"denleq"}
+var linearEquationMandatory = []string{"type", "geq", "leq"}
+var linearEquationOptional = []string{"coefleq", "negative"}
+var magicSquareMandatory = []string{"size"}
+var magicSquareOptional = []string{"geq", "leq", "nbrevealed"}
+var mathCrosswordMandatory = []string{"size", "geq", "leq"}
+var mathCrosswordOptional = []string{"operators", "nbmasked"}
+var mixedDrillMandatory = []string{"operations", "count"}
+var mixedDrillOptional = []string{"type", "nboperands", "layout", "cols"}
+var moneyMandatory = []string{"type", "geq", "leq"}
+var moneyOptional = []string{"currency", "decimals", "nbitems", "coins"}
+var mysteryOperationMandatory = []string{
+	"nbdigits1", "nbmasked1",
+	"nbdigits2", "nbmasked2",
+	"nbdigitsanswer", "nbmaskedanswer",
+	"operator"}
+var mysteryOperationOptional = []string{"allownegative"}
+var multiplicationTableMandatory = []string{"type", "nbdigits"}
+var multiplicationTableOptional = []string{"geq", "leq", "inv", "sorted", "layout", "excludedigits", "patterns"}
+var numberClassificationMandatory = []string{"type", "geq", "leq", "nbnumbers"}
+var numberClassificationOptional = []string{"multiple", "nbcols"}
+var numberComparisonMandatory = []string{"nbdigits"}
+var numberComparisonOptional = []string{"nbitems", "negative"}
+var numberFormMandatory = []string{"from", "to", "nbdigits"}
+var numberFormOptional = []string{"locale"}
+var numberLineMandatory = []string{"type", "geq", "leq", "step"}
+var numberLineOptional = []string{"nbticks", "hidden", "orientation"}
+var gridPaperMandatory = []string{"step", "cols", "rows", "style"}
+var gridPaperOptional = []string{"margin"}
+var gridMandatory = []string{"rows", "cols", "problem", "args"}
+var gridOptional = []string{"vspace", "pagerows"}
+var numberPyramidMandatory = []string{"height", "geq", "leq"}
+var numberPyramidOptional = []string{"reveal", "nbrevealed"}
+var percentageMandatory = []string{"type", "geq", "leq"}
+var percentageOptional = []string{"pctgeq", "pctleq", "decimals"}
+var perimeterAreaMandatory = []string{"type", "shape", "geq", "leq"}
+var pictogramMandatory = []string{"categories", "geq", "leq"}
+var pictogramOptional = []string{"chart", "symbol", "questions"}
+var placeValueMandatory = []string{"type", "nbdigits"}
+var placeValueOptional = []string{"nbmasked", "masked"}
+var primeFactorizationMandatory = []string{"geq", "leq"}
+var primeFactorizationOptional = []string{"maxfactors"}
+var ratioMandatory = []string{"type", "geq", "leq", "scalegeq", "scaleleq"}
+var roundingMandatory = []string{"nbdigits", "place"}
+var roundingOptional = []string{"tiebreak", "highlight"}
+var sequenceMandatory = []string{"type", "nbitems", "geq", "leq"}
+var sequenceOptional = []string{"pattern", "step", "altstep", "start-multiple", "mask", "nbmasked"}
+var shadedFractionMandatory = []string{"type", "dengeq", "denleq"}
+var shadedFractionOptional = []string{"shape", "contiguous"}
+var unitConversionMandatory = []string{"quantity"}
+var unitConversionOptional = []string{"direction", "geq", "leq", "decimals"}
+var wordProblemMandatory = []string{"operator", "geq", "leq"}
+var wordProblemOptional = []string{"bank"}
+
+// The templates parsed from master files are cached and indexed by the name of
+// the master file (and the directory its partials are resolved from) so that
+// they are read and parsed only once
+var templateCache = make(map[string]*template.Template)
+var templateCacheMutex sync.Mutex
+
+// Besides the methods of master files, the following functions are registered
+// in their templates: "dict" allows the user to introduce in the text template
+// any arguments; "add", "sub" and "mul" compute the sum, difference and product
+// of integers, e.g., {{add $n 1}}; "seq" returns the integers from one to the
+// given number, or in the given interval, e.g., {{range seq 2 9}}; and "repeat"
+// repeats a text a number of times, e.g., {{repeat "\\quad" 3}}. Functions
+// drawing random values are registered also (see randomFuncs)
+var masterFuncs = template.FuncMap{
+	"dict": func(values ...interface{}) (map[string]interface{}, error) {
+
+		// if the number of items is not even (as many
+		// pairs of the form "Key" "Value" should be
+		// given) then an error is raised
+		if len(values)%2 != 0 {
+			return nil, errors.New("Invalid dict call. There should be an even number of arguments of the form 'Key' 'Value'")
+		}
+
+		// Create a map with as many elements as keys
+		// have been specified
+		dict := make(map[string]interface{}, len(values)/2)
+
+		// and process them
+		for i := 0; i < len(values); i += 2 {
+			key, ok := values[i].(string)
+			if !ok {
+				return nil, errors.New("Dict keys must be strings")
+			}
+			dict[key] = values[i+1]
+		}
+
+		// at this point no error has been reported, move therefore back
+		return dict, nil
+	},
+	"add": func(values ...interface{}) (int, error) {
+		return arithmetic("add", values, func(x, y int) int { return x + y })
+	},
+	"sub": func(values ...interface{}) (int, error) {
+		return arithmetic("sub", values, func(x, y int) int { return x - y })
+	},
+	"mul": func(values ...interface{}) (int, error) {
+		return arithmetic("mul", values, func(x, y int) int { return x * y })
+	},
+	"seq": func(values ...interface{}) ([]int, error) {
+
+		// either the upper bound or both bounds have to be given
+		if len(values) < 1 || len(values) > 2 {
+			return nil, errors.New("Invalid seq call. Either the last number or the first and last numbers should be given")
+		}
+		bounds := []int{1}
+		for _, value := range values {
+			bound, err := helpers.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid seq call. %v", err)
+			}
+			bounds = append(bounds, bound)
+		}
+		first, last := bounds[len(bounds)-2], bounds[len(bounds)-1]
+
+		var result []int
+		for i := first; i <= last; i++ {
+			result = append(result, i)
+		}
+		return result, nil
+	},
+	"repeat": func(text string, count interface{}) (string, error) {
+		n, err := helpers.Atoi(count)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("Invalid repeat call. The number of repetitions '%v' should be a non-negative integer", count)
+		}
+		return strings.Repeat(text, n), nil
+	},
+}
+
 // types
 // ----------------------------------------------------------------------------
 
 // A master file consists of an input filename that stores the
 // tempalte to fill in to generate the final sheet of exercises, and
 // an output tex filename. It also comes with other fields that can be
-// used for customizing the resulting file such as the student's name
+// used for customizing the resulting file such as the student's name.
+// Optionally, the solutions of all problems generated can be written in JSON
+// format to a sibling file with the suffix ".solutions.json", and an answer
+// key with the same problems and their solutions can be written to a sibling
+// file with the suffix ".answers.tex". A seed can be given so that the same
+// sheet is generated every time. If the seed is zero, then it is taken from the
+// current time. It can be also requested that all problems of the same sheet
+// are unique, in which case repeated problems are regenerated up to the given
+// number of attempts (MAXREPEATATTEMPTS if it is zero), problems whose
+// difficulty falls outside the given band are regenerated, and problems can be
+// numbered consecutively. The TeX files can be compiled into PDF files with the
+// given LaTeX engine and number of passes (see CompilePDF). Finally, existing
+// TeX files are re-numbered unless they have to be overwritten. When many
+// master files are processed at once, each one can be written to its own
+// output directory. Every student can be given a difficulty level and any
+// other parameters which can be used in the master file, e.g., for defining
+// basic operations, so that the same master file generates differentiated
+// sheets
 type MasterFile struct {
-	Infile  string
-	Name    string
-	Class   string
-	Outfile string
+	Infile         string
+	Name           string
+	Class          string
+	Level          int
+	Params         map[string]interface{}
+	Outfile        string
+	Outdir         string
+	Solutions      bool
+	Answers        bool
+	Seed           int64
+	Unique         bool
+	UniqueAttempts int
+	Difficulty     DifficultyBand
+	Numbered       bool
+	PDF            bool
+	LatexEngine    string
+	LatexPasses    int
+	Overwrite      bool
+
+	// number of partials currently being included with Include
+	includes int
+
+	// variables set in the master file with Set, which are shared with all the
+	// partials it includes
+	variables map[string]interface{}
+
+	// all problems are generated with the same source of random numbers, and
+	// they are recorded here while executing the template
+	recorder
 }
 
 // functions
@@ -57,6 +261,30 @@ func NewMasterFile(filename, name, class string) MasterFile {
 	return MasterFile{Infile: filename, Name: name, Class: class}
 }
 
+// return the result of applying the given operation to all the given values,
+// which are first converted into integers, from left to right. At least two
+// values have to be given. The name of the function is used for reporting
+// errors
+func arithmetic(name string, values []interface{}, operation func(x, y int) int) (int, error) {
+
+	if len(values) < 2 {
+		return 0, fmt.Errorf("Invalid %v call. At least two numbers should be given", name)
+	}
+	var result int
+	for idx, value := range values {
+		number, err := helpers.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid %v call. %v", name, err)
+		}
+		if idx == 0 {
+			result = number
+		} else {
+			result = operation(result, number)
+		}
+	}
+	return result, nil
+}
+
 // veryMandatoryArgs is kind of a helper but specific for processing mandatory
 // arguments of those commands given in text templates. It verifies that all
 // mandatory arguments given in args appear in the specified dictionary. If not,
@@ -70,6 +298,187 @@ func verifyMandatoryArgs(dict map[string]interface{}, args []string, operation s
 	return nil
 }
 
+// return the integers given either as one integer or as a list of integers,
+// e.g., the bounds of all operands or every operand. If it is not possible, an
+// error is returned
+func atoiList(value interface{}) ([]int, error) {
+
+	if number, err := helpers.Atoi(value); err == nil {
+		return []int{number}, nil
+	}
+	return helpers.AtoiSlice(value)
+}
+
+// return the digits excluded from operands with the key "excludedigits" and
+// the patterns they should match with the key "patterns" (see
+// digitConstraint) in the given dictionary of the specified operation. Digits
+// are given either as one integer or a list of integers, and patterns as a
+// comma-separated string. None are given by default
+func verifyDigitConstraintDict(dict map[string]interface{}, operation string) (excluded []int, patterns []string, err error) {
+
+	if _, ok := dict["excludedigits"]; ok {
+		if excluded, err = atoiList(dict["excludedigits"]); err != nil {
+			return nil, nil, fmt.Errorf("the excluded digits of a/an %v should be given either as an integer or a list of integers", operation)
+		}
+	}
+	if _, ok := dict["patterns"]; ok {
+		value, ok := dict["patterns"].(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("the patterns of the operands of a/an %v should be given as a comma-separated string", operation)
+		}
+		for _, pattern := range strings.Split(value, ",") {
+			patterns = append(patterns, strings.TrimSpace(pattern))
+		}
+	}
+	return
+}
+
+// return a valid specification of an angle problem with no error if all the
+// keys given in dict are correct for defining it. If not, an error is returned.
+// If an error is returned, the contents of the angle problem are undefined
+//
+// A dictionary is correct if and only if it correctly provides the type of the
+// problem with the key "type". Optionally, the measure of angles can be forced
+// to be a multiple of a number of degrees given with "step" (by default,
+// DEFAULTANGLESTEP), and a protractor can be drawn below the angle with
+// "protractor"
+func verifyAngleDict(dict map[string]interface{}) (angle, error) {
+
+	// the mandatory keys are given next
+	mandatory := angleMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), angleOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "angle"); err != nil {
+		return angle{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var antype int
+	if antype, err = helpers.Atoi(dict["type"]); err != nil {
+		return angle{}, errors.New("the type of an angle problem should be given as an integer")
+	}
+
+	// next, the step of the measures and whether a protractor is drawn
+	step, protractor := DEFAULTANGLESTEP, false
+	if _, ok := dict["step"]; ok {
+		if step, err = helpers.Atoi(dict["step"]); err != nil {
+			return angle{}, errors.New("the step of the measure of angles should be given as an integer")
+		}
+	}
+	if _, ok := dict["protractor"]; ok {
+		if protractor, err = helpers.Atob(dict["protractor"]); err != nil {
+			return angle{}, errors.New("the protractor of an angle problem should be given as a boolean")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := AngleOptions{
+		Type:       antype,
+		Step:       step,
+		Protractor: protractor,
+	}
+	if err := options.Validate(); err != nil {
+		return angle{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating an angle problem and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.angle(), nil
+}
+
+// return a valid specification of a bar chart problem with no error if all the
+// keys given in dict are correct for defining it. If not, an error is
+// returned. If an error is returned, the contents of the bar chart problem are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides the names of
+// all categories as a comma-separated string with the key "categories", and
+// the range of their values with "geq" and "leq". Optionally, the mode can be
+// given with "mode", either "read" (by default) or "draw", and the questions
+// to answer in "read" mode can be given as a comma-separated string with
+// "questions", among "value", "most", "least" and "difference" (all of them by
+// default)
+func verifyBarChartDict(dict map[string]interface{}) (barChart, error) {
+
+	// the mandatory keys are given next
+	mandatory := barChartMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), barChartOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "bar chart"); err != nil {
+		return barChart{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var value string
+	var geq, leq int
+	if value, ok = dict["categories"].(string); !ok {
+		return barChart{}, errors.New("the categories of a bar chart should be given as a comma-separated string")
+	}
+	var categories []string
+	for _, category := range strings.Split(value, ",") {
+		categories = append(categories, strings.TrimSpace(category))
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return barChart{}, errors.New("the lower bound of the values of a bar chart should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return barChart{}, errors.New("the upper bound of the values of a bar chart should be given as an integer")
+	}
+
+	// next, the mode and the questions
+	mode := BCREAD
+	questions := []string{BCVALUE, BCMOST, BCLEAST, BCDIFFERENCE}
+	if _, ok = dict["mode"]; ok {
+		if mode, ok = dict["mode"].(string); !ok {
+			return barChart{}, errors.New("the mode of a bar chart should be given as a string")
+		}
+	}
+	if _, ok = dict["questions"]; ok {
+		if value, ok = dict["questions"].(string); !ok {
+			return barChart{}, errors.New("the questions of a bar chart should be given as a comma-separated string")
+		}
+		questions = []string{}
+		for _, question := range strings.Split(value, ",") {
+			questions = append(questions, strings.TrimSpace(question))
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := BarChartOptions{
+		Categories: categories,
+		Geq:        geq,
+		Leq:        leq,
+		Mode:       mode,
+		Questions:  questions,
+	}
+	if err := options.Validate(); err != nil {
+		return barChart{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a bar chart and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.barChart(), nil
+}
+
 // return a valid specification of a basic operation with no error if all the
 // keys given in dict are correct for defining a basic sequence. If not, an
 // error is returned. If an error is returned, the contents of the basic
@@ -77,11 +486,48 @@ func verifyMandatoryArgs(dict map[string]interface{}, args []string, operation s
 //
 // A dictionary is correct if and only if it correctly provides a type of basic
 // operation with the keyword "type", a number of digits of the operands, and
-// the result, and the number of operands to show.
+// the result, and the number of operands to show. Optionally, it can be
+// requested to show boxes for recording borrows in subtractions with the key
+// "scaffold", and for writing carries in additions with the key "carrybox".
+// Carries and borrows can be either required in at least one column or
+// forbidden with the key "carry", given either as "any", "required" and
+// "forbidden" or as a bool that allows them or not, and operands can
+// be given with a number of decimal digits with "nbdecimals". Negative operands
+// and results are allowed with the key "allownegative", and the operation can
+// be shown in one single line with the key "layout". Operands can be bounded
+// with "geq" and "leq", either with one integer for all operands or a list
+// with one integer for every operand, and their digits can be constrained with
+// "excludedigits" and "patterns" (see digitConstraint). Operands can have a
+// different number of digits if "nbdigitsop" is given as a list with one
+// integer for every operand. Alternatively, a
+// difficulty level can be given with the key "level" (see Level), in which
+// case the mandatory keys not given in dict are taken from it
 func verifyBasicOperationDict(dict map[string]interface{}) (basicOperation, error) {
 
 	// the mandatory keys are given next
-	mandatory := []string{"type", "operator", "nboperands", "nbdigitsop", "nbdigitsrslt"}
+	mandatory := basicOperationMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), basicOperationOptional...)
+
+	// if a level was given, then take from it all the keys not given in the
+	// dictionary
+	if _, ok := dict["level"]; ok {
+		number, err := helpers.Atoi(dict["level"])
+		if err != nil {
+			return basicOperation{}, errors.New("the level of a basic operation should be given as an integer")
+		}
+		level, ok := GetLevel(number)
+		if !ok {
+			return basicOperation{}, fmt.Errorf("the level '%v' of a basic operation is not defined", number)
+		}
+		leveled := level.dict()
+		for key, value := range dict {
+			leveled[key] = value
+		}
+		dict = leveled
+	}
 
 	// now, verify that all mandatory parameters are present in the dict
 	if err := verifyMandatoryArgs(dict, mandatory, "basic operation"); err != nil {
@@ -92,14 +538,10 @@ func verifyBasicOperationDict(dict map[string]interface{}) (basicOperation, erro
 	var ok bool
 	var err error
 	var operator string
-	var botype, nboperands, nbdigitsop, nbdigitsrslt int
+	var botype, nboperands, nbdigitsrslt int
+	var nbdigitsop []int
 	if operator, ok = dict["operator"].(string); !ok {
 		return basicOperation{}, errors.New("The operator of a basic operation should be given as a stirng")
-	} else {
-		operators := []string{"+", "-", "*", "/"}
-		if !helpers.Find(operator, operators) {
-			return basicOperation{}, errors.New("The operator of a basic operation has to be one and only one among the following: '+', '-', '*' or '/'")
-		}
 	}
 	if botype, err = helpers.Atoi(dict["type"]); err != nil {
 		return basicOperation{}, errors.New("the type of a basic operation should be given as an integer")
@@ -107,41 +549,148 @@ func verifyBasicOperationDict(dict map[string]interface{}) (basicOperation, erro
 	if nboperands, err = helpers.Atoi(dict["nboperands"]); err != nil {
 		return basicOperation{}, errors.New("the number of operands in a basic operation should be given as an integer")
 	}
-	if nbdigitsop, err = helpers.Atoi(dict["nbdigitsop"]); err != nil {
-		return basicOperation{}, errors.New("the number of digits of all operands should be given as an integer")
+	if nbdigitsop, err = atoiList(dict["nbdigitsop"]); err != nil || len(nbdigitsop) == 0 {
+		return basicOperation{}, errors.New("the number of digits of the operands should be given either as an integer or a list of integers")
 	}
 	if nbdigitsrslt, err = helpers.Atoi(dict["nbdigitsrslt"]); err != nil {
 		return basicOperation{}, errors.New("the number of digits of the result of a basic operation should be given as a string")
 	}
 
-	// finally, ensure the type is correct
-	if botype < BORESULT || botype > BOOPERAND {
-		return basicOperation{}, fmt.Errorf("the type of a basic operation given '%v' is incorrect", botype)
+	// next, check whether the scaffold for recording borrows was requested or
+	// not. By default, it is not shown
+	var scaffold bool
+	if _, ok := dict["scaffold"]; ok {
+		if scaffold, err = helpers.Atob(dict["scaffold"]); err != nil {
+			return basicOperation{}, errors.New("the flag for showing the scaffold of a basic operation should be given as a bool")
+		}
+	}
+
+	// and the same for the boxes for writing carries
+	var carrybox bool
+	if _, ok := dict["carrybox"]; ok {
+		if carrybox, err = helpers.Atob(dict["carrybox"]); err != nil {
+			return basicOperation{}, errors.New("the flag for showing the carry boxes of a basic operation should be given as a bool")
+		}
+	}
+
+	// and whether carries (and borrows) are required, forbidden or allowed in
+	// any column, which is the default. For backwards compatibility, a bool
+	// can be given as well which either allows or forbids them
+	carry := BOCARRYANY
+	if value, ok := dict["carry"]; ok {
+		if carry, ok = value.(string); !ok || !helpers.Find(carry, []string{BOCARRYANY, BOCARRYREQUIRED, BOCARRYFORBIDDEN}) {
+			allowed, err := helpers.Atob(value)
+			if err != nil {
+				return basicOperation{}, fmt.Errorf("the carries of a basic operation should be given either as '%v', '%v', '%v' or as a bool",
+					BOCARRYANY, BOCARRYREQUIRED, BOCARRYFORBIDDEN)
+			}
+			carry = BOCARRYANY
+			if !allowed {
+				carry = BOCARRYFORBIDDEN
+			}
+		}
+	}
+
+	// and the number of decimal digits of the operands, which are integers by
+	// default
+	var nbdecimals int
+	if _, ok := dict["nbdecimals"]; ok {
+		if nbdecimals, err = helpers.Atoi(dict["nbdecimals"]); err != nil {
+			return basicOperation{}, errors.New("the number of decimal digits of a basic operation should be given as an integer")
+		}
+	}
+
+	// and whether negative numbers are allowed or not. By default, they are not
+	var allownegative bool
+	if _, ok := dict["allownegative"]; ok {
+		if allownegative, err = helpers.Atob(dict["allownegative"]); err != nil {
+			return basicOperation{}, errors.New("the flag for allowing negative numbers in a basic operation should be given as a bool")
+		}
+	}
+
+	// and the layout, which is vertical by default
+	layout := BOVERTICAL
+	if _, ok := dict["layout"]; ok {
+		if layout, ok = dict["layout"].(string); !ok {
+			return basicOperation{}, errors.New("the layout of a basic operation should be given as a string")
+		}
+	}
+
+	// and the bounds of the operands, which are not bounded by default
+	var geq, leq []int
+	if _, ok := dict["geq"]; ok {
+		if geq, err = atoiList(dict["geq"]); err != nil {
+			return basicOperation{}, errors.New("the lower bounds of the operands of a basic operation should be given either as an integer or a list of integers")
+		}
+	}
+	if _, ok := dict["leq"]; ok {
+		if leq, err = atoiList(dict["leq"]); err != nil {
+			return basicOperation{}, errors.New("the upper bounds of the operands of a basic operation should be given either as an integer or a list of integers")
+		}
+	}
+
+	// and the constraints on the digits of the operands
+	excluded, patterns, err := verifyDigitConstraintDict(dict, "basic operation")
+	if err != nil {
+		return basicOperation{}, err
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := BasicOperationOptions{
+		Type:          botype,
+		Operator:      operator,
+		NbOperands:    nboperands,
+		NbDigitsOp:    nbdigitsop[0],
+		NbDigitsRslt:  nbdigitsrslt,
+		Scaffold:      scaffold,
+		CarryBox:      carrybox,
+		Carry:         carry,
+		NbDecimals:    nbdecimals,
+		AllowNegative: allownegative,
+		Layout:        layout,
+		Geq:           geq,
+		Leq:           leq,
+		ExcludeDigits: excluded,
+		Patterns:      patterns,
+	}
+	if len(nbdigitsop) > 1 {
+		options.NbDigitsOps = nbdigitsop
+	}
+	if err := options.Validate(); err != nil {
+		return basicOperation{}, err
 	}
 
 	// next, verify if there are some unnecessary parameters
-	if ok, key := helpers.VerifyKeys(dict, mandatory); !ok {
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
 		log.Printf("Warning: The key '%v' is not necessary for creating a basic operation and it will be ignored", key)
 	}
 
 	// otherwise, the dictionary is correct
-	return basicOperation{
-		botype:       botype,
-		operator:     operator,
-		nboperands:   nboperands,
-		nbdigitsop:   nbdigitsop,
-		nbdigitsrslt: nbdigitsrslt,
-	}, nil
+	return options.basicOperation(), nil
 }
 
 // verify that the keys given in dict are correct for defining
 // divisions. A dictionary is correct if and only if all the mandatory
 // arguments have been given. If not, an error is raised and execution
-// is aborted. Unnecessary keys are reported
+// is aborted. Unnecessary keys are reported. Optionally, it can be requested
+// to show the step-by-step scaffold of the long division with the key
+// "extended", and the layout of the division can be given with "style".
+// Divisions can be forced to be exact or to have a strictly positive remainder
+// with "remainder", and a box for writing the remainder can be requested with
+// "remainderbox". The quotient can be computed with a number of decimals given
+// with "nbdecimals", and divisors with two digits or more can be shown along
+// with the table of their multiples with "table". Finally, the dividend can be
+// bounded with "dvgeq" and "dvleq", and the divisor with "drgeq" and "drleq",
+// and the digits of both can be constrained with "excludedigits" and
+// "patterns" (see digitConstraint)
 func verifyDivisionDict(dict map[string]interface{}) (division, error) {
 
 	// the mandatory keys are given next
-	mandatory := []string{"nbdvdigits", "nbdrdigits", "nbqdigits"}
+	mandatory := divisionMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), divisionOptional...)
 
 	// now, verify that all mandatory parameters are present in the dict
 	if err := verifyMandatoryArgs(dict, mandatory, "division"); err != nil {
@@ -161,378 +710,3469 @@ func verifyDivisionDict(dict map[string]interface{}) (division, error) {
 		return division{}, errors.New("the number of digits of the quotient should be given as an integer")
 	}
 
+	// the quotient is computed with no decimals unless otherwise requested
+	var nbdecimals int
+	if _, ok := dict["nbdecimals"]; ok {
+		if nbdecimals, err = helpers.Atoi(dict["nbdecimals"]); err != nil {
+			return division{}, errors.New("the number of decimals of the quotient should be given as an integer")
+		}
+	}
+
+	// next, check whether the extended layout was requested or not. By
+	// default, only the dividend, divisor and the box for the quotient are
+	// shown
+	var extended bool
+	if _, ok := dict["extended"]; ok {
+		if extended, err = helpers.Atob(dict["extended"]); err != nil {
+			return division{}, errors.New("the flag for showing the extended layout of a division should be given as a bool")
+		}
+	}
+
+	// and also the layout of the division. By default, the one of the current
+	// locale is used
+	var style string
+	if _, ok := dict["style"]; ok {
+		if style, ok = dict["style"].(string); !ok {
+			return division{}, errors.New("the style of a division should be given as a string")
+		}
+	}
+
+	// the remainder is random unless it is requested otherwise, and no box is
+	// shown for writing it by default
+	var remainder string
+	if _, ok := dict["remainder"]; ok {
+		if remainder, ok = dict["remainder"].(string); !ok {
+			return division{}, errors.New("the remainder of a division should be given as a string")
+		}
+	}
+	var remainderbox bool
+	if _, ok := dict["remainderbox"]; ok {
+		if remainderbox, err = helpers.Atob(dict["remainderbox"]); err != nil {
+			return division{}, errors.New("the flag for showing the box of the remainder of a division should be given as a bool")
+		}
+	}
+
+	// likewise, the table of multiples of the divisor is not shown by default
+	var table bool
+	if _, ok := dict["table"]; ok {
+		if table, err = helpers.Atob(dict["table"]); err != nil {
+			return division{}, errors.New("the flag for showing the table of multiples of the divisor should be given as a bool")
+		}
+	}
+
+	// and the bounds of the dividend and the divisor, which are not bounded by
+	// default
+	bounds := make(map[string]int)
+	for _, key := range []string{"dvgeq", "dvleq", "drgeq", "drleq"} {
+		if _, ok := dict[key]; ok {
+			if bounds[key], err = helpers.Atoi(dict[key]); err != nil {
+				return division{}, fmt.Errorf("the bound '%v' of a division should be given as an integer", key)
+			}
+		}
+	}
+	excluded, patterns, err := verifyDigitConstraintDict(dict, "division")
+	if err != nil {
+		return division{}, err
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := DivisionOptions{
+		NbDvDigits:    nbdvdigits,
+		NbDrDigits:    nbdrdigits,
+		NbQDigits:     nbqdigits,
+		NbDecimals:    nbdecimals,
+		Extended:      extended,
+		Style:         style,
+		Remainder:     remainder,
+		RemainderBox:  remainderbox,
+		Table:         table,
+		DvGeq:         bounds["dvgeq"],
+		DvLeq:         bounds["dvleq"],
+		DrGeq:         bounds["drgeq"],
+		DrLeq:         bounds["drleq"],
+		ExcludeDigits: excluded,
+		Patterns:      patterns,
+	}
+	if err := options.Validate(); err != nil {
+		return division{}, err
+	}
+
 	// next, verify if there are some unnecessary parameters
-	if ok, key := helpers.VerifyKeys(dict, mandatory); !ok {
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
 		log.Printf("Warning: The key '%v' is not necessary for creating a division and it will be ignored", key)
 	}
 
 	// now, return the proper definition of a division problem
-	return division{
-		nbdvdigits: nbdvdigits,
-		nbdrdigits: nbdrdigits,
-		nbqdigits:  nbqdigits,
-	}, nil
+	return options.division(), nil
 }
 
-// return a valid specification of a mystery operation with no error if all the
-// keys given in dict are correct for defining a Mystery Operation. If not, an
-// error is returned. If an error is returned, the contents of the Mystery
-// Operation are undefined.
+// return a valid specification of a clock with no error if all the keys given
+// in dict are correct for defining clocks. If not, an error is returned. If an
+// error is returned, the contents of the clock are undefined
 //
-// A dictionary is correct if and only if correctly provides an operation (+, -,
-// /, *) and a number of digits for each operand and a number of masked digits
-// for each of them as well. The operator is provided through the key "operator"
-// and the number of digits and masked digits of each operand are given with the
-// keys "nbdigitsi" and "nbmaskedi" respectively, where i takes the values 1 and
-// 2 only. The number of digits of the result and the number of masked digits
-// are given with "nbdigitsanswer" and "nbmaskedanswer" respectively
-func verifyMysteryOperationDict(dict map[string]interface{}) (mysteryOperation, error) {
+// A dictionary is correct if and only if it correctly provides a type of clock
+// problem with the keyword "type", and the granularity of the minutes with
+// "granularity", one among "hour", "half", "quarter", "five" and "minute"
+func verifyClockDict(dict map[string]interface{}) (clock, error) {
 
 	// the mandatory keys are given next
-	mandatory := []string{
-		"nbdigits1", "nbmasked1",
-		"nbdigits2", "nbmasked2",
-		"nbdigitsanswer", "nbmaskedanswer",
-		"operator"}
+	mandatory := clockMandatory
 
 	// now, verify that all mandatory parameters are present in the dict
-	if err := verifyMandatoryArgs(dict, mandatory, "mystery operation"); err != nil {
-		return mysteryOperation{}, err
+	if err := verifyMandatoryArgs(dict, mandatory, "clock"); err != nil {
+		return clock{}, err
 	}
 
 	// make also sure that parameters are given with the right type
 	var ok bool
 	var err error
-	var operator string
-	var nbdigits1, nbdigits2 int
-	var nbmasked1, nbmasked2 int
-	var nbdigitsanswer, nbmaskedanswer int
-	if operator, ok = dict["operator"].(string); !ok {
-		return mysteryOperation{}, errors.New("The operator of a mystery operation should be given as a stirng")
-	} else {
-		operators := []string{"+", "-", "*", "/"}
-		if !helpers.Find(operator, operators) {
-			return mysteryOperation{}, errors.New("The operator of a mystery operation has to be one and only one among the following: '+', '-', '*' or '/'")
-		}
-	}
-	if nbdigits1, err = helpers.Atoi(dict["nbdigits1"]); err != nil {
-		return mysteryOperation{}, errors.New("the number of digits of the first operand should be given as a integer")
-	}
-	if nbdigits2, err = helpers.Atoi(dict["nbdigits2"]); err != nil {
-		return mysteryOperation{}, errors.New("the number of digits of the second operand should be given as a integer")
+	var clocktype int
+	var granularity string
+	if clocktype, err = helpers.Atoi(dict["type"]); err != nil {
+		return clock{}, errors.New("the type of a clock should be given as an integer")
 	}
-	if nbmasked1, err = helpers.Atoi(dict["nbmasked1"]); err != nil {
-		return mysteryOperation{}, errors.New("the number of masked digits of the first operand should be given as a integer")
-	}
-	if nbmasked2, err = helpers.Atoi(dict["nbmasked2"]); err != nil {
-		return mysteryOperation{}, errors.New("the number of masked digits of the second operand should be given as a integer")
+	if granularity, ok = dict["granularity"].(string); !ok {
+		return clock{}, errors.New("the granularity of a clock should be given as a string")
 	}
-	if nbdigitsanswer, err = helpers.Atoi(dict["nbdigitsanswer"]); err != nil {
-		return mysteryOperation{}, errors.New("the number of digits of the answer should be given as a integer")
+
+	// convert the dictionary into typed options and verify them
+	options := ClockOptions{
+		Type:        clocktype,
+		Granularity: granularity,
 	}
-	if nbmaskedanswer, err = helpers.Atoi(dict["nbmaskedanswer"]); err != nil {
-		return mysteryOperation{}, errors.New("the number of masked digits of the answer should be given as a integer")
+	if err := options.Validate(); err != nil {
+		return clock{}, err
 	}
 
 	// next, verify if there are some unnecessary parameters
 	if ok, key := helpers.VerifyKeys(dict, mandatory); !ok {
-		log.Printf("Warning: The key '%v' is not necessary for creating a mystery operation and it will be ignored", key)
+		log.Printf("Warning: The key '%v' is not necessary for creating a clock and it will be ignored", key)
 	}
 
-	// now, return the proper definition of a mystery operation problem
-	return mysteryOperation{
-		nbdigits1:      nbdigits1,
-		nbdigits2:      nbdigits2,
-		nbmasked1:      nbmasked1,
-		nbmasked2:      nbmasked2,
-		nbdigitsanswer: nbdigitsanswer,
-		nbmaskedanswer: nbmaskedanswer,
-		operator:       operator,
-	}, nil
+	// otherwise, the dictionary is correct
+	return options.clock(), nil
 }
 
-// return a valid specification of a multiplication table with no error if all
-// the keys given in dict are correct for defining a multiplication table. If
-// not, an error is returned. If an error is returned, the contents of the
-// multiplication able are undefined
+// return a valid specification of a counting problem with no error if all the
+// keys given in dict are correct for defining it. If not, an error is returned.
+// If an error is returned, the contents of the counting problem are undefined
 //
-// A dictionary is correct if and only if it correctly provides a type of
-// multiplication table with the keyword "type", and a number of digits for
-// randomly choosing the factor. Other optional parameters are the lower and
-// upper bound (which by default take the values 1 and 10 respectively), and
-// whether rows are shown in the regular order or inverted with the keyword
-// "inv" whose value can be either "true" or "false", and also whether the rows
-// are sorted or not with the keyword "sorted" whose only allowed values are
-// either "true" or "false".
-func verifyMultiplicationTableDict(dict map[string]interface{}) (multiplicationTable, error) {
+// A dictionary is correct if and only if it correctly provides the range of the
+// number of objects to count with the keys "geq" and "leq". Optionally, the
+// symbol used for drawing the objects can be given with "symbol", either
+// "star" (by default), "circle" or "apple", and objects can be shown in groups
+// of five with "grouped" (false by default)
+func verifyCountingObjectsDict(dict map[string]interface{}) (countingObjects, error) {
 
 	// the mandatory keys are given next
-	mandatory := []string{"type", "nbdigits"}
+	mandatory := countingObjectsMandatory
 
-	// all acknowledged options (including those that are optiona) are listed
+	// all acknowledged options (including those that are optional) are listed
 	// next
-	all := []string{"type", "nbdigits", "geq", "leq", "inv", "sorted"}
+	all := append(append([]string{}, mandatory...), countingObjectsOptional...)
 
 	// now, verify that all mandatory parameters are present in the dict
-	if err := verifyMandatoryArgs(dict, mandatory, "multiplication table"); err != nil {
-		return multiplicationTable{}, err
+	if err := verifyMandatoryArgs(dict, mandatory, "counting problem"); err != nil {
+		return countingObjects{}, err
 	}
 
-	// make also sure that all mandatory parameters are given with the right type
+	// make also sure that parameters are given with the right type
 	var ok bool
 	var err error
-	var mttype, nbdigits int
-	if mttype, err = helpers.Atoi(dict["type"]); err != nil {
-		return multiplicationTable{}, errors.New("the type of a multiplication table should be given as an integer")
+	var geq, leq int
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return countingObjects{}, errors.New("the lower bound of the number of objects should be given as an integer")
 	}
-	if nbdigits, err = helpers.Atoi(dict["nbdigits"]); err != nil {
-		return multiplicationTable{}, errors.New("the number of digits of the factor in a multiplication table should be given as an integer")
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return countingObjects{}, errors.New("the upper bound of the number of objects should be given as an integer")
 	}
 
-	// next, check whether some optional parameters were given or not. If not,
-	// make sure they take their default values
-	geq, leq := 1, 10
-	inv, sorted := false, true
-
-	// geq and leq are integer optional parameters
-	if _, ok = dict["geq"]; ok {
-		if geq, err = helpers.Atoi(dict["geq"]); err != nil {
-			return multiplicationTable{}, errors.New("the lower bound of a multiplication table should be given as an integer")
+	// next, the symbol used for drawing objects and whether they are grouped
+	// or not
+	symbol, grouped := components.SYMBOLSTAR, false
+	if _, ok = dict["symbol"]; ok {
+		if symbol, ok = dict["symbol"].(string); !ok {
+			return countingObjects{}, errors.New("the symbol of a counting problem should be given as a string")
 		}
 	}
-	if _, ok = dict["leq"]; ok {
-		if leq, err = helpers.Atoi(dict["leq"]); err != nil {
-			return multiplicationTable{}, errors.New("the upper bound of a multiplication table should be given as an integer")
+	if _, ok = dict["grouped"]; ok {
+		if grouped, err = helpers.Atob(dict["grouped"]); err != nil {
+			return countingObjects{}, errors.New("the flag for grouping objects in a counting problem should be given as a bool")
 		}
 	}
 
-	// inv and sorted are boolean optional parameters
-	if _, ok = dict["inv"]; ok {
-		if inv, err = helpers.Atob(dict["inv"]); err != nil {
-			return multiplicationTable{}, errors.New("the 'inv' flag should be given as a bool")
+	// convert the dictionary into typed options and verify them
+	options := CountingObjectsOptions{
+		Geq:     geq,
+		Leq:     leq,
+		Symbol:  symbol,
+		Grouped: grouped,
+	}
+	if err := options.Validate(); err != nil {
+		return countingObjects{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a counting problem and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.countingObjects(), nil
+}
+
+// return a valid specification of an elapsed time problem with no error if all
+// the keys given in dict are correct for defining elapsed time problems. If
+// not, an error is returned. If an error is returned, the contents of the
+// elapsed time problem are undefined
+//
+// A dictionary is correct if and only if it correctly provides a type of
+// elapsed time problem with the keyword "type", and the granularity of the
+// minutes with "granularity", one among "hour", "half", "quarter", "five" and
+// "minute". Optionally, the earliest start and the latest end of events can be
+// given as "hh:mm" with "from" and "to" (by default, "00:00" and "23:59"), and
+// their maximum duration in minutes with "maxduration" (180 by default)
+func verifyElapsedTimeDict(dict map[string]interface{}) (elapsedTime, error) {
+
+	// the mandatory keys are given next
+	mandatory := elapsedTimeMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), elapsedTimeOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "elapsed time problem"); err != nil {
+		return elapsedTime{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var ettype int
+	var granularity string
+	if ettype, err = helpers.Atoi(dict["type"]); err != nil {
+		return elapsedTime{}, errors.New("the type of an elapsed time problem should be given as an integer")
+	}
+	if granularity, ok = dict["granularity"].(string); !ok {
+		return elapsedTime{}, errors.New("the granularity of an elapsed time problem should be given as a string")
+	}
+
+	// next, check whether the range of times and the maximum duration were
+	// given or not
+	from, to := DEFAULTELAPSEDFROM, DEFAULTELAPSEDTO
+	if _, ok = dict["from"]; ok {
+		if from, ok = dict["from"].(string); !ok {
+			return elapsedTime{}, errors.New("the earliest start of an elapsed time problem should be given as a string")
 		}
 	}
-	if _, ok = dict["sorted"]; ok {
-		if sorted, err = helpers.Atob(dict["sorted"]); err != nil {
-			return multiplicationTable{}, errors.New("the 'sorted' flag should be given as a bool")
+	if _, ok = dict["to"]; ok {
+		if to, ok = dict["to"].(string); !ok {
+			return elapsedTime{}, errors.New("the latest end of an elapsed time problem should be given as a string")
+		}
+	}
+	maxduration := DEFAULTELAPSEDMAXDURATION
+	if _, ok = dict["maxduration"]; ok {
+		if maxduration, err = helpers.Atoi(dict["maxduration"]); err != nil {
+			return elapsedTime{}, errors.New("the maximum duration of an elapsed time problem should be given as an integer")
 		}
 	}
 
-	// finally, ensure the type is correct
-	if mttype < MTRESULT || mttype > MTOPERAND {
-		return multiplicationTable{}, fmt.Errorf("the type of a multiplication table given '%v' is incorrect", mttype)
+	// convert the dictionary into typed options and verify them
+	options := ElapsedTimeOptions{
+		Type:        ettype,
+		Granularity: granularity,
+		From:        from,
+		To:          to,
+		MaxDuration: maxduration,
+	}
+	if err := options.Validate(); err != nil {
+		return elapsedTime{}, err
 	}
 
 	// next, verify if there are some unnecessary parameters
 	if ok, key := helpers.VerifyKeys(dict, all); !ok {
-		log.Printf("Warning: The key '%v' is not necessary for creating a multiplication table and it will be ignored", key)
+		log.Printf("Warning: The key '%v' is not necessary for creating an elapsed time problem and it will be ignored", key)
 	}
 
 	// otherwise, the dictionary is correct
-	return multiplicationTable{
-		mttype:   mttype,
-		nbdigits: nbdigits,
-		geq:      geq,
-		leq:      leq,
-		inv:      inv,
-		sorted:   sorted,
-	}, nil
+	return options.elapsedTime(), nil
 }
 
-// return a valid specification of a sequence with no error if all the keys
-// given in dict are correct for defining a sequence. If not, an error is
-// returned. If an error is returned, the contents of the sequence are
-// undetermined
+// return a valid specification of an equivalent fraction with no error if all
+// the keys given in dict are correct for defining equivalent fractions. If not,
+// an error is returned. If an error is returned, the contents of the equivalent
+// fraction are undefined
 //
 // A dictionary is correct if and only if it correctly provides a type of
-// sequence with the keyword "type", a number of items with the keyword
-// "nbitems", and a lower and upper bound with "geq" and "leq"
-func verifySequenceDict(dict map[string]interface{}) (sequence, error) {
+// equivalent fraction with the keyword "type", the lower and upper bound of the
+// denominator of the base fraction with "dengeq" and "denleq", and the lower
+// and upper bound of the scale factor with "scalegeq" and "scaleleq"
+func verifyEquivalentFractionDict(dict map[string]interface{}) (equivalentFraction, error) {
 
 	// the mandatory keys are given next
-	mandatory := []string{"type", "nbitems", "geq", "leq"}
+	mandatory := equivalentFractionMandatory
 
 	// now, verify that all mandatory parameters are present in the dict
-	if err := verifyMandatoryArgs(dict, mandatory, "sequence"); err != nil {
-		return sequence{}, err
+	if err := verifyMandatoryArgs(dict, mandatory, "equivalent fraction"); err != nil {
+		return equivalentFraction{}, err
 	}
 
 	// make also sure that parameters are given with the right type
 	var err error
-	var seqtype, nbitems, geq, leq int
-	if seqtype, err = helpers.Atoi(dict["type"]); err != nil {
-		return sequence{}, errors.New("the type of a sequence should be given as an integer")
+	var eftype, dengeq, denleq, scalegeq, scaleleq int
+	if eftype, err = helpers.Atoi(dict["type"]); err != nil {
+		return equivalentFraction{}, errors.New("the type of an equivalent fraction should be given as an integer")
 	}
-	if nbitems, err = helpers.Atoi(dict["nbitems"]); err != nil {
-		return sequence{}, errors.New("the number of items in a sequence should be given as an integer")
+	if dengeq, err = helpers.Atoi(dict["dengeq"]); err != nil {
+		return equivalentFraction{}, errors.New("the lower bound of the denominator should be given as an integer")
+	}
+	if denleq, err = helpers.Atoi(dict["denleq"]); err != nil {
+		return equivalentFraction{}, errors.New("the upper bound of the denominator should be given as an integer")
+	}
+	if scalegeq, err = helpers.Atoi(dict["scalegeq"]); err != nil {
+		return equivalentFraction{}, errors.New("the lower bound of the scale factor should be given as an integer")
+	}
+	if scaleleq, err = helpers.Atoi(dict["scaleleq"]); err != nil {
+		return equivalentFraction{}, errors.New("the upper bound of the scale factor should be given as an integer")
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := EquivalentFractionOptions{
+		Type:     eftype,
+		DenGeq:   dengeq,
+		DenLeq:   denleq,
+		ScaleGeq: scalegeq,
+		ScaleLeq: scaleleq,
+	}
+	if err := options.Validate(); err != nil {
+		return equivalentFraction{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, mandatory); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating an equivalent fraction and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.equivalentFraction(), nil
+}
+
+// return a valid specification of a fact family with no error if all the keys
+// given in dict are correct for defining fact families. If not, an error is
+// returned. If an error is returned, the contents of the fact family are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides the operator
+// with the keyword "operator", either "+" or "*", and the lower and upper bound
+// of the numbers operated with "geq" and "leq". Optionally, whether the related
+// facts have to be written below the triangle or not can be given with "facts"
+// (true by default)
+func verifyFactFamilyDict(dict map[string]interface{}) (factFamily, error) {
+
+	// the mandatory keys are given next
+	mandatory := factFamilyMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), factFamilyOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "fact family"); err != nil {
+		return factFamily{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var operator string
+	var geq, leq int
+	if operator, ok = dict["operator"].(string); !ok {
+		return factFamily{}, errors.New("the operator of a fact family should be given as a string")
 	}
 	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
-		return sequence{}, errors.New("the lower bound of a sequence should be given as an integer")
+		return factFamily{}, errors.New("the lower bound of the numbers of a fact family should be given as an integer")
 	}
 	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
-		return sequence{}, errors.New("the upper bound of a sequence should be given as a string")
+		return factFamily{}, errors.New("the upper bound of the numbers of a fact family should be given as an integer")
 	}
 
-	// finally, ensure the type is correct
-	if seqtype < SEQNONE || seqtype > SEQBOTH {
-		return sequence{}, fmt.Errorf("the type of a sequence given '%v' is incorrect", seqtype)
+	// next, check whether the related facts have to be shown or not
+	facts := true
+	if _, ok = dict["facts"]; ok {
+		if facts, err = helpers.Atob(dict["facts"]); err != nil {
+			return factFamily{}, errors.New("the flag for showing the facts of a fact family should be given as a bool")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := FactFamilyOptions{
+		Operator:  operator,
+		Geq:       geq,
+		Leq:       leq,
+		HideFacts: !facts,
+	}
+	if err := options.Validate(); err != nil {
+		return factFamily{}, err
 	}
 
 	// next, verify if there are some unnecessary parameters
-	if ok, key := helpers.VerifyKeys(dict, mandatory); !ok {
-		log.Printf("Warning: The key '%v' is not necessary for creating a sequence and it will be ignored", key)
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a fact family and it will be ignored", key)
 	}
 
 	// otherwise, the dictionary is correct
-	return sequence{
-		seqtype: seqtype,
-		nbitems: nbitems,
-		geq:     geq,
-		leq:     leq,
-	}, nil
+	return options.factFamily(), nil
 }
 
-// methods
-// ----------------------------------------------------------------------------
+// return a valid specification of a conversion between fractions, decimals and
+// percentages with no error if all the keys given in dict are correct for
+// defining conversions. If not, an error is returned. If an error is returned,
+// the contents of the conversion are undefined
+//
+// A dictionary is correct if and only if it correctly provides the form of the
+// value to convert with the keyword "from" and the form it has to be converted
+// to with "to", both among "fraction", "decimal" and "percent", and necessarily
+// different. Optionally, the largest denominator of the fractions used can be
+// given with "denleq" (by default, 20)
+func verifyFDPConversionDict(dict map[string]interface{}) (fdpConversion, error) {
 
-// -- MasterFile
-// ----------------------------------------------------------------------------
+	// the mandatory keys are given next
+	mandatory := fdpConversionMandatory
 
-// Return the input filename that shall store the template file to
-// generate the exercises
-func (masterFile MasterFile) GetInfile() string {
-	return masterFile.Infile
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), fdpConversionOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "conversion"); err != nil {
+		return fdpConversion{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var from, to string
+	if from, ok = dict["from"].(string); !ok {
+		return fdpConversion{}, errors.New("the form of the value to convert should be given as a string")
+	}
+	if to, ok = dict["to"].(string); !ok {
+		return fdpConversion{}, errors.New("the form of the converted value should be given as a string")
+	}
+
+	// next, check whether the largest denominator was given or not
+	denleq := 20
+	if _, ok = dict["denleq"]; ok {
+		if denleq, err = helpers.Atoi(dict["denleq"]); err != nil {
+			return fdpConversion{}, errors.New("the upper bound of the denominator should be given as an integer greater or equal than 2")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := FDPConversionOptions{
+		From:   from,
+		To:     to,
+		DenLeq: denleq,
+	}
+	if err := options.Validate(); err != nil {
+		return fdpConversion{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a conversion and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.fdpConversion(), nil
 }
 
-// Return the student's name of this master file
-func (masterFile MasterFile) GetName() string {
-	return masterFile.Name
+// return a valid specification of grid paper with no error if all the keys
+// given in dict are correct for defining grid paper. If not, an error is
+// returned. If an error is returned, the contents of the grid paper are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides the side of each
+// cell in centimeters with the keyword "step", the number of columns and rows
+// with "cols" and "rows", and the style of the paper with "style" which can be
+// either "square" or "lined". Optionally, a margin in centimeters can be given
+// with the keyword "margin"
+func verifyGridPaperDict(dict map[string]interface{}) (gridPaper, error) {
+
+	// the mandatory keys are given next
+	mandatory := gridPaperMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), gridPaperOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "grid paper"); err != nil {
+		return gridPaper{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var style string
+	var step float64
+	var cols, rows int
+	if step, err = helpers.Atof(dict["step"]); err != nil {
+		return gridPaper{}, errors.New("the step of grid paper should be given as a strictly positive number")
+	}
+	if cols, err = helpers.Atoi(dict["cols"]); err != nil {
+		return gridPaper{}, errors.New("the number of columns of grid paper should be given as a strictly positive integer")
+	}
+	if rows, err = helpers.Atoi(dict["rows"]); err != nil {
+		return gridPaper{}, errors.New("the number of rows of grid paper should be given as a strictly positive integer")
+	}
+	if style, ok = dict["style"].(string); !ok {
+		return gridPaper{}, errors.New("the style of grid paper should be given as a string")
+	}
+
+	// next, check whether the margin was given or not. If not, no margin is
+	// left around the grid
+	margin := 0.0
+	if _, ok = dict["margin"]; ok {
+		if margin, err = helpers.Atof(dict["margin"]); err != nil {
+			return gridPaper{}, errors.New("the margin of grid paper should be given as a non-negative number")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := GridPaperOptions{
+		Step:   step,
+		Cols:   cols,
+		Rows:   rows,
+		Style:  style,
+		Margin: margin,
+	}
+	if err := options.Validate(); err != nil {
+		return gridPaper{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating grid paper and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.gridPaper(), nil
 }
 
-// Return the student's class of this master file
-func (masterFile MasterFile) GetClass() string {
-	return masterFile.Class
+// return a valid specification of a grid of problems with no error if all the
+// keys given in dict are correct for laying out problems. If not, an error is
+// returned. If an error is returned, the contents of the grid are undefined.
+//
+// A dictionary is correct if and only if it provides a strictly positive
+// number of rows and columns with the keys "rows" and "cols", the name of a
+// problem type that can be used in master files with the key "problem", and the
+// dictionary used for defining every problem with the key "args". Optionally,
+// the vertical space between rows can be given as a LaTeX length with the key
+// "vspace", and a page break can be forced after every number of rows given
+// with the key "pagerows"
+func verifyGridDict(dict map[string]interface{}) (gridLayout, error) {
+
+	// the mandatory keys are given next
+	mandatory := gridMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), gridOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "grid"); err != nil {
+		return gridLayout{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var problem string
+	var args map[string]interface{}
+	var rows, cols int
+	if rows, err = helpers.Atoi(dict["rows"]); err != nil || rows <= 0 {
+		return gridLayout{}, errors.New("the number of rows of a grid should be given as a strictly positive integer")
+	}
+	if cols, err = helpers.Atoi(dict["cols"]); err != nil || cols <= 0 {
+		return gridLayout{}, errors.New("the number of columns of a grid should be given as a strictly positive integer")
+	}
+	if problem, ok = dict["problem"].(string); !ok {
+		return gridLayout{}, errors.New("the problem of a grid should be given as a string with the name of its type")
+	}
+	if args, ok = dict["args"].(map[string]interface{}); !ok {
+		return gridLayout{}, errors.New("the arguments of the problems of a grid should be given as a dictionary")
+	}
+
+	// next, check whether the vertical space between rows was given or not
+	vspace := DEFAULTGRIDVSPACE
+	if _, ok = dict["vspace"]; ok {
+		if vspace, ok = dict["vspace"].(string); !ok {
+			return gridLayout{}, errors.New("the vertical space between the rows of a grid should be given as a string with a LaTeX length")
+		}
+	}
+
+	// and also the number of rows shown in every page. By default, pages are
+	// broken anywhere between rows
+	pagerows := 0
+	if _, ok = dict["pagerows"]; ok {
+		if pagerows, err = helpers.Atoi(dict["pagerows"]); err != nil || pagerows < 0 {
+			return gridLayout{}, errors.New("the number of rows of a grid in every page should be given as a non-negative integer")
+		}
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a grid and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return gridLayout{
+		rows:     rows,
+		cols:     cols,
+		problem:  problem,
+		args:     args,
+		vspace:   vspace,
+		pagerows: pagerows,
+	}, nil
 }
 
-// Return the output tex filename that shall contain the exercises in tex
-func (masterFile MasterFile) GetOutfile() string {
-	return masterFile.Outfile
+// return a valid specification of a mystery operation with no error if all the
+// keys given in dict are correct for defining a Mystery Operation. If not, an
+// error is returned. If an error is returned, the contents of the Mystery
+// Operation are undefined.
+//
+// A dictionary is correct if and only if correctly provides an operation (+, -,
+// /, *) and a number of digits for each operand and a number of masked digits
+// for each of them as well. The operator is provided through the key "operator"
+// and the number of digits and masked digits of each operand are given with the
+// keys "nbdigitsi" and "nbmaskedi" respectively, where i takes the values 1 and
+// 2 only. The number of digits of the result and the number of masked digits
+// are given with "nbdigitsanswer" and "nbmaskedanswer" respectively. Optionally,
+// subtractions can yield negative answers with the key "allownegative"
+func verifyMysteryOperationDict(dict map[string]interface{}) (mysteryOperation, error) {
+
+	// the mandatory keys are given next
+	mandatory := mysteryOperationMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), mysteryOperationOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "mystery operation"); err != nil {
+		return mysteryOperation{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var operator string
+	var nbdigits1, nbdigits2 int
+	var nbmasked1, nbmasked2 int
+	var nbdigitsanswer, nbmaskedanswer int
+	if operator, ok = dict["operator"].(string); !ok {
+		return mysteryOperation{}, errors.New("The operator of a mystery operation should be given as a stirng")
+	}
+	if nbdigits1, err = helpers.Atoi(dict["nbdigits1"]); err != nil {
+		return mysteryOperation{}, errors.New("the number of digits of the first operand should be given as a integer")
+	}
+	if nbdigits2, err = helpers.Atoi(dict["nbdigits2"]); err != nil {
+		return mysteryOperation{}, errors.New("the number of digits of the second operand should be given as a integer")
+	}
+	if nbmasked1, err = helpers.Atoi(dict["nbmasked1"]); err != nil {
+		return mysteryOperation{}, errors.New("the number of masked digits of the first operand should be given as a integer")
+	}
+	if nbmasked2, err = helpers.Atoi(dict["nbmasked2"]); err != nil {
+		return mysteryOperation{}, errors.New("the number of masked digits of the second operand should be given as a integer")
+	}
+	if nbdigitsanswer, err = helpers.Atoi(dict["nbdigitsanswer"]); err != nil {
+		return mysteryOperation{}, errors.New("the number of digits of the answer should be given as a integer")
+	}
+	if nbmaskedanswer, err = helpers.Atoi(dict["nbmaskedanswer"]); err != nil {
+		return mysteryOperation{}, errors.New("the number of masked digits of the answer should be given as a integer")
+	}
+
+	// whether subtractions can yield negative answers or not. By default, they
+	// can not
+	var allownegative bool
+	if _, ok := dict["allownegative"]; ok {
+		if allownegative, err = helpers.Atob(dict["allownegative"]); err != nil {
+			return mysteryOperation{}, errors.New("the flag for allowing negative answers in a mystery operation should be given as a bool")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := MysteryOperationOptions{
+		NbDigits1:      nbdigits1,
+		NbMasked1:      nbmasked1,
+		NbDigits2:      nbdigits2,
+		NbMasked2:      nbmasked2,
+		NbDigitsAnswer: nbdigitsanswer,
+		NbMaskedAnswer: nbmaskedanswer,
+		Operator:       operator,
+		AllowNegative:  allownegative,
+	}
+	if err := options.Validate(); err != nil {
+		return mysteryOperation{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a mystery operation and it will be ignored", key)
+	}
+
+	// now, return the proper definition of a mystery operation problem
+	return options.mysteryOperation(), nil
 }
 
-// the following function is provided just to allow the text/template to repeat
-// the same statement an arbitrary number of times. It just returns a slice of
-// MasterFiles of a given length. Each element can then be used to invoke the
-// various services provided for text/templates
-func (masterFile MasterFile) Slice(n int) []MasterFile {
-	return make([]MasterFile, n)
+// return a valid specification of a linear equation with no error if all the
+// keys given in dict are correct for defining it. If not, an error is returned.
+// If an error is returned, the contents of the linear equation are undefined
+//
+// A dictionary is correct if and only if it correctly provides a type of linear
+// equation with the keyword "type", and the lower and upper bound of its
+// solution with "geq" and "leq". Optionally, the largest coefficient can be
+// given with "coefleq" and negative numbers can be allowed with "negative"
+func verifyLinearEquationDict(dict map[string]interface{}) (linearEquation, error) {
+
+	// the mandatory keys are given next
+	mandatory := linearEquationMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), linearEquationOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "linear equation"); err != nil {
+		return linearEquation{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var letype, geq, leq int
+	if letype, err = helpers.Atoi(dict["type"]); err != nil {
+		return linearEquation{}, errors.New("the type of a linear equation should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return linearEquation{}, errors.New("the lower bound of the solution of a linear equation should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return linearEquation{}, errors.New("the upper bound of the solution of a linear equation should be given as an integer")
+	}
+
+	// next, the largest coefficient and whether negative numbers are allowed
+	// or not. By default, they are not
+	coefleq := DEFAULTLECOEFLEQ
+	if _, ok := dict["coefleq"]; ok {
+		if coefleq, err = helpers.Atoi(dict["coefleq"]); err != nil {
+			return linearEquation{}, errors.New("the largest coefficient of a linear equation should be given as an integer")
+		}
+	}
+	var negative bool
+	if _, ok := dict["negative"]; ok {
+		if negative, err = helpers.Atob(dict["negative"]); err != nil {
+			return linearEquation{}, errors.New("the flag for allowing negative numbers in a linear equation should be given as a bool")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := LinearEquationOptions{
+		Type:     letype,
+		Geq:      geq,
+		Leq:      leq,
+		CoefLeq:  coefleq,
+		Negative: negative,
+	}
+	if err := options.Validate(); err != nil {
+		return linearEquation{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a linear equation and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.linearEquation(), nil
+}
+
+// return a valid specification of a magic square with no error if all the keys
+// given in dict are correct for defining it. If not, an error is returned. If
+// an error is returned, the contents of the magic square are undefined
+//
+// A dictionary is correct if and only if it correctly provides the size of the
+// square with the key "size". Optionally, the lower and upper bound of the
+// magic constant can be given with "geq" and "leq", and the number of revealed
+// cells with "nbrevealed". By default, half the cells are revealed
+func verifyMagicSquareDict(dict map[string]interface{}) (magicSquare, error) {
+
+	// the mandatory keys are given next
+	mandatory := magicSquareMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), magicSquareOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "magic square"); err != nil {
+		return magicSquare{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var size int
+	if size, err = helpers.Atoi(dict["size"]); err != nil {
+		return magicSquare{}, errors.New("the size of a magic square should be given as an integer")
+	}
+
+	// next, the range of the magic constant and the number of revealed cells
+	geq, leq, nbrevealed := DEFAULTMAGICGEQ, DEFAULTMAGICLEQ, size*size/2
+	if _, ok := dict["geq"]; ok {
+		if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+			return magicSquare{}, errors.New("the lower bound of the magic constant should be given as an integer")
+		}
+	}
+	if _, ok := dict["leq"]; ok {
+		if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+			return magicSquare{}, errors.New("the upper bound of the magic constant should be given as an integer")
+		}
+	}
+	if _, ok := dict["nbrevealed"]; ok {
+		if nbrevealed, err = helpers.Atoi(dict["nbrevealed"]); err != nil {
+			return magicSquare{}, errors.New("the number of revealed cells of a magic square should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := MagicSquareOptions{
+		Size:       size,
+		Geq:        geq,
+		Leq:        leq,
+		NbRevealed: nbrevealed,
+	}
+	if err := options.Validate(); err != nil {
+		return magicSquare{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a magic square and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.magicSquare(), nil
+}
+
+// return a valid specification of crossed operations with no error if all the
+// keys given in dict are correct for defining them. If not, an error is
+// returned. If an error is returned, the contents of the crossed operations
+// are undefined
+//
+// A dictionary is correct if and only if it correctly provides the number of
+// operands of every equation with the key "size", and the range of the
+// operands with "geq" and "leq". Optionally, the allowed operators can be
+// given with "operators" and the number of hidden numbers with "nbmasked". By
+// default, additions and subtractions are used and twice as many numbers as
+// the size are hidden
+func verifyMathCrosswordDict(dict map[string]interface{}) (mathCrossword, error) {
+
+	// the mandatory keys are given next
+	mandatory := mathCrosswordMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), mathCrosswordOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "crossed operations"); err != nil {
+		return mathCrossword{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var size, geq, leq int
+	if size, err = helpers.Atoi(dict["size"]); err != nil {
+		return mathCrossword{}, errors.New("the size of crossed operations should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return mathCrossword{}, errors.New("the lower bound of the operands of crossed operations should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return mathCrossword{}, errors.New("the upper bound of the operands of crossed operations should be given as an integer")
+	}
+
+	// next, the operators and the number of hidden numbers
+	operators, nbmasked := DEFAULTCROSSWORDOPERATORS, 2*size
+	if _, ok := dict["operators"]; ok {
+		if operators, ok = dict["operators"].(string); !ok {
+			return mathCrossword{}, errors.New("the operators of crossed operations should be given as a string")
+		}
+	}
+	if _, ok := dict["nbmasked"]; ok {
+		if nbmasked, err = helpers.Atoi(dict["nbmasked"]); err != nil {
+			return mathCrossword{}, errors.New("the number of masked numbers of crossed operations should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := MathCrosswordOptions{
+		Size:      size,
+		Geq:       geq,
+		Leq:       leq,
+		Operators: operators,
+		NbMasked:  nbmasked,
+	}
+	if err := options.Validate(); err != nil {
+		return mathCrossword{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating crossed operations and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.mathCrossword(), nil
+}
+
+// return a valid specification of a mixed drill with no error if all the keys
+// given in dict are correct for defining it. If not, an error is returned. If
+// an error is returned, the contents of the mixed drill are undefined
+//
+// A dictionary is correct if and only if it correctly provides the basic
+// operations to interleave with the key "operations", either as a
+// comma-separated string or as a list of strings, where every operation is
+// given as "operator:nbdigitsop:nbdigitsrslt", e.g., "+:2:3", and the number
+// of problems to draw with "count". Optionally, the type of all basic
+// operations can be given with "type" (BORESULT by default), the number of
+// operands with "nboperands" (2 by default), their layout with "layout"
+// ("vertical" by default) and the number of columns used for laying out all
+// problems with "cols" (which depends on the layout by default)
+func verifyMixedDrillDict(dict map[string]interface{}) (mixedDrill, error) {
+
+	// the mandatory keys are given next
+	mandatory := mixedDrillMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), mixedDrillOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "mixed drill"); err != nil {
+		return mixedDrill{}, err
+	}
+
+	// make also sure that parameters are given with the right type. The
+	// operations can be given either as a comma-separated string or as a list
+	var err error
+	var operations []string
+	var count int
+	switch value := dict["operations"].(type) {
+	case string:
+		operations = strings.Split(value, ",")
+	case []interface{}:
+		for _, item := range value {
+			operation, ok := item.(string)
+			if !ok {
+				return mixedDrill{}, errors.New("the operations of a mixed drill should be given as strings")
+			}
+			operations = append(operations, operation)
+		}
+	case []string:
+		operations = value
+	default:
+		return mixedDrill{}, errors.New("the operations of a mixed drill should be given either as a comma-separated string or as a list of strings")
+	}
+	if count, err = helpers.Atoi(dict["count"]); err != nil {
+		return mixedDrill{}, errors.New("the number of problems of a mixed drill should be given as an integer")
+	}
+
+	// next, the optional parameters shared by all basic operations
+	botype, nboperands, layout := BORESULT, 2, BOVERTICAL
+	if _, ok := dict["type"]; ok {
+		if botype, err = helpers.Atoi(dict["type"]); err != nil {
+			return mixedDrill{}, errors.New("the type of the basic operations of a mixed drill should be given as an integer")
+		}
+	}
+	if _, ok := dict["nboperands"]; ok {
+		if nboperands, err = helpers.Atoi(dict["nboperands"]); err != nil {
+			return mixedDrill{}, errors.New("the number of operands of the basic operations of a mixed drill should be given as an integer")
+		}
+	}
+	if _, ok := dict["layout"]; ok {
+		if layout, ok = dict["layout"].(string); !ok {
+			return mixedDrill{}, errors.New("the layout of the basic operations of a mixed drill should be given as a string")
+		}
+	}
+
+	// and the number of columns, whose default value depends on the layout
+	cols := DEFAULTMIXEDDRILLCOLS
+	if layout == BOHORIZONTAL {
+		cols = DEFAULTHORIZONTALMIXEDDRILLCOLS
+	}
+	if _, ok := dict["cols"]; ok {
+		if cols, err = helpers.Atoi(dict["cols"]); err != nil {
+			return mixedDrill{}, errors.New("the number of columns of a mixed drill should be given as an integer")
+		}
+	}
+
+	// every operation is given as "operator:nbdigitsop:nbdigitsrslt"
+	var bos []BasicOperationOptions
+	for _, operation := range operations {
+		fields := strings.Split(strings.TrimSpace(operation), ":")
+		if len(fields) != 3 {
+			return mixedDrill{}, fmt.Errorf("the operation '%v' of a mixed drill should be given as 'operator:nbdigitsop:nbdigitsrslt'", operation)
+		}
+		var nbdigitsop, nbdigitsrslt int
+		if nbdigitsop, err = helpers.Atoi(fields[1]); err != nil {
+			return mixedDrill{}, fmt.Errorf("the number of digits of the operands of the operation '%v' of a mixed drill should be an integer", operation)
+		}
+		if nbdigitsrslt, err = helpers.Atoi(fields[2]); err != nil {
+			return mixedDrill{}, fmt.Errorf("the number of digits of the result of the operation '%v' of a mixed drill should be an integer", operation)
+		}
+		bos = append(bos, BasicOperationOptions{
+			Type:         botype,
+			Operator:     strings.TrimSpace(fields[0]),
+			NbOperands:   nboperands,
+			NbDigitsOp:   nbdigitsop,
+			NbDigitsRslt: nbdigitsrslt,
+			Layout:       layout,
+		})
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := MixedDrillOptions{
+		Operations: bos,
+		Count:      count,
+		Cols:       cols,
+	}
+	if err := options.Validate(); err != nil {
+		return mixedDrill{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a mixed drill and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.mixedDrill(), nil
+}
+
+// return a valid specification of a money problem with no error if all the
+// keys given in dict are correct for defining money problems. If not, an error
+// is returned. If an error is returned, the contents of the money problem are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides a type of money
+// problem with the keyword "type", and the range of prices in whole units with
+// "geq" and "leq". Optionally, the currency can be given with "currency" (one
+// among "EUR", "USD" and "GBP", "EUR" by default), whether prices have cents
+// with "decimals" (false by default), the number of items with "nbitems" (2 by
+// default), and whether the amounts given are drawn with coins and bills with
+// "coins" (false by default)
+func verifyMoneyDict(dict map[string]interface{}) (money, error) {
+
+	// the mandatory keys are given next
+	mandatory := moneyMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), moneyOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "money problem"); err != nil {
+		return money{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var mtype, geq, leq int
+	if mtype, err = helpers.Atoi(dict["type"]); err != nil {
+		return money{}, errors.New("the type of a money problem should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return money{}, errors.New("the lower bound of the prices of a money problem should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return money{}, errors.New("the upper bound of the prices of a money problem should be given as an integer")
+	}
+
+	// next, check the optional parameters
+	currency := DEFAULTCURRENCY
+	if _, ok := dict["currency"]; ok {
+		if currency, ok = dict["currency"].(string); !ok {
+			return money{}, errors.New("the currency of a money problem should be given as a string")
+		}
+	}
+	var decimals, coins bool
+	if _, ok := dict["decimals"]; ok {
+		if decimals, err = helpers.Atob(dict["decimals"]); err != nil {
+			return money{}, errors.New("the flag for using cents in a money problem should be given as a bool")
+		}
+	}
+	if _, ok := dict["coins"]; ok {
+		if coins, err = helpers.Atob(dict["coins"]); err != nil {
+			return money{}, errors.New("the flag for drawing coins and bills in a money problem should be given as a bool")
+		}
+	}
+	nbitems := DEFAULTMONEYITEMS
+	if _, ok := dict["nbitems"]; ok {
+		if nbitems, err = helpers.Atoi(dict["nbitems"]); err != nil {
+			return money{}, errors.New("the number of items of a money problem should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := MoneyOptions{
+		Type:     mtype,
+		Currency: currency,
+		Geq:      geq,
+		Leq:      leq,
+		Decimals: decimals,
+		NbItems:  nbitems,
+		Coins:    coins,
+	}
+	if err := options.Validate(); err != nil {
+		return money{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a money problem and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.money(), nil
+}
+
+// return a valid specification of a multiplication table with no error if all
+// the keys given in dict are correct for defining a multiplication table. If
+// not, an error is returned. If an error is returned, the contents of the
+// multiplication able are undefined
+//
+// A dictionary is correct if and only if it correctly provides a type of
+// multiplication table with the keyword "type", and a number of digits for
+// randomly choosing the factor. Other optional parameters are the lower and
+// upper bound (which by default take the values 1 and 10 respectively), and
+// whether rows are shown in the regular order or inverted with the keyword
+// "inv" whose value can be either "true" or "false", and also whether the rows
+// are sorted or not with the keyword "sorted" whose only allowed values are
+// either "true" or "false". The table can be shown in one block per line,
+// in two columns or as a grid of facts with "layout". Finally, the digits of
+// the factor can be constrained with "excludedigits" and "patterns" (see
+// digitConstraint).
+func verifyMultiplicationTableDict(dict map[string]interface{}) (multiplicationTable, error) {
+
+	// the mandatory keys are given next
+	mandatory := multiplicationTableMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), multiplicationTableOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "multiplication table"); err != nil {
+		return multiplicationTable{}, err
+	}
+
+	// make also sure that all mandatory parameters are given with the right type
+	var ok bool
+	var err error
+	var mttype, nbdigits int
+	if mttype, err = helpers.Atoi(dict["type"]); err != nil {
+		return multiplicationTable{}, errors.New("the type of a multiplication table should be given as an integer")
+	}
+	if nbdigits, err = helpers.Atoi(dict["nbdigits"]); err != nil {
+		return multiplicationTable{}, errors.New("the number of digits of the factor in a multiplication table should be given as an integer")
+	}
+
+	// next, check whether some optional parameters were given or not. If not,
+	// make sure they take their default values
+	geq, leq := 1, 10
+	inv, sorted := false, true
+
+	// geq and leq are integer optional parameters
+	if _, ok = dict["geq"]; ok {
+		if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+			return multiplicationTable{}, errors.New("the lower bound of a multiplication table should be given as an integer")
+		}
+	}
+	if _, ok = dict["leq"]; ok {
+		if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+			return multiplicationTable{}, errors.New("the upper bound of a multiplication table should be given as an integer")
+		}
+	}
+
+	// inv and sorted are boolean optional parameters
+	if _, ok = dict["inv"]; ok {
+		if inv, err = helpers.Atob(dict["inv"]); err != nil {
+			return multiplicationTable{}, errors.New("the 'inv' flag should be given as a bool")
+		}
+	}
+	if _, ok = dict["sorted"]; ok {
+		if sorted, err = helpers.Atob(dict["sorted"]); err != nil {
+			return multiplicationTable{}, errors.New("the 'sorted' flag should be given as a bool")
+		}
+	}
+
+	// and the layout, which is one block per line by default
+	layout := MTBLOCK
+	if _, ok = dict["layout"]; ok {
+		if layout, ok = dict["layout"].(string); !ok {
+			return multiplicationTable{}, errors.New("the layout of a multiplication table should be given as a string")
+		}
+	}
+
+	// and the constraints on the digits of the factor
+	excluded, patterns, err := verifyDigitConstraintDict(dict, "multiplication table")
+	if err != nil {
+		return multiplicationTable{}, err
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := MultiplicationTableOptions{
+		Type:          mttype,
+		NbDigits:      nbdigits,
+		Geq:           geq,
+		Leq:           leq,
+		Inv:           inv,
+		Sorted:        sorted,
+		Layout:        layout,
+		ExcludeDigits: excluded,
+		Patterns:      patterns,
+	}
+	if err := options.Validate(); err != nil {
+		return multiplicationTable{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a multiplication table and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.multiplicationTable(), nil
+}
+
+// return a valid specification of a number classification with no error if all
+// the keys given in dict are correct for defining it. If not, an error is
+// returned. If an error is returned, the contents of the number classification
+// are undefined
+//
+// A dictionary is correct if and only if it correctly provides the criterion
+// for classifying numbers with the key "type", the range of the numbers with
+// "geq" and "leq", and the number of numbers to classify with "nbnumbers".
+// Optionally, the number whose multiples have to be identified can be given
+// with "multiple" (DEFAULTCLASSMULTIPLE by default), and the number of numbers
+// shown in every row with "nbcols" (DEFAULTCLASSCOLUMNS by default)
+func verifyNumberClassificationDict(dict map[string]interface{}) (numberClassification, error) {
+
+	// the mandatory keys are given next
+	mandatory := numberClassificationMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), numberClassificationOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "number classification"); err != nil {
+		return numberClassification{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var cltype, geq, leq, nbnumbers int
+	if cltype, err = helpers.Atoi(dict["type"]); err != nil {
+		return numberClassification{}, errors.New("the type of a number classification should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return numberClassification{}, errors.New("the lower bound of the numbers to classify should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return numberClassification{}, errors.New("the upper bound of the numbers to classify should be given as an integer")
+	}
+	if nbnumbers, err = helpers.Atoi(dict["nbnumbers"]); err != nil {
+		return numberClassification{}, errors.New("the number of numbers to classify should be given as an integer")
+	}
+
+	// next, the multiple and the number of columns
+	multiple, nbcols := DEFAULTCLASSMULTIPLE, DEFAULTCLASSCOLUMNS
+	if _, ok := dict["multiple"]; ok {
+		if multiple, err = helpers.Atoi(dict["multiple"]); err != nil {
+			return numberClassification{}, errors.New("the multiple of a number classification should be given as an integer")
+		}
+	}
+	if _, ok := dict["nbcols"]; ok {
+		if nbcols, err = helpers.Atoi(dict["nbcols"]); err != nil {
+			return numberClassification{}, errors.New("the number of columns of a number classification should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := NumberClassificationOptions{
+		Type:      cltype,
+		Geq:       geq,
+		Leq:       leq,
+		NbNumbers: nbnumbers,
+		Multiple:  multiple,
+		NbCols:    nbcols,
+	}
+	if err := options.Validate(); err != nil {
+		return numberClassification{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a number classification and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.numberClassification(), nil
+}
+
+// return a valid specification of a number comparison with no error if all
+// the keys given in dict are correct for defining it. If not, an error is
+// returned. If an error is returned, the contents of the number comparison are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides the number of
+// digits of all numbers with the key "nbdigits". Optionally, the number of
+// numbers compared in the same row can be given with "nbitems" (2 by default),
+// and negative numbers can be requested with "negative" (false by default)
+func verifyNumberComparisonDict(dict map[string]interface{}) (numberComparison, error) {
+
+	// the mandatory keys are given next
+	mandatory := numberComparisonMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), numberComparisonOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "number comparison"); err != nil {
+		return numberComparison{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var nbdigits int
+	if nbdigits, err = helpers.Atoi(dict["nbdigits"]); err != nil {
+		return numberComparison{}, errors.New("the number of digits of a number comparison should be given as an integer")
+	}
+
+	// next, check whether the number of items was given or not. By default,
+	// pairs of numbers are compared
+	nbitems := 2
+	if _, ok := dict["nbitems"]; ok {
+		if nbitems, err = helpers.Atoi(dict["nbitems"]); err != nil {
+			return numberComparison{}, errors.New("the number of items of a number comparison should be given as an integer")
+		}
+	}
+
+	// and also whether negative numbers were requested. By default, they are
+	// not
+	var negative bool
+	if _, ok := dict["negative"]; ok {
+		if negative, err = helpers.Atob(dict["negative"]); err != nil {
+			return numberComparison{}, errors.New("the flag for generating negative numbers in a number comparison should be given as a bool")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := NumberComparisonOptions{
+		NbDigits: nbdigits,
+		NbItems:  nbitems,
+		Negative: negative,
+	}
+	if err := options.Validate(); err != nil {
+		return numberComparison{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a number comparison and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.numberComparison(), nil
+}
+
+// return a valid specification of a conversion between forms of numbers with
+// no error if all the keys given in dict are correct for defining it. If not,
+// an error is returned. If an error is returned, the contents of the
+// conversion are undefined
+//
+// A dictionary is correct if and only if it correctly provides the form of the
+// number to convert with the keyword "from" and the form it has to be
+// converted to with "to", both among "standard", "expanded" and "words", and
+// necessarily different, and the number of digits of the number with
+// "nbdigits". Optionally, the language used for writing numbers in words can
+// be given with "locale", either "en" or "es". By default, it is the language
+// of the current locale
+func verifyNumberFormDict(dict map[string]interface{}) (numberForm, error) {
+
+	// the mandatory keys are given next
+	mandatory := numberFormMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), numberFormOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "conversion between forms of numbers"); err != nil {
+		return numberForm{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var from, to string
+	var nbdigits int
+	if from, ok = dict["from"].(string); !ok {
+		return numberForm{}, errors.New("the form of the number to convert should be given as a string")
+	}
+	if to, ok = dict["to"].(string); !ok {
+		return numberForm{}, errors.New("the form of the converted number should be given as a string")
+	}
+	if nbdigits, err = helpers.Atoi(dict["nbdigits"]); err != nil {
+		return numberForm{}, errors.New("the number of digits of the number to convert should be given as an integer")
+	}
+
+	// next, check whether the language was given or not
+	locale := currentLocale().Code
+	if _, ok = dict["locale"]; ok {
+		if locale, ok = dict["locale"].(string); !ok {
+			return numberForm{}, errors.New("the language for writing numbers in words should be given as a string")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := NumberFormOptions{
+		From:     from,
+		To:       to,
+		NbDigits: nbdigits,
+		Locale:   locale,
+	}
+	if err := options.Validate(); err != nil {
+		return numberForm{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a conversion between forms of numbers and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.numberForm(), nil
+}
+
+// return a valid specification of a number line with no error if all the keys
+// given in dict are correct for defining number lines. If not, an error is
+// returned. If an error is returned, the contents of the number line are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides a type of number
+// line problem with the keyword "type", the lower and upper bound of the values
+// shown with "geq" and "leq", and the difference between consecutive ticks with
+// "step". Optionally, the number of ticks can be given with "nbticks" (by
+// default, those necessary to cover the whole range), the indices of the
+// hidden ticks (starting from 0) as a comma-separated string with "hidden" (by
+// default, they are randomly chosen), and the orientation with "orientation",
+// either "horizontal" (by default) or "vertical"
+func verifyNumberLineDict(dict map[string]interface{}) (numberLine, error) {
+
+	// the mandatory keys are given next
+	mandatory := numberLineMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), numberLineOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "number line"); err != nil {
+		return numberLine{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var nltype, geq, leq, step int
+	if nltype, err = helpers.Atoi(dict["type"]); err != nil {
+		return numberLine{}, errors.New("the type of a number line should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return numberLine{}, errors.New("the lower bound of a number line should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return numberLine{}, errors.New("the upper bound of a number line should be given as an integer")
+	}
+	if step, err = helpers.Atoi(dict["step"]); err != nil {
+		return numberLine{}, errors.New("the step of a number line should be given as a positive integer")
+	}
+
+	// next, check whether the number of ticks was given or not. If not, all
+	// ticks necessary to cover the whole range are drawn
+	var nbticks int
+	if _, ok := dict["nbticks"]; ok {
+		if nbticks, err = helpers.Atoi(dict["nbticks"]); err != nil || nbticks == 0 {
+			return numberLine{}, errors.New("the number of ticks of a number line should be given as an integer greater or equal than 2")
+		}
+	}
+
+	// and also the indices of the hidden ticks
+	var hidden []int
+	if _, ok := dict["hidden"]; ok {
+		indices, ok := dict["hidden"].(string)
+		if !ok {
+			return numberLine{}, errors.New("the hidden ticks of a number line should be given as a comma-separated string")
+		}
+		hidden = []int{}
+		for _, index := range strings.Split(indices, ",") {
+			var value int
+			if value, err = helpers.Atoi(strings.TrimSpace(index)); err != nil {
+				return numberLine{}, fmt.Errorf("the hidden tick '%v' should be given as an integer", index)
+			}
+			hidden = append(hidden, value)
+		}
+	}
+
+	// and the orientation
+	vertical := false
+	if _, ok := dict["orientation"]; ok {
+		orientation, ok := dict["orientation"].(string)
+		if !ok || (orientation != "horizontal" && orientation != "vertical") {
+			return numberLine{}, errors.New("the orientation of a number line has to be one and only one among the following: 'horizontal' or 'vertical'")
+		}
+		vertical = orientation == "vertical"
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := NumberLineOptions{
+		Type:     nltype,
+		Geq:      geq,
+		Leq:      leq,
+		Step:     step,
+		NbTicks:  nbticks,
+		Hidden:   hidden,
+		Vertical: vertical,
+	}
+	if err := options.Validate(); err != nil {
+		return numberLine{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a number line and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.numberLine(), nil
+}
+
+// return a valid specification of a number pyramid with no error if all the
+// keys given in dict are correct for defining it. If not, an error is returned.
+// If an error is returned, the contents of the number pyramid are undefined
+//
+// A dictionary is correct if and only if it correctly provides the height of
+// the pyramid with the key "height" and the lower and upper bound of the
+// numbers in its base with "geq" and "leq". Optionally, the cells revealed can
+// be given with "reveal", either "base" (by default) or "random", in which case
+// their number is given with "nbrevealed" (by default, the height)
+func verifyNumberPyramidDict(dict map[string]interface{}) (numberPyramid, error) {
+
+	// the mandatory keys are given next
+	mandatory := numberPyramidMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), numberPyramidOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "number pyramid"); err != nil {
+		return numberPyramid{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var height, geq, leq int
+	if height, err = helpers.Atoi(dict["height"]); err != nil {
+		return numberPyramid{}, errors.New("the height of a number pyramid should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return numberPyramid{}, errors.New("the lower bound of the base of a number pyramid should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return numberPyramid{}, errors.New("the upper bound of the base of a number pyramid should be given as an integer")
+	}
+
+	// next, the cells to reveal
+	reveal, nbrevealed := PYRBASE, height
+	if _, ok := dict["reveal"]; ok {
+		if reveal, ok = dict["reveal"].(string); !ok {
+			return numberPyramid{}, errors.New("the cells revealed in a number pyramid should be given as a string")
+		}
+	}
+	if _, ok := dict["nbrevealed"]; ok {
+		if nbrevealed, err = helpers.Atoi(dict["nbrevealed"]); err != nil {
+			return numberPyramid{}, errors.New("the number of revealed cells of a number pyramid should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := NumberPyramidOptions{
+		Height:     height,
+		Geq:        geq,
+		Leq:        leq,
+		Reveal:     reveal,
+		NbRevealed: nbrevealed,
+	}
+	if err := options.Validate(); err != nil {
+		return numberPyramid{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a number pyramid and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.numberPyramid(), nil
+}
+
+// return a valid specification of a percentage with no error if all the keys
+// given in dict are correct for defining it. If not, an error is returned. If
+// an error is returned, the contents of the percentage are undefined
+//
+// A dictionary is correct if and only if it correctly provides the type of the
+// problem with the key "type", and the range of the base with "geq" and "leq".
+// Optionally, the range of the percentage can be given with "pctgeq" and
+// "pctleq", which are 1 and 100 by default, and the number of decimals of the
+// result with "decimals", which is 0 by default
+func verifyPercentageDict(dict map[string]interface{}) (percentage, error) {
+
+	// the mandatory keys are given next
+	mandatory := percentageMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), percentageOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "percentage"); err != nil {
+		return percentage{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var pctype, geq, leq int
+	if pctype, err = helpers.Atoi(dict["type"]); err != nil {
+		return percentage{}, errors.New("the type of a percentage should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return percentage{}, errors.New("the lower bound of the base of a percentage should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return percentage{}, errors.New("the upper bound of the base of a percentage should be given as an integer")
+	}
+
+	// next, the range of the percentage and the number of decimals
+	pctgeq, pctleq, decimals := DEFAULTPCTGEQ, DEFAULTPCTLEQ, 0
+	if _, ok := dict["pctgeq"]; ok {
+		if pctgeq, err = helpers.Atoi(dict["pctgeq"]); err != nil {
+			return percentage{}, errors.New("the lower bound of the percentage should be given as an integer")
+		}
+	}
+	if _, ok := dict["pctleq"]; ok {
+		if pctleq, err = helpers.Atoi(dict["pctleq"]); err != nil {
+			return percentage{}, errors.New("the upper bound of the percentage should be given as an integer")
+		}
+	}
+	if _, ok := dict["decimals"]; ok {
+		if decimals, err = helpers.Atoi(dict["decimals"]); err != nil {
+			return percentage{}, errors.New("the number of decimals of a percentage should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := PercentageOptions{
+		Type:     pctype,
+		Geq:      geq,
+		Leq:      leq,
+		PctGeq:   pctgeq,
+		PctLeq:   pctleq,
+		Decimals: decimals,
+	}
+	if err := options.Validate(); err != nil {
+		return percentage{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a percentage and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.percentage(), nil
+}
+
+// return a valid specification of a perimeter and area problem with no error
+// if all the keys given in dict are correct for defining it. If not, an error
+// is returned. If an error is returned, the contents of the problem are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides the type of the
+// problem with the key "type", the shape with "shape", either "rectangle" or
+// "triangle", and the range of all lengths with "geq" and "leq"
+func verifyPerimeterAreaDict(dict map[string]interface{}) (perimeterArea, error) {
+
+	// the mandatory keys are given next
+	mandatory := perimeterAreaMandatory
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "perimeter and area problem"); err != nil {
+		return perimeterArea{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var patype, geq, leq int
+	var shape string
+	if patype, err = helpers.Atoi(dict["type"]); err != nil {
+		return perimeterArea{}, errors.New("the type of a perimeter and area problem should be given as an integer")
+	}
+	if shape, ok = dict["shape"].(string); !ok {
+		return perimeterArea{}, errors.New("the shape of a perimeter and area problem should be given as a string")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return perimeterArea{}, errors.New("the lower bound of the lengths should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return perimeterArea{}, errors.New("the upper bound of the lengths should be given as an integer")
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := PerimeterAreaOptions{
+		Type:  patype,
+		Shape: shape,
+		Geq:   geq,
+		Leq:   leq,
+	}
+	if err := options.Validate(); err != nil {
+		return perimeterArea{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, mandatory); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a perimeter and area problem and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.perimeterArea(), nil
+}
+
+// return a valid specification of a pictogram with no error if all the keys
+// given in dict are correct for defining it. If not, an error is returned. If
+// an error is returned, the contents of the pictogram are undefined
+//
+// A dictionary is correct if and only if it correctly provides the names of
+// all categories as a comma-separated string with the key "categories", and
+// the range of their values with "geq" and "leq". Optionally, data can be
+// shown with "chart", either "tally" (by default) or "pictogram", in which case
+// the symbol used is given with "symbol" ("star" by default), and the
+// questions to answer can be given as a comma-separated string with
+// "questions", among "most", "least" and "total" (all of them by default)
+func verifyPictogramDict(dict map[string]interface{}) (pictogram, error) {
+
+	// the mandatory keys are given next
+	mandatory := pictogramMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), pictogramOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "pictogram"); err != nil {
+		return pictogram{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var value string
+	var geq, leq int
+	if value, ok = dict["categories"].(string); !ok {
+		return pictogram{}, errors.New("the categories of a pictogram should be given as a comma-separated string")
+	}
+	var categories []string
+	for _, category := range strings.Split(value, ",") {
+		categories = append(categories, strings.TrimSpace(category))
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return pictogram{}, errors.New("the lower bound of the values of a pictogram should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return pictogram{}, errors.New("the upper bound of the values of a pictogram should be given as an integer")
+	}
+
+	// next, the chart, the symbol and the questions
+	chart, symbol := PICTALLY, components.SYMBOLSTAR
+	questions := []string{PICMOST, PICLEAST, PICTOTAL}
+	if _, ok = dict["chart"]; ok {
+		if chart, ok = dict["chart"].(string); !ok {
+			return pictogram{}, errors.New("the chart of a pictogram should be given as a string")
+		}
+	}
+	if _, ok = dict["symbol"]; ok {
+		if symbol, ok = dict["symbol"].(string); !ok {
+			return pictogram{}, errors.New("the symbol of a pictogram should be given as a string")
+		}
+	}
+	if _, ok = dict["questions"]; ok {
+		if value, ok = dict["questions"].(string); !ok {
+			return pictogram{}, errors.New("the questions of a pictogram should be given as a comma-separated string")
+		}
+		questions = []string{}
+		for _, question := range strings.Split(value, ",") {
+			questions = append(questions, strings.TrimSpace(question))
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := PictogramOptions{
+		Categories: categories,
+		Geq:        geq,
+		Leq:        leq,
+		Chart:      chart,
+		Symbol:     symbol,
+		Questions:  questions,
+	}
+	if err := options.Validate(); err != nil {
+		return pictogram{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a pictogram and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.pictogram(), nil
+}
+
+// return a valid specification of a place value problem with no error if all
+// the keys given in dict are correct for defining it. If not, an error is
+// returned. If an error is returned, the contents of the place value problem
+// are undefined
+//
+// A dictionary is correct if and only if it correctly provides a type of place
+// value problem with the keyword "type" and the number of digits with
+// "nbdigits". Optionally, when decomposing numbers, the names of the masked
+// places can be given as a comma-separated list with the key "masked" (e.g.,
+// "tens, units") or the number of places randomly masked with "nbmasked". By
+// default, all places are masked
+func verifyPlaceValueDict(dict map[string]interface{}) (placeValue, error) {
+
+	// the mandatory keys are given next
+	mandatory := placeValueMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), placeValueOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "place value problem"); err != nil {
+		return placeValue{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var pvtype, nbdigits int
+	if pvtype, err = helpers.Atoi(dict["type"]); err != nil {
+		return placeValue{}, errors.New("the type of a place value problem should be given as an integer")
+	}
+	if nbdigits, err = helpers.Atoi(dict["nbdigits"]); err != nil {
+		return placeValue{}, errors.New("the number of digits of a place value problem should be given as an integer")
+	}
+
+	// next, check whether the masked places were given by their names or by
+	// their number. If none is given, all places are masked
+	var masked []string
+	nbmasked := 0
+	if _, ok := dict["masked"]; ok {
+		value, ok := dict["masked"].(string)
+		if !ok {
+			return placeValue{}, errors.New("the masked places of a place value problem should be given as a comma-separated string")
+		}
+		for _, name := range strings.Split(value, ",") {
+			masked = append(masked, strings.TrimSpace(name))
+		}
+	}
+	if _, ok := dict["nbmasked"]; ok {
+		if nbmasked, err = helpers.Atoi(dict["nbmasked"]); err != nil {
+			return placeValue{}, errors.New("the number of masked places of a place value problem should be given as an integer")
+		}
+	} else if len(masked) == 0 {
+		nbmasked = nbdigits
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := PlaceValueOptions{
+		Type:     pvtype,
+		NbDigits: nbdigits,
+		NbMasked: nbmasked,
+		Masked:   masked,
+	}
+	if err := options.Validate(); err != nil {
+		return placeValue{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a place value problem and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.placeValue(), nil
+}
+
+// return a valid specification of a prime factorization with no error if all
+// the keys given in dict are correct for defining it. If not, an error is
+// returned. If an error is returned, the contents of the prime factorization
+// are undefined
+//
+// A dictionary is correct if and only if it correctly provides the lower and
+// upper bound of the numbers to decompose with the keys "geq" and "leq".
+// Optionally, the maximum number of prime factors (counted with their
+// multiplicity) can be given with "maxfactors"
+func verifyPrimeFactorizationDict(dict map[string]interface{}) (primeFactorization, error) {
+
+	// the mandatory keys are given next
+	mandatory := primeFactorizationMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), primeFactorizationOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "prime factorization"); err != nil {
+		return primeFactorization{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var geq, leq int
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return primeFactorization{}, errors.New("the lower bound of the numbers to decompose should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return primeFactorization{}, errors.New("the upper bound of the numbers to decompose should be given as an integer")
+	}
+
+	// and the maximum number of prime factors
+	maxfactors := DEFAULTMAXPRIMEFACTORS
+	if _, ok := dict["maxfactors"]; ok {
+		if maxfactors, err = helpers.Atoi(dict["maxfactors"]); err != nil {
+			return primeFactorization{}, errors.New("the maximum number of prime factors should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := PrimeFactorizationOptions{
+		Geq:        geq,
+		Leq:        leq,
+		MaxFactors: maxfactors,
+	}
+	if err := options.Validate(); err != nil {
+		return primeFactorization{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a prime factorization and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.primeFactorization(), nil
+}
+
+// return a valid specification of a ratio with no error if all the keys given
+// in dict are correct for defining a ratio. If not, an error is returned. If an
+// error is returned, the contents of the ratio are undefined
+//
+// A dictionary is correct if and only if it correctly provides a type of ratio
+// with the keyword "type", the lower and upper bound of both terms of the ratio
+// with "geq" and "leq", and the lower and upper bound of the scale factor with
+// "scalegeq" and "scaleleq"
+func verifyRatioDict(dict map[string]interface{}) (ratio, error) {
+
+	// the mandatory keys are given next
+	mandatory := ratioMandatory
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "ratio"); err != nil {
+		return ratio{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var rttype, geq, leq, scalegeq, scaleleq int
+	if rttype, err = helpers.Atoi(dict["type"]); err != nil {
+		return ratio{}, errors.New("the type of a ratio should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return ratio{}, errors.New("the lower bound of the terms of a ratio should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return ratio{}, errors.New("the upper bound of the terms of a ratio should be given as an integer")
+	}
+	if scalegeq, err = helpers.Atoi(dict["scalegeq"]); err != nil {
+		return ratio{}, errors.New("the lower bound of the scale factor should be given as an integer")
+	}
+	if scaleleq, err = helpers.Atoi(dict["scaleleq"]); err != nil {
+		return ratio{}, errors.New("the upper bound of the scale factor should be given as an integer")
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := RatioOptions{
+		Type:     rttype,
+		Geq:      geq,
+		Leq:      leq,
+		ScaleGeq: scalegeq,
+		ScaleLeq: scaleleq,
+	}
+	if err := options.Validate(); err != nil {
+		return ratio{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, mandatory); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a ratio and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.ratio(), nil
+}
+
+// return a valid specification of a rounding problem with no error if all the
+// keys given in dict are correct for defining it. If not, an error is
+// returned. If an error is returned, the contents of the rounding problem are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides the number of
+// digits of the numbers to round with the key "nbdigits" and the place they
+// have to be rounded to with "place". Optionally, the rule for breaking ties
+// can be given with "tiebreak", and the digit of the place can be highlighted
+// with "highlight"
+func verifyRoundingDict(dict map[string]interface{}) (rounding, error) {
+
+	// the mandatory keys are given next
+	mandatory := roundingMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), roundingOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "rounding problem"); err != nil {
+		return rounding{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var place string
+	var nbdigits int
+	if nbdigits, err = helpers.Atoi(dict["nbdigits"]); err != nil {
+		return rounding{}, errors.New("the number of digits of a rounding problem should be given as an integer")
+	}
+	if place, ok = dict["place"].(string); !ok {
+		return rounding{}, errors.New("the place of a rounding problem should be given as a string")
+	}
+
+	// next, check the rule for breaking ties. By default, numbers halfway
+	// between two multiples are rounded up
+	tiebreak := ROUNDUP
+	if _, ok = dict["tiebreak"]; ok {
+		if tiebreak, ok = dict["tiebreak"].(string); !ok {
+			return rounding{}, errors.New("the tiebreak of a rounding problem should be given as a string")
+		}
+	}
+
+	// next, check whether the digit of the place has to be highlighted. By
+	// default, it is not
+	var highlight bool
+	if _, ok = dict["highlight"]; ok {
+		if highlight, err = helpers.Atob(dict["highlight"]); err != nil {
+			return rounding{}, errors.New("the flag for highlighting the place of a rounding problem should be given as a bool")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := RoundingOptions{
+		NbDigits:  nbdigits,
+		Place:     place,
+		Tiebreak:  tiebreak,
+		Highlight: highlight,
+	}
+	if err := options.Validate(); err != nil {
+		return rounding{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a rounding problem and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.rounding(), nil
+}
+
+// return a valid specification of a sequence with no error if all the keys
+// given in dict are correct for defining a sequence. If not, an error is
+// returned. If an error is returned, the contents of the sequence are
+// undetermined
+//
+// A dictionary is correct if and only if it correctly provides a type of
+// sequence with the keyword "type", a number of items with the keyword
+// "nbitems", and a lower and upper bound with "geq" and "leq". Optionally, the
+// pattern of the sequence can be given with "pattern": "arithmetic" (by
+// default), "geometric", "alternating" or "fibonacci". The difference between
+// consecutive numbers (or their ratio in geometric sequences) can be given with
+// "step" (1 by default), which is negative to count backwards, and alternating
+// sequences add alternately "step" and "altstep". The first number can be
+// forced to be a multiple of the step with "start-multiple". Finally, the items
+// to hide can be given either with their indices (starting from zero) in
+// "mask", or with their number in "nbmasked", in which case they are randomly
+// chosen. Both override the type of the sequence
+func verifySequenceDict(dict map[string]interface{}) (sequence, error) {
+
+	// the mandatory keys are given next
+	mandatory := sequenceMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), sequenceOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "sequence"); err != nil {
+		return sequence{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var seqtype, nbitems, geq, leq int
+	if seqtype, err = helpers.Atoi(dict["type"]); err != nil {
+		return sequence{}, errors.New("the type of a sequence should be given as an integer")
+	}
+	if nbitems, err = helpers.Atoi(dict["nbitems"]); err != nil {
+		return sequence{}, errors.New("the number of items in a sequence should be given as an integer")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return sequence{}, errors.New("the lower bound of a sequence should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return sequence{}, errors.New("the upper bound of a sequence should be given as a string")
+	}
+
+	// next, check whether the pattern was given or not. By default, sequences
+	// are arithmetic
+	pattern := SEQARITHMETIC
+	if _, ok := dict["pattern"]; ok {
+		var isstring bool
+		if pattern, isstring = dict["pattern"].(string); !isstring {
+			return sequence{}, errors.New("the pattern of a sequence should be given as a string")
+		}
+	}
+
+	// and also whether the step was given or not. By default, consecutive
+	// numbers are counted
+	step := 1
+	if _, ok := dict["step"]; ok {
+		if step, err = helpers.Atoi(dict["step"]); err != nil || step == 0 {
+			return sequence{}, errors.New("the step of a sequence should be given as a non-zero integer")
+		}
+	}
+
+	// alternating sequences also require the alternate step
+	var altstep int
+	if _, ok := dict["altstep"]; ok {
+		if altstep, err = helpers.Atoi(dict["altstep"]); err != nil {
+			return sequence{}, errors.New("the alternate step of a sequence should be given as an integer")
+		}
+	}
+
+	// and also whether the first number has to be a multiple of the step
+	var startmultiple bool
+	if _, ok := dict["start-multiple"]; ok {
+		if startmultiple, err = helpers.Atob(dict["start-multiple"]); err != nil {
+			return sequence{}, errors.New("the flag for starting a sequence with a multiple of its step should be given as a bool")
+		}
+	}
+
+	// and finally, whether the items to hide were explicitly given
+	var mask []int
+	if _, ok := dict["mask"]; ok {
+		if mask, err = helpers.AtoiSlice(dict["mask"]); err != nil {
+			return sequence{}, errors.New("the masked items of a sequence should be given as a list of integers")
+		}
+	}
+	var nbmasked int
+	if _, ok := dict["nbmasked"]; ok {
+		if nbmasked, err = helpers.Atoi(dict["nbmasked"]); err != nil {
+			return sequence{}, errors.New("the number of masked items of a sequence should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := SequenceOptions{
+		Type:          seqtype,
+		NbItems:       nbitems,
+		Geq:           geq,
+		Leq:           leq,
+		Pattern:       pattern,
+		Step:          step,
+		AltStep:       altstep,
+		StartMultiple: startmultiple,
+		Mask:          mask,
+		NbMasked:      nbmasked,
+	}
+	if err := options.Validate(); err != nil {
+		return sequence{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a sequence and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.sequence(), nil
+}
+
+// return a valid specification of a shaded fraction with no error if all the
+// keys given in dict are correct for defining it. If not, an error is
+// returned. If an error is returned, the contents of the shaded fraction are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides the type of the
+// problem with the key "type", and the range of the denominator with "dengeq"
+// and "denleq". Optionally, the shape can be given with "shape", either "pie"
+// (by default) or "bar", and whether shaded parts are consecutive with
+// "contiguous", which is false by default
+func verifyShadedFractionDict(dict map[string]interface{}) (shadedFraction, error) {
+
+	// the mandatory keys are given next
+	mandatory := shadedFractionMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), shadedFractionOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "shaded fraction"); err != nil {
+		return shadedFraction{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var err error
+	var sftype, dengeq, denleq int
+	if sftype, err = helpers.Atoi(dict["type"]); err != nil {
+		return shadedFraction{}, errors.New("the type of a shaded fraction should be given as an integer")
+	}
+	if dengeq, err = helpers.Atoi(dict["dengeq"]); err != nil {
+		return shadedFraction{}, errors.New("the lower bound of the denominator should be given as an integer")
+	}
+	if denleq, err = helpers.Atoi(dict["denleq"]); err != nil {
+		return shadedFraction{}, errors.New("the upper bound of the denominator should be given as an integer")
+	}
+
+	// next, the shape and whether shaded parts are consecutive or not
+	shape, contiguous := components.FRACTIONPIE, false
+	if _, ok := dict["shape"]; ok {
+		if shape, ok = dict["shape"].(string); !ok {
+			return shadedFraction{}, errors.New("the shape of a shaded fraction should be given as a string")
+		}
+	}
+	if _, ok := dict["contiguous"]; ok {
+		if contiguous, err = helpers.Atob(dict["contiguous"]); err != nil {
+			return shadedFraction{}, errors.New("whether shaded parts are contiguous or not should be given as a boolean")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := ShadedFractionOptions{
+		Type:       sftype,
+		Shape:      shape,
+		DenGeq:     dengeq,
+		DenLeq:     denleq,
+		Contiguous: contiguous,
+	}
+	if err := options.Validate(); err != nil {
+		return shadedFraction{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a shaded fraction and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.shadedFraction(), nil
+}
+
+// return a valid specification of a unit conversion with no error if all the
+// keys given in dict are correct for defining unit conversions. If not, an
+// error is returned. If an error is returned, the contents of the unit
+// conversion are undefined
+//
+// A dictionary is correct if and only if it correctly provides the quantity to
+// convert with the keyword "quantity", one among "length", "mass" and
+// "capacity". Optionally, the direction of the conversion can be given with
+// "direction" ("down", "up" or "both", the default), the range of values with
+// "geq" and "leq" ([1, 100] by default), and the number of decimals of the
+// values with "decimals" (0 by default)
+func verifyUnitConversionDict(dict map[string]interface{}) (unitConversion, error) {
+
+	// the mandatory keys are given next
+	mandatory := unitConversionMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), unitConversionOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "unit conversion"); err != nil {
+		return unitConversion{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var quantity string
+	if quantity, ok = dict["quantity"].(string); !ok {
+		return unitConversion{}, errors.New("the quantity of a unit conversion should be given as a string")
+	}
+
+	// next, check the optional parameters
+	direction := DEFAULTUCDIRECTION
+	if _, ok = dict["direction"]; ok {
+		if direction, ok = dict["direction"].(string); !ok {
+			return unitConversion{}, errors.New("the direction of a unit conversion should be given as a string")
+		}
+	}
+	geq, leq, decimals := DEFAULTUCGEQ, DEFAULTUCLEQ, 0
+	if _, ok = dict["geq"]; ok {
+		if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+			return unitConversion{}, errors.New("the lower bound of a unit conversion should be given as an integer")
+		}
+	}
+	if _, ok = dict["leq"]; ok {
+		if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+			return unitConversion{}, errors.New("the upper bound of a unit conversion should be given as an integer")
+		}
+	}
+	if _, ok = dict["decimals"]; ok {
+		if decimals, err = helpers.Atoi(dict["decimals"]); err != nil {
+			return unitConversion{}, errors.New("the number of decimals of a unit conversion should be given as an integer")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := UnitConversionOptions{
+		Quantity:  quantity,
+		Direction: direction,
+		Geq:       geq,
+		Leq:       leq,
+		Decimals:  decimals,
+	}
+	if err := options.Validate(); err != nil {
+		return unitConversion{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a unit conversion and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.unitConversion(), nil
+}
+
+// return a valid specification of a word problem with no error if all the keys
+// given in dict are correct for defining word problems. If not, an error is
+// returned. If an error is returned, the contents of the word problem are
+// undefined
+//
+// A dictionary is correct if and only if it correctly provides an operator
+// (+, -, * or /) with the keyword "operator", and the lower and upper bound of
+// the operands with "geq" and "leq". Optionally, the name of a file with a bank
+// of stories in JSON format can be given with "bank"; otherwise, the default
+// bank of stories of the current locale is used
+func verifyWordProblemDict(dict map[string]interface{}) (wordProblem, error) {
+
+	// the mandatory keys are given next
+	mandatory := wordProblemMandatory
+
+	// all acknowledged options (including those that are optional) are listed
+	// next
+	all := append(append([]string{}, mandatory...), wordProblemOptional...)
+
+	// now, verify that all mandatory parameters are present in the dict
+	if err := verifyMandatoryArgs(dict, mandatory, "word problem"); err != nil {
+		return wordProblem{}, err
+	}
+
+	// make also sure that parameters are given with the right type
+	var ok bool
+	var err error
+	var operator, bank string
+	var geq, leq int
+	if operator, ok = dict["operator"].(string); !ok {
+		return wordProblem{}, errors.New("The operator of a word problem should be given as a string")
+	}
+	if geq, err = helpers.Atoi(dict["geq"]); err != nil {
+		return wordProblem{}, errors.New("the lower bound of the operands should be given as an integer")
+	}
+	if leq, err = helpers.Atoi(dict["leq"]); err != nil {
+		return wordProblem{}, errors.New("the upper bound of the operands should be given as an integer")
+	}
+
+	// next, check whether a bank of stories was given or not
+	if _, ok = dict["bank"]; ok {
+		if bank, ok = dict["bank"].(string); !ok {
+			return wordProblem{}, errors.New("the bank of stories should be given as the name of a file")
+		}
+	}
+
+	// convert the dictionary into typed options and verify them
+	options := WordProblemOptions{
+		Operator: operator,
+		Geq:      geq,
+		Leq:      leq,
+		Bank:     bank,
+	}
+	if err := options.Validate(); err != nil {
+		return wordProblem{}, err
+	}
+
+	// next, verify if there are some unnecessary parameters
+	if ok, key := helpers.VerifyKeys(dict, all); !ok {
+		log.Printf("Warning: The key '%v' is not necessary for creating a word problem and it will be ignored", key)
+	}
+
+	// otherwise, the dictionary is correct
+	return options.wordProblem()
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- MasterFile
+// ----------------------------------------------------------------------------
+
+// Return the input filename that shall store the template file to
+// generate the exercises
+func (masterFile MasterFile) GetInfile() string {
+	return masterFile.Infile
+}
+
+// Return the student's name of this master file
+func (masterFile MasterFile) GetName() string {
+	return masterFile.Name
+}
+
+// Return the student's class of this master file
+func (masterFile MasterFile) GetClass() string {
+	return masterFile.Class
+}
+
+// Return the student's difficulty level of this master file
+func (masterFile MasterFile) GetLevel() int {
+	return masterFile.Level
+}
+
+// Return the value of the given parameter of the student of this master file,
+// or the given value if the parameter was not given, e.g., {{.GetParam
+// "nbdigitsop" 2}}. Parameters can be also accessed directly as fields, e.g.,
+// {{.Params.nbdigitsop}}
+func (masterFile MasterFile) GetParam(key string, value interface{}) interface{} {
+
+	if param, ok := masterFile.Params[key]; ok {
+		return param
+	}
+	return value
+}
+
+// Return the output tex filename that shall contain the exercises in tex
+func (masterFile MasterFile) GetOutfile() string {
+	return masterFile.Outfile
+}
+
+// Return the directory where the output tex file shall be written
+func (masterFile MasterFile) GetOutdir() string {
+	return masterFile.Outdir
+}
+
+// Set the given variable of this master file to the given value, so that it
+// can be used anywhere else in the master file and the partials it includes
+// with Get, e.g., {{.Set "nbprobs" 6}}. Nothing is written
+func (masterFile MasterFile) Set(key string, value interface{}) (string, error) {
+
+	if masterFile.variables == nil {
+		return "", fmt.Errorf("The variable '%v' can not be set outside master files", key)
+	}
+	masterFile.variables[key] = value
+	return "", nil
+}
+
+// Return the value of the given variable of this master file set with Set,
+// e.g., {{range .Slice (.Get "nbprobs")}}. An error is returned if it was not
+// set before
+func (masterFile MasterFile) Get(key string) (interface{}, error) {
+
+	value, ok := masterFile.variables[key]
+	if !ok {
+		return nil, fmt.Errorf("The variable '%v' has not been set", key)
+	}
+	return value, nil
+}
+
+// the following function is provided just to allow the text/template to repeat
+// the same statement an arbitrary number of times. It just returns a slice of
+// MasterFiles of a given length. Each element is a copy of the receiver so that
+// it can then be used to invoke the various services provided for
+// text/templates
+func (masterFile MasterFile) Slice(n int) []MasterFile {
+	slice := make([]MasterFile, n)
+	for i := range slice {
+		slice[i] = masterFile
+	}
+	return slice
+}
+
+// TikZ reusable components
+//
+// The following meethods provide direct access to the TikZ reusable components
+// to be used in a master file directly
+// ----------------------------------------------------------------------------
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that create a coordinate with a label (identified with the key
+// "label") and located at a given position which can be identified either with
+// a position (using both keys "x" and "y") or a formula, with the key
+// "formula". The coordinates x and y must be given as floating-point numbers
+// whereas formulas should be given as strings.
+func (masterFile MasterFile) Coordinate(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var coord components.Coordinate
+	if coord, err = components.VerifyCoordinateDict(dict); err != nil {
+		return "", err
+	}
+
+	// otherwise return the string that represents this coordinate
+	return coord.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that create a text box located at a coordinate (either by providing
+// the coordinates of a Point or giving a Formula) with the contents
+// specified in the key "text"
+func (masterFile MasterFile) Text(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var text components.Text
+	if text, err = components.VerifyTextDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that shows up the contents of this text box
+	return text.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that draw a circle centered at the coordinate given in the key
+// "reference" (either a label or a formula) with the radius given in the key
+// "radius". Additional options can be given as a string in the key "options"
+func (masterFile MasterFile) Circle(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var circle components.Circle
+	if circle, err = components.VerifyCircleDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that draws this circle
+	return circle.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that draw an ellipse centered at the coordinate given in the key
+// "reference" (either a label or a formula) with the radii given in the keys
+// "xradius" and "yradius". Additional options can be given as a string in the
+// key "options"
+func (masterFile MasterFile) Ellipse(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var ellipse components.Ellipse
+	if ellipse, err = components.VerifyEllipseDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that draws this ellipse
+	return ellipse.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that draw an arrow from the coordinate given in the key "from" to
+// the one given in the key "to" (either labels or formulas). Optionally, it can
+// be double-headed with the key "double" and show a label at its midpoint with
+// the key "label". Additional options can be given as a string in the key
+// "options"
+func (masterFile MasterFile) Arrow(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var arrow components.Arrow
+	if arrow, err = components.VerifyArrowDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that draws this arrow
+	return arrow.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that draw a segment from the coordinate given in the key "from" to
+// the one given in the key "to" (either labels or formulas) labeled with the
+// length given in the key "length". If the key "brace" is true, the length is
+// shown over a brace, which requires the TikZ library
+// decorations.pathreplacing. Additional options can be given as a string in
+// the key "options"
+func (masterFile MasterFile) MeasuredSegment(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var segment components.MeasuredSegment
+	if segment, err = components.VerifyMeasuredSegmentDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that draws this segment
+	return segment.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that draw a grid of cells whose upper-left corner is located at the
+// coordinate given in the key "origin". Cells are given either with their text
+// in the key "cells" (rows separated by semicolons and cells by commas, framed
+// cells enclosed in square brackets) or as a grid of empty framed cells with
+// the keys "rows" and "cols". The size of the cells is given with the key
+// "step" or with "width" and "height", and additional options can be given as
+// a string in the key "options". Note that it is named differently than Grid,
+// which arranges problems in a grid
+func (masterFile MasterFile) CellGrid(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var grid components.Grid
+	if grid, err = components.VerifyGridDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that draws this grid
+	return grid.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that draw a closed polygon whose vertices are given in the keys
+// "ref0", "ref1", "ref2", ... (either labels or formulas). At least three
+// vertices should be given. Additional options can be given as a string in the
+// key "options", e.g., for filling it
+func (masterFile MasterFile) Polygon(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var polygon components.Polygon
+	if polygon, err = components.VerifyPolygonDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that draws this polygon
+	return polygon.String(), nil
+}
+
+// This method is intended to be used in master files. It is substituted by TikZ
+// contents that draw a regular polygon centered at the coordinate given in the
+// key "reference" (either a label or a formula) with the number of sides given
+// in the key "sides" and the distance from its center to every vertex given in
+// the key "radius". By default, its first vertex is right above its center,
+// and it can be rotated counterclockwise with the key "rotation" in degrees.
+// Additional options can be given as a string in the key "options"
+func (masterFile MasterFile) RegularPolygon(dict map[string]interface{}) (string, error) {
+
+	// first things first, verify that the given dictionary is correct
+	var err error
+	var polygon components.RegularPolygon
+	if polygon, err = components.VerifyRegularPolygonDict(dict); err != nil {
+		return "", err
+	}
+
+	// and return the string that draws this regular polygon
+	return polygon.String(), nil
+}
+
+// Angles
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates an angle problem with the
+// keywords given in the dictionary:
+//
+// type: either ANCLASSIFY (0), the angle has to be classified as acute, right or
+// obtuse, or ANMEASURE (1), the angle has to be measured in degrees
+// step: the measure of angles is a multiple of this number of degrees
+// (optional)
+// protractor: whether a protractor is drawn below the angle (optional)
+func (masterFile MasterFile) Angle(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	an, err := verifyAngleDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating an angle problem is incorrect: %v", err)
+	}
+
+	an.recorder = masterFile.recorder
+	return masterFile.number(an.execute())
+}
+
+// Bar charts
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a bar chart problem with
+// the keywords given in the dictionary:
+//
+// categories: comma-separated names of all categories
+// geq, leq: lower and upper bound of the values of all categories
+// mode: optional, either "read", the chart is drawn and questions have to be
+// answered, or "draw", data is given in a table and bars have to be drawn
+// questions: optional comma-separated questions among "value", "most", "least"
+// and "difference"
+func (masterFile MasterFile) BarChart(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	bc, err := verifyBarChartDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a bar chart is incorrect: %v", err)
+	}
+
+	bc.recorder = masterFile.recorder
+	return masterFile.number(bc.execute())
+}
+
+// Basic Operations
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a basic operation with
+// the keywords given in the dictionary. A dictionary is correct if and only if
+// it correctly provides a type of basic operation with the keyword "type", a
+// number of digits of the operands, and the result, and the number of operands
+// to show, with "nboperands", "nbdigitsop" and "nbdigitsrslt" respectively.
+// Optionally, subtractions can show boxes above every column of the first
+// operand for recording borrows with "scaffold", and additions can show boxes
+// above every column that might receive a carry with "carrybox". Operations are
+// shown in columns unless "layout" is "horizontal", in which case they are
+// shown in one single line. Operands can be bounded with "geq" and "leq",
+// e.g., (dict ... "geq" 25 "leq" 75) or (dict ... "geq" "3,10" "leq" "9,99")
+// to bound the first and second operands differently. Likewise, operands
+// can have a different number of digits, e.g., (dict ... "nbdigitsop" "3,2,1")
+func (masterFile MasterFile) BasicOperation(dict map[string]interface{}) (string, error) {
+
+	// verify the given dictionary is correct and get an instance of a valid
+	// basic operation
+	basicOperation, err := verifyBasicOperationDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a basic operation is incorrect: %v", err)
+	}
+
+	// and return the LaTeX/TikZ code for representing this sequence
+	basicOperation.recorder = masterFile.recorder
+	return masterFile.number(basicOperation.execute())
+}
+
+// Clocks
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a clock with the keywords
+// given in the dictionary:
+//
+// type: either CLOCKREAD (0) or CLOCKDRAW (1)
+// granularity: "hour", "half", "quarter", "five" or "minute"
+func (masterFile MasterFile) Clock(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	clk, err := verifyClockDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a clock is incorrect: %v", err)
+	}
+
+	clk.recorder = masterFile.recorder
+	return masterFile.number(clk.execute())
+}
+
+// Counting objects
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a counting problem with
+// the keywords given in the dictionary:
+//
+// geq, leq: lower and upper bound of the number of objects to count
+// symbol: optional symbol used for drawing objects: "star", "circle" or
+// "apple"
+// grouped: optional flag for showing objects in groups of five
+func (masterFile MasterFile) CountingObjects(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	co, err := verifyCountingObjectsDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a counting problem is incorrect: %v", err)
+	}
+
+	co.recorder = masterFile.recorder
+	return masterFile.number(co.execute())
+}
+
+// Divisions
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a division with the
+// keywords given in the dictionary:
+//
+// nbdvdigits: number of digits of the dividend
+// nbdrdigits: number of digits of the divisor
+// nbqdigits: number of digits of the quotient
+// extended: optionally, whether the step-by-step scaffold is shown or not
+// style: optionally, either "box", "bracket" or "potence"
+// remainder: optionally, either "random" (by default), "exact" or "nonzero"
+// remainderbox: optionally, whether a box is shown for writing the remainder
+// nbdecimals: optionally, number of decimals of the quotient
+// table: optionally, whether the products of the divisor by every digit are
+// shown to the right of the division. Only for divisors with two digits or more
+// dvgeq, dvleq: optionally, lower and upper bounds of the dividend
+// drgeq, drleq: optionally, lower and upper bounds of the divisor
+// excludedigits: optionally, digits that appear neither in the dividend nor in
+// the divisor
+// patterns: optionally, comma-separated patterns of the dividend and the
+// divisor, e.g., "x0"
+func (masterFile MasterFile) Division(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. Note
+	// that the types are not verified, only the presence of the
+	// keys. In case of an error, just return it
+	div, err := verifyDivisionDict(dict)
+	if err != nil {
+		return "", err
+	}
+
+	div.recorder = masterFile.recorder
+	return masterFile.number(div.execute())
+}
+
+// Elapsed time
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates an elapsed time problem
+// with the keywords given in the dictionary:
+//
+// type: either ETEND (0), ETDURATION (1) or ETSTART (2)
+// granularity: "hour", "half", "quarter", "five" or "minute"
+// from: optionally, the earliest start of events as "hh:mm"
+// to: optionally, the latest end of events as "hh:mm"
+// maxduration: optionally, the maximum duration of events in minutes
+func (masterFile MasterFile) ElapsedTime(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	et, err := verifyElapsedTimeDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating an elapsed time problem is incorrect: %v", err)
+	}
+
+	et.recorder = masterFile.recorder
+	return masterFile.number(et.execute())
+}
+
+// Equivalent Fractions
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates equivalent fractions with
+// the keywords given in the dictionary:
+//
+// type: 0 if the numerator of the scaled fraction is masked, 1 if its
+// denominator is masked instead
+// dengeq, denleq: lower and upper bound of the denominator of the base fraction
+// scalegeq, scaleleq: lower and upper bound of the scale factor
+func (masterFile MasterFile) EquivalentFraction(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	ef, err := verifyEquivalentFractionDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating an equivalent fraction is incorrect: %v", err)
+	}
+
+	ef.recorder = masterFile.recorder
+	return masterFile.number(ef.execute())
+}
+
+// Fact families
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a fact family triangle
+// with the keywords given in the dictionary:
+//
+// operator: either "+" for additions and subtractions or "*" for
+// multiplications and divisions
+// geq, leq: lower and upper bound of the numbers operated
+// facts: optional flag for requesting the related facts below the triangle
+func (masterFile MasterFile) FactFamily(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	ff, err := verifyFactFamilyDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a fact family is incorrect: %v", err)
+	}
+
+	ff.recorder = masterFile.recorder
+	return masterFile.number(ff.execute())
+}
+
+// Conversions between fractions, decimals and percentages
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a conversion between
+// fractions, decimals and percentages with the keywords given in the
+// dictionary:
+//
+// from: form of the value to convert: "fraction", "decimal" or "percent"
+// to: form of the converted value: "fraction", "decimal" or "percent"
+// denleq: optional upper bound of the denominator of the fractions used
+func (masterFile MasterFile) FDPConversion(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	fdp, err := verifyFDPConversionDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a conversion is incorrect: %v", err)
+	}
+
+	fdp.recorder = masterFile.recorder
+	return masterFile.number(fdp.execute())
+}
+
+// Grid paper
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates blank grid paper with the
+// keywords given in the dictionary:
+//
+// step: side of each cell in centimeters
+// cols, rows: number of columns and rows of the grid
+// style: either "square" or "lined"
+// margin: optional margin in centimeters left around the grid
+func (masterFile MasterFile) GridPaper(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	gp, err := verifyGridPaperDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating grid paper is incorrect: %v", err)
+	}
+
+	return gp.execute()
+}
+
+// Linear equations
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a linear equation with
+// the keywords given in the dictionary:
+//
+// type: 0 if the equation is solved in one step, 1 if it takes two steps
+// geq, leq: lower and upper bound of the solution
+// coefleq: optional largest coefficient. By default, 10
+// negative: optional flag for allowing negative numbers. By default, false
+func (masterFile MasterFile) LinearEquation(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	le, err := verifyLinearEquationDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a linear equation is incorrect: %v", err)
+	}
+
+	le.recorder = masterFile.recorder
+	return masterFile.number(le.execute())
+}
+
+// Magic squares
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a magic square with the
+// keywords given in the dictionary:
+//
+// size: number of rows and columns, either 3 or 4
+// geq, leq: optional lower and upper bound of the magic constant. By default,
+// 15 and 60
+// nbrevealed: optional number of revealed cells. By default, half the cells
+func (masterFile MasterFile) MagicSquare(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	ms, err := verifyMagicSquareDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a magic square is incorrect: %v", err)
+	}
+
+	ms.recorder = masterFile.recorder
+	return masterFile.number(ms.execute())
+}
+
+// Math crosswords
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates crossed operations with
+// the keywords given in the dictionary:
+//
+// size: number of operands of every equation, either 2 or 3
+// geq, leq: lower and upper bound of the operands
+// operators: optional string with the allowed operators among '+', '-' and
+// '*'. By default, "+-"
+// nbmasked: optional number of hidden numbers, up to 2*size+1. By default,
+// 2*size
+func (masterFile MasterFile) MathCrossword(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	mc, err := verifyMathCrosswordDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating crossed operations is incorrect: %v", err)
+	}
+
+	mc.recorder = masterFile.recorder
+	return masterFile.number(mc.execute())
+}
+
+// Mixed drills
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a page of basic
+// operations randomly chosen among different kinds with the keywords given in
+// the dictionary:
+//
+// operations: the basic operations to interleave, either as a comma-separated
+// string or as a list, each one given as "operator:nbdigitsop:nbdigitsrslt"
+// count: number of problems to draw
+// type: optional type of all basic operations, either BORESULT (0) or
+// BOOPERAND (1)
+// nboperands: optional number of operands of all basic operations
+// layout: optional layout of all basic operations, "vertical" or "horizontal"
+// cols: optional number of columns used for laying out all problems
+func (masterFile MasterFile) MixedDrill(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	md, err := verifyMixedDrillDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a mixed drill is incorrect: %v", err)
+	}
+
+	// and fill in every cell of the grid with a new problem until all of them
+	// have been drawn
+	md.recorder = masterFile.recorder
+	drawn := 0
+	return md.grid().execute(func() (string, error) {
+		if drawn >= md.count {
+			return "", nil
+		}
+		drawn++
+		return masterFile.number(md.execute())
+	})
+}
+
+// Money
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a money problem with the
+// keywords given in the dictionary:
+//
+// type: either MONEYTOTAL (0) or MONEYCHANGE (1)
+// geq: lower bound of the prices in whole units
+// leq: upper bound of the prices in whole units
+// currency: optionally, "EUR", "USD" or "GBP"
+// decimals: optionally, whether prices have cents or not
+// nbitems: optionally, the number of items to buy
+// coins: optionally, whether the amounts given are drawn with coins and bills
+func (masterFile MasterFile) Money(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	m, err := verifyMoneyDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a money problem is incorrect: %v", err)
+	}
+
+	m.recorder = masterFile.recorder
+	return masterFile.number(m.execute())
+}
+
+// Multiplication Tables
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a multiplication table
+// with the keywords given in the dictionary:
+//
+// type: type of the multiplication table
+// nbdigits: number of digits of the factor
+// geq, leq: lower and upper bound of the numbers used
+// inv: whether numbers are shown in the regular order or inverted
+// sorted: whether rows are shown in sorted order or not
+// layout: either "block" (by default) to show the table in one block per line,
+// "twocolumn" to show two tables side by side, or "grid" to show a grid of facts
+// with the numbers in [geq, leq] in random order on both axes
+// excludedigits: optionally, digits that do not appear in the factor nor in the
+// numbers it is multiplied by, e.g., "0,1" to avoid trivial rows
+// patterns: optionally, comma-separated patterns of the factor, e.g., "x5"
+func (masterFile MasterFile) MultiplicationTable(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	mt, err := verifyMultiplicationTableDict(dict)
+	if err != nil {
+		return "", err
+	}
+
+	mt.recorder = masterFile.recorder
+	return masterFile.number(mt.execute())
+}
+
+// Mystery Operations
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a mystery operation with
+// the keywords given in the dictionary:
+//
+// operator: either "+", "-", "*" or "/"
+// nbdigits1, nbdigits2: number of digits of the first and second operand
+// nbdigitsanswer: number of digits of the answer
+// nbmasked1, nbmasked2, nbmaskedanswer: number of masked digits of the first
+// and second operand, and the answer
+func (masterFile MasterFile) MysteryOperation(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	mo, err := verifyMysteryOperationDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a mystery operation is incorrect: %v", err)
+	}
+
+	mo.recorder = masterFile.recorder
+	return masterFile.number(mo.execute())
+}
+
+// Number classifications
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a number classification
+// with the keywords given in the dictionary:
+//
+// type: either CLASSEVENODD (0), even numbers have to be circled, or
+// CLASSMULTIPLE (1), multiples of a given number have to be circled
+// geq, leq: lower and upper bound of the numbers to classify
+// nbnumbers: number of numbers to classify
+// multiple: optional number whose multiples have to be circled
+// nbcols: optional number of numbers shown in every row
+func (masterFile MasterFile) NumberClassification(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	cl, err := verifyNumberClassificationDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a number classification is incorrect: %v", err)
+	}
+
+	cl.recorder = masterFile.recorder
+	return masterFile.number(cl.execute())
+}
+
+// Number comparisons
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a number comparison with
+// the keywords given in the dictionary:
+//
+// nbdigits: number of digits of all numbers
+// nbitems: optional number of numbers compared in the same row
+// negative: optional flag for generating negative numbers as well
+func (masterFile MasterFile) NumberComparison(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	nc, err := verifyNumberComparisonDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a number comparison is incorrect: %v", err)
+	}
+
+	nc.recorder = masterFile.recorder
+	return masterFile.number(nc.execute())
+}
+
+// Forms of numbers
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a conversion between
+// forms of numbers with the keywords given in the dictionary:
+//
+// from: form of the number to convert: "standard", "expanded" or "words"
+// to: form of the converted number: "standard", "expanded" or "words"
+// nbdigits: number of digits of the number to convert
+// locale: optional language for writing numbers in words, "en" or "es"
+func (masterFile MasterFile) NumberForm(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	nf, err := verifyNumberFormDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a conversion between forms of numbers is incorrect: %v", err)
+	}
+
+	nf.recorder = masterFile.recorder
+	return masterFile.number(nf.execute())
+}
+
+// Number lines
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a number line with the
+// keywords given in the dictionary:
+//
+// type: either NLFILL (0) or NLMARK (1)
+// geq: lower bound of the values shown
+// leq: upper bound of the values shown
+// step: difference between the values of consecutive ticks
+// nbticks: optional number of ticks
+// hidden: optional comma-separated string with the indices of hidden ticks
+// orientation: optional orientation, either "horizontal" or "vertical"
+func (masterFile MasterFile) NumberLine(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	nl, err := verifyNumberLineDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a number line is incorrect: %v", err)
+	}
+
+	nl.recorder = masterFile.recorder
+	return masterFile.number(nl.execute())
+}
+
+// Number pyramids
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a number pyramid with
+// the keywords given in the dictionary:
+//
+// height: number of rows of the pyramid
+// geq, leq: lower and upper bound of the numbers in the base
+// reveal: optional cells revealed, either "base" (by default) or "random"
+// nbrevealed: optional number of cells randomly revealed. By default, the
+// height
+func (masterFile MasterFile) NumberPyramid(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	np, err := verifyNumberPyramidDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a number pyramid is incorrect: %v", err)
+	}
+
+	np.recorder = masterFile.recorder
+	return masterFile.number(np.execute())
+}
+
+// Percentages
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a percentage with the
+// keywords given in the dictionary:
+//
+// type: either 0 (the result has to be guessed), 1 (the percentage has to be
+// guessed) or 2 (the base has to be guessed)
+// geq, leq: lower and upper bound of the base
+// pctgeq, pctleq: optional lower and upper bound of the percentage. By
+// default, 1 and 100
+// decimals: optional number of decimals of the result, up to 2. By default, 0
+func (masterFile MasterFile) Percentage(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	pc, err := verifyPercentageDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a percentage is incorrect: %v", err)
+	}
+
+	pc.recorder = masterFile.recorder
+	return masterFile.number(pc.execute())
+}
+
+// Perimeters and areas
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a perimeter and area
+// problem with the keywords given in the dictionary:
+//
+// type: either 0 (the perimeter has to be guessed), 1 (the area has to be
+// guessed) or 2 (the area is given and the height has to be guessed)
+// shape: either "rectangle" or "triangle"
+// geq, leq: lower and upper bound of all lengths
+func (masterFile MasterFile) PerimeterArea(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	pa, err := verifyPerimeterAreaDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a perimeter and area problem is incorrect: %v", err)
+	}
+
+	pa.recorder = masterFile.recorder
+	return masterFile.number(pa.execute())
 }
 
-// TikZ reusable components
-//
-// The following meethods provide direct access to the TikZ reusable components
-// to be used in a master file directly
+// Pictograms
 // ----------------------------------------------------------------------------
 
-// This method is intended to be used in master files. It is substituted by TikZ
-// contents that create a coordinate with a label (identified with the key
-// "label") and located at a given position which can be identified either with
-// a position (using both keys "x" and "y") or a formula, with the key
-// "formula". The coordinates x and y must be given as floating-point numbers
-// whereas formulas should be given as strings.
-func (masterFile MasterFile) Coordinate(dict map[string]interface{}) string {
+// Return the LaTeX code in TikZ format that generates a pictogram with the
+// keywords given in the dictionary:
+//
+// categories: comma-separated names of all categories
+// geq, leq: lower and upper bound of the values of all categories
+// chart: optional form used for showing data: "tally" or "pictogram"
+// symbol: optional symbol used in pictograms: "star", "circle" or "apple"
+// questions: optional comma-separated questions among "most", "least" and
+// "total"
+func (masterFile MasterFile) Pictogram(dict map[string]interface{}) (string, error) {
 
-	// first things first, verify that the given dictionary is correct
-	var err error
-	var coord components.Coordinate
-	if coord, err = components.VerifyCoordinateDict(dict); err != nil {
-		log.Fatal(err)
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	pic, err := verifyPictogramDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a pictogram is incorrect: %v", err)
 	}
 
-	// otherwise return the string that represents this coordinate
-	return coord.String()
+	pic.recorder = masterFile.recorder
+	return masterFile.number(pic.execute())
 }
 
-// This method is intended to be used in master files. It is substituted by TikZ
-// contents that create a text box located at a coordinate (either by providing
-// the coordinates of a Point or giving a Formula) with the contents
-// specified in the key "text"
-func (masterFile MasterFile) Text(dict map[string]interface{}) string {
+// Place values
+// ----------------------------------------------------------------------------
 
-	// first things first, verify that the given dictionary is correct
-	var err error
-	var text components.Text
-	if text, err = components.VerifyTextDict(dict); err != nil {
-		log.Fatal(err)
+// Return the LaTeX code in TikZ format that generates a place value problem
+// with the keywords given in the dictionary:
+//
+// type: 0 if the number has to be decomposed into the digits of its places, 1
+// if it has to be composed from them
+// nbdigits: number of digits of the number
+// masked: optional comma-separated list with the names of the masked places
+// nbmasked: optional number of places randomly masked
+func (masterFile MasterFile) PlaceValue(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	pv, err := verifyPlaceValueDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a place value problem is incorrect: %v", err)
 	}
 
-	// and return the string that shows up the contents of this text box
-	return text.String()
+	pv.recorder = masterFile.recorder
+	return masterFile.number(pv.execute())
 }
 
-// Basic Operations
+// Prime factorizations
 // ----------------------------------------------------------------------------
 
-// Return the LaTeX code in TikZ format that generates a basic operation with
-// the keywords given in the dictionary. A dictionary is correct if and only if
-// it correctly provides a type of basic operation with the keyword "type", a
-// number of digits of the operands, and the result, and the number of operands
-// to show, with "nboperands", "nbdigitsop" and "nbdigitsrslt" respectively
-func (masterFile MasterFile) BasicOperation(dict map[string]interface{}) string {
+// Return the LaTeX code in TikZ format that generates a factor tree with the
+// keywords given in the dictionary:
+//
+// geq, leq: lower and upper bound of the number to decompose
+// maxfactors: optional maximum number of prime factors. By default, 4
+func (masterFile MasterFile) PrimeFactorization(dict map[string]interface{}) (string, error) {
 
-	// verify the given dictionary is correct and get an instance of a valid
-	// basic operation
-	basicOperation, err := verifyBasicOperationDict(dict)
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	pf, err := verifyPrimeFactorizationDict(dict)
 	if err != nil {
-		log.Fatalf("The dictionary given for creating a basic operation is incorrect: %v", err)
+		return "", fmt.Errorf("The dictionary given for creating a prime factorization is incorrect: %v", err)
 	}
 
-	// and return the LaTeX/TikZ code for representing this sequence
-	return basicOperation.execute()
+	pf.recorder = masterFile.recorder
+	return masterFile.number(pf.execute())
 }
 
-// Divisions
+// Ratios
 // ----------------------------------------------------------------------------
 
-// Return the LaTeX code in TikZ format that generates a division with the
-// keywords given in the dictionary:
+// Return the LaTeX code in TikZ format that generates a ratio with the keywords
+// given in the dictionary:
 //
-// nbdvdigits: number of digits of the dividend
-// nbdrdigits: number of digits of the divisor
-// nbqdigits: number of digits of the quotient
-func (masterFile MasterFile) Division(dict map[string]interface{}) string {
+// type: 0 if the second scaled quantity has to be guessed, 1 if the first one
+// has to be guessed instead
+// geq, leq: lower and upper bound of both terms of the ratio
+// scalegeq, scaleleq: lower and upper bound of the scale factor
+func (masterFile MasterFile) Ratio(dict map[string]interface{}) (string, error) {
 
-	// Verify the given keys in the dictionary are correct. Note
-	// that the types are not verified, only the presence of the
-	// keys. In case of an error, just generate a fatal error
-	div, err := verifyDivisionDict(dict)
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	rt, err := verifyRatioDict(dict)
 	if err != nil {
-		log.Fatalf("%v", err)
+		return "", fmt.Errorf("The dictionary given for creating a ratio is incorrect: %v", err)
 	}
 
-	return div.execute()
+	rt.recorder = masterFile.recorder
+	return masterFile.number(rt.execute())
 }
 
-// Multiplication Tables
+// Rounding
 // ----------------------------------------------------------------------------
 
-// Return the LaTeX code in TikZ format that generates a multiplication table
-// with the keywords given in the dictionary:
+// Return the LaTeX code in TikZ format that generates a rounding problem with
+// the keywords given in the dictionary:
 //
-// type: type of the multiplication table
-// nbdigits: number of digits of the factor
-// geq, leq: lower and upper bound of the numbers used
-// inv: whether numbers are shown in the regular order or inverted
-// sorted: whether rows are shown in sorted order or not
-func (masterFile MasterFile) MultiplicationTable(dict map[string]interface{}) string {
+// nbdigits: number of digits of the number to round
+// place: place the number has to be rounded to, e.g., "ten" or "hundred"
+// tiebreak: optional rule for rounding numbers halfway between two multiples,
+// either "up" (by default), "even" or "away"
+// highlight: optional flag for highlighting the digit of the place
+func (masterFile MasterFile) Rounding(dict map[string]interface{}) (string, error) {
 
 	// Verify the given keys in the dictionary are correct. In case of an error,
-	// just generate a fatal error
-	mt, err := verifyMultiplicationTableDict(dict)
+	// just return it
+	rd, err := verifyRoundingDict(dict)
 	if err != nil {
-		log.Fatalf("%v", err)
+		return "", fmt.Errorf("The dictionary given for creating a rounding problem is incorrect: %v", err)
 	}
 
-	return mt.execute()
+	rd.recorder = masterFile.recorder
+	return masterFile.number(rd.execute())
 }
 
 // Sequences
@@ -545,61 +4185,437 @@ func (masterFile MasterFile) MultiplicationTable(dict map[string]interface{}) st
 // addition, a sequence is made up of a number of items, each one greater or
 // equal than a given threshold and lower or equal than another bound using the
 // keywords "geq" and "leq" respectively
-func (masterFile MasterFile) Sequence(dict map[string]interface{}) string {
+func (masterFile MasterFile) Sequence(dict map[string]interface{}) (string, error) {
 
 	// verify the given dictionary is correct and get an instance of a valid
 	// sequence
 	sequence, err := verifySequenceDict(dict)
 	if err != nil {
-		log.Fatalf("The dictionary given for creating a sequence is incorrect: %v", err)
+		return "", fmt.Errorf("The dictionary given for creating a sequence is incorrect: %v", err)
 	}
 
 	// and return the LaTeX/TikZ code for representing this sequence
-	return sequence.execute()
+	sequence.recorder = masterFile.recorder
+	return masterFile.number(sequence.execute())
 }
 
-// templates
+// Shaded fractions
 // ----------------------------------------------------------------------------
 
-// Parse the template given in contents to a masterfile and returns the result
-// in a buffer, and nil if no error was found
-func (masterFile MasterFile) masterToBufferFromTemplate(contents string) (bytes.Buffer, error) {
+// Return the LaTeX code in TikZ format that generates a shaded fraction with
+// the keywords given in the dictionary:
+//
+// type: either 0 (the fraction represented has to be written) or 1 (the
+// fraction is given and it has to be shaded)
+// dengeq, denleq: lower and upper bound of the denominator
+// shape: optional shape, either "pie" or "bar". By default, "pie"
+// contiguous: optional, if true shaded parts are consecutive. By default, false
+func (masterFile MasterFile) ShadedFraction(dict map[string]interface{}) (string, error) {
 
-	// create the buffer to return the result of the execution
-	var result bytes.Buffer
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	sf, err := verifyShadedFractionDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a shaded fraction is incorrect: %v", err)
+	}
+
+	sf.recorder = masterFile.recorder
+	return masterFile.number(sf.execute())
+}
+
+// Unit conversions
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a unit conversion with
+// the keywords given in the dictionary:
+//
+// quantity: "length", "mass" or "capacity"
+// direction: optionally, "down", "up" or "both"
+// geq: optionally, lower bound of the values to convert
+// leq: optionally, upper bound of the values to convert
+// decimals: optionally, number of decimals of the values to convert
+func (masterFile MasterFile) UnitConversion(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	uc, err := verifyUnitConversionDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a unit conversion is incorrect: %v", err)
+	}
+
+	uc.recorder = masterFile.recorder
+	return masterFile.number(uc.execute())
+}
+
+// Word problems
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a word problem with the
+// keywords given in the dictionary:
+//
+// operator: either "+", "-", "*" or "/"
+// geq: lower bound of the operands
+// leq: upper bound of the operands
+// bank: optional name of a file with a bank of stories in JSON format
+func (masterFile MasterFile) WordProblem(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	wp, err := verifyWordProblemDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a word problem is incorrect: %v", err)
+	}
+
+	wp.recorder = masterFile.recorder
+	return masterFile.number(wp.execute())
+}
+
+// Registered problems
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code in TikZ format that generates a problem of the given
+// type, which has to be registered (see Register), with the keywords given in
+// the dictionary. This allows using problem types defined outside this package
+// in master files
+func (masterFile MasterFile) Problem(name string, dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	gen, err := masterGenerator(name, dict)
+	if err != nil {
+		return "", err
+	}
+
+	// and draw the next problem
+	return masterFile.draw(name, gen)
+}
+
+// return a generator of problems of the given type, which has to be registered
+// and usable in master files, verified with the given dictionary. If it is not
+// possible, an error is returned
+func masterGenerator(name string, dict map[string]interface{}) (ProblemGenerator, error) {
+
+	// First, make sure this problem type exists and that it can be used in
+	// master files
+	entry, ok := lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("Unknown problem type '%v'", name)
+	}
+	if !entry.description.Master {
+		return nil, fmt.Errorf("The problem type '%v' can not be used in master files", name)
+	}
+
+	// Verify the given keys in the dictionary are correct
+	gen := entry.factory()
+	if err := gen.Verify(dict); err != nil {
+		return nil, fmt.Errorf("The dictionary given for creating a problem of type '%v' is incorrect: %v", name, err)
+	}
+	return gen, nil
+}
+
+// return the LaTeX code of the next problem of the given type drawn with the
+// given generator, either generating it or replaying it
+func (masterFile MasterFile) draw(name string, gen ProblemGenerator) (string, error) {
+
+	instance, err := masterFile.next(gen.GenerateJSON)
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid problem of type '%v': %v", name, err)
+	}
+	return masterFile.number(gen.TikZ(instance, masterFile.showAnswers()))
+}
+
+// Layout
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code that lays out a grid of problems of the same type with
+// the keywords given in the dictionary:
+//
+// rows, cols: number of rows and columns of the grid
+// problem: name of the problem type, e.g., "BasicOperation"
+// args: dictionary used for defining every problem
+// vspace: optional vertical space between rows given as a LaTeX length
+// pagerows: optional number of rows after which a page break is forced
+//
+// Every problem is generated independently, so that all cells show different
+// problems
+func (masterFile MasterFile) Grid(dict map[string]interface{}) (string, error) {
+
+	// Verify the given keys in the dictionary are correct. In case of an error,
+	// just return it
+	grid, err := verifyGridDict(dict)
+	if err != nil {
+		return "", fmt.Errorf("The dictionary given for creating a grid is incorrect: %v", err)
+	}
+	gen, err := masterGenerator(grid.problem, grid.args)
+	if err != nil {
+		return "", err
+	}
+
+	// and fill in every cell with a new problem
+	return grid.execute(func() (string, error) {
+		return masterFile.draw(grid.problem, gen)
+	})
+}
+
+// Headers and footers
+// ----------------------------------------------------------------------------
+
+// Return the LaTeX code that decorates every page, from the current one on,
+// with a header and a footer. Because they are shown in every page, they are
+// repeated whenever the contents are broken across pages. It optionally
+// receives a dictionary with the following keywords:
+//
+// title: title of the sheet shown in the center of the header, none by default
+// logo: name of an image file shown to the right of the header, none by default
+// student: whether the student's name and class are shown, true by default
+// date: whether the date is shown, true by default
+// score: number of points of the sheet, if a score box has to be shown
+// pagenumbers: whether page numbers are shown, true by default
+func (masterFile MasterFile) PageStyle(dicts ...map[string]interface{}) (string, error) {
 
-	// access a template and parse its contents. In addition it registers a
-	// function "dict" which allows the user to introduce in the text template
-	// any arguments
-	t := template.Must(template.New(contents).Funcs(template.FuncMap{
-		"dict": func(values ...interface{}) (map[string]interface{}, error) {
-
-			// if the number of items is not even (as many
-			// pairs of the form "Key" "Value" should be
-			// given) then an error is raised
-			if len(values)%2 != 0 {
-				return nil, errors.New("Invalid dict call. There should be an even number of arguments of the form 'Key' 'Value'")
+	// process the optional dictionary
+	ps := pageStyle{
+		name:        masterFile.Name,
+		class:       masterFile.Class,
+		student:     true,
+		date:        true,
+		pagenumbers: true,
+	}
+	if len(dicts) > 1 {
+		return "", errors.New("The page style accepts at most one dictionary")
+	}
+	if len(dicts) == 1 {
+		dict := dicts[0]
+		var ok bool
+		var err error
+		if _, ok = dict["title"]; ok {
+			if ps.title, ok = dict["title"].(string); !ok {
+				return "", errors.New("The title of the page style should be given as a string")
+			}
+		}
+		if _, ok = dict["logo"]; ok {
+			if ps.logo, ok = dict["logo"].(string); !ok {
+				return "", errors.New("The logo of the page style should be given as a string with the name of an image file")
+			}
+		}
+		if _, ok = dict["student"]; ok {
+			if ps.student, err = helpers.Atob(dict["student"]); err != nil {
+				return "", errors.New("The flag for showing the student's name and class should be given as a bool")
+			}
+		}
+		if _, ok = dict["date"]; ok {
+			if ps.date, err = helpers.Atob(dict["date"]); err != nil {
+				return "", errors.New("The flag for showing the date should be given as a bool")
 			}
+		}
+		if _, ok = dict["score"]; ok {
+			if ps.score, err = helpers.Atoi(dict["score"]); err != nil || ps.score < 0 {
+				return "", errors.New("The score of the page style should be given as a non-negative integer")
+			}
+		}
+		if _, ok = dict["pagenumbers"]; ok {
+			if ps.pagenumbers, err = helpers.Atob(dict["pagenumbers"]); err != nil {
+				return "", errors.New("The flag for showing page numbers should be given as a bool")
+			}
+		}
+		if ok, key := helpers.VerifyKeys(dict, []string{"title", "logo", "student", "date", "score", "pagenumbers"}); !ok {
+			log.Printf("Warning: The key '%v' is not necessary for creating the page style and it will be ignored", key)
+		}
+	}
+	return ps.execute()
+}
+
+// Numbering and answers
+// ----------------------------------------------------------------------------
+
+// return the given LaTeX code of a problem preceded by its number if problems
+// have to be numbered. Problems are numbered consecutively starting from one
+// in the same order they are drawn. Errors are returned as they are given
+func (masterFile MasterFile) number(code string, err error) (string, error) {
+
+	if err != nil || !masterFile.Numbered {
+		return code, err
+	}
+	return fmt.Sprintf("\\textbf{%v.}~%v", masterFile.count(), code), nil
+}
+
+// return the answer of the given problem, i.e., the solution of all its
+// masked arguments separated by commas. If no argument is masked, then its
+// full solution is given instead
+func problemAnswer(problem ProblemJSON) string {
+
+	var items []string
+	for idx, arg := range problem.Args {
+		if arg == "?" && idx < len(problem.Solution) {
+			items = append(items, problem.Solution[idx])
+		}
+	}
+	if len(items) == 0 {
+		items = problem.Solution
+	}
+	return strings.Join(items, ", ")
+}
 
-			// Create a map with as many elements as keys
-			// have been specified
-			dict := make(map[string]interface{}, len(values)/2)
-
-			// and process them
-			for i := 0; i < len(values); i += 2 {
-				key, ok := values[i].(string)
-				if !ok {
-					return nil, errors.New("Dict keys must be strings")
-				}
-				dict[key] = values[i+1]
+// Return the LaTeX code of a compact list with the answers of all problems
+// drawn so far, each one preceded by its number. It is intended to be used at
+// the end of master files, and it optionally receives a dictionary with the
+// following keywords:
+//
+// title: title of the section, "Answers" by default
+// newpage: whether the section is started in a new page, false by default
+func (masterFile MasterFile) AnswerSection(dicts ...map[string]interface{}) (string, error) {
+
+	// process the optional dictionary
+	title, newpage := "Answers", false
+	if len(dicts) > 1 {
+		return "", errors.New("The answer section accepts at most one dictionary")
+	}
+	if len(dicts) == 1 {
+		dict := dicts[0]
+		var ok bool
+		var err error
+		if _, ok = dict["title"]; ok {
+			if title, ok = dict["title"].(string); !ok {
+				return "", errors.New("The title of the answer section should be given as a string")
+			}
+		}
+		if _, ok = dict["newpage"]; ok {
+			if newpage, err = helpers.Atob(dict["newpage"]); err != nil {
+				return "", errors.New("The flag for starting the answer section in a new page should be given as a bool")
 			}
+		}
+		if ok, key := helpers.VerifyKeys(dict, []string{"title", "newpage"}); !ok {
+			log.Printf("Warning: The key '%v' is not necessary for creating the answer section and it will be ignored", key)
+		}
+	}
+
+	var output bytes.Buffer
+	if newpage {
+		output.WriteString("\\newpage\n")
+	}
+	fmt.Fprintf(&output, "\\section*{%v}\n\n\\noindent\n", latexEscape(title))
+
+	// only the problems drawn so far are shown. Note that when replaying the
+	// problems for generating the answer key all of them have been already
+	// recorded
+	for idx, problem := range (*masterFile.solutions)[:masterFile.count()] {
+		fmt.Fprintf(&output, "\\textbf{%v.}~%v\\quad\n", idx+1, latexEscape(problemAnswer(problem)))
+	}
+	return output.String(), nil
+}
+
+// Partials
+// ----------------------------------------------------------------------------
+
+// Return the result of executing the template stored in the given file with
+// this master file, so that reusable sections can be shared among many master
+// files. Relative paths are resolved with respect to the directory of the
+// master file. Partials can be also used with the action {{template "path" .}}
+func (masterFile MasterFile) Include(path string) (string, error) {
+
+	// make sure partials do not include themselves forever
+	if masterFile.includes >= MAXINCLUDEDEPTH {
+		return "", fmt.Errorf("The partial '%v' exceeds the maximum depth of nested inclusions (%v)", path, MAXINCLUDEDEPTH)
+	}
+	masterFile.includes++
+
+	dir := filepath.Dir(masterFile.Infile)
+	t, err := partialTemplate(resolvePartial(path, dir), dir)
+	if err != nil {
+		return "", err
+	}
+	if t, err = masterFile.bind(t); err != nil {
+		return "", err
+	}
+	var output bytes.Buffer
+	if err := t.Execute(&output, masterFile); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// templates
+// ----------------------------------------------------------------------------
+
+// Remove all templates from the cache, so that master files and their partials
+// are read and parsed again the next time they are used, e.g., after they have
+// been modified
+func ClearTemplateCache() {
 
-			// at this point no error has been reported, move therefore back
-			return dict, nil
-		}}).Parse(contents))
+	templateCacheMutex.Lock()
+	defer templateCacheMutex.Unlock()
 
-	// execute the template with the information in this instance
-	err := t.Execute(&result, masterFile)
+	templateCache = make(map[string]*template.Template)
+}
+
+// Return the template stored in the given master file. Master files are read
+// and parsed only once, and the resulting template is kept in a cache so that
+// all records sharing the same master file reuse it. If the master file could
+// not be accessed or parsed, an error is returned
+func masterTemplate(infile string) (*template.Template, error) {
+	return partialTemplate(infile, filepath.Dir(infile))
+}
+
+// Return the template stored in the given file, where all the partials it
+// refers to are resolved relative to the given directory. Templates are cached
+// by both the file and the directory
+func partialTemplate(infile, dir string) (*template.Template, error) {
+
+	// make sure the cache is accessed in mutual exclusion
+	templateCacheMutex.Lock()
+	defer templateCacheMutex.Unlock()
+
+	// if this master file has been already processed, then return the template
+	// straight away
+	key := infile + "\x00" + dir
+	if t, ok := templateCache[key]; ok {
+		return t, nil
+	}
+
+	// verify that the given master file exists and is accessible
+	masterisregular, _ := fstools.IsRegular(infile)
+	if !masterisregular {
+		return nil, fmt.Errorf("the master file '%s' does not exist or is not accessible", infile)
+	}
+
+	// these files are expected to be not too long, actually, so read the entire
+	// contents of the file into main memory
+	contents, err := ioutil.ReadFile(infile)
+	if err != nil {
+		return nil, fmt.Errorf("It was not possible to read the input file '%v'", infile)
+	}
+
+	// access a template and parse its contents along with all the partials it
+	// refers to
+	t, err := template.New(infile).Funcs(masterFuncs).Funcs(randomFuncs(nil)).Parse(string(contents))
+	if err != nil {
+		return nil, err
+	}
+	if err := parsePartials(t, string(contents), dir); err != nil {
+		return nil, err
+	}
+
+	// and store it in the cache before returning it
+	templateCache[key] = t
+	return t, nil
+}
+
+// Execute the given template over a masterfile and returns the result in a
+// buffer, and nil if no error was found
+func (masterFile MasterFile) masterToBufferFromTemplate(t *template.Template) (bytes.Buffer, error) {
+
+	// create the buffer to return the result of the execution
+	var result bytes.Buffer
+
+	// execute the template with the information in this instance, drawing
+	// random values with its own source of random numbers. Variables are
+	// set anew every time the template is executed
+	masterFile.variables = make(map[string]interface{})
+	t, err := masterFile.bind(t)
+	if err != nil {
+		return result, err
+	}
+	err = t.Execute(&result, masterFile)
 	if err != nil {
 
 		// note that the result might contain some partial results
@@ -610,60 +4626,128 @@ func (masterFile MasterFile) masterToBufferFromTemplate(contents string) (bytes.
 	return result, nil
 }
 
-// Writes into the specified dst file the result of instantiating the
-// given master file
-func (masterFile MasterFile) MasterToFileFromTemplate(dst string) {
+// Writes into the specified dst file the result of instantiating the given
+// master file. If the master file could not be processed or the results could
+// not be written, an error is returned. The generation stops as soon as the
+// given context is done, in which case no file is written
+func (masterFile MasterFile) MasterToFileFromTemplate(ctx context.Context, dst string) error {
 
-	// verify that the given master file exists and is accessible
-	masterisregular, _ := fstools.IsRegular(masterFile.Infile)
-	if !masterisregular {
-		log.Fatalf("the master file '%s' does not exist or is not accessible",
-			masterFile.Infile)
+	// get the template stored in the master file
+	t, err := masterTemplate(masterFile.Infile)
+	if err != nil {
+		return err
 	}
 
-	// these files are expected to be not too long, actually, so read the entire
-	// contents of the file into main memory
-	contents, err := ioutil.ReadFile(masterFile.Infile)
+	// all problems are generated with the same source of random numbers, and
+	// they are recorded in a slice while executing the template, so that they
+	// can be numbered, listed in an answer section and written as solutions or
+	// answer keys if requested
+	//
+	// The values drawn with the random functions of master files use their own
+	// source of random numbers, so that they can be drawn again when
+	// generating the answer key
+	var solutions []ProblemJSON
+	seed := masterFile.Seed
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
+	}
+	masterFile.recorder = recorder{rnd: newRand(masterFile.Seed), draws: newRand(seed), solutions: &solutions, band: masterFile.Difficulty, ctx: ctx}
+
+	// if problems have to be unique, then keep track of all of them in a
+	// generation context
+	if masterFile.Unique {
+		masterFile.recorder.context = newGenerationContext(masterFile.UniqueAttempts)
+	}
+
+	// execute the template. This is done before creating the output file so
+	// that no file is written in case of an error
+	result, err := masterFile.masterToBufferFromTemplate(t)
 	if err != nil {
-		log.Fatalf("It was not possible to read the input file '%v'", masterFile.Infile)
+		return fmt.Errorf("Error when executing the template over the master file '%v': %v", masterFile.Infile, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	// if the given filename already exists, then number it and so on until the
-	// resulting filename does not exist. If re-numbering is required, start
-	// with index 2
+	// if the given filename already exists and it should not be overwritten,
+	// then number it and so on until the resulting filename does not exist. If
+	// re-numbering is required, start with index 2. Files are created
+	// exclusively so that sheets generated concurrently never share the same
+	// file
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if masterFile.Overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
 	index := 2
 	current := dst
-	for _, err := os.Stat(dst); err == nil; {
+	file, err := os.OpenFile(dst, flags, 0644)
+	for os.IsExist(err) {
 		log.Printf("The file '%v' already exists", dst)
 
-		// renumber this filename
-		dst = fstools.NumberFilename(current, index)
-
-		// move forward to the next index and perform the test
+		// renumber this filename, move forward to the next index and try
 		// again
+		dst = fstools.NumberFilename(current, index)
 		index += 1
-		_, err = os.Stat(dst)
+		file, err = os.OpenFile(dst, flags, 0644)
 	}
-
-	// now, open the file in read/write mode
-	file, err := os.Create(dst)
 	if err != nil {
-		log.Fatalf("It was not possible to create the file '%v'", dst)
+		return fmt.Errorf("It was not possible to create the file '%v'", dst)
 	}
 
 	// make sure the file is closed before leaving
 	defer file.Close()
 
-	// execute the template
-	result, err := masterFile.masterToBufferFromTemplate(string(contents))
-	if err != nil {
-		log.Fatalf("Error when executing the template over the master file", result)
-	}
-
 	// and write the result in the output file
 	if _, err := file.WriteString(result.String()); err != nil {
-		log.Fatalf("Error while writing the result of a template in '%v'", dst)
+		return fmt.Errorf("Error while writing the result of a template in '%v'", dst)
 	}
+
+	// finally, write the solutions in JSON format to a sibling file, if
+	// requested
+	if masterFile.Solutions {
+
+		// in case no problem was generated, make sure an empty list is written
+		if solutions == nil {
+			solutions = []ProblemJSON{}
+		}
+		data, err := json.MarshalIndent(solutions, "", "\t")
+		if err != nil {
+			return fmt.Errorf("Error while marshalling the solutions of '%v': %v", dst, err)
+		}
+		solutionsFilename := strings.TrimSuffix(dst, ".tex") + ".solutions.json"
+		if err := ioutil.WriteFile(solutionsFilename, data, 0644); err != nil {
+			return fmt.Errorf("Error while writing the solutions in '%v'", solutionsFilename)
+		}
+	}
+
+	// and also the answer key, if requested. For this, the template is
+	// executed again replaying the same problems in the same order, so that
+	// their solutions are shown
+	if masterFile.Answers {
+
+		index := 0
+		masterFile.recorder = recorder{solutions: &solutions, replay: &index, draws: newRand(seed), ctx: ctx}
+		answers, err := masterFile.masterToBufferFromTemplate(t)
+		if err != nil {
+			return fmt.Errorf("Error when generating the answer key of the master file '%v': %v", masterFile.Infile, err)
+		}
+		answersFilename := strings.TrimSuffix(dst, ".tex") + ".answers.tex"
+		if err := ioutil.WriteFile(answersFilename, answers.Bytes(), 0644); err != nil {
+			return fmt.Errorf("Error while writing the answer key in '%v'", answersFilename)
+		}
+	}
+
+	// finally, compile the TeX files into PDF, if requested. Note that the
+	// output file might have been re-numbered
+	if masterFile.PDF {
+		masterFile.Outfile = dst
+		if err := masterFile.CompilePDF(ctx); err != nil {
+			return err
+		}
+	}
+
+	// at this point, everything went fine
+	return nil
 }
 
 /* Local Variables: */
@@ -0,0 +1,301 @@
+// -*- coding: utf-8 -*-
+// mathtools_test.go
+//
+// Description: Tests of the generation of sheets from master files
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 16:52:44.901537261 (1792169564)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Master file used in the tests, which includes a partial, generates problems
+// and draws random values
+const testMaster = `{{.Include "partial.tex"}}
+{{range .Slice 5}}{{.Division (dict "nbdvdigits" 3 "nbdrdigits" 1 "nbqdigits" 2)}}
+{{end}}
+Random value: {{rand 1 1000}}
+`
+
+// Partial included in the master file used in the tests
+const testPartial = `Student: {{.GetName}} ({{choice "a" "b" "c" "d"}})
+{{.Clock (dict "type" 0 "granularity" "quarter")}}
+`
+
+// Arguments of every problem type used in the master file of the tests
+var testArgs = map[string]map[string]interface{}{
+	"Clock":    {"type": 0, "granularity": "quarter"},
+	"Division": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2},
+}
+
+// Number of records generated from the same master file in the benchmarks
+const testNbRecords = 50
+
+// functions
+// ----------------------------------------------------------------------------
+
+// write the master file used in the tests and its partial to the given
+// directory, and return the path to the master file
+func writeTestMaster(tb testing.TB, dir string) string {
+
+	tb.Helper()
+	if err := ioutil.WriteFile(filepath.Join(dir, "partial.tex"), []byte(testPartial), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	infile := filepath.Join(dir, "test.master")
+	if err := ioutil.WriteFile(infile, []byte(testMaster), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	return infile
+}
+
+// generate the sheet of the given student from the given master file with the
+// given seed into the given directory, and return its contents
+func renderTestMaster(tb testing.TB, infile, dir, name string, seed int64) string {
+
+	tb.Helper()
+	masterFile := NewMasterFile(infile, name, "")
+	masterFile.Seed = seed
+	masterFile.Overwrite = true
+	dst := filepath.Join(dir, name+".tex")
+	if err := masterFile.MasterToFileFromTemplate(context.Background(), dst); err != nil {
+		tb.Fatal(err)
+	}
+	contents, err := ioutil.ReadFile(dst)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return string(contents)
+}
+
+// sheets generated with cached templates are exactly the same than those
+// generated parsing master files every time, also after clearing the cache
+func TestTemplateCacheOutput(t *testing.T) {
+
+	dir := t.TempDir()
+	infile := writeTestMaster(t, dir)
+
+	ClearTemplateCache()
+	uncached := renderTestMaster(t, infile, dir, "student", 7)
+	cached := renderTestMaster(t, infile, dir, "student", 7)
+	ClearTemplateCache()
+	cleared := renderTestMaster(t, infile, dir, "student", 7)
+
+	if cached != uncached {
+		t.Errorf("the sheet generated with a cached template differs:\n%v\nfrom the one generated without the cache:\n%v", cached, uncached)
+	}
+	if cleared != uncached {
+		t.Errorf("the sheet generated after clearing the cache differs:\n%v\nfrom the one generated before:\n%v", cleared, uncached)
+	}
+}
+
+// distinct problems whose arguments are masked, e.g., clocks, are not taken
+// as repetitions when problems are not allowed to repeat in the same sheet
+func TestUniqueMaskedArgs(t *testing.T) {
+
+	dir := t.TempDir()
+	infile := filepath.Join(dir, "clocks.master")
+	master := `{{range .Slice 6}}{{.Clock (dict "type" 0 "granularity" "quarter")}}
+{{end}}`
+	if err := ioutil.WriteFile(infile, []byte(master), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	masterFile := NewMasterFile(infile, "student", "")
+	masterFile.Seed = 3
+	masterFile.Unique = true
+	masterFile.Overwrite = true
+	if err := masterFile.MasterToFileFromTemplate(context.Background(), filepath.Join(dir, "student.tex")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output.String(), "it was not possible to avoid repeating") {
+		t.Errorf("expected no repetitions of distinct clocks but got: %v", output.String())
+	}
+}
+
+// the solutions written in JSON format are those of the problems shown in the
+// answer key, in the same order, and the values drawn at random are the same
+// in the sheet and its answer key
+func TestSolutionsMatchAnswers(t *testing.T) {
+
+	dir := t.TempDir()
+	infile := writeTestMaster(t, dir)
+	masterFile := NewMasterFile(infile, "student", "")
+	masterFile.Seed = 11
+	masterFile.Solutions = true
+	masterFile.Answers = true
+	dst := filepath.Join(dir, "student.tex")
+	if err := masterFile.MasterToFileFromTemplate(context.Background(), dst); err != nil {
+		t.Fatal(err)
+	}
+	read := func(filename string) string {
+		contents, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(contents)
+	}
+	sheet := read(dst)
+	answers := read(filepath.Join(dir, "student.answers.tex"))
+	var solutions []ProblemJSON
+	if err := json.Unmarshal([]byte(read(filepath.Join(dir, "student.solutions.json"))), &solutions); err != nil {
+		t.Fatal(err)
+	}
+
+	// the partial with a clock is included before all divisions
+	if len(solutions) != 6 || solutions[0].Probtype != "Clock" {
+		t.Fatalf("expected a clock followed by 5 divisions but got %v", solutions)
+	}
+
+	// every solution is drawn in the answer key with its answers, and in the
+	// sheet without them, and they appear in the same order
+	offset := 0
+	for idx, solution := range solutions {
+		gen := lookupFactory(t, solution.Probtype)
+		if err := gen.Verify(testArgs[solution.Probtype]); err != nil {
+			t.Fatal(err)
+		}
+		withAnswers, err := gen.TikZ(solution, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		position := strings.Index(answers[offset:], withAnswers)
+		if position < 0 {
+			t.Errorf("the solution #%v (%v) is not drawn in the answer key after position %v", idx, solution, offset)
+			continue
+		}
+		offset += position + len(withAnswers)
+		withoutAnswers, err := gen.TikZ(solution, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(sheet, withoutAnswers) {
+			t.Errorf("the solution #%v (%v) is not drawn in the sheet", idx, solution)
+		}
+	}
+
+	// and the values drawn at random are the same in both files
+	draws := regexp.MustCompile(`Student: student \(.\)|Random value: \d+`)
+	if sheetDraws, answersDraws := draws.FindAllString(sheet, -1), draws.FindAllString(answers, -1); len(sheetDraws) != 2 || strings.Join(sheetDraws, "\n") != strings.Join(answersDraws, "\n") {
+		t.Errorf("the values drawn at random in the sheet %v differ from those in the answer key %v", sheetDraws, answersDraws)
+	}
+}
+
+// the extended layout of divisions shows, in the answer key, the products and
+// remainders of every step of the long division, with the digits brought down
+func TestLongDivisionSteps(t *testing.T) {
+
+	// 156 / 12: 15 - 12 = 3, 6 is brought down, and 36 - 36 = 0
+	instance := ProblemJSON{
+		Probtype: "Division",
+		Args:     []string{"156", "12", "?", "?"},
+		Solution: []string{"156", "12", "13", "0"},
+	}
+	expected := []longDivisionStep{
+		{last: 1, product: "12", remainder: "36"},
+		{last: 2, product: "36", remainder: "0"},
+	}
+	steps := longDivisionSteps(instance.Solution[0], 12)
+	if len(steps) != len(expected) {
+		t.Fatalf("expected the steps %v but got %v", expected, steps)
+	}
+	for idx, step := range steps {
+		if step != expected[idx] {
+			t.Errorf("expected the step #%v to be %v but got %v", idx, expected[idx], step)
+		}
+	}
+	text := []string{"15 / 12 = 1", "1 * 12 = 12", "15 - 12 = 3", "bring down 6: 36",
+		"36 / 12 = 3", "3 * 12 = 36", "36 - 36 = 0"}
+	if got := longDivisionStepsText(instance.Solution[0], 12); strings.Join(got, "\n") != strings.Join(text, "\n") {
+		t.Errorf("expected the steps %v but got %v", text, got)
+	}
+
+	// every box of the scaffold is filled in with its number in the answer
+	// key, and it is left empty otherwise
+	gen := lookupFactory(t, "Division")
+	if err := gen.Verify(map[string]interface{}{"nbdvdigits": 3, "nbdrdigits": 2, "nbqdigits": 2, "extended": true}); err != nil {
+		t.Fatal(err)
+	}
+	withAnswers, err := gen.TikZ(instance, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutAnswers, err := gen.TikZ(instance, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, step := range expected {
+		for label, number := range map[string]string{
+			fmt.Sprintf("product%v", idx):   step.product,
+			fmt.Sprintf("remainder%v", idx): step.remainder} {
+			box := regexp.MustCompile(`\\draw \(` + label + `\) node \[[^]]*\] \{ (.*) \};`)
+			if match := box.FindStringSubmatch(withAnswers); match == nil || match[1] != `\huge `+number {
+				t.Errorf("expected the box '%v' with %v in the answer key but got %v", label, number, match)
+			}
+			if match := box.FindStringSubmatch(withoutAnswers); match == nil || match[1] != "" {
+				t.Errorf("expected the box '%v' to be empty in the sheet but got %v", label, match)
+			}
+		}
+	}
+}
+
+// return a new generator of the given problem type, which is expected to be
+// registered
+func lookupFactory(tb testing.TB, probtype string) ProblemGenerator {
+
+	tb.Helper()
+	entry, ok := lookup(probtype)
+	if !ok {
+		tb.Fatalf("the problem type '%v' is not registered", probtype)
+	}
+	return entry.factory()
+}
+
+// generate many records from the same master file either with the cache of
+// templates or clearing it before every record
+func BenchmarkMasterToFileFromTemplate(b *testing.B) {
+
+	for _, cache := range []bool{true, false} {
+		b.Run(fmt.Sprintf("cache=%v", cache), func(b *testing.B) {
+			dir := b.TempDir()
+			infile := writeTestMaster(b, dir)
+			ClearTemplateCache()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for record := 0; record < testNbRecords; record++ {
+					if !cache {
+						ClearTemplateCache()
+					}
+					renderTestMaster(b, infile, dir, fmt.Sprintf("student-%v", record), int64(record+1))
+				}
+			}
+		})
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
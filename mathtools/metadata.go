@@ -0,0 +1,124 @@
+// -*- coding: utf-8 -*-
+// metadata.go
+//
+// Description: Rates the difficulty of problems and classifies them by the
+// skills they exercise
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 07:41:18.220871554 (1792136478)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import "math/rand"
+
+// constants
+// ----------------------------------------------------------------------------
+
+// The difficulty of problems is rated in the range [MINDIFFICULTY,
+// MAXDIFFICULTY]
+const (
+	MINDIFFICULTY int = 1
+	MAXDIFFICULTY int = 5
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// Problem types which can rate the problems they generate from their
+// parameters (e.g., the number of digits, whether carries are needed or
+// negative numbers are shown) set their difficulty, skill and tags. They are
+// annotated after the default metadata of their type has been set
+type annotator interface {
+	annotate(problem *ProblemJSON)
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// Every problem type exercises a skill given as a strand of the curriculum
+// followed by a more specific skill within it. This is the skill of all
+// problems of every type unless they set a more specific one
+var problemSkills = map[string]string{
+	"Angle":                "geometry.angles",
+	"BarChart":             "data.bar-charts",
+	"BasicOperation":       "arithmetic.operations",
+	"Clock":                "measurement.time",
+	"CountingObjects":      "number.counting",
+	"Division":             "arithmetic.long-division",
+	"ElapsedTime":          "measurement.elapsed-time",
+	"EquivalentFraction":   "fractions.equivalence",
+	"FactFamily":           "arithmetic.fact-families",
+	"FDPConversion":        "fractions.conversion",
+	"LinearEquation":       "algebra.equations",
+	"MagicSquare":          "arithmetic.addition",
+	"MathCrossword":        "arithmetic.mixed-operations",
+	"MixedDrill":           "arithmetic.mixed-operations",
+	"Money":                "measurement.money",
+	"MultiplicationTable":  "arithmetic.multiplication-facts",
+	"MysteryOperation":     "arithmetic.inverse-operations",
+	"NumberClassification": "number.properties",
+	"NumberComparison":     "number.comparison",
+	"NumberForm":           "number.place-value",
+	"NumberLine":           "number.number-line",
+	"NumberPyramid":        "arithmetic.addition",
+	"Percentage":           "fractions.percentages",
+	"PerimeterArea":        "geometry.perimeter-area",
+	"Pictogram":            "data.pictograms",
+	"PlaceValue":           "number.place-value",
+	"PrimeFactorization":   "number.primes",
+	"Ratio":                "fractions.ratios",
+	"Rounding":             "number.rounding",
+	"Sequence":             "algebra.patterns",
+	"ShadedFraction":       "fractions.representation",
+	"UnitConversion":       "measurement.units",
+	"WordProblem":          "arithmetic.word-problems",
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given score as a difficulty, i.e., in the range [MINDIFFICULTY,
+// MAXDIFFICULTY]
+func difficulty(score int) int {
+
+	if score < MINDIFFICULTY {
+		return MINDIFFICULTY
+	}
+	if score > MAXDIFFICULTY {
+		return MAXDIFFICULTY
+	}
+	return score
+}
+
+// return a new problem generated by the given instance with the given source of
+// random numbers along with its metadata. Problems get the default skill of
+// their type with the minimum difficulty unless the instance can annotate them
+func generateAnnotatedJSONProblem(instance generator, rnd *rand.Rand) (ProblemJSON, error) {
+
+	problem, err := instance.generateJSONProblem(rnd)
+	if err != nil {
+		return problem, err
+	}
+	problem.Difficulty = MINDIFFICULTY
+	problem.Skill = problemSkills[problem.Probtype]
+	if annotator, ok := instance.(annotator); ok {
+		annotator.annotate(&problem)
+	}
+	return problem, nil
+}
+
+// return a function that generates new problems with the given instance along
+// with their metadata, so that their difficulty can be checked before drawing
+// them
+func annotated(instance generator) func(rnd *rand.Rand) (ProblemJSON, error) {
+	return func(rnd *rand.Rand) (ProblemJSON, error) {
+		return generateAnnotatedJSONProblem(instance, rnd)
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,122 @@
+// -*- coding: utf-8 -*-
+// mixeddrill.go
+//
+// Description: Provides services for automatically creating drill sheets
+// where basic operations of different kinds are randomly interleaved
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 03:21:07.862214950 (1792120867)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// By default, mixed drills are laid out in the following number of columns,
+// which depends on the layout of the basic operations
+const (
+	DEFAULTMIXEDDRILLCOLS           int = 4
+	DEFAULTHORIZONTALMIXEDDRILLCOLS int = 2
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A mixed drill consists of a number of basic operations, each one defined
+// with its own operator and number of digits. Every problem of the drill is a
+// basic operation randomly chosen among them, and count problems are laid out
+// in a grid with the given number of columns
+type mixedDrill struct {
+	operations []basicOperation
+	count      int
+	cols       int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- mixedDrill
+
+// return the instance of a basic operation randomly chosen among all the
+// operations of this drill that can be marshalled in JSON format. The receiver
+// is assumed to have been fully verified so that it should be consistent.
+func (md mixedDrill) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	if len(md.operations) == 0 {
+		return ProblemJSON{}, errors.New("It is not possible to generate mixed drills without operations")
+	}
+	return md.operations[rnd.Intn(len(md.operations))].generateJSONProblem(rnd)
+}
+
+// return the basic operation of this drill that generated the given instance.
+// It is the first one with the same operator, number of operands and number of
+// digits in the result or, if there is none, the first one with the same
+// operator
+func (md mixedDrill) operation(instance ProblemJSON) basicOperation {
+
+	result := strings.TrimPrefix(strings.Replace(instance.Solution[len(instance.Solution)-1], ".", "", 1), "-")
+	candidate := -1
+	for idx, bo := range md.operations {
+		if bo.operator != instance.Args[0] {
+			continue
+		}
+		if bo.nboperands == len(instance.Args)-2 &&
+			(bo.nbdigitsrslt == len(result) || bo.nbdecimals > 0) {
+			return bo
+		}
+		if candidate < 0 {
+			candidate = idx
+		}
+	}
+	if candidate < 0 {
+		candidate = 0
+	}
+	return md.operations[candidate]
+}
+
+// Return TikZ code that represents the next problem of this mixed drill
+func (md mixedDrill) execute() (string, error) {
+
+	// the next problem is generated (or replayed) with this drill, and then it
+	// is drawn by the basic operation that generated it, which replays it
+	instance, err := md.next(annotated(md))
+	if err != nil {
+		return "", err
+	}
+	index := 0
+	bo := md.operation(instance)
+	bo.recorder = recorder{
+		solutions: &[]ProblemJSON{instance},
+		replay:    &index,
+		blank:     !md.showAnswers(),
+	}
+	return bo.execute()
+}
+
+// return the grid used for laying out all the problems of this mixed drill.
+// Note that the last row might be incomplete
+func (md mixedDrill) grid() gridLayout {
+	return gridLayout{
+		rows:   helpers.CeilDiv(md.count, md.cols),
+		cols:   md.cols,
+		vspace: DEFAULTGRIDVSPACE,
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
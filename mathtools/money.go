@@ -0,0 +1,463 @@
+// -*- coding: utf-8 -*-
+// money.go
+//
+// Description: Provides services for automatically creating problems with
+// money, where either the total price of a number of items or the change
+// from a given amount has to be computed
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:15:13.359613180 (1792113313)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of money problems: "total" or "change". In the
+// first case, the prices of a number of items are given and the student has to
+// compute the total; in the latter, the student has to compute the change
+// received when paying them with the given amount
+const (
+	MONEYTOTAL int = iota
+	MONEYCHANGE
+)
+
+// By default, amounts are given in euros and two items are bought, but never
+// more than the following maximum number
+const DEFAULTCURRENCY string = "EUR"
+const DEFAULTMONEYITEMS int = 2
+const MAXMONEYITEMS int = 5
+
+// the TikZ code for generating money problems is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexMoneyCode = `\begin{minipage}{\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the money problem
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZMoneyCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Amounts ---------------------------------------------------------
+
+      % all amounts are shown from left to right, either as text or with coins
+      % and bills, and they are followed by the box of the answer
+{{.GetItems}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A currency is written with its symbol either before or after amounts, and it
+// has a number of bills and coins, whose values are given in cents in
+// decreasing order. The name of the cents is used for writing them on coins
+type currency struct {
+	symbol string
+	prefix bool
+	cents  string
+	bills  []int
+	coins  []int
+}
+
+// A money problem consists of a type (either MONEYTOTAL or MONEYCHANGE), the
+// currency used, and the number of items to buy, whose prices are in the range
+// [geq, leq] of whole units, and have cents only if decimals is true.
+// Optionally, the amounts given can be drawn with coins and bills
+type money struct {
+	mtype    int
+	currency string
+	geq, leq int
+	decimals bool
+	nbitems  int
+	coins    bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw money
+// problems
+type moneyTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// all amounts, either as text or with coins and bills, along with the
+	// symbols between them and the answer
+	items []fmt.Stringer
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The currencies acknowledged are indexed by their ISO code
+var currencies = map[string]currency{
+	"EUR": {
+		symbol: `\texteuro{}`, prefix: false, cents: "c",
+		bills: []int{50000, 20000, 10000, 5000, 2000, 1000, 500},
+		coins: []int{200, 100, 50, 20, 10, 5, 2, 1},
+	},
+	"USD": {
+		symbol: `\$`, prefix: true, cents: `\textcent{}`,
+		bills: []int{10000, 5000, 2000, 1000, 500, 100},
+		coins: []int{25, 10, 5, 1},
+	},
+	"GBP": {
+		symbol: `\pounds{}`, prefix: true, cents: "p",
+		bills: []int{5000, 2000, 1000, 500},
+		coins: []int{200, 100, 50, 20, 10, 5, 2, 1},
+	},
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given amount in cents as a number with two decimals, if requested,
+// or as a whole number otherwise
+func formatAmount(cents int, decimals bool) string {
+
+	if decimals {
+		return fmt.Sprintf("%v.%02d", cents/100, cents%100)
+	}
+	return strconv.Itoa(cents / 100)
+}
+
+// return the amount in cents of the given number with two decimals at most. If
+// it is not correct, an error is returned
+func parseAmount(amount string) (int, error) {
+
+	units, cents := amount, "00"
+	if idx := strings.Index(amount, "."); idx >= 0 {
+		units, cents = amount[:idx], (amount[idx+1:] + "00")[:2]
+	}
+	value, err := strconv.Atoi(units + cents)
+	if err != nil {
+		return 0, fmt.Errorf("the amount '%v' is incorrect", amount)
+	}
+	return value, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- currency
+
+// return the given amount written with the symbol of this currency in LaTeX
+func (c currency) format(amount string) string {
+
+	if c.prefix {
+		return c.symbol + amount
+	}
+	return amount + `\,` + c.symbol
+}
+
+// return the value of a coin or bill of this currency given in cents as it is
+// written on it
+func (c currency) face(cents int) string {
+
+	if cents < 100 {
+		return strconv.Itoa(cents) + c.cents
+	}
+	return c.format(strconv.Itoa(cents / 100))
+}
+
+// return the values in cents of the coins and bills of this currency that sum
+// up the given amount in cents with the minimum number of them
+func (c currency) change(cents int) []int {
+
+	var result []int
+	for _, value := range append(append([]int{}, c.bills...), c.coins...) {
+		for ; cents >= value; cents -= value {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// return whether the given value in cents is a bill of this currency or not
+func (c currency) isBill(cents int) bool {
+
+	for _, bill := range c.bills {
+		if bill == cents {
+			return true
+		}
+	}
+	return false
+}
+
+// -- moneyTikZ
+
+// Generates the TikZ code necessary for drawing all the amounts
+func (tikz moneyTikZ) GetItems() string {
+
+	// Use a btyes buffer to append the strings of each item
+	var output bytes.Buffer
+
+	for _, item := range tikz.items {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// items
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz moneyTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("moneyTikZ").Parse(tikZMoneyCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- money
+
+// return the instance of a specific money problem that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with the prices of all items followed by their total if
+// the type is MONEYTOTAL; otherwise, the amount paid is given first, then the
+// prices of all items, and finally the change. In both cases, the last item is
+// shown as "?" in the arguments as it has to be guessed by the student
+func (m money) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	cur, ok := currencies[m.currency]
+	if !ok {
+		return ProblemJSON{}, fmt.Errorf("Unknown currency '%v'", m.currency)
+	}
+	if m.geq > m.leq || m.geq < 0 {
+		return ProblemJSON{}, fmt.Errorf("The range of prices [%v, %v] is incorrect", m.geq, m.leq)
+	}
+
+	// randomly determine the prices of all items in cents. Prices are never
+	// null
+	var prices []int
+	total := 0
+	for len(prices) < m.nbitems {
+		price := 100 * (m.geq + rnd.Intn(1+m.leq-m.geq))
+		if m.decimals {
+			price = 100*m.geq + rnd.Intn(1+100*(m.leq-m.geq))
+		}
+		if price > 0 {
+			prices = append(prices, price)
+			total += price
+		}
+	}
+
+	// the solution consists of the prices of all items and either their total
+	// or the change
+	var solution []string
+	for _, price := range prices {
+		solution = append(solution, formatAmount(price, m.decimals))
+	}
+	if m.mtype == MONEYTOTAL {
+		solution = append(solution, formatAmount(total, m.decimals))
+	} else {
+
+		// the amount paid is the smallest bill greater than the total or, if
+		// there is none, the total rounded up to the next multiple of the
+		// largest bill
+		largest := cur.bills[0]
+		paid := largest * (1 + total/largest)
+		for _, bill := range cur.bills {
+			if bill > total {
+				paid = bill
+			}
+		}
+		solution = append([]string{formatAmount(paid, m.decimals)}, solution...)
+		solution = append(solution, formatAmount(paid-total, m.decimals))
+	}
+
+	// the arguments are the same but the last one, which has to be guessed
+	args := make([]string, len(solution))
+	copy(args, solution)
+	args[len(args)-1] = "?"
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "Money",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this money problem using TikZ
+// components
+func (m money) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the prices. For this, the service that
+	//              generates problems is the one that can marshal them into
+	//              JSON format. A question mark is an amount that has to be
+	//              guessed by the student
+	instance, err := m.next(annotated(m))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid money problem: %v", err)
+	}
+	cur := currencies[m.currency]
+
+	// all items are vertically centered at the same height, which is enough to
+	// host bills
+	height := helpers.Ftoa(0.25 + components.MONEYBILLHEIGHT/2.0)
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- items: they are placed from left to right. As coins and bills are
+	//           measured in centimeters and texts in widths of digits, the
+	//           horizontal position is given with both magnitudes
+	var items []fmt.Stringer
+	cm, zw := 0.25, 0.5
+	position := func(width, widthzw float64) string {
+		return fmt.Sprintf(`$(bottom) + (%vcm + %v\zerowidth, %vcm)$`,
+			helpers.Ftoa(cm+width/2.0), helpers.Ftoa(zw+widthzw/2.0), height)
+	}
+	text := func(label, options, content string, width float64) {
+		items = append(items, components.NewCoordinatedText(
+			components.NewCoordinate(components.Formula(position(0, width)), label),
+			options, content))
+		zw += width + 0.5
+	}
+
+	// operators are shown between amounts: in change problems, the first
+	// amount is the amount paid and all prices are subtracted from it
+	operator := `\huge $+$`
+	if m.mtype == MONEYCHANGE {
+		operator = `\huge $-$`
+	}
+	last := len(instance.Args) - 1
+	for idx, arg := range instance.Args[:last] {
+
+		if idx > 0 {
+			text(fmt.Sprintf("op%v", idx), "", operator, 1.0)
+		}
+
+		// the amounts given are shown either with coins and bills, or as text
+		if m.coins {
+			cents, err := parseAmount(arg)
+			if err != nil {
+				return "", err
+			}
+			for jdx, value := range cur.change(cents) {
+				label := fmt.Sprintf("money%v-%v", idx, jdx)
+				piece := components.NewMoney(label, cur.face(value), cur.isBill(value))
+				if piece.IsBill() {
+					piece.SetOptions("fill=green!15")
+				} else {
+					piece.SetOptions("fill=yellow!30")
+				}
+				items = append(items,
+					components.NewCoordinate(components.Formula(position(piece.GetWidth(), 0)), label),
+					piece)
+				cm += piece.GetWidth() + 0.1
+			}
+			zw += 0.5
+		} else {
+			text(fmt.Sprintf("amount%v", idx), "", `\huge `+cur.format(arg),
+				2.0+float64(len(arg)))
+		}
+	}
+
+	// -- answer: the box is wide enough to write the solution with its
+	//            currency symbol
+	text("equal", "", `\huge $=$`, 1.0)
+	width := 3.0 + float64(len(instance.Solution[last]))
+	text("answer",
+		fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+			helpers.Ftoa(width)),
+		m.answer(cur.format(instance.Solution[last])), width)
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%vcm + %v\zerowidth, %vcm)$`,
+			helpers.Ftoa(cm), helpers.Ftoa(zw), helpers.Ftoa(0.5+components.MONEYBILLHEIGHT))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the money
+	// problem
+	mPicture := moneyTikZ{
+		Bottom: bottom,
+		items:  items,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return mPicture.execute()
+}
+
+// Return TikZ code that represents a money problem
+func (m money) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this money problem
+	tpl, err := template.New("money").Parse(latexMoneyCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, m); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,203 @@
+// -*- coding: utf-8 -*-
+// moodle.go
+//
+// Description: Provides services for exporting generated problems to the
+// question bank formats of Moodle, GIFT and Moodle XML
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:55:12.611820540 (1792112112)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Problems can be exported to the following Moodle formats
+const (
+	MOODLEGIFT string = "gift"
+	MOODLEXML  string = "xml"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// The following types are used for marshalling questions in Moodle XML
+// format. Only cloze questions (i.e., with embedded answers) are generated
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+type moodleText struct {
+	Text string `xml:"text"`
+}
+
+type moodleQuestion struct {
+	Type         string     `xml:"type,attr"`
+	Name         moodleText `xml:"name"`
+	QuestionText struct {
+		Format string `xml:"format,attr"`
+		moodleText
+	} `xml:"questiontext"`
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the name of the given problem in the question bank
+func moodleName(problem ProblemJSON) string {
+	return fmt.Sprintf("%v #%v", problem.Probtype, problem.Id)
+}
+
+// return true if and only if the given value is a number, so that it can be
+// given as a numerical answer
+func isNumerical(value string) bool {
+
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// verify that the solution of the given problem has an item for every
+// argument, so that the masked arguments can be mapped to their answers
+func verifyMoodleProblem(problem ProblemJSON) error {
+
+	if len(problem.Args) != len(problem.Solution) {
+		return fmt.Errorf("the problem '%v' can not be exported to Moodle as its arguments and solution have a different number of items", moodleName(problem))
+	}
+	return nil
+}
+
+// return the given text escaped so that it can be written in GIFT format
+func escapeGIFT(text string) string {
+
+	return strings.NewReplacer(
+		`\`, `\\`, `~`, `\~`, `=`, `\=`, `#`, `\#`,
+		`{`, `\{`, `}`, `\}`, `:`, `\:`).Replace(text)
+}
+
+// return the given answer escaped so that it can be embedded in a cloze
+// question
+func escapeCloze(text string) string {
+
+	return strings.NewReplacer(
+		`\`, `\\`, `}`, `\}`, `#`, `\#`, `~`, `\~`, `/`, `\/`, `"`, `\"`).Replace(text)
+}
+
+// Return the given problems in GIFT format. As GIFT questions can only have one
+// blank, a question is generated for every masked argument, where the other
+// masked arguments are shown as a line. Numerical values are given as
+// numerical answers and any other value as a short answer
+func ExportGIFT(problems []ProblemJSON) ([]byte, error) {
+
+	var output bytes.Buffer
+	for _, problem := range problems {
+
+		if err := verifyMoodleProblem(problem); err != nil {
+			return nil, err
+		}
+
+		// create a question for every masked argument
+		blank := 0
+		for idx, arg := range problem.Args {
+			if arg != "?" {
+				continue
+			}
+
+			var items []string
+			for jdx, item := range problem.Args {
+				switch {
+				case jdx == idx && isNumerical(problem.Solution[jdx]):
+					items = append(items, fmt.Sprintf("{#%v}", problem.Solution[jdx]))
+				case jdx == idx:
+					items = append(items, fmt.Sprintf("{=%v}", escapeGIFT(problem.Solution[jdx])))
+				case item == "?":
+					items = append(items, "___")
+				default:
+					items = append(items, escapeGIFT(item))
+				}
+			}
+			fmt.Fprintf(&output, "::%v (%v):: %v\n\n",
+				escapeGIFT(moodleName(problem)), blank, strings.Join(items, " "))
+			blank++
+		}
+	}
+	return output.Bytes(), nil
+}
+
+// Return the given problems in Moodle XML format. Every problem is exported as
+// a cloze question where masked arguments are embedded answers. Numerical
+// values are given as numerical answers and any other value as a short answer
+func ExportMoodleXML(problems []ProblemJSON) ([]byte, error) {
+
+	quiz := moodleQuiz{Questions: []moodleQuestion{}}
+	for _, problem := range problems {
+
+		if err := verifyMoodleProblem(problem); err != nil {
+			return nil, err
+		}
+
+		var items []string
+		for idx, arg := range problem.Args {
+			switch {
+			case arg != "?":
+				items = append(items, html.EscapeString(arg))
+			case isNumerical(problem.Solution[idx]):
+				items = append(items, fmt.Sprintf("{1:NUMERICAL:=%v:0}", problem.Solution[idx]))
+			default:
+				items = append(items, fmt.Sprintf("{1:SHORTANSWER:=%v}", escapeCloze(problem.Solution[idx])))
+			}
+		}
+
+		question := moodleQuestion{Type: "cloze", Name: moodleText{moodleName(problem)}}
+		question.QuestionText.Format = "html"
+		question.QuestionText.Text = "<p>" + strings.Join(items, " ") + "</p>"
+		quiz.Questions = append(quiz.Questions, question)
+	}
+
+	data, err := xml.MarshalIndent(quiz, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// given an array of master problems (of any type) return a slice of bytes with
+// the requested problems exported to the given Moodle format, either MOODLEGIFT
+// or MOODLEXML. If a problem could not be generated or exported, the contents
+// of the returned data are undefined and an error is raised. The generation
+// stops as soon as the given context is done
+func GenerateMoodle(ctx context.Context, problems []MasterProblem, format string) (data []byte, err error) {
+
+	// generate all the problems requested
+	jsonprobs, err := generateJSONProblems(ctx, problems)
+	if err != nil {
+		return data, err
+	}
+
+	// and export them in the given format
+	switch format {
+	case MOODLEGIFT:
+		return ExportGIFT(jsonprobs)
+	case MOODLEXML:
+		return ExportMoodleXML(jsonprobs)
+	default:
+		return data, fmt.Errorf("Unknown Moodle format '%v'", format)
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -17,7 +17,6 @@ import (
 	"log"
 	"math/rand"
 	"text/template"
-	"time"
 
 	"github.com/clinaresl/mathprob/helpers"
 	"github.com/clinaresl/mathprob/mathtools/components"
@@ -35,6 +34,24 @@ const (
 	MTOPERAND
 )
 
+// Multiplication tables can be shown either in one block that takes the whole
+// width of the line ("block"), in blocks that take half the width so that two
+// tables are shown side by side ("twocolumn"), or as a grid of facts with the
+// factors on both axes and empty cells for writing their products ("grid")
+const (
+	MTBLOCK     string = "block"
+	MTTWOCOLUMN string = "twocolumn"
+	MTGRID      string = "grid"
+)
+
+// Cells of grids of facts are as high as the following value, and as wide as
+// the following margin plus the width of every digit, all given in centimeters
+const (
+	MTGRIDCELLHEIGHT float64 = 0.8
+	MTGRIDCELLMARGIN float64 = 0.3
+	MTGRIDDIGITWIDTH float64 = 0.3
+)
+
 // the TikZ code for generating arbitrary multiplication tables is shown next.
 // Note that it makes use of LaTeX/TikZ components
 const latexMultiplicationTableCode = `\begin{minipage}{\linewidth}
@@ -48,6 +65,19 @@ const latexMultiplicationTableCode = `\begin{minipage}{\linewidth}
 \end{minipage}
 `
 
+// multiplication tables shown side by side take half the width of the line
+// and hence they are scaled down
+const latexTwoColumnMultiplicationTableCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}[scale=0.75, every node/.style={transform shape}]
+
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
 // The LaTeX/TikZ code used for darwing a single line of a multiplication table
 // is shown next
 const tikZMultiplicationTableLineCode = `
@@ -75,6 +105,25 @@ const tikZMultiplicationTableCode = `% --- Bottom ------------------------------
       {{.GetLines}}
 `
 
+// The LaTeX/TikZ code used for drawing grids of facts
+const tikZMultiplicationGridCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left and upper-left corners of the bounding box
+      {{.Bottom}}
+      {{.Origin}}
+
+      % --- Grid ------------------------------------------------------------
+
+      % the factors are shown in the first row and column, and every product
+      % is written in a framed cell
+{{.Grid}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
 // types
 // ----------------------------------------------------------------------------
 
@@ -92,12 +141,26 @@ const tikZMultiplicationTableCode = `% --- Bottom ------------------------------
 //    0: both operands are given and the student has to guess the result
 //    1: only one operand is given, and the student has to guess the value of
 //    the other operand so that the equality holds
+//
+// The digits of the factor can be constrained (see digitConstraint) and, in
+// this case, the rows multiplied by numbers with excluded digits are not shown.
+//
+// Multiplication tables are shown in blocks, either one per line or two side by
+// side, or as a grid of facts. In the latter case, the numbers in the range
+// [geq, leq] are shown in random order on both axes and the student has to
+// write all their products, so that there is no factor and neither nbdigits
+// nor inv nor sorted are acknowledged
 type multiplicationTable struct {
-	mttype   int
-	nbdigits int
-	geq, leq int
-	inv      bool
-	sorted   bool
+	mttype     int
+	nbdigits   int
+	geq, leq   int
+	inv        bool
+	sorted     bool
+	layout     string
+	constraint digitConstraint
+
+	// generated problems are recorded when solutions are requested
+	recorder
 }
 
 // the following struct stores all the information necessary to draw
@@ -128,9 +191,47 @@ type multiplicationTableTikZ struct {
 	lines []multiplicationTableLineTikZ
 }
 
+// A grid of facts is drawn with a grid along with its bounding box
+type multiplicationGridTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0,0),
+	// and the origin of the grid is its upper-left corner
+	Bottom, Origin components.Coordinate
+
+	// the factors and products are all shown in a grid
+	Grid components.Grid
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
 // methods
 // ----------------------------------------------------------------------------
 
+// -- multiplicationGridTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz multiplicationGridTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("multiplicationGridTikZ").Parse(tikZMultiplicationGridCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
 // -- multiplicationTableTikZ
 
 // Return the LaTeX/TikZ commands that show up the entire picture stored in the
@@ -150,23 +251,23 @@ func (tikz multiplicationTableTikZ) GetLines() string {
 	return output.String()
 }
 
-func (tikz multiplicationTableTikZ) execute() string {
+func (tikz multiplicationTableTikZ) execute() (string, error) {
 
 	// create a template with the TikZ code for showing this picture
 	tpl, err := template.New("multiplicationTableTikZ").Parse(tikZMultiplicationTableCode)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the execution of
 	// the template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, tikz); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 // -- multiplicationTableLineTikZ
@@ -203,25 +304,32 @@ func (tikz multiplicationTableLineTikZ) String() string {
 //    2. Next, all items of each row are given in sorted order, e.g., "5", "1",
 //    "5" which stands for "5x1=5". If one item has to be guessed it is shown as
 //    a question mark "?"
-func (mt multiplicationTable) generateJSONProblem() (problemJSON, error) {
+//
+// Grids of facts are given differently (see generateJSONGrid)
+func (mt multiplicationTable) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
 
-	rand.Seed(time.Now().UTC().UnixNano())
+	if mt.layout == MTGRID {
+		return mt.generateJSONGrid(rnd)
+	}
 
 	// first, determine the factor to use in all rows of the multiplication
 	// table
-	factor := helpers.RandN(mt.nbdigits)
+	var factor int
+	if mt.constraint.constrains(mt.nbdigits) {
+		factor = mt.constraint.random(rnd, mt.nbdigits)
+	} else {
+		factor = helpers.RandN(rnd, mt.nbdigits)
+	}
 
 	// now, make room to store the full solution of the multiplication table. In
-	// total (1+leq-geq) rows have to be generated, each with three digits and
-	// write down the number used in the multiplication table
-	solution := make([]string, 1+(1+mt.leq-mt.geq)*3)
+	// total one row per number in the table has to be generated, each with
+	// three digits and write down the number used in the multiplication table
+	rows := mt.rows()
+	solution := make([]string, 1+len(rows)*3)
 	solution[0] = fmt.Sprintf("%v", factor)
 
 	// fill in the table
-	for i := mt.geq; i <= mt.leq; i++ {
-
-		// compute the relative position of this number
-		idx := i - mt.geq
+	for idx, i := range rows {
 
 		// store the values in the solution with the usual order
 		solution[1+idx*3] = fmt.Sprintf("%v", factor)
@@ -233,7 +341,7 @@ func (mt multiplicationTable) generateJSONProblem() (problemJSON, error) {
 
 			// if a number randomly generated in the interval [0, 100) falls in
 			// the first half, then reverse the operands
-			if rand.Int()%100 < 50 {
+			if rnd.Int()%100 < 50 {
 				solution[1+idx*3], solution[2+idx*3] = solution[2+idx*3], solution[1+idx*3]
 			}
 		}
@@ -247,13 +355,13 @@ func (mt multiplicationTable) generateJSONProblem() (problemJSON, error) {
 	if !mt.sorted {
 
 		// For this, shuffle a slice of ints with the indexes of each row
-		identity := make([]int, 1+mt.leq-mt.geq)
-		for i := 0; i <= mt.leq-mt.geq; i++ {
+		identity := make([]int, len(rows))
+		for i := 0; i < len(rows); i++ {
 			identity[i] = i
 		}
 
 		// and now shuffle them
-		rand.Shuffle(len(identity),
+		rnd.Shuffle(len(identity),
 			func(i, j int) {
 				identity[i], identity[j] = identity[j], identity[i]
 			})
@@ -263,7 +371,7 @@ func (mt multiplicationTable) generateJSONProblem() (problemJSON, error) {
 		// copy is necessary
 		isolution := make([]string, len(solution))
 		copy(isolution, solution)
-		for i := 0; i <= mt.leq-mt.geq; i++ {
+		for i := 0; i < len(rows); i++ {
 			solution[1+i*3], solution[2+i*3], solution[3+i*3] =
 				isolution[1+identity[i]*3], isolution[2+identity[i]*3], isolution[3+identity[i]*3]
 		}
@@ -273,9 +381,9 @@ func (mt multiplicationTable) generateJSONProblem() (problemJSON, error) {
 	// turn to create the specific instance determining what numbers are hidden.
 	// Note that the arguments preserve the first value, the factor used in the
 	// multiplication table
-	args := make([]string, 1+(1+mt.leq-mt.geq)*3)
+	args := make([]string, 1+len(rows)*3)
 	args[0] = solution[0]
-	for i := 0; i < 1+mt.leq-mt.geq; i++ {
+	for i := 0; i < len(rows); i++ {
 
 		// in case this is an ordinary multiplication table, just create the
 		// instance as usual
@@ -291,7 +399,7 @@ func (mt multiplicationTable) generateJSONProblem() (problemJSON, error) {
 
 			// if a number randomly generated in the interval [0, 100) falls in
 			// the first half then mask the first operand instead
-			if rand.Int()%100 < 50 {
+			if rnd.Int()%100 < 50 {
 
 				args[2+i*3], args[3+i*3] = solution[2+i*3], solution[3+i*3]
 				args[1+i*3] = "?"
@@ -300,24 +408,181 @@ func (mt multiplicationTable) generateJSONProblem() (problemJSON, error) {
 	}
 
 	// Now, generate the multiplication table
-	return problemJSON{
+	return ProblemJSON{
 		Probtype: "MultiplicationTable",
 		Args:     args,
 		Solution: solution,
 	}, nil
 }
 
+// return the instance of a grid of facts that can be marshalled in JSON format.
+// The receiver is assumed to have been fully verified so that it should be
+// consistent.
+//
+// The result is given as an array of numbers:
+//    1. The first string is the number n of rows and columns of the grid
+//    2. Next, the n factors of the rows and the n factors of the columns are
+//    given from top to bottom and from left to right respectively
+//    3. Finally, the n*n products are given row by row. All of them have to be
+//    guessed and thus they are shown as question marks "?"
+func (mt multiplicationTable) generateJSONGrid(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// the factors of both the rows and the columns are shuffled separately
+	rows := mt.rows()
+	cols := append([]int{}, rows...)
+	rnd.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+	rnd.Shuffle(len(cols), func(i, j int) { cols[i], cols[j] = cols[j], cols[i] })
+
+	args := []string{fmt.Sprintf("%v", len(rows))}
+	for _, factor := range append(append([]int{}, rows...), cols...) {
+		args = append(args, fmt.Sprintf("%v", factor))
+	}
+	solution := append([]string{}, args...)
+	for _, row := range rows {
+		for _, col := range cols {
+			args = append(args, "?")
+			solution = append(solution, fmt.Sprintf("%v", row*col))
+		}
+	}
+
+	return ProblemJSON{
+		Probtype: "MultiplicationTable",
+		Args:     args,
+		Solution: solution,
+	}, nil
+}
+
+// return the numbers multiplied by the factor in every row of this
+// multiplication table, i.e., those in the range [geq, leq] with no excluded
+// digits
+func (mt multiplicationTable) rows() (rows []int) {
+
+	for i := mt.geq; i <= mt.leq; i++ {
+		if (digitConstraint{excluded: mt.constraint.excluded}).admits(i) {
+			rows = append(rows, i)
+		}
+	}
+	return
+}
+
+// set the difficulty and tags of the given problem generated by this
+// multiplication table. Tables are harder with larger factors, when they are
+// not sorted and when operands have to be found instead of results
+func (mt multiplicationTable) annotate(problem *ProblemJSON) {
+
+	if mt.layout == MTGRID {
+		problem.Tags = []string{"multiplication", "facts-grid"}
+		problem.Difficulty = difficulty(1 + mt.leq/10)
+		return
+	}
+
+	score := mt.nbdigits
+	problem.Tags = []string{"multiplication", fmt.Sprintf("table-of-%v", problem.Solution[0])}
+	if !mt.sorted {
+		score++
+		problem.Tags = append(problem.Tags, "unsorted")
+	}
+	if mt.mttype == MTOPERAND {
+		score++
+		problem.Tags = append(problem.Tags, "missing-operand")
+	}
+	problem.Difficulty = difficulty(score)
+}
+
+// return a valid LaTeX/TikZ representation of this multiplication table shown
+// as a grid of facts using TikZ components
+func (mt multiplicationTable) gridTikZPicture() (string, error) {
+
+	// -- grid: randomly determine the factors of the grid. For this, the
+	//          service that generates problems is the one that can marshal
+	//          them into JSON format
+	instance, err := mt.next(annotated(mt))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid multiplication table: %v", err)
+	}
+	n, _ := helpers.Atoi(instance.Args[0])
+
+	// all cells are wide enough to write the largest product, and never
+	// narrower than two digits
+	nbdigits := 2
+	for _, cell := range instance.Solution[1:] {
+		nbdigits = int(helpers.Max(float64(nbdigits), float64(len(cell))))
+	}
+	width := MTGRIDCELLMARGIN + MTGRIDDIGITWIDTH*float64(nbdigits)
+
+	// compute the text of every cell and whether it is framed or not. The
+	// first row shows the multiplication symbol followed by the factors of the
+	// columns, and every other row starts with the factor of the row
+	texts := [][]string{{`\Large $\times$`}}
+	framed := [][]bool{{false}}
+	for col := 0; col < n; col++ {
+		texts[0] = append(texts[0], `\Large \bf `+instance.Args[1+n+col])
+		framed[0] = append(framed[0], false)
+	}
+	for row := 0; row < n; row++ {
+		texts = append(texts, []string{`\Large \bf ` + instance.Args[1+row]})
+		framed = append(framed, []bool{false})
+		for col := 0; col < n; col++ {
+			text := ""
+			if mt.showAnswers() {
+				text = `\Large ` + instance.Solution[1+2*n+row*n+col]
+			}
+			texts[1+row] = append(texts[1+row], text)
+			framed[1+row] = append(framed[1+row], true)
+		}
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box and the origin of
+	// the grid is its upper-left corner
+	grid := components.NewGrid("origin", width, MTGRIDCELLHEIGHT, texts, framed)
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	origin := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: grid.GetHeight(),
+	}, "origin")
+
+	// -- bounding box
+	right := components.NewCoordinate(components.Point{
+		X: grid.GetWidth(),
+		Y: grid.GetHeight(),
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the grid
+	// of facts
+	mtPicture := multiplicationGridTikZ{
+		Bottom: bottom,
+		Origin: origin,
+		Grid:   grid,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return mtPicture.execute()
+}
+
 // return a valid LaTeX/TikZ representation of this multiplication table using
 // TikZ components
-func (mt multiplicationTable) GetTikZPicture() string {
+func (mt multiplicationTable) GetTikZPicture() (string, error) {
+
+	// grids of facts are drawn separately
+	if mt.layout == MTGRID {
+		return mt.gridTikZPicture()
+	}
 
 	// -- operands: randomly determine the values of the operands and answers.
 	// For this, the service that generates problems is the one that can marshal
 	// them into JSON format. The operands and the result are given in Args,
 	// where a question mark is a number that has to be guessed by the student
-	instance, err := mt.generateJSONProblem()
+	instance, err := mt.next(annotated(mt))
 	if err != nil {
-		log.Fatalf(" Fatal error while generating a valid multiplication table: %v", err)
+		return "", fmt.Errorf("error while generating a valid multiplication table: %v", err)
 	}
 
 	// compute the number of digits required to draw all operands in the first
@@ -353,7 +618,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 
 		// drawing the (idx-1) line in the slice of arguments which corresponds
 		// with the i-th line in the multiplication table. i is counted from 1!
-		i := 2 + mt.leq - mt.geq - idx/3
+		i := 1 + len(instance.Args)/3 - idx/3
 
 		// create the different items of the i-th line (base 1)
 
@@ -366,6 +631,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 		if instance.Args[idx-2] == "?" {
 			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
 				2+nbdigits[0])
+			text = mt.answer(instance.Solution[idx-2])
 		} else {
 			text = fmt.Sprintf(`\huge %v`, instance.Args[idx-2])
 		}
@@ -375,9 +641,9 @@ func (mt multiplicationTable) GetTikZPicture() string {
 		op1 := components.NewCoordinatedText(
 			components.NewCoordinate(
 				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zeroheight+%v\baselineskip)$`,
-					(2.0+float64(nbdigits[0]))/2.0,
-					float64(i)-0.5,
-					0.5*(3*float64(i)-1))),
+					helpers.Ftoa((2.0+float64(nbdigits[0]))/2.0),
+					helpers.Ftoa(float64(i)-0.5),
+					helpers.Ftoa(0.5*(3*float64(i)-1)))),
 				fmt.Sprintf("op%v1", i)),
 			options,
 			text)
@@ -389,7 +655,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 			components.NewCoordinate(
 				components.Formula(fmt.Sprintf(`$(op%v1) + (%v*\zerowidth, 0.0)$`,
 					i,
-					1+(2.0+float64(nbdigits[0]))/2.0)),
+					helpers.Ftoa(1+(2.0+float64(nbdigits[0]))/2.0))),
 				fmt.Sprintf("operator%v", i)),
 			"",
 			`\huge $\times$`)
@@ -402,6 +668,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 		if instance.Args[idx-1] == "?" {
 			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
 				2+nbdigits[1])
+			text = mt.answer(instance.Solution[idx-1])
 		} else {
 			text = fmt.Sprintf(`\huge %v`, instance.Args[idx-1])
 		}
@@ -412,7 +679,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 			components.NewCoordinate(
 				components.Formula(fmt.Sprintf(`$(operator%v) + (%v*\zerowidth, 0.0)$`,
 					i,
-					1+(2.0+float64(nbdigits[1]))/2.0)),
+					helpers.Ftoa(1+(2.0+float64(nbdigits[1]))/2.0))),
 				fmt.Sprintf("op%v2", i)),
 			options,
 			text)
@@ -425,7 +692,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 			components.NewCoordinate(
 				components.Formula(fmt.Sprintf(`$(op%v2) + (%v*\zerowidth, 0.0)$`,
 					i,
-					1+(2.0+float64(nbdigits[1]))/2.0)),
+					helpers.Ftoa(1+(2.0+float64(nbdigits[1]))/2.0))),
 				fmt.Sprintf("equal%v", i)),
 			"",
 			`\huge $=$`)
@@ -439,6 +706,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 		if instance.Args[idx] == "?" {
 			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
 				2+nbdigits[2])
+			text = mt.answer(instance.Solution[idx])
 		} else {
 			text = fmt.Sprintf(`\huge %v`, instance.Args[idx])
 		}
@@ -449,7 +717,7 @@ func (mt multiplicationTable) GetTikZPicture() string {
 			components.NewCoordinate(
 				components.Formula(fmt.Sprintf(`$(equal%v) + (%v*\zerowidth, 0.0)$`,
 					i,
-					1+(2.0+float64(nbdigits[2]))/2.0)),
+					helpers.Ftoa(1+(2.0+float64(nbdigits[2]))/2.0))),
 				fmt.Sprintf("answer%v", i)),
 			options,
 			text)
@@ -476,23 +744,28 @@ func (mt multiplicationTable) GetTikZPicture() string {
 }
 
 // Return TikZ code that represents a sequence
-func (mt multiplicationTable) execute() string {
+func (mt multiplicationTable) execute() (string, error) {
 
-	// create a template with the TikZ code for showing this multiplication table
-	tpl, err := template.New("multiplicationTable").Parse(latexMultiplicationTableCode)
+	// create a template with the TikZ code for showing this multiplication
+	// table, which depends on its layout
+	code := latexMultiplicationTableCode
+	if mt.layout == MTTWOCOLUMN {
+		code = latexTwoColumnMultiplicationTableCode
+	}
+	tpl, err := template.New("multiplicationTable").Parse(code)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the execution of the
 	// template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, mt); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 // Local Variables:
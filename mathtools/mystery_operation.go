@@ -12,22 +12,68 @@
 package mathtools
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
-	"time"
+	"strings"
+	"text/template"
 
 	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
 )
 
 // constants
 // ----------------------------------------------------------------------------
 
+// the TikZ code for generating mystery operations is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexMysteryOperationCode = `\begin{minipage}{0.25\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the mystery operation
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZMysteryOperationCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Digits ----------------------------------------------------------
+
+      % every digit is shown in its own cell, aligned in columns. Masked digits
+      % are shown within empty boxes
+{{.GetCells}}
+      % --- Operator --------------------------------------------------------
+
+      % the operator is shown to the left of the second operand
+      {{.Operator}}
+
+      % --- Split line ------------------------------------------------------
+
+      % Next, a line splitting the operands and result is shown
+      {{.SplitLine}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
 // types
 // ----------------------------------------------------------------------------
 
 // A mystery operation consists of an incomplete operation of any type (+, -, /,
 // *) where some digits have been masked in the first operand, second, or the
-// result or any combination of these.
+// result or any combination of these. Subtractions can yield negative answers
+// only if negative numbers are allowed, in which case the unary minus is not
+// counted in the number of digits of the answer
 type mysteryOperation struct {
 
 	// number of digits of the first and second operand
@@ -41,11 +87,75 @@ type mysteryOperation struct {
 
 	// operator
 	operator string
+
+	// whether subtractions can yield negative answers or not
+	negative bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw mystery
+// operations
+type mysteryOperationTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// every digit of both operands and the answer is shown in its own cell
+	cells []components.CoordinatedText
+
+	// the operator and the line splitting the operands and the answer
+	Operator  components.CoordinatedText
+	SplitLine components.Line
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
 }
 
 // methods
 // ----------------------------------------------------------------------------
 
+// -- mysteryOperationTikZ
+
+// Generates the TikZ code necessary for drawing all the cells of the mystery
+// operation
+func (tikz mysteryOperationTikZ) GetCells() string {
+
+	// Use a btyes buffer to append the strings of each cell
+	var output bytes.Buffer
+
+	for _, cell := range tikz.cells {
+		fmt.Fprintf(&output, "      %v\n", cell)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// cells
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz mysteryOperationTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("mysteryOperationTikZ").Parse(tikZMysteryOperationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
 // -- mysteryOperation
 
 // return the instance of a specific mystery operation that can be marshalled in
@@ -59,10 +169,10 @@ type mysteryOperation struct {
 //    3. The 4th string is the number of digits of the answer
 //    4. Next, all digits of both operands and the digits of the answer are
 //    given consecutively. If one item has to be guessed it is masked with a
-//    question mark "?"
-func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
+//    question mark "?". If the answer is negative, its first digit is preceded
+//    by the unary minus, which is never masked
+func (mo mysteryOperation) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
 
-	rand.Seed(time.Now().UTC().UnixNano())
 
 	// create a slice with all digits to choose from
 	digits := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
@@ -73,15 +183,15 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 	// first of all, ensure there are no more masked digits in each item than
 	// digits in it
 	if mo.nbmasked1 > mo.nbdigits1 {
-		return problemJSON{}, fmt.Errorf("There are more masked digits (%v) in the first operand than digits in it (%v)",
+		return ProblemJSON{}, fmt.Errorf("There are more masked digits (%v) in the first operand than digits in it (%v)",
 			mo.nbmasked1, mo.nbdigits1)
 	}
 	if mo.nbmasked2 > mo.nbdigits2 {
-		return problemJSON{}, fmt.Errorf("There are more masked digits (%v) in the second operand than digits in it (%v)",
+		return ProblemJSON{}, fmt.Errorf("There are more masked digits (%v) in the second operand than digits in it (%v)",
 			mo.nbmasked2, mo.nbdigits2)
 	}
 	if mo.nbmaskedanswer > mo.nbdigitsanswer {
-		return problemJSON{}, fmt.Errorf("There are more masked digits (%v) in the answer than digits in it (%v)",
+		return ProblemJSON{}, fmt.Errorf("There are more masked digits (%v) in the answer than digits in it (%v)",
 			mo.nbmaskedanswer, mo.nbdigitsanswer)
 	}
 
@@ -91,21 +201,21 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 	case "+":
 		if mo.nbdigitsanswer < int(helpers.Max(float64(mo.nbdigits1), float64(mo.nbdigits2))) ||
 			mo.nbdigitsanswer > 1+int(helpers.Max(float64(mo.nbdigits1), float64(mo.nbdigits2))) {
-			return problemJSON{}, fmt.Errorf("It is not possible to generate a sum with %v digits with %v and %v digits in the first and second operands",
+			return ProblemJSON{}, fmt.Errorf("It is not possible to generate a sum with %v digits with %v and %v digits in the first and second operands",
 				mo.nbdigitsanswer, mo.nbdigits1, mo.nbdigits2)
 		}
 
 	case "-":
 		if mo.nbdigitsanswer < 1 ||
 			mo.nbdigitsanswer > int(helpers.Max(float64(mo.nbdigits1), float64(mo.nbdigits2))) {
-			return problemJSON{}, fmt.Errorf("It is not possible to generate a subtraction with %v digits with %v and %v digits in the first and second operands",
+			return ProblemJSON{}, fmt.Errorf("It is not possible to generate a subtraction with %v digits with %v and %v digits in the first and second operands",
 				mo.nbdigitsanswer, mo.nbdigits1, mo.nbdigits2)
 		}
 
 	case "*":
 		if mo.nbdigitsanswer < mo.nbdigits1+mo.nbdigits2-1 ||
 			mo.nbdigitsanswer > mo.nbdigits1+mo.nbdigits2 {
-			return problemJSON{}, fmt.Errorf("It is not possible to generate a multiplication with %v digits with %v and %v digits in the first and second operands",
+			return ProblemJSON{}, fmt.Errorf("It is not possible to generate a multiplication with %v digits with %v and %v digits in the first and second operands",
 				mo.nbdigitsanswer, mo.nbdigits1, mo.nbdigits2)
 		}
 
@@ -113,27 +223,33 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 		if mo.nbdigitsanswer < 1 ||
 			mo.nbdigitsanswer > int(helpers.Max(float64(mo.nbdigits1), float64(mo.nbdigits2)))-
 				int(helpers.Min(mo.nbdigits1, mo.nbdigits2)) {
-			return problemJSON{}, fmt.Errorf("It is not possible to generate a division with %v digits with %v and %v digits in the first and second operands",
+			return ProblemJSON{}, fmt.Errorf("It is not possible to generate a division with %v digits with %v and %v digits in the first and second operands",
 				mo.nbdigitsanswer, mo.nbdigits1, mo.nbdigits2)
 		}
 	}
 
 	// randomly pick up operands for this instance. Retry as many times as
 	// necessary as getting one instance which is compliant with the given
-	// parameters
+	// parameters, up to a maximum number of attempts. If none is found, the
+	// parameters are deemed to be incompatible
 	var operand1, operand2, answer string
-	for {
+	for attempt := 0; ; attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate a mystery operation with operator '%v', %v and %v digits in the first and second operands and %v digits in the answer after %v attempts",
+				mo.operator, mo.nbdigits1, mo.nbdigits2, mo.nbdigitsanswer, MAXGENERATIONATTEMPTS)
+		}
 
 		// create the first operand
 		operand1 = ""
 		for i := 0; i < mo.nbdigits1; i++ {
-			operand1 = operand1 + digits[rand.Intn(len(digits))]
+			operand1 = operand1 + digits[rnd.Intn(len(digits))]
 		}
 
 		// create the second operand
 		operand2 = ""
 		for i := 0; i < mo.nbdigits2; i++ {
-			operand2 = operand2 + digits[rand.Intn(len(digits))]
+			operand2 = operand2 + digits[rnd.Intn(len(digits))]
 		}
 
 		// compute the answer
@@ -141,12 +257,18 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 		op2, _ := helpers.Atoi(operand2)
 
 		// Intentionally remove combinations of subtractions/divisions where
-		// the second operand is greater than the first operand
-		if (mo.operator == "-" || mo.operator == "/") &&
+		// the second operand is greater than the first operand, unless
+		// subtractions are allowed to yield negative answers
+		if ((mo.operator == "-" && !mo.negative) || mo.operator == "/") &&
 			(op2 > op1) {
 			continue
 		}
 
+		// and also divisions by zero
+		if mo.operator == "/" && op2 == 0 {
+			continue
+		}
+
 		// compute the answer
 		switch mo.operator {
 		case "+":
@@ -161,7 +283,7 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 
 		// and verify that an answer with the given number of digits has been
 		// generated. If so, exit
-		if len(answer) == mo.nbdigitsanswer {
+		if len(strings.TrimPrefix(answer, "-")) == mo.nbdigitsanswer {
 			break
 		}
 	}
@@ -186,10 +308,15 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 		digit, _ := helpers.Atoi(operand2[i])
 		solution[4+len(operand1)+i] = fmt.Sprintf("%v", digit)
 	}
+	sign := ""
+	if strings.HasPrefix(answer, "-") {
+		sign, answer = "-", answer[1:]
+	}
 	for i := 0; i < mo.nbdigitsanswer; i++ {
 		digit, _ := helpers.Atoi(answer[i])
 		solution[4+len(operand1)+len(operand2)+i] = fmt.Sprintf("%v", digit)
 	}
+	solution[4+len(operand1)+len(operand2)] = sign + solution[4+len(operand1)+len(operand2)]
 
 	// -- args
 
@@ -197,32 +324,23 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 	// specified number of digits in each item. The following vectors contain
 	// the positions that have to be masked in each item
 	var masked1, masked2, maskedanswer []int
-	for {
-		idx := rand.Intn(mo.nbdigits1)
+	for len(masked1) < mo.nbmasked1 {
+		idx := rnd.Intn(mo.nbdigits1)
 		if !helpers.FindInt(idx, masked1) {
 			masked1 = append(masked1, idx)
 		}
-		if len(masked1) == mo.nbmasked1 {
-			break
-		}
 	}
-	for {
-		idx := rand.Intn(mo.nbdigits2)
+	for len(masked2) < mo.nbmasked2 {
+		idx := rnd.Intn(mo.nbdigits2)
 		if !helpers.FindInt(idx, masked2) {
 			masked2 = append(masked2, idx)
 		}
-		if len(masked2) == mo.nbmasked2 {
-			break
-		}
 	}
-	for {
-		idx := rand.Intn(mo.nbdigitsanswer)
+	for len(maskedanswer) < mo.nbmaskedanswer {
+		idx := rnd.Intn(mo.nbdigitsanswer)
 		if !helpers.FindInt(idx, maskedanswer) {
 			maskedanswer = append(maskedanswer, idx)
 		}
-		if len(maskedanswer) == mo.nbmaskedanswer {
-			break
-		}
 	}
 
 	// next, copy the solution to the args
@@ -246,7 +364,13 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 
 		if i >= 4+len(operand1)+len(operand2) {
 			if helpers.FindInt(i-4-len(operand1)-len(operand2), maskedanswer) {
-				args = append(args, "?")
+
+				// note the unary minus is never masked
+				if i == 4+len(operand1)+len(operand2) {
+					args = append(args, sign+"?")
+				} else {
+					args = append(args, "?")
+				}
 				continue
 			}
 		}
@@ -256,13 +380,156 @@ func (mo mysteryOperation) generateJSONProblem() (problemJSON, error) {
 	}
 
 	// Now, generate the mystery operation
-	return problemJSON{
+	return ProblemJSON{
 		Probtype: "MysteryOperation",
 		Args:     args,
 		Solution: solution,
 	}, nil
 }
 
+// return a valid LaTeX/TikZ representation of this mystery operation using
+// TikZ components. Digits are aligned in columns, with the first operand on
+// top, then the second operand and finally the answer below a split line
+func (mo mysteryOperation) GetTikZPicture() (string, error) {
+
+	// -- digits: randomly determine the digits of the operation. For this, the
+	//            service that generates problems is the one that can marshal
+	//            them into JSON format
+	instance, err := mo.next(annotated(mo))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid mystery operation: %v", err)
+	}
+
+	// the number of columns is the largest number of digits among all items,
+	// and every column is 1.5 times the width of a digit
+	nbcols := int(helpers.Max(float64(mo.nbdigits1),
+		helpers.Max(float64(mo.nbdigits2), float64(mo.nbdigitsanswer))))
+
+	// negative answers take an additional column for the unary minus
+	if mo.negative {
+		nbcols = int(helpers.Max(float64(nbcols), float64(1+mo.nbdigitsanswer)))
+	}
+	width := 1.5
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// return the location of the cell in the given row (0 for the answer, 1
+	// for the second operand and 2 for the first operand) and column, which is
+	// numbered from the right starting at zero. The leftmost column is left
+	// for the operator
+	at := func(row, col int) components.Formula {
+		return components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zeroheight + %v\baselineskip)$`,
+			helpers.Ftoa(width*(float64(nbcols-col)+0.5)),
+			helpers.Ftoa(0.5+float64(row)),
+			helpers.Ftoa(1.0+1.5*float64(row))))
+	}
+
+	// -- cells: the digits of every item are right aligned
+	var cells []components.CoordinatedText
+	var offset int
+	for row, nbdigits := range []int{mo.nbdigitsanswer, mo.nbdigits2, mo.nbdigits1} {
+
+		// the digits are given in the instance in the order of the first
+		// operand, second operand and answer
+		switch row {
+		case 0:
+			offset = 4 + mo.nbdigits1 + mo.nbdigits2
+		case 1:
+			offset = 4 + mo.nbdigits1
+		case 2:
+			offset = 4
+		}
+		for i := 0; i < nbdigits; i++ {
+			col := nbdigits - 1 - i
+			coord := components.NewCoordinate(at(row, col), fmt.Sprintf("cell%v%v", row, col))
+
+			// the unary minus of negative answers is shown in its own cell
+			// to the left of their first digit
+			item, digit := instance.Args[offset+i], instance.Solution[offset+i]
+			if strings.HasPrefix(item, "-") {
+				cells = append(cells, components.NewCoordinatedText(
+					components.NewCoordinate(at(row, nbdigits), fmt.Sprintf("cell%v%v", row, nbdigits)),
+					"", `\huge $-$`))
+				item, digit = item[1:], digit[1:]
+			}
+			if item == "?" {
+				cells = append(cells, components.NewCoordinatedText(coord,
+					fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+						helpers.Ftoa(width-0.25)),
+					mo.answer(digit)))
+			} else {
+				cells = append(cells, components.NewCoordinatedText(coord, "", `\huge `+item))
+			}
+		}
+	}
+
+	// -- operator: it is located in the leftmost column of the second operand
+	var opLaTeX string
+	switch mo.operator {
+	case "+", "-":
+		opLaTeX = mo.operator
+	case "*":
+		opLaTeX = `$\times$`
+	case "/":
+		opLaTeX = `$\div$`
+	}
+	operator := components.NewCoordinatedText(
+		components.NewCoordinate(at(1, nbcols), "operator"), "", `\huge `+opLaTeX)
+
+	// -- split line: it is drawn between the second operand and the answer
+	splitLine := components.NewLine(
+		fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight + 1.75\baselineskip)$`, helpers.Ftoa(0.25*width)),
+		fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight + 1.75\baselineskip)$`, helpers.Ftoa(width*(float64(nbcols)+1.0))))
+	splitLine.SetOptions("thick")
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 3\zeroheight + 4.5\baselineskip)$`,
+			helpers.Ftoa(width*(float64(nbcols)+1.25)))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// mystery operation
+	moPicture := mysteryOperationTikZ{
+		Bottom:    bottom,
+		cells:     cells,
+		Operator:  operator,
+		SplitLine: splitLine,
+		BBox:      bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return moPicture.execute()
+}
+
+// Return TikZ code that represents a mystery operation
+func (mo mysteryOperation) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this mystery operation
+	tpl, err := template.New("mysteryOperation").Parse(latexMysteryOperationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, mo); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
 // Local Variables:
 // mode:go
 // fill-column:80
@@ -0,0 +1,343 @@
+// -*- coding: utf-8 -*-
+// numberclassification.go
+//
+// Description: Provides services for automatically creating sheets where
+// numbers have to be classified either as even or odd, or as multiples of a
+// given number or not
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:04:18.419305721 (1792116258)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different criteria for classifying numbers: "even/odd" or
+// "multiple". In the first case, the student has to circle the even numbers;
+// in the latter, the student has to circle the multiples of a given number
+const (
+	CLASSEVENODD int = iota
+	CLASSMULTIPLE
+)
+
+// Numbers are classified as follows
+const (
+	CLASSEVEN        string = "even"
+	CLASSODD         string = "odd"
+	CLASSMULTIPLEOF  string = "multiple"
+	CLASSNOTMULTIPLE string = "nonmultiple"
+)
+
+// By default, numbers are classified as multiples of the following number, and
+// they are shown in rows with the following number of columns
+const (
+	DEFAULTCLASSMULTIPLE int = 3
+	DEFAULTCLASSCOLUMNS  int = 5
+)
+
+// Maximum number of numbers that can be classified in the same sheet
+const MAXCLASSNUMBERS int = 40
+
+// the TikZ code for generating number classifications is shown next. Note
+// that it makes use of LaTeX/TikZ components
+const latexNumberClassificationCode = `\begin{minipage}{1.0\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the number classification
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZNumberClassificationCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Numbers ---------------------------------------------------------
+
+      % all numbers are shown in rows from top to bottom, and those that
+      % satisfy the criterion are circled when showing the answers
+{{.GetNumbers}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A number classification consists of nbnumbers random numbers in the range
+// [geq, leq] shown in rows of nbcols numbers each. There are two types of
+// number classifications:
+//
+//    0: even numbers have to be circled
+//    1: multiples of the given number have to be circled
+type numberClassification struct {
+	cltype    int
+	geq, leq  int
+	nbnumbers int
+	multiple  int
+	nbcols    int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw number
+// classifications
+type numberClassificationTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// all numbers, which are circled if requested
+	numbers []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- numberClassificationTikZ
+
+// Generates the TikZ code necessary for drawing all the numbers of the number
+// classification
+func (tikz numberClassificationTikZ) GetNumbers() string {
+
+	// Use a btyes buffer to append the strings of each number
+	var output bytes.Buffer
+
+	for _, item := range tikz.numbers {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// numbers
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz numberClassificationTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("numberClassificationTikZ").Parse(tikZNumberClassificationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- numberClassification
+
+// return the number used for classifying numbers: 2 when classifying them as
+// even or odd, and the given multiple otherwise
+func (cl numberClassification) divisor() int {
+
+	if cl.cltype == CLASSEVENODD {
+		return 2
+	}
+	return cl.multiple
+}
+
+// return the classification of the given number: either "even" or "odd" when
+// classifying numbers as even or odd, and either "multiple" or "nonmultiple"
+// otherwise
+func (cl numberClassification) classify(number int) string {
+
+	if cl.cltype == CLASSEVENODD {
+		if number%2 == 0 {
+			return CLASSEVEN
+		}
+		return CLASSODD
+	}
+	if number%cl.multiple == 0 {
+		return CLASSMULTIPLEOF
+	}
+	return CLASSNOTMULTIPLE
+}
+
+// return the instance of a specific number classification that can be
+// marshalled in JSON format. The receiver is assumed to have been fully
+// verified so that it should be consistent.
+//
+// The arguments start with the number used for classifying, i.e., 2 when
+// classifying numbers as even or odd, or the given multiple otherwise. Next,
+// every number is followed by a "?", which has to be filled in by the student.
+// The solution contains the same numbers, each one followed by its
+// classification: "even" or "odd", or "multiple" or "nonmultiple"
+func (cl numberClassification) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if cl.geq < 0 || cl.geq > cl.leq {
+		return ProblemJSON{}, fmt.Errorf("The range [%v, %v] of the numbers to classify is incorrect", cl.geq, cl.leq)
+	}
+	if cl.nbnumbers < 1 || cl.nbnumbers > MAXCLASSNUMBERS {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to classify %v numbers", cl.nbnumbers)
+	}
+	if cl.divisor() < 2 {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to classify numbers as multiples of %v", cl.divisor())
+	}
+
+	// randomly generate all numbers. To avoid sheets where all numbers have
+	// the same classification, numbers are regenerated a maximum number of
+	// attempts until both classifications are present, if possible
+	numbers := make([]int, cl.nbnumbers)
+	for attempt := 0; attempt < MAXGENERATIONATTEMPTS; attempt++ {
+		found := make(map[string]bool)
+		for idx := range numbers {
+			numbers[idx] = cl.geq + rnd.Intn(1+cl.leq-cl.geq)
+			found[cl.classify(numbers[idx])] = true
+		}
+		if len(found) > 1 || cl.nbnumbers == 1 {
+			break
+		}
+	}
+
+	// create two slices: one for storing the instance of this problem where
+	// the classifications that should be given by the student are marked
+	// with question marks "?"; and another one with the full solution
+	args := []string{strconv.Itoa(cl.divisor())}
+	solution := []string{strconv.Itoa(cl.divisor())}
+	for _, number := range numbers {
+		args = append(args, strconv.Itoa(number), "?")
+		solution = append(solution, strconv.Itoa(number), cl.classify(number))
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "NumberClassification",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this number classification
+// using TikZ components
+func (cl numberClassification) GetTikZPicture() (string, error) {
+
+	// -- numbers: randomly determine the numbers to classify. For this, the
+	//             service that generates problems is the one that can marshal
+	//             them into JSON format. A question mark is a classification
+	//             that has to be guessed by the student
+	instance, err := cl.next(annotated(cl))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid number classification: %v", err)
+	}
+
+	// all numbers are shown within the same width which consists of the
+	// number of digits of the largest one plus two additional digits for
+	// circling them
+	width := 2.0 + float64(helpers.NbDigits(cl.leq))
+
+	// numbers are shown in rows of nbcols numbers within square cells, so that
+	// circles do not overlap
+	nbrows := helpers.CeilDiv(cl.nbnumbers, cl.nbcols)
+	cell := width + 0.5
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- numbers: they are shown from left to right and top to bottom. Those
+	//             that satisfy the criterion are circled when showing the
+	//             answers
+	var numbers []components.CoordinatedText
+	for idx := 0; 1+2*idx < len(instance.Args); idx++ {
+
+		row, col := idx/cl.nbcols, idx%cl.nbcols
+		options := ""
+		classification := instance.Solution[2+2*idx]
+		if cl.showAnswers() && (classification == CLASSEVEN || classification == CLASSMULTIPLEOF) {
+			options = fmt.Sprintf(`circle, minimum size=%v\zerowidth, draw, thick`, helpers.Ftoa(width))
+		}
+		numbers = append(numbers, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zerowidth)$`,
+					helpers.Ftoa(cell*(0.5+float64(col))),
+					helpers.Ftoa(cell*(0.5+float64(nbrows-1-row))))),
+				fmt.Sprintf("number%v", idx)),
+			options, fmt.Sprintf(`\huge $%v$`, instance.Args[1+2*idx])))
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zerowidth)$`,
+			helpers.Ftoa(cell*float64(helpers.Min(cl.nbcols, cl.nbnumbers))),
+			helpers.Ftoa(cell*float64(nbrows)))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the number
+	// classification
+	clPicture := numberClassificationTikZ{
+		Bottom:  bottom,
+		numbers: numbers,
+		BBox:    bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return clPicture.execute()
+}
+
+// Return TikZ code that represents a number classification
+func (cl numberClassification) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this number
+	// classification
+	tpl, err := template.New("numberClassification").Parse(latexNumberClassificationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, cl); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,302 @@
+// -*- coding: utf-8 -*-
+// numbercomparison.go
+//
+// Description: Provides services for automatically creating problems of
+// comparing numbers with <, > and =
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:05:10.205032587 (1792112710)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Maximum number of items that can be compared in the same row
+const MAXCOMPARISONITEMS int = 5
+
+// the TikZ code for generating number comparisons is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexNumberComparisonCode = `\begin{minipage}{{"{"}}{{.GetWidth}}\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the number comparison
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZNumberComparisonCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Items -----------------------------------------------------------
+
+      % all numbers are shown from left to right with an empty circle between
+      % every pair of consecutive numbers
+{{.GetItems}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A number comparison consists of a number of items, each one a random number
+// with the given number of digits, either positive or, if requested, also
+// negative. The student has to write the symbol <, > or = in the circle shown
+// between every pair of consecutive items
+type numberComparison struct {
+	nbdigits int
+	nbitems  int
+	negative bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw number
+// comparisons
+type numberComparisonTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// all numbers and the circles between them
+	items []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- numberComparisonTikZ
+
+// Generates the TikZ code necessary for drawing all the items of the number
+// comparison
+func (tikz numberComparisonTikZ) GetItems() string {
+
+	// Use a btyes buffer to append the strings of each item
+	var output bytes.Buffer
+
+	for _, item := range tikz.items {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// items
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz numberComparisonTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("numberComparisonTikZ").Parse(tikZNumberComparisonCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- numberComparison
+
+// return the fraction of the line width taken by the minipage of this number
+// comparison: pairs of numbers take half the line, whereas longer rows take
+// the whole line
+func (nc numberComparison) GetWidth() string {
+
+	if nc.nbitems == 2 {
+		return "0.5"
+	}
+	return "1.0"
+}
+
+// return the instance of a specific number comparison problem that can be
+// marshalled in JSON format. The receiver is assumed to have been fully
+// verified so that it should be consistent.
+//
+// The arguments contain all numbers with a "?" between every pair of them,
+// which has to be filled in by the student. The solution contains the same
+// numbers with the right symbol between them: "<", ">" or "="
+func (nc numberComparison) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if nc.nbitems < 2 || nc.nbitems > MAXCOMPARISONITEMS {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to compare %v numbers", nc.nbitems)
+	}
+	if nc.nbdigits < 1 || nc.nbdigits > 9 {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to compare numbers with %v digits", nc.nbdigits)
+	}
+
+	// randomly generate all numbers. To make sure that equalities are shown
+	// from time to time, every number is equal to the previous one with
+	// probability 1/4
+	numbers := make([]int, nc.nbitems)
+	for idx := range numbers {
+		if idx > 0 && rnd.Intn(4) == 0 {
+			numbers[idx] = numbers[idx-1]
+			continue
+		}
+		numbers[idx] = helpers.RandN(rnd, nc.nbdigits)
+		if nc.negative && rnd.Intn(2) == 0 {
+			numbers[idx] = -numbers[idx]
+		}
+	}
+
+	// create two slices: one for storing the instance of this problem where
+	// the symbols that should be filled in by the student are marked with
+	// question marks "?"; and another one with the full solution
+	var args, solution []string
+	for idx, number := range numbers {
+
+		// write the symbol that relates this number with the previous one
+		if idx > 0 {
+			symbol := "="
+			if numbers[idx-1] < number {
+				symbol = "<"
+			} else if numbers[idx-1] > number {
+				symbol = ">"
+			}
+			args = append(args, "?")
+			solution = append(solution, symbol)
+		}
+		args = append(args, strconv.FormatInt(int64(number), 10))
+		solution = append(solution, strconv.FormatInt(int64(number), 10))
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "NumberComparison",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this number comparison using
+// TikZ components
+func (nc numberComparison) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the numbers to compare. For this, the
+	//              service that generates problems is the one that can marshal
+	//              them into JSON format. A question mark is a symbol that has
+	//              to be guessed by the student
+	instance, err := nc.next(annotated(nc))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid number comparison: %v", err)
+	}
+
+	// all numbers are shown within the same width which consists of the
+	// number of digits plus one additional digit for the sign
+	width := 1.0 + float64(nc.nbdigits)
+
+	// circles are as wide as two digits
+	diameter := 2.0
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- items: numbers and circles are shown alternatively from left to
+	//           right, all of them vertically centered at the same height
+	var items []components.CoordinatedText
+	x := 0.5
+	for idx, arg := range instance.Args {
+
+		options, text, itemwidth := "", fmt.Sprintf(`\huge $%v$`, arg), width
+		if arg == "?" {
+			options = fmt.Sprintf(`circle, minimum size=%v\zerowidth, draw`, helpers.Ftoa(diameter))
+			text, itemwidth = "", diameter
+			if nc.showAnswers() {
+				text = fmt.Sprintf(`\huge $%v$`, instance.Solution[idx])
+			}
+		}
+		items = append(items, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight + 0.5\baselineskip)$`,
+					helpers.Ftoa(x+itemwidth/2.0))),
+				fmt.Sprintf("item%v", idx)),
+			options, text))
+		x += itemwidth + 0.5
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight + \baselineskip)$`,
+			helpers.Ftoa(x))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the number
+	// comparison
+	ncPicture := numberComparisonTikZ{
+		Bottom: bottom,
+		items:  items,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return ncPicture.execute()
+}
+
+// Return TikZ code that represents a number comparison
+func (nc numberComparison) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this number comparison
+	tpl, err := template.New("numberComparison").Parse(latexNumberComparisonCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, nc); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
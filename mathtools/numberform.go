@@ -0,0 +1,306 @@
+// -*- coding: utf-8 -*-
+// numberform.go
+//
+// Description: Provides services for automatically creating problems of
+// converting numbers between their standard form, expanded form and written
+// form
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:15:52.640173205 (1792116952)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Numbers can be written in any of the following forms: standard (e.g.,
+// 3421), expanded (e.g., 3000+400+20+1) or in words (e.g., three thousand four
+// hundred twenty-one)
+const (
+	NFSTANDARD string = "standard"
+	NFEXPANDED string = "expanded"
+	NFWORDS    string = "words"
+)
+
+// the TikZ code for generating conversions between forms of numbers is shown
+// next. Note that it makes use of LaTeX/TikZ components
+const latexNumberFormCode = `\begin{minipage}{\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the conversion
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZNumberFormCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Source ----------------------------------------------------------
+
+      % the number to convert is shown in the first line
+      {{.Source}}
+
+      % --- Target ----------------------------------------------------------
+
+      % the converted number is written in the second line, after an equal
+      % sign, within an empty box
+      {{.Equal}}
+      {{.Target}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A conversion between forms of numbers consists of the form the number is
+// given in ("from") and the form it has to be converted to ("to"), each one
+// among "standard", "expanded" and "words". Numbers have the given number of
+// digits, and they are written in words in the language given in locale
+type numberForm struct {
+	from, to string
+	nbdigits int
+	locale   string
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw
+// conversions between forms of numbers
+type numberFormTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the number to convert is shown in the first line, and the converted
+	// number is shown within a box after an equal sign in the second line
+	Source, Equal, Target components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given number written in its expanded form, i.e., as the sum of
+// the values of all its non-zero digits
+func expandedForm(number int) string {
+
+	var terms []string
+	value := strconv.Itoa(number)
+	for idx, digit := range value {
+		if digit != '0' {
+			terms = append(terms, string(digit)+strings.Repeat("0", len(value)-1-idx))
+		}
+	}
+	if len(terms) == 0 {
+		return "0"
+	}
+	return strings.Join(terms, "+")
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- numberFormTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz numberFormTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("numberFormTikZ").Parse(tikZNumberFormCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- numberForm
+
+// return the given number written in the given form
+func (nf numberForm) format(number int, form string) (string, error) {
+
+	switch form {
+	case NFSTANDARD:
+		return strconv.Itoa(number), nil
+	case NFEXPANDED:
+		return expandedForm(number), nil
+	case NFWORDS:
+		return toWords(number, nf.locale)
+	}
+	return "", fmt.Errorf("Unknown form of numbers '%v'", form)
+}
+
+// return the LaTeX text used for showing the given value written in the given
+// form. Words are written smaller than numbers so that they fit in one line
+func (nf numberForm) text(value, form string) string {
+
+	switch form {
+	case NFEXPANDED:
+		return fmt.Sprintf(`\huge $%v$`, value)
+	case NFWORDS:
+		return `\Large ` + value
+	}
+	return `\huge ` + value
+}
+
+// return the instance of a specific conversion between forms of numbers that
+// can be marshalled in JSON format. The receiver is assumed to have been fully
+// verified so that it should be consistent.
+//
+// The result is given with the number in its source form followed by the
+// number in its target form, which is shown as "?" in the arguments as it has
+// to be guessed by the student
+func (nf numberForm) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if nf.nbdigits < 1 || nf.nbdigits > helpers.NbDigits(MAXWORDSNUMBER) {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to convert numbers with %v digits", nf.nbdigits)
+	}
+
+	// randomly determine the number and write it both in the source and
+	// target form
+	number := helpers.RandN(rnd, nf.nbdigits)
+	source, err := nf.format(number, nf.from)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	target, err := nf.format(number, nf.to)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "NumberForm",
+		Args:     []string{source, "?"},
+		Solution: []string{source, target}}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this conversion using TikZ
+// components
+func (nf numberForm) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the number to convert. For this, the
+	//              service that generates problems is the one that can marshal
+	//              them into JSON format
+	instance, err := nf.next(annotated(nf))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid conversion between forms of numbers: %v", err)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// all items are placed with respect to the bottom coordinate, at the given
+	// horizontal distance (in digits) and vertical distance (in lines)
+	at := func(label string, x float64, line int) components.Coordinate {
+		return components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zeroheight + %v\baselineskip)$`,
+				helpers.Ftoa(x), helpers.Ftoa(0.5+1.5*float64(line)), helpers.Ftoa(0.5+1.5*float64(line)))),
+			label)
+	}
+
+	// -- source: it is shown left aligned in the first line
+	source := components.NewCoordinatedText(at("source", 0.5, 1),
+		`anchor=west, text width=0.9\linewidth, align=left`,
+		nf.text(instance.Args[0], nf.from))
+
+	// -- target: it is shown within a box which is wide enough to write the
+	//            number in any form
+	equal := components.NewCoordinatedText(at("equal", 1.0, 0), "", `\huge $=$`)
+	answer := ""
+	if nf.showAnswers() {
+		answer = nf.text(instance.Solution[1], nf.to)
+	}
+	target := components.NewCoordinatedText(at("target", 2.0, 0),
+		`anchor=west, rounded corners, rectangle, minimum width=0.8\linewidth, minimum height = \zeroheight + \baselineskip, draw`,
+		answer)
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(`$(bottom) + (0.95\linewidth, 2.5\zeroheight + 2.5\baselineskip)$`),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// conversion
+	nfPicture := numberFormTikZ{
+		Bottom: bottom,
+		Source: source,
+		Equal:  equal,
+		Target: target,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return nfPicture.execute()
+}
+
+// Return TikZ code that represents a conversion between forms of numbers
+func (nf numberForm) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this conversion
+	tpl, err := template.New("numberForm").Parse(latexNumberFormCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, nf); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,387 @@
+// -*- coding: utf-8 -*-
+// numberline.go
+//
+// Description: Provides services for automatically creating problems with
+// number lines
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:40:35.400473833 (1792111235)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of number line problems: "fill" or "mark". In
+// the first case, the values of some ticks are hidden and the student has to
+// fill them in; in the latter, a number is given and the student has to mark
+// it on the number line, where the values of some ticks are hidden as well
+const (
+	NLFILL int = iota
+	NLMARK
+)
+
+// the TikZ code for generating number lines is shown next. Note that it makes
+// use of LaTeX/TikZ components
+const latexNumberLineCode = `\begin{minipage}{{"{"}}{{.GetWidth}}\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the number line
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZNumberLineCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % Location of the first tick of the number line
+      {{.Origin}}
+
+      % --- Number line -----------------------------------------------------
+
+      {{.Line}}
+      % --- Boxes -----------------------------------------------------------
+
+      % the values of hidden ticks are shown within empty boxes, and the number
+      % to mark, if any, is shown above the number line
+{{.GetBoxes}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A number line problem consists of a type (either NLFILL or NLMARK), the
+// range of values [geq, leq] shown on the number line, the difference between
+// the values of consecutive ticks (step) and the number of ticks. If the number
+// of ticks is less than those necessary to cover the whole range, the value of
+// the first tick is randomly chosen. The indices of the hidden ticks can be
+// given explicitly; otherwise, they are randomly chosen. Finally, number lines
+// can be drawn either horizontally or vertically
+type numberLine struct {
+	nltype, geq, leq, step, nbticks int
+	hidden                          []int
+	vertical                        bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw number
+// lines
+type numberLineTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	// and the first tick of the number line is given with respect to it
+	Bottom, Origin components.Coordinate
+
+	// the number line
+	Line components.NumberLine
+
+	// empty boxes for the values of the hidden ticks, and the number to mark
+	boxes []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- numberLineTikZ
+
+// Generates the TikZ code necessary for drawing the boxes of the number line
+func (tikz numberLineTikZ) GetBoxes() string {
+
+	// Use a btyes buffer to append the strings of each component
+	var output bytes.Buffer
+
+	for _, box := range tikz.boxes {
+		fmt.Fprintf(&output, "      %v\n", box)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing the
+	// boxes
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz numberLineTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("numberLineTikZ").Parse(tikZNumberLineCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- numberLine
+
+// return the fraction of the line width taken by the minipage of this number
+// line: horizontal number lines take the whole line
+func (nl numberLine) GetWidth() string {
+
+	if nl.vertical {
+		return "0.25"
+	}
+	return "1.0"
+}
+
+// return the instance of a specific number line problem that can be marshalled
+// in JSON format. The receiver is assumed to have been fully verified so that
+// it should be consistent.
+//
+// The arguments contain the value of every tick, which is shown as "?" if it
+// is hidden. The solution contains the values of all ticks. Number lines of
+// type NLMARK have an additional item in both the arguments and the solution
+// with the number to mark, which is necessarily the value of a hidden tick
+func (nl numberLine) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if nl.nbticks < 2 || nl.step < 1 || nl.leq-nl.geq < (nl.nbticks-1)*nl.step {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to draw %v ticks in the range [%v, %v] with step %v",
+			nl.nbticks, nl.geq, nl.leq, nl.step)
+	}
+
+	// randomly determine the value of the first tick so that all ticks fall
+	// within the range
+	first := nl.geq + nl.step*rnd.Intn(1+(nl.leq-nl.geq-(nl.nbticks-1)*nl.step)/nl.step)
+
+	// determine the hidden ticks. If they were not given, then the first tick
+	// is always shown and some of the others are randomly hidden, keeping at
+	// least another one visible. Number lines of type NLMARK show only the
+	// first and last ticks by default
+	hidden := nl.hidden
+	if hidden == nil {
+		if nl.nltype == NLMARK {
+			for i := 1; i < nl.nbticks-1; i++ {
+				hidden = append(hidden, i)
+			}
+		} else {
+			for _, candidate := range rnd.Perm(nl.nbticks - 1)[:helpers.Min(nl.nbticks/2, nl.nbticks-2)] {
+				hidden = append(hidden, 1+candidate)
+			}
+		}
+	}
+
+	// compute now the values of all ticks
+	var args, solution []string
+	for i := 0; i < nl.nbticks; i++ {
+		value := strconv.Itoa(first + i*nl.step)
+		solution = append(solution, value)
+		if helpers.FindInt(i, hidden) {
+			args = append(args, "?")
+		} else {
+			args = append(args, value)
+		}
+	}
+
+	// in case a number has to be marked, randomly choose it among the hidden
+	// ticks
+	if nl.nltype == NLMARK {
+		if len(hidden) == 0 {
+			return ProblemJSON{}, fmt.Errorf("It is not possible to mark a number on a number line with no hidden ticks")
+		}
+		target := solution[hidden[rnd.Intn(len(hidden))]]
+		args = append(args, target)
+		solution = append(solution, target)
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "NumberLine",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this number line using TikZ
+// components
+func (nl numberLine) GetTikZPicture() (string, error) {
+
+	// -- values: randomly determine the values of all ticks. For this, the
+	//            service that generates problems is the one that can marshal
+	//            them into JSON format
+	instance, err := nl.next(annotated(nl))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid number line: %v", err)
+	}
+	values, args := instance.Solution, instance.Args
+	var target string
+	if nl.nltype == NLMARK {
+		target = values[len(values)-1]
+		values, args = values[:len(values)-1], args[:len(args)-1]
+	}
+
+	// the width of the boxes is computed from the largest number of digits of
+	// all values so that they fit comfortably
+	nbdigits := 0
+	for _, value := range values {
+		if len(value) > nbdigits {
+			nbdigits = len(value)
+		}
+	}
+	boxWidth := float64(nbdigits + 1)
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// the origin and the separation between ticks (in centimeters) depend on
+	// the orientation, leaving room for the boxes below or to the left of the
+	// number line
+	separation := helpers.Max(1.2, 0.4*float64(nbdigits+2))
+	originPoint := components.Point{X: 0.25 + separation/2.0, Y: 2.25}
+	if nl.vertical {
+		separation = 1.5
+		originPoint = components.Point{X: 1.0 + 0.4*boxWidth, Y: 1.0}
+	}
+	origin := components.NewCoordinate(originPoint, "origin")
+
+	// -- number line: labels of the hidden ticks are not shown
+	var labels []string
+	for _, arg := range args {
+		if arg == "?" {
+			labels = append(labels, "")
+		} else {
+			labels = append(labels, `\Large `+arg)
+		}
+	}
+	line := components.NewNumberLine("origin", separation, labels, nl.vertical)
+	line.SetOptions("<->, thick")
+
+	// -- boxes: the values of hidden ticks are shown within empty boxes in
+	//           number lines of type NLFILL, either below or to the left of
+	//           their ticks
+	at := func(i int) components.Formula {
+		if nl.vertical {
+			return components.Formula(fmt.Sprintf(`$(origin) + (-0.35cm - %v\zerowidth, %vcm)$`,
+				helpers.Ftoa(boxWidth/2.0), helpers.Ftoa(float64(i)*separation)))
+		}
+		return components.Formula(fmt.Sprintf(`$(origin) + (%vcm, -0.35cm - 0.5\zeroheight - 0.5\baselineskip)$`,
+			helpers.Ftoa(float64(i)*separation)))
+	}
+	var boxes []components.CoordinatedText
+	for i, arg := range args {
+		if arg == "?" && nl.nltype == NLFILL {
+			boxes = append(boxes, components.NewCoordinatedText(
+				components.NewCoordinate(at(i), fmt.Sprintf("tick%v", i)),
+				fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+					helpers.Ftoa(boxWidth)),
+				nl.answer(values[i])))
+		}
+	}
+
+	// the number to mark is shown above the number line and, when showing the
+	// answers, it is also marked on the number line
+	if nl.nltype == NLMARK {
+		position := `$(origin) + (0, 1.25cm)$`
+		if nl.vertical {
+			position = fmt.Sprintf(`$(origin) + (0, %vcm)$`, helpers.Ftoa(float64(len(args))*separation+0.25))
+		}
+		boxes = append(boxes, components.NewCoordinatedText(
+			components.NewCoordinate(components.Formula(position), "target"),
+			"rounded corners, rectangle, dashed, draw", `\huge `+target))
+		if nl.showAnswers() {
+			for i, value := range values {
+				if value == target {
+					boxes = append(boxes, components.NewCoordinatedText(
+						components.NewCoordinate(components.Formula(line.GetTick(i)), "mark"),
+						"circle, fill, inner sep=3pt", ""))
+					break
+				}
+			}
+		}
+	}
+
+	// -- bounding box
+	rightPoint := components.Point{
+		X: float64(len(args))*separation + 0.5,
+		Y: 4.25,
+	}
+	if nl.vertical {
+		rightPoint = components.Point{
+			X: originPoint.X + 1.0,
+			Y: originPoint.Y + float64(len(args))*separation + 1.0,
+		}
+	}
+	right := components.NewCoordinate(rightPoint, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// number line
+	numberLinePicture := numberLineTikZ{
+		Bottom: bottom,
+		Origin: origin,
+		Line:   line,
+		boxes:  boxes,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return numberLinePicture.execute()
+}
+
+// Return TikZ code that represents a number line problem
+func (nl numberLine) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this number line
+	tpl, err := template.New("numberLine").Parse(latexNumberLineCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, nl); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,398 @@
+// -*- coding: utf-8 -*-
+// numberpyramid.go
+//
+// Description: Provides services for automatically creating number pyramids
+// where every cell is the sum of the two cells below
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:29:56.488223791 (1792114196)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// The cells revealed in number pyramids are either all cells of the base
+// ("base") or a number of randomly chosen cells ("random") which are enough to
+// complete the pyramid
+const (
+	PYRBASE   string = "base"
+	PYRRANDOM string = "random"
+)
+
+// Number pyramids can not be higher than the following value
+const MAXPYRAMIDHEIGHT int = 6
+
+// the TikZ code for generating number pyramids is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexNumberPyramidCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the number pyramid
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZNumberPyramidCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Cells -----------------------------------------------------------
+
+      % every cell is located right above the two cells it is the sum of
+{{.GetCells}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A number pyramid consists of a number of rows (its height) where the base
+// has as many cells as the height of the pyramid and every other row has one
+// cell less than the row below. The numbers in the base are taken in the range
+// [geq, leq], and every other cell is the sum of the two cells right below it.
+// Either the base is revealed or nbrevealed cells randomly chosen so that the
+// pyramid can be completed
+type numberPyramid struct {
+	height     int
+	geq, leq   int
+	reveal     string
+	nbrevealed int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw number
+// pyramids
+type numberPyramidTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// all cells of the pyramid
+	cells []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the rank of the given matrix
+func rank(matrix [][]*big.Rat) int {
+
+	// perform Gaussian elimination over a copy of the matrix
+	rows := make([][]*big.Rat, len(matrix))
+	for i, row := range matrix {
+		for _, value := range row {
+			rows[i] = append(rows[i], new(big.Rat).Set(value))
+		}
+	}
+
+	result := 0
+	for col := 0; len(rows) > 0 && col < len(rows[0]) && result < len(rows); col++ {
+
+		// look for a pivot in this column among the rows not used yet
+		pivot := -1
+		for i := result; i < len(rows) && pivot < 0; i++ {
+			if rows[i][col].Sign() != 0 {
+				pivot = i
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		rows[result], rows[pivot] = rows[pivot], rows[result]
+
+		// and remove this column from all the rows below
+		for i := result + 1; i < len(rows); i++ {
+			factor := new(big.Rat).Quo(rows[i][col], rows[result][col])
+			for j := col; j < len(rows[i]); j++ {
+				rows[i][j].Sub(rows[i][j], new(big.Rat).Mul(factor, rows[result][j]))
+			}
+		}
+		result++
+	}
+	return result
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- numberPyramidTikZ
+
+// Generates the TikZ code necessary for drawing all the cells of the pyramid
+func (tikz numberPyramidTikZ) GetCells() string {
+
+	// Use a btyes buffer to append the strings of each cell
+	var output bytes.Buffer
+
+	for _, cell := range tikz.cells {
+		fmt.Fprintf(&output, "      %v\n", cell)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// cells
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz numberPyramidTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("numberPyramidTikZ").Parse(tikZNumberPyramidCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- numberPyramid
+
+// return the coefficients of all cells of this pyramid, from the bottom row to
+// the top one and from left to right, as a linear combination of the numbers
+// in the base
+func (np numberPyramid) coefficients() [][]*big.Rat {
+
+	var result [][]*big.Rat
+
+	// the cells of the base are given by themselves
+	var previous [][]int64
+	for i := 0; i < np.height; i++ {
+		row := make([]int64, np.height)
+		row[i] = 1
+		previous = append(previous, row)
+	}
+
+	// and every other row adds up the coefficients of the two cells below
+	for r := 0; r < np.height; r++ {
+		if r > 0 {
+			var current [][]int64
+			for i := 0; i+1 < len(previous); i++ {
+				row := make([]int64, np.height)
+				for j := range row {
+					row[j] = previous[i][j] + previous[i+1][j]
+				}
+				current = append(current, row)
+			}
+			previous = current
+		}
+		for _, row := range previous {
+			var values []*big.Rat
+			for _, value := range row {
+				values = append(values, big.NewRat(value, 1))
+			}
+			result = append(result, values)
+		}
+	}
+	return result
+}
+
+// return the instance of a specific number pyramid that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with the height of the pyramid followed by the numbers
+// of all its cells from the bottom row to the top one and from left to right.
+// Cells which are not revealed are shown as "?" in the arguments as they have
+// to be guessed by the student
+func (np numberPyramid) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if np.geq > np.leq {
+		return ProblemJSON{}, fmt.Errorf("The range [%v, %v] of the base of a number pyramid is empty", np.geq, np.leq)
+	}
+
+	// randomly choose the numbers of the base and compute all rows above it
+	var cells []int
+	row := make([]int, np.height)
+	for i := range row {
+		row[i] = np.geq + rnd.Intn(1+np.leq-np.geq)
+	}
+	for len(row) > 0 {
+		cells = append(cells, row...)
+		next := make([]int, len(row)-1)
+		for i := range next {
+			next[i] = row[i] + row[i+1]
+		}
+		row = next
+	}
+
+	// next, choose the cells to reveal. In case they are randomly chosen,
+	// ensure that the numbers of the base can be derived from them, i.e.,
+	// that their coefficients are linearly independent
+	revealed := make([]bool, len(cells))
+	if np.reveal == PYRBASE {
+		for i := 0; i < np.height; i++ {
+			revealed[i] = true
+		}
+	} else {
+		coefficients := np.coefficients()
+		for attempt := 0; ; attempt++ {
+			if attempt >= MAXGENERATIONATTEMPTS {
+				return ProblemJSON{}, fmt.Errorf("It was not possible to reveal %v cells of a number pyramid with height %v which suffice to complete it after %v attempts",
+					np.nbrevealed, np.height, MAXGENERATIONATTEMPTS)
+			}
+
+			var matrix [][]*big.Rat
+			chosen := rnd.Perm(len(cells))[:np.nbrevealed]
+			for _, idx := range chosen {
+				matrix = append(matrix, coefficients[idx])
+			}
+			if rank(matrix) == np.height {
+				for _, idx := range chosen {
+					revealed[idx] = true
+				}
+				break
+			}
+		}
+	}
+
+	// and return the problem along with its solution
+	args := []string{fmt.Sprintf("%v", np.height)}
+	solution := []string{fmt.Sprintf("%v", np.height)}
+	for idx, value := range cells {
+		solution = append(solution, fmt.Sprintf("%v", value))
+		if revealed[idx] {
+			args = append(args, fmt.Sprintf("%v", value))
+		} else {
+			args = append(args, "?")
+		}
+	}
+
+	return ProblemJSON{
+		Probtype: "NumberPyramid",
+		Args:     args,
+		Solution: solution,
+	}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this number pyramid using TikZ
+// components
+func (np numberPyramid) GetTikZPicture() (string, error) {
+
+	// -- cells: randomly determine the number pyramid. For this, the service
+	//           that generates problems is the one that can marshal them into
+	//           JSON format. A question mark is a number that has to be
+	//           guessed by the student
+	instance, err := np.next(annotated(np))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid number pyramid: %v", err)
+	}
+
+	// all cells are wide enough to write the number on top, which is the
+	// largest one
+	width := 2.0 + float64(len(instance.Solution[len(instance.Solution)-1]))
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- cells: every row is shifted half a cell to the right of the row
+	//           below
+	var cells []components.CoordinatedText
+	idx := 0
+	for r := 0; r < np.height; r++ {
+		for i := 0; i < np.height-r; i++ {
+			coord := components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zeroheight + %v\baselineskip)$`,
+					helpers.Ftoa(0.5+width*(0.5*float64(r)+float64(i)+0.5)),
+					helpers.Ftoa(0.5+float64(r)),
+					helpers.Ftoa(0.5+float64(r)))),
+				fmt.Sprintf("cell%v%v", r, i))
+			text := `\huge ` + instance.Args[1+idx]
+			if instance.Args[1+idx] == "?" {
+				text = np.answer(instance.Solution[1+idx])
+			}
+			cells = append(cells, components.NewCoordinatedText(coord,
+				fmt.Sprintf(`rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+					helpers.Ftoa(width)),
+				text))
+			idx++
+		}
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zeroheight + %v\baselineskip)$`,
+			helpers.Ftoa(1.0+width*float64(np.height)),
+			np.height, np.height)),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// number pyramid
+	npPicture := numberPyramidTikZ{
+		Bottom: bottom,
+		cells:  cells,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return npPicture.execute()
+}
+
+// Return TikZ code that represents a number pyramid
+func (np numberPyramid) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this number pyramid
+	tpl, err := template.New("numberPyramid").Parse(latexNumberPyramidCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, np); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,202 @@
+// -*- coding: utf-8 -*-
+// numberwords.go
+//
+// Description: Provides services for writing numbers in words in different
+// languages
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:15:52.640173205 (1792116952)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// By default, numbers are written in words in the following language
+const DEFAULTLOCALE string = "en"
+
+// Numbers can be written in words up to the following value
+const MAXWORDSNUMBER int = 999999999
+
+// types
+// ----------------------------------------------------------------------------
+
+// A speller writes non-negative numbers in words in a specific language
+type speller func(number int) string
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The languages acknowledged for writing numbers in words are indexed by their
+// ISO 639-1 code
+var spellers = map[string]speller{
+	"en": spellEnglish,
+	"es": spellSpanish,
+}
+
+// Names of numbers in English
+var englishUnits = []string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight",
+	"nine", "ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen",
+	"sixteen", "seventeen", "eighteen", "nineteen"}
+var englishTens = []string{
+	"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty",
+	"ninety"}
+
+// Names of numbers in Spanish. Note that numbers up to 29 are written as a
+// single word
+var spanishUnits = []string{
+	"cero", "uno", "dos", "tres", "cuatro", "cinco", "seis", "siete", "ocho",
+	"nueve", "diez", "once", "doce", "trece", "catorce", "quince", "dieciséis",
+	"diecisiete", "dieciocho", "diecinueve", "veinte", "veintiuno", "veintidós",
+	"veintitrés", "veinticuatro", "veinticinco", "veintiséis", "veintisiete",
+	"veintiocho", "veintinueve"}
+var spanishTens = []string{
+	"", "", "", "treinta", "cuarenta", "cincuenta", "sesenta", "setenta",
+	"ochenta", "noventa"}
+var spanishHundreds = []string{
+	"", "ciento", "doscientos", "trescientos", "cuatrocientos", "quinientos",
+	"seiscientos", "setecientos", "ochocientos", "novecientos"}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given number written in words in the language with the given ISO
+// 639-1 code. If the language is not acknowledged or the number can not be
+// written in words, an error is returned
+func toWords(number int, locale string) (string, error) {
+
+	spell, ok := spellers[locale]
+	if !ok {
+		return "", fmt.Errorf("Unknown language '%v' for writing numbers in words", locale)
+	}
+	if number < 0 || number > MAXWORDSNUMBER {
+		return "", fmt.Errorf("It is not possible to write %v in words", number)
+	}
+	return spell(number), nil
+}
+
+// return the given number, which is assumed to be in the range [1, 999],
+// written in words in English
+func spellEnglishHundreds(number int) string {
+
+	var words []string
+	if number >= 100 {
+		words = append(words, englishUnits[number/100], "hundred")
+		number %= 100
+	}
+	switch {
+	case number == 0:
+	case number < 20:
+		words = append(words, englishUnits[number])
+	case number%10 == 0:
+		words = append(words, englishTens[number/10])
+	default:
+		words = append(words, englishTens[number/10]+"-"+englishUnits[number%10])
+	}
+	return strings.Join(words, " ")
+}
+
+// return the given number written in words in English. Hundreds are not
+// followed by "and", as in American English
+func spellEnglish(number int) string {
+
+	if number == 0 {
+		return englishUnits[0]
+	}
+
+	// numbers are written in groups of three digits, each one followed by
+	// the name of its scale
+	var words []string
+	for _, scale := range []struct {
+		value int
+		name  string
+	}{{1000000, "million"}, {1000, "thousand"}, {1, ""}} {
+		if group := number / scale.value; group > 0 {
+			words = append(words, spellEnglishHundreds(group))
+			if scale.name != "" {
+				words = append(words, scale.name)
+			}
+		}
+		number %= scale.value
+	}
+	return strings.Join(words, " ")
+}
+
+// return the given number, which is assumed to be in the range [1, 999],
+// written in words in Spanish. If apocope is true, the last "uno" is shortened
+// as it happens before "mil" and "millones", e.g., "veintiún mil"
+func spellSpanishHundreds(number int, apocope bool) string {
+
+	var words []string
+	switch {
+	case number == 100:
+		return "cien"
+	case number >= 100:
+		words = append(words, spanishHundreds[number/100])
+		number %= 100
+	}
+	switch {
+	case number == 0:
+	case number < 30:
+		words = append(words, spanishUnits[number])
+	case number%10 == 0:
+		words = append(words, spanishTens[number/10])
+	default:
+		words = append(words, spanishTens[number/10], "y", spanishUnits[number%10])
+	}
+
+	// apply the apocope to the last word, if requested
+	if apocope {
+		switch words[len(words)-1] {
+		case "uno":
+			words[len(words)-1] = "un"
+		case "veintiuno":
+			words[len(words)-1] = "veintiún"
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// return the given number written in words in Spanish
+func spellSpanish(number int) string {
+
+	if number == 0 {
+		return spanishUnits[0]
+	}
+
+	var words []string
+
+	// millions are written in plural unless there is only one
+	if millions := number / 1000000; millions == 1 {
+		words = append(words, "un millón")
+	} else if millions > 1 {
+		words = append(words, spellSpanishHundreds(millions, true), "millones")
+	}
+	number %= 1000000
+
+	// thousands are written as "mil" if there is only one
+	if thousands := number / 1000; thousands == 1 {
+		words = append(words, "mil")
+	} else if thousands > 1 {
+		words = append(words, spellSpanishHundreds(thousands, true), "mil")
+	}
+	number %= 1000
+
+	if number > 0 {
+		words = append(words, spellSpanishHundreds(number, false))
+	}
+	return strings.Join(words, " ")
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,2105 @@
+// -*- coding: utf-8 -*-
+// options.go
+//
+// Description: Typed options for defining every type of problem without
+// dictionaries
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:44:53.134602785 (1792111493)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// Every type of problem can be defined either with a dictionary (as in master
+// files and the JSON API) or with its typed options. Dictionaries are first
+// converted into typed options, so that both are verified in the same way.
+// Typed options have no default values, i.e., all their fields have to be
+// given unless otherwise stated
+type Options interface {
+
+	// return an error if the options are not correct
+	Validate() error
+
+	// return the name of the problem type defined with these options
+	name() string
+
+	// return the problem defined with these options, which are assumed to be
+	// correct
+	generator() (generator, error)
+}
+
+// Problems defined with typed options generate instances in JSON format
+type generator interface {
+	generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error)
+}
+
+// Options of angle problems. Type is either ANCLASSIFY or ANMEASURE. The
+// measure of angles is a multiple of Step degrees, and a protractor is drawn
+// below the angle if Protractor is true
+type AngleOptions struct {
+	Type       int
+	Step       int
+	Protractor bool
+}
+
+// Options of bar chart problems. Every category has a value in the range [Geq,
+// Leq]. Mode is either BCREAD, in which case the Questions among BCVALUE,
+// BCMOST, BCLEAST and BCDIFFERENCE have to be answered, or BCDRAW
+type BarChartOptions struct {
+	Categories []string
+	Geq        int
+	Leq        int
+	Mode       string
+	Questions  []string
+}
+
+// Options of basic operations. Type is either BORESULT or BOOPERAND and the
+// operator is one among "+", "-", "*" and "/". Scaffold requests boxes for
+// recording borrows and it can only be given in subtractions, whereas CarryBox
+// requests boxes for writing carries and it can only be given in additions.
+// Carry is one among BOCARRYANY (also if empty), BOCARRYREQUIRED and
+// BOCARRYFORBIDDEN, and it either requires or forbids carries in additions and
+// borrows in subtractions. NbDecimals
+// is the number of decimal digits of all operands, which are also counted in
+// NbDigitsOp and NbDigitsRslt; it can not be given in divisions. AllowNegative
+// allows negative operands and results, but not in divisions nor with carries
+// or borrows. Layout is either BOVERTICAL (also if empty) or BOHORIZONTAL,
+// which can not be given with Scaffold nor CarryBox. Operands are bounded in
+// the range [Geq, Leq], with either one bound for all operands or one for every
+// operand; a null upper bound stands for no bound, and bounds can be given
+// with neither decimal nor negative numbers. The digits of all operands can be
+// constrained with ExcludeDigits and Patterns (see digitConstraint). Operands
+// can have a different number of digits given in NbDigitsOps, one for every
+// operand, in which case NbDigitsOp is ignored; this is not possible in
+// divisions
+type BasicOperationOptions struct {
+	Type          int
+	Operator      string
+	NbOperands    int
+	NbDigitsOp    int
+	NbDigitsOps   []int
+	NbDigitsRslt  int
+	Scaffold      bool
+	CarryBox      bool
+	Carry         string
+	NbDecimals    int
+	AllowNegative bool
+	Layout        string
+	Geq           []int
+	Leq           []int
+	ExcludeDigits []int
+	Patterns      []string
+}
+
+// Options of clocks. Type is either CLOCKREAD or CLOCKDRAW and the granularity
+// is one among "hour", "half", "quarter", "five" and "minute"
+type ClockOptions struct {
+	Type        int
+	Granularity string
+}
+
+// Options of counting problems. The number of objects is taken in the range
+// [Geq, Leq], and they are drawn with Symbol, either components.SYMBOLSTAR,
+// components.SYMBOLCIRCLE or components.SYMBOLAPPLE. If Grouped is true, they
+// are shown in groups of COUNTINGGROUPSIZE objects
+type CountingObjectsOptions struct {
+	Geq     int
+	Leq     int
+	Symbol  string
+	Grouped bool
+}
+
+// Options of divisions. Extended requests the step-by-step scaffold of the
+// long division, and Style is the layout of the division among DIVBOX,
+// DIVBRACKET and DIVPOTENCE. If it is empty, the one of the current locale is
+// used. Remainder is one among DIVRANDOM (also if empty), DIVEXACT and
+// DIVNONZERO, and RemainderBox requests a box for writing the remainder.
+// NbDecimals is the number of decimals of the quotient, which is zero by
+// default. Table requests the table of multiples of the divisor, which is only
+// available for divisors with two digits or more. The dividend and the divisor
+// are bounded in the ranges [DvGeq, DvLeq] and [DrGeq, DrLeq] respectively,
+// where a null upper bound stands for no bound, and the digits of both can be
+// constrained with ExcludeDigits and Patterns (see digitConstraint)
+type DivisionOptions struct {
+	NbDvDigits    int
+	NbDrDigits    int
+	NbQDigits     int
+	NbDecimals    int
+	Extended      bool
+	Style         string
+	Remainder     string
+	RemainderBox  bool
+	Table         bool
+	DvGeq         int
+	DvLeq         int
+	DrGeq         int
+	DrLeq         int
+	ExcludeDigits []int
+	Patterns      []string
+}
+
+// Options of elapsed time problems. Type is one among ETEND, ETDURATION and
+// ETSTART, and the granularity is one among "hour", "half", "quarter", "five"
+// and "minute". From and To are the earliest start and the latest end of
+// events given as "hh:mm", and MaxDuration is their maximum duration in minutes
+type ElapsedTimeOptions struct {
+	Type        int
+	Granularity string
+	From        string
+	To          string
+	MaxDuration int
+}
+
+// Options of equivalent fractions. Type is either EFNUMERATOR or
+// EFDENOMINATOR
+type EquivalentFractionOptions struct {
+	Type     int
+	DenGeq   int
+	DenLeq   int
+	ScaleGeq int
+	ScaleLeq int
+}
+
+// Options of fact families. Operator is either "+" (additions and
+// subtractions) or "*" (multiplications and divisions) and both numbers
+// operated are randomly chosen in the interval [Geq, Leq]. If HideFacts is
+// true, only the triangle is shown and the related facts are not requested
+type FactFamilyOptions struct {
+	Operator  string
+	Geq       int
+	Leq       int
+	HideFacts bool
+}
+
+// Options of conversions between fractions, decimals and percentages. From and
+// To are different forms among "fraction", "decimal" and "percent"
+type FDPConversionOptions struct {
+	From   string
+	To     string
+	DenLeq int
+}
+
+// Options of grid paper. The style is either "square" or "lined"
+type GridPaperOptions struct {
+	Step   float64
+	Cols   int
+	Rows   int
+	Style  string
+	Margin float64
+}
+
+// Options of linear equations. Type is either LEONESTEP or LETWOSTEP, the
+// solution is taken in the range [Geq, Leq] and the magnitude of coefficients
+// is at most CoefLeq. Negative allows negative numbers both in the coefficients
+// and the solution
+type LinearEquationOptions struct {
+	Type     int
+	Geq      int
+	Leq      int
+	CoefLeq  int
+	Negative bool
+}
+
+// Options of magic squares. Size is either 3 or 4, the magic constant is taken
+// in the range [Geq, Leq] and NbRevealed is the number of cells shown
+type MagicSquareOptions struct {
+	Size       int
+	Geq        int
+	Leq        int
+	NbRevealed int
+}
+
+// Options of crossed operations. Size is the number of operands of every
+// equation, either 2 or 3, and operands are taken in the range [Geq, Leq].
+// Operators is a string with the allowed operators among '+', '-' and '*', and
+// NbMasked is the number of numbers hidden, which can not exceed 2*Size+1
+type MathCrosswordOptions struct {
+	Size      int
+	Geq       int
+	Leq       int
+	Operators string
+	NbMasked  int
+}
+
+// Options of mixed drills. Every problem is a basic operation randomly chosen
+// among Operations, and Count problems are laid out in Cols columns
+type MixedDrillOptions struct {
+	Operations []BasicOperationOptions
+	Count      int
+	Cols       int
+}
+
+// Options of money problems. Type is either MONEYTOTAL or MONEYCHANGE and the
+// currency is one among "EUR", "USD" and "GBP". Prices are taken from the
+// range [Geq, Leq] of whole units and they have cents only if Decimals is true.
+// Coins requests the amounts given to be drawn with coins and bills
+type MoneyOptions struct {
+	Type     int
+	Currency string
+	Geq      int
+	Leq      int
+	Decimals bool
+	NbItems  int
+	Coins    bool
+}
+
+// Options of multiplication tables. Type is either MTRESULT or MTOPERAND. The
+// digits of the factor can be constrained with ExcludeDigits and Patterns (see
+// digitConstraint), and then the rows multiplied by numbers with any of the
+// excluded digits are not shown. Layout is one among MTBLOCK (also if empty),
+// MTTWOCOLUMN and MTGRID, and grids of facts can only be given with MTRESULT
+type MultiplicationTableOptions struct {
+	Type          int
+	NbDigits      int
+	Geq           int
+	Leq           int
+	Inv           bool
+	Sorted        bool
+	Layout        string
+	ExcludeDigits []int
+	Patterns      []string
+}
+
+// Options of mystery operations. The operator is one among "+", "-", "*" and
+// "/". AllowNegative allows subtractions to yield negative answers
+type MysteryOperationOptions struct {
+	NbDigits1      int
+	NbMasked1      int
+	NbDigits2      int
+	NbMasked2      int
+	NbDigitsAnswer int
+	NbMaskedAnswer int
+	Operator       string
+	AllowNegative  bool
+}
+
+// Options of number classifications. Type is either CLASSEVENODD or
+// CLASSMULTIPLE. NbNumbers numbers are taken in the range [Geq, Leq] and shown
+// in rows of NbCols numbers. Multiple is only used with CLASSMULTIPLE
+type NumberClassificationOptions struct {
+	Type      int
+	Geq       int
+	Leq       int
+	NbNumbers int
+	Multiple  int
+	NbCols    int
+}
+
+// Options of number comparisons. NbItems is the number of numbers compared in
+// the same row, and Negative requests negative numbers as well
+type NumberComparisonOptions struct {
+	NbDigits int
+	NbItems  int
+	Negative bool
+}
+
+// Options of conversions between forms of numbers. From and To are different
+// forms among NFSTANDARD, NFEXPANDED and NFWORDS. Numbers have NbDigits digits
+// and they are written in words in the language given in Locale
+type NumberFormOptions struct {
+	From     string
+	To       string
+	NbDigits int
+	Locale   string
+}
+
+// Options of number lines. Type is either NLFILL or NLMARK. If NbTicks is zero,
+// then all ticks necessary to cover the whole range are drawn, and if Hidden is
+// nil, then the hidden ticks are randomly chosen
+type NumberLineOptions struct {
+	Type     int
+	Geq      int
+	Leq      int
+	Step     int
+	NbTicks  int
+	Hidden   []int
+	Vertical bool
+}
+
+// Options of number pyramids. Height is the number of rows, the numbers of the
+// base are taken in the range [Geq, Leq] and Reveal is either PYRBASE or
+// PYRRANDOM, in which case NbRevealed cells are shown
+type NumberPyramidOptions struct {
+	Height     int
+	Geq        int
+	Leq        int
+	Reveal     string
+	NbRevealed int
+}
+
+// Options of percentages. Type is either PCRESULT, PCPERCENT or PCBASE. The
+// base is taken in the range [Geq, Leq] and the percentage in the range
+// [PctGeq, PctLeq]. The result is written with the given number of Decimals
+type PercentageOptions struct {
+	Type     int
+	Geq      int
+	Leq      int
+	PctGeq   int
+	PctLeq   int
+	Decimals int
+}
+
+// Options of perimeter and area problems. Type is either PAPERIMETER, PAAREA
+// or PASIDE and Shape is either "rectangle" or "triangle". All lengths are
+// taken in the range [Geq, Leq]
+type PerimeterAreaOptions struct {
+	Type  int
+	Shape string
+	Geq   int
+	Leq   int
+}
+
+// Options of pictograms. Every category has a value in the range [Geq, Leq],
+// and they are shown with Chart, either PICTALLY or PICPICTOGRAM, in which
+// case Symbol is used for every unit. Questions are among PICMOST, PICLEAST
+// and PICTOTAL
+type PictogramOptions struct {
+	Categories []string
+	Geq        int
+	Leq        int
+	Chart      string
+	Symbol     string
+	Questions  []string
+}
+
+// Options of place value problems. Type is either PVDECOMPOSE or PVCOMPOSE. In
+// the first case, either the names of the masked places are given in Masked
+// (e.g., "tens" or "units") or NbMasked places are randomly masked
+type PlaceValueOptions struct {
+	Type     int
+	NbDigits int
+	NbMasked int
+	Masked   []string
+}
+
+// Options of prime factorizations. Numbers are taken in the range [Geq, Leq]
+// and they have at least two and at most MaxFactors prime factors, counted
+// with their multiplicity
+type PrimeFactorizationOptions struct {
+	Geq        int
+	Leq        int
+	MaxFactors int
+}
+
+// Options of ratios. Type is either RTSECOND or RTFIRST
+type RatioOptions struct {
+	Type     int
+	Geq      int
+	Leq      int
+	ScaleGeq int
+	ScaleLeq int
+}
+
+// Options of rounding problems. Place is the name of the place numbers are
+// rounded to, one among "ten", "hundred", "thousand", "ten thousand" and
+// "hundred thousand". Tiebreak is the rule used for rounding numbers halfway
+// between two multiples, one among ROUNDUP (also if empty), ROUNDEVEN and
+// ROUNDAWAY. Highlight requests the digit of the place to be highlighted
+type RoundingOptions struct {
+	NbDigits  int
+	Place     string
+	Tiebreak  string
+	Highlight bool
+}
+
+// Options of sequences. Type is one among SEQNONE, SEQFIRST, SEQLAST and
+// SEQBOTH. Pattern is one among SEQARITHMETIC, SEQGEOMETRIC, SEQALTERNATING
+// and SEQFIBONACCI, and an empty pattern is taken as arithmetic. Step is the
+// difference between consecutive numbers, which is negative when counting
+// backwards, or the ratio between them in geometric sequences. For backwards
+// compatibility, a step equal to zero is taken as one. AltStep is the second
+// difference of alternating sequences. StartMultiple requests the first number
+// to be a multiple of the step. Finally, if either the indices of the items to
+// hide are given in Mask (starting from zero) or their number in NbMasked, then
+// they override the type
+type SequenceOptions struct {
+	Type          int
+	NbItems       int
+	Geq           int
+	Leq           int
+	Pattern       string
+	Step          int
+	AltStep       int
+	StartMultiple bool
+	Mask          []int
+	NbMasked      int
+}
+
+// Options of shaded fractions. Type is either SFREAD or SFSHADE, and Shape is
+// either "pie" or "bar". Denominators are taken in the range [DenGeq, DenLeq]
+// and Contiguous requests shaded parts to be consecutive
+type ShadedFractionOptions struct {
+	Type       int
+	Shape      string
+	DenGeq     int
+	DenLeq     int
+	Contiguous bool
+}
+
+// Options of unit conversions. Quantity is one among "length", "mass" and
+// "capacity", and Direction is one among UCDOWN, UCUP and UCBOTH. Values are
+// taken from the range [Geq, Leq] and divided by ten to the number of Decimals
+type UnitConversionOptions struct {
+	Quantity  string
+	Direction string
+	Geq       int
+	Leq       int
+	Decimals  int
+}
+
+// Options of word problems. The operator is one among "+", "-", "*" and "/". If
+// Bank is empty, then the default bank of stories of the current locale is
+// used; otherwise, it is the name of a file with a bank of stories in JSON
+// format
+type WordProblemOptions struct {
+	Operator string
+	Geq      int
+	Leq      int
+	Bank     string
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return a new master problem which generates the given number of problems
+// defined with the specified options. If avoidrepeat is true, consecutive
+// problems with the same arguments and solution are avoided. If the options are not
+// correct, an error is returned
+func NewMasterProblem(options Options, nbprobs int, avoidrepeat bool) (MasterProblem, error) {
+
+	if err := options.Validate(); err != nil {
+		return MasterProblem{}, err
+	}
+	return MasterProblem{
+		probtype:    options.name(),
+		options:     options,
+		nbprobs:     nbprobs,
+		avoidrepeat: avoidrepeat,
+	}, nil
+}
+
+// return an error if the bounds [geq, leq] of the given operand are negative or
+// they do not admit any number with the given number of digits. A null upper
+// bound stands for no bound
+func verifyOperandRange(operand string, nbdigits, geq, leq int) error {
+
+	if geq < 0 || leq < 0 {
+		return fmt.Errorf("the bounds of the %v should be non-negative", operand)
+	}
+	if lower, upper := operandRange(nbdigits, geq, leq); lower > upper {
+		if leq == 0 {
+			return fmt.Errorf("there are no numbers with %v digits greater or equal than %v for the %v", nbdigits, geq, operand)
+		}
+		return fmt.Errorf("there are no numbers with %v digits in the range [%v, %v] given for the %v", nbdigits, geq, leq, operand)
+	}
+	return nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- AngleOptions
+
+// return an error if the options of this angle problem are not correct
+func (options AngleOptions) Validate() error {
+
+	if options.Type != ANCLASSIFY && options.Type != ANMEASURE {
+		return fmt.Errorf("the type of an angle problem given '%v' is incorrect", options.Type)
+	}
+	if options.Step < 1 || options.Step >= 180 {
+		return fmt.Errorf("the step of the measure of angles given '%v' should be in the range [1, 179]", options.Step)
+	}
+	return nil
+}
+
+// return the angle problem defined with these options
+func (options AngleOptions) angle() angle {
+	return angle{
+		antype:     options.Type,
+		step:       options.Step,
+		protractor: options.Protractor,
+	}
+}
+
+func (options AngleOptions) name() string {
+	return "Angle"
+}
+
+func (options AngleOptions) generator() (generator, error) {
+	return options.angle(), nil
+}
+
+// -- BarChartOptions
+
+// return an error if the options of this bar chart problem are not correct
+func (options BarChartOptions) Validate() error {
+
+	if len(options.Categories) < 2 || len(options.Categories) > MAXBARCHARTCATEGORIES {
+		return fmt.Errorf("a bar chart should have between two and %v categories", MAXBARCHARTCATEGORIES)
+	}
+	for idx, category := range options.Categories {
+		if category == "" {
+			return errors.New("the names of the categories of a bar chart can not be empty")
+		}
+		if helpers.Find(category, options.Categories[:idx]) {
+			return fmt.Errorf("the category '%v' of a bar chart is given more than once", category)
+		}
+	}
+	if options.Geq < 0 || options.Geq > options.Leq || options.Leq < 1 || options.Leq > MAXBARCHARTVALUE {
+		return fmt.Errorf("the range [%v, %v] of the values of a bar chart should be non-empty and within [0, %v]",
+			options.Geq, options.Leq, MAXBARCHARTVALUE)
+	}
+	if options.Mode != BCREAD && options.Mode != BCDRAW {
+		return fmt.Errorf("the mode of a bar chart given '%v' should be either '%v' or '%v'", options.Mode, BCREAD, BCDRAW)
+	}
+	if options.Mode == BCREAD && len(options.Questions) == 0 {
+		return errors.New("at least one question should be asked in a bar chart")
+	}
+	for _, question := range options.Questions {
+		if !helpers.Find(question, []string{BCVALUE, BCMOST, BCLEAST, BCDIFFERENCE}) {
+			return fmt.Errorf("the question of a bar chart given '%v' should be either '%v', '%v', '%v' or '%v'",
+				question, BCVALUE, BCMOST, BCLEAST, BCDIFFERENCE)
+		}
+	}
+	return nil
+}
+
+// return the bar chart problem defined with these options
+func (options BarChartOptions) barChart() barChart {
+	return barChart{
+		categories: options.Categories,
+		geq:        options.Geq,
+		leq:        options.Leq,
+		mode:       options.Mode,
+		questions:  options.Questions,
+	}
+}
+
+func (options BarChartOptions) name() string {
+	return "BarChart"
+}
+
+func (options BarChartOptions) generator() (generator, error) {
+	return options.barChart(), nil
+}
+
+// -- BasicOperationOptions
+
+// return an error if the options of this basic operation are not correct
+func (options BasicOperationOptions) Validate() error {
+
+	if !helpers.Find(options.Operator, []string{"+", "-", "*", "/"}) {
+		return errors.New("The operator of a basic operation has to be one and only one among the following: '+', '-', '*' or '/'")
+	}
+	if options.Type < BORESULT || options.Type > BOOPERAND {
+		return fmt.Errorf("the type of a basic operation given '%v' is incorrect", options.Type)
+	}
+
+	// operands with a different number of digits should be given one for
+	// every operand
+	if len(options.NbDigitsOps) > 0 {
+		if len(options.NbDigitsOps) != options.NbOperands {
+			return fmt.Errorf("the number of digits of the operands of a basic operation should be given once for each one of its %v operands", options.NbOperands)
+		}
+		for _, nbdigits := range options.NbDigitsOps {
+			if nbdigits < 1 {
+				return fmt.Errorf("the number of digits of the operands of a basic operation given '%v' should be strictly positive", nbdigits)
+			}
+		}
+		if options.Operator == "/" {
+			return errors.New("the operands of divisions can not be given with a different number of digits")
+		}
+	}
+	widths := options.widths()
+	widest, narrowest := widths[0], widths[0]
+	for _, nbdigits := range widths {
+		widest = int(helpers.Max(float64(widest), float64(nbdigits)))
+		narrowest = helpers.Min(narrowest, nbdigits)
+	}
+
+	if options.Scaffold && options.Operator != "-" {
+		return errors.New("the scaffold for recording borrows can only be shown in subtractions")
+	}
+	if options.CarryBox && options.Operator != "+" {
+		return errors.New("the boxes for writing carries can only be shown in additions")
+	}
+	if options.Carry != "" && !helpers.Find(options.Carry, []string{BOCARRYANY, BOCARRYREQUIRED, BOCARRYFORBIDDEN}) {
+		return fmt.Errorf("the carries of a basic operation given '%v' should be either '%v', '%v' or '%v'",
+			options.Carry, BOCARRYANY, BOCARRYREQUIRED, BOCARRYFORBIDDEN)
+	}
+	regrouping := options.Carry == BOCARRYREQUIRED || options.Carry == BOCARRYFORBIDDEN
+	if regrouping && options.Operator != "+" && options.Operator != "-" {
+		return errors.New("carries can only be required or forbidden in additions and subtractions")
+	}
+
+	// additions without carries have necessarily as many digits as their
+	// operands
+	if options.Carry == BOCARRYFORBIDDEN && options.Operator == "+" && widest != options.NbDigitsRslt {
+		return errors.New("additions without carries should have as many digits in the result as in their widest operand")
+	}
+
+	// additions of one digit with carries have necessarily two digits, and
+	// subtractions borrow in a column other than the leading one
+	if options.Carry == BOCARRYREQUIRED && options.Operator == "+" && widest == 1 && options.NbDigitsRslt == 1 {
+		return errors.New("additions of one digit with carries should have two digits in the result")
+	}
+	if options.Carry == BOCARRYREQUIRED && options.Operator == "-" && widths[0] < 2 {
+		return errors.New("subtractions with borrows should have operands with two digits at least")
+	}
+
+	// operands always have at least one digit in their integer part
+	if options.NbDecimals < 0 || options.NbDecimals >= narrowest {
+		return fmt.Errorf("the number of decimal digits of a basic operation given '%v' should be non-negative and less than the number of digits of its operands", options.NbDecimals)
+	}
+	if options.NbDecimals > 0 && options.Operator == "/" {
+		return errors.New("divisions can not be given with decimal digits")
+	}
+	if options.AllowNegative && options.Operator == "/" {
+		return errors.New("divisions can not be given with negative numbers")
+	}
+	if options.AllowNegative && (options.Scaffold || options.CarryBox || regrouping) {
+		return errors.New("carries and borrows can not be either shown, required or forbidden in basic operations with negative numbers")
+	}
+	if options.Layout != "" && options.Layout != BOVERTICAL && options.Layout != BOHORIZONTAL {
+		return fmt.Errorf("the layout of a basic operation given '%v' should be either '%v' or '%v'", options.Layout, BOVERTICAL, BOHORIZONTAL)
+	}
+	if options.Layout == BOHORIZONTAL && (options.Scaffold || options.CarryBox) {
+		return errors.New("neither the scaffold nor the carry boxes can be shown in basic operations with a horizontal layout")
+	}
+
+	// the digits of the operands should be feasible
+	operands := make([]string, len(widths))
+	for i := range operands {
+		operands[i] = fmt.Sprintf("operand #%v of a basic operation", 1+i)
+	}
+	if err := options.constraint().verify(operands, widths); err != nil {
+		return err
+	}
+
+	// bounds are given either for all operands or for every operand, and they
+	// should admit numbers with the number of digits of the operands
+	if len(options.Geq) == 0 && len(options.Leq) == 0 {
+		return nil
+	}
+	if options.NbDecimals > 0 || options.AllowNegative {
+		return errors.New("the operands of basic operations can not be bounded with either decimal or negative numbers")
+	}
+	for _, bounds := range [][]int{options.Geq, options.Leq} {
+		if len(bounds) > 1 && len(bounds) != options.NbOperands {
+			return fmt.Errorf("the bounds of the operands of a basic operation should be given either once or once for each one of its %v operands", options.NbOperands)
+		}
+	}
+	bo := options.basicOperation()
+	for i := 0; i < options.NbOperands; i++ {
+		geq, leq := bo.bounds(i)
+		if err := verifyOperandRange(operands[i], widths[i], geq, leq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// return the basic operation defined with these options. If the operands have
+// a different number of digits, the number of digits of the basic operation is
+// the one of its widest operand
+func (options BasicOperationOptions) basicOperation() basicOperation {
+
+	nbdigitsop := options.NbDigitsOp
+	if len(options.NbDigitsOps) > 0 {
+		nbdigitsop = 0
+		for _, nbdigits := range options.NbDigitsOps {
+			nbdigitsop = int(helpers.Max(float64(nbdigitsop), float64(nbdigits)))
+		}
+	}
+	return basicOperation{
+		botype:       options.Type,
+		operator:     options.Operator,
+		nboperands:   options.NbOperands,
+		nbdigitsop:   nbdigitsop,
+		nbdigitsops:  options.NbDigitsOps,
+		nbdigitsrslt: options.NbDigitsRslt,
+		scaffold:     options.Scaffold,
+		carrybox:     options.CarryBox,
+		carry:        options.carry(),
+		nbdecimals:   options.NbDecimals,
+		negative:     options.AllowNegative,
+		layout:       options.Layout,
+		geq:          options.Geq,
+		leq:          options.Leq,
+		constraint:   options.constraint(),
+	}
+}
+
+// return the number of digits of every operand given in these options
+func (options BasicOperationOptions) widths() []int {
+
+	if len(options.NbDigitsOps) > 0 {
+		return options.NbDigitsOps
+	}
+	widths := make([]int, options.NbOperands)
+	for i := range widths {
+		widths[i] = options.NbDigitsOp
+	}
+	if len(widths) == 0 {
+		widths = []int{options.NbDigitsOp}
+	}
+	return widths
+}
+
+// return the carries requested in these options, which are allowed in any
+// column by default
+func (options BasicOperationOptions) carry() string {
+	if options.Carry == "" {
+		return BOCARRYANY
+	}
+	return options.Carry
+}
+
+// return the constraint on the digits of the operands of these options
+func (options BasicOperationOptions) constraint() digitConstraint {
+	return digitConstraint{excluded: options.ExcludeDigits, patterns: options.Patterns}
+}
+
+func (options BasicOperationOptions) name() string {
+	return "BasicOperation"
+}
+
+func (options BasicOperationOptions) generator() (generator, error) {
+	return options.basicOperation(), nil
+}
+
+// -- ClockOptions
+
+// return an error if the options of this clock are not correct
+func (options ClockOptions) Validate() error {
+
+	if clockStep(options.Granularity) == 0 {
+		return errors.New("the granularity of a clock has to be one and only one among the following: 'hour', 'half', 'quarter', 'five' or 'minute'")
+	}
+	if options.Type < CLOCKREAD || options.Type > CLOCKDRAW {
+		return fmt.Errorf("the type of a clock given '%v' is incorrect", options.Type)
+	}
+	return nil
+}
+
+// return the clock defined with these options
+func (options ClockOptions) clock() clock {
+	return clock{
+		clocktype:   options.Type,
+		granularity: options.Granularity,
+	}
+}
+
+func (options ClockOptions) name() string {
+	return "Clock"
+}
+
+func (options ClockOptions) generator() (generator, error) {
+	return options.clock(), nil
+}
+
+// -- CountingObjectsOptions
+
+// return an error if the options of this counting problem are not correct
+func (options CountingObjectsOptions) Validate() error {
+
+	if options.Geq < 1 || options.Geq > options.Leq || options.Leq > MAXCOUNTINGOBJECTS {
+		return fmt.Errorf("the range [%v, %v] of the number of objects should be non-empty and within [1, %v]",
+			options.Geq, options.Leq, MAXCOUNTINGOBJECTS)
+	}
+	if !helpers.Find(options.Symbol, []string{components.SYMBOLSTAR, components.SYMBOLCIRCLE, components.SYMBOLAPPLE}) {
+		return fmt.Errorf("the symbol of a counting problem given '%v' should be either '%v', '%v' or '%v'",
+			options.Symbol, components.SYMBOLSTAR, components.SYMBOLCIRCLE, components.SYMBOLAPPLE)
+	}
+	return nil
+}
+
+// return the counting problem defined with these options
+func (options CountingObjectsOptions) countingObjects() countingObjects {
+	return countingObjects{
+		geq:     options.Geq,
+		leq:     options.Leq,
+		symbol:  options.Symbol,
+		grouped: options.Grouped,
+	}
+}
+
+func (options CountingObjectsOptions) name() string {
+	return "CountingObjects"
+}
+
+func (options CountingObjectsOptions) generator() (generator, error) {
+	return options.countingObjects(), nil
+}
+
+// -- DivisionOptions
+
+// return an error if the options of this division are not correct
+func (options DivisionOptions) Validate() error {
+
+	if options.NbDvDigits <= 0 || options.NbDrDigits <= 0 || options.NbQDigits <= 0 {
+		return errors.New("the number of digits of the dividend, divisor and quotient should be strictly positive")
+	}
+	if options.NbDvDigits < options.NbDrDigits {
+		return fmt.Errorf("the number of digits of the dividend given '%v' should be greater or equal than the number of digits of the divisor given '%v'",
+			options.NbDvDigits, options.NbDrDigits)
+	}
+	if err := verifyOperandRange("dividend", options.NbDvDigits, options.DvGeq, options.DvLeq); err != nil {
+		return err
+	}
+	if err := verifyOperandRange("divisor", options.NbDrDigits, options.DrGeq, options.DrLeq); err != nil {
+		return err
+	}
+	if err := options.constraint().verify([]string{"dividend", "divisor"}, []int{options.NbDvDigits, options.NbDrDigits}); err != nil {
+		return err
+	}
+	if options.Table && options.NbDrDigits < 2 {
+		return errors.New("the table of multiples of the divisor is only available for divisors with two digits or more")
+	}
+	if options.NbDecimals < 0 {
+		return fmt.Errorf("the number of decimals of the quotient of a division given '%v' should be non-negative", options.NbDecimals)
+	}
+	if options.Style != "" && !helpers.Find(options.Style, []string{DIVBOX, DIVBRACKET, DIVPOTENCE}) {
+		return fmt.Errorf("the style of a division given '%v' is incorrect. It should be one and only one among the following: '%v', '%v' or '%v'",
+			options.Style, DIVBOX, DIVBRACKET, DIVPOTENCE)
+	}
+	if options.Remainder != "" && !helpers.Find(options.Remainder, []string{DIVRANDOM, DIVEXACT, DIVNONZERO}) {
+		return fmt.Errorf("the remainder of a division given '%v' is incorrect. It should be one and only one among the following: '%v', '%v' or '%v'",
+			options.Remainder, DIVRANDOM, DIVEXACT, DIVNONZERO)
+	}
+	return nil
+}
+
+// return the division defined with these options
+func (options DivisionOptions) division() division {
+	return division{
+		nbdvdigits:   options.NbDvDigits,
+		nbdrdigits:   options.NbDrDigits,
+		nbqdigits:    options.NbQDigits,
+		nbdecimals:   options.NbDecimals,
+		extended:     options.Extended,
+		style:        options.Style,
+		remainder:    options.Remainder,
+		remainderbox: options.RemainderBox,
+		table:        options.Table,
+		dvgeq:        options.DvGeq,
+		dvleq:        options.DvLeq,
+		drgeq:        options.DrGeq,
+		drleq:        options.DrLeq,
+		constraint:   options.constraint(),
+	}
+}
+
+// return the constraint on the digits of the dividend and the divisor of these
+// options
+func (options DivisionOptions) constraint() digitConstraint {
+	return digitConstraint{excluded: options.ExcludeDigits, patterns: options.Patterns}
+}
+
+func (options DivisionOptions) name() string {
+	return "Division"
+}
+
+func (options DivisionOptions) generator() (generator, error) {
+	return options.division(), nil
+}
+
+// -- ElapsedTimeOptions
+
+// return an error if the options of this elapsed time problem are not correct
+func (options ElapsedTimeOptions) Validate() error {
+
+	step := clockStep(options.Granularity)
+	if step == 0 {
+		return errors.New("the granularity of an elapsed time problem has to be one and only one among the following: 'hour', 'half', 'quarter', 'five' or 'minute'")
+	}
+	if options.Type < ETEND || options.Type > ETSTART {
+		return fmt.Errorf("the type of an elapsed time problem given '%v' is incorrect", options.Type)
+	}
+	from, err := parseTime(options.From)
+	if err != nil {
+		return err
+	}
+	to, err := parseTime(options.To)
+	if err != nil {
+		return err
+	}
+	if from >= to {
+		return fmt.Errorf("the earliest start '%v' of an elapsed time problem should be before its latest end '%v'", options.From, options.To)
+	}
+	if options.MaxDuration < step {
+		return fmt.Errorf("the maximum duration of an elapsed time problem should be at least %v minutes", step)
+	}
+	return nil
+}
+
+// return the elapsed time problem defined with these options
+func (options ElapsedTimeOptions) elapsedTime() elapsedTime {
+	from, _ := parseTime(options.From)
+	to, _ := parseTime(options.To)
+	return elapsedTime{
+		ettype:      options.Type,
+		granularity: options.Granularity,
+		from:        from,
+		to:          to,
+		maxduration: options.MaxDuration,
+	}
+}
+
+func (options ElapsedTimeOptions) name() string {
+	return "ElapsedTime"
+}
+
+func (options ElapsedTimeOptions) generator() (generator, error) {
+	return options.elapsedTime(), nil
+}
+
+// -- EquivalentFractionOptions
+
+// return an error if the options of this equivalent fraction are not correct
+func (options EquivalentFractionOptions) Validate() error {
+
+	if options.Type < EFNUMERATOR || options.Type > EFDENOMINATOR {
+		return fmt.Errorf("the type of an equivalent fraction given '%v' is incorrect", options.Type)
+	}
+	return nil
+}
+
+// return the equivalent fraction defined with these options
+func (options EquivalentFractionOptions) equivalentFraction() equivalentFraction {
+	return equivalentFraction{
+		eftype:   options.Type,
+		dengeq:   options.DenGeq,
+		denleq:   options.DenLeq,
+		scalegeq: options.ScaleGeq,
+		scaleleq: options.ScaleLeq,
+	}
+}
+
+func (options EquivalentFractionOptions) name() string {
+	return "EquivalentFraction"
+}
+
+func (options EquivalentFractionOptions) generator() (generator, error) {
+	return options.equivalentFraction(), nil
+}
+
+// -- FactFamilyOptions
+
+// return an error if the options of this fact family are not correct
+func (options FactFamilyOptions) Validate() error {
+
+	if options.Operator != "+" && options.Operator != "*" {
+		return fmt.Errorf("the operator of a fact family given '%v' is incorrect. It should be either '+' or '*'", options.Operator)
+	}
+	if options.Geq < 0 || (options.Operator == "*" && options.Geq < 1) {
+		return fmt.Errorf("the lower bound of the numbers of a fact family given '%v' is incorrect", options.Geq)
+	}
+	if options.Geq > options.Leq {
+		return fmt.Errorf("the lower bound of the numbers of a fact family '%v' can not be larger than its upper bound '%v'", options.Geq, options.Leq)
+	}
+	return nil
+}
+
+// return the fact family defined with these options
+func (options FactFamilyOptions) factFamily() factFamily {
+	return factFamily{
+		operator:  options.Operator,
+		geq:       options.Geq,
+		leq:       options.Leq,
+		hideFacts: options.HideFacts,
+	}
+}
+
+func (options FactFamilyOptions) name() string {
+	return "FactFamily"
+}
+
+func (options FactFamilyOptions) generator() (generator, error) {
+	return options.factFamily(), nil
+}
+
+// -- FDPConversionOptions
+
+// return an error if the options of this conversion are not correct
+func (options FDPConversionOptions) Validate() error {
+
+	forms := []string{"fraction", "decimal", "percent"}
+	if !helpers.Find(options.From, forms) {
+		return errors.New("the form of the value to convert has to be one and only one among the following: 'fraction', 'decimal' or 'percent'")
+	}
+	if !helpers.Find(options.To, forms) {
+		return errors.New("the form of the converted value has to be one and only one among the following: 'fraction', 'decimal' or 'percent'")
+	}
+	if options.From == options.To {
+		return fmt.Errorf("a %v can not be converted into a %v", options.From, options.To)
+	}
+	if options.DenLeq < 2 {
+		return errors.New("the upper bound of the denominator should be given as an integer greater or equal than 2")
+	}
+	return nil
+}
+
+// return the conversion defined with these options
+func (options FDPConversionOptions) fdpConversion() fdpConversion {
+	return fdpConversion{
+		from:   options.From,
+		to:     options.To,
+		denleq: options.DenLeq,
+	}
+}
+
+func (options FDPConversionOptions) name() string {
+	return "FDPConversion"
+}
+
+func (options FDPConversionOptions) generator() (generator, error) {
+	return options.fdpConversion(), nil
+}
+
+// -- GridPaperOptions
+
+// return an error if the options of this grid paper are not correct
+func (options GridPaperOptions) Validate() error {
+
+	if options.Step <= 0 {
+		return errors.New("the step of grid paper should be given as a strictly positive number")
+	}
+	if options.Cols <= 0 {
+		return errors.New("the number of columns of grid paper should be given as a strictly positive integer")
+	}
+	if options.Rows <= 0 {
+		return errors.New("the number of rows of grid paper should be given as a strictly positive integer")
+	}
+	if !helpers.Find(options.Style, []string{"square", "lined"}) {
+		return errors.New("the style of grid paper has to be one and only one among the following: 'square' or 'lined'")
+	}
+	if options.Margin < 0 {
+		return errors.New("the margin of grid paper should be given as a non-negative number")
+	}
+	return nil
+}
+
+// return the grid paper defined with these options
+func (options GridPaperOptions) gridPaper() gridPaper {
+	return gridPaper{
+		step:   options.Step,
+		cols:   options.Cols,
+		rows:   options.Rows,
+		style:  options.Style,
+		margin: options.Margin,
+	}
+}
+
+func (options GridPaperOptions) name() string {
+	return "GridPaper"
+}
+
+func (options GridPaperOptions) generator() (generator, error) {
+	return options.gridPaper(), nil
+}
+
+// -- LinearEquationOptions
+
+// return an error if the options of this linear equation are not correct
+func (options LinearEquationOptions) Validate() error {
+
+	if options.Type < LEONESTEP || options.Type > LETWOSTEP {
+		return fmt.Errorf("the type of a linear equation given '%v' is incorrect", options.Type)
+	}
+	if options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the solution of a linear equation is empty", options.Geq, options.Leq)
+	}
+	if !options.Negative && options.Geq < 0 {
+		return errors.New("the solution of a linear equation can be negative only if negative numbers are allowed")
+	}
+	if options.CoefLeq < 2 {
+		return fmt.Errorf("the largest coefficient of a linear equation given '%v' should be at least 2", options.CoefLeq)
+	}
+	return nil
+}
+
+// return the linear equation defined with these options
+func (options LinearEquationOptions) linearEquation() linearEquation {
+	return linearEquation{
+		letype:   options.Type,
+		geq:      options.Geq,
+		leq:      options.Leq,
+		coefleq:  options.CoefLeq,
+		negative: options.Negative,
+	}
+}
+
+func (options LinearEquationOptions) name() string {
+	return "LinearEquation"
+}
+
+func (options LinearEquationOptions) generator() (generator, error) {
+	return options.linearEquation(), nil
+}
+
+// -- MagicSquareOptions
+
+// return an error if the options of this magic square are not correct
+func (options MagicSquareOptions) Validate() error {
+
+	if options.Size != 3 && options.Size != 4 {
+		return fmt.Errorf("the size of a magic square given '%v' should be either 3 or 4", options.Size)
+	}
+	if options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the magic constant is empty", options.Geq, options.Leq)
+	}
+	if options.NbRevealed < 0 || options.NbRevealed > options.Size*options.Size {
+		return fmt.Errorf("the number of revealed cells of a magic square should be in the range [0, %v]", options.Size*options.Size)
+	}
+	return nil
+}
+
+// return the magic square defined with these options
+func (options MagicSquareOptions) magicSquare() magicSquare {
+	return magicSquare{
+		size:       options.Size,
+		geq:        options.Geq,
+		leq:        options.Leq,
+		nbrevealed: options.NbRevealed,
+	}
+}
+
+func (options MagicSquareOptions) name() string {
+	return "MagicSquare"
+}
+
+func (options MagicSquareOptions) generator() (generator, error) {
+	return options.magicSquare(), nil
+}
+
+// -- MathCrosswordOptions
+
+// return an error if the options of these crossed operations are not correct
+func (options MathCrosswordOptions) Validate() error {
+
+	if options.Size != 2 && options.Size != 3 {
+		return fmt.Errorf("the size of crossed operations given '%v' should be either 2 or 3", options.Size)
+	}
+	if options.Geq < 0 || options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the operands of crossed operations should be non-empty and non-negative", options.Geq, options.Leq)
+	}
+	if options.Operators == "" || strings.Trim(options.Operators, "+-*") != "" {
+		return fmt.Errorf("the operators of crossed operations given '%v' should be a non-empty combination of '+', '-' and '*'", options.Operators)
+	}
+	if strings.Contains(options.Operators, "*") && options.Geq < 1 {
+		return errors.New("the operands of crossed operations with multiplications should be strictly positive")
+	}
+	// every row and column is an equation, but only 2*Size+1 of them are
+	// independent, so no more numbers can be derived
+	if options.NbMasked < 1 || options.NbMasked > 2*options.Size+1 {
+		return fmt.Errorf("the number of masked numbers of crossed operations should be in the range [1, %v]", 2*options.Size+1)
+	}
+	return nil
+}
+
+// return the crossed operations defined with these options
+func (options MathCrosswordOptions) mathCrossword() mathCrossword {
+	return mathCrossword{
+		size:      options.Size,
+		geq:       options.Geq,
+		leq:       options.Leq,
+		operators: options.Operators,
+		nbmasked:  options.NbMasked,
+	}
+}
+
+func (options MathCrosswordOptions) name() string {
+	return "MathCrossword"
+}
+
+func (options MathCrosswordOptions) generator() (generator, error) {
+	return options.mathCrossword(), nil
+}
+
+// -- MixedDrillOptions
+
+// return an error if the options of this mixed drill are not correct
+func (options MixedDrillOptions) Validate() error {
+
+	if len(options.Operations) == 0 {
+		return errors.New("at least one basic operation should be given in a mixed drill")
+	}
+	for _, operation := range options.Operations {
+		if err := operation.Validate(); err != nil {
+			return err
+		}
+	}
+	if options.Count <= 0 {
+		return fmt.Errorf("the number of problems of a mixed drill given '%v' should be strictly positive", options.Count)
+	}
+	if options.Cols <= 0 {
+		return fmt.Errorf("the number of columns of a mixed drill given '%v' should be strictly positive", options.Cols)
+	}
+	return nil
+}
+
+// return the mixed drill defined with these options
+func (options MixedDrillOptions) mixedDrill() mixedDrill {
+
+	var operations []basicOperation
+	for _, operation := range options.Operations {
+		operations = append(operations, operation.basicOperation())
+	}
+	return mixedDrill{
+		operations: operations,
+		count:      options.Count,
+		cols:       options.Cols,
+	}
+}
+
+func (options MixedDrillOptions) name() string {
+	return "MixedDrill"
+}
+
+func (options MixedDrillOptions) generator() (generator, error) {
+	return options.mixedDrill(), nil
+}
+
+// -- MoneyOptions
+
+// return an error if the options of this money problem are not correct
+func (options MoneyOptions) Validate() error {
+
+	if options.Type < MONEYTOTAL || options.Type > MONEYCHANGE {
+		return fmt.Errorf("the type of a money problem given '%v' is incorrect", options.Type)
+	}
+	if _, ok := currencies[options.Currency]; !ok {
+		return fmt.Errorf("the currency of a money problem given '%v' is incorrect. It should be one among 'EUR', 'USD' or 'GBP'", options.Currency)
+	}
+	if options.Geq < 0 || options.Leq < 1 || options.Geq > options.Leq {
+		return fmt.Errorf("the range of prices [%v, %v] of a money problem is incorrect", options.Geq, options.Leq)
+	}
+	if options.NbItems < 1 || options.NbItems > MAXMONEYITEMS {
+		return fmt.Errorf("the number of items of a money problem should be in the range [1, %v]", MAXMONEYITEMS)
+	}
+	return nil
+}
+
+// return the money problem defined with these options
+func (options MoneyOptions) money() money {
+	return money{
+		mtype:    options.Type,
+		currency: options.Currency,
+		geq:      options.Geq,
+		leq:      options.Leq,
+		decimals: options.Decimals,
+		nbitems:  options.NbItems,
+		coins:    options.Coins,
+	}
+}
+
+func (options MoneyOptions) name() string {
+	return "Money"
+}
+
+func (options MoneyOptions) generator() (generator, error) {
+	return options.money(), nil
+}
+
+// -- MultiplicationTableOptions
+
+// return an error if the options of this multiplication table are not correct
+func (options MultiplicationTableOptions) Validate() error {
+
+	if options.Type < MTRESULT || options.Type > MTOPERAND {
+		return fmt.Errorf("the type of a multiplication table given '%v' is incorrect", options.Type)
+	}
+	if options.Layout != "" && !helpers.Find(options.Layout, []string{MTBLOCK, MTTWOCOLUMN, MTGRID}) {
+		return fmt.Errorf("the layout of a multiplication table given '%v' should be either '%v', '%v' or '%v'",
+			options.Layout, MTBLOCK, MTTWOCOLUMN, MTGRID)
+	}
+	if options.Layout == MTGRID && options.Type != MTRESULT {
+		return errors.New("grids of facts can only be given with multiplication tables where the results have to be guessed")
+	}
+	if err := options.constraint().verify([]string{"factor"}, []int{options.NbDigits}); err != nil {
+		return err
+	}
+	if len(options.multiplicationTable().rows()) == 0 {
+		return fmt.Errorf("there are no numbers in the range [%v, %v] without the excluded digits %v", options.Geq, options.Leq, options.ExcludeDigits)
+	}
+	return nil
+}
+
+// return the multiplication table defined with these options
+func (options MultiplicationTableOptions) multiplicationTable() multiplicationTable {
+	return multiplicationTable{
+		mttype:     options.Type,
+		nbdigits:   options.NbDigits,
+		geq:        options.Geq,
+		leq:        options.Leq,
+		inv:        options.Inv,
+		sorted:     options.Sorted,
+		layout:     options.Layout,
+		constraint: options.constraint(),
+	}
+}
+
+// return the constraint on the digits of the factor of these options
+func (options MultiplicationTableOptions) constraint() digitConstraint {
+	return digitConstraint{excluded: options.ExcludeDigits, patterns: options.Patterns}
+}
+
+func (options MultiplicationTableOptions) name() string {
+	return "MultiplicationTable"
+}
+
+func (options MultiplicationTableOptions) generator() (generator, error) {
+	return options.multiplicationTable(), nil
+}
+
+// -- MysteryOperationOptions
+
+// return an error if the options of this mystery operation are not correct
+func (options MysteryOperationOptions) Validate() error {
+
+	if !helpers.Find(options.Operator, []string{"+", "-", "*", "/"}) {
+		return errors.New("The operator of a mystery operation has to be one and only one among the following: '+', '-', '*' or '/'")
+	}
+	if options.AllowNegative && options.Operator != "-" {
+		return errors.New("negative answers can only be allowed in subtractions")
+	}
+	return nil
+}
+
+// return the mystery operation defined with these options
+func (options MysteryOperationOptions) mysteryOperation() mysteryOperation {
+	return mysteryOperation{
+		nbdigits1:      options.NbDigits1,
+		nbdigits2:      options.NbDigits2,
+		nbmasked1:      options.NbMasked1,
+		nbmasked2:      options.NbMasked2,
+		nbdigitsanswer: options.NbDigitsAnswer,
+		nbmaskedanswer: options.NbMaskedAnswer,
+		operator:       options.Operator,
+		negative:       options.AllowNegative,
+	}
+}
+
+func (options MysteryOperationOptions) name() string {
+	return "MysteryOperation"
+}
+
+func (options MysteryOperationOptions) generator() (generator, error) {
+	return options.mysteryOperation(), nil
+}
+
+// -- NumberClassificationOptions
+
+// return an error if the options of this number classification are not
+// correct
+func (options NumberClassificationOptions) Validate() error {
+
+	if options.Type != CLASSEVENODD && options.Type != CLASSMULTIPLE {
+		return fmt.Errorf("the type of a number classification given '%v' is incorrect", options.Type)
+	}
+	if options.Geq < 0 || options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the numbers to classify should be non-empty and non-negative", options.Geq, options.Leq)
+	}
+	if options.NbNumbers < 1 || options.NbNumbers > MAXCLASSNUMBERS {
+		return fmt.Errorf("the number of numbers to classify given '%v' should be in the range [1, %v]", options.NbNumbers, MAXCLASSNUMBERS)
+	}
+	if options.Type == CLASSMULTIPLE && options.Multiple < 2 {
+		return fmt.Errorf("the multiple of a number classification given '%v' should be at least 2", options.Multiple)
+	}
+	if options.NbCols < 1 {
+		return fmt.Errorf("the number of columns of a number classification given '%v' should be strictly positive", options.NbCols)
+	}
+	return nil
+}
+
+// return the number classification defined with these options
+func (options NumberClassificationOptions) numberClassification() numberClassification {
+	return numberClassification{
+		cltype:    options.Type,
+		geq:       options.Geq,
+		leq:       options.Leq,
+		nbnumbers: options.NbNumbers,
+		multiple:  options.Multiple,
+		nbcols:    options.NbCols,
+	}
+}
+
+func (options NumberClassificationOptions) name() string {
+	return "NumberClassification"
+}
+
+func (options NumberClassificationOptions) generator() (generator, error) {
+	return options.numberClassification(), nil
+}
+
+// -- NumberComparisonOptions
+
+// return an error if the options of this number comparison are not correct
+func (options NumberComparisonOptions) Validate() error {
+
+	if options.NbDigits < 1 || options.NbDigits > 9 {
+		return errors.New("the number of digits of a number comparison should be in the range [1, 9]")
+	}
+	if options.NbItems < 2 || options.NbItems > MAXCOMPARISONITEMS {
+		return fmt.Errorf("the number of items of a number comparison should be in the range [2, %v]", MAXCOMPARISONITEMS)
+	}
+	return nil
+}
+
+// return the number comparison defined with these options
+func (options NumberComparisonOptions) numberComparison() numberComparison {
+	return numberComparison{
+		nbdigits: options.NbDigits,
+		nbitems:  options.NbItems,
+		negative: options.Negative,
+	}
+}
+
+func (options NumberComparisonOptions) name() string {
+	return "NumberComparison"
+}
+
+func (options NumberComparisonOptions) generator() (generator, error) {
+	return options.numberComparison(), nil
+}
+
+// -- NumberFormOptions
+
+// return an error if the options of this conversion between forms of numbers
+// are not correct
+func (options NumberFormOptions) Validate() error {
+
+	forms := []string{NFSTANDARD, NFEXPANDED, NFWORDS}
+	if !helpers.Find(options.From, forms) {
+		return errors.New("the form of the number to convert has to be one and only one among the following: 'standard', 'expanded' or 'words'")
+	}
+	if !helpers.Find(options.To, forms) {
+		return errors.New("the form of the converted number has to be one and only one among the following: 'standard', 'expanded' or 'words'")
+	}
+	if options.From == options.To {
+		return fmt.Errorf("a number in %v form can not be converted into %v form", options.From, options.To)
+	}
+	if options.NbDigits < 1 || options.NbDigits > helpers.NbDigits(MAXWORDSNUMBER) {
+		return fmt.Errorf("the number of digits of the number to convert given '%v' should be in the range [1, %v]",
+			options.NbDigits, helpers.NbDigits(MAXWORDSNUMBER))
+	}
+	if _, ok := spellers[options.Locale]; !ok {
+		return fmt.Errorf("the language for writing numbers in words given '%v' is incorrect. It should be either 'en' or 'es'", options.Locale)
+	}
+	return nil
+}
+
+// return the conversion between forms of numbers defined with these options
+func (options NumberFormOptions) numberForm() numberForm {
+	return numberForm{
+		from:     options.From,
+		to:       options.To,
+		nbdigits: options.NbDigits,
+		locale:   options.Locale,
+	}
+}
+
+func (options NumberFormOptions) name() string {
+	return "NumberForm"
+}
+
+func (options NumberFormOptions) generator() (generator, error) {
+	return options.numberForm(), nil
+}
+
+// -- NumberLineOptions
+
+// return an error if the options of this number line are not correct
+func (options NumberLineOptions) Validate() error {
+
+	if options.Type < NLFILL || options.Type > NLMARK {
+		return fmt.Errorf("the type of a number line given '%v' is incorrect", options.Type)
+	}
+	if options.Step < 1 {
+		return errors.New("the step of a number line should be given as a positive integer")
+	}
+	if options.Leq-options.Geq < options.Step {
+		return fmt.Errorf("the range [%v, %v] of a number line should contain at least two ticks with step %v",
+			options.Geq, options.Leq, options.Step)
+	}
+	nbticks := 1 + (options.Leq-options.Geq)/options.Step
+	if options.NbTicks != 0 && (options.NbTicks < 2 || options.NbTicks > nbticks) {
+		return fmt.Errorf("the number of ticks of a number line should be given as an integer between 2 and %v", nbticks)
+	}
+	if options.NbTicks != 0 {
+		nbticks = options.NbTicks
+	}
+	for _, index := range options.Hidden {
+		if index < 0 || index >= nbticks {
+			return fmt.Errorf("the hidden tick '%v' should be an integer between 0 and %v", index, nbticks-1)
+		}
+	}
+	return nil
+}
+
+// return the number line defined with these options
+func (options NumberLineOptions) numberLine() numberLine {
+
+	nbticks := options.NbTicks
+	if nbticks == 0 {
+		nbticks = 1 + (options.Leq-options.Geq)/options.Step
+	}
+	return numberLine{
+		nltype:   options.Type,
+		geq:      options.Geq,
+		leq:      options.Leq,
+		step:     options.Step,
+		nbticks:  nbticks,
+		hidden:   options.Hidden,
+		vertical: options.Vertical,
+	}
+}
+
+func (options NumberLineOptions) name() string {
+	return "NumberLine"
+}
+
+func (options NumberLineOptions) generator() (generator, error) {
+	return options.numberLine(), nil
+}
+
+// -- NumberPyramidOptions
+
+// return an error if the options of this number pyramid are not correct
+func (options NumberPyramidOptions) Validate() error {
+
+	if options.Height < 2 || options.Height > MAXPYRAMIDHEIGHT {
+		return fmt.Errorf("the height of a number pyramid should be in the range [2, %v]", MAXPYRAMIDHEIGHT)
+	}
+	if options.Geq < 0 || options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the base of a number pyramid should be non-empty and non-negative", options.Geq, options.Leq)
+	}
+	if options.Reveal != PYRBASE && options.Reveal != PYRRANDOM {
+		return fmt.Errorf("the cells revealed in a number pyramid should be either '%v' or '%v'", PYRBASE, PYRRANDOM)
+	}
+
+	// at least as many cells as in the base are necessary for completing the
+	// pyramid
+	nbcells := options.Height * (options.Height + 1) / 2
+	if options.Reveal == PYRRANDOM && (options.NbRevealed < options.Height || options.NbRevealed > nbcells) {
+		return fmt.Errorf("the number of revealed cells of a number pyramid should be in the range [%v, %v]", options.Height, nbcells)
+	}
+	return nil
+}
+
+// return the number pyramid defined with these options
+func (options NumberPyramidOptions) numberPyramid() numberPyramid {
+	return numberPyramid{
+		height:     options.Height,
+		geq:        options.Geq,
+		leq:        options.Leq,
+		reveal:     options.Reveal,
+		nbrevealed: options.NbRevealed,
+	}
+}
+
+func (options NumberPyramidOptions) name() string {
+	return "NumberPyramid"
+}
+
+func (options NumberPyramidOptions) generator() (generator, error) {
+	return options.numberPyramid(), nil
+}
+
+// -- PercentageOptions
+
+// return an error if the options of this percentage are not correct
+func (options PercentageOptions) Validate() error {
+
+	if options.Type < PCRESULT || options.Type > PCBASE {
+		return fmt.Errorf("the type of a percentage given '%v' is incorrect", options.Type)
+	}
+	if options.Geq < 1 || options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the base of a percentage should be non-empty and strictly positive", options.Geq, options.Leq)
+	}
+	if options.PctGeq < 1 || options.PctGeq > options.PctLeq {
+		return fmt.Errorf("the range [%v, %v] of the percentage should be non-empty and strictly positive", options.PctGeq, options.PctLeq)
+	}
+	if options.Decimals < 0 || options.Decimals > MAXPERCENTAGEDECIMALS {
+		return fmt.Errorf("the number of decimals of a percentage given '%v' should be in the range [0, %v]", options.Decimals, MAXPERCENTAGEDECIMALS)
+	}
+	return nil
+}
+
+// return the percentage defined with these options
+func (options PercentageOptions) percentage() percentage {
+	return percentage{
+		pctype:   options.Type,
+		geq:      options.Geq,
+		leq:      options.Leq,
+		pctgeq:   options.PctGeq,
+		pctleq:   options.PctLeq,
+		decimals: options.Decimals,
+	}
+}
+
+func (options PercentageOptions) name() string {
+	return "Percentage"
+}
+
+func (options PercentageOptions) generator() (generator, error) {
+	return options.percentage(), nil
+}
+
+// -- PerimeterAreaOptions
+
+// return an error if the options of this perimeter and area problem are not
+// correct
+func (options PerimeterAreaOptions) Validate() error {
+
+	if options.Type < PAPERIMETER || options.Type > PASIDE {
+		return fmt.Errorf("the type of a perimeter and area problem given '%v' is incorrect", options.Type)
+	}
+	if options.Shape != PARECTANGLE && options.Shape != PATRIANGLE {
+		return fmt.Errorf("the shape of a perimeter and area problem given '%v' should be either '%v' or '%v'",
+			options.Shape, PARECTANGLE, PATRIANGLE)
+	}
+	if options.Geq < 1 || options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the lengths of a perimeter and area problem should be non-empty and strictly positive", options.Geq, options.Leq)
+	}
+	return nil
+}
+
+// return the perimeter and area problem defined with these options
+func (options PerimeterAreaOptions) perimeterArea() perimeterArea {
+	return perimeterArea{
+		patype: options.Type,
+		shape:  options.Shape,
+		geq:    options.Geq,
+		leq:    options.Leq,
+	}
+}
+
+func (options PerimeterAreaOptions) name() string {
+	return "PerimeterArea"
+}
+
+func (options PerimeterAreaOptions) generator() (generator, error) {
+	return options.perimeterArea(), nil
+}
+
+// -- PictogramOptions
+
+// return an error if the options of this pictogram are not correct
+func (options PictogramOptions) Validate() error {
+
+	if len(options.Categories) < 2 {
+		return errors.New("a pictogram should have at least two categories")
+	}
+	for idx, category := range options.Categories {
+		if category == "" {
+			return errors.New("the names of the categories of a pictogram can not be empty")
+		}
+		if helpers.Find(category, options.Categories[:idx]) {
+			return fmt.Errorf("the category '%v' of a pictogram is given more than once", category)
+		}
+	}
+	if options.Geq < 0 || options.Geq > options.Leq || options.Leq > MAXPICTOGRAMVALUE {
+		return fmt.Errorf("the range [%v, %v] of the values of a pictogram should be non-empty and within [0, %v]",
+			options.Geq, options.Leq, MAXPICTOGRAMVALUE)
+	}
+	if options.Chart != PICTALLY && options.Chart != PICPICTOGRAM {
+		return fmt.Errorf("the chart of a pictogram given '%v' should be either '%v' or '%v'", options.Chart, PICTALLY, PICPICTOGRAM)
+	}
+	if !helpers.Find(options.Symbol, []string{components.SYMBOLSTAR, components.SYMBOLCIRCLE, components.SYMBOLAPPLE}) {
+		return fmt.Errorf("the symbol of a pictogram given '%v' should be either '%v', '%v' or '%v'",
+			options.Symbol, components.SYMBOLSTAR, components.SYMBOLCIRCLE, components.SYMBOLAPPLE)
+	}
+	if len(options.Questions) == 0 {
+		return errors.New("at least one question should be asked in a pictogram")
+	}
+	for _, question := range options.Questions {
+		if !helpers.Find(question, []string{PICMOST, PICLEAST, PICTOTAL}) {
+			return fmt.Errorf("the question of a pictogram given '%v' should be either '%v', '%v' or '%v'",
+				question, PICMOST, PICLEAST, PICTOTAL)
+		}
+	}
+	return nil
+}
+
+// return the pictogram defined with these options
+func (options PictogramOptions) pictogram() pictogram {
+	return pictogram{
+		categories: options.Categories,
+		geq:        options.Geq,
+		leq:        options.Leq,
+		chart:      options.Chart,
+		symbol:     options.Symbol,
+		questions:  options.Questions,
+	}
+}
+
+func (options PictogramOptions) name() string {
+	return "Pictogram"
+}
+
+func (options PictogramOptions) generator() (generator, error) {
+	return options.pictogram(), nil
+}
+
+// -- PlaceValueOptions
+
+// return an error if the options of this place value problem are not correct
+func (options PlaceValueOptions) Validate() error {
+
+	if options.Type < PVDECOMPOSE || options.Type > PVCOMPOSE {
+		return fmt.Errorf("the type of a place value problem given '%v' is incorrect", options.Type)
+	}
+	if options.NbDigits < 1 || options.NbDigits > len(placeNames) {
+		return fmt.Errorf("the number of digits of a place value problem should be in the range [1, %v]", len(placeNames))
+	}
+
+	// the masked places are only relevant when decomposing numbers
+	if options.Type == PVCOMPOSE {
+		return nil
+	}
+	if len(options.Masked) > 0 {
+		if options.NbMasked != 0 {
+			return errors.New("the masked places of a place value problem can not be given along with the number of masked places")
+		}
+		for _, name := range options.Masked {
+			if !helpers.Find(name, placeNames[:options.NbDigits]) {
+				return fmt.Errorf("the place '%v' does not exist in numbers with %v digits", name, options.NbDigits)
+			}
+		}
+		return nil
+	}
+	if options.NbMasked < 1 || options.NbMasked > options.NbDigits {
+		return fmt.Errorf("the number of masked places of a place value problem should be in the range [1, %v]", options.NbDigits)
+	}
+	return nil
+}
+
+// return the place value problem defined with these options
+func (options PlaceValueOptions) placeValue() placeValue {
+	return placeValue{
+		pvtype:   options.Type,
+		nbdigits: options.NbDigits,
+		nbmasked: options.NbMasked,
+		masked:   options.Masked,
+	}
+}
+
+func (options PlaceValueOptions) name() string {
+	return "PlaceValue"
+}
+
+func (options PlaceValueOptions) generator() (generator, error) {
+	return options.placeValue(), nil
+}
+
+// -- PrimeFactorizationOptions
+
+// return an error if the options of this prime factorization are not correct
+func (options PrimeFactorizationOptions) Validate() error {
+
+	if options.Geq < 4 {
+		return fmt.Errorf("the lower bound of a prime factorization given '%v' should be at least 4, the smallest composite number", options.Geq)
+	}
+	if options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of a prime factorization is empty", options.Geq, options.Leq)
+	}
+	if options.MaxFactors < 2 {
+		return fmt.Errorf("the maximum number of prime factors given '%v' should be at least 2", options.MaxFactors)
+	}
+	return nil
+}
+
+// return the prime factorization defined with these options
+func (options PrimeFactorizationOptions) primeFactorization() primeFactorization {
+	return primeFactorization{
+		geq:        options.Geq,
+		leq:        options.Leq,
+		maxfactors: options.MaxFactors,
+	}
+}
+
+func (options PrimeFactorizationOptions) name() string {
+	return "PrimeFactorization"
+}
+
+func (options PrimeFactorizationOptions) generator() (generator, error) {
+	return options.primeFactorization(), nil
+}
+
+// -- RatioOptions
+
+// return an error if the options of this ratio are not correct
+func (options RatioOptions) Validate() error {
+
+	if options.Type < RTSECOND || options.Type > RTFIRST {
+		return fmt.Errorf("the type of a ratio given '%v' is incorrect", options.Type)
+	}
+	return nil
+}
+
+// return the ratio defined with these options
+func (options RatioOptions) ratio() ratio {
+	return ratio{
+		rttype:   options.Type,
+		geq:      options.Geq,
+		leq:      options.Leq,
+		scalegeq: options.ScaleGeq,
+		scaleleq: options.ScaleLeq,
+	}
+}
+
+func (options RatioOptions) name() string {
+	return "Ratio"
+}
+
+func (options RatioOptions) generator() (generator, error) {
+	return options.ratio(), nil
+}
+
+// -- RoundingOptions
+
+// return an error if the options of this rounding problem are not correct
+func (options RoundingOptions) Validate() error {
+
+	place := roundingPlace(options.Place)
+	if place == 0 {
+		return fmt.Errorf("the place of a rounding problem given '%v' is incorrect", options.Place)
+	}
+	if options.NbDigits <= place || options.NbDigits > 9 {
+		return fmt.Errorf("numbers rounded to the nearest %v should have between %v and 9 digits", options.Place, place+1)
+	}
+	if options.Tiebreak != "" && !helpers.Find(options.Tiebreak, []string{ROUNDUP, ROUNDEVEN, ROUNDAWAY}) {
+		return fmt.Errorf("the tiebreak of a rounding problem given '%v' is incorrect. It should be one and only one among the following: '%v', '%v' or '%v'",
+			options.Tiebreak, ROUNDUP, ROUNDEVEN, ROUNDAWAY)
+	}
+	return nil
+}
+
+// return the rounding problem defined with these options
+func (options RoundingOptions) rounding() rounding {
+	tiebreak := options.Tiebreak
+	if tiebreak == "" {
+		tiebreak = ROUNDUP
+	}
+	return rounding{
+		nbdigits:  options.NbDigits,
+		place:     options.Place,
+		tiebreak:  tiebreak,
+		highlight: options.Highlight,
+	}
+}
+
+func (options RoundingOptions) name() string {
+	return "Rounding"
+}
+
+func (options RoundingOptions) generator() (generator, error) {
+	return options.rounding(), nil
+}
+
+// -- SequenceOptions
+
+// return an error if the options of this sequence are not correct
+func (options SequenceOptions) Validate() error {
+
+	if options.Type < SEQNONE || options.Type > SEQBOTH {
+		return fmt.Errorf("the type of a sequence given '%v' is incorrect", options.Type)
+	}
+	switch options.Pattern {
+	case "", SEQARITHMETIC:
+	case SEQGEOMETRIC:
+		if options.Step < 2 {
+			return fmt.Errorf("the ratio of a geometric sequence should be at least 2 but %v was given", options.Step)
+		}
+	case SEQALTERNATING:
+		if options.AltStep == 0 {
+			return errors.New("the alternate step of an alternating sequence should be a non-zero integer")
+		}
+	case SEQFIBONACCI:
+		if options.NbItems < 3 {
+			return fmt.Errorf("a Fibonacci-like sequence should have at least 3 items but %v were given", options.NbItems)
+		}
+	default:
+		return fmt.Errorf("the pattern of a sequence given '%v' is incorrect", options.Pattern)
+	}
+
+	// verify also the items to hide, if any is given
+	if len(options.Mask) > 0 {
+		if options.NbMasked != 0 {
+			return errors.New("the masked items of a sequence can not be given along with the number of masked items")
+		}
+		for _, idx := range options.Mask {
+			if idx < 0 || idx >= options.NbItems {
+				return fmt.Errorf("the masked item %v does not exist in a sequence with %v items", idx, options.NbItems)
+			}
+		}
+	}
+	if options.NbMasked < 0 || options.NbMasked > options.NbItems {
+		return fmt.Errorf("the number of masked items of a sequence should be in the range [0, %v]", options.NbItems)
+	}
+	return nil
+}
+
+// return the sequence defined with these options
+func (options SequenceOptions) sequence() sequence {
+	return sequence{
+		seqtype:       options.Type,
+		nbitems:       options.NbItems,
+		geq:           options.Geq,
+		leq:           options.Leq,
+		pattern:       options.Pattern,
+		step:          options.Step,
+		altstep:       options.AltStep,
+		startmultiple: options.StartMultiple,
+		mask:          options.Mask,
+		nbmasked:      options.NbMasked,
+	}
+}
+
+func (options SequenceOptions) name() string {
+	return "Sequence"
+}
+
+func (options SequenceOptions) generator() (generator, error) {
+	return options.sequence(), nil
+}
+
+// -- ShadedFractionOptions
+
+// return an error if the options of this shaded fraction are not correct
+func (options ShadedFractionOptions) Validate() error {
+
+	if options.Type < SFREAD || options.Type > SFSHADE {
+		return fmt.Errorf("the type of a shaded fraction given '%v' is incorrect", options.Type)
+	}
+	if options.Shape != components.FRACTIONPIE && options.Shape != components.FRACTIONBAR {
+		return fmt.Errorf("the shape of a shaded fraction given '%v' should be either '%v' or '%v'",
+			options.Shape, components.FRACTIONPIE, components.FRACTIONBAR)
+	}
+	if options.DenGeq < 2 || options.DenGeq > options.DenLeq {
+		return fmt.Errorf("the range [%v, %v] of the denominator of a shaded fraction should be non-empty and start at 2 at least", options.DenGeq, options.DenLeq)
+	}
+	return nil
+}
+
+// return the shaded fraction defined with these options
+func (options ShadedFractionOptions) shadedFraction() shadedFraction {
+	return shadedFraction{
+		sftype:     options.Type,
+		shape:      options.Shape,
+		dengeq:     options.DenGeq,
+		denleq:     options.DenLeq,
+		contiguous: options.Contiguous,
+	}
+}
+
+func (options ShadedFractionOptions) name() string {
+	return "ShadedFraction"
+}
+
+func (options ShadedFractionOptions) generator() (generator, error) {
+	return options.shadedFraction(), nil
+}
+
+// -- UnitConversionOptions
+
+// return an error if the options of this unit conversion are not correct
+func (options UnitConversionOptions) Validate() error {
+
+	if _, ok := units[options.Quantity]; !ok {
+		return fmt.Errorf("the quantity of a unit conversion given '%v' is incorrect. It should be one among 'length', 'mass' or 'capacity'", options.Quantity)
+	}
+	if options.Direction != UCDOWN && options.Direction != UCUP && options.Direction != UCBOTH {
+		return fmt.Errorf("the direction of a unit conversion given '%v' is incorrect. It should be one among 'down', 'up' or 'both'", options.Direction)
+	}
+	if options.Geq < 0 || options.Geq > options.Leq {
+		return fmt.Errorf("the range of values [%v, %v] of a unit conversion is incorrect", options.Geq, options.Leq)
+	}
+	if options.Decimals < 0 || options.Decimals > MAXUCDECIMALS {
+		return fmt.Errorf("the number of decimals of a unit conversion should be in the range [0, %v]", MAXUCDECIMALS)
+	}
+	return nil
+}
+
+// return the unit conversion defined with these options
+func (options UnitConversionOptions) unitConversion() unitConversion {
+	return unitConversion{
+		quantity:  options.Quantity,
+		direction: options.Direction,
+		geq:       options.Geq,
+		leq:       options.Leq,
+		decimals:  options.Decimals,
+	}
+}
+
+func (options UnitConversionOptions) name() string {
+	return "UnitConversion"
+}
+
+func (options UnitConversionOptions) generator() (generator, error) {
+	return options.unitConversion(), nil
+}
+
+// -- WordProblemOptions
+
+// return an error if the options of this word problem are not correct. Note
+// that the bank of stories, if any, is read only when the word problem is
+// created
+func (options WordProblemOptions) Validate() error {
+
+	if !helpers.Find(options.Operator, []string{"+", "-", "*", "/"}) {
+		return errors.New("The operator of a word problem has to be one and only one among the following: '+', '-', '*' or '/'")
+	}
+	if options.Geq < 0 || options.Geq > options.Leq {
+		return fmt.Errorf("the range [%v, %v] of the operands should be non-empty and non-negative", options.Geq, options.Leq)
+	}
+	return nil
+}
+
+// return the word problem defined with these options. If the bank of stories
+// can not be read, an error is returned
+func (options WordProblemOptions) wordProblem() (wordProblem, error) {
+
+	bank := currentLocale().stories
+	if options.Bank != "" {
+		var err error
+		if bank, err = loadStoryBank(options.Bank); err != nil {
+			return wordProblem{}, err
+		}
+	}
+	return wordProblem{
+		operator: options.Operator,
+		geq:      options.Geq,
+		leq:      options.Leq,
+		bank:     bank,
+	}, nil
+}
+
+func (options WordProblemOptions) name() string {
+	return "WordProblem"
+}
+
+func (options WordProblemOptions) generator() (generator, error) {
+	return options.wordProblem()
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
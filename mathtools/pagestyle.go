@@ -0,0 +1,140 @@
+// -*- coding: utf-8 -*-
+// pagestyle.go
+//
+// Description: Provides services for decorating every page of the sheets
+// generated from master files with a header and a footer
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 03:48:31.620413857 (1792122511)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// The page style is defined with the low-level commands of LaTeX so that it
+// does not need any additional package and it can be used with any document
+// class, including exam. Once selected, the header and footer are shown in
+// every page, so that they are repeated whenever the contents of the sheet are
+// broken across pages, either by LaTeX or by a grid of problems
+const latexPageStyleCode = `\makeatletter
+\def\ps@mathprob{%
+    \def\@oddhead{\rlap{ {{- .GetLeftHeader -}} }\hfill {{- .GetCenterHeader -}} \hfill\llap{ {{- .GetRightHeader -}} }}%
+    \let\@evenhead\@oddhead
+    \def\@oddfoot{\rlap{ {{- .GetLeftFooter -}} }\hfill {{- .GetCenterFooter -}} \hfill}%
+    \let\@evenfoot\@oddfoot}
+\makeatother
+\pagestyle{mathprob}
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// The header of every page shows, from left to right, the student's name and
+// class, the title of the sheet and the date, followed by a logo given as the
+// name of an image file. The footer shows a box for writing down the score out
+// of the given number of points, and the page number. Any of them can be
+// omitted: the title and logo when they are empty, the score box if the score
+// is zero, and the rest with their own flags
+type pageStyle struct {
+	name, class string
+	title, logo string
+	student     bool
+	date        bool
+	score       int
+	pagenumbers bool
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- pageStyle
+
+// Return the left part of the header with the student's name and class. If no
+// name or class is known, then a line is left for writing them down
+func (ps pageStyle) GetLeftHeader() string {
+
+	if !ps.student {
+		return ""
+	}
+	field := func(value string) string {
+		if value == "" {
+			return `\rule{10em}{0.4pt}`
+		}
+		return latexEscape(value)
+	}
+	return fmt.Sprintf(`%v\quad %v`, field(ps.name), field(ps.class))
+}
+
+// Return the center of the header with the title of the sheet, if any
+func (ps pageStyle) GetCenterHeader() string {
+
+	if ps.title == "" {
+		return ""
+	}
+	return fmt.Sprintf(`\textbf{%v}`, latexEscape(ps.title))
+}
+
+// Return the right part of the header with the date and the logo, if any.
+// Logos are scaled to the height of the header and they require the package
+// graphicx
+func (ps pageStyle) GetRightHeader() string {
+
+	var items []string
+	if ps.date {
+		items = append(items, `\today`)
+	}
+	if ps.logo != "" {
+		items = append(items, fmt.Sprintf(`\raisebox{-0.2\height}{\includegraphics[height=\headheight]{%v}}`, ps.logo))
+	}
+	return strings.Join(items, `\quad `)
+}
+
+// Return the left part of the footer with a box for the score, if any
+func (ps pageStyle) GetLeftFooter() string {
+
+	if ps.score <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(`\framebox[3em]{\strut}\,/\,%v`, ps.score)
+}
+
+// Return the center of the footer with the page number, if requested
+func (ps pageStyle) GetCenterFooter() string {
+
+	if !ps.pagenumbers {
+		return ""
+	}
+	return `\thepage`
+}
+
+// Return the LaTeX code that selects this page style from the current page on
+func (ps pageStyle) execute() (string, error) {
+
+	// create a template with the LaTeX code of the page style
+	tmpl, err := template.New("pageStyle").Parse(latexPageStyleCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and execute the template with the information in the receiver
+	var tplOutput bytes.Buffer
+	if err := tmpl.Execute(&tplOutput, ps); err != nil {
+		return "", err
+	}
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
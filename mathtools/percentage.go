@@ -0,0 +1,348 @@
+// -*- coding: utf-8 -*-
+// percentage.go
+//
+// Description: Provides services for automatically creating problems with
+// percentages of quantities
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:42:40.868137359 (1792114960)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are three different types of percentages: "result", "percent" or
+// "base". In the first case, the result of taking the percentage of the base
+// has to be guessed by the student; in the second one, the percentage has to
+// be guessed instead; finally, in the last one it is the base which has to be
+// guessed
+const (
+	PCRESULT int = iota
+	PCPERCENT
+	PCBASE
+)
+
+// By default, percentages are taken in the following range
+const (
+	DEFAULTPCTGEQ int = 1
+	DEFAULTPCTLEQ int = 100
+)
+
+// Results of percentages can not have more decimals than the following value
+const MAXPERCENTAGEDECIMALS int = 2
+
+// The text between the percentage and the base is as wide as the following
+// number of digits
+const PCOFLENGTH float64 = 3.0
+
+// the TikZ code for generating percentages is shown next. Note that it makes
+// use of LaTeX/TikZ components
+const latexPercentageCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the percentage
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZPercentageCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Percentage ------------------------------------------------------
+
+      % the percentage is shown in the form p % of N = R, each item written to
+      % the right of the previous one. One of the numbers is shown within an
+      % empty box
+      {{.Percent}}
+      {{.Of}}
+      {{.Base}}
+      {{.Equal}}
+      {{.Result}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A percentage consists of a percentage randomly chosen in the interval
+// [pctgeq, pctleq] which is taken of a base randomly chosen in the interval
+// [geq, leq]. The result has no more decimals than the given number, and it is
+// always written with them. There are three types of percentages:
+//
+//    0: the result has to be guessed by the student
+//    1: the percentage has to be guessed instead
+//    2: the base has to be guessed
+type percentage struct {
+	pctype         int
+	geq, leq       int
+	pctgeq, pctleq int
+	decimals       int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw
+// percentages
+type percentageTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the percentage is followed by the base and the result separated by the
+	// equal symbol
+	Percent, Of, Base, Equal, Result components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- percentageTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz percentageTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("percentageTikZ").Parse(tikZPercentageCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- percentage
+
+// return the instance of a specific percentage problem that can be marshalled
+// in JSON format. The receiver is assumed to have been fully verified so that
+// it should be consistent.
+//
+// The result is given with three items: the percentage, the base and the
+// result, which is written with the requested number of decimals. One of them
+// is shown as "?" in the arguments as it has to be guessed by the student
+func (pc percentage) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if pc.geq < 1 || pc.geq > pc.leq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate the base of a percentage in the range [%v, %v]",
+			pc.geq, pc.leq)
+	}
+	if pc.pctgeq < 1 || pc.pctgeq > pc.pctleq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate percentages in the range [%v, %v]",
+			pc.pctgeq, pc.pctleq)
+	}
+
+	// randomly determine the percentage and the base until the result has no
+	// more decimals than requested. If none is found after a maximum number of
+	// attempts, the parameters are deemed to be incompatible
+	scale := 1
+	for i := 0; i < pc.decimals; i++ {
+		scale *= 10
+	}
+	var percent, base int
+	for attempt := 0; ; attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate a percentage in the range [%v, %v] of a base in the range [%v, %v] with %v decimals after %v attempts",
+				pc.pctgeq, pc.pctleq, pc.geq, pc.leq, pc.decimals, MAXGENERATIONATTEMPTS)
+		}
+		percent = pc.pctgeq + rnd.Intn(1+pc.pctleq-pc.pctgeq)
+		base = pc.geq + rnd.Intn(1+pc.leq-pc.geq)
+		if (percent*base*scale)%100 == 0 {
+			break
+		}
+	}
+
+	// create two slices: one for storing the instance of this problem in the
+	// order: percentage, base and result, where the part that should be filled
+	// in by the student is marked with a question mark "?"; and another one
+	// with the full solution
+	solution := []string{
+		strconv.FormatInt(int64(percent), 10),
+		strconv.FormatInt(int64(base), 10),
+		formatDecimal(percent*base*scale/100, pc.decimals),
+	}
+	args := make([]string, 3)
+	copy(args, solution)
+
+	// and now mask the requested number
+	switch pc.pctype {
+	case PCRESULT:
+		args[2] = "?"
+	case PCPERCENT:
+		args[0] = "?"
+	case PCBASE:
+		args[1] = "?"
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "Percentage",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this percentage using TikZ
+// components
+func (pc percentage) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the percentage, base and result. For
+	//              this, the service that generates problems is the one that
+	//              can marshal them into JSON format. A question mark is a
+	//              number that has to be guessed by the student
+	instance, err := pc.next(annotated(pc))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid percentage: %v", err)
+	}
+
+	// compute the number of characters required to draw all numbers so that
+	// all of them are equally wide
+	nbdigits := 0.0
+	for _, item := range instance.Solution {
+		nbdigits = helpers.Max(nbdigits, float64(len(item)))
+	}
+
+	// all numbers are shown within the same width which consists of the number
+	// of digits plus one additional digit to each side
+	width := 2.0 + nbdigits
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// the following function returns the text to show in the cell of the i-th
+	// argument: either an empty box, if the number has to be guessed, or the
+	// number itself
+	cell := func(label, formula string, i int) components.CoordinatedText {
+		options, text := "", ""
+		if instance.Args[i] == "?" {
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width))
+			text = pc.answer(localizeDecimal(instance.Solution[i]))
+		} else {
+			text = `\huge ` + localizeDecimal(instance.Args[i])
+		}
+		return components.NewCoordinatedText(
+			components.NewCoordinate(components.Formula(formula), label),
+			options, text)
+	}
+
+	// every item is located to the right of the given reference, separated by
+	// half the width of a digit. The length of items other than numbers is
+	// given in digits
+	after := func(reference string, length float64) string {
+		return fmt.Sprintf(`$(%v) + (%v\zerowidth, 0.0)$`,
+			reference, helpers.Ftoa(0.5+length/2.0+width/2.0))
+	}
+	symbol := func(label, reference string, length float64, text string) components.CoordinatedText {
+		return components.NewCoordinatedText(
+			components.NewCoordinate(components.Formula(after(reference, length)), label),
+			"", text)
+	}
+
+	// -- percentage
+	percent := cell("percent",
+		fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip)$`,
+			helpers.Ftoa(width/2.0)),
+		0)
+	of := symbol("of", "percent", PCOFLENGTH, `\huge \% of`)
+
+	// -- base
+	base := cell("base", after("of", PCOFLENGTH), 1)
+
+	// -- equal
+	equal := symbol("equal", "base", 1.0, `\huge $=$`)
+
+	// -- result
+	result := cell("result", after("equal", 1.0), 2)
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(result) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip)$`,
+			helpers.Ftoa(0.5+width/2.0))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// percentage
+	pcPicture := percentageTikZ{
+		Bottom:  bottom,
+		Percent: percent,
+		Of:      of,
+		Base:    base,
+		Equal:   equal,
+		Result:  result,
+		BBox:    bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return pcPicture.execute()
+}
+
+// Return TikZ code that represents a percentage
+func (pc percentage) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this percentage
+	tpl, err := template.New("percentage").Parse(latexPercentageCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, pc); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
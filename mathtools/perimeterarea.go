@@ -0,0 +1,509 @@
+// -*- coding: utf-8 -*-
+// perimeterarea.go
+//
+// Description: Provides services for automatically creating problems with the
+// perimeter and area of rectangles and triangles
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:44:15.463880777 (1792115055)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are three different types of perimeter and area problems: "perimeter",
+// "area" or "side". In the first case, the perimeter of the shape has to be
+// guessed by the student; in the second one, its area; finally, in the last
+// one the area is given and one side (or the height of triangles) has to be
+// guessed
+const (
+	PAPERIMETER int = iota
+	PAAREA
+	PASIDE
+)
+
+// Shapes are either rectangles or triangles
+const (
+	PARECTANGLE string = "rectangle"
+	PATRIANGLE  string = "triangle"
+)
+
+// Shapes are scaled so that their largest dimension is drawn with the
+// following length in centimeters
+const PASHAPESIZE float64 = 4.0
+
+// the TikZ code for generating perimeter and area problems is shown next. Note
+// that it makes use of LaTeX/TikZ components
+const latexPerimeterAreaCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the perimeter or area problem
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZPerimeterAreaCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box and vertices of the shape
+      {{.Bottom}}
+{{.GetVertices}}
+      % --- Shape -----------------------------------------------------------
+
+      % the shape is drawn with thick lines and the height of triangles, if
+      % any, with dashed lines
+      {{.Shape}}
+{{.GetLines}}
+      % --- Labels ----------------------------------------------------------
+
+      % the lengths are written next to the sides. An unknown length is
+      % shown within an empty box
+{{.GetLabels}}
+      % --- Question --------------------------------------------------------
+
+      % the question is shown below the shape
+      {{.Question}}
+      {{.Answer}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A perimeter and area problem consists of a shape, either a rectangle or a
+// triangle, whose lengths are randomly chosen in the interval [geq, leq].
+// Rectangles are given by their width and height. Triangles are given by their
+// three sides when the perimeter is requested, and by their base and height
+// otherwise. There are three types of problems:
+//
+//    0: the perimeter has to be guessed by the student
+//    1: the area has to be guessed by the student
+//    2: the area is given and the height has to be guessed instead
+type perimeterArea struct {
+	patype   int
+	shape    string
+	geq, leq int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw perimeter
+// and area problems
+type perimeterAreaTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the vertices of the shape
+	vertices []components.Coordinate
+
+	// the shape is either a rectangle or a closed line, and triangles might
+	// have additional lines for showing their height
+	Shape fmt.Stringer
+	lines []components.Line
+
+	// the lengths written next to the sides
+	labels []components.CoordinatedText
+
+	// the question consists of the magnitude requested and its value
+	Question, Answer components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- perimeterAreaTikZ
+
+// Generates the TikZ code necessary for defining all vertices of the shape
+func (tikz perimeterAreaTikZ) GetVertices() string {
+
+	// Use a btyes buffer to append the strings of each vertex
+	var output bytes.Buffer
+
+	for _, vertex := range tikz.vertices {
+		fmt.Fprintf(&output, "      %v\n", vertex)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for defining
+	// all vertices
+	return output.String()
+}
+
+// Generates the TikZ code necessary for drawing all additional lines
+func (tikz perimeterAreaTikZ) GetLines() string {
+
+	// Use a btyes buffer to append the strings of each line
+	var output bytes.Buffer
+
+	for _, line := range tikz.lines {
+		fmt.Fprintf(&output, "      %v\n", line)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// lines
+	return output.String()
+}
+
+// Generates the TikZ code necessary for writing all lengths
+func (tikz perimeterAreaTikZ) GetLabels() string {
+
+	// Use a btyes buffer to append the strings of each label
+	var output bytes.Buffer
+
+	for _, label := range tikz.labels {
+		fmt.Fprintf(&output, "      %v\n", label)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for writing all
+	// labels
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz perimeterAreaTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("perimeterAreaTikZ").Parse(tikZPerimeterAreaCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- perimeterArea
+
+// return the instance of a specific perimeter and area problem that can be
+// marshalled in JSON format. The receiver is assumed to have been fully
+// verified so that it should be consistent.
+//
+// The result is given with the shape ("rectangle" or "triangle"), the
+// magnitude ("perimeter" or "area"), the lengths of the shape and the value of
+// the magnitude. Rectangles are given with their width and height; triangles
+// with their three sides if the perimeter is requested, and with their base
+// and height otherwise. Either the magnitude or the height are shown as "?" in
+// the arguments as they have to be guessed by the student
+func (pa perimeterArea) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if pa.geq < 1 || pa.geq > pa.leq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate lengths in the range [%v, %v]",
+			pa.geq, pa.leq)
+	}
+
+	// randomly determine the lengths of the shape until they are valid, i.e.,
+	// the sides of triangles satisfy the triangle inequality and their area is
+	// a whole number. If none is found after a maximum number of attempts, the
+	// parameters are deemed to be incompatible
+	var lengths []int
+	var value int
+	for attempt := 0; ; attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate a %v with lengths in the range [%v, %v] after %v attempts",
+				pa.shape, pa.geq, pa.leq, MAXGENERATIONATTEMPTS)
+		}
+
+		length := func() int {
+			return pa.geq + rnd.Intn(1+pa.leq-pa.geq)
+		}
+		if pa.shape == PARECTANGLE {
+			lengths = []int{length(), length()}
+			value = lengths[0] * lengths[1]
+			if pa.patype == PAPERIMETER {
+				value = 2 * (lengths[0] + lengths[1])
+			}
+			break
+		}
+		if pa.patype == PAPERIMETER {
+			lengths = []int{length(), length(), length()}
+			if lengths[0] < lengths[1]+lengths[2] && lengths[1] < lengths[0]+lengths[2] && lengths[2] < lengths[0]+lengths[1] {
+				value = lengths[0] + lengths[1] + lengths[2]
+				break
+			}
+			continue
+		}
+		lengths = []int{length(), length()}
+		if (lengths[0]*lengths[1])%2 == 0 {
+			value = lengths[0] * lengths[1] / 2
+			break
+		}
+	}
+
+	// create two slices: one for storing the instance of this problem, where
+	// the part that should be filled in by the student is marked with a
+	// question mark "?"; and another one with the full solution
+	magnitude := "area"
+	if pa.patype == PAPERIMETER {
+		magnitude = "perimeter"
+	}
+	solution := []string{pa.shape, magnitude}
+	for _, length := range lengths {
+		solution = append(solution, strconv.FormatInt(int64(length), 10))
+	}
+	solution = append(solution, strconv.FormatInt(int64(value), 10))
+	args := make([]string, len(solution))
+	copy(args, solution)
+
+	// and now mask the requested number, either the magnitude or the last
+	// length, i.e., the height
+	if pa.patype == PASIDE {
+		args[len(args)-2] = "?"
+	} else {
+		args[len(args)-1] = "?"
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "PerimeterArea",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this perimeter and area problem
+// using TikZ components
+func (pa perimeterArea) GetTikZPicture() (string, error) {
+
+	// -- shape: randomly determine the lengths of the shape. For this, the
+	//           service that generates problems is the one that can marshal
+	//           them into JSON format. A question mark is a number that has to
+	//           be guessed by the student
+	instance, err := pa.next(annotated(pa))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid perimeter and area problem: %v", err)
+	}
+
+	// all numbers to guess are shown within the same width which consists of
+	// the number of digits of the largest number plus one additional digit to
+	// each side
+	nbdigits := 0.0
+	var lengths []float64
+	for _, item := range instance.Solution[2:] {
+		nbdigits = helpers.Max(nbdigits, float64(len(item)))
+		value, _ := strconv.Atoi(item)
+		lengths = append(lengths, float64(value))
+	}
+	lengths = lengths[:len(lengths)-1]
+	width := 2.0 + nbdigits
+
+	// the following function returns the text to show for the i-th argument:
+	// either an empty box, if the number has to be guessed, or the number
+	// itself
+	cell := func(label string, position components.Positioner, i int) components.CoordinatedText {
+		options, text := "", ""
+		if instance.Args[i] == "?" {
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width))
+			text = pa.answer(instance.Solution[i])
+		} else {
+			text = `\huge ` + instance.Args[i]
+		}
+		return components.NewCoordinatedText(components.NewCoordinate(position, label), options, text)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box. The shape is drawn
+	// above the question leaving room for the labels to its left and below
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	origin := components.Point{X: 2.0, Y: 2.4}
+
+	// compute the vertices of the shape in the same units than its lengths,
+	// with the first one located at the origin, and also the location of the
+	// labels of all lengths given as the index of the vertices they are
+	// written between. The labels of triangles are moved away from their
+	// centroid
+	var points [][2]float64
+	var sides [][2]int
+	switch {
+	case instance.Args[0] == PARECTANGLE:
+		points = [][2]float64{{0, 0}, {lengths[0], 0}, {lengths[0], lengths[1]}, {0, lengths[1]}}
+		sides = [][2]int{{0, 1}, {3, 0}}
+	case instance.Args[1] == "perimeter":
+
+		// the first side is the base, and the apex is located using the law
+		// of cosines
+		a, b, c := lengths[0], lengths[1], lengths[2]
+		x := (a*a + b*b - c*c) / (2.0 * a)
+		points = [][2]float64{{0, 0}, {a, 0}, {x, math.Sqrt(b*b - x*x)}}
+		sides = [][2]int{{0, 1}, {2, 0}, {1, 2}}
+	default:
+
+		// the apex is located at one third of the base, and the height is
+		// drawn from it
+		points = [][2]float64{{0, 0}, {lengths[0], 0}, {lengths[0] / 3.0, lengths[1]}, {lengths[0] / 3.0, 0}}
+		sides = [][2]int{{0, 1}, {2, 3}}
+	}
+
+	// scale the shape so that its largest dimension has the same size in all
+	// problems, and shift it so that no vertex is located to the left of the
+	// origin
+	minx, maxx, maxy := 0.0, 0.0, 0.0
+	for _, point := range points {
+		minx, maxx, maxy = math.Min(minx, point[0]), math.Max(maxx, point[0]), math.Max(maxy, point[1])
+	}
+	scale := PASHAPESIZE / math.Max(maxx-minx, maxy)
+	var corners []components.Point
+	var vertices []components.Coordinate
+	var centroid components.Point
+	for idx, point := range points {
+		corner := components.Point{
+			X: origin.X + scale*(point[0]-minx),
+			Y: origin.Y + scale*point[1],
+		}
+		corners = append(corners, corner)
+		vertices = append(vertices, components.NewCoordinate(corner, fmt.Sprintf("vertex%v", idx)))
+		if idx < 3 {
+			centroid = components.Point{X: centroid.X + corner.X/3.0, Y: centroid.Y + corner.Y/3.0}
+		}
+	}
+
+	// -- shape
+	var shape fmt.Stringer
+	var lines []components.Line
+	if instance.Args[0] == PARECTANGLE {
+		rect := components.NewRectangle("vertex0", "vertex2")
+		rect.SetOptions("thick")
+		shape = rect
+	} else {
+		triangle := components.NewLine("vertex0", "vertex1", "vertex2", "vertex0")
+		triangle.SetOptions("thick")
+		shape = triangle
+		if instance.Args[1] != "perimeter" {
+			height := components.NewLine("vertex2", "vertex3")
+			height.SetOptions("dashed")
+			lines = append(lines, height)
+		}
+	}
+
+	// -- labels: the sides of rectangles are labeled below and to their left,
+	//            the sides of triangles away from their centroid and the
+	//            height to its right
+	var labels []components.CoordinatedText
+	for idx, side := range sides {
+		p0, p1 := corners[side[0]], corners[side[1]]
+		middle := components.Point{X: (p0.X + p1.X) / 2.0, Y: (p0.Y + p1.Y) / 2.0}
+		var offset components.Point
+		switch {
+		case instance.Args[0] == PARECTANGLE && idx == 0:
+			offset = components.Point{X: 0.0, Y: -0.6}
+		case instance.Args[0] == PARECTANGLE:
+			offset = components.Point{X: -0.3 - 0.1*width, Y: 0.0}
+		case instance.Args[1] != "perimeter" && idx == 1:
+			offset = components.Point{X: 0.3 + 0.1*width, Y: 0.0}
+		default:
+			dx, dy := middle.X-centroid.X, middle.Y-centroid.Y
+			norm := math.Hypot(dx, dy)
+			offset = components.Point{X: 0.6 * dx / norm, Y: 0.6 * dy / norm}
+		}
+		labels = append(labels, cell(fmt.Sprintf("length%v", idx),
+			components.Point{X: middle.X + offset.X, Y: middle.Y + offset.Y},
+			2+idx))
+	}
+
+	// -- question: the magnitude is written below the shape, either the
+	//              perimeter (P) or the area (A)
+	symbol := `\huge $A =$`
+	if instance.Args[1] == "perimeter" {
+		symbol = `\huge $P =$`
+	}
+	question := components.NewCoordinatedText(
+		components.NewCoordinate(components.Point{
+			X: origin.X + scale*(maxx-minx)/2.0 - 1.0,
+			Y: 0.6,
+		}, "question"),
+		"", symbol)
+	answer := cell("answer",
+		components.Formula(fmt.Sprintf(`$(question) + (0.8cm, 0.0) + (%v\zerowidth, 0.0)$`,
+			helpers.Ftoa(width/2.0))),
+		len(instance.Args)-1)
+
+	// -- bounding box
+	right := components.NewCoordinate(components.Point{
+		X: origin.X + scale*(maxx-minx) + 2.0,
+		Y: origin.Y + scale*maxy + 0.8,
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// perimeter and area problem
+	paPicture := perimeterAreaTikZ{
+		Bottom:   bottom,
+		vertices: vertices,
+		Shape:    shape,
+		lines:    lines,
+		labels:   labels,
+		Question: question,
+		Answer:   answer,
+		BBox:     bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return paPicture.execute()
+}
+
+// Return TikZ code that represents a perimeter and area problem
+func (pa perimeterArea) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this problem
+	tpl, err := template.New("perimeterArea").Parse(latexPerimeterAreaCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, pa); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
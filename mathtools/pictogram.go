@@ -0,0 +1,446 @@
+// -*- coding: utf-8 -*-
+// pictogram.go
+//
+// Description: Provides services for automatically creating problems where
+// data shown with tally marks or pictograms has to be read
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 02:41:36.520873114 (1792118496)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Data is shown either with tally marks ("tally") or with one symbol for every
+// unit ("pictogram")
+const (
+	PICTALLY     string = "tally"
+	PICPICTOGRAM string = "pictogram"
+)
+
+// The questions that can be asked are which category has the largest value
+// ("most"), which one has the smallest value ("least") and the sum of all
+// values ("total")
+const (
+	PICMOST  string = "most"
+	PICLEAST string = "least"
+	PICTOTAL string = "total"
+)
+
+// Maximum value of every category, so that all marks fit in one row
+const MAXPICTOGRAMVALUE int = 20
+
+// Every category is shown in a row with the following height, and tally marks
+// are as high as the following value and separated by the given distance. The
+// symbols of pictograms are as wide as the following size. All values are
+// given in centimeters
+const (
+	PICROWHEIGHT  float64 = 1.0
+	PICTALLYSIZE  float64 = 0.6
+	PICTALLYSTEP  float64 = 0.2
+	PICSYMBOLSIZE float64 = 0.5
+)
+
+// the TikZ code for generating pictograms is shown next. Note that it makes
+// use of LaTeX/TikZ components
+const latexPictogramCode = `\begin{minipage}{\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the pictogram
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZPictogramCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Data ------------------------------------------------------------
+
+      % every category is shown in a row with its name followed by its marks
+{{.GetData}}
+      % --- Questions -------------------------------------------------------
+
+      % every question is shown with a box where the answer has to be written
+{{.GetQuestions}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A pictogram consists of a number of categories, each one with a random value
+// in the range [geq, leq], which are shown either with tally marks or with a
+// symbol for every unit ("star", "circle" or "apple"). The student has to
+// answer the given questions, each one among "most", "least" and "total"
+type pictogram struct {
+	categories []string
+	geq, leq   int
+	chart      string
+	symbol     string
+	questions  []string
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw
+// pictograms
+type pictogramTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the names of the categories along with their marks, either tally marks or
+	// symbols
+	data []fmt.Stringer
+
+	// the questions are shown with their boxes
+	questions []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the TikZ position at the given distance in centimeters from the
+// lower-left corner of the bounding box
+func fromBottom(x, y float64) string {
+	return fmt.Sprintf(`$(bottom) + (%vcm, %vcm)$`, helpers.Ftoa(x), helpers.Ftoa(y))
+}
+
+// return the tally marks of the given value, which are drawn from the given
+// horizontal position with their base at the given height, all given in
+// centimeters. Every group of five marks consists of four vertical lines
+// crossed by a diagonal one
+func tallyMarks(value int, x, y float64) []fmt.Stringer {
+
+	var result []fmt.Stringer
+	for idx := 0; idx < value; idx++ {
+		group, mark := idx/5, idx%5
+		x0 := x + float64(group)*(5.0*PICTALLYSTEP+PICTALLYSTEP) + float64(mark)*PICTALLYSTEP
+		line := components.NewLine(fromBottom(x0, y), fromBottom(x0, y+PICTALLYSIZE))
+		if mark == 4 {
+			x0 = x + float64(group)*(5.0*PICTALLYSTEP+PICTALLYSTEP)
+			line = components.NewLine(
+				fromBottom(x0-PICTALLYSTEP/2.0, y+PICTALLYSIZE/4.0),
+				fromBottom(x0+3.5*PICTALLYSTEP, y+3.0*PICTALLYSIZE/4.0))
+		}
+		line.SetOptions("thick")
+		result = append(result, line)
+	}
+	return result
+}
+
+// return random values in the range [geq, leq] for the given number of
+// categories along with the indices of the categories with the largest and
+// smallest value. If unique is true, there must be only one category with the
+// largest value and only one with the smallest value, and an error is returned
+// if this is not achieved after MAXGENERATIONATTEMPTS attempts
+func categoryValues(nbcategories, geq, leq int, unique bool, rnd *rand.Rand) (values []int, most, least int, err error) {
+
+	values = make([]int, nbcategories)
+	for attempt := 0; attempt < MAXGENERATIONATTEMPTS; attempt++ {
+		for idx := range values {
+			values[idx] = geq + rnd.Intn(1+leq-geq)
+		}
+
+		// compute the categories with the largest and smallest value, and
+		// how many of them there are
+		most, least = 0, 0
+		nbmost, nbleast := 0, 0
+		for idx, value := range values {
+			if value > values[most] {
+				most, nbmost = idx, 0
+			}
+			if value == values[most] {
+				nbmost++
+			}
+			if value < values[least] {
+				least, nbleast = idx, 0
+			}
+			if value == values[least] {
+				nbleast++
+			}
+		}
+		if !unique || (nbmost == 1 && nbleast == 1) {
+			return values, most, least, nil
+		}
+	}
+	return nil, 0, 0, fmt.Errorf("It was not possible to generate %v categories with a unique largest and smallest value in the range [%v, %v] after %v attempts",
+		nbcategories, geq, leq, MAXGENERATIONATTEMPTS)
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- pictogramTikZ
+
+// Generates the TikZ code necessary for drawing all categories
+func (tikz pictogramTikZ) GetData() string {
+
+	// Use a btyes buffer to append the strings of each element
+	var output bytes.Buffer
+
+	for _, item := range tikz.data {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// categories
+	return output.String()
+}
+
+// Generates the TikZ code necessary for drawing all questions
+func (tikz pictogramTikZ) GetQuestions() string {
+
+	// Use a btyes buffer to append the strings of each question
+	var output bytes.Buffer
+
+	for _, item := range tikz.questions {
+		fmt.Fprintf(&output, "      %v\n", item)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// questions
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz pictogramTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("pictogramTikZ").Parse(tikZPictogramCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- pictogram
+
+// return the instance of a specific pictogram that can be marshalled in JSON
+// format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with the name of every category followed by its value.
+// Next, every question is followed by its answer: the name of a category for
+// "most" and "least", and the sum of all values for "total". Answers are shown
+// as "?" in the arguments as they have to be guessed by the student
+func (pic pictogram) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if len(pic.categories) < 2 {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate pictograms with %v categories", len(pic.categories))
+	}
+	if pic.geq < 0 || pic.geq > pic.leq || pic.leq > MAXPICTOGRAMVALUE {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate pictograms with values in the range [%v, %v]", pic.geq, pic.leq)
+	}
+
+	// randomly determine the values of all categories. If the categories with
+	// the largest or smallest value have to be guessed, they have to be unique
+	unique := helpers.Find(PICMOST, pic.questions) || helpers.Find(PICLEAST, pic.questions)
+	values, most, least, err := categoryValues(len(pic.categories), pic.geq, pic.leq, unique, rnd)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+
+	// create two slices: one for storing the instance of this problem where
+	// the answers that should be given by the student are marked with
+	// question marks "?"; and another one with the full solution
+	var args, solution []string
+	total := 0
+	for idx, category := range pic.categories {
+		args = append(args, category, strconv.Itoa(values[idx]))
+		solution = append(solution, category, strconv.Itoa(values[idx]))
+		total += values[idx]
+	}
+	for _, question := range pic.questions {
+		answer := strconv.Itoa(total)
+		switch question {
+		case PICMOST:
+			answer = pic.categories[most]
+		case PICLEAST:
+			answer = pic.categories[least]
+		}
+		args = append(args, question, "?")
+		solution = append(solution, question, answer)
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "Pictogram",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this pictogram using TikZ
+// components
+func (pic pictogram) GetTikZPicture() (string, error) {
+
+	// -- data: randomly determine the values of all categories. For this, the
+	//          service that generates problems is the one that can marshal
+	//          them into JSON format. A question mark is an answer that has to
+	//          be guessed by the student
+	instance, err := pic.next(annotated(pic))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid pictogram: %v", err)
+	}
+
+	// the names of all categories are written in a column wide enough for
+	// the longest one
+	namewidth := 0.0
+	for _, category := range pic.categories {
+		namewidth = helpers.Max(namewidth, float64(len([]rune(category))))
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- questions: they are shown from top to bottom at the bottom of the
+	//              picture, each one with a box wide enough for its answer
+	var questions []components.CoordinatedText
+	nbquestions := len(pic.questions)
+	for idx := 0; idx < nbquestions; idx++ {
+		y := PICROWHEIGHT * (0.5 + float64(nbquestions-1-idx))
+		offset := 2 * (len(pic.categories) + idx)
+		width, answer := 2.0+namewidth, instance.Solution[1+offset]
+		if instance.Args[offset] == PICTOTAL {
+			width = 2.0 + float64(helpers.NbDigits(len(pic.categories)*pic.leq))
+		}
+		if pic.showAnswers() {
+			answer = `\Large ` + answer
+		} else {
+			answer = ""
+		}
+		questions = append(questions,
+			components.NewCoordinatedText(
+				components.NewCoordinate(components.Formula(fromBottom(0.2, y)), fmt.Sprintf("question%v", idx)),
+				"anchor=west", `\large `+instance.Args[offset]+":"),
+			components.NewCoordinatedText(
+				components.NewCoordinate(components.Formula(fromBottom(2.2, y)), fmt.Sprintf("answer%v", idx)),
+				fmt.Sprintf(`anchor=west, rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+					helpers.Ftoa(width)),
+				answer))
+	}
+
+	// -- data: every category is shown in a row from top to bottom above the
+	//          questions with its name followed by its marks. Every unit takes
+	//          the same width, so that rows are long enough for the largest
+	//          value
+	var data []fmt.Stringer
+	xmarks, unit := 0.5+0.25*namewidth, 1.2*PICTALLYSTEP
+	if pic.chart == PICPICTOGRAM {
+		unit = 1.2 * PICSYMBOLSIZE
+	}
+	xmax := xmarks + unit*float64(pic.leq)
+	for idx, category := range pic.categories {
+		y := PICROWHEIGHT * (float64(nbquestions) + 0.5 + float64(len(pic.categories)-1-idx))
+		data = append(data, components.NewCoordinatedText(
+			components.NewCoordinate(components.Formula(fromBottom(0.2, y)), fmt.Sprintf("category%v", idx)),
+			"anchor=west", `\large `+category))
+
+		value, _ := strconv.Atoi(instance.Args[1+2*idx])
+		if pic.chart == PICTALLY {
+			data = append(data, tallyMarks(value, xmarks, y-PICTALLYSIZE/2.0)...)
+		} else {
+			for idx := 0; idx < value; idx++ {
+				data = append(data, components.NewSymbol(
+					fromBottom(xmarks+(0.5+float64(idx))*unit, y), pic.symbol, PICSYMBOLSIZE))
+			}
+		}
+
+		// separate this category from the next one
+		line := components.NewLine(fromBottom(0.0, y-PICROWHEIGHT/2.0), fromBottom(xmax, y-PICROWHEIGHT/2.0))
+		line.SetOptions("gray")
+		data = append(data, line)
+	}
+
+	// -- bounding box: it has to contain the boxes of the questions as well,
+	//                 whose width is estimated assuming that every digit is
+	//                 half a centimeter wide
+	right := components.NewCoordinate(components.Point{
+		X: helpers.Max(xmax, 2.2+0.5*(2.0+namewidth)) + 0.2,
+		Y: PICROWHEIGHT * float64(nbquestions+len(pic.categories)),
+	}, "right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// pictogram
+	picPicture := pictogramTikZ{
+		Bottom:    bottom,
+		data:      data,
+		questions: questions,
+		BBox:      bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return picPicture.execute()
+}
+
+// Return TikZ code that represents a pictogram
+func (pic pictogram) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this pictogram
+	tpl, err := template.New("pictogram").Parse(latexPictogramCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, pic); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,359 @@
+// -*- coding: utf-8 -*-
+// placevalue.go
+//
+// Description: Provides services for automatically creating problems of
+// decomposing numbers into place values and composing them back
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:04:05.211720943 (1792112645)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of place value problems: "decompose" or
+// "compose". In the first case, the number is shown and the student has to
+// guess the digits of some of its places; in the latter, all places are shown
+// and the student has to guess the number
+const (
+	PVDECOMPOSE int = iota
+	PVCOMPOSE
+)
+
+// the TikZ code for generating place value problems is shown next. Note that
+// it makes use of LaTeX/TikZ components
+const latexPlaceValueCode = `\begin{minipage}{\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the place value problem
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZPlaceValueCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Number ----------------------------------------------------------
+
+      % the number is shown to the left of the equal sign
+      {{.Number}}
+      {{.Equal}}
+
+      % --- Places ----------------------------------------------------------
+
+      % every place is shown with its digit above its name, and consecutive
+      % places are separated by a plus sign
+{{.GetPlaces}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A place value problem consists of a number with the given number of digits
+// and its decomposition into the digits of every place (units, tens, hundreds,
+// ...). There are two types of place value problems:
+//
+//    0: the number is shown and the student has to guess the digits of the
+//    masked places. These are either those given in masked or, if none is
+//    given, nbmasked places randomly chosen
+//    1: the digits of all places are shown and the student has to guess the
+//    number
+type placeValue struct {
+	pvtype   int
+	nbdigits int
+	nbmasked int
+	masked   []string
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw place
+// value problems
+type placeValueTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the number is shown to the left of the equal sign
+	Number, Equal components.CoordinatedText
+
+	// every place is shown with its digit and its name, and plus signs are
+	// shown between consecutive places
+	places []components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// Names of the places of every digit of a number starting from the units.
+// Numbers can have at most as many digits as places are named here, so that
+// all of them fit in the width of a page
+var placeNames = []string{
+	"units", "tens", "hundreds",
+	"thousands", "ten thousands", "hundred thousands"}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- placeValueTikZ
+
+// Generates the TikZ code necessary for drawing all the places of the number
+func (tikz placeValueTikZ) GetPlaces() string {
+
+	// Use a btyes buffer to append the strings of each place
+	var output bytes.Buffer
+
+	for _, place := range tikz.places {
+		fmt.Fprintf(&output, "      %v\n", place)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// places
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz placeValueTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("placeValueTikZ").Parse(tikZPlaceValueCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- placeValue
+
+// return the instance of a specific place value problem that can be marshalled
+// in JSON format. The receiver is assumed to have been fully verified so that
+// it should be consistent.
+//
+// The result is given with the number followed by the digits of all its places
+// from the highest one to the units. Either the number or the masked places are
+// shown as "?" in the arguments as they have to be guessed by the student
+func (pv placeValue) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if pv.nbdigits < 1 || pv.nbdigits > len(placeNames) {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate place value problems with %v digits", pv.nbdigits)
+	}
+
+	// randomly determine the number
+	number := helpers.RandN(rnd, pv.nbdigits)
+
+	// the solution consists of the number and the digits of all its places,
+	// from the highest one to the units
+	value := strconv.FormatInt(int64(number), 10)
+	solution := []string{value}
+	for _, digit := range value {
+		solution = append(solution, string(digit))
+	}
+	args := make([]string, len(solution))
+	copy(args, solution)
+
+	// in case the number has to be composed, mask it
+	if pv.pvtype == PVCOMPOSE {
+		args[0] = "?"
+	} else {
+
+		// otherwise, mask either the given places or the requested number of
+		// places randomly chosen. Note that the i-th place is located at
+		// position nbdigits-i of the arguments
+		if len(pv.masked) > 0 {
+			for _, name := range pv.masked {
+				for place, placeName := range placeNames[:pv.nbdigits] {
+					if name == placeName {
+						args[pv.nbdigits-place] = "?"
+					}
+				}
+			}
+		} else {
+			for _, place := range rnd.Perm(pv.nbdigits)[:pv.nbmasked] {
+				args[pv.nbdigits-place] = "?"
+			}
+		}
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "PlaceValue",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this place value problem using
+// TikZ components
+func (pv placeValue) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the number. For this, the service that
+	//              generates problems is the one that can marshal them into
+	//              JSON format. A question mark is a number that has to be
+	//              guessed by the student
+	instance, err := pv.next(annotated(pv))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid place value problem: %v", err)
+	}
+
+	// the number is shown with one additional digit to each side, and every
+	// place is as wide as its name, which is written in two lines at most
+	numberwidth := 2.0 + float64(pv.nbdigits)
+	placewidth := 3.0
+
+	// all digits are vertically centered at the same height, above the names
+	// of the places
+	height := `2.5\baselineskip + 0.5\zeroheight`
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// the following function returns the text to show at the given
+	// horizontal position for the i-th argument: either an empty box, if the
+	// value has to be guessed, or the value itself
+	cell := func(label string, x, width float64, i int) components.CoordinatedText {
+		options, text := "", ""
+		if instance.Args[i] == "?" {
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width))
+			text = pv.answer(instance.Solution[i])
+		} else {
+			text = `\huge ` + instance.Args[i]
+		}
+		return components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v)$`,
+					helpers.Ftoa(x), height)),
+				label),
+			options, text)
+	}
+
+	// likewise, symbols are shown at the given horizontal position
+	symbol := func(label string, x float64, text string) components.CoordinatedText {
+		return components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v)$`,
+					helpers.Ftoa(x), height)),
+				label),
+			"", text)
+	}
+
+	// -- number
+	number := cell("number", 0.5+numberwidth/2.0, numberwidth, 0)
+	equal := symbol("equal", 1.25+numberwidth, `\huge $=$`)
+
+	// -- places: they are shown from the highest one to the units, each one
+	//            with its name below its digit
+	var places []components.CoordinatedText
+	x := 2.0 + numberwidth
+	for idx := 1; idx <= pv.nbdigits; idx++ {
+
+		// separate this place from the previous one with a plus sign
+		if idx > 1 {
+			places = append(places, symbol(fmt.Sprintf("plus%v", idx), x+0.75, `\huge $+$`))
+			x += 1.5
+		}
+
+		center := x + placewidth/2.0
+		places = append(places, cell(fmt.Sprintf("place%v", idx), center, 2.0, idx))
+		places = append(places, components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.25\baselineskip)$`,
+					helpers.Ftoa(center))),
+				fmt.Sprintf("name%v", idx)),
+			fmt.Sprintf(`anchor=south, text width=%v\zerowidth, align=center`, helpers.Ftoa(placewidth)),
+			`\footnotesize `+placeNames[pv.nbdigits-idx]))
+		x += placewidth
+	}
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 3\baselineskip + \zeroheight)$`,
+			helpers.Ftoa(x+0.5))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the place
+	// value problem
+	pvPicture := placeValueTikZ{
+		Bottom: bottom,
+		Number: number,
+		Equal:  equal,
+		places: places,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return pvPicture.execute()
+}
+
+// Return TikZ code that represents a place value problem
+func (pv placeValue) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this place value
+	// problem
+	tpl, err := template.New("placeValue").Parse(latexPlaceValueCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, pv); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
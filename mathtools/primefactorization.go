@@ -0,0 +1,357 @@
+// -*- coding: utf-8 -*-
+// primefactorization.go
+//
+// Description: Provides services for automatically creating problems of
+// decomposing numbers into their prime factors with factor trees
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:26:33.168802976 (1792113993)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// By default, numbers are decomposed into at most the following number of
+// prime factors (counted with their multiplicity)
+const DEFAULTMAXPRIMEFACTORS int = 4
+
+// the TikZ code for generating factor trees is shown next. Note that it makes
+// use of LaTeX/TikZ components
+const latexPrimeFactorizationCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the factor tree
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZPrimeFactorizationCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Root ------------------------------------------------------------
+
+      % the number to decompose is shown at the top of the tree
+      {{.Root}}
+
+      % --- Nodes -----------------------------------------------------------
+
+      % every level of the tree splits off one prime factor to the left and
+      % the remaining quotient to the right
+{{.GetNodes}}
+      % --- Branches --------------------------------------------------------
+
+{{.GetBranches}}
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A prime factorization consists of a composite number in the range [geq, leq]
+// with at most maxfactors prime factors (counted with their multiplicity) that
+// has to be decomposed with a factor tree
+type primeFactorization struct {
+	geq, leq   int
+	maxfactors int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw factor
+// trees
+type primeFactorizationTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the number to decompose is shown at the root of the tree
+	Root components.CoordinatedText
+
+	// the nodes of the tree below its root are shown as boxes which are
+	// connected to their parents with branches
+	nodes    []components.CoordinatedText
+	branches []components.Line
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the prime factors of the given number in increasing order, each one
+// repeated as many times as it divides the number
+func primeFactors(number int) []int {
+
+	var factors []int
+	for factor := 2; factor*factor <= number; factor++ {
+		for number%factor == 0 {
+			factors = append(factors, factor)
+			number /= factor
+		}
+	}
+	if number > 1 {
+		factors = append(factors, number)
+	}
+	return factors
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- primeFactorizationTikZ
+
+// Generates the TikZ code necessary for drawing all the nodes of the tree but
+// its root
+func (tikz primeFactorizationTikZ) GetNodes() string {
+
+	// Use a btyes buffer to append the strings of each node
+	var output bytes.Buffer
+
+	for _, node := range tikz.nodes {
+		fmt.Fprintf(&output, "      %v\n", node)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// nodes
+	return output.String()
+}
+
+// Generates the TikZ code necessary for drawing all the branches of the tree
+func (tikz primeFactorizationTikZ) GetBranches() string {
+
+	// Use a btyes buffer to append the strings of each branch
+	var output bytes.Buffer
+
+	for _, branch := range tikz.branches {
+		fmt.Fprintf(&output, "      %v\n", branch)
+	}
+
+	// and return the concatenation of the LaTeX/TikZ code used for drawing all
+	// branches
+	return output.String()
+}
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz primeFactorizationTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("primeFactorizationTikZ").Parse(tikZPrimeFactorizationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- primeFactorization
+
+// return the instance of a specific prime factorization that can be marshalled
+// in JSON format. The receiver is assumed to have been fully verified so that
+// it should be consistent.
+//
+// The result is given as the number to decompose followed by all its prime
+// factors in increasing order. All factors are shown as "?" in the arguments
+// as they have to be guessed by the student
+func (pf primeFactorization) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if pf.geq > pf.leq {
+		return ProblemJSON{}, fmt.Errorf("The range [%v, %v] of the numbers to decompose is empty", pf.geq, pf.leq)
+	}
+
+	// randomly pick up numbers in the given range until one with at least two
+	// and no more than the maximum number of prime factors is found. If none
+	// is found after a maximum number of attempts, the parameters are deemed
+	// to be incompatible
+	var number int
+	var factors []int
+	for attempt := 0; len(factors) < 2 || len(factors) > pf.maxfactors; attempt++ {
+
+		if attempt >= MAXGENERATIONATTEMPTS {
+			return ProblemJSON{}, fmt.Errorf("It was not possible to generate a composite number in the range [%v, %v] with at most %v prime factors after %v attempts",
+				pf.geq, pf.leq, pf.maxfactors, MAXGENERATIONATTEMPTS)
+		}
+		number = pf.geq + rnd.Intn(1+pf.leq-pf.geq)
+		factors = primeFactors(number)
+	}
+
+	// and return the problem along with its solution
+	args := []string{fmt.Sprintf("%v", number)}
+	solution := []string{fmt.Sprintf("%v", number)}
+	for _, factor := range factors {
+		args = append(args, "?")
+		solution = append(solution, fmt.Sprintf("%v", factor))
+	}
+	return ProblemJSON{
+		Probtype: "PrimeFactorization",
+		Args:     args,
+		Solution: solution,
+	}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this prime factorization using
+// TikZ components. The tree has as many levels as prime factors: every level
+// below the root shows one prime factor to the left and the quotient of the
+// number by all the factors found so far to the right, so that the last level
+// contains the two largest factors
+func (pf primeFactorization) GetTikZPicture() (string, error) {
+
+	// -- factors: randomly determine the number to decompose. For this, the
+	//             service that generates problems is the one that can marshal
+	//             them into JSON format
+	instance, err := pf.next(annotated(pf))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid prime factorization: %v", err)
+	}
+	number, _ := helpers.Atoi(instance.Solution[0])
+	factors := instance.Solution[1:]
+	nblevels := len(factors)
+
+	// all boxes are wide enough to write the number to decompose and every
+	// child is shifted half a step to the left or right of its parent
+	boxwidth := 2.0 + float64(helpers.NbDigits(number))
+	step := 1.0 + boxwidth
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// return the location of the node in the given level (0 for the root) and
+	// the given horizontal offset (measured in halves of a step) to the right
+	// of the root, which is located so that the leftmost prime factor is
+	// half a digit away from the left side of the bounding box
+	at := func(level, offset int) components.Formula {
+		return components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zeroheight + %v\baselineskip)$`,
+			helpers.Ftoa(0.5+boxwidth/2.0+step/2.0*float64(1+offset)),
+			helpers.Ftoa(0.5+float64(nblevels-1-level)),
+			helpers.Ftoa(1.0+2.0*float64(nblevels-1-level))))
+	}
+
+	// the following function returns a box at the given level and offset with
+	// the given label whose contents have to be guessed
+	box := func(level, offset int, label, solution string) components.CoordinatedText {
+		return components.NewCoordinatedText(
+			components.NewCoordinate(at(level, offset), label),
+			fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(boxwidth)),
+			pf.answer(solution))
+	}
+
+	// and the following one returns a branch between the bottom of the parent
+	// and the top of the child given with their labels
+	branch := func(parent, child string) components.Line {
+		return components.NewLine(
+			fmt.Sprintf(`$(%v) + (0, -0.5\zeroheight - 0.5\baselineskip)$`, parent),
+			fmt.Sprintf(`$(%v) + (0, 0.5\zeroheight + 0.5\baselineskip)$`, child))
+	}
+
+	// -- root
+	root := components.NewCoordinatedText(
+		components.NewCoordinate(at(0, 0), "quotient0"),
+		"", fmt.Sprintf(`\huge %v`, number))
+
+	// -- nodes: the prime factor of every level is located half a step to the
+	//           left of its parent and the quotient half a step to its right.
+	//           The quotient of the last level is the largest prime factor
+	var nodes []components.CoordinatedText
+	var branches []components.Line
+	quotient := number
+	for level := 1; level < nblevels; level++ {
+		factor, _ := helpers.Atoi(factors[level-1])
+		quotient /= factor
+
+		nodes = append(nodes,
+			box(level, level-2, fmt.Sprintf("factor%v", level), factors[level-1]),
+			box(level, level, fmt.Sprintf("quotient%v", level), fmt.Sprintf("%v", quotient)))
+		branches = append(branches,
+			branch(fmt.Sprintf("quotient%v", level-1), fmt.Sprintf("factor%v", level)),
+			branch(fmt.Sprintf("quotient%v", level-1), fmt.Sprintf("quotient%v", level)))
+	}
+
+	// -- bounding box: the rightmost node is the quotient of the last level
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v\zeroheight + %v\baselineskip)$`,
+			helpers.Ftoa(1.0+boxwidth+step/2.0*float64(nblevels)),
+			nblevels,
+			2*nblevels)),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// factor tree
+	pfPicture := primeFactorizationTikZ{
+		Bottom:   bottom,
+		Root:     root,
+		nodes:    nodes,
+		branches: branches,
+		BBox:     bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return pfPicture.execute()
+}
+
+// Return TikZ code that represents a prime factorization
+func (pf primeFactorization) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this prime factorization
+	tpl, err := template.New("primeFactorization").Parse(latexPrimeFactorizationCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, pf); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
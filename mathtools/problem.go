@@ -10,10 +10,18 @@ package mathtools
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/clinaresl/mathprob/helpers"
 )
 
 // This file contains general functions for handling requests to automatically
@@ -21,233 +29,650 @@ import (
 // as a bridge to connect mathprob to a front-end. It works from a list of
 // requests given also in JSON format
 
+// constants
+// ----------------------------------------------------------------------------
+
+// Maximum number of attempts for generating an instance which is different
+// from the previous one when repetitions have to be avoided
+const MAXREPEATATTEMPTS int = 100
+
+// Maximum number of attempts for randomly generating an instance which is
+// compliant with the parameters of a problem. If it is exceeded, the parameters
+// are deemed to be incompatible and an error is returned
+const MAXGENERATIONATTEMPTS int = 100000
+
 // types
 // ----------------------------------------------------------------------------
 
 // A master problem consists of a number of arbitrary arguments of any type
-// indexed by a string, a specific type and a number of problems to generate
+// indexed by a string, a specific type and a number of problems to generate.
+// Optionally, it can be requested to avoid generating consecutive problems
+// with exactly the same arguments and solution. Also, a seed can be given so that the same
+// problems are generated every time. If the seed is zero, then it is taken
+// from the current time. Problems whose difficulty falls outside the given
+// band (if any) are generated again. Master problems created with typed
+// options (see NewMasterProblem) use them instead of the arguments
 type MasterProblem struct {
-	probtype string
-	args     map[string]interface{}
-	nbprobs  int
+	probtype    string
+	args        map[string]interface{}
+	options     Options
+	nbprobs     int
+	avoidrepeat bool
+	Seed        int64
+	Difficulty  DifficultyBand
+}
+
+// Every type of problem supported is described with its name, the mandatory
+// and optional keys of the dictionary used to define it, and an example of
+// valid arguments. In addition, it is recorded whether it can be used in master
+// files or only through the JSON API
+type ProblemType struct {
+	Name      string                 `json:"name"`
+	Mandatory []string               `json:"mandatory"`
+	Optional  []string               `json:"optional,omitempty"`
+	Example   map[string]interface{} `json:"example"`
+	Master    bool                   `json:"master"`
 }
 
 // A problem in JSON format consists mainly of two fields: the arguments of the
 // problem and its solution. Those records in the arguments of the problem that
 // have to be filled in by the student are marked with a question mark "?". In
 // addition, different problems might have different types and thus, a probtype
-// field is given also
-type problemJSON struct {
-	Probtype string   `json:"type"`
-	Id       int      `json:"id"`
-	Args     []string `json:"args"`
-	Solution []string `json:"solution"`
+// field is given also. Some problems (e.g., divisions or linear equations) also
+// enumerate the intermediate steps of their solution, so that worked solutions
+// or hints can be shown. Finally, problems generated through the JSON API are
+// given with their difficulty (see MINDIFFICULTY and MAXDIFFICULTY), the skill
+// they exercise and tags that describe them further (e.g., "carrying")
+type ProblemJSON struct {
+	Probtype   string   `json:"type"`
+	Id         int      `json:"id"`
+	Args       []string `json:"args"`
+	Solution   []string `json:"solution"`
+	Steps      []string `json:"steps,omitempty"`
+	Difficulty int      `json:"difficulty,omitempty"`
+	Skill      string   `json:"skill,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// When generating problems from master files, their solutions can be
+// optionally recorded in a slice shared by all problems of the same master
+// file. If no slice is given, then problems are not recorded at all.
+//
+// Recorded problems can be replayed later in the same order they were
+// generated. In this case, replay stores the index of the next problem to
+// replay and the solutions are shown within the boxes the student should fill
+// in, so that answer keys can be generated for the same problems, unless blank
+// is true.
+//
+// All problems of the same master file are generated with the same source of
+// random numbers. If none is given, a new one is created for every problem.
+// The random functions of master files draw values with a different source of
+// random numbers, if any. Optionally, a generation context can be given to avoid repeating problems
+// within the same master file, and problems whose difficulty falls outside the
+// given band are generated again. The generation stops as soon as the given
+// context (if any) is done
+type recorder struct {
+	solutions *[]ProblemJSON
+	replay    *int
+	blank     bool
+	rnd       *rand.Rand
+	draws     *rand.Rand
+	context   *generationContext
+	band      DifficultyBand
+	ctx       context.Context
+}
+
+// A generation context keeps track of all the problems generated so far in
+// the same sheet, so that repeated problems are regenerated a maximum number
+// of attempts
+type generationContext struct {
+	seen     map[string]bool
+	attempts int
 }
 
 // functions
 // ----------------------------------------------------------------------------
 
+// return a description of all the problem types currently supported, sorted in
+// alphabetical order. The name of each problem type is the one used in the
+// JSON API and also the name of the method used in master files. Problem types
+// registered by third parties (see Register) are included as well
+func SupportedTypes() []ProblemType {
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	var types []ProblemType
+	for _, entry := range registry {
+		types = append(types, entry.description)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name < types[j].Name
+	})
+	return types
+}
+
 // return an array of instances of MasterProblem from the contents of a json
-// file. In case it is not possible to unmarshall the contents of the json file,
-// then an error is returned and the contents of the slice are undefined
+// file. The contents are first validated against the JSON Schema of requests
+// (see RequestSchema). In case it is not possible to unmarshall the contents of
+// the json file, then an error is returned with the path to the offending value
+// and the contents of the slice are undefined
 func Unmarshall(data []byte) (output []MasterProblem, err error) {
 
 	// first things first, decode the data in the JSON file, which is expected
-	// to be a slice of entries, each specifying a different problem type
-	var jsondata []map[string]interface{}
-	if err = json.NewDecoder(bytes.NewReader(data)).Decode(&jsondata); err != nil {
-		return output, errors.New("Error while decoding JSON data to generate instances of master problems")
+	// to be a slice of entries, each specifying a different problem type.
+	// Numbers are decoded as they are written so that large seeds are not
+	// rounded
+	var jsondata interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&jsondata); err != nil {
+		return output, fmt.Errorf("Error while decoding JSON data to generate instances of master problems: %v", err)
+	}
+	if err = validateRequest(jsondata); err != nil {
+		return output, fmt.Errorf("Invalid request of problems: %v", err)
 	}
 
-	// ensure that data has been properly processed
-	for _, entry := range jsondata {
-
-		var ok bool
-
-		// Right now just unmarshall this specific entry to an instance of
-		// MasterProblem by decoding each entry separately
+	// once the data has been validated, all entries are known to have the
+	// right types. Still, their problem types and arguments have to be
+	// verified before generating any problem
+	for idx, item := range jsondata.([]interface{}) {
+		entry := item.(map[string]interface{})
+		if err = verifyRequestArgs(entry["type"].(string), entry["args"].(map[string]interface{}), fmt.Sprintf("$[%v]", idx)); err != nil {
+			return output, fmt.Errorf("Invalid request of problems: %v", err)
+		}
 
-		// First, process the type. Verify first that it exists as a key in the
-		// JSON input file; if so, extract its value
-		var probtype string
-		if _, ok = entry["type"]; !ok {
-			return output, errors.New("The type of problem to generate has not been found!")
-		} else {
-			if probtype, ok = entry["type"].(string); !ok {
-				return output, errors.New("The problem type could not be casted into a string")
+		// the seed is optional and, by default, it is taken from the current
+		// time. Likewise, repetitions of consecutive problems are allowed
+		// unless otherwise stated
+		var seed int
+		if _, ok := entry["seed"]; ok {
+			if seed, err = helpers.Atoi(entry["seed"]); err != nil {
+				return output, errors.New("The seed could not be casted into an integer")
 			}
 		}
-		// Second, process the number of problems to generate. Verify first that
-		// it exists as a key in the JSON input file; if so, extract its value
-		var nbprobs int
-		if _, ok = entry["nbprobs"]; !ok {
-			return output, errors.New("The number of problems has not been found!")
-		} else {
-			if _, ok = entry["nbprobs"].(float64); !ok {
-				return output, errors.New("The number of problems to generate could not be casted into an integer")
-			} else {
-				nbprobs = int(entry["nbprobs"].(float64))
+		var avoidrepeat bool
+		if _, ok := entry["avoidrepeat"]; ok {
+			if avoidrepeat, err = helpers.Atob(entry["avoidrepeat"]); err != nil {
+				return output, fmt.Errorf("Invalid request of problems: $[%v].avoidrepeat: %v", idx, err)
 			}
 		}
 
-		// Thirdly, ensure the arguments are given and extract'em
-		var args map[string]interface{} = make(map[string]interface{})
-		if _, ok = entry["args"]; !ok {
-			return output, errors.New("The arguments for generating a type of problem has not been found!")
-		} else {
-			if args, ok = entry["args"].(map[string]interface{}); !ok {
-				return output, errors.New("The arguments are not given as a dict of strings to values of any type")
+		// likewise, problems of any difficulty are accepted unless a band of
+		// difficulties is given, either as a single difficulty or an interval
+		var band DifficultyBand
+		if _, ok := entry["difficulty"]; ok {
+			if band, err = ParseDifficultyBand(fmt.Sprint(entry["difficulty"])); err != nil {
+				return output, err
 			}
 		}
 
-		// and generate a master problem
-		masterProblem := MasterProblem{
-			probtype: probtype,
-			args:     args,
-			nbprobs:  nbprobs,
-		}
-		output = append(output, masterProblem)
+		// and generate a master problem. The number of problems is known to
+		// be an integer
+		nbprobs, _ := helpers.Atoi(entry["nbprobs"])
+		output = append(output, MasterProblem{
+			probtype:    entry["type"].(string),
+			args:        entry["args"].(map[string]interface{}),
+			nbprobs:     nbprobs,
+			avoidrepeat: avoidrepeat,
+			Seed:        int64(seed),
+			Difficulty:  band,
+		})
 	}
 
 	// and finally return with the data computed so far
 	return
 }
 
+// verify that the given problem type is registered and that the given
+// arguments are correct for it, and return an error with the path to the
+// offending value otherwise, where the given path is the one of the entry of
+// the request, e.g., "$[2].type" or "$[2].args.nbdvdigits". Whether the
+// arguments are correct is decided by the generator of the problem type only,
+// since some mandatory keys can be omitted when others are given, e.g., the
+// difficulty level of basic operations. As the generators of problems do not
+// report the key of the wrong arguments, the offending key is the first one,
+// in alphabetical order, either given or mandatory, whose replacement with
+// the value of the example of the problem type (or its removal, if none is
+// given) makes a difference when verifying the arguments
+func verifyRequestArgs(probtype string, args map[string]interface{}, path string) error {
+
+	entry, ok := lookup(probtype)
+	if !ok {
+		return fmt.Errorf("%v.type: unknown problem type '%v'", path, probtype)
+	}
+	err := entry.factory().Verify(args)
+	if err == nil {
+		return nil
+	}
+
+	// locate the key responsible of the error, including the mandatory keys
+	// which are missing
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	for _, key := range entry.description.Mandatory {
+		if _, ok := args[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		candidate := make(map[string]interface{}, len(args)+1)
+		for k, v := range args {
+			candidate[k] = v
+		}
+		if example, ok := entry.description.Example[key]; ok {
+			candidate[key] = example
+		} else {
+			delete(candidate, key)
+		}
+		if other := entry.factory().Verify(candidate); other == nil || other.Error() != err.Error() {
+			return fmt.Errorf("%v.args.%v: %v", path, key, err)
+		}
+	}
+	return fmt.Errorf("%v.args: %v", path, err)
+}
+
+// return a new instance of the given master problem that can be marshalled in
+// JSON format using the given source of random numbers, whose difficulty falls
+// within the band of the master problem, if any. If the instance could not be
+// generated, the contents of the returned problem are undefined and an error is
+// raised
+func generateJSONInstance(problem MasterProblem, rnd *rand.Rand) (ProblemJSON, error) {
+
+	// in case this problem was defined with typed options, use them directly
+	if problem.options != nil {
+		instance, err := problem.options.generator()
+		if err != nil {
+			return ProblemJSON{}, err
+		}
+		return problem.Difficulty.generate(annotated(instance), rnd)
+	}
+
+	// otherwise, look up the generator of this type of problem in the registry
+	entry, ok := lookup(problem.probtype)
+	if !ok {
+		return ProblemJSON{}, fmt.Errorf("Unsupported generation of JSON problems for problem type '%v'", problem.probtype)
+	}
+
+	// verify that all items in the dictionary of args are correct and, if so,
+	// generate a JSON stream with the representation of this specific problem
+	gen := entry.factory()
+	if err := gen.Verify(problem.args); err != nil {
+		return ProblemJSON{}, err
+	}
+	return problem.Difficulty.generate(gen.GenerateJSON, rnd)
+}
+
+// return a new source of random numbers initialized with the given seed. If
+// the seed is zero, then it is initialized with the current time
+func newRand(seed int64) *rand.Rand {
+
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// return true if and only if both problems have precisely the same arguments
+// and solution. Note that the solution has to be compared as well, since some
+// problem types hide the values to find in their arguments, e.g., clocks
+func sameProblem(prob1, prob2 ProblemJSON) bool {
+	return sameStrings(prob1.Args, prob2.Args) && sameStrings(prob1.Solution, prob2.Solution)
+}
+
+// return true if and only if both slices have precisely the same strings in
+// the same order
+func sameStrings(strings1, strings2 []string) bool {
+
+	// if they have a different number of strings, then they are necessarily
+	// different
+	if len(strings1) != len(strings2) {
+		return false
+	}
+
+	// otherwise, compare them one by one
+	for idx, item := range strings1 {
+		if item != strings2[idx] {
+			return false
+		}
+	}
+
+	// at this point, both slices are the same
+	return true
+}
+
+// return a step of a solution which applies the given operator to both numbers
+// and produces the given result. Negative numbers are written within
+// parentheses
+func solutionStep(number1, operator, number2, result interface{}) string {
+
+	term := func(number interface{}) string {
+		if text := fmt.Sprintf("%v", number); strings.HasPrefix(text, "-") {
+			return "(" + text + ")"
+		}
+		return fmt.Sprintf("%v", number)
+	}
+	return fmt.Sprintf("%v %v %v = %v", term(number1), operator, term(number2), result)
+}
+
 // given an array of master problems (of any type) return a slice of bytes in
 // JSON format with the requested problems. If a problem could not be generated,
-// the contents of the returned data are undefined and an error is raised
-func GenerateJSON(problems []MasterProblem) (data []byte, err error) {
+// the contents of the returned data are undefined and an error is raised. The
+// generation stops as soon as the given context is done
+func GenerateJSON(ctx context.Context, problems []MasterProblem) (data []byte, err error) {
+
+	// generate all the problems requested
+	jsonprobs, err := generateJSONProblems(ctx, problems)
+	if err != nil {
+		return data, err
+	}
+
+	// Now, marshal data and return the json bytes stream. Note that this
+	// function returns straight away the same error returned by the Marshal
+	// function
+	data, err = json.MarshalIndent(jsonprobs, "", "\t")
+	return data, err
+}
 
-	// -- initialization: create a slice of JSON problems where each request is
-	//                    filled in. These is the slice to marshal
-	var jsonprobs []problemJSON
+// given an array of master problems (of any type) write to the given writer the
+// requested problems in the same JSON format used by GenerateJSON. Problems are
+// encoded and written as soon as they are generated, so that they are not kept
+// in memory. If a problem could not be generated or written, an error is
+// returned and the contents written so far are not a valid JSON document. The
+// generation stops as soon as the given context is done
+func GenerateJSONStream(ctx context.Context, w io.Writer, problems []MasterProblem) error {
+	return streamJSONProblems(ctx, w, problems, "", "")
+}
+
+// write the list of problems requested in the given array of master problems
+// to the given writer as soon as they are generated, exactly as
+// json.MarshalIndent does with the given prefix, so that the list can be
+// nested in other JSON documents. The given header is written right before the
+// list. Nothing is written until the first problem has been generated, so that
+// if it can not be generated, nothing is written at all
+func streamJSONProblems(ctx context.Context, w io.Writer, problems []MasterProblem, header, prefix string) error {
+
+	// problems are written as the items of a list
+	first := header + "[\n" + prefix + "\t"
+	separator := first
+	err := eachJSONProblem(ctx, problems, func(iprob ProblemJSON) error {
+		data, err := json.MarshalIndent(iprob, prefix+"\t", "\t")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+		separator = ",\n" + prefix + "\t"
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// close the list, unless it is empty
+	if separator == first {
+		_, err = io.WriteString(w, header+"[]")
+	} else {
+		_, err = io.WriteString(w, "\n"+prefix+"]")
+	}
+	return err
+}
+
+// given an array of master problems (of any type) return all the problems
+// requested. If a problem could not be generated or the given context is done,
+// an error is raised
+func GenerateProblems(ctx context.Context, problems []MasterProblem) ([]ProblemJSON, error) {
+	return generateJSONProblems(ctx, problems)
+}
+
+// given an array of master problems (of any type) return all the problems
+// requested. If a problem could not be generated or the given context is done,
+// an error is raised
+func generateJSONProblems(ctx context.Context, problems []MasterProblem) (jsonprobs []ProblemJSON, err error) {
+
+	// just collect all problems in the same order they are generated
+	err = eachJSONProblem(ctx, problems, func(iprob ProblemJSON) error {
+		jsonprobs = append(jsonprobs, iprob)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonprobs, nil
+}
+
+// generate all the problems requested in the given array of master problems
+// (of any type) and invoke the given function with every one as soon as it is
+// generated. If a problem could not be generated, the function returns an
+// error or the given context is done, then the generation stops and the error
+// is returned
+func eachJSONProblem(ctx context.Context, problems []MasterProblem, yield func(ProblemJSON) error) error {
 
 	// for all problems
 	for _, problem := range problems {
 
+		// each master problem uses its own source of random numbers so that
+		// it can be reproduced by giving its seed
+		rnd := newRand(problem.Seed)
+
 		// each master problem requests a specific number of instances to
 		// generate
+		var previous ProblemJSON
 		for i := 0; i < problem.nbprobs; i++ {
 
-			// depending upon the type of problem to generate
-			switch strings.ToUpper(problem.probtype) {
-
-			case "BASICOPERATION":
-
-				// First, verify that all items in the dictionary of args are correct
-				if instance, err := verifyBasicOperationDict(problem.args); err != nil {
-					return data, err
-				} else {
+			// stop as soon as the generation is cancelled
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 
-					// if so, generate a JSON stream with the representation of this
-					// specific problem
-					if iprob, err := instance.generateJSONProblem(); err != nil {
-						return data, err
-					} else {
+			// generate a new instance of this problem
+			iprob, err := generateJSONInstance(problem, rnd)
+			if err != nil {
+				return err
+			}
 
-						// if everything went on correctly, then correctly
-						// number this problem and add this problem to the slice
-						// of problems to marshal
-						iprob.Id = i
-						jsonprobs = append(jsonprobs, iprob)
+			// in case it was requested to avoid repetitions, then make sure
+			// this instance is not the same than the previous one in this
+			// block. If so, regenerate it a bounded number of times
+			if problem.avoidrepeat && i > 0 {
+				for attempt := 0; sameProblem(iprob, previous); attempt++ {
+
+					// if the maximum number of attempts has been exhausted,
+					// then accept the repeated instance
+					if attempt >= MAXREPEATATTEMPTS {
+						log.Printf("Warning: it was not possible to avoid repeating the problem #%v of type '%v' after %v attempts", i, problem.probtype, MAXREPEATATTEMPTS)
+						break
+					}
+					if iprob, err = generateJSONInstance(problem, rnd); err != nil {
+						return err
 					}
 				}
+			}
 
-			case "DIVISION":
+			// if everything went on correctly, then correctly number this
+			// problem and hand it over
+			iprob.Id = i
+			previous = iprob
+			if err := yield(iprob); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-				// First, verify that all items in the dictionary of args are correct
-				if instance, err := verifyDivisionDict(problem.args); err != nil {
-					return data, err
-				} else {
+// methods
+// ----------------------------------------------------------------------------
 
-					// if so, generate a JSON stream with the representation of this
-					// specific problem
-					if iprob, err := instance.generateJSONProblem(); err != nil {
-						return data, err
-					} else {
+// -- MasterProblem
 
-						// if everything went on correctly, then correctly
-						// number this problem and add this problem to the slice
-						// of problems to marshal
-						iprob.Id = i
-						jsonprobs = append(jsonprobs, iprob)
-					}
-				}
+// return the number of problems to generate with this master problem
+func (problem MasterProblem) GetNbProbs() int {
+	return problem.nbprobs
+}
 
-			case "MYSTERYOPERATION":
+// return the LaTeX/TikZ code that draws the given instance of this master
+// problem, either with or without its answers
+func (problem MasterProblem) TikZ(instance ProblemJSON, answers bool) (string, error) {
 
-				// First, verify that all items in the dictionary of args are correct
-				if instance, err := verifyMysteryOperationDict(problem.args); err != nil {
-					return data, err
-				} else {
+	entry, ok := lookup(problem.probtype)
+	if !ok {
+		return "", fmt.Errorf("Unsupported problem type '%v'", problem.probtype)
+	}
+	gen := entry.factory()
+
+	// problems defined with typed options are drawn with them, whereas the
+	// others have to verify their dictionary first
+	if bg, ok := gen.(*builtinGenerator); ok && problem.options != nil {
+		instance, err := problem.options.generator()
+		if err != nil {
+			return "", err
+		}
+		bg.instance = instance
+	} else if err := gen.Verify(problem.args); err != nil {
+		return "", err
+	}
+	return gen.TikZ(instance, answers)
+}
 
-					// if so, generate a JSON stream with the representation of this
-					// specific problem
-					if iprob, err := instance.generateJSONProblem(); err != nil {
-						return data, err
-					} else {
+// -- generationContext
 
-						// if everything went on correctly, then correctly
-						// number this problem and add this problem to the slice
-						// of problems to marshal
-						iprob.Id = i
-						jsonprobs = append(jsonprobs, iprob)
-					}
-				}
+// return a new generation context which regenerates repeated problems at most
+// the given number of attempts. If it is not strictly positive, then
+// MAXREPEATATTEMPTS is used instead
+func newGenerationContext(attempts int) *generationContext {
+
+	if attempts <= 0 {
+		attempts = MAXREPEATATTEMPTS
+	}
+	return &generationContext{seen: make(map[string]bool), attempts: attempts}
+}
 
-			case "MULTIPLICATIONTABLE":
+// return a key that identifies the given problem by its type, arguments and
+// solution, so that it agrees with sameProblem
+func (ctx *generationContext) key(problem ProblemJSON) string {
+	return problem.Probtype + "\x00" + strings.Join(problem.Args, "\x00") +
+		"\x01" + strings.Join(problem.Solution, "\x00")
+}
 
-				// First, verify that all items in the dictionary of args are correct
-				if instance, err := verifyMultiplicationTableDict(problem.args); err != nil {
-					return data, err
-				} else {
+// generate a new problem with the given function and the given source of
+// random numbers which has not been generated before in this context. If it
+// is not possible after the maximum number of attempts, then a repeated
+// problem is accepted and a warning is issued
+func (ctx *generationContext) generate(generate func(rnd *rand.Rand) (ProblemJSON, error), rnd *rand.Rand) (ProblemJSON, error) {
 
-					// if so, generate a JSON stream with the representation of this
-					// specific problem
-					if iprob, err := instance.generateJSONProblem(); err != nil {
-						return data, err
-					} else {
+	problem, err := generate(rnd)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	for attempt := 0; ctx.seen[ctx.key(problem)]; attempt++ {
 
-						// if everything went on correctly, then correctly
-						// number this problem and add this problem to the slice
-						// of problems to marshal
-						iprob.Id = i
-						jsonprobs = append(jsonprobs, iprob)
-					}
-				}
+		// if the maximum number of attempts has been exhausted, then accept
+		// the repeated instance
+		if attempt >= ctx.attempts {
+			log.Printf("Warning: it was not possible to avoid repeating a problem of type '%v' after %v attempts", problem.Probtype, ctx.attempts)
+			break
+		}
+		if problem, err = generate(rnd); err != nil {
+			return ProblemJSON{}, err
+		}
+	}
+	ctx.seen[ctx.key(problem)] = true
+	return problem, nil
+}
 
-			case "SEQUENCE":
+// -- recorder
 
-				// First, verify that all items in the dictionary of args are correct
-				if instance, err := verifySequenceDict(problem.args); err != nil {
-					return data, err
-				} else {
+// add the given problem to the slice of solutions of this recorder, if any.
+// Problems are numbered in the same order they are recorded
+func (r recorder) record(problem ProblemJSON) {
 
-					// if so, generate a JSON stream with the representation of this
-					// specific problem
-					if iprob, err := instance.generateJSONProblem(); err != nil {
-						return data, err
-					} else {
+	if r.solutions == nil {
+		return
+	}
+	problem.Id = len(*r.solutions)
+	*r.solutions = append(*r.solutions, problem)
+}
 
-						// if everything went on correctly, then correctly
-						// number this problem and add this problem to the slice
-						// of problems to marshal
-						iprob.Id = i
-						jsonprobs = append(jsonprobs, iprob)
-					}
-				}
+// return the number of problems drawn so far, either generated or replayed
+func (r recorder) count() int {
 
-			default:
-				return data, fmt.Errorf("Unsupported generation of JSON problems for problem type '%v'", problem.probtype)
-			}
+	if r.replay != nil {
+		return *r.replay
+	}
+	if r.solutions == nil {
+		return 0
+	}
+	return len(*r.solutions)
+}
+
+// return the next problem to draw. If problems are being replayed, then the
+// next recorded problem is returned; otherwise, a new problem is generated with
+// the given function, until its difficulty falls within the band of this
+// recorder (if any), and it is recorded, if requested
+func (r recorder) next(generate func(rnd *rand.Rand) (ProblemJSON, error)) (ProblemJSON, error) {
+
+	// in case problems are being replayed, return the next one
+	if r.replay != nil {
+		if *r.replay >= len(*r.solutions) {
+			return ProblemJSON{}, errors.New("There are no more recorded problems to replay")
 		}
+		problem := (*r.solutions)[*r.replay]
+		*r.replay += 1
+		return problem, nil
 	}
 
-	// Now, marshal data and return the json bytes stream. Note that this
-	// function returns straight away the same error returned by the Marshal
-	// function
-	data, err = json.MarshalIndent(jsonprobs, "", "\t")
-	return data, err
+	// otherwise, generate a new problem (avoiding repetitions if a generation
+	// context is given) and record it, unless the generation was cancelled
+	if r.ctx != nil {
+		if err := r.ctx.Err(); err != nil {
+			return ProblemJSON{}, err
+		}
+	}
+	rnd := r.rnd
+	if rnd == nil {
+		rnd = newRand(0)
+	}
+	if !r.band.isNull() {
+		unbanded := generate
+		generate = func(rnd *rand.Rand) (ProblemJSON, error) {
+			return r.band.generate(unbanded, rnd)
+		}
+	}
+	var problem ProblemJSON
+	var err error
+	if r.context != nil {
+		problem, err = r.context.generate(generate, rnd)
+	} else {
+		problem, err = generate(rnd)
+	}
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	r.record(problem)
+	return problem, nil
+}
+
+// return whether the solutions of problems have to be shown, i.e., if recorded
+// problems are being replayed and they are not blank
+func (r recorder) showAnswers() bool {
+	return r.replay != nil && !r.blank
+}
+
+// return the text to show within a box that has to be filled in by the
+// student. It is empty unless the solutions of problems have to be shown, in
+// which case the given solution is shown
+func (r recorder) answer(solution string) string {
+
+	if !r.showAnswers() {
+		return ""
+	}
+	return `\huge ` + solution
 }
 
 // Local Variables:
@@ -0,0 +1,149 @@
+// -*- coding: utf-8 -*-
+// problem_test.go
+//
+// Description: Tests of the generation of problems in JSON format
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 16:31:05.227148903 (1792168265)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// requests with unknown problem types or wrong arguments are rejected before
+// generating any problem, with the path to the offending value
+func TestUnmarshallErrorPaths(t *testing.T) {
+
+	division := `{"type": "Division", "args": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2}, "nbprobs": 1}`
+	tests := []struct {
+		name    string
+		request string
+		path    string
+	}{
+		{"unknown type",
+			`[{"type": "Nope", "args": {}, "nbprobs": 1}]`,
+			"$[0].type:"},
+		{"unknown type after a valid entry",
+			`[` + division + `, {"type": "Nope", "args": {}, "nbprobs": 1}]`,
+			"$[1].type:"},
+		{"missing mandatory key",
+			`[{"type": "Division", "args": {"nbdvdigits": 3, "nbdrdigits": 1}, "nbprobs": 1}]`,
+			"$[0].args.nbqdigits:"},
+		{"wrongly typed mandatory key",
+			`[` + division + `, {"type": "Division", "args": {"nbdvdigits": "x", "nbdrdigits": 1, "nbqdigits": 2}, "nbprobs": 1}]`,
+			"$[1].args.nbdvdigits:"},
+		{"wrongly typed optional key",
+			`[{"type": "Division", "args": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2, "style": 4}, "nbprobs": 1}]`,
+			"$[0].args.style:"},
+		{"schema violation",
+			`[{"type": "Division", "args": {}, "nbprobs": -1}]`,
+			"$[0].nbprobs:"},
+		{"wrongly typed avoidrepeat",
+			`[` + division + `, {"type": "Division", "args": {}, "nbprobs": 1, "avoidrepeat": 1}]`,
+			"$[1].avoidrepeat:"},
+	}
+	for _, test := range tests {
+		_, err := Unmarshall([]byte(test.request))
+		if err == nil {
+			t.Errorf("%v: the request was accepted", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.path) {
+			t.Errorf("%v: expected an error with the path '%v' but got '%v'", test.name, test.path, err)
+		}
+	}
+}
+
+// correct requests are accepted, also those where the mandatory keys are
+// given by the difficulty level
+func TestUnmarshallValidRequest(t *testing.T) {
+
+	requests := []string{
+		`[{"type": "division", "args": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2}, "nbprobs": 2}]`,
+		`[{"type": "BasicOperation", "args": {"level": 3}, "nbprobs": 2}]`,
+	}
+	for _, request := range requests {
+		problems, err := Unmarshall([]byte(request))
+		if err != nil {
+			t.Errorf("the request %v was rejected: %v", request, err)
+			continue
+		}
+		if len(problems) != 1 || problems[0].GetNbProbs() != 2 {
+			t.Errorf("expected one master problem with 2 problems but got %v", problems)
+			continue
+		}
+		if _, err := GenerateProblems(context.Background(), problems); err != nil {
+			t.Errorf("the problems of the request %v could not be generated: %v", request, err)
+		}
+	}
+}
+
+// seeds are read exactly, also those that can not be represented as
+// floating-point numbers
+func TestUnmarshallLargeSeed(t *testing.T) {
+
+	for _, seed := range []string{"9007199254740993", `"9007199254740993"`, "-9223372036854775807"} {
+		problems, err := Unmarshall([]byte(`[{"type": "Division", "args": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2}, "nbprobs": 1, "seed": ` + seed + `}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := strings.Trim(seed, `"`); strconv.FormatInt(problems[0].Seed, 10) != expected {
+			t.Errorf("expected the seed %v but got %v", expected, problems[0].Seed)
+		}
+	}
+}
+
+// when repetitions are avoided, no two consecutive problems of the same block
+// are identical, also for problem types whose arguments are masked
+func TestAvoidRepeat(t *testing.T) {
+
+	blocks := []string{
+		`{"type": "Clock", "args": {"type": 0, "granularity": "hour"}, "nbprobs": 50, "avoidrepeat": true, "seed": %v}`,
+		`{"type": "BasicOperation", "args": {"type": 0, "operator": "+", "nboperands": 2, "nbdigitsop": 1, "nbdigitsrslt": 1}, "nbprobs": 50, "avoidrepeat": true, "seed": %v}`,
+		`{"type": "Division", "args": {"nbdvdigits": 2, "nbdrdigits": 1, "nbqdigits": 1}, "nbprobs": 50, "avoidrepeat": true, "seed": %v}`,
+	}
+	for seed := 1; seed <= 5; seed++ {
+		for _, block := range blocks {
+			problems, err := Unmarshall([]byte("[" + fmt.Sprintf(block, seed) + "]"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			generated, err := GenerateProblems(context.Background(), problems)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for idx := 1; idx < len(generated); idx++ {
+				if sameProblem(generated[idx-1], generated[idx]) {
+					t.Errorf("the problems #%v and #%v of type %v generated with seed %v are the same: %v",
+						idx-1, idx, generated[idx].Probtype, seed, generated[idx])
+				}
+			}
+		}
+	}
+}
+
+// problems are the same only if both their arguments and solutions are
+func TestSameProblem(t *testing.T) {
+
+	six := ProblemJSON{Args: []string{"?"}, Solution: []string{"6:00"}}
+	twelve := ProblemJSON{Args: []string{"?"}, Solution: []string{"12:00"}}
+	if sameProblem(six, twelve) {
+		t.Errorf("problems with different solutions are considered the same")
+	}
+	if !sameProblem(six, six) {
+		t.Errorf("a problem is not considered the same than itself")
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
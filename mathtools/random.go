@@ -0,0 +1,108 @@
+// -*- coding: utf-8 -*-
+// random.go
+//
+// Description: Provides the functions of master files that draw random values,
+// so that ad-hoc problems and varied headings can be written without a
+// dedicated problem type
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 13:48:09.731052417 (1792158489)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"text/template"
+)
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the functions registered in the templates of master files which draw
+// random values with the given source of random numbers. If none is given, a
+// new one is created every time they are invoked:
+//
+// rand: a random integer in the interval [lower, upper], e.g., {{rand 10 99}}
+// choice: one of the given items chosen at random, e.g., {{choice "a" "b"}}
+// shuffle: the given items in random order, e.g., {{range shuffle 1 2 3}}
+//
+// Both choice and shuffle accept also a single slice with all items
+func randomFuncs(rnd *rand.Rand) template.FuncMap {
+
+	// return the source of random numbers to use
+	source := func() *rand.Rand {
+		if rnd == nil {
+			return newRand(0)
+		}
+		return rnd
+	}
+
+	return template.FuncMap{
+		"rand": func(lower, upper int) (int, error) {
+			if lower > upper {
+				return 0, fmt.Errorf("Invalid rand call. It is not possible to draw a number in the range [%v, %v]", lower, upper)
+			}
+			return randRange(source(), lower, upper), nil
+		},
+		"choice": func(values ...interface{}) (interface{}, error) {
+			items := randomItems(values)
+			if len(items) == 0 {
+				return nil, errors.New("Invalid choice call. At least one item should be given")
+			}
+			return items[source().Intn(len(items))], nil
+		},
+		"shuffle": func(values ...interface{}) []interface{} {
+			items := append([]interface{}{}, randomItems(values)...)
+			source().Shuffle(len(items), func(i, j int) {
+				items[i], items[j] = items[j], items[i]
+			})
+			return items
+		},
+	}
+}
+
+// return the items given to the random functions of master files. If only one
+// slice is given, then its elements are returned; otherwise, the values are
+// returned as they are
+func randomItems(values []interface{}) []interface{} {
+
+	if len(values) != 1 {
+		return values
+	}
+	slice := reflect.ValueOf(values[0])
+	if slice.Kind() != reflect.Slice && slice.Kind() != reflect.Array {
+		return values
+	}
+	items := make([]interface{}, slice.Len())
+	for idx := range items {
+		items[idx] = slice.Index(idx).Interface()
+	}
+	return items
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- MasterFile
+
+// return a copy of the given template whose random functions draw values with
+// the source of random numbers of this master file, so that the same values
+// are drawn when generating the sheet and its answer key
+func (masterFile MasterFile) bind(t *template.Template) (*template.Template, error) {
+
+	bound, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return bound.Funcs(randomFuncs(masterFile.draws)), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
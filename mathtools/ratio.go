@@ -0,0 +1,333 @@
+// -*- coding: utf-8 -*-
+// ratio.go
+//
+// Description: Provides services for automatically creating problems with
+// simple ratios
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:25:31.204718335 (1792110331)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of ratios: "second" or "first". In the first
+// case, the scaled quantity of the first term is shown and the student has to
+// guess the scaled quantity of the second term; in the latter, it is the other
+// way round
+const (
+	RTSECOND int = iota
+	RTFIRST
+)
+
+// the TikZ code for generating ratios is shown next. Note that it makes use of
+// LaTeX/TikZ components
+const latexRatioCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the ratio
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZRatioCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Ratio -----------------------------------------------------------
+
+      % the ratio is shown in the form a : b, each term written to the right
+      % of the previous one
+      {{.Term1}}
+      {{.Colon1}}
+      {{.Term2}}
+
+      % --- Equal -----------------------------------------------------------
+
+      {{.Equal}}
+
+      % --- Scaled quantities -----------------------------------------------
+
+      % the scaled quantities are shown in the same form than the ratio. One
+      % of them is shown within an empty box
+      {{.Quantity1}}
+      {{.Colon2}}
+      {{.Quantity2}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A ratio consists of two terms a:b, each one randomly chosen in the interval
+// [geq, leq], and two scaled quantities which result from multiplying both
+// terms by a factor randomly chosen in the interval [scalegeq, scaleleq].
+// There are two types of ratios:
+//
+//    0: the second scaled quantity has to be guessed by the student
+//    1: the first scaled quantity has to be guessed instead
+type ratio struct {
+	rttype             int
+	geq, leq           int
+	scalegeq, scaleleq int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw ratios
+type ratioTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the ratio consists of two terms separated by a colon
+	Term1, Colon1, Term2 components.CoordinatedText
+
+	// the equal symbol is located between the ratio and the scaled quantities
+	Equal components.CoordinatedText
+
+	// the scaled quantities are drawn much in the same way than the ratio
+	Quantity1, Colon2, Quantity2 components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- ratioTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz ratioTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("ratioTikZ").Parse(tikZRatioCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- ratio
+
+// return the instance of a specific ratio problem that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with four items: both terms of the ratio and both scaled
+// quantities. One of the scaled quantities is shown as "?" in the arguments as
+// it has to be guessed by the student
+func (rt ratio) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+
+	// First, verify that parameters are correct
+	if rt.geq < 1 || rt.geq > rt.leq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate the terms of a ratio in the range [%v, %v]",
+			rt.geq, rt.leq)
+	}
+	if rt.scalegeq < 2 || rt.scalegeq > rt.scaleleq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate scale factors in the range [%v, %v]",
+			rt.scalegeq, rt.scaleleq)
+	}
+
+	// randomly determine both terms of the ratio and the scale. Because the
+	// scaled quantities are computed by multiplying both terms by the same
+	// integer, the answer is necessarily a whole number
+	term1 := rt.geq + rnd.Intn(1+rt.leq-rt.geq)
+	term2 := rt.geq + rnd.Intn(1+rt.leq-rt.geq)
+	scale := rt.scalegeq + rnd.Intn(1+rt.scaleleq-rt.scalegeq)
+
+	// create two slices: one for storing the instance of this problem in the
+	// order: both terms of the ratio and both scaled quantities, where the part
+	// that should be filled in by the student is marked with a question mark
+	// "?"; and another one with the full solution
+	solution := []string{
+		strconv.FormatInt(int64(term1), 10),
+		strconv.FormatInt(int64(term2), 10),
+		strconv.FormatInt(int64(scale*term1), 10),
+		strconv.FormatInt(int64(scale*term2), 10),
+	}
+	args := make([]string, 4)
+	copy(args, solution)
+
+	// and now mask the requested scaled quantity
+	if rt.rttype == RTSECOND {
+		args[3] = "?"
+	} else {
+		args[2] = "?"
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "Ratio",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this ratio using TikZ components
+func (rt ratio) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the values of the ratio and the scaled
+	//              quantities. For this, the service that generates problems
+	//              is the one that can marshal them into JSON format. A
+	//              question mark is a number that has to be guessed by the
+	//              student
+	instance, err := rt.next(annotated(rt))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid ratio: %v", err)
+	}
+
+	// compute the number of digits required to draw all numbers so that all
+	// of them are equally wide
+	nbdigits := 0.0
+	for _, item := range instance.Solution {
+		value, _ := helpers.Atoi(item)
+		nbdigits = helpers.Max(nbdigits, float64(helpers.NbDigits(value)))
+	}
+
+	// all numbers are shown within the same width which consists of the number
+	// of digits plus one additional digit to each side
+	width := 2.0 + nbdigits
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// the following function returns the text to show in the cell of the i-th
+	// argument: either an empty box, if the number has to be guessed, or the
+	// number itself. Cells are located to the right of the given reference
+	cell := func(label, reference string, shift float64, i int) components.CoordinatedText {
+		options, text := "", ""
+		if instance.Args[i] == "?" {
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width))
+			text = rt.answer(instance.Solution[i])
+		} else {
+			text = `\huge ` + instance.Args[i]
+		}
+		return components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(%v) + (%v\zerowidth, 0.0)$`,
+					reference, helpers.Ftoa(shift))),
+				label),
+			options, text)
+	}
+
+	// likewise, symbols are located to the right of the given reference
+	symbol := func(label, reference, text string) components.CoordinatedText {
+		return components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(%v) + (%v\zerowidth, 0.0)$`,
+					reference, helpers.Ftoa(1.0+width/2.0))),
+				label),
+			"", text)
+	}
+
+	// -- ratio
+	term1 := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip)$`,
+				helpers.Ftoa(width/2.0))),
+			"term1"),
+		"", `\huge `+instance.Args[0])
+	colon1 := symbol("colon1", "term1", `\huge $:$`)
+	term2 := cell("term2", "colon1", 1.0+width/2.0, 1)
+
+	// -- equal
+	equal := symbol("equal", "term2", `\huge $=$`)
+
+	// -- scaled quantities
+	quantity1 := cell("quantity1", "equal", 1.0+width/2.0, 2)
+	colon2 := symbol("colon2", "quantity1", `\huge $:$`)
+	quantity2 := cell("quantity2", "colon2", 1.0+width/2.0, 3)
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(quantity2) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip)$`,
+			helpers.Ftoa(0.5+width/2.0))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the ratio
+	rtPicture := ratioTikZ{
+		Bottom:    bottom,
+		Term1:     term1,
+		Colon1:    colon1,
+		Term2:     term2,
+		Equal:     equal,
+		Quantity1: quantity1,
+		Colon2:    colon2,
+		Quantity2: quantity2,
+		BBox:      bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return rtPicture.execute()
+}
+
+// Return TikZ code that represents a ratio
+func (rt ratio) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this ratio
+	tpl, err := template.New("ratio").Parse(latexRatioCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, rt); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,731 @@
+// -*- coding: utf-8 -*-
+// registry.go
+//
+// Description: Provides a registry of all the problem types supported, so that
+// new problem types can be added without modifying this package
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:50:25.824407100 (1792111825)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// Every problem type has to provide the following services: verifying the
+// dictionary used to define it, generating new instances in JSON format once
+// it has been verified, and drawing a given instance in LaTeX/TikZ format,
+// either with or without its answers
+type ProblemGenerator interface {
+	Verify(dict map[string]interface{}) error
+	GenerateJSON(rnd *rand.Rand) (ProblemJSON, error)
+	TikZ(instance ProblemJSON, answers bool) (string, error)
+}
+
+// Every problem type is registered with its description and a function that
+// creates new generators of problems of this type
+type registryEntry struct {
+	description ProblemType
+	factory     func() ProblemGenerator
+}
+
+// The problem types provided by this package are adapted to the interface of
+// problem generators with two functions: one that verifies the dictionary and
+// returns an instance ready to generate problems, and another which draws the
+// problems of a verified instance with the given recorder
+type builtinGenerator struct {
+	verify func(dict map[string]interface{}) (generator, error)
+	draw   func(instance generator, r recorder) (string, error)
+
+	// the instance created after verifying a dictionary
+	instance generator
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// All problem types are registered here indexed by their name in upper case,
+// so that they are looked up regardless of the case
+var registry = make(map[string]registryEntry)
+var registryMutex sync.RWMutex
+
+// functions
+// ----------------------------------------------------------------------------
+
+// register a new problem type with the given description and a function that
+// creates new generators of problems of this type. Problem types registered
+// this way can be used in JSON problems and, if they are marked as Master,
+// also in master files with the method Problem. It is an error to register
+// the same name twice
+func Register(description ProblemType, factory func() ProblemGenerator) error {
+
+	if description.Name == "" {
+		return errors.New("problem types can not be registered without a name")
+	}
+	if factory == nil {
+		return fmt.Errorf("the problem type '%v' can not be registered without a factory", description.Name)
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	key := strings.ToUpper(description.Name)
+	if _, ok := registry[key]; ok {
+		return fmt.Errorf("the problem type '%v' has already been registered", description.Name)
+	}
+	registry[key] = registryEntry{description: description, factory: factory}
+	return nil
+}
+
+// return the registry entry of the given problem type, regardless of the case
+// of its name, and whether it exists or not
+func lookup(name string) (registryEntry, bool) {
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	entry, ok := registry[strings.ToUpper(name)]
+	return entry, ok
+}
+
+// register all the problem types provided by this package
+func init() {
+
+	builtins := []struct {
+		description ProblemType
+		verify      func(dict map[string]interface{}) (generator, error)
+		draw        func(instance generator, r recorder) (string, error)
+	}{
+		{
+			description: ProblemType{
+				Name:      "Angle",
+				Mandatory: angleMandatory,
+				Optional:  angleOptional,
+				Example: map[string]interface{}{
+					"type": 0, "step": 5, "protractor": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyAngleDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				an := instance.(angle)
+				an.recorder = r
+				return an.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "BarChart",
+				Mandatory: barChartMandatory,
+				Optional:  barChartOptional,
+				Example: map[string]interface{}{
+					"categories": "apples, pears, plums, grapes", "geq": 0, "leq": 10,
+					"mode": "read", "questions": "value, most, difference",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyBarChartDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				bc := instance.(barChart)
+				bc.recorder = r
+				return bc.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "BasicOperation",
+				Mandatory: basicOperationMandatory,
+				Optional:  basicOperationOptional,
+				Example: map[string]interface{}{
+					"type": 1, "operator": "-", "nboperands": 3, "nbdigitsop": 2, "nbdigitsrslt": 1,
+					"scaffold": false, "carrybox": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyBasicOperationDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				bo := instance.(basicOperation)
+				bo.recorder = r
+				return bo.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Clock",
+				Mandatory: clockMandatory,
+				Example: map[string]interface{}{
+					"type": 0, "granularity": "quarter",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyClockDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				clk := instance.(clock)
+				clk.recorder = r
+				return clk.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "CountingObjects",
+				Mandatory: countingObjectsMandatory,
+				Optional:  countingObjectsOptional,
+				Example: map[string]interface{}{
+					"geq": 3, "leq": 12, "symbol": "star", "grouped": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyCountingObjectsDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				co := instance.(countingObjects)
+				co.recorder = r
+				return co.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Division",
+				Mandatory: divisionMandatory,
+				Optional:  divisionOptional,
+				Example: map[string]interface{}{
+					"nbdvdigits": 5, "nbdrdigits": 2, "nbqdigits": 3, "extended": false, "style": "box",
+					"remainder": "random", "remainderbox": false, "nbdecimals": 0, "table": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyDivisionDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				div := instance.(division)
+				div.recorder = r
+				return div.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "ElapsedTime",
+				Mandatory: elapsedTimeMandatory,
+				Optional:  elapsedTimeOptional,
+				Example: map[string]interface{}{
+					"type": 0, "granularity": "five", "from": "08:00", "to": "21:00", "maxduration": 120,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyElapsedTimeDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				et := instance.(elapsedTime)
+				et.recorder = r
+				return et.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "EquivalentFraction",
+				Mandatory: equivalentFractionMandatory,
+				Example: map[string]interface{}{
+					"type": 0, "dengeq": 2, "denleq": 9, "scalegeq": 2, "scaleleq": 5,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyEquivalentFractionDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				ef := instance.(equivalentFraction)
+				ef.recorder = r
+				return ef.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "FactFamily",
+				Mandatory: factFamilyMandatory,
+				Optional:  factFamilyOptional,
+				Example: map[string]interface{}{
+					"operator": "*", "geq": 2, "leq": 9, "facts": true,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyFactFamilyDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				ff := instance.(factFamily)
+				ff.recorder = r
+				return ff.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "FDPConversion",
+				Mandatory: fdpConversionMandatory,
+				Optional:  fdpConversionOptional,
+				Example: map[string]interface{}{
+					"from": "fraction", "to": "percent", "denleq": 20,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyFDPConversionDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				fdp := instance.(fdpConversion)
+				fdp.recorder = r
+				return fdp.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "LinearEquation",
+				Mandatory: linearEquationMandatory,
+				Optional:  linearEquationOptional,
+				Example: map[string]interface{}{
+					"type": 1, "geq": 0, "leq": 20, "coefleq": 9, "negative": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyLinearEquationDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				le := instance.(linearEquation)
+				le.recorder = r
+				return le.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "MagicSquare",
+				Mandatory: magicSquareMandatory,
+				Optional:  magicSquareOptional,
+				Example: map[string]interface{}{
+					"size": 3, "geq": 15, "leq": 45, "nbrevealed": 4,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyMagicSquareDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				ms := instance.(magicSquare)
+				ms.recorder = r
+				return ms.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "MathCrossword",
+				Mandatory: mathCrosswordMandatory,
+				Optional:  mathCrosswordOptional,
+				Example: map[string]interface{}{
+					"size": 2, "geq": 1, "leq": 20, "operators": "+-", "nbmasked": 4,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyMathCrosswordDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				mc := instance.(mathCrossword)
+				mc.recorder = r
+				return mc.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "MixedDrill",
+				Mandatory: mixedDrillMandatory,
+				Optional:  mixedDrillOptional,
+				Example: map[string]interface{}{
+					"operations": "+:2:2, -:2:2, *:1:2", "count": 12, "layout": "horizontal",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyMixedDrillDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				md := instance.(mixedDrill)
+				md.recorder = r
+				return md.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Money",
+				Mandatory: moneyMandatory,
+				Optional:  moneyOptional,
+				Example: map[string]interface{}{
+					"type": 1, "geq": 1, "leq": 20, "currency": "EUR", "decimals": true, "nbitems": 2, "coins": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyMoneyDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				m := instance.(money)
+				m.recorder = r
+				return m.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "MultiplicationTable",
+				Mandatory: multiplicationTableMandatory,
+				Optional:  multiplicationTableOptional,
+				Example: map[string]interface{}{
+					"type": 0, "nbdigits": 1, "geq": 1, "leq": 10, "inv": "true", "sorted": "false",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyMultiplicationTableDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				mt := instance.(multiplicationTable)
+				mt.recorder = r
+				return mt.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "MysteryOperation",
+				Mandatory: mysteryOperationMandatory,
+				Optional:  mysteryOperationOptional,
+				Example: map[string]interface{}{
+					"operator": "+", "nbdigits1": 5, "nbdigits2": 5, "nbdigitsanswer": 6,
+					"nbmasked1": 2, "nbmasked2": 1, "nbmaskedanswer": 1,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyMysteryOperationDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				mo := instance.(mysteryOperation)
+				mo.recorder = r
+				return mo.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "NumberClassification",
+				Mandatory: numberClassificationMandatory,
+				Optional:  numberClassificationOptional,
+				Example: map[string]interface{}{
+					"type": 1, "geq": 1, "leq": 50, "nbnumbers": 15, "multiple": 3,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyNumberClassificationDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				cl := instance.(numberClassification)
+				cl.recorder = r
+				return cl.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "NumberComparison",
+				Mandatory: numberComparisonMandatory,
+				Optional:  numberComparisonOptional,
+				Example: map[string]interface{}{
+					"nbdigits": 3, "nbitems": 2, "negative": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyNumberComparisonDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				nc := instance.(numberComparison)
+				nc.recorder = r
+				return nc.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "NumberForm",
+				Mandatory: numberFormMandatory,
+				Optional:  numberFormOptional,
+				Example: map[string]interface{}{
+					"from": "standard", "to": "words", "nbdigits": 4, "locale": "en",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyNumberFormDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				nf := instance.(numberForm)
+				nf.recorder = r
+				return nf.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "NumberLine",
+				Mandatory: numberLineMandatory,
+				Optional:  numberLineOptional,
+				Example: map[string]interface{}{
+					"type": 0, "geq": 0, "leq": 50, "step": 5, "nbticks": 8, "orientation": "horizontal",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyNumberLineDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				nl := instance.(numberLine)
+				nl.recorder = r
+				return nl.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "NumberPyramid",
+				Mandatory: numberPyramidMandatory,
+				Optional:  numberPyramidOptional,
+				Example: map[string]interface{}{
+					"height": 4, "geq": 1, "leq": 10, "reveal": "random", "nbrevealed": 4,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyNumberPyramidDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				np := instance.(numberPyramid)
+				np.recorder = r
+				return np.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Percentage",
+				Mandatory: percentageMandatory,
+				Optional:  percentageOptional,
+				Example: map[string]interface{}{
+					"type": 0, "geq": 10, "leq": 200, "pctgeq": 5, "pctleq": 100, "decimals": 0,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyPercentageDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				pc := instance.(percentage)
+				pc.recorder = r
+				return pc.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "PerimeterArea",
+				Mandatory: perimeterAreaMandatory,
+				Example: map[string]interface{}{
+					"type": 1, "shape": "rectangle", "geq": 2, "leq": 12,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyPerimeterAreaDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				pa := instance.(perimeterArea)
+				pa.recorder = r
+				return pa.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Pictogram",
+				Mandatory: pictogramMandatory,
+				Optional:  pictogramOptional,
+				Example: map[string]interface{}{
+					"categories": "cats, dogs, birds, fish", "geq": 1, "leq": 12,
+					"chart": "tally", "questions": "most, least, total",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyPictogramDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				pic := instance.(pictogram)
+				pic.recorder = r
+				return pic.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "PlaceValue",
+				Mandatory: placeValueMandatory,
+				Optional:  placeValueOptional,
+				Example: map[string]interface{}{
+					"type": 0, "nbdigits": 4, "masked": "hundreds, units",
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyPlaceValueDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				pv := instance.(placeValue)
+				pv.recorder = r
+				return pv.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "PrimeFactorization",
+				Mandatory: primeFactorizationMandatory,
+				Optional:  primeFactorizationOptional,
+				Example: map[string]interface{}{
+					"geq": 12, "leq": 200, "maxfactors": 4,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyPrimeFactorizationDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				pf := instance.(primeFactorization)
+				pf.recorder = r
+				return pf.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Ratio",
+				Mandatory: ratioMandatory,
+				Example: map[string]interface{}{
+					"type": 0, "geq": 1, "leq": 5, "scalegeq": 2, "scaleleq": 4,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyRatioDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				rt := instance.(ratio)
+				rt.recorder = r
+				return rt.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Rounding",
+				Mandatory: roundingMandatory,
+				Optional:  roundingOptional,
+				Example: map[string]interface{}{
+					"nbdigits": 4, "place": "hundred", "highlight": true,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyRoundingDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				rd := instance.(rounding)
+				rd.recorder = r
+				return rd.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "Sequence",
+				Mandatory: sequenceMandatory,
+				Optional:  sequenceOptional,
+				Example: map[string]interface{}{
+					"type": 0, "nbitems": 5, "geq": 100, "leq": 999, "pattern": "arithmetic", "step": 1, "start-multiple": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifySequenceDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				seq := instance.(sequence)
+				seq.recorder = r
+				return seq.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "ShadedFraction",
+				Mandatory: shadedFractionMandatory,
+				Optional:  shadedFractionOptional,
+				Example: map[string]interface{}{
+					"type": 0, "dengeq": 2, "denleq": 8, "shape": "pie", "contiguous": false,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyShadedFractionDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				sf := instance.(shadedFraction)
+				sf.recorder = r
+				return sf.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "UnitConversion",
+				Mandatory: unitConversionMandatory,
+				Optional:  unitConversionOptional,
+				Example: map[string]interface{}{
+					"quantity": "length", "direction": "both", "geq": 1, "leq": 100, "decimals": 1,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyUnitConversionDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				uc := instance.(unitConversion)
+				uc.recorder = r
+				return uc.execute()
+			},
+		},
+		{
+			description: ProblemType{
+				Name:      "WordProblem",
+				Mandatory: wordProblemMandatory,
+				Optional:  wordProblemOptional,
+				Example: map[string]interface{}{
+					"operator": "+", "geq": 10, "leq": 99,
+				},
+				Master: true,
+			},
+			verify: func(dict map[string]interface{}) (generator, error) { return verifyWordProblemDict(dict) },
+			draw: func(instance generator, r recorder) (string, error) {
+				wp := instance.(wordProblem)
+				wp.recorder = r
+				return wp.execute()
+			},
+		},
+	}
+
+	for _, builtin := range builtins {
+		verify, draw := builtin.verify, builtin.draw
+		if err := Register(builtin.description, func() ProblemGenerator {
+			return &builtinGenerator{verify: verify, draw: draw}
+		}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- builtinGenerator
+
+// verify the given dictionary and, if it is correct, keep the instance created
+// from it for generating new problems
+func (bg *builtinGenerator) Verify(dict map[string]interface{}) error {
+
+	instance, err := bg.verify(dict)
+	if err != nil {
+		return err
+	}
+	bg.instance = instance
+	return nil
+}
+
+// return a new problem in JSON format along with its metadata generated with
+// the given source of random numbers. The receiver must have been verified
+// before
+func (bg *builtinGenerator) GenerateJSON(rnd *rand.Rand) (ProblemJSON, error) {
+
+	if bg.instance == nil {
+		return ProblemJSON{}, errors.New("problems can not be generated before verifying their dictionary")
+	}
+	return generateAnnotatedJSONProblem(bg.instance, rnd)
+}
+
+// return the LaTeX/TikZ code that draws the given instance, either with or
+// without its answers. The receiver must have been verified before
+func (bg *builtinGenerator) TikZ(instance ProblemJSON, answers bool) (string, error) {
+
+	if bg.instance == nil {
+		return "", errors.New("problems can not be drawn before verifying their dictionary")
+	}
+
+	// the instance is drawn by replaying it
+	index := 0
+	return bg.draw(bg.instance, recorder{
+		solutions: &[]ProblemJSON{instance},
+		replay:    &index,
+		blank:     !answers,
+	})
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
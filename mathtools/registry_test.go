@@ -0,0 +1,81 @@
+// -*- coding: utf-8 -*-
+// registry_test.go
+//
+// Description: Tests of all the problem types registered
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 10:21:07.518204391 (1792181067)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/clinaresl/mathprob/helpers"
+)
+
+// every problem type registered accepts its own example, and problems
+// generated from it with a fixed seed are always the same and can be drawn
+// both with and without answers
+func TestRegisteredTypes(t *testing.T) {
+
+	types := SupportedTypes()
+	if len(types) == 0 {
+		t.Fatal("no problem types are registered")
+	}
+	for _, problemType := range types {
+		t.Run(problemType.Name, func(t *testing.T) {
+
+			// the example sets all the mandatory keys
+			if err := helpers.VerifyArgs(problemType.Example, problemType.Mandatory); err != nil {
+				t.Fatalf("invalid example: %v", err)
+			}
+
+			// generate a few problems from two different generators with the
+			// same seed
+			generate := func() (ProblemGenerator, []ProblemJSON) {
+				gen := lookupFactory(t, problemType.Name)
+				if err := gen.Verify(problemType.Example); err != nil {
+					t.Fatalf("the example %v was rejected: %v", problemType.Example, err)
+				}
+				rnd := rand.New(rand.NewSource(1))
+				var problems []ProblemJSON
+				for i := 0; i < 5; i++ {
+					problem, err := gen.GenerateJSON(rnd)
+					if err != nil {
+						t.Fatal(err)
+					}
+					problems = append(problems, problem)
+				}
+				return gen, problems
+			}
+			gen, problems := generate()
+			if _, others := generate(); !reflect.DeepEqual(problems, others) {
+				t.Errorf("expected the problems %v with the same seed but got %v", problems, others)
+			}
+
+			// and draw all of them
+			for _, problem := range problems {
+				if problem.Probtype == "" || len(problem.Args) == 0 {
+					t.Errorf("the problem %+v has no type or arguments", problem)
+				}
+				for _, answers := range []bool{false, true} {
+					if tikz, err := gen.TikZ(problem, answers); err != nil {
+						t.Errorf("the problem %+v could not be drawn with answers=%v: %v", problem, answers, err)
+					} else if tikz == "" {
+						t.Errorf("the problem %+v was drawn with answers=%v as an empty string", problem, answers)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,312 @@
+// -*- coding: utf-8 -*-
+// rounding.go
+//
+// Description: Provides services for automatically creating problems of
+// rounding numbers to a given place
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:05:51.473761796 (1792112751)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Numbers halfway between two multiples of the place they are rounded to can
+// be rounded up, to the even multiple or away from zero
+const (
+	ROUNDUP   string = "up"
+	ROUNDEVEN string = "even"
+	ROUNDAWAY string = "away"
+)
+
+// the TikZ code for generating rounding problems is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexRoundingCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the rounding problem
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZRoundingCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Number ----------------------------------------------------------
+
+      % the number is shown along with the place it has to be rounded to
+      {{.Number}}
+      {{.Place}}
+
+      % --- Answer ----------------------------------------------------------
+
+      % the rounded number is written in a box to the right of the number
+      {{.Approx}}
+      {{.Answer}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A rounding problem consists of a random number with the given number of
+// digits which has to be rounded to the nearest place given, e.g., "ten" or
+// "hundred", breaking ties with the given rule. Optionally, the digit of the
+// place is highlighted
+type rounding struct {
+	nbdigits  int
+	place     string
+	tiebreak  string
+	highlight bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw rounding
+// problems
+type roundingTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the number is shown with the place it has to be rounded to below it
+	Number, Place components.CoordinatedText
+
+	// the rounded number is written in a box to the right of the number
+	Approx, Answer components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// Names of the places numbers can be rounded to, starting from the tens
+var roundingPlaces = []string{
+	"ten", "hundred", "thousand", "ten thousand", "hundred thousand"}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the position of the given place among the places numbers can be
+// rounded to, starting from one for the tens, and zero if it does not exist
+func roundingPlace(place string) int {
+
+	for idx, name := range roundingPlaces {
+		if name == place {
+			return 1 + idx
+		}
+	}
+	return 0
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- roundingTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz roundingTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("roundingTikZ").Parse(tikZRoundingCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- rounding
+
+// return the instance of a specific rounding problem that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with three items: the number, the place it has to be
+// rounded to and the rounded number, which is shown as "?" in the arguments as
+// it has to be guessed by the student
+func (rd rounding) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct. Numbers can only be rounded
+	// to places lower than their number of digits
+	place := roundingPlace(rd.place)
+	if place == 0 || place >= rd.nbdigits {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to round numbers with %v digits to the nearest %v",
+			rd.nbdigits, rd.place)
+	}
+
+	// randomly determine the number and round it breaking ties as requested
+	number := helpers.RandN(rnd, rd.nbdigits)
+	scale := 1
+	for i := 0; i < place; i++ {
+		scale *= 10
+	}
+	rounded := helpers.Round(number, scale, rd.tiebreak)
+
+	// create two slices: one for storing the instance of this problem where the
+	// rounded number is marked with a question mark "?"; and another one with
+	// the full solution
+	solution := []string{
+		strconv.FormatInt(int64(number), 10),
+		rd.place,
+		strconv.FormatInt(int64(rounded), 10),
+	}
+	args := []string{solution[0], solution[1], "?"}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "Rounding",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this rounding problem using TikZ
+// components
+func (rd rounding) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the number to round. For this, the
+	//              service that generates problems is the one that can marshal
+	//              them into JSON format. A question mark is a number that has
+	//              to be guessed by the student
+	instance, err := rd.next(annotated(rd))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid rounding problem: %v", err)
+	}
+
+	// all numbers are shown within the same width which consists of the
+	// number of digits plus one additional digit to each side
+	width := 2.0 + float64(rd.nbdigits)
+
+	// both the number and the answer are vertically centered at the same
+	// height, above the name of the place
+	height := `1.5\baselineskip + 0.5\zeroheight`
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- number: if requested, the digit of the place it has to be rounded to
+	//            is highlighted
+	text := instance.Args[0]
+	if rd.highlight {
+		idx := len(text) - 1 - roundingPlace(rd.place)
+		text = fmt.Sprintf(`%v\underline{\textcolor{red}{%v}}%v`, text[:idx], text[idx:idx+1], text[idx+1:])
+	}
+	number := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v)$`,
+				helpers.Ftoa(0.5+width/2.0), height)),
+			"number"),
+		"", `\huge `+text)
+	place := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.25\baselineskip)$`,
+				helpers.Ftoa(0.5+width/2.0))),
+			"place"),
+		"anchor=south", `\footnotesize nearest `+instance.Args[1])
+
+	// -- answer
+	approx := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v)$`,
+				helpers.Ftoa(1.25+width), height)),
+			"approx"),
+		"", `\huge $\approx$`)
+	answer := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v)$`,
+				helpers.Ftoa(2.0+1.5*width), height)),
+			"answer"),
+		fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+			helpers.Ftoa(width)),
+		rd.answer(instance.Solution[2]))
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 2\baselineskip + \zeroheight)$`,
+			helpers.Ftoa(2.5+2.0*width))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// rounding problem
+	rdPicture := roundingTikZ{
+		Bottom: bottom,
+		Number: number,
+		Place:  place,
+		Approx: approx,
+		Answer: answer,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return rdPicture.execute()
+}
+
+// Return TikZ code that represents a rounding problem
+func (rd rounding) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this rounding problem
+	tpl, err := template.New("rounding").Parse(latexRoundingCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, rd); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
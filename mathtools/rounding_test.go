@@ -0,0 +1,83 @@
+// -*- coding: utf-8 -*-
+// rounding_test.go
+//
+// Description: Tests of the generation of rounding problems
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 09:31:48.127640582 (1792179108)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// numbers halfway between two multiples of the place they are rounded to, e.g.,
+// 25 rounded to the nearest ten, are rounded with the given tiebreak, which
+// defaults to rounding them up
+func TestRoundingTiebreak(t *testing.T) {
+
+	tests := []struct {
+		tiebreak string
+		round    func(number int) int
+	}{
+		{"", func(number int) int { return number + 5 }},
+		{ROUNDUP, func(number int) int { return number + 5 }},
+		{ROUNDAWAY, func(number int) int { return number + 5 }},
+		{ROUNDEVEN, func(number int) int {
+			if ((number-5)/10)%2 == 0 {
+				return number - 5
+			}
+			return number + 5
+		}},
+	}
+	for _, test := range tests {
+		options := RoundingOptions{NbDigits: 2, Place: "ten", Tiebreak: test.tiebreak}
+		if err := options.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		rd := options.rounding()
+
+		// generate two-digit numbers until every tie has been seen
+		rnd := rand.New(rand.NewSource(1))
+		ties := make(map[int]bool)
+		for attempt := 0; attempt < 1000 && len(ties) < 9; attempt++ {
+			instance, err := rd.generateJSONProblem(rnd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			number, _ := strconv.Atoi(instance.Solution[0])
+			if number%10 != 5 {
+				continue
+			}
+			ties[number] = true
+			if rounded, expected := instance.Solution[2], strconv.Itoa(test.round(number)); rounded != expected {
+				t.Errorf("expected %v rounded to the nearest ten with tiebreak '%v' to be %v but got %v",
+					number, test.tiebreak, expected, rounded)
+			}
+		}
+		if len(ties) < 9 {
+			t.Errorf("expected all ties from 15 to 95 with tiebreak '%v' but got %v", test.tiebreak, ties)
+		}
+	}
+
+	// unknown tiebreaks are rejected, both in typed options and dictionaries
+	if err := (RoundingOptions{NbDigits: 2, Place: "ten", Tiebreak: "down"}).Validate(); err == nil {
+		t.Errorf("the tiebreak 'down' was accepted")
+	}
+	if _, err := verifyRoundingDict(map[string]interface{}{"nbdigits": 2, "place": "ten", "tiebreak": "down"}); err == nil {
+		t.Errorf("the tiebreak 'down' was accepted in a dictionary")
+	}
+	if rd, err := verifyRoundingDict(map[string]interface{}{"nbdigits": 2, "place": "ten", "tiebreak": ROUNDEVEN}); err != nil || rd.tiebreak != ROUNDEVEN {
+		t.Errorf("expected the tiebreak '%v' but got '%v' (%v)", ROUNDEVEN, rd.tiebreak, err)
+	}
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,193 @@
+// -*- coding: utf-8 -*-
+// schema.go
+//
+// Description: Provides the JSON Schemas of the JSON API and validates data
+// against them
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 08:02:47.610932117 (1792137767)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The JSON Schemas of the requests of problems and the problems generated are
+// distributed along with this package
+//
+//go:embed schemas/*.schema.json
+var schemas embed.FS
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the JSON Schema with the given name, which is assumed to exist
+func schema(name string) []byte {
+
+	data, err := schemas.ReadFile("schemas/" + name + ".schema.json")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// return the JSON Schema of requests of problems, i.e., the contents of JSON
+// problem files
+func RequestSchema() []byte {
+	return schema("request")
+}
+
+// return the JSON Schema of the problems generated in JSON format
+func ProblemSchema() []byte {
+	return schema("problem")
+}
+
+// return the name of the JSON type of the given value decoded with
+// encoding/json. Numbers without a fractional part are integers
+func schemaType(value interface{}) string {
+
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// verify that the given value decoded with encoding/json is valid according to
+// the given JSON Schema, and return an error with the path to the first
+// offending value otherwise. Only the keywords used in the schemas of this
+// package are supported: type, required, properties, additionalProperties,
+// items, minimum, maximum, minLength and pattern
+func validateValue(schema map[string]interface{}, value interface{}, path string) error {
+
+	// numbers decoded with json.Decoder.UseNumber are validated as floats
+	if number, ok := value.(json.Number); ok {
+		if float, err := number.Float64(); err == nil {
+			value = float
+		}
+	}
+
+	// first, verify the type of the value. Integers are also numbers
+	if types, ok := schema["type"]; ok {
+		var allowed []string
+		switch types := types.(type) {
+		case string:
+			allowed = []string{types}
+		case []interface{}:
+			for _, name := range types {
+				allowed = append(allowed, name.(string))
+			}
+		}
+		actual, found := schemaType(value), false
+		for _, name := range allowed {
+			found = found || name == actual || (name == "number" && actual == "integer")
+		}
+		if !found {
+			return fmt.Errorf("%v: expected %v but found %v", path, strings.Join(allowed, " or "), actual)
+		}
+	}
+
+	switch value := value.(type) {
+
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && value < minimum {
+			return fmt.Errorf("%v: %v is less than the minimum %v", path, value, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && value > maximum {
+			return fmt.Errorf("%v: %v is greater than the maximum %v", path, value, maximum)
+		}
+
+	case string:
+		if minLength, ok := schema["minLength"].(float64); ok && len([]rune(value)) < int(minLength) {
+			return fmt.Errorf("%v: the string '%v' is shorter than %v characters", path, value, minLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if matched, err := regexp.MatchString(pattern, value); err != nil || !matched {
+				return fmt.Errorf("%v: the string '%v' does not match the pattern '%v'", path, value, pattern)
+			}
+		}
+
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for idx, item := range value {
+				if err := validateValue(items, item, fmt.Sprintf("%v[%v]", path, idx)); err != nil {
+					return err
+				}
+			}
+		}
+
+	case map[string]interface{}:
+
+		// verify that all required properties are given, and then all
+		// properties in alphabetical order so that errors are always reported
+		// in the same order
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, key := range required {
+				if _, ok := value[key.(string)]; !ok {
+					return fmt.Errorf("%v: the key '%v' is missing", path, key)
+				}
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		properties, _ := schema["properties"].(map[string]interface{})
+		for _, key := range keys {
+			property, ok := properties[key].(map[string]interface{})
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					return fmt.Errorf("%v: the key '%v' is not allowed", path, key)
+				}
+				continue
+			}
+			if err := validateValue(property, value[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// verify that the given request of problems decoded with encoding/json is valid
+// according to its JSON Schema, and return an error with the path to the first
+// offending value otherwise. Paths start with "$", e.g., "$[2].nbprobs"
+func validateRequest(request interface{}) error {
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(RequestSchema(), &root); err != nil {
+		return err
+	}
+	return validateValue(root, request, "$")
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -22,11 +22,9 @@ package mathtools
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"math/rand"
 	"strconv"
 	"text/template"
-	"time"
 
 	"github.com/clinaresl/mathprob/helpers"
 	"github.com/clinaresl/mathprob/mathtools/components"
@@ -45,6 +43,14 @@ const (
 	SEQBOTH
 )
 
+// The numbers of a sequence follow any of the following patterns
+const (
+	SEQARITHMETIC  string = "arithmetic"
+	SEQGEOMETRIC   string = "geometric"
+	SEQALTERNATING string = "alternating"
+	SEQFIBONACCI   string = "fibonacci"
+)
+
 // the TikZ code for generating arbitrary sequences is shown next. Note that it
 // makes use of LaTeX/TikZ components
 const latexSequenceCode = `\begin{minipage}{\linewidth}
@@ -109,11 +115,31 @@ const tikZSequenceCode = `% --- Coordinates ------------------------------------
 // A Sequence consists of a type: "first", "last", "none" or "both" if either
 // the first number has to be given, the last one, none of them, or both
 // respectively. It consists of a number of items, each one greater or equal
-// than a given threshold and less or equal than another bound.
+// than a given threshold and less or equal than another bound. The numbers of
+// the sequence follow a pattern:
+//
+//    arithmetic: consecutive numbers differ in the given step, which can be
+//    negative to count backwards
+//    geometric: every number is the previous one multiplied by the step
+//    alternating: the step and the alternate step are added alternately
+//    fibonacci: every number is the sum of the two previous ones
+//
+// Optionally, the first number can be forced to be a multiple of the step.
+// Instead of the type, the items to hide can be explicitly given with their
+// indices (starting from zero) in mask or, alternatively, nbmasked items can be
+// randomly hidden
 type sequence struct {
-	seqtype  int
-	nbitems  int
-	geq, leq int
+	seqtype       int
+	nbitems       int
+	geq, leq      int
+	pattern       string
+	step, altstep int
+	startmultiple bool
+	mask          []int
+	nbmasked      int
+
+	// generated problems are recorded when solutions are requested
+	recorder
 }
 
 // A sequence is drawn using TikZ reusable components only. It cconsists of the
@@ -170,27 +196,172 @@ func (tikz sequenceTikZ) GetSequenceItems() string {
 
 // Return the LaTeX/TikZ commands that show up the picture stored in the
 // receiver
-func (seq sequenceTikZ) execute() string {
+func (seq sequenceTikZ) execute() (string, error) {
 
 	// create a template with the TikZ code for showing this picture
 	tpl, err := template.New("sequenceTikZ").Parse(tikZSequenceCode)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the execution of
 	// the template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, seq); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 // -- sequence
 
+// return all the numbers of this sequence according to its pattern using the
+// given source of random numbers. If they can not be generated within the
+// interval [geq, leq], an error is returned
+func (seq sequence) numbers(rnd *rand.Rand) ([]int, error) {
+
+	switch seq.pattern {
+	case SEQGEOMETRIC:
+		return seq.geometricNumbers(rnd)
+	case SEQFIBONACCI:
+		return seq.fibonacciNumbers(rnd)
+	}
+
+	// arithmetic and alternating sequences are computed by adding their steps
+	// to the first number. Arithmetic sequences always add the same step,
+	// whereas alternating sequences alternate between two different steps
+	step := seq.step
+	if step == 0 {
+		step = 1
+	}
+	offsets := make([]int, seq.nbitems)
+	for idx := 1; idx < seq.nbitems; idx++ {
+		offsets[idx] = offsets[idx-1] + step
+		if seq.pattern == SEQALTERNATING && idx%2 == 0 {
+			offsets[idx] = offsets[idx-1] + seq.altstep
+		}
+	}
+	minoffset, maxoffset := 0, 0
+	for _, offset := range offsets {
+		if offset < minoffset {
+			minoffset = offset
+		}
+		if offset > maxoffset {
+			maxoffset = offset
+		}
+	}
+
+	// If the interval [geq, leq] is too narrow to host all numbers, then
+	// immediately return an error
+	if seq.leq-seq.geq < maxoffset-minoffset {
+		return nil, fmt.Errorf("It is not possible to fit %v different numbers separated by %v taken from the range [%v, %v]",
+			seq.nbitems, step, seq.geq, seq.leq)
+	}
+
+	// The first number is taken from the following interval, which takes into
+	// account not only the interval [geq, leq] but also the number of items to
+	// display in the sequence and the steps between them
+	lower, upper := seq.geq-minoffset, seq.leq-maxoffset
+	var number1 int
+	if seq.startmultiple {
+
+		// in case the first number has to be a multiple of the step, then
+		// randomly pick up any multiple in the interval
+		magnitude := step
+		if magnitude < 0 {
+			magnitude = -magnitude
+		}
+		first, last := helpers.CeilDiv(lower, magnitude), helpers.FloorDiv(upper, magnitude)
+		if first > last {
+			return nil, fmt.Errorf("It is not possible to start a sequence of %v numbers separated by %v with a multiple of %v in the range [%v, %v]",
+				seq.nbitems, step, magnitude, seq.geq, seq.leq)
+		}
+		number1 = magnitude * (first + rnd.Int()%(1+last-first))
+	} else {
+		number1 = lower + rnd.Int()%(1+upper-lower)
+	}
+
+	numbers := make([]int, seq.nbitems)
+	for idx, offset := range offsets {
+		numbers[idx] = number1 + offset
+	}
+	return numbers, nil
+}
+
+// return the numbers of a geometric sequence, where every number results from
+// multiplying the previous one by the step. The first number is strictly
+// positive and it is chosen so that all numbers fall within [geq, leq]
+func (seq sequence) geometricNumbers(rnd *rand.Rand) ([]int, error) {
+
+	// compute the largest factor applied to the first number. Note that it is
+	// not necessary to go beyond the upper bound
+	factor := 1
+	for idx := 1; idx < seq.nbitems && factor <= seq.leq; idx++ {
+		factor *= seq.step
+	}
+
+	// and determine the interval of the first number
+	lower, upper := seq.geq, seq.leq/factor
+	if lower < 1 {
+		lower = 1
+	}
+	if seq.startmultiple {
+		lower = seq.step * helpers.CeilDiv(lower, seq.step)
+	}
+	if lower > upper {
+		return nil, fmt.Errorf("It is not possible to fit %v numbers multiplied by %v taken from the range [%v, %v]",
+			seq.nbitems, seq.step, seq.geq, seq.leq)
+	}
+
+	numbers := make([]int, seq.nbitems)
+	if seq.startmultiple {
+		numbers[0] = lower + seq.step*(rnd.Int()%(1+(upper-lower)/seq.step))
+	} else {
+		numbers[0] = lower + rnd.Int()%(1+upper-lower)
+	}
+	for idx := 1; idx < seq.nbitems; idx++ {
+		numbers[idx] = numbers[idx-1] * seq.step
+	}
+	return numbers, nil
+}
+
+// return the numbers of a Fibonacci-like sequence, where every number is the
+// sum of the two previous ones. The first two numbers are randomly chosen
+// until all numbers fall within [geq, leq] or the maximum number of attempts
+// is exhausted
+func (seq sequence) fibonacciNumbers(rnd *rand.Rand) ([]int, error) {
+
+	numbers := make([]int, seq.nbitems)
+	for attempt := 0; attempt < MAXGENERATIONATTEMPTS; attempt++ {
+
+		// randomly choose the first two numbers, which are necessarily
+		// non-negative
+		lower := seq.geq
+		if lower < 0 {
+			lower = 0
+		}
+		if lower > seq.leq {
+			break
+		}
+		numbers[0] = lower + rnd.Int()%(1+seq.leq-lower)
+		numbers[1] = lower + rnd.Int()%(1+seq.leq-lower)
+
+		// compute the rest and check they fall within the interval
+		ok := true
+		for idx := 2; idx < seq.nbitems && ok; idx++ {
+			numbers[idx] = numbers[idx-1] + numbers[idx-2]
+			ok = numbers[idx] <= seq.leq
+		}
+		if ok {
+			return numbers, nil
+		}
+	}
+	return nil, fmt.Errorf("It was not possible to generate a Fibonacci-like sequence of %v numbers in the range [%v, %v] after %v attempts",
+		seq.nbitems, seq.geq, seq.leq, MAXGENERATIONATTEMPTS)
+}
+
 // return the instance of a specific sequence problem that can be marshalled in
 // JSON format. The receiver is assumed to have been fully verified so that it
 // should be consistent.
@@ -198,40 +369,33 @@ func (seq sequenceTikZ) execute() string {
 // The result is given with a list with as many elements as items in the
 // sequence where "?" signals those locations that have to be guessed by the
 // student
-func (seq sequence) generateJSONProblem() (problemJSON, error) {
+func (seq sequence) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
 
-	rand.Seed(time.Now().UTC().UnixNano())
 
-	// determine the first number of the sequence ---even if it is not
-	// displayed. If the interval [geq, leq] is too narrow to host nbitems,
-	// immediately log a fatal error
-	if 1+seq.leq-seq.geq < seq.nbitems {
-		return problemJSON{}, fmt.Errorf("It is not possible to fit %v different numbers taken from the range [%v, %v]",
-			seq.nbitems, seq.geq, seq.leq)
+	// determine all the numbers of the sequence ---even if they are not
+	// displayed
+	numbers, err := seq.numbers(rnd)
+	if err != nil {
+		return ProblemJSON{}, err
 	}
 
-	// The following expression takes into account not only the interval [geq,
-	// leq] but also the number of items to display in the sequence
-	number1 := seq.geq + rand.Int()%(2+seq.leq-seq.nbitems-seq.geq)
-
 	// in case this sequence is of type SEQNONE, then randomly choose a position
 	// in between to show a number, unless there are only two items in which
 	// case randomly chose any
 	var pos int
 	if seq.nbitems <= 2 {
-		pos = rand.Int() % (seq.nbitems)
+		pos = rnd.Int() % (seq.nbitems)
 	} else {
-		pos = 1 + rand.Int()%(seq.nbitems-2)
+		pos = 1 + rnd.Int()%(seq.nbitems-2)
 	}
 
 	// and now fill in the sequence along with the solution
 	args := make([]string, seq.nbitems)
 	solution := make([]string, seq.nbitems)
-	for item := number1; item < number1+seq.nbitems; item++ {
+	for idx := 0; idx < seq.nbitems; idx++ {
 
 		// first, write the solution
-		idx := item - number1
-		solution[idx] = strconv.FormatInt(int64(item), 10)
+		solution[idx] = strconv.FormatInt(int64(numbers[idx]), 10)
 
 		// now, depending on the position and type
 
@@ -263,25 +427,71 @@ func (seq sequence) generateJSONProblem() (problemJSON, error) {
 		}
 	}
 
+	// in case the items to hide were explicitly given, or their number, then
+	// they override the type of the sequence
+	if len(seq.mask) > 0 || seq.nbmasked > 0 {
+		copy(args, solution)
+		mask := seq.mask
+		if len(mask) == 0 {
+			mask = rnd.Perm(seq.nbitems)[:seq.nbmasked]
+		}
+		for _, idx := range mask {
+			args[idx] = "?"
+		}
+	}
+
 	// and return the problem along with its solution
-	return problemJSON{
+	return ProblemJSON{
 		Probtype: "Sequence",
 		Args:     args,
 		Solution: solution}, nil
 }
 
+// set the difficulty and tags of the given problem generated by this sequence.
+// Arithmetic sequences counting forwards are the easiest ones, and sequences
+// are harder with larger numbers or when many items have to be found
+func (seq sequence) annotate(problem *ProblemJSON) {
+
+	// sequences are arithmetic unless otherwise stated
+	pattern := seq.pattern
+	if pattern == "" {
+		pattern = SEQARITHMETIC
+	}
+	score := 1
+	problem.Tags = []string{pattern}
+	if pattern != SEQARITHMETIC {
+		score += 2
+	} else if seq.step < 0 {
+		score++
+		problem.Tags = append(problem.Tags, "counting-backwards")
+	}
+	if seq.leq > 100 {
+		score++
+	}
+	masked := 0
+	for _, arg := range problem.Args {
+		if arg == "?" {
+			masked++
+		}
+	}
+	if masked > seq.nbitems/2 {
+		score++
+	}
+	problem.Difficulty = difficulty(score)
+}
+
 // return a valid LaTeX/TikZ representation of this sequence using TikZ
 // components
-func (seq sequence) GetTikZPicture() string {
+func (seq sequence) GetTikZPicture() (string, error) {
 
 	// -- operands: randomly determine the values of the operands. For this, the
 	//              service that generates problems is the one that can marshal
 	//              them into JSON format. The numbers of the sequence are given
 	//              in Args, where a question mark is a number that has to be
 	//              guessed by the student
-	instance, err := seq.generateJSONProblem()
+	instance, err := seq.next(annotated(seq))
 	if err != nil {
-		log.Fatalf(" Fatal error while generating a valid sequence: %v", err)
+		return "", fmt.Errorf("error while generating a valid sequence: %v", err)
 	}
 
 	// in spite of the values geq and leq, it is good to compute the maximum
@@ -310,7 +520,7 @@ func (seq sequence) GetTikZPicture() string {
 	// first is the center of the location of the first box
 	first := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, 0.5\zeroheight+1.5\baselineskip)$`,
-			1.0+(2+nbdigits)/2.0)),
+			helpers.Ftoa(1.0+(2+nbdigits)/2.0))),
 		"first",
 	)
 
@@ -318,12 +528,12 @@ func (seq sequence) GetTikZPicture() string {
 	// intermediate text boxes
 	last := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(first) + (%v*\zerowidth, 0.0)$`,
-			(2.5+nbdigits)*float64((seq.nbitems-1)))),
+			helpers.Ftoa((2.5+nbdigits)*float64((seq.nbitems-1))))),
 		"last",
 	)
 	right := components.NewCoordinate(
 		components.Formula(fmt.Sprintf(`$(last) + (%v\zerowidth, 0.5\zeroheight + 0.5\baselineskip)$`,
-			(2+nbdigits)/2.0)),
+			helpers.Ftoa((2+nbdigits)/2.0))),
 		"right",
 	)
 
@@ -349,7 +559,7 @@ func (seq sequence) GetTikZPicture() string {
 		// in spite of the contents, the next cell is located at
 		coord := components.NewCoordinate(
 			components.Formula(fmt.Sprintf(`$(first) + (%v\zerowidth, 0)$`,
-				float64(idx)*(2.5+nbdigits))),
+				helpers.Ftoa(float64(idx)*(2.5+nbdigits)))),
 			fmt.Sprintf("cell%v", idx),
 		)
 
@@ -359,10 +569,10 @@ func (seq sequence) GetTikZPicture() string {
 			// then add an empty text box
 			box = components.NewLabeledText(
 				fmt.Sprintf(`rounded corners, rectangle, minimum width=%v*\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
-					2.0+nbdigits,
+					helpers.Ftoa(2.0+nbdigits),
 				),
 				fmt.Sprintf("cell%v", idx),
-				"",
+				seq.answer(instance.Solution[idx]),
 			)
 		} else {
 
@@ -394,23 +604,23 @@ func (seq sequence) GetTikZPicture() string {
 }
 
 // Return TikZ code that represents a sequence
-func (seq sequence) execute() string {
+func (seq sequence) execute() (string, error) {
 
 	// create a template with the TikZ code for showing this sequence
 	tpl, err := template.New("sequence").Parse(latexSequenceCode)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and now make the appropriate substitutions. Note that the execution of the
 	// template is written to a string
 	var tplOutput bytes.Buffer
 	if err := tpl.Execute(&tplOutput, seq); err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	// and return the resulting string
-	return tplOutput.String()
+	return tplOutput.String(), nil
 }
 
 /* Local Variables: */
@@ -0,0 +1,332 @@
+// -*- coding: utf-8 -*-
+// shadedfraction.go
+//
+// Description: Provides services for automatically creating problems where
+// fractions are represented with circles or bars partially shaded
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:40:40.662951707 (1792114840)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// There are two different types of shaded fractions: "read" or "shade". In the
+// first case, the student has to write the fraction represented by the shaded
+// parts of the shape; in the latter, the fraction is given and the student has
+// to shade it
+const (
+	SFREAD int = iota
+	SFSHADE
+)
+
+// the TikZ code for generating shaded fractions is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexShadedFractionCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the shaded fraction
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZShadedFractionCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box and center of the shape
+      {{.Bottom}}
+      {{.Center}}
+
+      % --- Shape -----------------------------------------------------------
+
+      % the shape is divided into as many parts as the denominator, and the
+      % shaded ones are filled
+{{.Shape}}
+      % --- Fraction --------------------------------------------------------
+
+      % the fraction is shown to the right of the shape with its fraction bar
+      % at the same height than its center
+      {{.Denominator}}
+      {{.Numerator}}
+      {{.Bar}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// A shaded fraction consists of a shape, either a circle ("pie") or a bar
+// ("bar"), divided into as many equal parts as the denominator, which is
+// randomly chosen in the interval [dengeq, denleq], and a number of shaded
+// parts given by the numerator. Shaded parts are randomly chosen unless they
+// are requested to be contiguous. There are two types of shaded fractions:
+//
+//    0: the fraction represented by the shape has to be written by the student
+//    1: the fraction is given and the student has to shade it in the shape
+type shadedFraction struct {
+	sftype         int
+	shape          string
+	dengeq, denleq int
+	contiguous     bool
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw shaded
+// fractions
+type shadedFractionTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0),
+	// and the shape is drawn around its center
+	Bottom, Center components.Coordinate
+
+	// the shape which represents the fraction
+	Shape components.FractionShape
+
+	// the fraction consists of a numerator and denominator separated by a
+	// fraction bar
+	Numerator, Denominator components.CoordinatedText
+	Bar                    components.Line
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- shadedFractionTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz shadedFractionTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("shadedFractionTikZ").Parse(tikZShadedFractionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- shadedFraction
+
+// return the instance of a specific shaded fraction that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with four items: the shape ("pie" or "bar"), the pattern
+// of shaded parts as a string with one digit per part (1 if it is shaded and 0
+// otherwise), and the numerator and denominator of the fraction. Either both
+// the numerator and the denominator or the pattern are shown as "?" in the
+// arguments as they have to be guessed by the student
+func (sf shadedFraction) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct. Note that fractions are
+	// proper and thus their denominator should be at least 2
+	if sf.dengeq < 2 || sf.dengeq > sf.denleq {
+		return ProblemJSON{}, fmt.Errorf("It is not possible to generate denominators in the range [%v, %v]",
+			sf.dengeq, sf.denleq)
+	}
+
+	// randomly determine the fraction, which is always a proper fraction with
+	// at least one part shaded
+	denominator := sf.dengeq + rnd.Intn(1+sf.denleq-sf.dengeq)
+	numerator := 1 + rnd.Intn(denominator-1)
+
+	// and now choose the parts to shade, either one after the other starting
+	// from a random part (note that parts of circles wrap around), or randomly
+	pattern := make([]byte, denominator)
+	for i := range pattern {
+		pattern[i] = '0'
+	}
+	if sf.contiguous {
+		start := rnd.Intn(denominator)
+		if sf.shape == components.FRACTIONBAR {
+			start = rnd.Intn(1 + denominator - numerator)
+		}
+		for i := 0; i < numerator; i++ {
+			pattern[(start+i)%denominator] = '1'
+		}
+	} else {
+		for _, idx := range rnd.Perm(denominator)[:numerator] {
+			pattern[idx] = '1'
+		}
+	}
+
+	// create two slices: one for storing the instance of this problem, where
+	// the part that should be filled in by the student is marked with a
+	// question mark "?"; and another one with the full solution
+	solution := []string{
+		sf.shape,
+		string(pattern),
+		strconv.FormatInt(int64(numerator), 10),
+		strconv.FormatInt(int64(denominator), 10),
+	}
+	args := make([]string, 4)
+	copy(args, solution)
+	if sf.sftype == SFREAD {
+		args[2], args[3] = "?", "?"
+	} else {
+		args[1] = "?"
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "ShadedFraction",
+		Args:     args,
+		Solution: solution}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this shaded fraction using TikZ
+// components
+func (sf shadedFraction) GetTikZPicture() (string, error) {
+
+	// -- shape: randomly determine the fraction and the parts shaded. For
+	//           this, the service that generates problems is the one that can
+	//           marshal them into JSON format. A question mark is either the
+	//           fraction or the shading that has to be guessed by the student
+	instance, err := sf.next(annotated(sf))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid shaded fraction: %v", err)
+	}
+
+	// the parts of the shape are shaded only if they are given or the answers
+	// have to be shown
+	shaded := make([]bool, len(instance.Solution[1]))
+	if instance.Args[1] != "?" || sf.showAnswers() {
+		for idx, part := range instance.Solution[1] {
+			shaded[idx] = part == '1'
+		}
+	}
+	shape := components.NewFractionShape("center", instance.Solution[0], shaded)
+
+	// both numbers of the fraction are shown within the same width which
+	// consists of the number of digits of the denominator plus one additional
+	// digit to each side
+	width := 2.0 + float64(len(instance.Solution[3]))
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box, and the shape is
+	// centered to its right, high enough to leave room for the fraction
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+	center := components.NewCoordinate(components.Point{
+		X: 0.5 + shape.GetWidth()/2.0,
+		Y: helpers.Max(shape.GetHeight()/2.0, 1.2),
+	}, "center")
+
+	// the following function returns the text to show in the cell of the i-th
+	// argument: either an empty box, if the number has to be guessed, or the
+	// number itself
+	cell := func(label, formula string, i int) components.CoordinatedText {
+		options, text := "", ""
+		if instance.Args[i] == "?" {
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+				helpers.Ftoa(width))
+			text = sf.answer(instance.Solution[i])
+		} else {
+			text = `\huge ` + instance.Args[i]
+		}
+		return components.NewCoordinatedText(
+			components.NewCoordinate(components.Formula(formula), label),
+			options, text)
+	}
+
+	// -- fraction
+	den := cell("den",
+		fmt.Sprintf(`$(center) + (%vcm, 0.0) + (%v\zerowidth, -0.5\zeroheight-0.5\baselineskip-0.1cm)$`,
+			helpers.Ftoa(0.5+shape.GetWidth()/2.0), helpers.Ftoa(1.0+width/2.0)),
+		3)
+	num := cell("num",
+		`$(den) + (0.0, \zeroheight+\baselineskip+0.2cm)$`,
+		2)
+	bar := components.NewLine(
+		fmt.Sprintf(`$(den) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.1cm)$`, helpers.Ftoa(-width/2.0)),
+		fmt.Sprintf(`$(den) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.1cm)$`, helpers.Ftoa(width/2.0)))
+	bar.SetOptions("thick")
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(num) + (%v\zerowidth, 0.5\zeroheight+0.5\baselineskip+0.2cm)$`,
+			helpers.Ftoa(0.5+width/2.0))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the
+	// shaded fraction
+	sfPicture := shadedFractionTikZ{
+		Bottom:      bottom,
+		Center:      center,
+		Shape:       shape,
+		Numerator:   num,
+		Denominator: den,
+		Bar:         bar,
+		BBox:        bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return sfPicture.execute()
+}
+
+// Return TikZ code that represents a shaded fraction
+func (sf shadedFraction) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this shaded fraction
+	tpl, err := template.New("shadedFraction").Parse(latexShadedFractionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, sf); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
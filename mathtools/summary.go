@@ -0,0 +1,112 @@
+// -*- coding: utf-8 -*-
+// summary.go
+//
+// Description: Reports what would be generated from master files without
+// writing anything
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 08:41:52.117305482 (1792140112)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/clinaresl/mathprob/fstools"
+)
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// LaTeX commands which start a new page
+var pageBreaks = []string{"\\newpage", "\\clearpage", "\\pagebreak"}
+
+// types
+// ----------------------------------------------------------------------------
+
+// The summary of a sheet consists of the number of problems generated, an
+// estimate of the number of pages of the TeX file, and all the files that
+// would be written
+type SheetSummary struct {
+	Problems int
+	Pages    int
+	Files    []string
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- MasterFile
+
+// Return a summary of the sheet that would be written into the specified dst
+// file from this master file (see MasterToFileFromTemplate) without writing
+// anything. The master file is validated first and then the template is
+// executed so that all problems are generated. The number of pages is
+// estimated from the page breaks explicitly given in the TeX file. If the
+// master file is not correct or it could not be executed, an error is returned
+func (masterFile MasterFile) Summarize(ctx context.Context, dst string) (SheetSummary, error) {
+
+	if err := masterFile.Validate(); err != nil {
+		return SheetSummary{}, err
+	}
+	t, err := masterTemplate(masterFile.Infile)
+	if err != nil {
+		return SheetSummary{}, err
+	}
+
+	// execute the template exactly in the same way it would be done for
+	// writing the TeX file
+	var solutions []ProblemJSON
+	masterFile.recorder = recorder{rnd: newRand(masterFile.Seed), draws: newRand(masterFile.Seed), solutions: &solutions, band: masterFile.Difficulty, ctx: ctx}
+	if masterFile.Unique {
+		masterFile.recorder.context = newGenerationContext(masterFile.UniqueAttempts)
+	}
+	result, err := masterFile.masterToBufferFromTemplate(t)
+	if err != nil {
+		return SheetSummary{}, fmt.Errorf("Error when executing the template over the master file '%v': %v", masterFile.Infile, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return SheetSummary{}, err
+	}
+
+	summary := SheetSummary{Problems: len(solutions), Pages: 1}
+	for _, pageBreak := range pageBreaks {
+		summary.Pages += strings.Count(result.String(), pageBreak)
+	}
+
+	// existing files would be re-numbered unless they have to be overwritten
+	if !masterFile.Overwrite {
+		current := dst
+		for index := 2; ; index++ {
+			if _, err := os.Stat(dst); os.IsNotExist(err) {
+				break
+			}
+			dst = fstools.NumberFilename(current, index)
+		}
+	}
+	summary.Files = append(summary.Files, dst)
+	prefix := strings.TrimSuffix(dst, ".tex")
+	if masterFile.Solutions {
+		summary.Files = append(summary.Files, prefix+".solutions.json")
+	}
+	if masterFile.Answers {
+		summary.Files = append(summary.Files, prefix+".answers.tex")
+	}
+	if masterFile.PDF {
+		summary.Files = append(summary.Files, prefix+".pdf")
+		if masterFile.Answers {
+			summary.Files = append(summary.Files, prefix+".answers.pdf")
+		}
+	}
+	return summary, nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,121 @@
+// -*- coding: utf-8 -*-
+// svg.go
+//
+// Description: Provides services for rendering problems in SVG format so that
+// they can be embedded in web pages or apps without a LaTeX toolchain
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:52:41.358251810 (1792111961)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Separation in centimeters between consecutive items of problems rendered in
+// SVG
+const SVGSEPARATION float64 = 0.3
+
+// types
+// ----------------------------------------------------------------------------
+
+// A problem in SVG format consists of the same type and identifier of the
+// problem in JSON format along with its picture, which is a complete SVG
+// document
+type ProblemSVG struct {
+	Probtype string `json:"type"`
+	Id       int    `json:"id"`
+	SVG      string `json:"svg"`
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return an SVG document which shows the given problem. All its arguments are
+// shown from left to right, and those that have to be filled in by the student
+// are shown as empty boxes wide enough to write their solution
+func problemSVG(instance ProblemJSON) (string, error) {
+
+	canvas := components.NewSVGCanvas()
+
+	// the lower-left corner of the picture is always located at (0, 0)
+	if err := components.NewCoordinate(components.Point{X: 0.0, Y: 0.0}, "bottom").SVG(canvas); err != nil {
+		return "", err
+	}
+
+	// draw every argument right after the previous one
+	x := 0.0
+	for idx, arg := range instance.Args {
+
+		// the width of every item is computed from its contents or, if it has
+		// to be filled in, from its solution
+		text, options, width := arg, "", float64(len([]rune(arg)))
+		if arg == "?" {
+			text, width = "", 2.0
+			if idx < len(instance.Solution) {
+				width = helpers.Max(width, 1.0+float64(len([]rune(instance.Solution[idx]))))
+			}
+			options = fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, draw`, helpers.Ftoa(width))
+		}
+		center := components.Point{
+			X: x + (components.SVGCHARWIDTH*width+0.2)/2.0,
+			Y: components.SVGCHARHEIGHT / 2.0,
+		}
+		item := components.NewCoordinatedText(
+			components.NewCoordinate(center, fmt.Sprintf("arg%v", idx)), options, text)
+		if err := item.SVG(canvas); err != nil {
+			return "", err
+		}
+		x += components.SVGCHARWIDTH*width + 0.2 + SVGSEPARATION
+	}
+
+	// and return the SVG document
+	return canvas.String(), nil
+}
+
+// given an array of master problems (of any type) return a slice of bytes in
+// JSON format with the requested problems rendered in SVG format. If a problem
+// could not be generated, the contents of the returned data are undefined and
+// an error is raised. The generation stops as soon as the given context is done
+func GenerateSVG(ctx context.Context, problems []MasterProblem) (data []byte, err error) {
+
+	// generate all the problems requested
+	jsonprobs, err := generateJSONProblems(ctx, problems)
+	if err != nil {
+		return data, err
+	}
+
+	// and render each one in SVG format
+	svgprobs := []ProblemSVG{}
+	for _, iprob := range jsonprobs {
+		picture, err := problemSVG(iprob)
+		if err != nil {
+			return data, fmt.Errorf("it was not possible to render the problem #%v of type '%v' in SVG: %v", iprob.Id, iprob.Probtype, err)
+		}
+		svgprobs = append(svgprobs, ProblemSVG{
+			Probtype: iprob.Probtype,
+			Id:       iprob.Id,
+			SVG:      picture,
+		})
+	}
+
+	// Now, marshal data and return the json bytes stream
+	data, err = json.MarshalIndent(svgprobs, "", "\t")
+	return data, err
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
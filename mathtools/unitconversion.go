@@ -0,0 +1,327 @@
+// -*- coding: utf-8 -*-
+// unitconversion.go
+//
+// Description: Provides services for automatically creating problems of
+// converting quantities between units of the metric system
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 01:16:54.000705480 (1792113414)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Conversions can be made from larger units to smaller ones ("down"), from
+// smaller units to larger ones ("up") or in any direction ("both")
+const (
+	UCDOWN string = "down"
+	UCUP   string = "up"
+	UCBOTH string = "both"
+)
+
+// By default, conversions are made in any direction with whole numbers in the
+// range [1, 100], and values never have more than the following number of
+// decimal digits
+const (
+	DEFAULTUCGEQ       int    = 1
+	DEFAULTUCLEQ       int    = 100
+	MAXUCDECIMALS      int    = 3
+	DEFAULTUCDIRECTION string = UCBOTH
+)
+
+// the TikZ code for generating unit conversions is shown next. Note that it
+// makes use of LaTeX/TikZ components
+const latexUnitConversionCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the unit conversion
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZUnitConversionCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Quantity --------------------------------------------------------
+
+      % the quantity is shown to the left of the equal sign
+      {{.Quantity}}
+      {{.Equal}}
+
+      % --- Answer ----------------------------------------------------------
+
+      % the converted value is written in a box followed by its unit
+      {{.Answer}}
+      {{.Unit}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// types
+// ----------------------------------------------------------------------------
+
+// Every unit is characterized by its symbol and its exponent, i.e., the power
+// of ten that relates it to the base unit of its quantity
+type unit struct {
+	symbol   string
+	exponent int
+}
+
+// A unit conversion consists of a quantity ("length", "mass" or "capacity"),
+// the direction of the conversion ("down", "up" or "both"), and the range
+// [geq, leq] of the values to convert, which are divided by ten to the number
+// of decimals given
+type unitConversion struct {
+	quantity  string
+	direction string
+	geq, leq  int
+	decimals  int
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw unit
+// conversions
+type unitConversionTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the quantity to convert is shown to the left of the equal sign
+	Quantity, Equal components.CoordinatedText
+
+	// the converted value and its unit
+	Answer, Unit components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The units of every quantity are given in increasing order
+var units = map[string][]unit{
+	"length":   {{"mm", -3}, {"cm", -2}, {"m", 0}, {"km", 3}},
+	"mass":     {{"g", 0}, {"kg", 3}},
+	"capacity": {{"mL", -3}, {"L", 0}},
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- unitConversionTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz unitConversionTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("unitConversionTikZ").Parse(tikZUnitConversionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- unitConversion
+
+// return the instance of a specific unit conversion that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with four items: the value to convert, its unit, the
+// converted value and the unit it is converted to. The converted value is shown
+// as "?" in the arguments as it has to be guessed by the student
+func (uc unitConversion) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	choices, ok := units[uc.quantity]
+	if !ok {
+		return ProblemJSON{}, fmt.Errorf("Unknown quantity '%v'", uc.quantity)
+	}
+	if uc.geq > uc.leq {
+		return ProblemJSON{}, fmt.Errorf("The range [%v, %v] of the values to convert is empty", uc.geq, uc.leq)
+	}
+
+	// randomly choose two different units. As they are given in increasing
+	// order, the first one is larger when converting down and smaller when
+	// converting up
+	first := rnd.Intn(len(choices))
+	second := rnd.Intn(len(choices) - 1)
+	if second >= first {
+		second++
+	}
+	if (uc.direction == UCDOWN && first < second) || (uc.direction == UCUP && first > second) {
+		first, second = second, first
+	}
+	from, to := choices[first], choices[second]
+
+	// randomly determine the value to convert and compute the converted value
+	// exactly
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(uc.decimals)), nil)
+	value := new(big.Rat).SetFrac(big.NewInt(int64(uc.geq+rnd.Intn(1+uc.leq-uc.geq))), scale)
+	exponent := from.exponent - to.exponent
+	if exponent < 0 {
+		exponent = -exponent
+	}
+	factor := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+	result := new(big.Rat)
+	if from.exponent > to.exponent {
+		result.Mul(value, factor)
+	} else {
+		result.Quo(value, factor)
+	}
+
+	// both values are written with as many decimal digits as necessary
+	number, err := terminatingDecimal(value)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	converted, err := terminatingDecimal(result)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "UnitConversion",
+		Args:     []string{number, from.symbol, "?", to.symbol},
+		Solution: []string{number, from.symbol, converted, to.symbol}}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this unit conversion using TikZ
+// components
+func (uc unitConversion) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the quantity to convert. For this, the
+	//              service that generates problems is the one that can marshal
+	//              them into JSON format. A question mark is a number that has
+	//              to be guessed by the student
+	instance, err := uc.next(annotated(uc))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid unit conversion: %v", err)
+	}
+
+	// the quantity is as wide as its value and unit, and the box is wide
+	// enough to write the converted value
+	quantitywidth := 1.0 + float64(len(instance.Args[0])+len(instance.Args[1]))
+	boxwidth := 2.0 + float64(len(instance.Solution[2]))
+	unitwidth := float64(len(instance.Args[3]))
+
+	// all items are vertically centered at the same height
+	height := `0.5\zeroheight + 0.5\baselineskip`
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// the following function returns a text centered at the given horizontal
+	// position
+	item := func(label string, x float64, options, text string) components.CoordinatedText {
+		return components.NewCoordinatedText(
+			components.NewCoordinate(
+				components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, %v)$`,
+					helpers.Ftoa(x), height)),
+				label),
+			options, text)
+	}
+
+	// -- quantity
+	quantity := item("quantity", 0.5+quantitywidth/2.0, "",
+		fmt.Sprintf(`\huge %v %v`, localizeDecimal(instance.Args[0]), instance.Args[1]))
+	equal := item("equal", 1.25+quantitywidth, "", `\huge $=$`)
+
+	// -- answer
+	answer := item("answer", 2.0+quantitywidth+boxwidth/2.0,
+		fmt.Sprintf(`rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+			helpers.Ftoa(boxwidth)),
+		uc.answer(localizeDecimal(instance.Solution[2])))
+	unit := item("unit", 2.5+quantitywidth+boxwidth+unitwidth/2.0, "", `\huge `+instance.Args[3])
+
+	// -- bounding box
+	right := components.NewCoordinate(
+		components.Formula(fmt.Sprintf(`$(bottom) + (%v\zerowidth, \zeroheight + \baselineskip)$`,
+			helpers.Ftoa(3.0+quantitywidth+boxwidth+unitwidth))),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the unit
+	// conversion
+	ucPicture := unitConversionTikZ{
+		Bottom:   bottom,
+		Quantity: quantity,
+		Equal:    equal,
+		Answer:   answer,
+		Unit:     unit,
+		BBox:     bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return ucPicture.execute()
+}
+
+// Return TikZ code that represents a unit conversion
+func (uc unitConversion) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this unit conversion
+	tpl, err := template.New("unitConversion").Parse(latexUnitConversionCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, uc); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
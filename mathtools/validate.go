@@ -0,0 +1,343 @@
+// -*- coding: utf-8 -*-
+// validate.go
+//
+// Description: Provides services for validating master files without
+// generating any problem
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 04:40:19.503817224 (1792125619)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A master validator walks over the parse trees of a master file and all its
+// partials, and records a message for every error found along with its
+// location. Partials included with Include are resolved relative to the given
+// directory, and they are validated only once
+type masterValidator struct {
+	dir      string
+	visited  map[string]bool
+	messages []string
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the value of the given argument of a template action if it is a
+// constant, and true. Dictionaries created with "dict" are returned as maps if
+// all their keys and values are constant. Otherwise, false is returned, as
+// their values are only known when executing the template
+func constantArg(node parse.Node) (interface{}, bool) {
+
+	switch arg := node.(type) {
+	case *parse.StringNode:
+		return arg.Text, true
+	case *parse.BoolNode:
+		return arg.True, true
+	case *parse.NumberNode:
+
+		// numbers are given to methods in the same way they are given by
+		// text/template, i.e., as integers unless they are written as floats
+		if arg.IsInt && !strings.ContainsAny(arg.Text, ".eEpP") {
+			return int(arg.Int64), true
+		}
+		if arg.IsFloat {
+			return arg.Float64, true
+		}
+	case *parse.PipeNode:
+
+		// only dictionaries can be processed
+		if len(arg.Cmds) != 1 || len(arg.Decl) > 0 {
+			return nil, false
+		}
+		cmd := arg.Cmds[0]
+		if ident, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != "dict" || len(cmd.Args)%2 == 0 {
+			return nil, false
+		}
+		dict := make(map[string]interface{}, len(cmd.Args)/2)
+		for i := 1; i < len(cmd.Args); i += 2 {
+			key, ok := cmd.Args[i].(*parse.StringNode)
+			if !ok {
+				return nil, false
+			}
+			value, ok := constantArg(cmd.Args[i+1])
+			if !ok {
+				return nil, false
+			}
+			dict[key.Text] = value
+		}
+		return dict, true
+	}
+	return nil, false
+}
+
+// return the name of the method or field of master files invoked by the given
+// command, either from the dot (e.g., .Angle) or from the root variable (e.g.,
+// $.Angle), or an empty string if none is invoked
+func commandName(cmd *parse.CommandNode) string {
+
+	switch arg := cmd.Args[0].(type) {
+	case *parse.FieldNode:
+		if len(arg.Ident) == 1 {
+			return arg.Ident[0]
+		}
+	case *parse.VariableNode:
+		if len(arg.Ident) == 2 && arg.Ident[0] == "$" {
+			return arg.Ident[1]
+		}
+	}
+	return ""
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- masterValidator
+
+// validate all the templates associated with the given one
+func (v *masterValidator) template(t *template.Template) {
+
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil && tmpl.Tree.Root != nil {
+			v.node(tmpl.Tree, tmpl.Tree.Root)
+		}
+	}
+}
+
+// validate the given node of the given parse tree and all its descendants
+func (v *masterValidator) node(tree *parse.Tree, node parse.Node) {
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			v.node(tree, child)
+		}
+	case *parse.ActionNode:
+		v.pipe(tree, n.Pipe)
+	case *parse.TemplateNode:
+		v.pipe(tree, n.Pipe)
+	case *parse.IfNode:
+		v.branch(tree, &n.BranchNode)
+	case *parse.RangeNode:
+		v.branch(tree, &n.BranchNode)
+	case *parse.WithNode:
+		v.branch(tree, &n.BranchNode)
+	}
+}
+
+// validate the pipeline and both lists of the given branch
+func (v *masterValidator) branch(tree *parse.Tree, branch *parse.BranchNode) {
+
+	v.pipe(tree, branch.Pipe)
+	if branch.List != nil {
+		v.node(tree, branch.List)
+	}
+	if branch.ElseList != nil {
+		v.node(tree, branch.ElseList)
+	}
+}
+
+// validate all commands of the given pipeline
+func (v *masterValidator) pipe(tree *parse.Tree, pipe *parse.PipeNode) {
+
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+
+		// first, validate the pipelines given as arguments
+		for _, arg := range cmd.Args {
+			if nested, ok := arg.(*parse.PipeNode); ok {
+				v.pipe(tree, nested)
+			}
+		}
+
+		// and next the command itself, if it invokes a master file
+		if name := commandName(cmd); name != "" {
+			if err := v.call(name, cmd.Args[1:]); err != nil {
+				location, _ := tree.ErrorContext(cmd)
+				v.messages = append(v.messages, fmt.Sprintf("%v: %v", location, err))
+			}
+		}
+	}
+}
+
+// verify the invocation of the given method of master files with the given
+// arguments. Only those arguments that are constant are verified, and no
+// problem is generated
+func (v *masterValidator) call(name string, args []parse.Node) error {
+
+	// first, make sure that master files provide this method or field
+	masterType := reflect.TypeOf(MasterFile{})
+	if _, ok := masterType.MethodByName(name); !ok {
+		if _, ok := masterType.FieldByName(name); !ok {
+			return fmt.Errorf("Unknown method '%v' of master files", name)
+		}
+		return nil
+	}
+
+	// get the values of all constant arguments. The validation of those that
+	// are not constant is postponed until the template is executed
+	values := make([]interface{}, len(args))
+	for idx, arg := range args {
+		var ok bool
+		if values[idx], ok = constantArg(arg); !ok {
+			return nil
+		}
+	}
+	dict := func(idx int) (map[string]interface{}, bool) {
+		if idx >= len(values) {
+			return nil, false
+		}
+		value, ok := values[idx].(map[string]interface{})
+		return value, ok
+	}
+
+	switch name {
+	case "Include":
+		if path, ok := values[0].(string); ok && len(values) == 1 {
+			return v.include(path)
+		}
+	case "Problem":
+		if problem, ok := values[0].(string); ok && len(values) == 2 {
+			if args, ok := dict(1); ok {
+				_, err := masterGenerator(problem, args)
+				return err
+			}
+		}
+	case "Grid":
+		if args, ok := dict(0); ok {
+			grid, err := verifyGridDict(args)
+			if err != nil {
+				return fmt.Errorf("The dictionary given for creating a grid is incorrect: %v", err)
+			}
+			_, err = masterGenerator(grid.problem, grid.args)
+			return err
+		}
+	case "GridPaper":
+		if args, ok := dict(0); ok {
+			if _, err := verifyGridPaperDict(args); err != nil {
+				return fmt.Errorf("The dictionary given for creating grid paper is incorrect: %v", err)
+			}
+		}
+	case "Coordinate":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyCoordinateDict(args)
+			return err
+		}
+	case "Text":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyTextDict(args)
+			return err
+		}
+	case "Circle":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyCircleDict(args)
+			return err
+		}
+	case "Ellipse":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyEllipseDict(args)
+			return err
+		}
+	case "Arrow":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyArrowDict(args)
+			return err
+		}
+	case "MeasuredSegment":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyMeasuredSegmentDict(args)
+			return err
+		}
+	case "CellGrid":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyGridDict(args)
+			return err
+		}
+	case "Polygon":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyPolygonDict(args)
+			return err
+		}
+	case "RegularPolygon":
+		if args, ok := dict(0); ok {
+			_, err := components.VerifyRegularPolygonDict(args)
+			return err
+		}
+	default:
+
+		// otherwise, if this is a problem type, verify its dictionary
+		if entry, ok := lookup(name); ok && entry.description.Master {
+			if args, ok := dict(0); ok {
+				_, err := masterGenerator(name, args)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validate the partial stored in the given path, resolved relative to the
+// directory of the master file, unless it was already validated
+func (v *masterValidator) include(path string) error {
+
+	path = resolvePartial(path, v.dir)
+	if v.visited[path] {
+		return nil
+	}
+	v.visited[path] = true
+
+	t, err := partialTemplate(path, v.dir)
+	if err != nil {
+		return err
+	}
+	v.template(t)
+	return nil
+}
+
+// -- MasterFile
+
+// Validate the master file without generating any problem. The template and
+// all its partials are parsed, and the dictionaries given to every method of
+// master files are verified. All errors found are reported with their
+// locations, i.e., the name of the file, line and column. Note that only
+// arguments given as constants can be verified before executing the template
+func (masterFile MasterFile) Validate() error {
+
+	t, err := masterTemplate(masterFile.Infile)
+	if err != nil {
+		return err
+	}
+
+	v := masterValidator{
+		dir:     filepath.Dir(masterFile.Infile),
+		visited: map[string]bool{masterFile.Infile: true},
+	}
+	v.template(t)
+	if len(v.messages) > 0 {
+		return errors.New(strings.Join(v.messages, "\n"))
+	}
+	return nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,369 @@
+// -*- coding: utf-8 -*-
+// wordproblem.go
+//
+// Description: Provides services for automatically creating word problems,
+// i.e., arithmetic operations told as short stories
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 00:42:27.497395038 (1792111347)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathtools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"text/template"
+
+	"github.com/clinaresl/mathprob/helpers"
+	"github.com/clinaresl/mathprob/mathtools/components"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// the TikZ code for generating word problems is shown next. Note that it makes
+// use of LaTeX/TikZ components
+const latexWordProblemCode = `\begin{minipage}{0.5\linewidth}
+    \begin{center}
+        \begin{tikzpicture}
+
+            % draw the word problem
+            {{.GetTikZPicture}}
+
+        \end{tikzpicture}
+    \end{center}
+\end{minipage}
+`
+
+const tikZWordProblemCode = `% --- Coordinates -----------------------------------------------------
+
+      % Lower-left corner of the bounding box
+      {{.Bottom}}
+
+      % --- Story -----------------------------------------------------------
+
+      % the story is written above the answer box
+      {{.Story}}
+
+      % --- Answer ----------------------------------------------------------
+
+      % the answer is shown within an empty box in the lower-right corner
+      {{.Answer}}
+
+      % --- Bounding Box ----------------------------------------------------
+
+      {{.BBox}}
+
+      % ---------------------------------------------------------------------
+`
+
+// global variables
+// ----------------------------------------------------------------------------
+
+// The names of the characters of the stories are randomly chosen among the
+// following ones
+var wordProblemNames = []string{
+	"Ana", "Luis", "María", "Pablo", "Lucía", "Carlos", "Sofía", "Javier",
+}
+
+// The default banks of stories used for generating word problems in every
+// language. Stories are text templates where {{.Name}} is substituted by the
+// name of a character, {{.A}} and {{.B}} by the first and second operand, and
+// {{.Operator}} by the word used for the operator in the current locale
+var englishStoryBank = []story{
+	{Operator: "+", Text: "{{.Name}} has {{.A}} apples and buys {{.B}} more. How many apples does {{.Name}} have now?"},
+	{Operator: "+", Text: "There are {{.A}} pupils in a class and {{.B}} new pupils arrive. How many pupils are there in the class now?"},
+	{Operator: "-", Text: "{{.Name}} has {{.A}} stickers and gives {{.B}} away to some friends. How many stickers are left?"},
+	{Operator: "-", Text: "A bus carries {{.A}} passengers and {{.B}} get off at the next stop. How many passengers are left on the bus?"},
+	{Operator: "*", Text: "{{.Name}} buys {{.A}} boxes with {{.B}} pencils each. How many pencils has {{.Name}} bought in total?"},
+	{Operator: "*", Text: "A garden has {{.A}} rows with {{.B}} flowers in each row. How many flowers are there in the garden?"},
+	{Operator: "/", Text: "{{.Name}} shares {{.A}} sweets equally among {{.B}} friends. How many sweets does each friend get?"},
+	{Operator: "/", Text: "There are {{.A}} eggs which are packed in boxes of {{.B}} eggs. How many boxes are filled?"},
+}
+var spanishStoryBank = []story{
+	{Operator: "+", Text: "{{.Name}} tiene {{.A}} manzanas y compra {{.B}} más. ¿Cuántas manzanas tiene ahora?"},
+	{Operator: "+", Text: "En una clase hay {{.A}} alumnos y llegan {{.B}} alumnos nuevos. ¿Cuántos alumnos hay ahora en la clase?"},
+	{Operator: "-", Text: "{{.Name}} tiene {{.A}} cromos y regala {{.B}} a sus amigos. ¿Cuántos cromos le quedan?"},
+	{Operator: "-", Text: "Un autobús lleva {{.A}} pasajeros y en la siguiente parada se bajan {{.B}}. ¿Cuántos pasajeros quedan en el autobús?"},
+	{Operator: "*", Text: "{{.Name}} compra {{.A}} cajas con {{.B}} lápices cada una. ¿Cuántos lápices ha comprado en total?"},
+	{Operator: "*", Text: "Un jardín tiene {{.A}} filas con {{.B}} flores en cada fila. ¿Cuántas flores hay en el jardín?"},
+	{Operator: "/", Text: "{{.Name}} reparte {{.A}} caramelos entre {{.B}} amigos a partes iguales. ¿Cuántos caramelos recibe cada amigo?"},
+	{Operator: "/", Text: "Hay {{.A}} huevos que se guardan en cajas de {{.B}} huevos. ¿Cuántas cajas se llenan?"},
+}
+
+// Banks of stories read from files are cached and indexed by the name of the
+// file so that they are read and parsed only once
+var storyBankCache = make(map[string][]story)
+var storyBankCacheMutex sync.Mutex
+
+// types
+// ----------------------------------------------------------------------------
+
+// A story consists of the operator it is intended for and the text template
+// used for telling it. Banks of stories can be given in JSON format as a list
+// of objects with the keys "operator" and "text"
+type story struct {
+	Operator string `json:"operator"`
+	Text     string `json:"text"`
+}
+
+// A word problem consists of an operator ("+", "-", "*" or "/"), the range of
+// values [geq, leq] of the operands, and the bank of stories to choose from
+type wordProblem struct {
+	operator string
+	geq, leq int
+	bank     []story
+
+	// generated problems are recorded when solutions are requested
+	recorder
+}
+
+// The following struct stores all the information necessary to draw word
+// problems
+type wordProblemTikZ struct {
+
+	// the lower left corner of the bounding box is located always at (0, 0)
+	Bottom components.Coordinate
+
+	// the story and the box where the answer has to be written
+	Story, Answer components.CoordinatedText
+
+	// the bounding box surrounding all the necessary area for solving the
+	// exercise
+	BBox components.CoordinatedRectangle
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the bank of stories stored in the given file in JSON format. Banks are
+// read and verified only once. If the file can not be read or any of its
+// stories is incorrect, an error is returned
+func loadStoryBank(filename string) ([]story, error) {
+
+	storyBankCacheMutex.Lock()
+	defer storyBankCacheMutex.Unlock()
+
+	// in case this bank has already been read, return it immediately
+	if bank, ok := storyBankCache[filename]; ok {
+		return bank, nil
+	}
+
+	// otherwise, read the file and decode its contents
+	contents, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var bank []story
+	if err := json.Unmarshal(contents, &bank); err != nil {
+		return nil, fmt.Errorf("the bank of stories '%v' is not a valid JSON list: %v", filename, err)
+	}
+
+	// and make sure that all stories are correct
+	operators := []string{"+", "-", "*", "/"}
+	for idx, item := range bank {
+		if !helpers.Find(item.Operator, operators) {
+			return nil, fmt.Errorf("the operator '%v' of the story #%v in '%v' is incorrect", item.Operator, idx, filename)
+		}
+		if _, err := template.New("story").Parse(item.Text); err != nil {
+			return nil, fmt.Errorf("the text of the story #%v in '%v' is incorrect: %v", idx, filename, err)
+		}
+	}
+
+	// cache it and return it
+	storyBankCache[filename] = bank
+	return bank, nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- wordProblemTikZ
+
+// Return the LaTeX/TikZ commands that show up the picture stored in the
+// receiver
+func (tikz wordProblemTikZ) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this picture
+	tpl, err := template.New("wordProblemTikZ").Parse(tikZWordProblemCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of
+	// the template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, tikz); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// -- wordProblem
+
+// return the instance of a specific word problem that can be marshalled in
+// JSON format. The receiver is assumed to have been fully verified so that it
+// should be consistent.
+//
+// The result is given with two items: the story and the result of the
+// operation, which is shown as "?" in the arguments as it has to be guessed by
+// the student. Subtractions never have negative results and divisions are
+// always exact
+func (wp wordProblem) generateJSONProblem(rnd *rand.Rand) (ProblemJSON, error) {
+
+	// First, verify that parameters are correct
+	if wp.geq > wp.leq {
+		return ProblemJSON{}, fmt.Errorf("The range [%v, %v] of the operands is empty", wp.geq, wp.leq)
+	}
+
+	// select the stories for this operator
+	var stories []story
+	for _, item := range wp.bank {
+		if item.Operator == wp.operator {
+			stories = append(stories, item)
+		}
+	}
+	if len(stories) == 0 {
+		return ProblemJSON{}, fmt.Errorf("There are no stories for the operator '%v'", wp.operator)
+	}
+
+	// randomly determine the operands within the given range
+	a, b := wp.geq+rnd.Intn(1+wp.leq-wp.geq), wp.geq+rnd.Intn(1+wp.leq-wp.geq)
+	var result int
+	switch wp.operator {
+	case "+":
+		result = a + b
+	case "-":
+
+		// the first operand is the largest one
+		if a < b {
+			a, b = b, a
+		}
+		result = a - b
+	case "*":
+		result = a * b
+	case "/":
+
+		// the divisor can not be null, and the first operand is computed as
+		// the product of the divisor and the result
+		if b == 0 {
+			b = 1
+		}
+		result = a
+		a = result * b
+	}
+
+	// and now tell the story with a random character
+	tpl, err := template.New("story").Parse(stories[rnd.Intn(len(stories))].Text)
+	if err != nil {
+		return ProblemJSON{}, err
+	}
+	var text bytes.Buffer
+	if err := tpl.Execute(&text, struct {
+		Name     string
+		A, B     int
+		Operator string
+	}{wordProblemNames[rnd.Intn(len(wordProblemNames))], a, b, currentLocale().Operators[wp.operator]}); err != nil {
+		return ProblemJSON{}, err
+	}
+
+	// and return the problem along with its solution
+	return ProblemJSON{
+		Probtype: "WordProblem",
+		Args:     []string{text.String(), "?"},
+		Solution: []string{text.String(), strconv.Itoa(result)}}, nil
+}
+
+// return a valid LaTeX/TikZ representation of this word problem using TikZ
+// components
+func (wp wordProblem) GetTikZPicture() (string, error) {
+
+	// -- operands: randomly determine the story to tell. For this, the service
+	//              that generates problems is the one that can marshal them
+	//              into JSON format
+	instance, err := wp.next(annotated(wp))
+	if err != nil {
+		return "", fmt.Errorf("error while generating a valid word problem: %v", err)
+	}
+
+	// -- Coordinates
+
+	// Bottom is the lower-left corner of the bounding box
+	bottom := components.NewCoordinate(components.Point{
+		X: 0.0,
+		Y: 0.0,
+	}, "bottom")
+
+	// -- story: it is written within a paragraph which is anchored at its
+	//           lower-left corner right above the answer box. The node is
+	//           named so that the bounding box can be computed from it
+	story := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(`$(bottom) + (0.25cm, 0.35cm + \zeroheight + \baselineskip)$`),
+			"story"),
+		"name=storytext, anchor=south west, text width=6.5cm, align=justify",
+		`\large `+instance.Args[0])
+
+	// -- answer: the width of the box is computed from the solution so that it
+	//            fits comfortably
+	width := 2.0 + float64(len(instance.Solution[1]))
+	answer := components.NewCoordinatedText(
+		components.NewCoordinate(
+			components.Formula(`$(bottom) + (6.75cm, 0.25cm)$`),
+			"answer"),
+		fmt.Sprintf(`name=answerbox, anchor=south east, rounded corners, rectangle, minimum width=%v\zerowidth, minimum height = \zeroheight + \baselineskip, draw`,
+			helpers.Ftoa(width)),
+		wp.answer(instance.Solution[1]))
+
+	// -- bounding box: its upper-right corner is located right above the story
+	right := components.NewCoordinate(
+		components.Formula(`$(storytext.north -| answerbox.east) + (0.25cm, 0.25cm)$`),
+		"right")
+	bBox := components.NewCoordinatedRectangle(bottom, right)
+	bBox.SetOptions("white")
+
+	// And put all these elements together to show up the picture of the word
+	// problem
+	wordProblemPicture := wordProblemTikZ{
+		Bottom: bottom,
+		Story:  story,
+		Answer: answer,
+		BBox:   bBox,
+	}
+
+	// and return the TikZ code necessary for drawing the problem
+	return wordProblemPicture.execute()
+}
+
+// Return TikZ code that represents a word problem
+func (wp wordProblem) execute() (string, error) {
+
+	// create a template with the TikZ code for showing this word problem
+	tpl, err := template.New("wordProblem").Parse(latexWordProblemCode)
+	if err != nil {
+		return "", err
+	}
+
+	// and now make the appropriate substitutions. Note that the execution of the
+	// template is written to a string
+	var tplOutput bytes.Buffer
+	if err := tpl.Execute(&tplOutput, wp); err != nil {
+		return "", err
+	}
+
+	// and return the resulting string
+	return tplOutput.String(), nil
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
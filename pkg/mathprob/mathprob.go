@@ -0,0 +1,187 @@
+// -*- coding: utf-8 -*-
+// mathprob.go
+//
+// Description: Stable API for generating math problems from Go programs
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 07:12:31.508113429 (1792134751)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+// Package mathprob provides a stable API for generating math problems from Go
+// programs. Every type of problem is defined with its typed options, e.g.:
+//
+//	prob, err := mathprob.NewBasicOperation(mathprob.BasicOperationOptions{
+//		Type:         mathprob.BORESULT,
+//		Operator:     "+",
+//		NbOperands:   2,
+//		NbDigitsOp:   3,
+//		NbDigitsRslt: 4,
+//	}, 0)
+//
+// and the problems generated can be then exported in JSON format or drawn in
+// LaTeX/TikZ format with or without their answers
+package mathprob
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// Every problem generated consists of its type, its arguments and its
+// solution. Those arguments that have to be filled in by the student are
+// marked with a question mark "?" and their solution is given in the same
+// position
+type Problem interface {
+
+	// return the name of the type of this problem, e.g., "BasicOperation"
+	Type() string
+
+	// return the arguments of this problem
+	Args() []string
+
+	// return the solution of this problem
+	Solution() []string
+
+	// return the intermediate steps of the solution of this problem, if any
+	Steps() []string
+
+	// return the difficulty of this problem in the range [MINDIFFICULTY,
+	// MAXDIFFICULTY]
+	Difficulty() int
+
+	// return the skill exercised by this problem, e.g., "arithmetic.addition"
+	Skill() string
+
+	// return the tags that describe this problem, e.g., "carrying"
+	Tags() []string
+
+	// return this problem in JSON format as in the JSON API
+	JSON() ([]byte, error)
+
+	// return the LaTeX/TikZ code that draws this problem
+	TikZ() (string, error)
+
+	// return the LaTeX/TikZ code that draws this problem with its answers
+	AnswerKey() (string, error)
+}
+
+// Every problem type is defined with its typed options. All of them are
+// defined in this package (e.g., BasicOperationOptions)
+type Options = mathtools.Options
+
+// Problems are generated from a master problem which is also used to draw them
+type problem struct {
+	master   mathtools.MasterProblem
+	instance mathtools.ProblemJSON
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return a new problem defined with the given options. The same problem is
+// generated every time the same seed is given unless it is zero, in which case
+// it is taken from the current time. If the options are not correct, or no
+// problem can be generated with them, an error is returned
+func New(options Options, seed int64) (Problem, error) {
+
+	problems, err := Generate(context.Background(), options, 1, seed)
+	if err != nil {
+		return nil, err
+	}
+	return problems[0], nil
+}
+
+// return the given number of problems defined with the given options, all
+// generated from the same seed (see New). Consecutive problems with the same
+// arguments are avoided. The generation stops as soon as the given context is
+// done
+func Generate(ctx context.Context, options Options, nbprobs int, seed int64) ([]Problem, error) {
+
+	master, err := mathtools.NewMasterProblem(options, nbprobs, true)
+	if err != nil {
+		return nil, err
+	}
+	master.Seed = seed
+	instances, err := mathtools.GenerateProblems(ctx, []mathtools.MasterProblem{master})
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]Problem, 0, len(instances))
+	for _, instance := range instances {
+		problems = append(problems, problem{master: master, instance: instance})
+	}
+	return problems, nil
+}
+
+// select the locale with the given ISO 639-1 code (e.g., "es") for drawing all
+// problems from now on, or the default conventions if an empty code is given
+func SetLocale(code string) error {
+	return mathtools.SetLocale(code)
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- problem
+
+// return the name of the type of this problem
+func (prob problem) Type() string {
+	return prob.instance.Probtype
+}
+
+// return a copy of the arguments of this problem
+func (prob problem) Args() []string {
+	return append([]string(nil), prob.instance.Args...)
+}
+
+// return a copy of the solution of this problem
+func (prob problem) Solution() []string {
+	return append([]string(nil), prob.instance.Solution...)
+}
+
+// return a copy of the intermediate steps of the solution of this problem
+func (prob problem) Steps() []string {
+	return append([]string(nil), prob.instance.Steps...)
+}
+
+// return the difficulty of this problem
+func (prob problem) Difficulty() int {
+	return prob.instance.Difficulty
+}
+
+// return the skill exercised by this problem
+func (prob problem) Skill() string {
+	return prob.instance.Skill
+}
+
+// return a copy of the tags that describe this problem
+func (prob problem) Tags() []string {
+	return append([]string(nil), prob.instance.Tags...)
+}
+
+// return this problem in JSON format
+func (prob problem) JSON() ([]byte, error) {
+	return json.Marshal(prob.instance)
+}
+
+// return the LaTeX/TikZ code that draws this problem without its answers
+func (prob problem) TikZ() (string, error) {
+	return prob.master.TikZ(prob.instance, false)
+}
+
+// return the LaTeX/TikZ code that draws this problem with its answers
+func (prob problem) AnswerKey() (string, error) {
+	return prob.master.TikZ(prob.instance, true)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
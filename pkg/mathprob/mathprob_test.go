@@ -0,0 +1,213 @@
+// -*- coding: utf-8 -*-
+// mathprob_test.go
+//
+// Description: Tests of the stable API for generating math problems
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 09:02:17.482913027 (1792177337)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathprob
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// every constructor generates a problem of its type which can be exported in
+// JSON format and drawn both with and without its answers
+func TestConstructors(t *testing.T) {
+
+	basicOperation := BasicOperationOptions{
+		Type:         BORESULT,
+		Operator:     "+",
+		NbOperands:   2,
+		NbDigitsOp:   2,
+		NbDigitsRslt: 3,
+	}
+	tests := []struct {
+		name string
+		new  func(seed int64) (Problem, error)
+	}{
+		{"Angle", func(seed int64) (Problem, error) {
+			return NewAngle(AngleOptions{Type: ANCLASSIFY, Step: 5}, seed)
+		}},
+		{"BarChart", func(seed int64) (Problem, error) {
+			return NewBarChart(BarChartOptions{
+				Categories: []string{"apples", "pears", "plums", "grapes"},
+				Geq:        0, Leq: 10, Mode: "read",
+				Questions: []string{"value", "most", "difference"}}, seed)
+		}},
+		{"BasicOperation", func(seed int64) (Problem, error) {
+			return NewBasicOperation(basicOperation, seed)
+		}},
+		{"Clock", func(seed int64) (Problem, error) {
+			return NewClock(ClockOptions{Type: CLOCKREAD, Granularity: "quarter"}, seed)
+		}},
+		{"CountingObjects", func(seed int64) (Problem, error) {
+			return NewCountingObjects(CountingObjectsOptions{Geq: 3, Leq: 12, Symbol: "star"}, seed)
+		}},
+		{"Division", func(seed int64) (Problem, error) {
+			return NewDivision(DivisionOptions{NbDvDigits: 3, NbDrDigits: 1, NbQDigits: 2, Remainder: "random"}, seed)
+		}},
+		{"ElapsedTime", func(seed int64) (Problem, error) {
+			return NewElapsedTime(ElapsedTimeOptions{Granularity: "five", From: "08:00", To: "21:00", MaxDuration: 120}, seed)
+		}},
+		{"EquivalentFraction", func(seed int64) (Problem, error) {
+			return NewEquivalentFraction(EquivalentFractionOptions{DenGeq: 2, DenLeq: 9, ScaleGeq: 2, ScaleLeq: 5}, seed)
+		}},
+		{"FactFamily", func(seed int64) (Problem, error) {
+			return NewFactFamily(FactFamilyOptions{Operator: "*", Geq: 2, Leq: 9}, seed)
+		}},
+		{"FDPConversion", func(seed int64) (Problem, error) {
+			return NewFDPConversion(FDPConversionOptions{From: "fraction", To: "percent", DenLeq: 20}, seed)
+		}},
+		{"LinearEquation", func(seed int64) (Problem, error) {
+			return NewLinearEquation(LinearEquationOptions{Type: 1, Geq: 0, Leq: 20, CoefLeq: 9}, seed)
+		}},
+		{"MagicSquare", func(seed int64) (Problem, error) {
+			return NewMagicSquare(MagicSquareOptions{Size: 3, Geq: 15, Leq: 45, NbRevealed: 4}, seed)
+		}},
+		{"MathCrossword", func(seed int64) (Problem, error) {
+			return NewMathCrossword(MathCrosswordOptions{Size: 2, Geq: 1, Leq: 20, Operators: "+-", NbMasked: 4}, seed)
+		}},
+		{"MixedDrill", func(seed int64) (Problem, error) {
+			return NewMixedDrill(MixedDrillOptions{Operations: []BasicOperationOptions{basicOperation}, Count: 4, Cols: 2}, seed)
+		}},
+		{"Money", func(seed int64) (Problem, error) {
+			return NewMoney(MoneyOptions{Type: 1, Currency: "EUR", Geq: 1, Leq: 20, Decimals: true, NbItems: 2}, seed)
+		}},
+		{"MultiplicationTable", func(seed int64) (Problem, error) {
+			return NewMultiplicationTable(MultiplicationTableOptions{NbDigits: 1, Geq: 1, Leq: 10}, seed)
+		}},
+		{"MysteryOperation", func(seed int64) (Problem, error) {
+			return NewMysteryOperation(MysteryOperationOptions{
+				NbDigits1: 5, NbMasked1: 2, NbDigits2: 5, NbMasked2: 1,
+				NbDigitsAnswer: 6, NbMaskedAnswer: 1, Operator: "+"}, seed)
+		}},
+		{"NumberClassification", func(seed int64) (Problem, error) {
+			return NewNumberClassification(NumberClassificationOptions{Type: 1, Geq: 1, Leq: 50, NbNumbers: 15, Multiple: 3, NbCols: 5}, seed)
+		}},
+		{"NumberComparison", func(seed int64) (Problem, error) {
+			return NewNumberComparison(NumberComparisonOptions{NbDigits: 3, NbItems: 2}, seed)
+		}},
+		{"NumberForm", func(seed int64) (Problem, error) {
+			return NewNumberForm(NumberFormOptions{From: "standard", To: "words", NbDigits: 4, Locale: "en"}, seed)
+		}},
+		{"NumberLine", func(seed int64) (Problem, error) {
+			return NewNumberLine(NumberLineOptions{Geq: 0, Leq: 50, Step: 5, NbTicks: 8}, seed)
+		}},
+		{"NumberPyramid", func(seed int64) (Problem, error) {
+			return NewNumberPyramid(NumberPyramidOptions{Height: 4, Geq: 1, Leq: 10, Reveal: "random", NbRevealed: 4}, seed)
+		}},
+		{"Percentage", func(seed int64) (Problem, error) {
+			return NewPercentage(PercentageOptions{Geq: 10, Leq: 200, PctGeq: 5, PctLeq: 100}, seed)
+		}},
+		{"PerimeterArea", func(seed int64) (Problem, error) {
+			return NewPerimeterArea(PerimeterAreaOptions{Type: 1, Shape: "rectangle", Geq: 2, Leq: 12}, seed)
+		}},
+		{"Pictogram", func(seed int64) (Problem, error) {
+			return NewPictogram(PictogramOptions{
+				Categories: []string{"cats", "dogs", "birds", "fish"},
+				Geq:        1, Leq: 12, Chart: "tally", Symbol: "star",
+				Questions: []string{"most", "least", "total"}}, seed)
+		}},
+		{"PlaceValue", func(seed int64) (Problem, error) {
+			return NewPlaceValue(PlaceValueOptions{NbDigits: 4, Masked: []string{"hundreds", "units"}}, seed)
+		}},
+		{"PrimeFactorization", func(seed int64) (Problem, error) {
+			return NewPrimeFactorization(PrimeFactorizationOptions{Geq: 12, Leq: 200, MaxFactors: 4}, seed)
+		}},
+		{"Ratio", func(seed int64) (Problem, error) {
+			return NewRatio(RatioOptions{Geq: 1, Leq: 5, ScaleGeq: 2, ScaleLeq: 4}, seed)
+		}},
+		{"Rounding", func(seed int64) (Problem, error) {
+			return NewRounding(RoundingOptions{NbDigits: 4, Place: "hundred", Highlight: true}, seed)
+		}},
+		{"Sequence", func(seed int64) (Problem, error) {
+			return NewSequence(SequenceOptions{NbItems: 5, Geq: 100, Leq: 999, Pattern: "arithmetic", Step: 1}, seed)
+		}},
+		{"ShadedFraction", func(seed int64) (Problem, error) {
+			return NewShadedFraction(ShadedFractionOptions{Shape: "pie", DenGeq: 2, DenLeq: 8}, seed)
+		}},
+		{"UnitConversion", func(seed int64) (Problem, error) {
+			return NewUnitConversion(UnitConversionOptions{Quantity: "length", Direction: "both", Geq: 1, Leq: 100, Decimals: 1}, seed)
+		}},
+		{"WordProblem", func(seed int64) (Problem, error) {
+			return NewWordProblem(WordProblemOptions{Operator: "+", Geq: 10, Leq: 99}, seed)
+		}},
+	}
+
+	// all constructors of this package are tested
+	file, err := parser.ParseFile(token.NewFileSet(), "options.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tested := make(map[string]bool)
+	for _, test := range tests {
+		tested["New"+test.name] = true
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "New") && !tested[fn.Name.Name] {
+			t.Errorf("the constructor '%v' is not tested", fn.Name.Name)
+		}
+	}
+
+	// mixed drills generate problems of the type of their operations
+	probtypes := map[string]string{"MixedDrill": "BasicOperation"}
+	for _, test := range tests {
+		probtype, ok := probtypes[test.name]
+		if !ok {
+			probtype = test.name
+		}
+		prob, err := test.new(5)
+		if err != nil {
+			t.Errorf("%v: the problem could not be generated: %v", test.name, err)
+			continue
+		}
+		if prob.Type() != probtype {
+			t.Errorf("%v: expected a problem of type '%v' but got '%v'", test.name, probtype, prob.Type())
+		}
+		if contents, err := prob.JSON(); err != nil || !json.Valid(contents) {
+			t.Errorf("%v: the problem could not be exported in JSON format: %v", test.name, err)
+		}
+		if tikz, err := prob.TikZ(); err != nil || tikz == "" {
+			t.Errorf("%v: the problem could not be drawn: %v", test.name, err)
+		}
+		if answers, err := prob.AnswerKey(); err != nil || answers == "" {
+			t.Errorf("%v: the problem could not be drawn with its answers: %v", test.name, err)
+		}
+
+		// the same problem is generated again with the same seed
+		if other, err := test.new(5); err != nil {
+			t.Errorf("%v: the problem could not be generated again: %v", test.name, err)
+		} else if first, second := prob.(problem).instance, other.(problem).instance; !equalStrings(first.Args, second.Args) || !equalStrings(first.Solution, second.Solution) {
+			t.Errorf("%v: expected the same problem with the same seed but got %v and %v", test.name, first, second)
+		}
+	}
+}
+
+// return true if and only if both slices have the same strings in the same
+// order
+func equalStrings(strings1, strings2 []string) bool {
+
+	if len(strings1) != len(strings2) {
+		return false
+	}
+	for idx, item := range strings1 {
+		if item != strings2[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,535 @@
+// -*- coding: utf-8 -*-
+// options.go
+//
+// Description: Typed options and constructors of every type of problem
+// -----------------------------------------------------------------------------
+//
+// Started on <vie 16-10-2026 07:20:04.916254871 (1792135204)>
+// Carlos Linares López <carlos.linares@uc3m.es>
+//
+
+package mathprob
+
+import "github.com/clinaresl/mathprob/mathtools"
+
+// constants
+// ----------------------------------------------------------------------------
+
+// The difficulty of problems is rated in the range [MINDIFFICULTY,
+// MAXDIFFICULTY]
+const (
+	MINDIFFICULTY = mathtools.MINDIFFICULTY
+	MAXDIFFICULTY = mathtools.MAXDIFFICULTY
+)
+
+// Types and modes of angle problems
+const (
+	ANCLASSIFY = mathtools.ANCLASSIFY
+	ANMEASURE  = mathtools.ANMEASURE
+	ANACUTE    = mathtools.ANACUTE
+	ANRIGHT    = mathtools.ANRIGHT
+	ANOBTUSE   = mathtools.ANOBTUSE
+)
+
+// Types and modes of bar charts
+const (
+	BCREAD       = mathtools.BCREAD
+	BCDRAW       = mathtools.BCDRAW
+	BCVALUE      = mathtools.BCVALUE
+	BCMOST       = mathtools.BCMOST
+	BCLEAST      = mathtools.BCLEAST
+	BCDIFFERENCE = mathtools.BCDIFFERENCE
+)
+
+// Types and modes of basic operations
+const (
+	BORESULT     = mathtools.BORESULT
+	BOOPERAND    = mathtools.BOOPERAND
+	BOVERTICAL   = mathtools.BOVERTICAL
+	BOHORIZONTAL = mathtools.BOHORIZONTAL
+
+	BOCARRYANY       = mathtools.BOCARRYANY
+	BOCARRYREQUIRED  = mathtools.BOCARRYREQUIRED
+	BOCARRYFORBIDDEN = mathtools.BOCARRYFORBIDDEN
+)
+
+// Types and modes of clocks
+const (
+	CLOCKREAD = mathtools.CLOCKREAD
+	CLOCKDRAW = mathtools.CLOCKDRAW
+)
+
+// Layouts and remainders of divisions
+const (
+	DIVBOX     = mathtools.DIVBOX
+	DIVBRACKET = mathtools.DIVBRACKET
+	DIVPOTENCE = mathtools.DIVPOTENCE
+	DIVRANDOM  = mathtools.DIVRANDOM
+	DIVEXACT   = mathtools.DIVEXACT
+	DIVNONZERO = mathtools.DIVNONZERO
+)
+
+// Types and modes of elapsed time problems
+const (
+	ETEND      = mathtools.ETEND
+	ETDURATION = mathtools.ETDURATION
+	ETSTART    = mathtools.ETSTART
+)
+
+// Types and modes of equivalent fractions
+const (
+	EFNUMERATOR   = mathtools.EFNUMERATOR
+	EFDENOMINATOR = mathtools.EFDENOMINATOR
+)
+
+// Types and modes of linear equations
+const (
+	LEONESTEP = mathtools.LEONESTEP
+	LETWOSTEP = mathtools.LETWOSTEP
+)
+
+// Types and modes of money problems
+const (
+	MONEYTOTAL  = mathtools.MONEYTOTAL
+	MONEYCHANGE = mathtools.MONEYCHANGE
+)
+
+// Types and modes of multiplication tables
+const (
+	MTRESULT    = mathtools.MTRESULT
+	MTOPERAND   = mathtools.MTOPERAND
+	MTBLOCK     = mathtools.MTBLOCK
+	MTTWOCOLUMN = mathtools.MTTWOCOLUMN
+	MTGRID      = mathtools.MTGRID
+)
+
+// Types and modes of number classification problems
+const (
+	CLASSEVENODD  = mathtools.CLASSEVENODD
+	CLASSMULTIPLE = mathtools.CLASSMULTIPLE
+)
+
+// Types and modes of number form problems
+const (
+	NFSTANDARD = mathtools.NFSTANDARD
+	NFEXPANDED = mathtools.NFEXPANDED
+	NFWORDS    = mathtools.NFWORDS
+)
+
+// Types and modes of number lines
+const (
+	NLFILL = mathtools.NLFILL
+	NLMARK = mathtools.NLMARK
+)
+
+// Types and modes of number pyramids
+const (
+	PYRBASE   = mathtools.PYRBASE
+	PYRRANDOM = mathtools.PYRRANDOM
+)
+
+// Types and modes of percentage problems
+const (
+	PCRESULT  = mathtools.PCRESULT
+	PCPERCENT = mathtools.PCPERCENT
+	PCBASE    = mathtools.PCBASE
+)
+
+// Types and modes of perimeter and area problems
+const (
+	PAPERIMETER = mathtools.PAPERIMETER
+	PAAREA      = mathtools.PAAREA
+	PASIDE      = mathtools.PASIDE
+	PARECTANGLE = mathtools.PARECTANGLE
+	PATRIANGLE  = mathtools.PATRIANGLE
+)
+
+// Types and modes of pictograms
+const (
+	PICTALLY     = mathtools.PICTALLY
+	PICPICTOGRAM = mathtools.PICPICTOGRAM
+	PICMOST      = mathtools.PICMOST
+	PICLEAST     = mathtools.PICLEAST
+	PICTOTAL     = mathtools.PICTOTAL
+)
+
+// Types and modes of place value problems
+const (
+	PVDECOMPOSE = mathtools.PVDECOMPOSE
+	PVCOMPOSE   = mathtools.PVCOMPOSE
+)
+
+// Types and modes of ratio problems
+const (
+	RTSECOND = mathtools.RTSECOND
+	RTFIRST  = mathtools.RTFIRST
+)
+
+// Types and modes of rounding problems
+const (
+	ROUNDUP   = mathtools.ROUNDUP
+	ROUNDEVEN = mathtools.ROUNDEVEN
+	ROUNDAWAY = mathtools.ROUNDAWAY
+)
+
+// Types and modes of sequences
+const (
+	SEQNONE        = mathtools.SEQNONE
+	SEQFIRST       = mathtools.SEQFIRST
+	SEQLAST        = mathtools.SEQLAST
+	SEQBOTH        = mathtools.SEQBOTH
+	SEQARITHMETIC  = mathtools.SEQARITHMETIC
+	SEQGEOMETRIC   = mathtools.SEQGEOMETRIC
+	SEQALTERNATING = mathtools.SEQALTERNATING
+	SEQFIBONACCI   = mathtools.SEQFIBONACCI
+)
+
+// Types and modes of shaded fractions
+const (
+	SFREAD  = mathtools.SFREAD
+	SFSHADE = mathtools.SFSHADE
+)
+
+// Types and modes of unit conversions
+const (
+	UCDOWN = mathtools.UCDOWN
+	UCUP   = mathtools.UCUP
+	UCBOTH = mathtools.UCBOTH
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// Options of angle problems. See mathtools.AngleOptions for a description of
+// all their fields
+type AngleOptions = mathtools.AngleOptions
+
+// Options of bar charts. See mathtools.BarChartOptions for a description of all
+// their fields
+type BarChartOptions = mathtools.BarChartOptions
+
+// Options of basic operations. See mathtools.BasicOperationOptions for a
+// description of all their fields
+type BasicOperationOptions = mathtools.BasicOperationOptions
+
+// Options of clocks. See mathtools.ClockOptions for a description of all their
+// fields
+type ClockOptions = mathtools.ClockOptions
+
+// Options of counting problems. See mathtools.CountingObjectsOptions for a
+// description of all their fields
+type CountingObjectsOptions = mathtools.CountingObjectsOptions
+
+// Options of divisions. See mathtools.DivisionOptions for a description of all
+// their fields
+type DivisionOptions = mathtools.DivisionOptions
+
+// Options of elapsed time problems. See mathtools.ElapsedTimeOptions for a
+// description of all their fields
+type ElapsedTimeOptions = mathtools.ElapsedTimeOptions
+
+// Options of equivalent fractions. See mathtools.EquivalentFractionOptions for
+// a description of all their fields
+type EquivalentFractionOptions = mathtools.EquivalentFractionOptions
+
+// Options of fact families. See mathtools.FactFamilyOptions for a description
+// of all their fields
+type FactFamilyOptions = mathtools.FactFamilyOptions
+
+// Options of conversions among fractions, decimals and percentages. See
+// mathtools.FDPConversionOptions for a description of all their fields
+type FDPConversionOptions = mathtools.FDPConversionOptions
+
+// Options of linear equations. See mathtools.LinearEquationOptions for a
+// description of all their fields
+type LinearEquationOptions = mathtools.LinearEquationOptions
+
+// Options of magic squares. See mathtools.MagicSquareOptions for a description
+// of all their fields
+type MagicSquareOptions = mathtools.MagicSquareOptions
+
+// Options of math crosswords. See mathtools.MathCrosswordOptions for a
+// description of all their fields
+type MathCrosswordOptions = mathtools.MathCrosswordOptions
+
+// Options of mixed drills. See mathtools.MixedDrillOptions for a description of
+// all their fields
+type MixedDrillOptions = mathtools.MixedDrillOptions
+
+// Options of money problems. See mathtools.MoneyOptions for a description of
+// all their fields
+type MoneyOptions = mathtools.MoneyOptions
+
+// Options of multiplication tables. See mathtools.MultiplicationTableOptions
+// for a description of all their fields
+type MultiplicationTableOptions = mathtools.MultiplicationTableOptions
+
+// Options of mystery operations. See mathtools.MysteryOperationOptions for a
+// description of all their fields
+type MysteryOperationOptions = mathtools.MysteryOperationOptions
+
+// Options of number classification problems. See
+// mathtools.NumberClassificationOptions for a description of all their fields
+type NumberClassificationOptions = mathtools.NumberClassificationOptions
+
+// Options of number comparisons. See mathtools.NumberComparisonOptions for a
+// description of all their fields
+type NumberComparisonOptions = mathtools.NumberComparisonOptions
+
+// Options of number form problems. See mathtools.NumberFormOptions for a
+// description of all their fields
+type NumberFormOptions = mathtools.NumberFormOptions
+
+// Options of number lines. See mathtools.NumberLineOptions for a description of
+// all their fields
+type NumberLineOptions = mathtools.NumberLineOptions
+
+// Options of number pyramids. See mathtools.NumberPyramidOptions for a
+// description of all their fields
+type NumberPyramidOptions = mathtools.NumberPyramidOptions
+
+// Options of percentage problems. See mathtools.PercentageOptions for a
+// description of all their fields
+type PercentageOptions = mathtools.PercentageOptions
+
+// Options of perimeter and area problems. See mathtools.PerimeterAreaOptions
+// for a description of all their fields
+type PerimeterAreaOptions = mathtools.PerimeterAreaOptions
+
+// Options of pictograms. See mathtools.PictogramOptions for a description of
+// all their fields
+type PictogramOptions = mathtools.PictogramOptions
+
+// Options of place value problems. See mathtools.PlaceValueOptions for a
+// description of all their fields
+type PlaceValueOptions = mathtools.PlaceValueOptions
+
+// Options of prime factorizations. See mathtools.PrimeFactorizationOptions for
+// a description of all their fields
+type PrimeFactorizationOptions = mathtools.PrimeFactorizationOptions
+
+// Options of ratio problems. See mathtools.RatioOptions for a description of
+// all their fields
+type RatioOptions = mathtools.RatioOptions
+
+// Options of rounding problems. See mathtools.RoundingOptions for a description
+// of all their fields
+type RoundingOptions = mathtools.RoundingOptions
+
+// Options of sequences. See mathtools.SequenceOptions for a description of all
+// their fields
+type SequenceOptions = mathtools.SequenceOptions
+
+// Options of shaded fractions. See mathtools.ShadedFractionOptions for a
+// description of all their fields
+type ShadedFractionOptions = mathtools.ShadedFractionOptions
+
+// Options of unit conversions. See mathtools.UnitConversionOptions for a
+// description of all their fields
+type UnitConversionOptions = mathtools.UnitConversionOptions
+
+// Options of word problems. See mathtools.WordProblemOptions for a description
+// of all their fields
+type WordProblemOptions = mathtools.WordProblemOptions
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return a new problem of type Angle defined with the given options (see New)
+func NewAngle(options AngleOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type BarChart defined with the given options (see
+// New)
+func NewBarChart(options BarChartOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type BasicOperation defined with the given options
+// (see New)
+func NewBasicOperation(options BasicOperationOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Clock defined with the given options (see New)
+func NewClock(options ClockOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type CountingObjects defined with the given options
+// (see New)
+func NewCountingObjects(options CountingObjectsOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Division defined with the given options (see
+// New)
+func NewDivision(options DivisionOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type ElapsedTime defined with the given options (see
+// New)
+func NewElapsedTime(options ElapsedTimeOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type EquivalentFraction defined with the given
+// options (see New)
+func NewEquivalentFraction(options EquivalentFractionOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type FactFamily defined with the given options (see
+// New)
+func NewFactFamily(options FactFamilyOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type FDPConversion defined with the given options
+// (see New)
+func NewFDPConversion(options FDPConversionOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type LinearEquation defined with the given options
+// (see New)
+func NewLinearEquation(options LinearEquationOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type MagicSquare defined with the given options (see
+// New)
+func NewMagicSquare(options MagicSquareOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type MathCrossword defined with the given options
+// (see New)
+func NewMathCrossword(options MathCrosswordOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type MixedDrill defined with the given options (see
+// New)
+func NewMixedDrill(options MixedDrillOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Money defined with the given options (see New)
+func NewMoney(options MoneyOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type MultiplicationTable defined with the given
+// options (see New)
+func NewMultiplicationTable(options MultiplicationTableOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type MysteryOperation defined with the given options
+// (see New)
+func NewMysteryOperation(options MysteryOperationOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type NumberClassification defined with the given
+// options (see New)
+func NewNumberClassification(options NumberClassificationOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type NumberComparison defined with the given options
+// (see New)
+func NewNumberComparison(options NumberComparisonOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type NumberForm defined with the given options (see
+// New)
+func NewNumberForm(options NumberFormOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type NumberLine defined with the given options (see
+// New)
+func NewNumberLine(options NumberLineOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type NumberPyramid defined with the given options
+// (see New)
+func NewNumberPyramid(options NumberPyramidOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Percentage defined with the given options (see
+// New)
+func NewPercentage(options PercentageOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type PerimeterArea defined with the given options
+// (see New)
+func NewPerimeterArea(options PerimeterAreaOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Pictogram defined with the given options (see
+// New)
+func NewPictogram(options PictogramOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type PlaceValue defined with the given options (see
+// New)
+func NewPlaceValue(options PlaceValueOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type PrimeFactorization defined with the given
+// options (see New)
+func NewPrimeFactorization(options PrimeFactorizationOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Ratio defined with the given options (see New)
+func NewRatio(options RatioOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Rounding defined with the given options (see
+// New)
+func NewRounding(options RoundingOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type Sequence defined with the given options (see
+// New)
+func NewSequence(options SequenceOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type ShadedFraction defined with the given options
+// (see New)
+func NewShadedFraction(options ShadedFractionOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type UnitConversion defined with the given options
+// (see New)
+func NewUnitConversion(options UnitConversionOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// return a new problem of type WordProblem defined with the given options (see
+// New)
+func NewWordProblem(options WordProblemOptions, seed int64) (Problem, error) {
+	return New(options, seed)
+}
+
+// Local Variables:
+// mode:go
+// fill-column:80
+// End:
@@ -0,0 +1,165 @@
+/*
+  protobuf.go
+  Description: Encoding and decoding of the protocol buffers used by the gRPC
+  service
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 06:10:33 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Wire types of protocol buffers. Only varints and length-delimited values are
+// used by the messages of the gRPC service, but fixed-size values are skipped
+// correctly
+const (
+	PBVARINT  int = 0
+	PBFIXED64 int = 1
+	PBBYTES   int = 2
+	PBFIXED32 int = 5
+)
+
+// types
+// ----------------------------------------------------------------------------
+
+// A protocol buffer encoder appends the fields of a message one after the
+// other. Fields with default values (zero, false or empty) are omitted as in
+// proto3
+type protoEncoder struct {
+	data []byte
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// decode all the fields of the given protocol buffer and invoke the given
+// function with the number of every field, its wire type and its value, which
+// is given either as an integer (varints) or as a slice of bytes
+// (length-delimited). Fields with fixed-size values are skipped. If the data is
+// malformed or the function returns an error, it is returned
+func protoDecode(data []byte, field func(number, wiretype int, value uint64, bytes []byte) error) error {
+
+	for len(data) > 0 {
+
+		// every field starts with a key that contains its number and wire type
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("malformed key in protocol buffer")
+		}
+		data = data[n:]
+		number, wiretype := int(key>>3), int(key&7)
+
+		var value uint64
+		var bytes []byte
+		switch wiretype {
+		case PBVARINT:
+			if value, n = binary.Uvarint(data); n <= 0 {
+				return errors.New("malformed varint in protocol buffer")
+			}
+			data = data[n:]
+		case PBBYTES:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errors.New("malformed length-delimited value in protocol buffer")
+			}
+			bytes, data = data[n:n+int(length)], data[n+int(length):]
+		case PBFIXED64, PBFIXED32:
+			size := 8
+			if wiretype == PBFIXED32 {
+				size = 4
+			}
+			if len(data) < size {
+				return errors.New("malformed fixed-size value in protocol buffer")
+			}
+			data = data[size:]
+			continue
+		default:
+			return errors.New("unsupported wire type in protocol buffer")
+		}
+		if err := field(number, wiretype, value, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- protoEncoder
+
+// append the key of a field with the given number and wire type
+func (enc *protoEncoder) key(number, wiretype int) {
+	enc.data = binary.AppendUvarint(enc.data, uint64(number)<<3|uint64(wiretype))
+}
+
+// append a field with the given number and integer value. Negative values are
+// encoded with ten bytes as in the types int32 and int64
+func (enc *protoEncoder) Int(number int, value int64) {
+
+	if value != 0 {
+		enc.key(number, PBVARINT)
+		enc.data = binary.AppendUvarint(enc.data, uint64(value))
+	}
+}
+
+// append a field with the given number and boolean value
+func (enc *protoEncoder) Bool(number int, value bool) {
+
+	if value {
+		enc.Int(number, 1)
+	}
+}
+
+// append a field with the given number and bytes
+func (enc *protoEncoder) Bytes(number int, value []byte) {
+
+	if len(value) > 0 {
+		enc.key(number, PBBYTES)
+		enc.data = binary.AppendUvarint(enc.data, uint64(len(value)))
+		enc.data = append(enc.data, value...)
+	}
+}
+
+// append a field with the given number and string
+func (enc *protoEncoder) String(number int, value string) {
+	enc.Bytes(number, []byte(value))
+}
+
+// append a repeated field with the given number and strings. Note that, unlike
+// the others, empty strings are also encoded so that all items are preserved
+func (enc *protoEncoder) Strings(number int, values []string) {
+
+	for _, value := range values {
+		enc.key(number, PBBYTES)
+		enc.data = binary.AppendUvarint(enc.data, uint64(len(value)))
+		enc.data = append(enc.data, value...)
+	}
+}
+
+// append a field with the given number and the message encoded in the given
+// encoder. Embedded messages are always encoded, even if they are empty
+func (enc *protoEncoder) Message(number int, message protoEncoder) {
+
+	enc.key(number, PBBYTES)
+	enc.data = binary.AppendUvarint(enc.data, uint64(len(message.data)))
+	enc.data = append(enc.data, message.data...)
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
@@ -0,0 +1,143 @@
+/*
+  protobuf_test.go
+  Description: Tests of the encoding and decoding of protocol buffers
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 09:58:12 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// a field decoded from a protocol buffer
+type protoField struct {
+	number, wiretype int
+	value            uint64
+	bytes            string
+}
+
+// return all the fields of the given protocol buffer
+func protoFields(t *testing.T, data []byte) []protoField {
+
+	t.Helper()
+	var fields []protoField
+	if err := protoDecode(data, func(number, wiretype int, value uint64, bytes []byte) error {
+		fields = append(fields, protoField{number, wiretype, value, string(bytes)})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+// fields are encoded as specified in the documentation of protocol buffers
+func TestProtoEncoderWireFormat(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		encode   func(enc *protoEncoder)
+		expected []byte
+	}{
+		{"varint", func(enc *protoEncoder) { enc.Int(1, 150) },
+			[]byte{0x08, 0x96, 0x01}},
+		{"negative varint", func(enc *protoEncoder) { enc.Int(2, -1) },
+			[]byte{0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{"string", func(enc *protoEncoder) { enc.String(2, "testing") },
+			[]byte{0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g'}},
+		{"bool", func(enc *protoEncoder) { enc.Bool(5, true) },
+			[]byte{0x28, 0x01}},
+		{"repeated strings", func(enc *protoEncoder) { enc.Strings(3, []string{"a", ""}) },
+			[]byte{0x1a, 0x01, 'a', 0x1a, 0x00}},
+		{"embedded message", func(enc *protoEncoder) {
+			var message protoEncoder
+			message.Int(1, 150)
+			enc.Message(3, message)
+		}, []byte{0x1a, 0x03, 0x08, 0x96, 0x01}},
+		{"empty embedded message", func(enc *protoEncoder) { enc.Message(1, protoEncoder{}) },
+			[]byte{0x0a, 0x00}},
+		{"default values", func(enc *protoEncoder) {
+			enc.Int(1, 0)
+			enc.Bool(2, false)
+			enc.String(3, "")
+			enc.Strings(4, nil)
+		}, nil},
+	}
+	for _, test := range tests {
+		var enc protoEncoder
+		test.encode(&enc)
+		if !bytes.Equal(enc.data, test.expected) {
+			t.Errorf("%v: expected % x but got % x", test.name, test.expected, enc.data)
+		}
+	}
+}
+
+// all fields encoded are decoded with the same values, and fixed-size fields
+// are skipped
+func TestProtoRoundTrip(t *testing.T) {
+
+	var message protoEncoder
+	message.String(1, "Division")
+	message.Int(2, -7)
+
+	var enc protoEncoder
+	enc.Int(1, 42)
+	enc.Bool(2, true)
+	enc.Strings(3, []string{"12", "?", ""})
+	enc.data = append(enc.data, 0x21, 1, 2, 3, 4, 5, 6, 7, 8) // fixed64 field 4
+	enc.data = append(enc.data, 0x2d, 1, 2, 3, 4)             // fixed32 field 5
+	enc.Message(6, message)
+
+	expected := []protoField{
+		{1, PBVARINT, 42, ""},
+		{2, PBVARINT, 1, ""},
+		{3, PBBYTES, 0, "12"},
+		{3, PBBYTES, 0, "?"},
+		{3, PBBYTES, 0, ""},
+		{6, PBBYTES, 0, string(message.data)},
+	}
+	fields := protoFields(t, enc.data)
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected the fields %v but got %v", expected, fields)
+	}
+
+	// and the embedded message is decoded as well. Negative integers are
+	// recovered by casting their varint
+	embedded := protoFields(t, []byte(fields[len(fields)-1].bytes))
+	if len(embedded) != 2 || embedded[0].bytes != "Division" || int64(embedded[1].value) != -7 {
+		t.Errorf("expected the embedded message with 'Division' and -7 but got %v", embedded)
+	}
+}
+
+// malformed protocol buffers are rejected
+func TestProtoDecodeMalformed(t *testing.T) {
+
+	tests := map[string][]byte{
+		"truncated key":    {0x80},
+		"truncated varint": {0x08, 0x96},
+		"truncated bytes":  {0x12, 0x07, 't', 'e'},
+		"truncated fixed":  {0x21, 1, 2, 3},
+		"wire type":        {0x0b},
+	}
+	for name, data := range tests {
+		if err := protoDecode(data, func(number, wiretype int, value uint64, bytes []byte) error {
+			return nil
+		}); err == nil {
+			t.Errorf("%v: the protocol buffer % x was accepted", name, data)
+		}
+	}
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
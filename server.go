@@ -0,0 +1,227 @@
+/*
+  server.go
+  Description: HTTP server exposing the JSON problem API
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 00:45:02 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// Maximum size in bytes of the body of requests to the server
+const MAXREQUESTSIZE int64 = 1 << 20
+
+// Maximum number of problems that can be requested at once to the server
+const MAXSERVEDPROBLEMS int = 10000
+
+// types
+// ----------------------------------------------------------------------------
+
+// A stream writer sends to the client everything written to it straight away.
+// It records whether anything has been written, since afterwards the status of
+// the response can not be changed anymore
+type streamWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+// functions
+// ----------------------------------------------------------------------------
+
+// write the given error to the client in JSON format with the given HTTP
+// status code
+func serveError(w http.ResponseWriter, status int, err error) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Write(data)
+}
+
+// handles requests for generating problems. The body of the request has the
+// same format than JSON problem files (see -help-json-problem) and the problems
+// generated are returned in the same format used with -json-problems-file.
+// Problems are streamed to the client as soon as they are generated. If an
+// error happens once the response has started, it can not be reported to the
+// client anymore and the response is truncated
+func handleProblems(w http.ResponseWriter, r *http.Request) {
+
+	masterProblem, base, ok := readProblems(w, r)
+	if !ok {
+		return
+	}
+	stream := &streamWriter{w: w}
+	if err := writeJSONProblems(r.Context(), stream, masterProblem, base); err != nil {
+		if !stream.started {
+			serveError(w, http.StatusBadRequest, err)
+			return
+		}
+		log.Printf(" Error: %v", err)
+	}
+}
+
+// handles requests for generating problems rendered in SVG format. The body of
+// the request is the same used for generating problems in JSON format
+func handleProblemsSVG(w http.ResponseWriter, r *http.Request) {
+
+	masterProblem, _, ok := readProblems(w, r)
+	if !ok {
+		return
+	}
+
+	// get the contents of problems in SVG format
+	jsonOutput, err := mathtools.GenerateSVG(r.Context(), masterProblem)
+	if err != nil {
+		serveError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonOutput)
+}
+
+// return the problems requested in the body of the given request, the seed
+// from which the seeds of problems without one are derived and true. If the
+// request is not correct, an error is written to the client and false is
+// returned
+func readProblems(w http.ResponseWriter, r *http.Request) ([]mathtools.MasterProblem, int64, bool) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		serveError(w, http.StatusMethodNotAllowed, fmt.Errorf("method '%v' not allowed", r.Method))
+		return nil, 0, false
+	}
+
+	// read the body of the request, which can not be arbitrarily large
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MAXREQUESTSIZE))
+	if err != nil {
+		serveError(w, http.StatusRequestEntityTooLarge, err)
+		return nil, 0, false
+	}
+
+	// Unmarshall the problems requested and make sure that not too many are
+	// requested at once
+	masterProblem, err := mathtools.Unmarshall(body)
+	if err != nil {
+		serveError(w, http.StatusBadRequest, err)
+		return nil, 0, false
+	}
+	nbprobs := 0
+	for _, problem := range masterProblem {
+		nbprobs += problem.GetNbProbs()
+	}
+	if nbprobs > MAXSERVEDPROBLEMS {
+		serveError(w, http.StatusBadRequest,
+			fmt.Errorf("it is not allowed to request more than %v problems at once", MAXSERVEDPROBLEMS))
+		return nil, 0, false
+	}
+	base := deriveSeeds(masterProblem)
+	return masterProblem, base, true
+}
+
+// handles requests for listing all the problem types supported
+func handleProblemTypes(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		serveError(w, http.StatusMethodNotAllowed, fmt.Errorf("method '%v' not allowed", r.Method))
+		return
+	}
+
+	jsonOutput, err := json.MarshalIndent(mathtools.SupportedTypes(), "", "\t")
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonOutput)
+}
+
+// handles requests for the JSON Schemas of the requests of problems and the
+// problems generated, which are served at /schemas/request.json and
+// /schemas/problem.json respectively
+func handleSchemas(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		serveError(w, http.StatusMethodNotAllowed, fmt.Errorf("method '%v' not allowed", r.Method))
+		return
+	}
+
+	var schema []byte
+	switch r.URL.Path {
+	case "/schemas/request.json":
+		schema = mathtools.RequestSchema()
+	case "/schemas/problem.json":
+		schema = mathtools.ProblemSchema()
+	default:
+		serveError(w, http.StatusNotFound, fmt.Errorf("unknown JSON Schema '%v'", r.URL.Path))
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(schema)
+}
+
+// starts an HTTP server listening at the given address which exposes the JSON
+// problem API with the following endpoints:
+//
+//    POST /problems: generates the problems requested in the body
+//    POST /problems/svg: same as above but problems are rendered in SVG
+//    GET /problems/types: lists all the problem types supported
+//    GET /schemas/request.json, /schemas/problem.json: JSON Schemas of the
+//    requests and the problems generated
+//
+// It only returns if the server can not be started or it stops
+func serve(addr string) error {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/problems", handleProblems)
+	mux.HandleFunc("/problems/svg", handleProblemsSVG)
+	mux.HandleFunc("/problems/types", handleProblemTypes)
+	mux.HandleFunc("/schemas/", handleSchemas)
+
+	log.Printf("Serving the JSON problem API at %v\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// -- streamWriter
+
+// write the given data to the client and flush it. The content type is set the
+// first time
+func (stream *streamWriter) Write(data []byte) (int, error) {
+
+	if !stream.started {
+		stream.w.Header().Set("Content-Type", "application/json")
+		stream.started = true
+	}
+	n, err := stream.w.Write(data)
+	if flusher, ok := stream.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
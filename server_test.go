@@ -0,0 +1,92 @@
+/*
+  server_test.go
+  Description: Tests of the HTTP server exposing the JSON problem API
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 16:12:40 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// return the response of the server to the given request of problems
+func postProblems(t *testing.T, body string) *httptest.ResponseRecorder {
+
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/problems", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handleProblems(rec, req)
+	return rec
+}
+
+// invalid requests are rejected with a 400 and an error in JSON format, and
+// nothing of the envelope is written
+func TestHandleProblemsInvalidRequest(t *testing.T) {
+
+	requests := map[string]string{
+		"unknown type": `[{"type": "Nope", "args": {}, "nbprobs": 1}]`,
+		"wrong args":   `[{"type": "Division", "args": {"nbdvdigits": "x", "nbdrdigits": 1, "nbqdigits": 1}, "nbprobs": 1}]`,
+	}
+	for name, body := range requests {
+		rec := postProblems(t, body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: expected status %v but got %v with body %q", name, http.StatusBadRequest, rec.Code, rec.Body.String())
+			continue
+		}
+		var response map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Errorf("%v: the body %q is not a valid JSON document: %v", name, rec.Body.String(), err)
+			continue
+		}
+		if response["error"] == "" {
+			t.Errorf("%v: no error was reported in %q", name, rec.Body.String())
+		}
+	}
+}
+
+// valid requests are answered with an envelope with all problems requested
+func TestHandleProblemsEnvelope(t *testing.T) {
+
+	rec := postProblems(t, `[{"type": "Division", "args": {"nbdvdigits": 3, "nbdrdigits": 1, "nbqdigits": 2}, "nbprobs": 3, "seed": 1}]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %v but got %v with body %q", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var envelope mathtools.JSONEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("the body %q is not a valid envelope: %v", rec.Body.String(), err)
+	}
+	if envelope.Format != mathtools.JSONFORMATVERSION || len(envelope.Problems) != 3 {
+		t.Errorf("expected an envelope in format %v with 3 problems but got format %v with %v problems",
+			mathtools.JSONFORMATVERSION, envelope.Format, len(envelope.Problems))
+	}
+
+	// the seed, which is derived from the current time, is written as a
+	// string so that it is not rounded
+	var fields map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatal(err)
+	}
+	if seed, ok := fields["seed"].(string); !ok || seed != strconv.FormatInt(envelope.Seed, 10) {
+		t.Errorf("expected the seed %v as a string but got %#v", envelope.Seed, fields["seed"])
+	}
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
@@ -0,0 +1,89 @@
+//go:build js && wasm
+
+/*
+  files.go
+  Description: In-memory file system with the files given from JavaScript
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 18:14:52 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"time"
+)
+
+// typedefs
+// ----------------------------------------------------------------------------
+
+// A memFS is a read-only file system which maps the name of every file to its
+// contents. It has no directories so that all files are given by their name
+type memFS map[string][]byte
+
+// A memFile is a file of a memFS open for reading
+type memFile struct {
+	*bytes.Reader
+	name string
+	size int64
+}
+
+// A memFileInfo describes a file of a memFS
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+// methods
+// ----------------------------------------------------------------------------
+
+// Open the file with the given name. It implements fs.FS
+func (fsys memFS) Open(name string) (fs.File, error) {
+
+	data, ok := fsys[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{Reader: bytes.NewReader(data), name: name, size: int64(len(data))}, nil
+}
+
+// Return a copy of the contents of the file with the given name. It implements
+// fs.ReadFileFS
+func (fsys memFS) ReadFile(name string) ([]byte, error) {
+
+	data, ok := fsys[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte{}, data...), nil
+}
+
+// Return the description of the file. It implements fs.File
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	return memFileInfo{name: f.name, size: f.size}, nil
+}
+
+// Close the file. It implements fs.File
+func (f *memFile) Close() error {
+	return nil
+}
+
+// The following methods implement fs.FileInfo for read-only regular files
+func (info memFileInfo) Name() string       { return info.name }
+func (info memFileInfo) Size() int64        { return info.size }
+func (info memFileInfo) Mode() fs.FileMode  { return 0444 }
+func (info memFileInfo) ModTime() time.Time { return time.Time{} }
+func (info memFileInfo) IsDir() bool        { return false }
+func (info memFileInfo) Sys() interface{}   { return nil }
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
@@ -0,0 +1,105 @@
+//go:build js && wasm
+
+/*
+  main.go
+  Description: WebAssembly entry point for generating problems in browsers
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 06:55:40 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+// This program exports the JSON problem API to JavaScript so that problems can
+// be generated in browsers without any server. It is built with
+//
+//	GOOS=js GOARCH=wasm go build -o mathprob.wasm ./wasm
+//
+// and loaded with the file wasm_exec.js distributed with Go. Once started, it
+// defines the following global functions:
+//
+//	GenerateProblemsJSON(jsonString): receives the same JSON used in JSON
+//	problem files (see -help-json-problem) and returns the problems generated
+//	as a JSON string
+//
+//	SetFilesJSON(jsonString): receives a JSON object with the contents of all
+//	files that can be used while generating problems (e.g., banks of stories)
+//	indexed by their names
+//
+// Errors are returned as a JSON object with the key "error"
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"syscall/js"
+
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// functions
+// ----------------------------------------------------------------------------
+
+// return the given error as a JSON object with the key "error"
+func jsonError(err error) string {
+
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(data)
+}
+
+// generates the problems requested in the JSON string given as the only
+// argument, and returns them as a JSON string
+func generateProblemsJSON(this js.Value, args []js.Value) interface{} {
+
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return `{"error": "GenerateProblemsJSON expects a JSON string"}`
+	}
+	masterProblem, err := mathtools.Unmarshall([]byte(args[0].String()))
+	if err != nil {
+		return jsonError(err)
+	}
+	data, err := mathtools.GenerateJSON(context.Background(), masterProblem)
+	if err != nil {
+		return jsonError(err)
+	}
+	return string(data)
+}
+
+// sets the files that can be read while generating problems from the JSON
+// object given as the only argument, which maps the name of every file to its
+// contents. It returns an empty JSON object if no error was found
+func setFilesJSON(this js.Value, args []js.Value) interface{} {
+
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return `{"error": "SetFilesJSON expects a JSON string"}`
+	}
+	var files map[string]string
+	if err := json.Unmarshal([]byte(args[0].String()), &files); err != nil {
+		return jsonError(err)
+	}
+	fsys := make(memFS)
+	for name, contents := range files {
+		fsys[name] = []byte(contents)
+	}
+	mathtools.SetFileSystem(fsys)
+	return "{}"
+}
+
+// Main body
+func main() {
+
+	js.Global().Set("GenerateProblemsJSON", js.FuncOf(generateProblemsJSON))
+	js.Global().Set("SetFilesJSON", js.FuncOf(setFilesJSON))
+
+	// and keep running so that the functions can be invoked from JavaScript
+	select {}
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */
@@ -0,0 +1,74 @@
+/*
+  watch.go
+  Description: Regeneration of sheets every time their master file is modified
+  -----------------------------------------------------------------------------
+
+  Started on  <Fri Oct 16 04:58:36 2026 >
+  Last update <>
+  -----------------------------------------------------------------------------
+
+  Made by
+  Login   <carlos.linares@uc3m.es>
+*/
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/clinaresl/mathprob/mathtools"
+)
+
+// constants
+// ----------------------------------------------------------------------------
+
+// The master file is checked for modifications with the following period
+const WATCHINTERVAL time.Duration = 500 * time.Millisecond
+
+// functions
+// ----------------------------------------------------------------------------
+
+// monitor the given master file and generate the given TeX file (and its PDF,
+// if requested) every time it is saved. The TeX file is overwritten every time,
+// and the templates are parsed again so that modifications in the partials are
+// also taken into account. Errors are reported but they do not stop watching
+// the master file, which goes on until the given context is done
+func watch(ctx context.Context, masterFile mathtools.MasterFile, dst string) {
+
+	masterFile.Overwrite = true
+	log.Printf("Watching '%v' for modifications. Press Ctrl-C to stop", masterFile.GetInfile())
+
+	var modified time.Time
+	ticker := time.NewTicker(WATCHINTERVAL)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+
+		// stop as soon as the program is interrupted
+		if ctx.Err() != nil {
+			return
+		}
+
+		// the master file might be temporarily unavailable while it is being
+		// saved, so just wait for it
+		info, err := os.Stat(masterFile.GetInfile())
+		if err != nil || info.ModTime().Equal(modified) {
+			continue
+		}
+		modified = info.ModTime()
+
+		mathtools.ClearTemplateCache()
+		if err := masterFile.MasterToFileFromTemplate(ctx, dst); err != nil {
+			log.Printf(" Error: %v", err)
+			continue
+		}
+		log.Printf("'%v' generated", dst)
+	}
+}
+
+/* Local Variables: */
+/* mode:go */
+/* fill-column:80 */
+/* End: */